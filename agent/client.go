@@ -35,11 +35,33 @@ import (
 )
 
 // Establish starts the daemon, if necessary, and returns a client to it.
+// Depending on the tunnel mode, the client may instead dial through
+// in-process tunnels, either always or whenever the daemon can't be started.
 func Establish(ctx context.Context, apiClient *api.Client) (*Client, error) {
 	if err := wireguard.PruneInvalidPeers(ctx, apiClient); err != nil {
 		return nil, err
 	}
 
+	mode, err := tunnelMode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == TunnelModeUserspace {
+		return newUserspaceClient(apiClient), nil
+	}
+
+	c, err := establishAgent(ctx)
+	if err == nil || mode == TunnelModeAgent || ctx.Err() != nil {
+		return c, err
+	}
+
+	warnUserspaceFallback(ctx, err)
+
+	return newUserspaceClient(apiClient), nil
+}
+
+func establishAgent(ctx context.Context) (*Client, error) {
 	c := newClient("unix", PathToSocket())
 
 	res, err := c.Ping(ctx)
@@ -127,6 +149,10 @@ type Client struct {
 	network string
 	address string
 	dialer  net.Dialer
+
+	// apiClient is set for clients which dial through in-process tunnels
+	// rather than through the agent.
+	apiClient *api.Client
 }
 
 func (c *Client) dialContext(ctx context.Context) (conn net.Conn, err error) {
@@ -169,6 +195,10 @@ func (c *Client) do(parent context.Context, fn func(net.Conn) error) (err error)
 }
 
 func (c *Client) Kill(ctx context.Context) error {
+	if c.userspace() {
+		return nil
+	}
+
 	return c.do(ctx, func(conn net.Conn) error {
 		return proto.Write(conn, "kill")
 	})
@@ -187,6 +217,10 @@ func (err errInvalidResponse) Error() string {
 }
 
 func (c *Client) Ping(ctx context.Context) (res PingResponse, err error) {
+	if c.userspace() {
+		return c.userspacePing(), nil
+	}
+
 	err = c.do(ctx, func(conn net.Conn) (err error) {
 		if err = proto.Write(conn, "ping"); err != nil {
 			return
@@ -241,6 +275,10 @@ type EstablishResponse struct {
 }
 
 func (c *Client) doEstablish(ctx context.Context, slug string, recycle bool) (res *EstablishResponse, err error) {
+	if c.userspace() {
+		return c.userspaceEstablish(ctx, slug, recycle)
+	}
+
 	err = c.do(ctx, func(conn net.Conn) (err error) {
 		verb := "establish"
 		if recycle {
@@ -284,6 +322,10 @@ func (c *Client) Reestablish(ctx context.Context, slug string) (res *EstablishRe
 }
 
 func (c *Client) Probe(ctx context.Context, slug string) error {
+	if c.userspace() {
+		return c.userspaceProbe(ctx, slug)
+	}
+
 	return c.do(ctx, func(conn net.Conn) (err error) {
 		if err = proto.Write(conn, "probe", slug); err != nil {
 			return
@@ -308,6 +350,10 @@ func (c *Client) Probe(ctx context.Context, slug string) error {
 }
 
 func (c *Client) Resolve(ctx context.Context, slug, host string) (addr string, err error) {
+	if c.userspace() {
+		return c.userspaceResolve(ctx, slug, host)
+	}
+
 	err = c.do(ctx, func(conn net.Conn) (err error) {
 		if err = proto.Write(conn, "resolve", slug, host); err != nil {
 			return
@@ -389,6 +435,11 @@ func (c *Client) Instances(ctx context.Context, org, app string) (instances Inst
 	gqlChan := make(chan instancesResult)
 	var agentInstances Instances
 	go func() {
+		if c.userspace() {
+			agentChan <- c.userspaceInstances(ctx, org, app, &agentInstances)
+			return
+		}
+
 		agentChan <- c.do(ctx, func(conn net.Conn) (err error) {
 			if err = proto.Write(conn, "instances", org, app); err != nil {
 				return
@@ -613,6 +664,10 @@ func (d *dialer) Config() *wg.Config {
 }
 
 func (d *dialer) DialContext(ctx context.Context, network, addr string) (conn net.Conn, err error) {
+	if d.client.userspace() {
+		return d.userspaceDial(ctx, addr)
+	}
+
 	if conn, err = d.client.dialContext(ctx); err != nil {
 		return
 	}
@@ -659,6 +714,10 @@ type Pinger struct {
 // to a Pinger connection by sending the "ping6" command. Call "Close"
 // on a Pinger when you're done pinging things.
 func (c *Client) Pinger(ctx context.Context, slug string) (p *Pinger, err error) {
+	if c.userspace() {
+		return nil, errors.New("pinger: pinging requires the agent; run with --tunnel-mode agent")
+	}
+
 	if _, err = c.Establish(ctx, slug); err != nil {
 		return nil, fmt.Errorf("pinger: %w", err)
 	}
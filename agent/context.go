@@ -6,6 +6,8 @@ import (
 
 type contextKey struct{}
 
+type clientContextKey struct{}
+
 func DialerWithContext(ctx context.Context, dialer Dialer) context.Context {
 	return context.WithValue(ctx, contextKey{}, dialer)
 }
@@ -13,3 +15,15 @@ func DialerWithContext(ctx context.Context, dialer Dialer) context.Context {
 func DialerFromContext(ctx context.Context) Dialer {
 	return ctx.Value(contextKey{}).(Dialer)
 }
+
+// ClientWithContext returns a copy of ctx carrying client, so that commands
+// establish the agent only once however many operations they run.
+func ClientWithContext(ctx context.Context, client *Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext returns the agent client ctx carries, if any.
+func ClientFromContext(ctx context.Context) *Client {
+	client, _ := ctx.Value(clientContextKey{}).(*Client)
+	return client
+}
@@ -7,21 +7,17 @@ import (
 	"log"
 	"net"
 	"os"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/azazeal/pause"
-	"github.com/spf13/viper"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/superfly/flyctl/agent"
-	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/wg"
 
 	"github.com/superfly/flyctl/api"
-	"github.com/superfly/flyctl/internal/env"
 	"github.com/superfly/flyctl/internal/sentry"
 	"github.com/superfly/flyctl/internal/wireguard"
 )
@@ -218,15 +214,8 @@ func (s *server) buildTunnel(org *api.Organization, recycle bool) (tunnel *wg.Tu
 		return
 	}
 
-	// WIP: can't stay this way, need something more clever than this
-	if env.IsCI() || os.Getenv("WSWG") != "" || viper.GetBool(flyctl.ConfigWireGuardWebsockets) {
-		if tunnel, err = wg.ConnectWS(context.Background(), state); err != nil {
-			return
-		}
-	} else {
-		if tunnel, err = wg.Connect(context.Background(), state); err != nil {
-			return
-		}
+	if tunnel, err = agent.ConnectTunnel(state); err != nil {
+		return
 	}
 
 	s.tunnels[org.Slug] = tunnel
@@ -238,44 +227,7 @@ func (s *server) fetchInstances(ctx context.Context, tunnel *wg.Tunnel, app stri
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	regionsv, err := tunnel.LookupTXT(ctx, fmt.Sprintf("regions.%s.internal", app))
-	if err != nil {
-		return nil, fmt.Errorf("look up regions for %s: %w", app, err)
-	}
-
-	var regions string
-
-	if len(regionsv) > 0 {
-		regions = strings.Trim(regionsv[0], " \t")
-	}
-
-	if regions == "" {
-		return nil, fmt.Errorf("can't find deployed regions for %s", app)
-	}
-
-	ret := &agent.Instances{}
-
-	for _, region := range strings.Split(regions, ",") {
-		name := fmt.Sprintf("%s.%s.internal", region, app)
-		addrs, err := tunnel.LookupAAAA(ctx, name)
-		if err != nil {
-			s.printf("can't lookup records for %s: %s", name, err)
-			continue
-		}
-
-		if len(addrs) == 1 {
-			ret.Labels = append(ret.Labels, name)
-			ret.Addresses = append(ret.Addresses, addrs[0].String())
-			continue
-		}
-
-		for _, addr := range addrs {
-			ret.Labels = append(ret.Labels, fmt.Sprintf("%s (%s)", region, addr))
-			ret.Addresses = append(ret.Addresses, addr.String())
-		}
-	}
-
-	return ret, nil
+	return agent.LookupInstances(ctx, tunnel, app, s.printf)
 }
 
 func (s *server) tunnelFor(slug string) *wg.Tunnel {
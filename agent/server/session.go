@@ -22,7 +22,6 @@ import (
 	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/agent/internal/proto"
 	"github.com/superfly/flyctl/api"
-	"github.com/superfly/flyctl/wg"
 
 	"github.com/superfly/flyctl/internal/buildinfo"
 )
@@ -262,7 +261,7 @@ func (s *session) resolve(ctx context.Context, args ...string) {
 		return
 	}
 
-	addr, err := resolve(ctx, tunnel, args[1])
+	addr, err := agent.ResolveOnTunnel(ctx, tunnel, args[1])
 	if err != nil {
 		s.error(err)
 
@@ -272,41 +271,6 @@ func (s *session) resolve(ctx context.Context, args ...string) {
 	s.ok(addr)
 }
 
-func resolve(ctx context.Context, tunnel *wg.Tunnel, addr string) (string, error) {
-	host, port, err := net.SplitHostPort(addr)
-	if err != nil {
-		if !strings.Contains(err.Error(), "missing port") {
-			return "", err
-		}
-
-		host = addr
-	}
-
-	if n := net.ParseIP(host); n != nil && n.To16() != nil {
-		if port == "" {
-			return n.String(), nil
-		}
-
-		return net.JoinHostPort(n.String(), port), nil
-	}
-
-	ips, err := tunnel.LookupAAAA(ctx, host)
-	if err != nil {
-		return "", err
-	}
-
-	if len(ips) == 0 {
-		return "", agent.ErrNoSuchHost
-	}
-
-	addr = ips[0].String()
-	if port != "" {
-		addr = net.JoinHostPort(addr, port)
-	}
-
-	return addr, nil
-}
-
 var (
 	errMalformedConnect = errors.New("malformed connect command")
 	errDone             = errors.New("done")
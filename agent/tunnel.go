@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/superfly/flyctl/flyctl"
+	"github.com/superfly/flyctl/internal/env"
+	"github.com/superfly/flyctl/wg"
+)
+
+// ConnectTunnel connects a tunnel for state, over websockets where UDP is
+// unlikely to get through.
+func ConnectTunnel(state *wg.WireGuardState) (*wg.Tunnel, error) {
+	// WIP: can't stay this way, need something more clever than this
+	if env.IsCI() || os.Getenv("WSWG") != "" || viper.GetBool(flyctl.ConfigWireGuardWebsockets) {
+		return wg.ConnectWS(context.Background(), state)
+	}
+
+	return wg.Connect(context.Background(), state)
+}
+
+// LookupInstances looks up the instances of app through tunnel, reporting
+// the regions whose instances can't be looked up to logf.
+func LookupInstances(ctx context.Context, tunnel *wg.Tunnel, app string, logf func(string, ...interface{})) (*Instances, error) {
+	regionsv, err := tunnel.LookupTXT(ctx, fmt.Sprintf("regions.%s.internal", app))
+	if err != nil {
+		return nil, fmt.Errorf("look up regions for %s: %w", app, err)
+	}
+
+	var regions string
+
+	if len(regionsv) > 0 {
+		regions = strings.Trim(regionsv[0], " \t")
+	}
+
+	if regions == "" {
+		return nil, fmt.Errorf("can't find deployed regions for %s", app)
+	}
+
+	ret := &Instances{}
+
+	for _, region := range strings.Split(regions, ",") {
+		name := fmt.Sprintf("%s.%s.internal", region, app)
+		addrs, err := tunnel.LookupAAAA(ctx, name)
+		if err != nil {
+			logf("can't lookup records for %s: %s", name, err)
+			continue
+		}
+
+		if len(addrs) == 1 {
+			ret.Labels = append(ret.Labels, name)
+			ret.Addresses = append(ret.Addresses, addrs[0].String())
+			continue
+		}
+
+		for _, addr := range addrs {
+			ret.Labels = append(ret.Labels, fmt.Sprintf("%s (%s)", region, addr))
+			ret.Addresses = append(ret.Addresses, addr.String())
+		}
+	}
+
+	return ret, nil
+}
+
+// ResolveOnTunnel resolves the host of addr, which may carry a port, to its
+// first AAAA record through tunnel.
+func ResolveOnTunnel(ctx context.Context, tunnel *wg.Tunnel, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		if !strings.Contains(err.Error(), "missing port") {
+			return "", err
+		}
+
+		host = addr
+	}
+
+	if n := net.ParseIP(host); n != nil && n.To16() != nil {
+		if port == "" {
+			return n.String(), nil
+		}
+
+		return net.JoinHostPort(n.String(), port), nil
+	}
+
+	ips, err := tunnel.LookupAAAA(ctx, host)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ips) == 0 {
+		return "", ErrNoSuchHost
+	}
+
+	addr = ips[0].String()
+	if port != "" {
+		addr = net.JoinHostPort(addr, port)
+	}
+
+	return addr, nil
+}
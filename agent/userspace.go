@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/logger"
+	"github.com/superfly/flyctl/internal/wireguard"
+	"github.com/superfly/flyctl/wg"
+)
+
+// The tunnel modes --tunnel-mode accepts.
+const (
+	TunnelModeAuto      = "auto"
+	TunnelModeAgent     = "agent"
+	TunnelModeUserspace = "userspace"
+)
+
+func tunnelMode(ctx context.Context) (string, error) {
+	mode := TunnelModeAuto
+	if cfg := config.MaybeFromContext(ctx); cfg != nil && cfg.TunnelMode != "" {
+		mode = cfg.TunnelMode
+	}
+
+	switch mode {
+	case TunnelModeAuto, TunnelModeAgent, TunnelModeUserspace:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid tunnel mode %q: must be one of %s, %s or %s",
+			mode, TunnelModeAuto, TunnelModeAgent, TunnelModeUserspace)
+	}
+}
+
+// userspaceTunnels holds the in-process tunnels of this process, by
+// organization slug, so that every dial to an organization shares one.
+var userspaceTunnels = struct {
+	sync.Mutex
+	bySlug map[string]*wg.Tunnel
+}{
+	bySlug: map[string]*wg.Tunnel{},
+}
+
+var userspaceNotice sync.Once
+
+// warnUserspaceFallback tells the user, once per process, that the agent
+// couldn't be started.
+func warnUserspaceFallback(ctx context.Context, err error) {
+	userspaceNotice.Do(func() {
+		msg := fmt.Sprintf("The flyctl agent couldn't be started (%v); dialing through a slower in-process tunnel instead.", err)
+
+		if logger := logger.MaybeFromContext(ctx); logger != nil {
+			logger.Warn(msg)
+		} else {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+	})
+}
+
+// newUserspaceClient returns a client which dials through in-process tunnels
+// rather than through the agent.
+func newUserspaceClient(apiClient *api.Client) *Client {
+	return &Client{
+		apiClient: apiClient,
+	}
+}
+
+func (c *Client) userspace() bool {
+	return c.apiClient != nil
+}
+
+// userspaceTunnel returns the in-process tunnel to the organization slug
+// names, connecting it first if there's none or recycle is set.
+func (c *Client) userspaceTunnel(ctx context.Context, slug string, recycle bool) (*wg.Tunnel, error) {
+	userspaceTunnels.Lock()
+	defer userspaceTunnels.Unlock()
+
+	if tunnel := userspaceTunnels.bySlug[slug]; tunnel != nil && !recycle {
+		return tunnel, nil
+	}
+
+	org, err := c.apiClient.GetOrganizationBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching organization %s: %w", slug, err)
+	}
+
+	state, err := wireguard.UserspaceStateForOrg(c.apiClient, org, recycle)
+	if err != nil {
+		return nil, fmt.Errorf("failed obtaining wireguard peer for %s: %w", slug, err)
+	}
+
+	tunnel, err := ConnectTunnel(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed connecting tunnel to %s: %w", slug, err)
+	}
+
+	if old := userspaceTunnels.bySlug[slug]; old != nil {
+		_ = old.Close()
+	}
+	userspaceTunnels.bySlug[slug] = tunnel
+
+	return tunnel, nil
+}
+
+func (c *Client) userspaceEstablish(ctx context.Context, slug string, recycle bool) (*EstablishResponse, error) {
+	tunnel, err := c.userspaceTunnel(ctx, slug, recycle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EstablishResponse{
+		WireGuardState: tunnel.State,
+		TunnelConfig:   tunnel.Config,
+	}, nil
+}
+
+func (c *Client) userspaceProbe(ctx context.Context, slug string) error {
+	userspaceTunnels.Lock()
+	tunnel := userspaceTunnels.bySlug[slug]
+	userspaceTunnels.Unlock()
+
+	if tunnel == nil {
+		return ErrTunnelUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := tunnel.LookupAAAA(ctx, "_api.internal"); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return ErrTunnelUnavailable
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) userspaceResolve(ctx context.Context, slug, host string) (string, error) {
+	tunnel, err := c.userspaceTunnel(ctx, slug, false)
+	if err != nil {
+		return "", err
+	}
+
+	return ResolveOnTunnel(ctx, tunnel, host)
+}
+
+func (c *Client) userspaceInstances(ctx context.Context, org, app string, instances *Instances) error {
+	tunnel, err := c.userspaceTunnel(ctx, org, false)
+	if err != nil {
+		return err
+	}
+
+	ret, err := LookupInstances(ctx, tunnel, app, func(format string, args ...interface{}) {
+		if logger := logger.MaybeFromContext(ctx); logger != nil {
+			logger.Debugf(format, args...)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	*instances = *ret
+
+	return nil
+}
+
+func (c *Client) userspacePing() PingResponse {
+	return PingResponse{
+		PID:     os.Getpid(),
+		Version: buildinfo.Version(),
+	}
+}
+
+func (d *dialer) userspaceDial(ctx context.Context, addr string) (net.Conn, error) {
+	tunnel, err := d.client.userspaceTunnel(ctx, d.slug, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+
+	return tunnel.DialContext(ctx, "tcp", addr)
+}
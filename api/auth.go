@@ -42,6 +42,51 @@ func StartCLISessionWebAuth(machineName string, signup bool) (CLISessionAuth, er
 	return result, nil
 }
 
+// CLISessionDeviceAuth holds the details of a session authorized from
+// another device by entering UserCode at VerificationURL
+type CLISessionDeviceAuth struct {
+	ID              string `json:"id"`
+	UserCode        string `json:"user_code"`
+	VerificationURL string `json:"verification_url"`
+	// ExpiresIn and Interval are in seconds
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval"`
+}
+
+// StartCLISessionDeviceAuth starts a session with the platform via device
+// authorization, for hosts that can't open a browser
+func StartCLISessionDeviceAuth(ctx context.Context, machineName string, signup bool) (result CLISessionDeviceAuth, err error) {
+	postData, _ := json.Marshal(map[string]interface{}{
+		"name":   machineName,
+		"device": true,
+		"signup": signup,
+	})
+
+	url := fmt.Sprintf("%s/api/v1/cli_sessions", baseURL)
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(postData)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var res *http.Response
+	if res, err = http.DefaultClient.Do(req); err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		err = ErrUnknown
+
+		return
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&result)
+
+	return
+}
+
 // GetAccessTokenForCLISession Obtains the access token for the session
 func GetAccessTokenForCLISession(ctx context.Context, id string) (token string, err error) {
 	url := fmt.Sprintf("%s/api/v1/cli_sessions/%s", baseURL, id)
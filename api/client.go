@@ -68,14 +68,8 @@ func (c *Client) Run(req *graphql.Request) (Query, error) {
 
 // RunWithContext - Runs a GraphQL request within a Go context
 func (c *Client) RunWithContext(ctx context.Context, req *graphql.Request) (Query, error) {
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
-	req.Header.Set("User-Agent", c.userAgent)
-	if c.trace != "" {
-		req.Header.Set("Fly-Force-Trace", c.trace)
-	}
-
 	var resp Query
-	err := c.client.Run(ctx, req, &resp)
+	err := c.runInto(ctx, req, &resp)
 
 	if resp.Errors != nil && errorLog {
 		fmt.Fprintf(os.Stderr, "Error: %+v\n", resp.Errors)
@@ -84,6 +78,18 @@ func (c *Client) RunWithContext(ctx context.Context, req *graphql.Request) (Quer
 	return resp, err
 }
 
+// runInto runs a GraphQL request decoding its response into resp, for
+// queries whose shape isn't known ahead of time, such as aliased ones.
+func (c *Client) runInto(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.trace != "" {
+		req.Header.Set("Fly-Force-Trace", c.trace)
+	}
+
+	return c.client.Run(ctx, req, resp)
+}
+
 var compactPattern = regexp.MustCompile(`\s+`)
 
 func compactQueryString(q string) string {
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// signalNames names the signals processes are commonly killed by.
+var signalNames = map[int16]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	3:  "SIGQUIT",
+	4:  "SIGILL",
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	8:  "SIGFPE",
+	9:  "SIGKILL",
+	11: "SIGSEGV",
+	13: "SIGPIPE",
+	14: "SIGALRM",
+	15: "SIGTERM",
+}
+
+// MachineExit is the account init gives of a machine exiting, decoded from
+// its exit event.
+type MachineExit struct {
+	ExitCode int16 `json:"exit_code"`
+	// Signal names the signal which killed the process, if any.
+	Signal        string    `json:"signal,omitempty"`
+	OOMKilled     bool      `json:"oom_killed"`
+	RequestedStop bool      `json:"requested_stop"`
+	RootfsFailed  bool      `json:"rootfs_failed"`
+	MountFailed   bool      `json:"mount_failed"`
+	Error         string    `json:"error,omitempty"`
+	At            time.Time `json:"at"`
+}
+
+// Exit decodes the exit event of e, returning nil unless e is one.
+func (e *MachineEvent) Exit() *MachineExit {
+	if e.Type != "exit" || e.Request == nil || e.Request.ExitEvent == nil {
+		return nil
+	}
+
+	event := e.Request.ExitEvent
+
+	exit := &MachineExit{
+		ExitCode:      event.ExitCode,
+		OOMKilled:     event.OOMKilled,
+		RequestedStop: event.RequestedStop,
+		Error:         event.Error,
+		At:            time.UnixMilli(e.Timestamp).UTC(),
+	}
+
+	if event.ExitCode == 0 && event.GuestExitCode != 0 {
+		exit.ExitCode = event.GuestExitCode
+	}
+
+	// -1 stands for no signal at all
+	signal := event.GuestSignal
+	if signal <= 0 {
+		signal = event.Signal
+	}
+	if signal > 0 {
+		exit.Signal = signalName(signal)
+	}
+
+	if msg := strings.ToLower(event.Error); strings.Contains(msg, "mount") {
+		if strings.Contains(msg, "rootfs") || strings.Contains(msg, "root filesystem") {
+			exit.RootfsFailed = true
+		} else {
+			exit.MountFailed = true
+		}
+	}
+
+	return exit
+}
+
+// LastExit decodes the latest exit event of m, returning nil should m have
+// none.
+func (m *Machine) LastExit() *MachineExit {
+	var last *MachineEvent
+	for _, event := range m.Events {
+		if event.Exit() != nil && (last == nil || event.Timestamp > last.Timestamp) {
+			last = event
+		}
+	}
+
+	if last == nil {
+		return nil
+	}
+
+	return last.Exit()
+}
+
+// Hint suggests what to do about the exit, or returns an empty string when
+// there's nothing to be done, such as when the stop was requested.
+func (e *MachineExit) Hint() string {
+	switch {
+	case e.OOMKilled:
+		return "consider increasing memory, the machine was OOM killed"
+	case e.RootfsFailed:
+		return "the root filesystem failed to mount; check that the image is valid"
+	case e.MountFailed:
+		return "a volume failed to mount; check that the mounted volumes exist in the machine's region"
+	case e.RequestedStop:
+		return ""
+	case e.Signal != "":
+		return fmt.Sprintf("the process was killed by %s; check its logs", e.Signal)
+	case e.ExitCode != 0:
+		return fmt.Sprintf("the process exited with code %d; check its logs", e.ExitCode)
+	default:
+		return ""
+	}
+}
+
+func signalName(signal int16) string {
+	if name, ok := signalNames[signal]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("signal %d", signal)
+}
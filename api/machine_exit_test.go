@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMachineEventExit(t *testing.T) {
+	cases := []struct {
+		name  string
+		event string
+		want  *MachineExit
+		hint  string
+	}{
+		{
+			name:  "not an exit",
+			event: `{"type": "start", "status": "started", "timestamp": 1000}`,
+		},
+		{
+			name:  "exit code",
+			event: `{"type": "exit", "timestamp": 1000, "request": {"exit_event": {"exit_code": 3, "guest_signal": -1, "signal": -1}}}`,
+			want:  &MachineExit{ExitCode: 3},
+			hint:  "the process exited with code 3; check its logs",
+		},
+		{
+			name:  "guest exit code",
+			event: `{"type": "exit", "timestamp": 1000, "request": {"exit_event": {"guest_exit_code": 1, "guest_signal": -1, "signal": -1}}}`,
+			want:  &MachineExit{ExitCode: 1},
+			hint:  "the process exited with code 1; check its logs",
+		},
+		{
+			name:  "signal",
+			event: `{"type": "exit", "timestamp": 1000, "request": {"exit_event": {"exit_code": 139, "guest_signal": 11, "signal": -1}}}`,
+			want:  &MachineExit{ExitCode: 139, Signal: "SIGSEGV"},
+			hint:  "the process was killed by SIGSEGV; check its logs",
+		},
+		{
+			name:  "unnamed signal",
+			event: `{"type": "exit", "timestamp": 1000, "request": {"exit_event": {"guest_signal": -1, "signal": 31}}}`,
+			want:  &MachineExit{Signal: "signal 31"},
+			hint:  "the process was killed by signal 31; check its logs",
+		},
+		{
+			name:  "oom",
+			event: `{"type": "exit", "timestamp": 1000, "request": {"exit_event": {"exit_code": 137, "guest_signal": 9, "oom_killed": true}}}`,
+			want:  &MachineExit{ExitCode: 137, Signal: "SIGKILL", OOMKilled: true},
+			hint:  "consider increasing memory, the machine was OOM killed",
+		},
+		{
+			name:  "requested stop",
+			event: `{"type": "exit", "timestamp": 1000, "request": {"exit_event": {"exit_code": 143, "guest_signal": 15, "requested_stop": true}}}`,
+			want:  &MachineExit{ExitCode: 143, Signal: "SIGTERM", RequestedStop: true},
+		},
+		{
+			name:  "rootfs",
+			event: `{"type": "exit", "timestamp": 1000, "request": {"exit_event": {"exit_code": 1, "error": "error mounting rootfs: no such device"}}}`,
+			want:  &MachineExit{ExitCode: 1, RootfsFailed: true, Error: "error mounting rootfs: no such device"},
+			hint:  "the root filesystem failed to mount; check that the image is valid",
+		},
+		{
+			name:  "mount",
+			event: `{"type": "exit", "timestamp": 1000, "request": {"exit_event": {"exit_code": 1, "error": "failed to mount /dev/vdb at /data"}}}`,
+			want:  &MachineExit{ExitCode: 1, MountFailed: true, Error: "failed to mount /dev/vdb at /data"},
+			hint:  "a volume failed to mount; check that the mounted volumes exist in the machine's region",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var event MachineEvent
+			if err := json.Unmarshal([]byte(tc.event), &event); err != nil {
+				t.Fatal(err)
+			}
+
+			got := event.Exit()
+			if tc.want == nil {
+				if got != nil {
+					t.Fatalf("got exit %+v, want none", got)
+				}
+				return
+			}
+
+			tc.want.At = time.UnixMilli(1000).UTC()
+			if got == nil || *got != *tc.want {
+				t.Fatalf("got exit %+v, want %+v", got, tc.want)
+			}
+
+			if hint := got.Hint(); hint != tc.hint {
+				t.Errorf("got hint %q, want %q", hint, tc.hint)
+			}
+		})
+	}
+}
+
+func TestMachineLastExit(t *testing.T) {
+	exit := func(ts int64, code int16) *MachineEvent {
+		return &MachineEvent{
+			Type:      "exit",
+			Timestamp: ts,
+			Request:   &MachineRequest{ExitEvent: &MachineExitEvent{ExitCode: code, GuestSignal: -1, Signal: -1}},
+		}
+	}
+
+	m := &Machine{Events: []*MachineEvent{
+		{Type: "start", Timestamp: 4000},
+		exit(3000, 2),
+		exit(1000, 1),
+	}}
+
+	got := m.LastExit()
+	if got == nil || got.ExitCode != 2 {
+		t.Fatalf("got last exit %+v, want the one with exit code 2", got)
+	}
+
+	if got := (&Machine{}).LastExit(); got != nil {
+		t.Fatalf("got last exit %+v of a machine without events", got)
+	}
+}
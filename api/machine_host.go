@@ -0,0 +1,24 @@
+package api
+
+// The statuses hosts of machines report.
+const (
+	HostStatusOk       = "ok"
+	HostStatusDegraded = "degraded"
+	HostStatusUnknown  = "unknown"
+)
+
+// HostHealth returns the status of the host of m, unknown should it not
+// report one.
+func (m Machine) HostHealth() string {
+	if m.HostStatus == "" {
+		return HostStatusUnknown
+	}
+
+	return m.HostStatus
+}
+
+// HostImpaired reports whether the host of m reports a status other than ok.
+// Hosts which report none aren't taken to be impaired.
+func (m Machine) HostImpaired() bool {
+	return m.HostStatus != "" && m.HostStatus != HostStatusOk
+}
@@ -0,0 +1,26 @@
+package api
+
+import "testing"
+
+func TestMachineHostStatus(t *testing.T) {
+	cases := []struct {
+		status   string
+		health   string
+		impaired bool
+	}{
+		{status: "", health: HostStatusUnknown},
+		{status: HostStatusOk, health: HostStatusOk},
+		{status: HostStatusDegraded, health: HostStatusDegraded, impaired: true},
+		{status: HostStatusUnknown, health: HostStatusUnknown, impaired: true},
+	}
+
+	for _, tc := range cases {
+		m := Machine{HostStatus: tc.status}
+		if got := m.HostHealth(); got != tc.health {
+			t.Errorf("HostHealth() of %q = %q, want %q", tc.status, got, tc.health)
+		}
+		if got := m.HostImpaired(); got != tc.impaired {
+			t.Errorf("HostImpaired() of %q = %v, want %v", tc.status, got, tc.impaired)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import "time"
+
+// MachineChecksPausedMetadataKey marks machines whose checks `machine
+// pause-checks` paused for maintenance. Its value is either "true", for a pause
+// lasting until `machine resume-checks`, or the RFC3339 time it expires at.
+const MachineChecksPausedMetadataKey = "fly_checks_paused"
+
+// ChecksPaused reports whether the checks of m are paused for maintenance at
+// now, along with when the pause expires. until is zero for pauses which don't.
+func (m Machine) ChecksPaused(now time.Time) (until time.Time, paused bool) {
+	if m.Config == nil {
+		return time.Time{}, false
+	}
+
+	switch val, ok := m.Config.Metadata[MachineChecksPausedMetadataKey]; {
+	case !ok:
+		return time.Time{}, false
+	case val == "true":
+		return time.Time{}, true
+	default:
+		until, err := time.Parse(time.RFC3339, val)
+		if err != nil || !now.Before(until) {
+			return time.Time{}, false
+		}
+
+		return until, true
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMachineChecksPaused(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		value  *string
+		paused bool
+		until  time.Time
+	}{
+		{name: "not paused"},
+		{name: "indefinitely", value: StringPointer("true"), paused: true},
+		{name: "until later", value: StringPointer("2023-03-01T13:00:00Z"), paused: true, until: now.Add(time.Hour)},
+		{name: "expired", value: StringPointer("2023-03-01T11:00:00Z")},
+		{name: "malformed", value: StringPointer("1h")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := Machine{Config: &MachineConfig{Metadata: map[string]string{}}}
+			if tc.value != nil {
+				m.Config.Metadata[MachineChecksPausedMetadataKey] = *tc.value
+			}
+
+			until, paused := m.ChecksPaused(now)
+			if paused != tc.paused || !until.Equal(tc.until) {
+				t.Fatalf("got paused %v until %v, want %v until %v", paused, until, tc.paused, tc.until)
+			}
+		})
+	}
+}
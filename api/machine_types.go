@@ -15,15 +15,28 @@ type Machine struct {
 	InstanceID string `json:"instance_id"`
 	Version    string `json:"version"`
 	// PrivateIP is the internal 6PN address of the machine.
-	PrivateIP  string                `json:"private_ip"`
-	CreatedAt  string                `json:"created_at"`
-	UpdatedAt  string                `json:"updated_at"`
-	Config     *MachineConfig        `json:"config"`
-	Events     []*MachineEvent       `json:"events,omitempty"`
-	Checks     []*MachineCheckStatus `json:"checks,omitempty"`
+	PrivateIP string                `json:"private_ip"`
+	CreatedAt string                `json:"created_at"`
+	UpdatedAt string                `json:"updated_at"`
+	Config    *MachineConfig        `json:"config"`
+	Events    []*MachineEvent       `json:"events,omitempty"`
+	Checks    []*MachineCheckStatus `json:"checks,omitempty"`
+	// Notices lists pending host events, such as maintenance or migrations,
+	// scheduled to restart the machine.
+	Notices []*MachineNotice `json:"notices,omitempty"`
+	// HostStatus is the status of the host the machine runs on, such as ok
+	// or degraded.
+	HostStatus string `json:"host_status,omitempty"`
 	LeaseNonce string
 }
 
+type MachineNotice struct {
+	Type     string     `json:"type"`
+	Reason   string     `json:"reason,omitempty"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
 func (m Machine) FullImageRef() string {
 	return fmt.Sprintf("%s/%s:%s", m.ImageRef.Registry, m.ImageRef.Repository, m.ImageRef.Tag)
 }
@@ -65,6 +78,13 @@ type MachineEvent struct {
 	Timestamp int64           `json:"timestamp"`
 }
 
+// MachineVersion is a config the machine was given, identified by a ULID of
+// when that happened.
+type MachineVersion struct {
+	Version    string         `json:"version"`
+	UserConfig *MachineConfig `json:"user_config"`
+}
+
 type MachineRequest struct {
 	ExitEvent    *MachineExitEvent `json:"exit_event,omitempty"`
 	RestartCount int64             `json:"restart_count"`
@@ -78,6 +98,9 @@ type MachineExitEvent struct {
 	RequestedStop bool  `json:"requested_stop"`
 	Resarting     bool  `json:"restarting"`
 	Signal        int16 `json:"signal"`
+	// Error is the error init exited with, such as failing to mount the
+	// root filesystem or a volume.
+	Error string `json:"error,omitempty"`
 }
 
 type StopMachineInput struct {
@@ -134,6 +157,8 @@ type MachineGuest struct {
 	CPUKind  string `json:"cpu_kind"`
 	CPUs     int    `json:"cpus"`
 	MemoryMB int    `json:"memory_mb"`
+	GPUKind  string `json:"gpu_kind,omitempty"`
+	GPUs     int    `json:"gpus,omitempty"`
 
 	KernelArgs []string `json:"kernel_args,omitempty"`
 }
@@ -163,6 +188,9 @@ type MachineCheck struct {
 	Timeout    *Duration `json:"timeout,omitempty" toml:",omitempty"`
 	HTTPMethod *string   `json:"method,omitempty" toml:"method,omitempty"`
 	HTTPPath   *string   `json:"path,omitempty" toml:"path,omitempty"`
+	// Command is what exec checks run in the guest, failing when it exits
+	// non-zero.
+	Command []string `json:"command,omitempty" toml:"command,omitempty"`
 }
 
 type MachineCheckStatus struct {
@@ -183,6 +211,8 @@ type MachineService struct {
 	InternalPort int                        `json:"internal_port" toml:"internal_port"`
 	Ports        []MachinePort              `json:"ports" toml:"ports"`
 	Concurrency  *MachineServiceConcurrency `json:"concurrency,omitempty" toml:"concurrency"`
+	Autostop     *bool                      `json:"autostop,omitempty" toml:"auto_stop_machines,omitempty"`
+	Autostart    *bool                      `json:"autostart,omitempty" toml:"auto_start_machines,omitempty"`
 }
 
 type MachineServiceConcurrency struct {
@@ -206,6 +236,32 @@ type MachineConfig struct {
 	Schedule  string                  `json:"schedule,omitempty"`
 	Network   MachineNetwork          `json:"network,omitempty"`
 	Checks    map[string]MachineCheck `json:"checks,omitempty"`
+	Files     []*MachineFile          `json:"files,omitempty"`
+
+	// Containers are those the machine runs, for machines running more than
+	// one.
+	Containers []MachineContainer `json:"containers,omitempty"`
+
+	// AutoDestroy destroys the machine once it exits
+	AutoDestroy bool `json:"auto_destroy,omitempty"`
+}
+
+// MachineFile is a file written into the machine at GuestPath before it
+// boots, holding either RawValue or the value of the app secret SecretName.
+type MachineFile struct {
+	GuestPath  string  `json:"guest_path"`
+	RawValue   *string `json:"raw_value,omitempty"`
+	SecretName *string `json:"secret_name,omitempty"`
+}
+
+// MachineContainer is one of the containers of a machine, started once those
+// it depends on are.
+type MachineContainer struct {
+	Name      string            `json:"name"`
+	Image     string            `json:"image"`
+	Cmd       []string          `json:"cmd,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	DependsOn []string          `json:"depends_on,omitempty"`
 }
 
 type MachineNetwork struct {
@@ -234,6 +290,8 @@ type LaunchMachineInput struct {
 	OrgSlug string         `json:"organizationId,omitempty"`
 	Region  string         `json:"region,omitempty"`
 	Config  *MachineConfig `json:"config"`
+	// SkipLaunch updates a stopped machine's config without starting it
+	SkipLaunch bool `json:"skip_launch,omitempty"`
 	// Client side only
 	SkipHealthChecks bool
 }
@@ -158,6 +158,7 @@ func (client *Client) GetAppCompact(ctx context.Context, appName string) (*AppCo
 				status
 				appUrl
 				platformVersion
+				network
 				organization {
 					id
 					slug
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// MetricSample is a single series of an instant metrics query.
+type MetricSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// QueryMetrics runs an instant PromQL query against the metrics of the
+// organization identified by orgSlug.
+func (c *Client) QueryMetrics(ctx context.Context, orgSlug, query string) ([]MetricSample, error) {
+	endpoint := fmt.Sprintf("%s/prometheus/%s/api/v1/query?query=%s", baseURL, url.PathEscape(orgSlug), url.QueryEscape(query))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Result []struct {
+				Metric map[string]string `json:"metric"`
+				Value  []interface{}     `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed decoding metrics response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if result.Status != "success" {
+		return nil, fmt.Errorf("metrics query failed: %s", result.Error)
+	}
+
+	samples := make([]MetricSample, 0, len(result.Data.Result))
+	for _, r := range result.Data.Result {
+		// values are [<unix timestamp>, "<value>"]
+		if len(r.Value) != 2 {
+			continue
+		}
+
+		raw, ok := r.Value[1].(string)
+		if !ok {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+
+		samples = append(samples, MetricSample{Labels: r.Metric, Value: v})
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,84 @@
+package api
+
+import "context"
+
+func (c *Client) GetNetworks(ctx context.Context, orgSlug string) ([]Network, error) {
+	query := `
+		query ($slug: String!) {
+			organization(slug: $slug) {
+				networks {
+					nodes {
+						id
+						name
+						apps {
+							nodes {
+								name
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("slug", orgSlug)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization.Networks == nil {
+		return nil, nil
+	}
+
+	return data.Organization.Networks.Nodes, nil
+}
+
+func (c *Client) CreateNetwork(ctx context.Context, orgID, name string) (*Network, error) {
+	query := `
+		mutation ($input: CreateNetworkInput!) {
+			createNetwork(input: $input) {
+				network {
+					id
+					name
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("input", map[string]string{
+		"organizationId": orgID,
+		"name":           name,
+	})
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.CreateNetwork.Network, nil
+}
+
+func (c *Client) DeleteNetwork(ctx context.Context, networkID string) error {
+	query := `
+		mutation ($input: DeleteNetworkInput!) {
+			deleteNetwork(input: $input) {
+				organization {
+					id
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+	req.Var("input", map[string]string{
+		"networkId": networkID,
+	})
+
+	_, err := c.RunWithContext(ctx, req)
+
+	return err
+}
@@ -244,6 +244,38 @@ func (c *Client) DeleteOrganizationMembership(ctx context.Context, orgId, userId
 	return data.DeleteOrganizationMembership.Organization.Name, data.DeleteOrganizationMembership.User.Email, nil
 }
 
+// CreateLimitedAccessToken creates an access token of org limited to what
+// profile grants.
+func (c *Client) CreateLimitedAccessToken(ctx context.Context, orgID, name, profile string) (*LimitedAccessToken, error) {
+	query := `
+	mutation($input: CreateLimitedAccessTokenInput!) {
+		createLimitedAccessToken(input: $input) {
+			limitedAccessToken {
+				id
+				name
+				token
+				expiresAt
+			}
+		}
+	}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", map[string]string{
+		"organizationId": orgID,
+		"name":           name,
+		"profile":        profile,
+	})
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.CreateLimitedAccessToken.LimitedAccessToken, nil
+}
+
 func (c *Client) UpdateRemoteBuilder(ctx context.Context, orgName string, image string) (*Organization, error) {
 
 	org, err := c.GetOrganizationBySlug(ctx, orgName)
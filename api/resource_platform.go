@@ -1,6 +1,9 @@
 package api
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 func (c *Client) PlatformRegions(ctx context.Context) ([]Region, *Region, error) {
 	query := `
@@ -62,6 +65,32 @@ func (c *Client) PlatformRegionsAll(ctx context.Context) ([]Region, error) {
 	return data.Platform.Regions, nil
 }
 
+// PlatformGPURegions returns the regions along with the kinds of GPUs they
+// offer. Only APIs whose Region has gpuKinds can answer it, which HasField
+// tells.
+func (c *Client) PlatformGPURegions(ctx context.Context) ([]Region, error) {
+	query := `
+		query {
+			platform {
+				regions {
+					name
+					code
+					gpuKinds
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Platform.Regions, nil
+}
+
 func (c *Client) PlatformVMSizes(ctx context.Context) ([]VMSize, error) {
 	query := `
 		query {
@@ -71,6 +100,7 @@ func (c *Client) PlatformVMSizes(ctx context.Context) ([]VMSize, error) {
 					cpuCores
 					memoryGb
 					memoryMb
+					memoryIncrementsMb
 					priceMonth
 					priceSecond
 				}
@@ -85,5 +115,21 @@ func (c *Client) PlatformVMSizes(ctx context.Context) ([]VMSize, error) {
 		return nil, err
 	}
 
+	// The API doesn't list the CPU class of sizes, which their names carry
+	for i := range data.Platform.VMSizes {
+		data.Platform.VMSizes[i].CPUClass = cpuClassOfSize(data.Platform.VMSizes[i].Name)
+	}
+
 	return data.Platform.VMSizes, nil
 }
+
+// cpuClassOfSize returns the CPU class of the VM size named name, such as
+// performance for performance-2x.
+func cpuClassOfSize(name string) string {
+	switch class, _, _ := strings.Cut(name, "-"); class {
+	case "performance", "dedicated":
+		return class
+	default:
+		return "shared"
+	}
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"strings"
 )
 
 func (client *Client) CreatePostgresCluster(ctx context.Context, input CreatePostgresClusterInput) (*CreatePostgresClusterPayload, error) {
@@ -156,6 +158,50 @@ func (client *Client) ListPostgresClusterAttachments(ctx context.Context, appNam
 	return data.PostgresAttachments.Nodes, nil
 }
 
+// ListPostgresClusterAttachmentsOfApps returns the attachments of
+// postgresAppName to each of appNames, keyed by app name, in a single query.
+func (client *Client) ListPostgresClusterAttachmentsOfApps(ctx context.Context, appNames []string, postgresAppName string) (map[string][]*PostgresClusterAttachment, error) {
+	if len(appNames) == 0 {
+		return map[string][]*PostgresClusterAttachment{}, nil
+	}
+
+	var vars, fields strings.Builder
+	for i := range appNames {
+		fmt.Fprintf(&vars, ", $app%d: String!", i)
+		fmt.Fprintf(&fields, `
+			app%d: postgresAttachments(appName: $app%d, postgresAppName: $postgresAppName) {
+				nodes {
+					id
+					databaseName
+					databaseUser
+					environmentVariableName
+				}
+			}`, i, i)
+	}
+
+	query := fmt.Sprintf("query($postgresAppName: String!%s) {%s\n}", vars.String(), fields.String())
+
+	req := client.NewRequest(query)
+	req.Var("postgresAppName", postgresAppName)
+	for i, name := range appNames {
+		req.Var(fmt.Sprintf("app%d", i), name)
+	}
+
+	var data map[string]struct {
+		Nodes []*PostgresClusterAttachment
+	}
+	if err := client.runInto(ctx, req, &data); err != nil {
+		return nil, err
+	}
+
+	attachments := make(map[string][]*PostgresClusterAttachment, len(appNames))
+	for i, name := range appNames {
+		attachments[name] = data[fmt.Sprintf("app%d", i)].Nodes
+	}
+
+	return attachments, nil
+}
+
 func (client *Client) ListPostgresUsers(ctx context.Context, appName string) ([]PostgresClusterUser, error) {
 	query := `
 		query($appName: String!) {
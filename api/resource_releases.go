@@ -21,6 +21,7 @@ func (c *Client) GetAppReleases(ctx context.Context, appName string, limit int)
 							name
 						}
 						createdAt
+						metadata
 					}
 				}
 			}
@@ -67,3 +68,53 @@ func (c *Client) GetAppRelease(ctx context.Context, appName string, id string) (
 
 	return data.App.Release, nil
 }
+
+// CreateRelease records a release for apps whose deployments flyctl drives
+// itself, such as machines apps.
+func (c *Client) CreateRelease(ctx context.Context, input CreateReleaseInput) (*Release, error) {
+	query := `
+		mutation ($input: CreateReleaseInput!) {
+			createRelease(input: $input) {
+				release {
+					id
+					version
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.CreateRelease.Release, nil
+}
+
+func (c *Client) UpdateRelease(ctx context.Context, input UpdateReleaseInput) (*Release, error) {
+	query := `
+		mutation ($input: UpdateReleaseInput!) {
+			updateRelease(input: $input) {
+				release {
+					id
+					status
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.UpdateRelease.Release, nil
+}
@@ -0,0 +1,38 @@
+package api
+
+import "context"
+
+// HasField reports whether the type of the schema named typeName has the
+// field named fieldName, so that fields the API doesn't offer everywhere yet
+// are only asked for where it does.
+func (client *Client) HasField(ctx context.Context, typeName, fieldName string) (bool, error) {
+	query := `
+		query($name: String!) {
+			schemaType: __type(name: $name) {
+				fields {
+					name
+				}
+			}
+		}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("name", typeName)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return false, err
+	}
+
+	if data.SchemaType == nil {
+		return false, nil
+	}
+
+	for _, field := range data.SchemaType.Fields {
+		if field.Name == fieldName {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
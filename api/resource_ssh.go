@@ -46,7 +46,10 @@ mutation($input: EstablishSSHKeyInput!) {
 	return &data.EstablishSSHKey, nil
 }
 
-func (c *Client) IssueSSHCertificate(ctx context.Context, org OrganizationImpl, email string, username *string, valid_hours *int) (*IssuedCertificate, error) {
+// IssueSSHCertificate issues a certificate for email, valid for validHours
+// and the given principals (unix users). The API defaults to root when no
+// principals are given.
+func (c *Client) IssueSSHCertificate(ctx context.Context, org OrganizationImpl, email string, principals []string, validHours *int) (*IssuedCertificate, error) {
 	req := c.NewRequest(`
 mutation($input: IssueCertificateInput!) {
   issueCertificate(input: $input) {
@@ -59,12 +62,12 @@ mutation($input: IssueCertificateInput!) {
 		"email":          email,
 	}
 
-	if username != nil {
-		inputs["username"] = *username
+	if len(principals) > 0 {
+		inputs["principals"] = principals
 	}
 
-	if valid_hours != nil {
-		inputs["validHours"] = *valid_hours
+	if validHours != nil {
+		inputs["validHours"] = *validHours
 	}
 
 	req.Var("input", inputs)
@@ -124,6 +124,62 @@ func (c *Client) ExtendVolume(ctx context.Context, input ExtendVolumeInput) (*Vo
 	return &data.ExtendVolume.Volume, nil
 }
 
+func (c *Client) ForkVolume(ctx context.Context, input ForkVolumeInput) (*Volume, error) {
+	query := `
+		mutation($input: ForkVolumeInput!) {
+			forkVolume(input: $input) {
+				volume {
+					id
+					name
+					app {
+						name
+					}
+					region
+					sizeGb
+					encrypted
+					createdAt
+					host {
+						id
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.ForkVolume.Volume, nil
+}
+
+// CreateVolumeSnapshot starts taking a snapshot of the volume volID. The
+// snapshot is listed among those of the volume once it's been taken.
+func (c *Client) CreateVolumeSnapshot(ctx context.Context, volID string) error {
+	query := `
+		mutation($input: CreateVolumeSnapshotInput!) {
+			createVolumeSnapshot(input: $input) {
+				volume {
+					id
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", CreateVolumeSnapshotInput{VolumeID: volID})
+
+	_, err := c.RunWithContext(ctx, req)
+
+	return err
+}
+
 func (c *Client) DeleteVolume(ctx context.Context, volID string) (App *App, err error) {
 	query := `
 		mutation($input: DeleteVolumeInput!) {
@@ -159,6 +215,7 @@ func (c *Client) GetVolume(ctx context.Context, volID string) (Volume *Volume, e
 					name
 				}
 				name
+				state
 				sizeGb
 				region
 				encrypted
@@ -166,6 +223,14 @@ func (c *Client) GetVolume(ctx context.Context, volID string) (Volume *Volume, e
 				host {
 					id
 				}
+				attachedAllocation {
+					idShort
+					taskName
+				}
+				attachedMachine {
+					id
+					name
+				}
 			}
 		}
 	}`
@@ -182,6 +247,31 @@ func (c *Client) GetVolume(ctx context.Context, volID string) (Volume *Volume, e
 	return &data.Volume, nil
 }
 
+// GetVolumeSnapshotRetention returns the number of days the snapshots of the
+// volume are kept for, or nil where the platform applies its default. Only
+// ask for it where the schema has Volume.snapshotRetention.
+func (c *Client) GetVolumeSnapshotRetention(ctx context.Context, volID string) (*int, error) {
+	query := `
+	query($id: ID!) {
+		volume: node(id: $id) {
+			... on Volume {
+				snapshotRetention
+			}
+		}
+	}`
+
+	req := c.NewRequest(query)
+
+	req.Var("id", volID)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Volume.SnapshotRetention, nil
+}
+
 func (c *Client) GetVolumeSnapshots(ctx context.Context, volID string) ([]Snapshot, error) {
 	query := `
 	query($id: ID!) {
@@ -194,6 +284,7 @@ func (c *Client) GetVolumeSnapshots(ctx context.Context, volID string) ([]Snapsh
 						id
 						size
 						digest
+						status
 						createdAt
 					}
 				}
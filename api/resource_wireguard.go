@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 )
 
 // GetWireGuardPeerStatus is distinct from the rest of the WireGuard
@@ -83,6 +84,16 @@ query($slug: String!) {
 }
 
 func (c *Client) CreateWireGuardPeer(ctx context.Context, org *Organization, region, name, pubkey string) (*CreatedWireGuardPeer, error) {
+	return c.createWireGuardPeer(ctx, org, region, name, pubkey, 0)
+}
+
+// CreateEphemeralWireGuardPeer creates a peer which is removed server-side
+// once ttl has elapsed.
+func (c *Client) CreateEphemeralWireGuardPeer(ctx context.Context, org *Organization, region, name, pubkey string, ttl time.Duration) (*CreatedWireGuardPeer, error) {
+	return c.createWireGuardPeer(ctx, org, region, name, pubkey, ttl)
+}
+
+func (c *Client) createWireGuardPeer(ctx context.Context, org *Organization, region, name, pubkey string, ttl time.Duration) (*CreatedWireGuardPeer, error) {
 	req := c.NewRequest(`
 mutation($input: AddWireGuardPeerInput!) {
   addWireGuardPeer(input: $input) {
@@ -111,6 +122,11 @@ mutation($input: AddWireGuardPeerInput!) {
 		inputs["region"] = region
 	}
 
+	if ttl > 0 {
+		inputs["ephemeral"] = true
+		inputs["ttl"] = int(ttl.Seconds())
+	}
+
 	req.Var("input", inputs)
 
 	data, err := c.RunWithContext(ctx, req)
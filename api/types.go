@@ -82,6 +82,18 @@ type Query struct {
 		ReleaseCommand *ReleaseCommand
 	}
 
+	CreateRelease struct {
+		Release Release
+	}
+
+	CreateNetwork struct {
+		Network Network
+	}
+
+	UpdateRelease struct {
+		Release Release
+	}
+
 	EnsureRemoteBuilder *struct {
 		App     *App
 		URL     string
@@ -177,9 +189,19 @@ type Query struct {
 	CreateOrganization CreateOrganizationPayload
 	DeleteOrganization DeleteOrganizationPayload
 
+	// SchemaType is the type of the schema introspection looked up.
+	SchemaType *struct {
+		Fields []struct {
+			Name string
+		}
+	}
+
 	CreateVolume CreateVolumePayload
 	DeleteVolume DeleteVolumePayload
 	ExtendVolume ExtendVolumePayload
+	ForkVolume   ForkVolumePayload
+
+	CreateVolumeSnapshot CreateVolumeSnapshotPayload
 
 	AddWireGuardPeer              CreatedWireGuardPeer
 	EstablishSSHKey               SSHCertificate
@@ -217,6 +239,10 @@ type Query struct {
 
 	DeleteOrganizationMembership *DeleteOrganizationMembershipPayload
 
+	CreateLimitedAccessToken struct {
+		LimitedAccessToken LimitedAccessToken
+	}
+
 	UpdateRemoteBuilder struct {
 		Organization Organization
 	}
@@ -230,6 +256,15 @@ type CreatedWireGuardPeer struct {
 	Pubkey     string `json:"pubkey"`
 }
 
+// LimitedAccessToken is an access token of an organization limited to what
+// its profile grants.
+type LimitedAccessToken struct {
+	ID        string
+	Name      string
+	Token     string
+	ExpiresAt time.Time
+}
+
 type DeleteOrganizationMembershipPayload struct {
 	Organization *Organization
 	User         *User
@@ -259,6 +294,7 @@ type MachineInit struct {
 	Entrypoint []string `json:"entrypoint"`
 	Cmd        []string `json:"cmd"`
 	Tty        bool     `json:"tty"`
+	SwapSizeMB *int     `json:"swap_size_mb,omitempty"`
 }
 
 func DefinitionPtr(in map[string]interface{}) *Definition {
@@ -359,6 +395,7 @@ type Snapshot struct {
 	ID        string `json:"id"`
 	Digest    string
 	Size      string
+	Status    string
 	CreatedAt time.Time
 }
 
@@ -382,6 +419,9 @@ type Volume struct {
 	Host               struct {
 		ID string
 	}
+	// SnapshotRetention is the number of days snapshots of the volume are
+	// kept for, or nil where the platform applies its default.
+	SnapshotRetention *int
 }
 
 type ProvisionAddOnInput struct {
@@ -409,6 +449,27 @@ type ExtendVolumeInput struct {
 	SizeGb   int    `json:"sizeGb"`
 }
 
+// ForkVolumeInput copies the volume SourceVolumeID, which may belong to another
+// app of the same organization, to a new volume of AppID.
+type ForkVolumeInput struct {
+	AppID          string `json:"appId"`
+	SourceVolumeID string `json:"sourceVolId"`
+	Name           string `json:"name"`
+	MachinesOnly   bool   `json:"machinesOnly"`
+}
+
+type ForkVolumePayload struct {
+	Volume Volume
+}
+
+type CreateVolumeSnapshotInput struct {
+	VolumeID string `json:"volumeId"`
+}
+
+type CreateVolumeSnapshotPayload struct {
+	Volume Volume
+}
+
 type CreateVolumePayload struct {
 	App    App
 	Volume Volume
@@ -452,6 +513,7 @@ type AppCompact struct {
 		Name string
 	}
 	ImageDetails ImageVersion
+	Network      string
 }
 
 func (app *AppCompact) IsPostgresApp() bool {
@@ -569,6 +631,10 @@ type Organization struct {
 	LoggedCertificates *struct {
 		Nodes []LoggedCertificate
 	}
+
+	Networks *struct {
+		Nodes []Network
+	}
 }
 
 func (o *Organization) GetID() string {
@@ -579,6 +645,27 @@ func (o *Organization) GetSlug() string {
 	return o.Slug
 }
 
+// Network is a custom 6PN network apps of an organization may be placed in,
+// isolating them from the organization's other apps.
+type Network struct {
+	ID   string
+	Name string
+	Apps struct {
+		Nodes []struct {
+			Name string
+		}
+	}
+}
+
+// AppNames returns the names of the apps placed in the network.
+func (n *Network) AppNames() []string {
+	names := make([]string, 0, len(n.Apps.Nodes))
+	for _, app := range n.Apps.Nodes {
+		names = append(names, app.Name)
+	}
+	return names
+}
+
 type OrganizationBasic struct {
 	ID   string
 	Slug string
@@ -732,6 +819,83 @@ type Release struct {
 	EvaluationID       string
 	CreatedAt          time.Time
 	ImageRef           string
+	Metadata           *ReleaseMetadata
+}
+
+// ReleaseMetadata holds the details flyctl records about a release.
+type ReleaseMetadata struct {
+	// ReleaseCommandInstanceID is the ID of the machine or VM which ran the
+	// release command, kept around so its logs may be found afterwards.
+	ReleaseCommandInstanceID string `json:"release_command_instance_id,omitempty"`
+	// GroupImages holds the images of the process groups which were built
+	// images of their own, by group, as references pinned to their digests.
+	GroupImages map[string]string `json:"group_images,omitempty"`
+	// SkippedRegions lists the regions whose machines a partial release left
+	// on their previous release.
+	SkippedRegions []string `json:"skipped_regions,omitempty"`
+	// OrphanedMachines lists the machines of process groups the release
+	// removed, along with what was done with them.
+	OrphanedMachines []OrphanedMachine `json:"orphaned_machines,omitempty"`
+	// Rollout is the progress of the rollout of the release to machines,
+	// recorded as it goes so that it may be followed from elsewhere.
+	Rollout *ReleaseRollout `json:"rollout,omitempty"`
+}
+
+// The states of machines in a rollout.
+const (
+	RolloutPending  = "pending"
+	RolloutUpdating = "updating"
+	RolloutDone     = "done"
+	RolloutFailed   = "failed"
+	RolloutSkipped  = "skipped"
+)
+
+// ReleaseRollout is the progress of the rollout of a release.
+type ReleaseRollout struct {
+	Image     string           `json:"image"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Machines  []RolloutMachine `json:"machines"`
+}
+
+// RolloutMachine is a machine of a rollout, along with its state in it.
+type RolloutMachine struct {
+	ID     string `json:"id"`
+	Region string `json:"region"`
+	State  string `json:"state"`
+}
+
+// Active reports whether any machine of the rollout is yet to be updated.
+func (r *ReleaseRollout) Active() bool {
+	for _, m := range r.Machines {
+		if m.State == RolloutPending || m.State == RolloutUpdating {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrphanedMachine is a machine of a process group a release removed.
+type OrphanedMachine struct {
+	ID     string `json:"id"`
+	Group  string `json:"group"`
+	Region string `json:"region"`
+	// Action is what was done with the machine: stopped, destroyed or kept.
+	Action string `json:"action"`
+}
+
+type CreateReleaseInput struct {
+	AppID           string      `json:"appId"`
+	Image           string      `json:"image"`
+	PlatformVersion string      `json:"platformVersion"`
+	Strategy        string      `json:"strategy"`
+	Definition      *Definition `json:"definition,omitempty"`
+}
+
+type UpdateReleaseInput struct {
+	ReleaseID string           `json:"releaseId"`
+	Status    string           `json:"status,omitempty"`
+	Metadata  *ReleaseMetadata `json:"metadata,omitempty"`
 }
 
 type Build struct {
@@ -967,6 +1131,8 @@ type Region struct {
 	Latitude         float32
 	Longitude        float32
 	GatewayAvailable bool
+	// GPUKinds lists the kinds of GPUs machines in the region may have.
+	GPUKinds []string
 }
 
 type AutoscalingConfig struct {
@@ -1008,7 +1174,8 @@ type VMSize struct {
 	MemoryMB    int
 	PriceMonth  float32
 	PriceSecond float32
-	// MemoryIncrementsMB []int
+	// MemoryIncrementsMB are the amounts of memory the size can be scaled to
+	MemoryIncrementsMB []int
 }
 
 type ProcessGroup struct {
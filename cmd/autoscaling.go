@@ -11,6 +11,7 @@ import (
 
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/docstrings"
+	"github.com/superfly/flyctl/internal/command"
 
 	"github.com/spf13/cobra"
 )
@@ -28,6 +29,24 @@ func newAutoscaleCommand(client *client.Client) *Command {
 	setCmdStrings := docstrings.Get("autoscale.set")
 	setCmd := BuildCommand(cmd, runSetParams, setCmdStrings.Usage, setCmdStrings.Short, setCmdStrings.Long, client, requireSession, requireAppName)
 	setCmd.Args = cobra.RangeArgs(0, 2)
+	setCmd.AddIntFlag(IntFlagOpts{
+		Name:        "min",
+		Description: "Minimum number of machines per region, for machines apps (advisory, not enforced)",
+	})
+	setCmd.AddIntFlag(IntFlagOpts{
+		Name:        "max",
+		Description: "Maximum number of machines per region, for machines apps (advisory, not enforced)",
+	})
+	setCmd.AddStringSliceFlag(StringSliceFlagOpts{
+		Name:        "region",
+		Shorthand:   "r",
+		Description: "Regions to apply the limits to, for machines apps (defaults to every region with machines)",
+	})
+	setCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "yes",
+		Shorthand:   "y",
+		Description: "Accept creating stopped machines to reach --min",
+	})
 
 	showCmdStrings := docstrings.Get("autoscale.show")
 	BuildCommand(cmd, runAutoscalingShow, showCmdStrings.Usage, showCmdStrings.Short, showCmdStrings.Long, client, requireSession, requireAppName)
@@ -36,12 +55,30 @@ func newAutoscaleCommand(client *client.Client) *Command {
 }
 
 func runSetParams(commandContext *cmdctx.CmdContext) error {
+	isMachine, err := command.CheckPlatform(commandContext.Client.API(), commandContext.Command.Context(), commandContext.AppName)
+	if err != nil {
+		return fmt.Errorf("failed to check platform version %w", err)
+	}
+
+	if isMachine {
+		return runMachinesAutoscaleSet(commandContext)
+	}
+
 	return actualScale(commandContext, false)
 }
 
 func runDisableAutoscaling(cmdCtx *cmdctx.CmdContext) error {
 	ctx := cmdCtx.Command.Context()
 
+	isMachine, err := command.CheckPlatform(cmdCtx.Client.API(), ctx, cmdCtx.AppName)
+	if err != nil {
+		return fmt.Errorf("failed to check platform version %w", err)
+	}
+
+	if isMachine {
+		return runMachinesAutoscaleDisable(cmdCtx)
+	}
+
 	newcfg := api.UpdateAutoscaleConfigInput{AppID: cmdCtx.AppName, Enabled: api.BoolPointer(false)}
 
 	cfg, err := cmdCtx.Client.API().UpdateAutoscaleConfig(ctx, newcfg)
@@ -129,6 +166,15 @@ func actualScale(cmdCtx *cmdctx.CmdContext, balanceRegions bool) error {
 func runAutoscalingShow(cmdCtx *cmdctx.CmdContext) error {
 	ctx := cmdCtx.Command.Context()
 
+	isMachine, err := command.CheckPlatform(cmdCtx.Client.API(), ctx, cmdCtx.AppName)
+	if err != nil {
+		return fmt.Errorf("failed to check platform version %w", err)
+	}
+
+	if isMachine {
+		return runMachinesAutoscaleShow(cmdCtx)
+	}
+
 	cfg, err := cmdCtx.Client.API().AppAutoscalingConfig(ctx, cmdCtx.AppName)
 	if err != nil {
 		return err
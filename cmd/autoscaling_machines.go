@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/helpers"
+	mach "github.com/superfly/flyctl/internal/machine"
+)
+
+// regionAutoscale is the autoscale policy of a region along with the number
+// of machines it has.
+type regionAutoscale struct {
+	Region   string `json:"region"`
+	Min      int    `json:"min"`
+	Max      int    `json:"max"`
+	Machines int    `json:"machines"`
+}
+
+type machinesAutoscale struct {
+	App     string            `json:"app"`
+	Enabled bool              `json:"enabled"`
+	Regions []regionAutoscale `json:"regions"`
+}
+
+func summarizeAutoscale(appName string, machines []*api.Machine) machinesAutoscale {
+	summary := machinesAutoscale{App: appName}
+
+	counts := map[string]int{}
+	for _, m := range machines {
+		counts[m.Region]++
+	}
+
+	for region, policy := range mach.AutoscaleByRegion(machines) {
+		summary.Regions = append(summary.Regions, regionAutoscale{
+			Region:   region,
+			Min:      policy.Min,
+			Max:      policy.Max,
+			Machines: counts[region],
+		})
+	}
+
+	sort.Slice(summary.Regions, func(i, j int) bool {
+		return summary.Regions[i].Region < summary.Regions[j].Region
+	})
+	summary.Enabled = len(summary.Regions) > 0
+
+	return summary
+}
+
+func printMachinesAutoscale(cmdCtx *cmdctx.CmdContext, summary machinesAutoscale) {
+	if cmdCtx.OutputJSON() {
+		prettyJSON, _ := json.MarshalIndent(summary, "", "    ")
+		fmt.Fprintln(cmdCtx.Out, string(prettyJSON))
+		return
+	}
+
+	if !summary.Enabled {
+		fmt.Fprintf(cmdCtx.Out, "%15s: %s\n", "Autoscaling", "Disabled")
+		return
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "%15s: %s\n", "Autoscaling", "Enabled (advisory limits, not enforced)")
+	for _, region := range summary.Regions {
+		fmt.Fprintf(cmdCtx.Out, "%15s: %d-%d machines (has %d)\n", region.Region, region.Min, region.Max, region.Machines)
+	}
+}
+
+// listAutoscaleMachines returns the machines of the app of cmdCtx along with
+// a context and flaps client to act on them with.
+func listAutoscaleMachines(cmdCtx *cmdctx.CmdContext) (context.Context, *flaps.Client, []*api.Machine, error) {
+	ctx := client.NewContext(cmdCtx.Command.Context(), cmdCtx.Client)
+
+	app, err := cmdCtx.Client.API().GetAppCompact(ctx, cmdCtx.AppName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return ctx, flapsClient, machines, nil
+}
+
+func runMachinesAutoscaleShow(cmdCtx *cmdctx.CmdContext) error {
+	_, _, machines, err := listAutoscaleMachines(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	printMachinesAutoscale(cmdCtx, summarizeAutoscale(cmdCtx.AppName, machines))
+
+	return nil
+}
+
+func runMachinesAutoscaleSet(cmdCtx *cmdctx.CmdContext) error {
+	if len(cmdCtx.Args) > 0 {
+		return errors.New("machines apps take --min and --max instead of min= and max= arguments")
+	}
+
+	flags := cmdCtx.Command.Flags()
+	if !flags.Changed("min") || !flags.Changed("max") {
+		return errors.New("--min and --max are required for machines apps")
+	}
+
+	policy := mach.AutoscalePolicy{
+		Min: cmdCtx.Config.GetInt("min"),
+		Max: cmdCtx.Config.GetInt("max"),
+	}
+	switch {
+	case policy.Min < 0:
+		return fmt.Errorf("--min must not be negative, got %d", policy.Min)
+	case policy.Max < 1:
+		return fmt.Errorf("--max must be at least 1, got %d", policy.Max)
+	case policy.Min > policy.Max:
+		return fmt.Errorf("--min %d is greater than --max %d", policy.Min, policy.Max)
+	}
+
+	ctx, flapsClient, machines, err := listAutoscaleMachines(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	if len(machines) == 0 {
+		return fmt.Errorf("app %s has no machines to autoscale; deploy it first", cmdCtx.AppName)
+	}
+
+	byRegion := map[string][]*api.Machine{}
+	for _, m := range machines {
+		byRegion[m.Region] = append(byRegion[m.Region], m)
+	}
+
+	regions := cmdCtx.Config.GetStringSlice("region")
+	if len(regions) == 0 {
+		for region := range byRegion {
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+
+	// Check that every region can be brought within the policy before
+	// touching any of them.
+	short := map[string]int{}
+	for _, region := range regions {
+		switch n := len(byRegion[region]); {
+		case n > policy.Max:
+			return fmt.Errorf("region %s has %d machines, more than --max %d; destroy some with `flyctl machine destroy` or raise --max", region, n, policy.Max)
+		case n < policy.Min:
+			template := autoscaleTemplate(byRegion[region], machines)
+			if len(template.Config.Mounts) > 0 {
+				return fmt.Errorf("region %s needs %d more machines, but machine %s mounts a volume; clone it into %s with `flyctl machine clone --volume` instead", region, policy.Min-n, template.ID, region)
+			}
+
+			short[region] = policy.Min - n
+			fmt.Fprintf(cmdCtx.Out, "Region %s has %d machines, %d short of --min %d\n", region, n, policy.Min-n, policy.Min)
+		}
+	}
+
+	if len(short) > 0 {
+		total := 0
+		for _, n := range short {
+			total += n
+		}
+
+		if !cmdCtx.Config.GetBool("yes") {
+			if !helpers.IsTerminal() {
+				return errors.New("creating machines up to --min requires confirmation; pass --yes when not running interactively")
+			}
+
+			if !confirm(fmt.Sprintf("Create %d stopped machines?", total)) {
+				return errors.New("--min can't be met without creating machines")
+			}
+		}
+	}
+
+	for _, region := range regions {
+		for i := 0; i < short[region]; i++ {
+			m, err := createAutoscaleMachine(ctx, flapsClient, cmdCtx.AppName, region, autoscaleTemplate(byRegion[region], machines), policy)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmdCtx.Out, "Created stopped machine %s in region %s\n", m.ID, region)
+			machines = append(machines, m)
+		}
+
+		for _, m := range byRegion[region] {
+			if err := setAutoscaleMetadata(ctx, flapsClient, m, policy); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintln(cmdCtx.Out, "The limits are advisory only; nothing creates or destroys machines later on to stay within them")
+
+	if !autostartEnabled(machines) {
+		fmt.Fprintln(cmdCtx.Out, aurora.Yellow("None of the services of this app start machines on demand; set auto_start_machines in fly.toml so load starts the stopped ones"))
+	}
+
+	printMachinesAutoscale(cmdCtx, summarizeAutoscale(cmdCtx.AppName, machines))
+
+	return nil
+}
+
+func runMachinesAutoscaleDisable(cmdCtx *cmdctx.CmdContext) error {
+	ctx, flapsClient, machines, err := listAutoscaleMachines(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range machines {
+		if _, ok := mach.Autoscale(m); !ok {
+			continue
+		}
+
+		for _, key := range []string{mach.AutoscaleMinMetadataKey, mach.AutoscaleMaxMetadataKey} {
+			if err := flapsClient.DeleteMetadata(ctx, m.ID, key); err != nil {
+				return err
+			}
+			delete(m.Config.Metadata, key)
+		}
+	}
+
+	printMachinesAutoscale(cmdCtx, summarizeAutoscale(cmdCtx.AppName, machines))
+
+	return nil
+}
+
+// autoscaleTemplate returns the machine to base new machines of a region on,
+// preferring one of the app process group of that region.
+func autoscaleTemplate(region, all []*api.Machine) *api.Machine {
+	if len(region) == 0 {
+		region = all
+	}
+
+	for _, m := range region {
+		if group := m.Config.Metadata["process_group"]; group == "" || group == "app" {
+			return m
+		}
+	}
+
+	return region[0]
+}
+
+func createAutoscaleMachine(ctx context.Context, flapsClient *flaps.Client, appName, region string, template *api.Machine, policy mach.AutoscalePolicy) (*api.Machine, error) {
+	config, err := mach.CloneConfig(*template.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Metadata == nil {
+		config.Metadata = map[string]string{}
+	}
+	config.Metadata[mach.AutoscaleMinMetadataKey] = strconv.Itoa(policy.Min)
+	config.Metadata[mach.AutoscaleMaxMetadataKey] = strconv.Itoa(policy.Max)
+
+	m, err := flapsClient.Launch(ctx, api.LaunchMachineInput{
+		AppID:      appName,
+		Region:     region,
+		Config:     config,
+		SkipLaunch: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create machine in region %s: %w", region, err)
+	}
+
+	return m, nil
+}
+
+func setAutoscaleMetadata(ctx context.Context, flapsClient *flaps.Client, m *api.Machine, policy mach.AutoscalePolicy) error {
+	values := map[string]string{
+		mach.AutoscaleMinMetadataKey: strconv.Itoa(policy.Min),
+		mach.AutoscaleMaxMetadataKey: strconv.Itoa(policy.Max),
+	}
+
+	for key, value := range values {
+		if err := flapsClient.SetMetadata(ctx, m.ID, key, value); err != nil {
+			return err
+		}
+
+		if m.Config.Metadata == nil {
+			m.Config.Metadata = map[string]string{}
+		}
+		m.Config.Metadata[key] = value
+	}
+
+	return nil
+}
+
+// autostartEnabled reports whether any service of any of machines starts
+// stopped machines on demand.
+func autostartEnabled(machines []*api.Machine) bool {
+	for _, m := range machines {
+		for _, service := range m.Config.Services {
+			if service.Autostart != nil && *service.Autostart {
+				return true
+			}
+		}
+	}
+
+	return false
+}
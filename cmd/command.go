@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/buildinfo"
 	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/terminal"
@@ -228,7 +230,7 @@ func requireSession(cmd *Command) Initializer {
 	return Initializer{
 		PreRun: func(ctx *cmdctx.CmdContext) error {
 			if !ctx.Client.Authenticated() {
-				return client.ErrNoAuthToken
+				return flyerr.WithCode(flyerr.CodeUnauthorized, client.ErrNoAuthToken)
 			}
 			return nil
 		},
@@ -245,7 +247,7 @@ func addAppConfigFlags(cmd *Command) {
 	cmd.AddStringFlag(StringFlagOpts{
 		Name:        "config",
 		Shorthand:   "c",
-		Description: "Path to an app config file or directory containing one",
+		Description: "Path to an app config file or directory containing one, an https:// URL to fetch it from, or - to read it from stdin",
 		Default:     defaultConfigFilePath,
 		EnvName:     "FLY_APP_CONFIG",
 	})
@@ -254,6 +256,9 @@ func addAppConfigFlags(cmd *Command) {
 func setupAppName(ctx *cmdctx.CmdContext) error {
 	// resolve the config file path
 	configPath := ctx.Config.GetString("config")
+	if app.IsRemoteSource(configPath) {
+		return setupRemoteAppConfig(ctx, configPath)
+	}
 	if configPath == "" {
 		configPath = defaultConfigFilePath
 	}
@@ -293,6 +298,27 @@ func setupAppName(ctx *cmdctx.CmdContext) error {
 	return nil
 }
 
+// setupRemoteAppConfig loads the app config from source, which is either stdin
+// or a URL. Commands writing the config back write it to the working directory.
+func setupRemoteAppConfig(ctx *cmdctx.CmdContext, source string) error {
+	data, err := app.ReadRemoteSource(ctx.Command.Context(), source, ctx.IO.In)
+	if err == nil {
+		ctx.AppConfig, err = flyctl.LoadAppConfigFromReader(bytes.NewReader(data))
+	}
+	if err != nil {
+		return fmt.Errorf("failed loading app config from %s: %w", app.DescribeSource(source), err)
+	}
+	terminal.Debug("Loaded app config from", app.DescribeSource(source))
+
+	ctx.ConfigFile = filepath.Join(ctx.WorkingDir, defaultConfigFilePath)
+
+	if ctx.AppName = ctx.Config.GetString("app"); ctx.AppName == "" {
+		ctx.AppName = ctx.AppConfig.AppName
+	}
+
+	return nil
+}
+
 func requireAppName(cmd *Command) Initializer {
 	// TODO: Add Flags to docStrings
 
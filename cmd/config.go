@@ -7,6 +7,8 @@ import (
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/cmd/presenters"
 	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
 
 	"github.com/superfly/flyctl/docstrings"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/machine"
 )
 
 func newConfigCommand(client *client.Client) *Command {
@@ -21,14 +24,25 @@ func newConfigCommand(client *client.Client) *Command {
 
 	cmd := BuildCommandKS(nil, nil, configStrings, client, requireSession, requireAppName)
 
+	fromMachinesFlag := BoolFlagOpts{
+		Name:        "from-machines",
+		Description: "Synthesize the config from the app's current machines, for machines apps",
+	}
+
 	configDisplayStrings := docstrings.Get("config.display")
-	BuildCommandKS(cmd, runDisplayConfig, configDisplayStrings, client, requireSession, requireAppName)
+	displayCmd := BuildCommandKS(cmd, runDisplayConfig, configDisplayStrings, client, requireSession, requireAppName)
+	displayCmd.AddBoolFlag(fromMachinesFlag)
 
 	configSaveStrings := docstrings.Get("config.save")
-	BuildCommandKS(cmd, runSaveConfig, configSaveStrings, client, requireSession, requireAppName)
+	saveCmd := BuildCommandKS(cmd, runSaveConfig, configSaveStrings, client, requireSession, requireAppName)
+	saveCmd.AddBoolFlag(fromMachinesFlag)
 
 	configValidateStrings := docstrings.Get("config.validate")
-	BuildCommandKS(cmd, runValidateConfig, configValidateStrings, client, requireAppName)
+	validateCmd := BuildCommandKS(cmd, runValidateConfig, configValidateStrings, client, requireAppName)
+	validateCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "skip-secret-validation",
+		Description: "Don't check that the secrets the config refers to are set on the app",
+	})
 
 	configEnvStrings := docstrings.Get("config.env")
 	BuildCommandKS(cmd, runEnvConfig, configEnvStrings, client, requireSession, requireAppName)
@@ -39,6 +53,15 @@ func newConfigCommand(client *client.Client) *Command {
 func runDisplayConfig(cmdCtx *cmdctx.CmdContext) error {
 	ctx := cmdCtx.Command.Context()
 
+	if cmdCtx.Config.GetBool("from-machines") {
+		cfg, err := configFromMachines(cmdCtx)
+		if err != nil {
+			return err
+		}
+
+		return cfg.EncodeTo(cmdCtx.IO.Out)
+	}
+
 	cfg, err := cmdCtx.Client.API().GetConfig(ctx, cmdCtx.AppName)
 	if err != nil {
 		return err
@@ -67,6 +90,21 @@ func runSaveConfig(cmdCtx *cmdctx.CmdContext) error {
 		}
 	}
 
+	if cmdCtx.Config.GetBool("from-machines") {
+		cfg, err := configFromMachines(cmdCtx)
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.WriteToFile(configfilename); err != nil {
+			return err
+		}
+
+		fmt.Println("Wrote config file", helpers.PathRelativeToCWD(configfilename))
+
+		return nil
+	}
+
 	if cmdCtx.AppConfig == nil {
 		cmdCtx.AppConfig = flyctl.NewAppConfig()
 	}
@@ -82,6 +120,46 @@ func runSaveConfig(cmdCtx *cmdctx.CmdContext) error {
 	return writeAppConfig(cmdCtx.ConfigFile, cmdCtx.AppConfig)
 }
 
+// configFromMachines synthesizes the config of a machines app from its
+// machines, printing a warning for each setting they disagree on.
+func configFromMachines(cmdCtx *cmdctx.CmdContext) (*app.Config, error) {
+	ctx := client.NewContext(cmdCtx.Command.Context(), cmdCtx.Client)
+
+	appCompact, err := cmdCtx.Client.API().GetAppCompact(ctx, cmdCtx.AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	if appCompact.PlatformVersion != app.MachinesPlatform {
+		return nil, fmt.Errorf("--from-machines is only available for machines apps, and %s isn't one", appCompact.Name)
+	}
+
+	flapsClient, err := flaps.New(ctx, appCompact)
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(machines) == 0 {
+		return nil, fmt.Errorf("app %s has no machines to synthesize a config from", appCompact.Name)
+	}
+
+	cfg, warnings := app.FromMachines(appCompact.Name, machines)
+	for _, warning := range warnings {
+		fmt.Fprintln(cmdCtx.IO.ErrOut, aurora.Yellow("WARN"), warning)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("the synthesized config is not valid: %w", err)
+	}
+
+	return cfg, nil
+}
+
 func runValidateConfig(commandContext *cmdctx.CmdContext) error {
 	ctx := commandContext.Command.Context()
 
@@ -101,14 +179,47 @@ func runValidateConfig(commandContext *cmdctx.CmdContext) error {
 		commandContext.WriteJSON(serverCfg.Definition)
 	}
 
-	if serverCfg.Valid {
-		fmt.Println(aurora.Green("✓").String(), "Configuration is valid")
+	if !serverCfg.Valid {
+		printAppConfigErrors(*serverCfg)
+
+		return errors.New("App configuration is not valid")
+	}
+
+	if !commandContext.Config.GetBool("skip-secret-validation") {
+		if err := validateConfigSecrets(commandContext); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(aurora.Green("✓").String(), "Configuration is valid")
+	return nil
+}
+
+// validateConfigSecrets checks that the secrets the config of a machines app
+// refers to are set on it. Without a session there are no secrets to check
+// against, so it's skipped.
+func validateConfigSecrets(cmdCtx *cmdctx.CmdContext) error {
+	if !cmdCtx.Client.Authenticated() {
 		return nil
 	}
 
-	printAppConfigErrors(*serverCfg)
+	ctx := client.NewContext(cmdCtx.Command.Context(), cmdCtx.Client)
+
+	basicApp, err := cmdCtx.Client.API().GetAppBasic(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	if basicApp.PlatformVersion != app.MachinesPlatform {
+		return nil
+	}
+
+	cfg, err := app.LoadConfig(ctx, cmdCtx.ConfigFile, app.MachinesPlatform)
+	if err != nil {
+		return err
+	}
 
-	return errors.New("App configuration is not valid")
+	return machine.ValidateSecrets(ctx, cmdCtx.AppName, cfg.ReferencedSecrets())
 }
 
 func runEnvConfig(cmdCtx *cmdctx.CmdContext) error {
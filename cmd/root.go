@@ -59,7 +59,6 @@ func NewRootCmd(client *client.Client) *cobra.Command {
 	rootCmd.AddCommand(
 		newCertificatesCommand(client),
 		newConfigCommand(client),
-		newDashboardCommand(client),
 		newListCommand(client),
 		newRegionsCommand(client),
 		newScaleCommand(client),
@@ -169,7 +169,7 @@ func runScaleShow(cmdCtx *cmdctx.CmdContext) error {
 	}
 
 	if isMachine {
-		return fmt.Errorf("it looks like your app is running on v2 of our platform, and does not support this legacy command: try running fly machine status instead")
+		return runMachinesScaleShow(cmdCtx)
 	}
 
 	size, tgCounts, processGroups, err := cmdCtx.Client.API().AppVMResources(ctx, cmdCtx.AppName)
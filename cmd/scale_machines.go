@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/flaps"
+)
+
+// machineGuestSize is a guest size along with the number of machines using it.
+// Machines without a guest share the zero size, which is unknown.
+type machineGuestSize struct {
+	CPUKind  string `json:"cpu_kind"`
+	CPUs     int    `json:"cpus"`
+	MemoryMB int    `json:"memory_mb"`
+	Count    int    `json:"count"`
+}
+
+func (s machineGuestSize) String() string {
+	if s.unknown() {
+		return "-"
+	}
+
+	return fmt.Sprintf("%s-cpu-%dx, %d MB", s.CPUKind, s.CPUs, s.MemoryMB)
+}
+
+func (s machineGuestSize) unknown() bool {
+	return s.CPUKind == "" && s.CPUs == 0 && s.MemoryMB == 0
+}
+
+// machineProcessGroup summarizes the machines of a single process group.
+type machineProcessGroup struct {
+	Name       string             `json:"name"`
+	Count      int                `json:"count"`
+	Sizes      []machineGuestSize `json:"sizes"`
+	MixedSizes bool               `json:"mixed_sizes"`
+	Regions    map[string]int     `json:"regions"`
+}
+
+type machineScaleSummary struct {
+	App     string                `json:"app"`
+	Groups  []machineProcessGroup `json:"groups"`
+	Count   int                   `json:"count"`
+	Regions map[string]int        `json:"regions"`
+}
+
+func summarizeMachines(appName string, machines []*api.Machine) machineScaleSummary {
+	summary := machineScaleSummary{
+		App:     appName,
+		Regions: map[string]int{},
+	}
+
+	groups := map[string]*machineProcessGroup{}
+	for _, m := range machines {
+		var name string
+		if m.Config != nil {
+			name = m.Config.Metadata["process_group"]
+		}
+		if name == "" {
+			name = "app"
+		}
+
+		group, ok := groups[name]
+		if !ok {
+			group = &machineProcessGroup{Name: name, Regions: map[string]int{}}
+			groups[name] = group
+		}
+
+		group.Count++
+		group.Regions[m.Region]++
+		summary.Count++
+		summary.Regions[m.Region]++
+
+		var size machineGuestSize
+		if m.Config != nil && m.Config.Guest != nil {
+			guest := m.Config.Guest
+			size = machineGuestSize{CPUKind: guest.CPUKind, CPUs: guest.CPUs, MemoryMB: guest.MemoryMB}
+		}
+
+		found := false
+		for i := range group.Sizes {
+			if s := &group.Sizes[i]; s.CPUKind == size.CPUKind && s.CPUs == size.CPUs && s.MemoryMB == size.MemoryMB {
+				s.Count++
+				found = true
+				break
+			}
+		}
+		if !found {
+			size.Count = 1
+			group.Sizes = append(group.Sizes, size)
+		}
+	}
+
+	for _, group := range groups {
+		group.MixedSizes = len(group.Sizes) > 1
+		sort.Slice(group.Sizes, func(i, j int) bool {
+			return group.Sizes[i].Count > group.Sizes[j].Count
+		})
+		summary.Groups = append(summary.Groups, *group)
+	}
+
+	sort.Slice(summary.Groups, func(i, j int) bool {
+		return summary.Groups[i].Name < summary.Groups[j].Name
+	})
+
+	return summary
+}
+
+func formatRegionCounts(regions map[string]int) string {
+	names := make([]string, 0, len(regions))
+	for name := range regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, regions[name]))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func runMachinesScaleShow(cmdCtx *cmdctx.CmdContext) error {
+	ctx := client.NewContext(cmdCtx.Command.Context(), cmdCtx.Client)
+
+	app, err := cmdCtx.Client.API().GetAppCompact(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	summary := summarizeMachines(app.Name, machines)
+
+	if cmdCtx.OutputJSON() {
+		prettyJSON, _ := json.MarshalIndent(summary, "", "    ")
+		fmt.Fprintln(cmdCtx.Out, string(prettyJSON))
+		return nil
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "VM Resources for %s\n", app.Name)
+
+	if summary.Count == 0 {
+		fmt.Fprintln(cmdCtx.Out, "No machines are running for this app")
+		return nil
+	}
+
+	for _, group := range summary.Groups {
+		fmt.Fprintf(cmdCtx.Out, "\nProcess group %s\n", group.Name)
+		fmt.Fprintf(cmdCtx.Out, "%15s: %d\n", "Count", group.Count)
+
+		if !group.MixedSizes {
+			fmt.Fprintf(cmdCtx.Out, "%15s: %s\n", "VM Size", group.Sizes[0])
+		} else {
+			for i, size := range group.Sizes {
+				label := ""
+				if i == 0 {
+					label = "VM Sizes"
+				}
+				fmt.Fprintf(cmdCtx.Out, "%15s: %s (%d machines)\n", label, size, size.Count)
+			}
+		}
+
+		fmt.Fprintf(cmdCtx.Out, "%15s: %s\n", "Regions", formatRegionCounts(group.Regions))
+
+		if group.MixedSizes {
+			fmt.Fprintln(cmdCtx.Out, aurora.Yellow(fmt.Sprintf("Machines in process group %s have mixed sizes; run `flyctl machine update` to bring them in line", group.Name)))
+		}
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "\n%d machines across %d regions: %s\n", summary.Count, len(summary.Regions), formatRegionCounts(summary.Regions))
+
+	return nil
+}
@@ -11,8 +11,10 @@ import (
 	"os"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/dustin/go-humanize"
 	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -23,8 +25,10 @@ import (
 	"github.com/superfly/flyctl/cmdctx"
 	"github.com/superfly/flyctl/docstrings"
 	"github.com/superfly/flyctl/flyctl"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/wireguard"
 	"github.com/superfly/flyctl/terminal"
+	"github.com/superfly/flyctl/wg"
 )
 
 func newWireGuardCommand(client *client.Client) *Command {
@@ -35,8 +39,43 @@ func newWireGuardCommand(client *client.Client) *Command {
 		return BuildCommandKS(parent, fn, docstrings.Get(ds), client, requireSession)
 	}
 
-	child(cmd, runWireGuardList, "wireguard.list").Args = cobra.MaximumNArgs(1)
-	child(cmd, runWireGuardCreate, "wireguard.create").Args = cobra.MaximumNArgs(4)
+	list := child(cmd, runWireGuardList, "wireguard.list")
+	list.Args = cobra.MaximumNArgs(1)
+	list.AddBoolFlag(BoolFlagOpts{
+		Name:        "stale",
+		Description: "Check each peer's last handshake and highlight stale peers",
+	})
+	list.AddIntFlag(IntFlagOpts{
+		Name:        "days",
+		Description: "Number of days without a handshake after which a peer is stale",
+		Default:     defaultStaleDays,
+	})
+
+	create := child(cmd, runWireGuardCreate, "wireguard.create")
+	create.Args = cobra.MaximumNArgs(4)
+	create.AddBoolFlag(BoolFlagOpts{
+		Name:        "ephemeral",
+		Description: "Have the peer removed automatically once its TTL expires",
+	})
+	create.AddStringFlag(StringFlagOpts{
+		Name:        "ttl",
+		Description: "How long an ephemeral peer lives for, e.g. 30m or 2h",
+		Default:     "24h",
+	})
+
+	prune := child(cmd, runWireGuardPrune, "wireguard.prune")
+	prune.Args = cobra.MaximumNArgs(1)
+	prune.AddIntFlag(IntFlagOpts{
+		Name:        "days",
+		Description: "Number of days without a handshake after which a peer is stale",
+		Default:     defaultStaleDays,
+	})
+	prune.AddBoolFlag(BoolFlagOpts{
+		Name:        "yes",
+		Shorthand:   "y",
+		Description: "accept all confirmations",
+	})
+
 	child(cmd, runWireGuardRemove, "wireguard.remove").Args = cobra.MaximumNArgs(2)
 	child(cmd, runWireGuardStat, "wireguard.status").Args = cobra.MaximumNArgs(2)
 	child(cmd, runWireGuardResetPeer, "wireguard.reset").Args = cobra.MaximumNArgs(1)
@@ -106,8 +145,36 @@ func runWireGuardList(cmdCtx *cmdctx.CmdContext) error {
 		return err
 	}
 
+	if !cmdCtx.Config.GetBool("stale") {
+		if cmdCtx.OutputJSON() {
+			cmdCtx.WriteJSON(peers)
+			return nil
+		}
+
+		table := tablewriter.NewWriter(cmdCtx.Out)
+
+		table.SetHeader([]string{
+			"Name",
+			"Region",
+			"Peer IP",
+		})
+
+		for _, peer := range peers {
+			table.Append([]string{peer.Name, peer.Region, peer.Peerip})
+		}
+
+		table.Render()
+
+		return nil
+	}
+
+	activity, err := fetchWireGuardPeerActivity(ctx, client, org.Slug, peers, staleDays(cmdCtx))
+	if err != nil {
+		return err
+	}
+
 	if cmdCtx.OutputJSON() {
-		cmdCtx.WriteJSON(peers)
+		cmdCtx.WriteJSON(activity)
 		return nil
 	}
 
@@ -117,14 +184,158 @@ func runWireGuardList(cmdCtx *cmdctx.CmdContext) error {
 		"Name",
 		"Region",
 		"Peer IP",
+		"Last Handshake",
 	})
 
-	for _, peer := range peers {
-		table.Append([]string{peer.Name, peer.Region, peer.Peerip})
+	var stale int
+	for _, a := range activity {
+		row := []string{a.Peer.Name, a.Peer.Region, a.Peer.Peerip, a.lastSeen()}
+		if !a.Stale {
+			table.Append(row)
+			continue
+		}
+
+		stale++
+		colors := make([]tablewriter.Colors, len(row))
+		for i := range colors {
+			colors[i] = tablewriter.Colors{tablewriter.FgYellowColor}
+		}
+		table.Rich(row, colors)
 	}
 
 	table.Render()
 
+	if stale > 0 {
+		fmt.Fprintf(cmdCtx.Out, "\n%d of %d peers are stale; remove them with `flyctl wireguard prune %s`\n", stale, len(activity), org.Slug)
+	}
+
+	return nil
+}
+
+const defaultStaleDays = 7
+
+// wireGuardPeerActivity describes when a peer was last seen by its gateway.
+type wireGuardPeerActivity struct {
+	Peer *api.WireGuardPeer
+
+	// LastSeen is the peer's last handshake or, for peers which never
+	// completed one, the time it was installed on the gateway. It's zero when
+	// neither is known.
+	LastSeen time.Time
+	Stale    bool
+}
+
+func (a *wireGuardPeerActivity) lastSeen() string {
+	if a.LastSeen.IsZero() {
+		return "unknown"
+	}
+	return humanize.Time(a.LastSeen)
+}
+
+func staleDays(cmdCtx *cmdctx.CmdContext) int {
+	if days := cmdCtx.Config.GetInt("days"); days > 0 {
+		return days
+	}
+	return defaultStaleDays
+}
+
+// fetchWireGuardPeerActivity looks up the gateway status of each peer. Peers
+// whose status can't be determined are never reported as stale.
+func fetchWireGuardPeerActivity(ctx context.Context, client *api.Client, slug string, peers []*api.WireGuardPeer, days int) ([]*wireGuardPeerActivity, error) {
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+
+	activity := make([]*wireGuardPeerActivity, 0, len(peers))
+	for _, peer := range peers {
+		status, err := client.GetWireGuardPeerStatus(ctx, slug, peer.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed fetching status of peer %s: %w", peer.Name, err)
+		}
+
+		a := &wireGuardPeerActivity{Peer: peer}
+		if status != nil {
+			if t, ok := parseWireGuardTime(status.LastHandshake); ok {
+				a.LastSeen = t
+			} else if t, ok := parseWireGuardTime(status.Added); ok {
+				a.LastSeen = t
+			}
+		}
+		a.Stale = !a.LastSeen.IsZero() && a.LastSeen.Before(cutoff)
+
+		activity = append(activity, a)
+	}
+
+	return activity, nil
+}
+
+func parseWireGuardTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 -0700 MST", "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, s); err == nil && !t.IsZero() && t.Unix() > 0 {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func runWireGuardPrune(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	client := cmdCtx.Client.API()
+
+	org, err := orgByArg(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	peers, err := client.GetWireGuardPeers(ctx, org.Slug)
+	if err != nil {
+		return err
+	}
+
+	// the agent's own peer is off limits, no matter how quiet it's been
+	agentPeer, err := wireguard.AgentPeerName(org.Slug)
+	if err != nil {
+		return err
+	}
+
+	days := staleDays(cmdCtx)
+
+	activity, err := fetchWireGuardPeerActivity(ctx, client, org.Slug, peers, days)
+	if err != nil {
+		return err
+	}
+
+	var stale []*wireGuardPeerActivity
+	for _, a := range activity {
+		if a.Stale && a.Peer.Name != agentPeer {
+			stale = append(stale, a)
+		}
+	}
+
+	if len(stale) == 0 {
+		fmt.Fprintf(cmdCtx.Out, "No peers in organization %s have gone without a handshake for more than %d days\n", org.Slug, days)
+		return nil
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Found %d stale peers in organization %s:\n", len(stale), org.Slug)
+	for _, a := range stale {
+		fmt.Fprintf(cmdCtx.Out, "  %s (%s, last seen %s)\n", a.Peer.Name, a.Peer.Region, a.lastSeen())
+	}
+
+	if !cmdCtx.Config.GetBool("yes") && !confirm(fmt.Sprintf("Remove %d peers?", len(stale))) {
+		return nil
+	}
+
+	var removed int
+	for _, a := range stale {
+		if err := client.RemoveWireGuardPeer(ctx, org, a.Peer.Name); err != nil {
+			fmt.Fprintf(cmdCtx.IO.ErrOut, "Failed removing peer %s: %s\n", a.Peer.Name, err)
+			continue
+		}
+		removed++
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Removed %d of %d stale peers.\n", removed, len(stale))
+
 	return nil
 }
 
@@ -275,11 +486,33 @@ func runWireGuardCreate(ctx *cmdctx.CmdContext) error {
 		name = ctx.Args[2]
 	}
 
-	state, err := wireguard.Create(ctx.Client.API(), org, region, name)
+	if !ctx.Config.GetBool("ephemeral") && ctx.Command.Flags().Changed("ttl") {
+		return flyerr.WithCode(flyerr.CodeValidation, errors.New("--ttl only applies to ephemeral peers; pass --ephemeral along with it"))
+	}
+
+	var ttl time.Duration
+	if ctx.Config.GetBool("ephemeral") {
+		if ttl, err = time.ParseDuration(ctx.Config.GetString("ttl")); err != nil {
+			return fmt.Errorf("invalid ttl: %w", err)
+		} else if ttl <= 0 {
+			return fmt.Errorf("ttl must be positive")
+		}
+	}
+
+	var state *wg.WireGuardState
+	if ttl > 0 {
+		state, err = wireguard.CreateEphemeral(ctx.Client.API(), org, region, name, ttl)
+	} else {
+		state, err = wireguard.Create(ctx.Client.API(), org, region, name)
+	}
 	if err != nil {
 		return err
 	}
 
+	if ttl > 0 {
+		fmt.Printf("Peer %s is ephemeral and will be removed at %s\n", state.Name, time.Now().Add(ttl).UTC().Format(time.RFC3339))
+	}
+
 	data := &state.Peer
 
 	fmt.Printf(`
@@ -128,7 +128,15 @@ authenticated and in use.`,
 			`Enable autoscaling and set the application's autoscaling parameters:
 
 min=int - minimum number of instances to be allocated globally.
-max=int - maximum number of instances to be allocated globally.`,
+max=int - maximum number of instances to be allocated globally.
+
+For machines apps, set the number of machines each region should have with
+--min and --max instead, limited to some regions with --region. Regions short
+of --min get stopped machines cloned from their existing ones, for the proxy
+to start under load should auto_start_machines be set in fly.toml. Beyond
+that, the limits are advisory only: they're recorded for autoscale show, but
+nothing enforces them, so machines are neither created nor destroyed later on
+to stay within them.`,
 		}
 	case "autoscale.show":
 		return KeyStrings{"show", "Show current autoscaling configuration",
@@ -808,11 +816,20 @@ number to operate. This can be found through the volumes list command`,
 		}
 	case "wireguard.create":
 		return KeyStrings{"create [org] [region] [name]", "Add a WireGuard peer connection",
-			`Add a WireGuard peer connection to an organization`,
+			`Add a WireGuard peer connection to an organization. Peers created
+with --ephemeral are removed automatically once their --ttl expires.`,
 		}
 	case "wireguard.list":
 		return KeyStrings{"list [<org>]", "List all WireGuard peer connections",
-			`List all WireGuard peer connections`,
+			`List all WireGuard peer connections. With --stale, each peer's
+gateway status is checked and peers without a handshake for more than
+--days days are highlighted.`,
+		}
+	case "wireguard.prune":
+		return KeyStrings{"prune [org]", "Remove stale WireGuard peer connections",
+			`Remove WireGuard peers that have gone without a handshake for more
+than --days days, after confirmation. The peer used by the local agent is
+never removed.`,
 		}
 	case "wireguard.remove":
 		return KeyStrings{"remove [org] [name]", "Remove a WireGuard peer connection",
@@ -4,13 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httputil"
 	"strings"
 
+	"github.com/oklog/ulid/v2"
 	"github.com/samber/lo"
 
 	"github.com/superfly/flyctl/agent"
@@ -23,6 +24,10 @@ import (
 
 var NonceHeader = "fly-machine-lease-nonce"
 
+// IdempotencyKeyHeader carries the key the Machines API deduplicates requests
+// creating, updating and starting machines by.
+var IdempotencyKeyHeader = "fly-idempotency-key"
+
 type Client struct {
 	app        *api.AppCompact
 	peerIP     string
@@ -83,8 +88,13 @@ func (f *Client) Launch(ctx context.Context, builder api.LaunchMachineInput) (*a
 
 	out := new(api.Machine)
 
-	if err := f.sendRequest(ctx, http.MethodPost, endpoint, builder, out, nil); err != nil {
-		return nil, fmt.Errorf("failed to launch VM: %w", err)
+	key := idempotencyKey(ctx, "launch")
+	headers := map[string][]string{
+		IdempotencyKeyHeader: {key},
+	}
+
+	if err := f.sendRequest(ctx, http.MethodPost, endpoint, builder, out, headers); err != nil {
+		return nil, fmt.Errorf("failed to launch VM (idempotency key %s): %w", key, err)
 	}
 
 	return out, nil
@@ -97,12 +107,15 @@ func (f *Client) Update(ctx context.Context, builder api.LaunchMachineInput, non
 		headers[NonceHeader] = []string{nonce}
 	}
 
+	key := idempotencyKey(ctx, "update")
+	headers[IdempotencyKeyHeader] = []string{key}
+
 	endpoint := fmt.Sprintf("/%s", builder.ID)
 
 	out := new(api.Machine)
 
 	if err := f.sendRequest(ctx, http.MethodPost, endpoint, builder, out, headers); err != nil {
-		return nil, fmt.Errorf("failed to update VM %s: %w", builder.ID, err)
+		return nil, fmt.Errorf("failed to update VM %s (idempotency key %s): %w", builder.ID, key, err)
 	}
 	return out, nil
 }
@@ -112,8 +125,13 @@ func (f *Client) Start(ctx context.Context, machineID string) (*api.MachineStart
 
 	out := new(api.MachineStartResponse)
 
-	if err := f.sendRequest(ctx, http.MethodPost, startEndpoint, nil, out, nil); err != nil {
-		return nil, fmt.Errorf("failed to start VM %s: %w", machineID, err)
+	key := idempotencyKey(ctx, "start")
+	headers := map[string][]string{
+		IdempotencyKeyHeader: {key},
+	}
+
+	if err := f.sendRequest(ctx, http.MethodPost, startEndpoint, nil, out, headers); err != nil {
+		return nil, fmt.Errorf("failed to start VM %s (idempotency key %s): %w", machineID, key, err)
 	}
 	return out, nil
 }
@@ -186,6 +204,20 @@ func (f *Client) Get(ctx context.Context, machineID string) (*api.Machine, error
 	return out, nil
 }
 
+// GetVersions returns the configs the machine identified by machineID was
+// given over time.
+func (f *Client) GetVersions(ctx context.Context, machineID string) ([]*api.MachineVersion, error) {
+	endpoint := fmt.Sprintf("/%s/versions", machineID)
+
+	var out []*api.MachineVersion
+
+	if err := f.sendRequest(ctx, http.MethodGet, endpoint, nil, &out, nil); err != nil {
+		return nil, fmt.Errorf("failed to get config versions of VM %s: %w", machineID, err)
+	}
+
+	return out, nil
+}
+
 func (f *Client) GetMany(ctx context.Context, machineIDs []string) ([]*api.Machine, error) {
 	machines := make([]*api.Machine, 0, len(machineIDs))
 	for _, id := range machineIDs {
@@ -282,6 +314,56 @@ func (f *Client) AcquireLease(ctx context.Context, machineID string, ttl *int) (
 	return out, nil
 }
 
+// RefreshLease extends the lease identified by nonce on the machine by ttl
+// seconds from now.
+func (f *Client) RefreshLease(ctx context.Context, machineID string, ttl *int, nonce string) (*api.MachineLease, error) {
+	endpoint := fmt.Sprintf("/%s/lease", machineID)
+
+	if ttl != nil {
+		endpoint += fmt.Sprintf("?ttl=%d", *ttl)
+	}
+
+	headers := map[string][]string{
+		NonceHeader: {nonce},
+	}
+
+	out := new(api.MachineLease)
+
+	err := f.sendRequest(ctx, http.MethodPost, endpoint, nil, out, headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh lease on VM %s: %w", machineID, err)
+	}
+	return out, nil
+}
+
+// SetMetadata sets the metadata key of the machine to value without updating
+// the machine otherwise, so that it keeps running.
+func (f *Client) SetMetadata(ctx context.Context, machineID, key, value string) error {
+	endpoint := fmt.Sprintf("/%s/metadata/%s", machineID, key)
+
+	in := map[string]string{
+		"value": value,
+	}
+
+	if err := f.sendRequest(ctx, http.MethodPost, endpoint, in, nil, nil); err != nil {
+		return fmt.Errorf("failed to set metadata %s of VM %s: %w", key, machineID, err)
+	}
+
+	return nil
+}
+
+// DeleteMetadata removes the metadata key of the machine without updating the
+// machine otherwise.
+func (f *Client) DeleteMetadata(ctx context.Context, machineID, key string) error {
+	endpoint := fmt.Sprintf("/%s/metadata/%s", machineID, key)
+
+	if err := f.sendRequest(ctx, http.MethodDelete, endpoint, nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete metadata %s of VM %s: %w", key, machineID, err)
+	}
+
+	return nil
+}
+
 func (f *Client) ReleaseLease(ctx context.Context, machineID, nonce string) error {
 	endpoint := fmt.Sprintf("/%s/lease", machineID)
 
@@ -294,6 +376,19 @@ func (f *Client) ReleaseLease(ctx context.Context, machineID, nonce string) erro
 	return f.sendRequest(ctx, http.MethodDelete, endpoint, nil, nil, headers)
 }
 
+// idempotencyKey returns a new key for one call of op. The key is set on the
+// request itself, so the retrying transport sends it on every attempt and the
+// API carries out the operation once however many attempts reach it.
+func idempotencyKey(ctx context.Context, op string) string {
+	key := ulid.Make().String()
+
+	if logger := logger.MaybeFromContext(ctx); logger != nil {
+		logger.Debugf("flaps: %s with idempotency key %s", op, key)
+	}
+
+	return key
+}
+
 func (f *Client) sendRequest(ctx context.Context, method, endpoint string, in, out interface{}, headers map[string][]string) error {
 	req, err := f.NewRequest(ctx, method, endpoint, in, headers)
 	if err != nil {
@@ -350,25 +445,67 @@ func (f *Client) NewRequest(ctx context.Context, method, path string, in interfa
 	return req, nil
 }
 
+// NewReverseProxy returns an http.Handler which forwards requests to the
+// Machines API over the client's tunnel, authenticating them as the current
+// user. Request paths are passed through unchanged.
+func (f *Client) NewReverseProxy() *httputil.ReverseProxy {
+	host := net.JoinHostPort(f.peerIP, "4280")
+
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = "http"
+			req.URL.Host = host
+			req.Host = host
+
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", f.authToken))
+			req.Header.Set("User-Agent", f.userAgent)
+		},
+		Transport: f.httpClient.Transport,
+	}
+}
+
+// FlapsError is an error response of the Machines API.
+type FlapsError struct {
+	Message      string
+	Status       int
+	FlyRequestID string
+}
+
+func (e *FlapsError) Error() string { return e.Message }
+
+// StatusCode returns the HTTP status code of the response.
+func (e *FlapsError) StatusCode() int { return e.Status }
+
+// RequestID returns the ID the API assigned the failed request, if any.
+func (e *FlapsError) RequestID() string { return e.FlyRequestID }
+
 func handleAPIError(resp *http.Response) error {
+	apiErr := &FlapsError{
+		Status:       resp.StatusCode,
+		FlyRequestID: resp.Header.Get("fly-request-id"),
+	}
+
 	switch resp.StatusCode / 100 {
 	case 1, 3:
-		return fmt.Errorf("API returned unexpected status, %d", resp.StatusCode)
+		apiErr.Message = fmt.Sprintf("API returned unexpected status, %d", resp.StatusCode)
 	case 4, 5:
-		apiErr := struct {
+		body := struct {
 			Error   string `json:"error"`
 			Message string `json:"message,omitempty"`
 		}{}
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			return fmt.Errorf("request returned non-2xx status, %d", resp.StatusCode)
-		}
-		if apiErr.Message != "" {
-			return fmt.Errorf("%s", apiErr.Message)
+		switch err := json.NewDecoder(resp.Body).Decode(&body); {
+		case err != nil:
+			apiErr.Message = fmt.Sprintf("request returned non-2xx status, %d", resp.StatusCode)
+		case body.Message != "":
+			apiErr.Message = body.Message
+		default:
+			apiErr.Message = body.Error
 		}
-		return errors.New(apiErr.Error)
 	default:
-		return errors.New("something went terribly wrong")
+		apiErr.Message = "something went terribly wrong"
 	}
+
+	return apiErr
 }
 
 func resolvePeerIP(ip string) string {
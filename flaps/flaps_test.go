@@ -0,0 +1,100 @@
+package flaps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Debug(...interface{})          {}
+func (discardLogger) Debugf(string, ...interface{}) {}
+
+// fakeMachines is a Machines API deduplicating creates by idempotency key,
+// which answers the first attempt of each with a 503 after creating the
+// machine, as when the response is lost on the way back.
+type fakeMachines struct {
+	mu       sync.Mutex
+	byKey    map[string]*api.Machine
+	attempts map[string]int
+}
+
+func (f *fakeMachines) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := r.Header.Get(IdempotencyKeyHeader)
+	f.attempts[key]++
+
+	machine, ok := f.byKey[key]
+	if !ok || key == "" {
+		machine = &api.Machine{ID: fmt.Sprintf("m%d", len(f.byKey)+1)}
+		f.byKey[key] = machine
+	}
+
+	if f.attempts[key] == 1 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(machine)
+}
+
+func testClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+
+			return d.DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+
+	httpClient, err := api.NewHTTPClient(discardLogger{}, transport)
+	require.NoError(t, err)
+
+	return &Client{
+		app:        &api.AppCompact{Name: "app"},
+		peerIP:     "fdaa::3",
+		httpClient: httpClient,
+	}
+}
+
+func TestRetriedLaunchCreatesOneMachine(t *testing.T) {
+	fake := &fakeMachines{
+		byKey:    map[string]*api.Machine{},
+		attempts: map[string]int{},
+	}
+	client := testClient(t, fake)
+
+	machine, err := client.Launch(context.Background(), api.LaunchMachineInput{Region: "ord"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "m1", machine.ID)
+	assert.Len(t, fake.byKey, 1)
+	for key, n := range fake.attempts {
+		assert.NotEmpty(t, key)
+		assert.Equal(t, 2, n)
+	}
+
+	// another launch is another operation, with a key of its own
+	_, err = client.Launch(context.Background(), api.LaunchMachineInput{Region: "ord"})
+	require.NoError(t, err)
+	assert.Len(t, fake.byKey, 2)
+}
@@ -76,6 +76,18 @@ func LoadAppConfig(configFile string) (*AppConfig, error) {
 	return &appConfig, err
 }
 
+// LoadAppConfigFromReader loads a TOML app config from r, for configs that
+// aren't read from a file.
+func LoadAppConfigFromReader(r io.Reader) (*AppConfig, error) {
+	appConfig := AppConfig{
+		Definition: map[string]interface{}{},
+	}
+
+	err := appConfig.unmarshalTOML(r)
+
+	return &appConfig, err
+}
+
 func (ac *AppConfig) HasDefinition() bool {
 	return len(ac.Definition) > 0
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/api"
@@ -43,12 +44,18 @@ func NewCommand(ctx context.Context, app *api.AppCompact) (*Command, error) {
 		return nil, fmt.Errorf("ssh: can't build tunnel for %s: %s", app.Organization.Slug, err)
 	}
 
+	return NewCommandWithDialer(ctx, app, dialer), nil
+}
+
+// NewCommandWithDialer returns a Command running through dialer, for callers
+// which already have one to the organization of app.
+func NewCommandWithDialer(ctx context.Context, app *api.AppCompact, dialer agent.Dialer) *Command {
 	return &Command{
 		ctx:    ctx,
 		app:    app,
 		dialer: dialer,
 		io:     iostreams.FromContext(ctx),
-	}, nil
+	}
 }
 
 func (pc *Command) UpdateSettings(ctx context.Context, leaderIp string, config map[string]string) error {
@@ -78,6 +85,32 @@ func (pc *Command) UpdateSettings(ctx context.Context, leaderIp string, config m
 	return nil
 }
 
+// UpdateUserPassword changes the password of an existing user in place with
+// ALTER ROLE, run through psql on the leader. The statement is passed encoded
+// so neither of its arguments needs escaping for the shell.
+func (pc *Command) UpdateUserPassword(ctx context.Context, leaderIp, name, password string) error {
+	stmt := fmt.Sprintf("ALTER ROLE %s WITH PASSWORD %s;", quoteIdentifier(name), quoteLiteral(password))
+
+	cmd := fmt.Sprintf(
+		"sh -c 'echo %s | base64 -d | PGPASSWORD=$OPERATOR_PASSWORD psql -h localhost -p 5433 -U postgres -v ON_ERROR_STOP=1 -q -o /dev/null'",
+		encodeCommand(stmt),
+	)
+
+	if _, err := ssh.RunSSHCommand(ctx, pc.app, pc.dialer, leaderIp, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 // encodeCommand will base64 encode a command string so it can be passed
 // in with  exec.Command.
 func encodeCommand(command string) string {
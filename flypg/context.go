@@ -11,3 +11,16 @@ func CommandWithContext(ctx context.Context, cmd *Command) context.Context {
 func CommandFromContext(ctx context.Context) *Command {
 	return ctx.Value(cmdContextKey{}).(*Command)
 }
+
+type poolContextKey struct{}
+
+// PoolWithContext returns a copy of ctx carrying pool.
+func PoolWithContext(ctx context.Context, pool *Pool) context.Context {
+	return context.WithValue(ctx, poolContextKey{}, pool)
+}
+
+// PoolFromContext returns the Pool ctx carries, if any.
+func PoolFromContext(ctx context.Context) *Pool {
+	pool, _ := ctx.Value(poolContextKey{}).(*Pool)
+	return pool
+}
@@ -23,10 +23,29 @@ type Client struct {
 
 // NewFromInstance creates a new Client that targets a specific instance(address)
 func NewFromInstance(address string, dialer agent.Dialer) *Client {
+	return NewPool(dialer).Instance(address)
+}
+
+// Pool hands out Clients of the instances of a cluster which share a single
+// HTTP transport, so that connections dialed through the agent are kept alive
+// and reused rather than dialed from scratch for every Client.
+type Pool struct {
+	httpClient *http.Client
+}
+
+// NewPool creates a new Pool dialing through dialer.
+func NewPool(dialer agent.Dialer) *Pool {
+	return &Pool{
+		httpClient: newHttpClient(dialer),
+	}
+}
+
+// Instance returns a Client that targets a specific instance(address).
+func (p *Pool) Instance(address string) *Client {
 	url := fmt.Sprintf("http://%s:5500", address)
 	terminal.Debugf("flypg will connect to: %s\n", url)
 	return &Client{
-		httpClient: newHttpClient(dialer),
+		httpClient: p.httpClient,
 		BaseURL:    url,
 	}
 }
@@ -0,0 +1,49 @@
+package flypg
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/superfly/flyctl/wg"
+)
+
+// countingDialer dials addr whatever the address asked for, counting dials.
+type countingDialer struct {
+	addr  string
+	dials int32
+}
+
+func (d *countingDialer) State() *wg.WireGuardState { return nil }
+
+func (d *countingDialer) Config() *wg.Config { return nil }
+
+func (d *countingDialer) DialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	atomic.AddInt32(&d.dials, 1)
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, d.addr)
+}
+
+func TestPoolReusesConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result": "leader"}`))
+	}))
+	defer server.Close()
+
+	dialer := &countingDialer{addr: server.Listener.Addr().String()}
+	pool := NewPool(dialer)
+
+	for i := 0; i < 3; i++ {
+		role, err := pool.Instance("fdaa::3").NodeRole(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "leader", role)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dialer.dials))
+}
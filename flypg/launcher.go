@@ -43,6 +43,11 @@ type CreateClusterInput struct {
 	VolumeSize         *int
 	VMSize             *api.VMSize
 	SnapshotID         *string
+	// ForkFrom is the ID of a volume the data of the first member is forked
+	// from, taking precedence over SnapshotID.
+	ForkFrom *string
+	// Metadata is added to the metadata of every member.
+	Metadata map[string]string
 }
 
 func NewLauncher(client *api.Client) *Launcher {
@@ -95,29 +100,41 @@ func (l *Launcher) LaunchMachinesPostgres(ctx context.Context, config *CreateClu
 		}
 
 		snapshot := config.SnapshotID
+		fork := config.ForkFrom
 		verb := "Provisioning"
 
-		// When a snapshot is specified, we only want to pass it into the first volume created.
-		if snapshot != nil {
+		// When a snapshot or a volume to fork is specified, we only want to pass it into the first volume created.
+		switch {
+		case i > 0:
+			snapshot, fork = nil, nil
+		case fork != nil:
+			verb = "Forking"
+			snapshot = nil
+		case snapshot != nil:
 			verb = "Restoring"
-			if i > 0 {
-				snapshot = nil
-			}
 		}
 
 		fmt.Fprintf(io.Out, "%s %d of %d machines with image %s\n", verb, i+1, config.InitialClusterSize, machineConf.Image)
 
-		volInput := api.CreateVolumeInput{
-			AppID:             app.ID,
-			Name:              volumeName,
-			Region:            config.Region,
-			SizeGb:            *config.VolumeSize,
-			Encrypted:         true,
-			RequireUniqueZone: false,
-			SnapshotID:        snapshot,
+		var vol *api.Volume
+		if fork != nil {
+			vol, err = l.client.ForkVolume(ctx, api.ForkVolumeInput{
+				AppID:          app.ID,
+				SourceVolumeID: *fork,
+				Name:           volumeName,
+				MachinesOnly:   true,
+			})
+		} else {
+			vol, err = l.client.CreateVolume(ctx, api.CreateVolumeInput{
+				AppID:             app.ID,
+				Name:              volumeName,
+				Region:            config.Region,
+				SizeGb:            *config.VolumeSize,
+				Encrypted:         true,
+				RequireUniqueZone: false,
+				SnapshotID:        snapshot,
+			})
 		}
-
-		vol, err := l.client.CreateVolume(ctx, volInput)
 		if err != nil {
 			return err
 		}
@@ -144,7 +161,7 @@ func (l *Launcher) LaunchMachinesPostgres(ctx context.Context, config *CreateClu
 		fmt.Fprintf(io.Out, "Waiting for machine to start...\n")
 
 		waitTimeout := time.Minute * 5
-		if snapshot != nil {
+		if snapshot != nil || fork != nil {
 			waitTimeout = time.Hour
 		}
 
@@ -305,6 +322,9 @@ func (l *Launcher) getPostgresConfig(config *CreateClusterInput) *api.MachineCon
 		"managed-by-fly-deploy": "true",
 		"fly-managed-postgres":  "true",
 	}
+	for k, v := range config.Metadata {
+		machineConfig.Metadata[k] = v
+	}
 
 	// Restart policy
 	machineConfig.Restart.Policy = api.MachineRestartPolicyAlways
@@ -369,7 +389,10 @@ func (l *Launcher) setSecrets(ctx context.Context, config *CreateClusterInput) (
 		"OPERATOR_PASSWORD": opPassword,
 	}
 
-	if config.SnapshotID != nil {
+	switch {
+	case config.ForkFrom != nil:
+		secrets["FLY_RESTORED_FROM"] = *config.ForkFrom
+	case config.SnapshotID != nil:
 		secrets["FLY_RESTORED_FROM"] = *config.SnapshotID
 	}
 
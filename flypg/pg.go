@@ -189,3 +189,17 @@ func (c *Client) UpdateSettings(ctx context.Context, settings map[string]string)
 
 	return nil
 }
+
+// ArchiverStats returns the WAL archiver statistics (pg_stat_archiver) of the
+// node.
+func (c *Client) ArchiverStats(ctx context.Context) (*ArchiverStats, error) {
+	endpoint := "/commands/admin/archiver/stats"
+
+	out := new(ArchiverStatsResponse)
+
+	if err := c.Do(ctx, http.MethodGet, endpoint, nil, out); err != nil {
+		return nil, err
+	}
+
+	return &out.Result, nil
+}
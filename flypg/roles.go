@@ -0,0 +1,46 @@
+package flypg
+
+import "github.com/superfly/flyctl/api"
+
+// MachineRole returns the cluster role reported by the machine's role check.
+// It returns "unknown" when the machine has no role check and "error" when the
+// check isn't passing.
+func MachineRole(machine *api.Machine) (role string) {
+	role = "unknown"
+
+	for _, check := range machine.Checks {
+		if check.Name == "role" {
+			if check.Status == "passing" {
+				role = check.Output
+			} else {
+				role = "error"
+			}
+			break
+		}
+	}
+	return role
+}
+
+// MachineNodeRoles splits machines into the cluster leader and everything
+// else. Machines with an unknown role are treated as replicas.
+func MachineNodeRoles(machines []*api.Machine) (leader *api.Machine, replicas []*api.Machine) {
+	for _, machine := range machines {
+		if MachineRole(machine) == "leader" {
+			leader = machine
+		} else {
+			replicas = append(replicas, machine)
+		}
+	}
+	return leader, replicas
+}
+
+// RestartOrder returns machines ordered so that replicas come first and the
+// leader, if any, comes last.
+func RestartOrder(machines []*api.Machine) []*api.Machine {
+	leader, replicas := MachineNodeRoles(machines)
+	if leader == nil {
+		return replicas
+	}
+
+	return append(replicas, leader)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 type DatabaseListResponse struct {
@@ -92,12 +93,48 @@ type PGSetting struct {
 	Desc           string   `json:"short_desc,omitempty"`
 	PendingChange  string   `json:"pending_change,omitempty"`
 	PendingRestart bool     `json:"pending_restart,omitempty"`
+	Source         string   `json:"source,omitempty"`
+	BootVal        string   `json:"boot_val,omitempty"`
+}
+
+// IsDefault reports whether the setting has its built-in default value, as
+// reported by BootVal, or comes from the default where that isn't reported.
+func (s PGSetting) IsDefault() bool {
+	if s.BootVal != "" {
+		return s.Setting == s.BootVal
+	}
+
+	return s.Source == "default"
 }
 
 type SettingsViewResponse struct {
 	Result PGSettings
 }
 
+// ArchiverStats mirrors the pg_stat_archiver view.
+type ArchiverStats struct {
+	ArchivedCount    int        `json:"archived_count"`
+	LastArchivedWAL  string     `json:"last_archived_wal,omitempty"`
+	LastArchivedTime *time.Time `json:"last_archived_time,omitempty"`
+	FailedCount      int        `json:"failed_count"`
+	LastFailedWAL    string     `json:"last_failed_wal,omitempty"`
+	LastFailedTime   *time.Time `json:"last_failed_time,omitempty"`
+	StatsReset       *time.Time `json:"stats_reset,omitempty"`
+}
+
+// IsFailing reports whether the most recent archival attempt failed.
+func (s ArchiverStats) IsFailing() bool {
+	if s.FailedCount == 0 || s.LastFailedTime == nil {
+		return false
+	}
+
+	return s.LastArchivedTime == nil || s.LastFailedTime.After(*s.LastArchivedTime)
+}
+
+type ArchiverStatsResponse struct {
+	Result ArchiverStats
+}
+
 type Error struct {
 	StatusCode int
 	Err        string `json:"error"`
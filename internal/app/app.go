@@ -19,6 +19,7 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/iostreams"
 	"github.com/superfly/flyctl/scanner"
 )
@@ -40,10 +41,6 @@ func NewConfig() *Config {
 
 // LoadConfig loads the app config at the given path.
 func LoadConfig(ctx context.Context, path string, platformVersion string) (cfg *Config, err error) {
-	cfg = &Config{
-		Definition: map[string]interface{}{},
-	}
-
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -54,14 +51,24 @@ func LoadConfig(ctx context.Context, path string, platformVersion string) (cfg *
 		}
 	}()
 
-	cfg.Path = path
-	cfg.platformVersion = platformVersion
+	if cfg, err = loadConfig(ctx, file, platformVersion); cfg != nil {
+		cfg.Path = path
+	}
+
+	return
+}
+
+func loadConfig(ctx context.Context, r io.ReadSeeker, platformVersion string) (cfg *Config, err error) {
+	cfg = &Config{
+		Definition:      map[string]interface{}{},
+		platformVersion: platformVersion,
+	}
 
 	if platformVersion == "" {
-		cfg.DeterminePlatform(ctx, file)
+		cfg.DeterminePlatform(ctx, r)
 	}
 
-	err = cfg.unmarshalTOML(file)
+	err = cfg.unmarshalTOML(r)
 
 	return
 }
@@ -73,23 +80,41 @@ type SlimConfig struct {
 
 // Config wraps the properties of app configuration.
 type Config struct {
-	AppName         string                      `toml:"app,omitempty"`
-	Build           *Build                      `toml:"build,omitempty"`
-	HttpService     *HttpService                `toml:"http_service,omitempty"`
-	Definition      map[string]interface{}      `toml:"definition,omitempty"`
-	Path            string                      `toml:"path,omitempty"`
-	Services        []api.MachineService        `toml:"services"`
-	Env             map[string]string           `toml:"env" json:"env"`
-	Metrics         *api.MachineMetrics         `toml:"metrics" json:"metrics"`
-	Statics         []*Static                   `toml:"statics,omitempty" json:"statics"`
-	Deploy          *Deploy                     `toml:"deploy, omitempty"`
-	PrimaryRegion   string                      `toml:"primary_region,omitempty"`
-	Checks          map[string]api.MachineCheck `toml:"checks,omitempty"`
+	AppName       string                      `toml:"app,omitempty"`
+	StrictAppName bool                        `toml:"strict_app_name,omitempty"`
+	Build         *Build                      `toml:"build,omitempty"`
+	HttpService   *HttpService                `toml:"http_service,omitempty"`
+	Definition    map[string]interface{}      `toml:"definition,omitempty"`
+	Path          string                      `toml:"path,omitempty"`
+	Services      []api.MachineService        `toml:"services"`
+	Env           map[string]string           `toml:"env" json:"env"`
+	Metrics       *api.MachineMetrics         `toml:"metrics" json:"metrics"`
+	Statics       []*Static                   `toml:"statics,omitempty" json:"statics"`
+	Deploy        *Deploy                     `toml:"deploy, omitempty"`
+	PrimaryRegion string                      `toml:"primary_region,omitempty"`
+	Checks        map[string]api.MachineCheck `toml:"checks,omitempty"`
+	SwapSizeMB    *int                        `toml:"swap_size_mb,omitempty" json:"swap_size_mb,omitempty"`
+	Processes     map[string]string           `toml:"processes,omitempty" json:"processes,omitempty"`
+	// ProcessBuilds holds the builds of the process groups with images of
+	// their own.
+	ProcessBuilds map[string]*ProcessBuild `toml:"-" json:"process_builds,omitempty"`
+	// Containers are those the machines run, for apps running more than one
+	// container per machine.
+	Containers []Container `toml:"containers,omitempty" json:"containers,omitempty"`
+	// VM configures the machines of the app, as when all of them need GPUs.
+	VM              *VM `toml:"vm,omitempty" json:"vm,omitempty"`
 	platformVersion string
+
+	// comments are written below the header of generated files
+	comments []string
+	// source describes where the config was read from, in case that's not
+	// a file at Path
+	source string
 }
 
 type Deploy struct {
-	ReleaseCommand string `toml:"release_command,omitempty"`
+	ReleaseCommand  string        `toml:"release_command,omitempty"`
+	WaitGracePeriod *api.Duration `toml:"wait_grace_period,omitempty"`
 }
 
 type Static struct {
@@ -105,6 +130,32 @@ type HttpService struct {
 type VM struct {
 	CpuCount int `toml:"cpu_count,omitempty"`
 	Memory   int `toml:"memory,omitempty"`
+	// GPUKind is the kind of GPU each machine gets and GPUs how many, 1
+	// unless set.
+	GPUKind string `toml:"gpu_kind,omitempty" json:"gpu_kind,omitempty"`
+	GPUs    int    `toml:"gpus,omitempty" json:"gpus,omitempty"`
+}
+
+// GPUGuest returns guest with the GPUs the [vm] section asks for, or guest
+// itself if it asks for none. Machines without a guest of their own get the
+// smallest preset along with the GPUs.
+func (c *Config) GPUGuest(guest *api.MachineGuest) *api.MachineGuest {
+	if c.VM == nil || c.VM.GPUKind == "" {
+		return guest
+	}
+
+	g := *api.MachinePresets["shared-cpu-1x"]
+	if guest != nil {
+		g = *guest
+	}
+
+	g.GPUKind = c.VM.GPUKind
+	g.GPUs = c.VM.GPUs
+	if g.GPUs == 0 {
+		g.GPUs = 1
+	}
+
+	return &g
 }
 
 type Build struct {
@@ -116,7 +167,17 @@ type Build struct {
 	Builtin           string                 `toml:"builtin,omitempty"`
 	Dockerfile        string                 `toml:"dockerfile,omitempty"`
 	Ignorefile        string                 `toml:"ignorefile,omitempty"`
-	DockerBuildTarget string                 `toml:"buildpacks,omitempty"`
+	DockerBuildTarget string                 `toml:"build-target,omitempty"`
+}
+
+// Source describes where the config was read from for use in messages: its
+// path, stdin or a URL.
+func (c *Config) Source() string {
+	if c.source != "" {
+		return c.source
+	}
+
+	return c.Path
 }
 
 // SetMachinesPlatform informs the TOML marshaller that this config is for the machines platform
@@ -220,9 +281,7 @@ func (c *Config) unmarshalTOML(r io.ReadSeeker) (err error) {
 		// Rewind TOML in preparation for parsing the full config
 		r.Seek(0, io.SeekStart)
 		if c.ForMachines() {
-			_, err = toml.NewDecoder(r).Decode(&c)
-
-			if err != nil {
+			if err = c.decodeMachinesTOML(r); err != nil {
 				return err
 			}
 
@@ -246,6 +305,11 @@ func (c *Config) unmarshalNativeMap(data map[string]interface{}) error {
 	}
 	delete(data, "app")
 
+	if strict, ok := (data["strict_app_name"]).(bool); ok {
+		c.StrictAppName = strict
+	}
+	delete(data, "strict_app_name")
+
 	c.Build = unmarshalBuild(data)
 	delete(data, "build")
 
@@ -276,6 +340,13 @@ func unmarshalBuild(data map[string]interface{}) *Build {
 			b.Builder = fmt.Sprint(v)
 			configValueSet = configValueSet || b.Builder != ""
 		case "buildpacks":
+			if target, ok := v.(string); ok {
+				if b.DockerBuildTarget == "" {
+					b.DockerBuildTarget = target
+				}
+				configValueSet = configValueSet || target != ""
+				warnDeprecatedBuildTarget()
+			}
 			if bpSlice, ok := v.([]interface{}); ok {
 				for _, argV := range bpSlice {
 					b.Buildpacks = append(b.Buildpacks, fmt.Sprint(argV))
@@ -322,10 +393,25 @@ func (c *Config) marshalTOML(w io.Writer) error {
 
 	encoder := toml.NewEncoder(&b)
 	fmt.Fprintf(w, "# fly.toml file generated for %s on %s\n\n", c.AppName, time.Now().Format(time.RFC3339))
+	for _, comment := range c.comments {
+		fmt.Fprintf(w, "# %s\n", comment)
+	}
+	if len(c.comments) > 0 {
+		fmt.Fprintln(w)
+	}
 
 	// For machines apps, encode and write directly, bypassing custom marshalling
 	if c.platformVersion == MachinesPlatform {
-		encoder.Encode(&c)
+		if len(c.ProcessBuilds) == 0 {
+			encoder.Encode(&c)
+		} else {
+			flat := *c
+			flat.Processes = nil
+			encoder.Encode(&flat)
+			if err := c.encodeProcessTables(&b); err != nil {
+				return err
+			}
+		}
 		_, err := b.WriteTo(w)
 		return err
 	}
@@ -334,6 +420,10 @@ func (c *Config) marshalTOML(w io.Writer) error {
 		"app": c.AppName,
 	}
 
+	if c.StrictAppName {
+		rawData["strict_app_name"] = true
+	}
+
 	if err := encoder.Encode(rawData); err != nil {
 		return err
 	}
@@ -436,8 +526,34 @@ func (c *Config) Validate() (err error) {
 				fmt.Printf("Validation error on %s: %s\n", err.Field(), err.Tag())
 			}
 		}
+
+		return
+	}
+
+	if _, err := c.MachineChecks(); err != nil {
+		return err
+	}
+
+	return c.validateContainers()
+}
+
+// ReferencedSecrets returns the names of the app secrets interpolated into
+// the env or the process commands of the config, leaving out the variables
+// [env] sets, however the config was loaded.
+func (c *Config) ReferencedSecrets() []string {
+	env := map[string]string{}
+	for _, set := range []map[string]string{c.GetEnvVariables(), c.Env} {
+		for name, value := range set {
+			env[name] = value
+		}
 	}
-	return
+
+	commands := make([]string, 0, len(c.Processes))
+	for _, command := range c.Processes {
+		commands = append(commands, command)
+	}
+
+	return machine.InterpolatedSecrets(env, commands...)
 }
 
 // HasServices - Does this config have a services section
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// MinCheckInterval is the shortest interval machines run checks at.
+const MinCheckInterval = time.Second
+
+// The types of checks machines run.
+const (
+	CheckTypeHTTP = "http"
+	CheckTypeTCP  = "tcp"
+	CheckTypeExec = "exec"
+)
+
+// MachineChecks returns the checks of the config the way machines run them,
+// failing on checks they can't run.
+func (c *Config) MachineChecks() (map[string]api.MachineCheck, error) {
+	if len(c.Checks) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(c.Checks))
+	for name := range c.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make(map[string]api.MachineCheck, len(c.Checks))
+	for _, name := range names {
+		check, err := machineCheck(c.Checks[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid check %s: %w", name, err)
+		}
+		checks[name] = check
+	}
+
+	return checks, nil
+}
+
+// machineCheck returns check as machines run it: HTTP checks request a path
+// of a port, / unless set, TCP checks connect to a port and exec checks run
+// a command in the guest, failing when it exits non-zero.
+func machineCheck(check api.MachineCheck) (api.MachineCheck, error) {
+	check.Type = strings.ToLower(check.Type)
+
+	if check.Interval != nil && check.Interval.Duration < MinCheckInterval {
+		return check, fmt.Errorf("interval %s is shorter than the minimum of %s", check.Interval.Duration, MinCheckInterval)
+	}
+
+	switch check.Type {
+	case CheckTypeHTTP:
+		if check.Port == 0 {
+			return check, errors.New("http checks need a port")
+		}
+		if len(check.Command) > 0 {
+			return check, errors.New("http checks take no command")
+		}
+		if check.HTTPPath == nil {
+			path := "/"
+			check.HTTPPath = &path
+		}
+	case CheckTypeTCP:
+		if check.Port == 0 {
+			return check, errors.New("tcp checks need a port")
+		}
+		if check.HTTPPath != nil || check.HTTPMethod != nil || len(check.Command) > 0 {
+			return check, errors.New("tcp checks take a port only")
+		}
+	case CheckTypeExec:
+		if len(check.Command) == 0 || strings.TrimSpace(check.Command[0]) == "" {
+			return check, errors.New("exec checks need a command")
+		}
+		if check.Port != 0 || check.HTTPPath != nil || check.HTTPMethod != nil {
+			return check, errors.New("exec checks take a command only")
+		}
+	case "":
+		return check, fmt.Errorf("missing type; use one of %s, %s or %s", CheckTypeHTTP, CheckTypeTCP, CheckTypeExec)
+	default:
+		return check, fmt.Errorf("unsupported type %q; use one of %s, %s or %s", check.Type, CheckTypeHTTP, CheckTypeTCP, CheckTypeExec)
+	}
+
+	return check, nil
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestMachineChecks(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		want   api.MachineCheck
+		err    string
+	}{
+		{
+			name: "http",
+			config: `
+[checks.web]
+type = "http"
+port = 8080
+interval = "15s"
+timeout = "2s"
+`,
+			want: api.MachineCheck{
+				Type:     "http",
+				Port:     8080,
+				Interval: &api.Duration{Duration: 15 * time.Second},
+				Timeout:  &api.Duration{Duration: 2 * time.Second},
+				HTTPPath: api.StringPointer("/"),
+			},
+		},
+		{
+			name: "http with path and method",
+			config: `
+[checks.web]
+type = "HTTP"
+port = 8080
+method = "head"
+path = "/healthz"
+`,
+			want: api.MachineCheck{
+				Type:       "http",
+				Port:       8080,
+				HTTPMethod: api.StringPointer("head"),
+				HTTPPath:   api.StringPointer("/healthz"),
+			},
+		},
+		{
+			name: "tcp",
+			config: `
+[checks.db]
+type = "tcp"
+port = 5432
+interval = "10s"
+`,
+			want: api.MachineCheck{
+				Type:     "tcp",
+				Port:     5432,
+				Interval: &api.Duration{Duration: 10 * time.Second},
+			},
+		},
+		{
+			name: "exec",
+			config: `
+[checks.worker]
+type = "exec"
+command = ["/bin/check-queue", "--max-lag", "30"]
+interval = "30s"
+timeout = "5s"
+`,
+			want: api.MachineCheck{
+				Type:     "exec",
+				Command:  []string{"/bin/check-queue", "--max-lag", "30"},
+				Interval: &api.Duration{Duration: 30 * time.Second},
+				Timeout:  &api.Duration{Duration: 5 * time.Second},
+			},
+		},
+		{
+			name: "http without port",
+			config: `
+[checks.web]
+type = "http"
+`,
+			err: "http checks need a port",
+		},
+		{
+			name: "tcp with path",
+			config: `
+[checks.db]
+type = "tcp"
+port = 5432
+path = "/"
+`,
+			err: "tcp checks take a port only",
+		},
+		{
+			name: "exec without command",
+			config: `
+[checks.worker]
+type = "exec"
+`,
+			err: "exec checks need a command",
+		},
+		{
+			name: "exec with empty command",
+			config: `
+[checks.worker]
+type = "exec"
+command = [""]
+`,
+			err: "exec checks need a command",
+		},
+		{
+			name: "interval below minimum",
+			config: `
+[checks.worker]
+type = "exec"
+command = ["true"]
+interval = "500ms"
+`,
+			err: "interval 500ms is shorter than the minimum of 1s",
+		},
+		{
+			name: "missing type",
+			config: `
+[checks.web]
+port = 8080
+`,
+			err: "missing type",
+		},
+		{
+			name: "unknown type",
+			config: `
+[checks.web]
+type = "grpc"
+port = 8080
+`,
+			err: `unsupported type "grpc"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := loadConfig(context.Background(), strings.NewReader(tc.config), MachinesPlatform)
+			require.NoError(t, err)
+
+			checks, err := cfg.MachineChecks()
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, checks, 1)
+
+			for _, check := range checks {
+				assert.Equal(t, tc.want, check)
+			}
+		})
+	}
+}
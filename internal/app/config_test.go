@@ -2,10 +2,14 @@ package app
 
 import (
 	"context"
+	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
 )
 
 func TestLoadTOMLAppConfigWithAppName(t *testing.T) {
@@ -60,3 +64,63 @@ func TestLoadTOMLAppConfigWithServices(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, p.Definition, rawData)
 }
+
+func TestLoadTOMLAppConfigWithProcessBuilds(t *testing.T) {
+	const path = "./testdata/process-builds.toml"
+
+	p, err := LoadConfig(context.Background(), path, MachinesPlatform)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "bin/web", "worker": "bin/worker --queue default"}, p.Processes)
+	assert.Equal(t, map[string]*ProcessBuild{
+		"worker": {Dockerfile: "worker/Dockerfile", Context: "worker", Args: map[string]string{"QUEUE": "default"}},
+	}, p.ProcessBuilds)
+
+	written := filepath.Join(t.TempDir(), DefaultConfigFileName)
+	assert.NoError(t, p.WriteToFile(written))
+
+	reloaded, err := LoadConfig(context.Background(), written, MachinesPlatform)
+	assert.NoError(t, err)
+	assert.Equal(t, p.Processes, reloaded.Processes)
+	assert.Equal(t, p.ProcessBuilds, reloaded.ProcessBuilds)
+}
+
+func TestLoadTOMLAppConfigWithDeprecatedBuildTarget(t *testing.T) {
+	const path = "./testdata/build-target-deprecated.toml"
+
+	for _, platform := range []string{NomadPlatform, MachinesPlatform} {
+		p, err := LoadConfig(context.Background(), path, platform)
+		assert.NoError(t, err, platform)
+		assert.Equal(t, "release", p.Build.DockerBuildTarget, platform)
+		assert.Empty(t, p.Build.Buildpacks, platform)
+	}
+}
+
+func TestReferencedSecrets(t *testing.T) {
+	cfg, err := LoadConfig(context.Background(), "./testdata/secret-refs.toml", MachinesPlatform)
+	assert.NoError(t, err)
+
+	secrets := cfg.ReferencedSecrets()
+	sort.Strings(secrets)
+	assert.Equal(t, []string{"DB_PASSWORD", "WORKER_TOKEN"}, secrets)
+}
+
+func TestLoadTOMLAppConfigWithContainers(t *testing.T) {
+	p, err := LoadConfig(context.Background(), "./testdata/containers.toml", MachinesPlatform)
+	assert.NoError(t, err)
+	assert.NoError(t, p.Validate())
+
+	assert.Equal(t, []api.MachineContainer{
+		{Name: "app", Image: "registry.fly.io/test-app:v2", DependsOn: []string{"proxy"}},
+		{Name: "proxy", Image: "envoyproxy/envoy:v1.25", Cmd: []string{"envoy", "-c", "/etc/envoy.yaml"}, Env: map[string]string{"LOG_LEVEL": "info"}},
+	}, p.MachineContainers("registry.fly.io/test-app:v2"))
+
+	p.Containers[1].Ports = []int{8080}
+	assert.EqualError(t, p.Validate(), "port 8080 is served by more than one container: app, proxy")
+
+	p.Containers[1].Ports = nil
+	p.Containers[1].DependsOn = []string{"app"}
+	assert.EqualError(t, p.Validate(), "containers depend on each other in a cycle: app -> proxy -> app")
+
+	p.Containers[1].Name = "app"
+	assert.EqualError(t, p.Validate(), `container name "app" is used more than once`)
+}
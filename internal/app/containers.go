@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// Container configures one of the containers the machines of the app run,
+// from a [[containers]] section. Containers without an image run the deployed
+// image.
+type Container struct {
+	Name      string            `toml:"name" json:"name"`
+	Image     string            `toml:"image,omitempty" json:"image,omitempty"`
+	Command   []string          `toml:"command,omitempty" json:"command,omitempty"`
+	Env       map[string]string `toml:"env,omitempty" json:"env,omitempty"`
+	DependsOn []string          `toml:"depends_on,omitempty" json:"depends_on,omitempty"`
+	// Ports are the internal ports of the services the container serves.
+	Ports []int `toml:"ports,omitempty" json:"ports,omitempty"`
+}
+
+// MachineContainers returns the containers of the machine config, those
+// without an image of their own running image.
+func (c *Config) MachineContainers(image string) []api.MachineContainer {
+	if len(c.Containers) == 0 {
+		return nil
+	}
+
+	containers := make([]api.MachineContainer, 0, len(c.Containers))
+	for _, container := range c.Containers {
+		mc := api.MachineContainer{
+			Name:      container.Name,
+			Image:     container.Image,
+			Cmd:       container.Command,
+			Env:       container.Env,
+			DependsOn: container.DependsOn,
+		}
+		if mc.Image == "" {
+			mc.Image = image
+		}
+
+		containers = append(containers, mc)
+	}
+
+	return containers
+}
+
+// dependencyCycle returns the names of containers depending on each other in
+// a cycle, if any.
+func (c *Config) dependencyCycle() []string {
+	deps := make(map[string][]string, len(c.Containers))
+	for _, container := range c.Containers {
+		deps[container.Name] = container.DependsOn
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	var (
+		state = map[string]int{}
+		path  []string
+		visit func(string) []string
+	)
+
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return append(append([]string(nil), path[i:]...), name)
+				}
+			}
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+
+		return nil
+	}
+
+	for _, container := range c.Containers {
+		if cycle := visit(container.Name); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// servicePorts returns the internal ports of the services of c.
+func (c *Config) servicePorts() []int {
+	var ports []int
+	if c.HttpService != nil {
+		ports = append(ports, c.HttpService.InternalPort)
+	}
+	for _, service := range c.Services {
+		ports = append(ports, service.InternalPort)
+	}
+
+	return ports
+}
+
+// validateContainers checks that containers are named uniquely, depend on
+// other containers without cycles, and that exactly one container serves each
+// service.
+func (c *Config) validateContainers() error {
+	if len(c.Containers) == 0 {
+		return nil
+	}
+
+	var (
+		names  = make(map[string]bool, len(c.Containers))
+		owners = map[int][]string{}
+	)
+
+	for _, container := range c.Containers {
+		switch {
+		case container.Name == "":
+			return fmt.Errorf("containers must be named")
+		case names[container.Name]:
+			return fmt.Errorf("container name %q is used more than once", container.Name)
+		}
+		names[container.Name] = true
+
+		for _, port := range container.Ports {
+			owners[port] = append(owners[port], container.Name)
+		}
+	}
+
+	for _, container := range c.Containers {
+		for _, dep := range container.DependsOn {
+			switch {
+			case dep == container.Name:
+				return fmt.Errorf("container %q depends on itself", container.Name)
+			case !names[dep]:
+				return fmt.Errorf("container %q depends on unknown container %q", container.Name, dep)
+			}
+		}
+	}
+
+	if cycle := c.dependencyCycle(); cycle != nil {
+		return fmt.Errorf("containers depend on each other in a cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	for _, port := range c.servicePorts() {
+		switch owned := owners[port]; len(owned) {
+		case 0:
+			return fmt.Errorf("no container serves port %d; list it in the ports of one of the containers", port)
+		case 1:
+		default:
+			return fmt.Errorf("port %d is served by more than one container: %s", port, strings.Join(owned, ", "))
+		}
+	}
+
+	return nil
+}
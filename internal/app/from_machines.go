@@ -0,0 +1,245 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+	mach "github.com/superfly/flyctl/internal/machine"
+)
+
+// FromMachines synthesizes the config of the machines app appName from the
+// configs of its machines. Where the machines disagree, the value most of
+// them share is chosen and a warning describing the conflict is returned.
+func FromMachines(appName string, machines []*api.Machine) (*Config, []string) {
+	cfg := NewConfig()
+	cfg.AppName = appName
+	cfg.SetMachinesPlatform()
+
+	var configs []*api.Machine
+	for _, m := range machines {
+		if m.Config == nil || m.Config.Metadata["process_group"] == "release_command" {
+			continue
+		}
+		configs = append(configs, m)
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].ID < configs[j].ID
+	})
+
+	if len(configs) == 0 {
+		return cfg, nil
+	}
+
+	s := &synthesizer{machines: configs}
+
+	var image string
+	s.pick("image", &image, func(c *api.MachineConfig) interface{} { return c.Image })
+	if image != "" {
+		cfg.Build = &Build{Image: image}
+	}
+
+	var services []api.MachineService
+	s.pick("services", &services, func(c *api.MachineConfig) interface{} { return c.Services })
+	cfg.HttpService, cfg.Services = splitHttpService(services)
+
+	s.pick("checks", &cfg.Checks, func(c *api.MachineConfig) interface{} { return c.Checks })
+	s.pick("metrics", &cfg.Metrics, func(c *api.MachineConfig) interface{} { return c.Metrics })
+	s.pick("swap size", &cfg.SwapSizeMB, func(c *api.MachineConfig) interface{} { return c.Init.SwapSizeMB })
+
+	cfg.Env = s.env()
+	if region, ok := cfg.Env["PRIMARY_REGION"]; ok {
+		cfg.PrimaryRegion = region
+		delete(cfg.Env, "PRIMARY_REGION")
+	}
+	if len(cfg.Env) == 0 {
+		cfg.Env = nil
+	}
+
+	cfg.Processes = s.processes()
+
+	var guest *api.MachineGuest
+	s.pick("guest size", &guest, func(c *api.MachineConfig) interface{} { return c.Guest })
+	if guest != nil {
+		cfg.comments = append(cfg.comments,
+			fmt.Sprintf("machines run on %d %s cpu(s) with %dMB of memory; change this with `fly machine update --cpus --memory`", guest.CPUs, guest.CPUKind, guest.MemoryMB))
+	}
+
+	return cfg, s.warnings
+}
+
+type synthesizer struct {
+	machines []*api.Machine
+	warnings []string
+}
+
+// pick decodes the JSON encoding of field shared by most machines into dst.
+func (s *synthesizer) pick(what string, dst interface{}, field func(*api.MachineConfig) interface{}) {
+	counts := map[string]int{}
+	var order []string
+
+	for _, m := range s.machines {
+		b, _ := json.Marshal(field(m.Config))
+
+		key := string(b)
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+
+	chosen := majority(order, counts)
+	if len(order) > 1 {
+		s.warnings = append(s.warnings, fmt.Sprintf("machines disagree on their %s; using the one %d of %d machines share", what, counts[chosen], len(s.machines)))
+	}
+
+	_ = json.Unmarshal([]byte(chosen), dst)
+}
+
+// env returns the environment shared by most machines, variable by variable.
+// Scoped secrets aren't part of the app's config and thus left out.
+func (s *synthesizer) env() map[string]string {
+	var (
+		counts = map[string]map[string]int{}
+		orders = map[string][]string{}
+	)
+
+	for _, m := range s.machines {
+		scoped := map[string]bool{}
+		for _, name := range mach.ScopedSecretNames(m.Config) {
+			scoped[name] = true
+		}
+
+		for name, value := range m.Config.Env {
+			if scoped[name] {
+				continue
+			}
+
+			if counts[name] == nil {
+				counts[name] = map[string]int{}
+			}
+			if counts[name][value] == 0 {
+				orders[name] = append(orders[name], value)
+			}
+			counts[name][value]++
+		}
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make(map[string]string, len(counts))
+	for _, name := range names {
+		values := counts[name]
+
+		var total int
+		for _, n := range values {
+			total += n
+		}
+
+		// a variable only some machines set belongs to those machines
+		// rather than to the app
+		if total*2 <= len(s.machines) {
+			s.warnings = append(s.warnings, fmt.Sprintf("only %d of %d machines set %s; leaving it out", total, len(s.machines), name))
+
+			continue
+		}
+
+		env[name] = majority(orders[name], values)
+		if len(values) > 1 {
+			s.warnings = append(s.warnings, fmt.Sprintf("machines disagree on the value of %s; using the one %d of %d machines share", name, values[env[name]], len(s.machines)))
+		}
+	}
+
+	return env
+}
+
+// processes returns the command of each process group, in case the app runs
+// any besides the default one.
+func (s *synthesizer) processes() map[string]string {
+	groups := map[string][]*api.Machine{}
+	for _, m := range s.machines {
+		group := m.Config.Metadata["process_group"]
+		if group == "" {
+			group = "app"
+		}
+		groups[group] = append(groups[group], m)
+	}
+
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	processes := map[string]string{}
+	for _, group := range names {
+		sub := &synthesizer{machines: groups[group]}
+
+		var cmd []string
+		sub.pick(fmt.Sprintf("%s command", group), &cmd, func(c *api.MachineConfig) interface{} { return c.Init.Cmd })
+		s.warnings = append(s.warnings, sub.warnings...)
+
+		if len(cmd) > 0 {
+			processes[group] = strings.Join(cmd, " ")
+		}
+	}
+
+	if len(processes) == 0 || (len(groups) == 1 && groups["app"] != nil) {
+		return nil
+	}
+
+	return processes
+}
+
+// majority returns the key of counts with the highest count; ties go to the
+// key appearing first in order.
+func majority(order []string, counts map[string]int) (chosen string) {
+	if len(order) == 0 {
+		return
+	}
+
+	chosen = order[0]
+	for _, key := range order[1:] {
+		if counts[key] > counts[chosen] {
+			chosen = key
+		}
+	}
+
+	return
+}
+
+// splitHttpService returns the first of services that matches the shape
+// deploys give http_service sections, and the rest of them.
+func splitHttpService(services []api.MachineService) (*HttpService, []api.MachineService) {
+	for i, service := range services {
+		if service.Protocol != "tcp" || len(service.Ports) != 2 {
+			continue
+		}
+
+		http, tls := service.Ports[0], service.Ports[1]
+		if http.Port != 80 || !reflect.DeepEqual(http.Handlers, []string{"http"}) ||
+			tls.Port != 443 || !reflect.DeepEqual(tls.Handlers, []string{"http", "tls"}) || tls.ForceHttps {
+			continue
+		}
+
+		rest := append(append([]api.MachineService(nil), services[:i]...), services[i+1:]...)
+		if len(rest) == 0 {
+			rest = nil
+		}
+
+		return &HttpService{
+			InternalPort: service.InternalPort,
+			ForceHttps:   http.ForceHttps,
+			Concurrency:  service.Concurrency,
+		}, rest
+	}
+
+	return nil, services
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/superfly/flyctl/api"
+)
+
+func testMachine(id, foo string) *api.Machine {
+	return &api.Machine{
+		ID: id,
+		Config: &api.MachineConfig{
+			Image:    "registry.fly.io/test-app:deployment-1",
+			Env:      map[string]string{"FOO": foo, "PRIMARY_REGION": "ams"},
+			Metadata: map[string]string{"process_group": "app"},
+			Services: []api.MachineService{{
+				Protocol:     "tcp",
+				InternalPort: 8080,
+				Ports: []api.MachinePort{
+					{Port: 80, Handlers: []string{"http"}, ForceHttps: true},
+					{Port: 443, Handlers: []string{"http", "tls"}},
+				},
+			}},
+			Checks: map[string]api.MachineCheck{
+				"alive": {Type: "tcp", Port: 8080},
+			},
+			Guest: &api.MachineGuest{CPUKind: "shared", CPUs: 1, MemoryMB: 256},
+		},
+	}
+}
+
+func TestFromMachines(t *testing.T) {
+	machines := []*api.Machine{
+		testMachine("3", "b"),
+		testMachine("1", "a"),
+		testMachine("2", "a"),
+		{ID: "4", Config: &api.MachineConfig{Metadata: map[string]string{"process_group": "release_command"}}},
+	}
+
+	cfg, warnings := FromMachines("test-app", machines)
+
+	assert.Equal(t, []string{"machines disagree on the value of FOO; using the one 2 of 3 machines share"}, warnings)
+	assert.Equal(t, map[string]string{"FOO": "a"}, cfg.Env)
+	assert.Equal(t, "ams", cfg.PrimaryRegion)
+	assert.Equal(t, "registry.fly.io/test-app:deployment-1", cfg.Image())
+	assert.Equal(t, &HttpService{InternalPort: 8080, ForceHttps: true}, cfg.HttpService)
+	assert.Empty(t, cfg.Services)
+	assert.Nil(t, cfg.Processes)
+
+	path := filepath.Join(t.TempDir(), DefaultConfigFileName)
+	require.NoError(t, cfg.WriteToFile(path))
+
+	loaded, err := LoadConfig(context.Background(), path, MachinesPlatform)
+	require.NoError(t, err)
+	require.NoError(t, loaded.Validate())
+
+	assert.Equal(t, cfg.Env, loaded.Env)
+	assert.Equal(t, cfg.HttpService, loaded.HttpService)
+	assert.Equal(t, cfg.Checks, loaded.Checks)
+	assert.Equal(t, cfg.PrimaryRegion, loaded.PrimaryRegion)
+}
+
+func TestFromMachinesProcesses(t *testing.T) {
+	web, worker := testMachine("1", "a"), testMachine("2", "a")
+	web.Config.Init.Cmd = []string{"bin/web"}
+	worker.Config.Init.Cmd = []string{"bin/worker", "--queue", "default"}
+	worker.Config.Metadata["process_group"] = "worker"
+
+	cfg, _ := FromMachines("test-app", []*api.Machine{web, worker})
+
+	assert.Equal(t, map[string]string{"app": "bin/web", "worker": "bin/worker --queue default"}, cfg.Processes)
+}
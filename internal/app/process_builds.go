@@ -0,0 +1,213 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/superfly/flyctl/terminal"
+)
+
+// ProcessBuild configures building a separate image for the machines of a
+// process group, from its [processes.<name>.build] section.
+type ProcessBuild struct {
+	Dockerfile        string            `toml:"dockerfile,omitempty"`
+	Context           string            `toml:"context,omitempty"`
+	DockerBuildTarget string            `toml:"build-target,omitempty"`
+	Args              map[string]string `toml:"args,omitempty"`
+}
+
+// decodeMachinesTOML decodes r into c. Besides the name = "command" form,
+// process groups may be given as tables carrying their command and a build
+// section.
+func (c *Config) decodeMachinesTOML(r io.ReadSeeker) error {
+	var raw map[string]interface{}
+	if _, err := toml.NewDecoder(r).Decode(&raw); err != nil {
+		return err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	processes, builds, err := splitProcesses(raw["processes"])
+	if err != nil {
+		return err
+	}
+
+	migrated := migrateBuildTarget(raw)
+
+	if builds == nil && !migrated {
+		_, err = toml.NewDecoder(r).Decode(&c)
+
+		return err
+	}
+
+	// re-encode the config with the tables of processes flattened into
+	// their commands, which is what Config decodes
+	if builds != nil {
+		raw["processes"] = processes
+	}
+
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(raw); err != nil {
+		return err
+	}
+	if _, err := toml.NewDecoder(&b).Decode(&c); err != nil {
+		return err
+	}
+
+	c.ProcessBuilds = builds
+
+	return nil
+}
+
+// splitProcesses splits the processes section into the commands and builds of
+// its groups. The returned builds are nil if no group has one.
+func splitProcesses(section interface{}) (map[string]string, map[string]*ProcessBuild, error) {
+	groups, ok := section.(map[string]interface{})
+	if !ok {
+		return nil, nil, nil
+	}
+
+	var (
+		processes = make(map[string]string, len(groups))
+		builds    map[string]*ProcessBuild
+	)
+
+	for name, value := range groups {
+		switch v := value.(type) {
+		case string:
+			processes[name] = v
+		case map[string]interface{}:
+			for key := range v {
+				if key != "cmd" && key != "build" {
+					return nil, nil, fmt.Errorf("processes.%s: unknown key %q", name, key)
+				}
+			}
+
+			if cmd, ok := v["cmd"]; ok {
+				if processes[name], ok = cmd.(string); !ok {
+					return nil, nil, fmt.Errorf("processes.%s.cmd must be a string", name)
+				}
+			}
+
+			if data, ok := v["build"].(map[string]interface{}); ok {
+				build, err := decodeProcessBuild(name, data)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				if builds == nil {
+					builds = map[string]*ProcessBuild{}
+				}
+				builds[name] = build
+			}
+		default:
+			return nil, nil, fmt.Errorf("processes.%s must be a command or a table", name)
+		}
+	}
+
+	return processes, builds, nil
+}
+
+// deprecatedBuildTargetKey is the key of the build section earlier versions
+// wrote the build target under, which is also that of the list of
+// buildpacks.
+const deprecatedBuildTargetKey = "buildpacks"
+
+// migrateBuildTarget moves a build target given under the deprecated key of
+// the build section of raw to build-target, warning about it, and reports
+// whether it did.
+func migrateBuildTarget(raw map[string]interface{}) bool {
+	build, ok := raw["build"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	target, ok := build[deprecatedBuildTargetKey].(string)
+	if !ok {
+		return false
+	}
+	delete(build, deprecatedBuildTargetKey)
+
+	if _, ok := build["build-target"]; !ok {
+		build["build-target"] = target
+	}
+	warnDeprecatedBuildTarget()
+
+	return true
+}
+
+func warnDeprecatedBuildTarget() {
+	terminal.Warnf("the build target is set as build.%s, which is deprecated; set it as build.build-target instead\n", deprecatedBuildTargetKey)
+}
+
+func decodeProcessBuild(name string, data map[string]interface{}) (*ProcessBuild, error) {
+	build := &ProcessBuild{}
+
+	for key, value := range data {
+		var dst *string
+		switch key {
+		case "dockerfile":
+			dst = &build.Dockerfile
+		case "context":
+			dst = &build.Context
+		case "build-target":
+			dst = &build.DockerBuildTarget
+		case "args":
+			args, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("processes.%s.build.args must be a table", name)
+			}
+
+			build.Args = make(map[string]string, len(args))
+			for k, v := range args {
+				build.Args[k] = fmt.Sprint(v)
+			}
+
+			continue
+		default:
+			return nil, fmt.Errorf("processes.%s.build: unknown key %q", name, key)
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("processes.%s.build.%s must be a string", name, key)
+		}
+		*dst = s
+	}
+
+	return build, nil
+}
+
+// encodeProcessTables writes the process groups of c as tables, which is the
+// only form able to carry their builds.
+func (c *Config) encodeProcessTables(w io.Writer) error {
+	names := make([]string, 0, len(c.Processes)+len(c.ProcessBuilds))
+	seen := map[string]bool{}
+	for name := range c.Processes {
+		names = append(names, name)
+		seen[name] = true
+	}
+	for name := range c.ProcessBuilds {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	type process struct {
+		Cmd   string        `toml:"cmd,omitempty"`
+		Build *ProcessBuild `toml:"build,omitempty"`
+	}
+
+	processes := make(map[string]process, len(names))
+	for _, name := range names {
+		processes[name] = process{Cmd: c.Processes[name], Build: c.ProcessBuilds[name]}
+	}
+
+	return toml.NewEncoder(w).Encode(map[string]interface{}{"processes": processes})
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StdinSource is the config source denoting standard input.
+const StdinSource = "-"
+
+const remoteSourceTimeout = 10 * time.Second
+
+// maxRemoteSourceSize bounds how much of a config source is read, as configs
+// are way smaller.
+const maxRemoteSourceSize = 1 << 20
+
+// IsRemoteSource reports whether source denotes a config that's not read from
+// a local file, i.e. standard input or an https URL.
+func IsRemoteSource(source string) bool {
+	return source == StdinSource || strings.HasPrefix(source, "https://")
+}
+
+// DescribeSource returns source as it's referred to in messages.
+func DescribeSource(source string) string {
+	if source == StdinSource {
+		return "stdin"
+	}
+
+	return source
+}
+
+// ReadRemoteSource reads the config source denotes, from stdin in case it's
+// StdinSource. URLs are fetched with a short timeout, refusing redirects to
+// other hosts.
+func ReadRemoteSource(ctx context.Context, source string, stdin io.Reader) ([]byte, error) {
+	if source == StdinSource {
+		return readSource(stdin)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, remoteSourceTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" || req.URL.Host != via[0].URL.Host {
+				return fmt.Errorf("refusing redirect to %s", req.URL.Redacted())
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+
+			return nil
+		},
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", res.Status)
+	}
+
+	return readSource(res.Body)
+}
+
+// readSource reads r up to maxRemoteSourceSize, failing should there be more.
+func readSource(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxRemoteSourceSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxRemoteSourceSize {
+		return nil, fmt.Errorf("the config is larger than %d bytes", maxRemoteSourceSize)
+	}
+
+	return data, nil
+}
+
+// LoadConfigFromSource loads the app config source denotes; see
+// IsRemoteSource. Such configs have no Path, so paths they contain resolve
+// against the working directory.
+func LoadConfigFromSource(ctx context.Context, source string, stdin io.Reader, platformVersion string) (*Config, error) {
+	data, err := ReadRemoteSource(ctx, source, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := loadConfig(ctx, bytes.NewReader(data), platformVersion)
+	if err != nil {
+		return nil, err
+	}
+	cfg.source = DescribeSource(source)
+
+	return cfg, nil
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// StarterOptions describe an app a starter config is written for.
+type StarterOptions struct {
+	AppName       string
+	PrimaryRegion string
+	// Network is the custom network the app was created in, if any.
+	Network         string
+	PlatformVersion string
+}
+
+// starterSkeletons are the sections starter configs carry commented out, for
+// each platform, so that they may be filled in before deploying.
+var starterSkeletons = map[string]string{
+	MachinesPlatform: `# [build]
+#   dockerfile = "Dockerfile"
+
+# [env]
+#   PORT = "8080"
+
+# [http_service]
+#   internal_port = 8080
+#   force_https = true
+
+# [mounts]
+#   source = "data"
+#   destination = "/data"
+`,
+	NomadPlatform: `# [build]
+#   dockerfile = "Dockerfile"
+
+# [env]
+#   PORT = "8080"
+
+# [[services]]
+#   internal_port = 8080
+#   protocol = "tcp"
+#
+#   [[services.ports]]
+#     handlers = ["http"]
+#     port = 80
+#     force_https = true
+#
+#   [[services.ports]]
+#     handlers = ["tls", "http"]
+#     port = 443
+
+# [mounts]
+#   source = "data"
+#   destination = "/data"
+`,
+}
+
+// StarterConfig returns the config of an app which has just been created,
+// with nothing but its name and primary region set.
+func StarterConfig(opts StarterOptions) *Config {
+	cfg := NewConfig()
+	cfg.AppName = opts.AppName
+	cfg.SetPlatformVersion(opts.PlatformVersion)
+
+	if cfg.ForMachines() {
+		cfg.PrimaryRegion = opts.PrimaryRegion
+	} else if opts.PrimaryRegion != "" {
+		cfg.Definition["primary_region"] = opts.PrimaryRegion
+	}
+
+	if opts.Network != "" {
+		cfg.comments = append(cfg.comments,
+			fmt.Sprintf("%s was created in the %s network, apart from the other apps of its organization.", opts.AppName, opts.Network))
+	}
+
+	return cfg
+}
+
+// EncodeStarterConfig encodes the starter config of the app opts describe,
+// followed by the sections it leaves commented out. It fails should the
+// result not load back as a valid config.
+func EncodeStarterConfig(ctx context.Context, opts StarterOptions) ([]byte, error) {
+	cfg := StarterConfig(opts)
+
+	var b bytes.Buffer
+	if err := cfg.EncodeTo(&b); err != nil {
+		return nil, err
+	}
+
+	if skeleton := starterSkeletons[cfg.platformVersion]; skeleton != "" {
+		if !bytes.HasSuffix(b.Bytes(), []byte("\n\n")) {
+			b.WriteString("\n")
+		}
+		b.WriteString(skeleton)
+	}
+
+	loaded, err := loadConfig(ctx, bytes.NewReader(b.Bytes()), cfg.platformVersion)
+	if err != nil {
+		return nil, fmt.Errorf("the starter config doesn't load: %w", err)
+	}
+	if err := loaded.Validate(); err != nil {
+		return nil, fmt.Errorf("the starter config isn't valid: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
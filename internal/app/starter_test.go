@@ -0,0 +1,36 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeStarterConfig(t *testing.T) {
+	for _, platform := range []string{MachinesPlatform, NomadPlatform} {
+		t.Run(platform, func(t *testing.T) {
+			data, err := EncodeStarterConfig(context.Background(), StarterOptions{
+				AppName:         "test-app",
+				PrimaryRegion:   "ams",
+				Network:         "isolated",
+				PlatformVersion: platform,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "# test-app was created in the isolated network")
+			assert.Contains(t, string(data), "# [build]")
+
+			cfg, err := loadConfig(context.Background(), bytes.NewReader(data), platform)
+			require.NoError(t, err)
+			assert.Equal(t, "test-app", cfg.AppName)
+
+			if platform == MachinesPlatform {
+				assert.Equal(t, "ams", cfg.PrimaryRegion)
+			} else {
+				assert.Equal(t, map[string]interface{}{"primary_region": "ams"}, cfg.Definition)
+			}
+		})
+	}
+}
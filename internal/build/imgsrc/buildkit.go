@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 
 	"github.com/docker/docker/api/types"
@@ -77,12 +78,81 @@ func getBuildNodeID() string {
 
 type tracer struct {
 	displayCh chan *buildkitClient.SolveStatus
+
+	// steps records, by vertex digest, the build steps seen so far and
+	// whether BuildKit served them from its cache
+	steps map[string]*buildStep
+	order []string
+}
+
+type buildStep struct {
+	name   string
+	cached bool
 }
 
 func newTracer() *tracer {
 	return &tracer{
 		displayCh: make(chan *buildkitClient.SolveStatus),
+		steps:     map[string]*buildStep{},
+	}
+}
+
+// StageCache counts the steps of a build stage and how many of them were
+// served from the build cache.
+type StageCache struct {
+	Stage  string
+	Steps  int
+	Cached int
+}
+
+// stepNameRegexp matches the prefix BuildKit gives the names of Dockerfile
+// steps, e.g. [build 2/4] or [2/4] for the steps of unnamed stages.
+var stepNameRegexp = regexp.MustCompile(`^\[(?:(\S+) )?\d+/\d+\]`)
+
+func (t *tracer) record(v *controlapi.Vertex) {
+	key := v.Digest.String()
+
+	step, ok := t.steps[key]
+	if !ok {
+		step = &buildStep{name: v.Name}
+		t.steps[key] = step
+		t.order = append(t.order, key)
 	}
+	step.cached = step.cached || v.Cached
+}
+
+// stageCache returns the cache counts of the stages seen, in the order
+// their first steps were.
+func (t *tracer) stageCache() (stages []StageCache) {
+	index := map[string]int{}
+
+	for _, d := range t.order {
+		step := t.steps[d]
+
+		m := stepNameRegexp.FindStringSubmatch(step.name)
+		if m == nil {
+			continue
+		}
+
+		stage := m[1]
+		if stage == "" {
+			stage = "default"
+		}
+
+		i, ok := index[stage]
+		if !ok {
+			i = len(stages)
+			index[stage] = i
+			stages = append(stages, StageCache{Stage: stage})
+		}
+
+		stages[i].Steps++
+		if step.cached {
+			stages[i].Cached++
+		}
+	}
+
+	return
 }
 
 func (t *tracer) write(msg jsonmessage.JSONMessage) {
@@ -103,6 +173,7 @@ func (t *tracer) write(msg jsonmessage.JSONMessage) {
 
 	s := buildkitClient.SolveStatus{}
 	for _, v := range resp.Vertexes {
+		t.record(v)
 		s.Vertexes = append(s.Vertexes, &buildkitClient.Vertex{
 			Digest:    v.Digest,
 			Inputs:    v.Inputs,
@@ -0,0 +1,166 @@
+package imgsrc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// cosignSignatureAnnotation is the annotation of the layers of cosign
+// signature manifests holding the signature of their payload.
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// ErrSignatureMismatch is returned by CosignVerifier for images without a
+// signature the key verifies.
+var ErrSignatureMismatch = errors.New("image signature mismatch")
+
+// CosignVerifier verifies images are signed with cosign by the holder of a
+// key, reading the signatures cosign stores next to images in their
+// registry.
+type CosignVerifier struct {
+	key crypto.PublicKey
+}
+
+// NewCosignVerifier returns a CosignVerifier for the PEM encoded public key
+// at keyRef, a path or an http(s) URL.
+func NewCosignVerifier(ctx context.Context, keyRef string) (*CosignVerifier, error) {
+	data, err := readCosignKey(ctx, keyRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading cosign key %s: %w", keyRef, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("cosign key %s isn't a PEM encoded public key", keyRef)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing cosign key %s: %w", keyRef, err)
+	}
+
+	return &CosignVerifier{key: key}, nil
+}
+
+func readCosignKey(ctx context.Context, keyRef string) ([]byte, error) {
+	if !strings.HasPrefix(keyRef, "https://") && !strings.HasPrefix(keyRef, "http://") {
+		return os.ReadFile(keyRef)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keyRef, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", res.Status)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+func (*CosignVerifier) Name() string {
+	return "cosign"
+}
+
+type signatureManifest struct {
+	Layers []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+type signaturePayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Verify checks any of the signatures of image verifies with the key and
+// signs the digest of image.
+func (v *CosignVerifier) Verify(ctx context.Context, image *RemoteImage) error {
+	// cosign tags signatures sha256-<hex>.sig
+	tag := strings.Replace(image.Digest, ":", "-", 1) + ".sig"
+	accept := strings.Join([]string{mediaTypeOCIManifest, mediaTypeDockerManifest}, ", ")
+
+	var m signatureManifest
+	if _, err := image.registry.get(ctx, fmt.Sprintf("/v2/%s/manifests/%s", image.repo, tag), accept, &m); err != nil {
+		return fmt.Errorf("failed fetching the cosign signatures of %s: %w", image.Ref, err)
+	}
+
+	for _, layer := range m.Layers {
+		encoded, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		signature, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+
+		payload, _, err := image.registry.fetch(ctx, fmt.Sprintf("/v2/%s/blobs/%s", image.repo, layer.Digest), "")
+		if err != nil {
+			return fmt.Errorf("failed fetching a cosign signature payload of %s: %w", image.Ref, err)
+		}
+
+		sum := sha256.Sum256(payload)
+		if layer.Digest != "sha256:"+hex.EncodeToString(sum[:]) {
+			continue
+		}
+
+		if !verifySignature(v.key, payload, signature) {
+			continue
+		}
+
+		var p signaturePayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			continue
+		}
+
+		if p.Critical.Image.DockerManifestDigest == image.Digest {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: none of the %d cosign signatures of %s verify with the key", ErrSignatureMismatch, len(m.Layers), image.Digest)
+}
+
+// verifySignature reports whether signature is that of payload for key, the
+// way cosign signs with each kind of key.
+func verifySignature(key crypto.PublicKey, payload, signature []byte) bool {
+	digest := sha256.Sum256(payload)
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	default:
+		return false
+	}
+}
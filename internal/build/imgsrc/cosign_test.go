@@ -0,0 +1,110 @@
+package imgsrc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signedDigest = "sha256:9c7a54a9a43cca047013b82af109fe963fde787f63f9e016fdc3384500c2823d"
+
+// serveSignature serves a cosign signature of signedDigest by key, behind a
+// bearer token challenge.
+func serveSignature(t *testing.T, key *ecdsa.PrivateKey) *RemoteImage {
+	t.Helper()
+
+	payload := []byte(`{"critical":{"identity":{"docker-reference":"example.com/my-app"},"image":{"docker-manifest-digest":"` + signedDigest + `"},"type":"cosign container image signature"},"optional":null}`)
+	sum := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	require.NoError(t, err)
+	payloadDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+	manifest, err := json.Marshal(map[string]interface{}{
+		"layers": []map[string]interface{}{{
+			"digest":      payloadDigest,
+			"annotations": map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+		}},
+	})
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_, _ = w.Write([]byte(`{"token":"t0ken"}`))
+
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:my-app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/my-app/manifests/sha256-9c7a54a9a43cca047013b82af109fe963fde787f63f9e016fdc3384500c2823d.sig":
+			_, _ = w.Write(manifest)
+		case "/v2/my-app/blobs/" + payloadDigest:
+			_, _ = w.Write(payload)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return &RemoteImage{
+		Ref:      "example.com/my-app:v1",
+		Digest:   signedDigest,
+		repo:     "my-app",
+		registry: &registryClient{baseURL: server.URL, client: server.Client()},
+	}
+}
+
+func TestCosignVerifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	image := serveSignature(t, key)
+
+	verifications := VerifyImage(context.Background(), image,
+		&CosignVerifier{key: &key.PublicKey},
+		&CosignVerifier{key: &other.PublicKey},
+	)
+	require.Len(t, verifications, 2)
+	assert.True(t, verifications[0].Verified)
+	assert.False(t, verifications[1].Verified)
+
+	err = (&CosignVerifier{key: &other.PublicKey}).Verify(context.Background(), image)
+	assert.ErrorIs(t, err, ErrSignatureMismatch)
+}
+
+func TestSplitRegistry(t *testing.T) {
+	cases := []struct {
+		ref, host, rest string
+	}{
+		{"registry.fly.io/my-app:v1", "registry.fly.io", "my-app:v1"},
+		{"localhost:5000/my-app", "localhost:5000", "my-app"},
+		{"superfly/flyctl:latest", "docker.io", "superfly/flyctl:latest"},
+		{"nginx", "docker.io", "nginx"},
+	}
+
+	for _, c := range cases {
+		host, rest := splitRegistry(c.ref)
+		assert.Equal(t, c.host, host)
+		assert.Equal(t, c.rest, rest)
+	}
+}
@@ -26,8 +26,10 @@ import (
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/cmdfmt"
 	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/internal/tracing"
 	"github.com/superfly/flyctl/iostreams"
 	"github.com/superfly/flyctl/terminal"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -187,8 +189,9 @@ func (*dockerfileBuilder) Run(ctx context.Context, dockerFactory *dockerClientFa
 		return nil, "", errors.Wrap(err, "error checking for buildkit support")
 	}
 	build.SetBuilderMetaPart2(buildkitEnabled, serverInfo.ServerVersion, fmt.Sprintf("%s/%s/%s", serverInfo.OSType, serverInfo.Architecture, serverInfo.OSVersion))
+	var stages []StageCache
 	if buildkitEnabled {
-		imageID, err = runBuildKitBuild(ctx, streams, docker, r, opts, relativedockerfilePath, buildArgs)
+		imageID, stages, err = runBuildKitBuild(ctx, streams, docker, r, opts, relativedockerfilePath, buildArgs)
 		if err != nil {
 			build.ImageBuildFinish()
 			build.BuildFinish()
@@ -225,9 +228,11 @@ func (*dockerfileBuilder) Run(ctx context.Context, dockerFactory *dockerClientFa
 	}
 
 	return &DeploymentImage{
-		ID:   img.ID,
-		Tag:  opts.Tag,
-		Size: img.Size,
+		ID:        img.ID,
+		Tag:       opts.Tag,
+		Size:      img.Size,
+		CacheFrom: opts.CacheFrom,
+		Stages:    stages,
 	}, "", nil
 }
 
@@ -246,10 +251,12 @@ func runClassicBuild(ctx context.Context, streams *iostreams.IOStreams, docker *
 	options := types.ImageBuildOptions{
 		Tags:        []string{opts.Tag},
 		BuildArgs:   buildArgs,
+		Labels:      opts.Labels,
 		AuthConfigs: authConfigs(),
 		Platform:    "linux/amd64",
 		Dockerfile:  dockerfilePath,
 		Target:      opts.Target,
+		CacheFrom:   opts.CacheFrom,
 		NoCache:     opts.NoCache,
 	}
 
@@ -276,7 +283,7 @@ func runClassicBuild(ctx context.Context, streams *iostreams.IOStreams, docker *
 
 const uploadRequestRemote = "upload-request"
 
-func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker *dockerclient.Client, r io.ReadCloser, opts ImageOptions, dockerfilePath string, buildArgs map[string]*string) (imageID string, err error) {
+func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker *dockerclient.Client, r io.ReadCloser, opts ImageOptions, dockerfilePath string, buildArgs map[string]*string) (imageID string, stages []StageCache, err error) {
 	io := iostreams.FromContext(ctx)
 	s, err := createBuildSession(opts.WorkingDir)
 	if err != nil {
@@ -296,6 +303,17 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 
 	s.Allow(secretsprovider.FromMap(finalSecrets))
 
+	// embed cache metadata in the image, so that later builds may use it as
+	// a cache source in turn
+	if len(opts.CacheFrom) > 0 {
+		if _, ok := buildArgs["BUILDKIT_INLINE_CACHE"]; !ok {
+			inline := "1"
+			buildArgs["BUILDKIT_INLINE_CACHE"] = &inline
+		}
+	}
+
+	tracer := newTracer()
+
 	eg, errCtx := errgroup.WithContext(ctx)
 
 	dialSession := func(ctx context.Context, proto string, meta map[string][]string) (net.Conn, error) {
@@ -326,6 +344,7 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 		buildOpts := types.ImageBuildOptions{
 			Tags:          []string{opts.Tag},
 			BuildArgs:     buildArgs,
+			Labels:        opts.Labels,
 			Version:       types.BuilderBuildKit,
 			AuthConfigs:   authConfigs(),
 			SessionID:     s.ID(),
@@ -334,6 +353,7 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 			Platform:      "linux/amd64",
 			Dockerfile:    dockerfilePath,
 			Target:        opts.Target,
+			CacheFrom:     opts.CacheFrom,
 			NoCache:       opts.NoCache,
 		}
 
@@ -358,7 +378,6 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 
 			// TODO: replace with iostreams
 			termFd, isTerm := term.GetFdInfo(os.Stderr)
-			tracer := newTracer()
 			var c2 console.Console
 			if io.ColorEnabled() {
 				if cons, err := console.ConsoleFromFile(os.Stderr); err == nil {
@@ -406,13 +425,18 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 	})
 
 	if err := eg.Wait(); err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return imageID, nil
+	return imageID, tracer.stageCache(), nil
 }
 
-func pushToFly(ctx context.Context, docker *dockerclient.Client, streams *iostreams.IOStreams, tag string) error {
+func pushToFly(ctx context.Context, docker *dockerclient.Client, streams *iostreams.IOStreams, tag string) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "push", attribute.String("image.tag", tag))
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	pushResp, err := docker.ImagePush(ctx, tag, types.ImagePushOptions{
 		RegistryAuth: flyRegistryAuth(),
 	})
@@ -421,7 +445,14 @@ func pushToFly(ctx context.Context, docker *dockerclient.Client, streams *iostre
 	}
 	defer pushResp.Close()
 
-	err = jsonmessage.DisplayJSONMessagesStream(pushResp, streams.ErrOut, streams.StderrFd(), streams.IsStderrTTY(), nil)
+	recordDigest := func(msg jsonmessage.JSONMessage) {
+		var result types.PushResult
+		if msg.Aux != nil && json.Unmarshal(*msg.Aux, &result) == nil && result.Digest != "" {
+			span.SetAttributes(attribute.String("image.digest", result.Digest))
+		}
+	}
+
+	err = jsonmessage.DisplayJSONMessagesStream(pushResp, streams.ErrOut, streams.StderrFd(), streams.IsStderrTTY(), recordDigest)
 	if err != nil {
 		var msgerr *jsonmessage.JSONError
 
@@ -0,0 +1,321 @@
+package imgsrc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/flyctl"
+)
+
+// MachinesPlatform is the platform Fly machines run images of.
+const MachinesPlatform = "linux/amd64"
+
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// ErrPlatformMismatch is returned by CheckPlatforms for images lacking a
+// variant machines may run.
+var ErrPlatformMismatch = errors.New("image platform mismatch")
+
+// CheckPlatforms returns an error wrapping ErrPlatformMismatch in case none
+// of the platforms of image is MachinesPlatform.
+func CheckPlatforms(image string, platforms []string) error {
+	for _, p := range platforms {
+		if p == MachinesPlatform {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(`%w: image %s is built for %s, but machines run %s images.
+Images built locally on ARM hosts such as Apple Silicon Macs are arm64; deploy with --remote-only to build on a
+remote builder, or cross-compile with "docker buildx build --platform %s". Pass --skip-arch-check to deploy anyway`,
+		ErrPlatformMismatch, image, strings.Join(platforms, ", "), MachinesPlatform, MachinesPlatform)
+}
+
+// IsFlyRegistryImage reports whether ref names an image stored in the Fly
+// registry.
+func IsFlyRegistryImage(ref string) bool {
+	return strings.HasPrefix(ref, "registry.fly.io/")
+}
+
+// FetchImagePlatforms returns the platforms, as os/arch, the image ref names
+// is available for, reading its manifest from the Fly registry. Manifest
+// lists yield all the platforms they contain.
+func FetchImagePlatforms(ctx context.Context, ref string) ([]string, error) {
+	repo, reference, err := splitRef(strings.TrimPrefix(ref, "registry.fly.io/"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &registryClient{
+		baseURL: "https://registry.fly.io",
+		token:   flyctl.GetAPIToken(),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+
+	return c.platforms(ctx, repo, reference)
+}
+
+// FetchImageDigest returns the digest of the manifest of the image ref names
+// in the Fly registry.
+func FetchImageDigest(ctx context.Context, ref string) (string, error) {
+	repo, reference, err := splitRef(strings.TrimPrefix(ref, "registry.fly.io/"))
+	if err != nil {
+		return "", err
+	}
+
+	c := &registryClient{
+		baseURL: "https://registry.fly.io",
+		token:   flyctl.GetAPIToken(),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+
+	return c.digest(ctx, repo, reference)
+}
+
+// splitRef splits ref, short of its registry host, into its repository and
+// its tag or digest.
+func splitRef(ref string) (repo, reference string, err error) {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:], nil
+	}
+
+	if i := strings.LastIndex(ref, ":"); i >= 0 && !strings.Contains(ref[i:], "/") {
+		return ref[:i], ref[i+1:], nil
+	}
+
+	if ref == "" {
+		return "", "", errors.New("empty image reference")
+	}
+
+	return ref, "latest", nil
+}
+
+type registryClient struct {
+	baseURL string
+	// token is the Fly API token, which the Fly registry accepts as the
+	// password of basic auth.
+	token string
+	// bearer is the token handed out once the registry challenged a request.
+	bearer string
+	client *http.Client
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Platform  *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform,omitempty"`
+}
+
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    descriptor   `json:"config"`
+	Manifests []descriptor `json:"manifests"`
+}
+
+type imageConfig struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+}
+
+func (c *registryClient) platforms(ctx context.Context, repo, reference string) ([]string, error) {
+	accept := strings.Join([]string{mediaTypeDockerManifestList, mediaTypeOCIIndex, mediaTypeDockerManifest, mediaTypeOCIManifest}, ", ")
+
+	var m manifest
+	mediaType, err := c.get(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repo, reference), accept, &m)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching manifest: %w", err)
+	}
+	if m.MediaType != "" {
+		mediaType = m.MediaType
+	}
+
+	switch mediaType {
+	case mediaTypeDockerManifestList, mediaTypeOCIIndex:
+		var platforms []string
+		for _, d := range m.Manifests {
+			if d.Platform != nil {
+				platforms = append(platforms, d.Platform.OS+"/"+d.Platform.Architecture)
+			}
+		}
+
+		return platforms, nil
+	case mediaTypeDockerManifest, mediaTypeOCIManifest:
+		// single platform images keep theirs in the config blob
+		var cfg imageConfig
+		if _, err := c.get(ctx, fmt.Sprintf("/v2/%s/blobs/%s", repo, m.Config.Digest), "", &cfg); err != nil {
+			return nil, fmt.Errorf("failed fetching image config: %w", err)
+		}
+
+		return []string{cfg.OS + "/" + cfg.Architecture}, nil
+	default:
+		return nil, fmt.Errorf("unsupported manifest media type %q", mediaType)
+	}
+}
+
+func (c *registryClient) digest(ctx context.Context, repo, reference string) (string, error) {
+	accept := strings.Join([]string{mediaTypeDockerManifestList, mediaTypeOCIIndex, mediaTypeDockerManifest, mediaTypeOCIManifest}, ", ")
+
+	res, err := c.do(ctx, http.MethodHead, fmt.Sprintf("/v2/%s/manifests/%s", repo, reference), accept)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status %s", res.Status)
+	}
+
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", errors.New("the registry returned no manifest digest")
+	}
+
+	return digest, nil
+}
+
+func (c *registryClient) get(ctx context.Context, path, accept string, out interface{}) (string, error) {
+	body, mediaType, err := c.fetch(ctx, path, accept)
+	if err != nil {
+		return "", err
+	}
+
+	return mediaType, json.Unmarshal(body, out)
+}
+
+// fetch returns the body the registry answers path with, along with its
+// media type.
+func (c *registryClient) fetch(ctx context.Context, path, accept string) ([]byte, string, error) {
+	res, err := c.do(ctx, http.MethodGet, path, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected response status %s", res.Status)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mediaType := strings.TrimSpace(strings.Split(res.Header.Get("Content-Type"), ";")[0])
+
+	return body, mediaType, nil
+}
+
+// do sends a request for path to the registry. Registries which challenge
+// the request for a bearer token are sent it again with one.
+func (c *registryClient) do(ctx context.Context, method, path, accept string) (*http.Response, error) {
+	res, err := c.send(ctx, method, path, accept)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || c.bearer != "" {
+		return res, err
+	}
+
+	challenge := res.Header.Get("WWW-Authenticate")
+	res.Body.Close()
+
+	if c.bearer, err = c.authorize(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return c.send(ctx, method, path, accept)
+}
+
+func (c *registryClient) send(ctx context.Context, method, path, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	switch {
+	case c.bearer != "":
+		req.Header.Set("Authorization", "Bearer "+c.bearer)
+	case c.token != "":
+		req.SetBasicAuth("x", c.token)
+	}
+
+	return c.client.Do(req)
+}
+
+// authorize returns the bearer token the service a registry challenged a
+// request with hands out for it, as Docker Hub and most other registries do
+// even for anonymous pulls.
+func (c *registryClient) authorize(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+	}
+
+	var realm string
+	query := url.Values{}
+	for _, param := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			continue
+		}
+
+		value = strings.Trim(value, `"`)
+		if key == "realm" {
+			realm = value
+		} else {
+			query.Set(key, value)
+		}
+	}
+	if realm == "" {
+		return "", fmt.Errorf("registry authentication challenge %q names no realm", challenge)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.token != "" {
+		req.SetBasicAuth("x", c.token)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed authenticating with the registry: %s", res.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return "", errors.New("the registry handed out no token")
+	}
+
+	return token.Token, nil
+}
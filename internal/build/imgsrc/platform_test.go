@@ -0,0 +1,77 @@
+package imgsrc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveFixtures(t *testing.T, routes map[string]string) *registryClient {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", "manifests", fixture))
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	return &registryClient{baseURL: server.URL, client: server.Client()}
+}
+
+func TestPlatformsManifestList(t *testing.T) {
+	c := serveFixtures(t, map[string]string{
+		"/v2/my-app/manifests/deployment-1": "list.json",
+	})
+
+	platforms, err := c.platforms(context.Background(), "my-app", "deployment-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"linux/arm64", "linux/amd64"}, platforms)
+	assert.NoError(t, CheckPlatforms("registry.fly.io/my-app:deployment-1", platforms))
+}
+
+func TestPlatformsSingleArch(t *testing.T) {
+	c := serveFixtures(t, map[string]string{
+		"/v2/my-app/manifests/deployment-1": "single.json",
+		"/v2/my-app/blobs/sha256:9c7a54a9a43cca047013b82af109fe963fde787f63f9e016fdc3384500c2823d": "config_arm64.json",
+	})
+
+	platforms, err := c.platforms(context.Background(), "my-app", "deployment-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"linux/arm64"}, platforms)
+	assert.ErrorIs(t, CheckPlatforms("registry.fly.io/my-app:deployment-1", platforms), ErrPlatformMismatch)
+}
+
+func TestSplitRef(t *testing.T) {
+	cases := []struct {
+		ref, repo, reference string
+	}{
+		{"my-app:deployment-1", "my-app", "deployment-1"},
+		{"my-app@sha256:abc", "my-app", "sha256:abc"},
+		{"my-app", "my-app", "latest"},
+	}
+
+	for _, c := range cases {
+		repo, reference, err := splitRef(c.ref)
+		require.NoError(t, err)
+		assert.Equal(t, c.repo, repo)
+		assert.Equal(t, c.reference, reference)
+	}
+}
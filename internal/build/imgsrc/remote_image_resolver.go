@@ -32,9 +32,10 @@ func (s *remoteImageResolver) Run(ctx context.Context, _ *dockerClientFactory, s
 	fmt.Fprintf(streams.ErrOut, "image found: %s\n", img.ID)
 
 	di := &DeploymentImage{
-		ID:   img.ID,
-		Tag:  img.Ref,
-		Size: int64(img.CompressedSize),
+		ID:     img.ID,
+		Tag:    img.Ref,
+		Size:   int64(img.CompressedSize),
+		Digest: img.Digest,
 	}
 
 	return di, "", nil
@@ -36,11 +36,14 @@ type ImageOptions struct {
 	Publish         bool
 	Tag             string
 	Target          string
+	CacheFrom       []string
 	NoCache         bool
 	BuiltIn         string
 	BuiltInSettings map[string]interface{}
 	Builder         string
 	Buildpacks      []string
+	// Labels are applied to the built image.
+	Labels map[string]string
 }
 
 type RefOptions struct {
@@ -56,6 +59,17 @@ type DeploymentImage struct {
 	ID   string
 	Tag  string
 	Size int64
+	// Digest is the digest of the manifest of the image, where the resolver
+	// learns it.
+	Digest string
+
+	// CacheFrom lists the images the build used as cache sources.
+	CacheFrom []string
+	// Stages holds per stage cache counts for builds BuildKit ran.
+	Stages []StageCache
+	// Labels are the labels the deployment applies to the image and copies
+	// into the metadata of its machines.
+	Labels map[string]string
 }
 
 type Resolver struct {
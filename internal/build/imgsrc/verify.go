@@ -0,0 +1,112 @@
+package imgsrc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/flyctl"
+)
+
+// RemoteImage is an image in a registry, pinned to the digest of its
+// manifest.
+type RemoteImage struct {
+	Ref    string
+	Digest string
+
+	repo     string
+	registry *registryClient
+}
+
+// NewRemoteImage returns the image ref names, pinned to digest or, if it's
+// empty, to the digest its registry resolves ref to.
+func NewRemoteImage(ctx context.Context, ref, digest string) (*RemoteImage, error) {
+	host, rest := splitRegistry(ref)
+
+	repo, reference, err := splitRef(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &registryClient{
+		baseURL: "https://" + host,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	switch host {
+	case "registry.fly.io":
+		c.token = flyctl.GetAPIToken()
+	case "docker.io":
+		c.baseURL = "https://registry-1.docker.io"
+		if !strings.Contains(repo, "/") {
+			repo = "library/" + repo
+		}
+	}
+
+	if digest == "" {
+		if digest, err = c.digest(ctx, repo, reference); err != nil {
+			return nil, fmt.Errorf("failed resolving the digest of %s: %w", ref, err)
+		}
+	}
+
+	return &RemoteImage{Ref: ref, Digest: digest, repo: repo, registry: c}, nil
+}
+
+// PinnedRef returns the ref of the image pinned to its digest, such as
+// example.com/my-app@sha256:<hex>.
+func (i *RemoteImage) PinnedRef() string {
+	name := i.Ref
+	if at := strings.Index(name, "@"); at >= 0 {
+		name = name[:at]
+	} else if colon := strings.LastIndex(name, ":"); colon >= 0 && !strings.Contains(name[colon:], "/") {
+		name = name[:colon]
+	}
+
+	return name + "@" + i.Digest
+}
+
+// splitRegistry splits ref into the host of its registry, Docker Hub for refs
+// naming none, and the rest of it.
+func splitRegistry(ref string) (host, rest string) {
+	if i := strings.Index(ref, "/"); i >= 0 {
+		if first := ref[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			return first, ref[i+1:]
+		}
+	}
+
+	return "docker.io", ref
+}
+
+// ImageVerifier checks an image before it's deployed, such as that it's
+// signed by a trusted key. Verifiers are run before any machine is touched.
+type ImageVerifier interface {
+	// Name identifies the verifier in output.
+	Name() string
+	// Verify returns an error explaining why image fails verification.
+	Verify(ctx context.Context, image *RemoteImage) error
+}
+
+// Verification is the outcome of running an ImageVerifier.
+type Verification struct {
+	Verifier string `json:"verifier"`
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// VerifyImage runs all the verifiers against image, returning their outcomes
+// in order.
+func VerifyImage(ctx context.Context, image *RemoteImage, verifiers ...ImageVerifier) []Verification {
+	verifications := make([]Verification, 0, len(verifiers))
+	for _, v := range verifiers {
+		verification := Verification{Verifier: v.Name(), Verified: true}
+		if err := v.Verify(ctx, image); err != nil {
+			verification.Verified = false
+			verification.Error = err.Error()
+		}
+
+		verifications = append(verifications, verification)
+	}
+
+	return verifications
+}
@@ -4,6 +4,7 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +15,8 @@ import (
 	"github.com/superfly/flyctl/iostreams"
 	"github.com/superfly/graphql"
 
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/env"
 	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/logger"
 
@@ -33,22 +36,33 @@ func Run(ctx context.Context, io *iostreams.IOStreams, args ...string) int {
 
 	cs := io.ColorScheme()
 
-	switch _, err := cmd.ExecuteContextC(ctx); {
+	executed, err := cmd.ExecuteContextC(ctx)
+
+	report := printError
+	if err != nil && wantsJSON(executed) {
+		report = printJSONError
+	}
+
+	switch {
 	case err == nil:
 		return 0
 	case errors.Is(err, context.Canceled), errors.Is(err, terminal.InterruptErr):
 		return 127
 	case errors.Is(err, context.DeadlineExceeded):
-		printError(io.ErrOut, cs, err)
+		report(io.ErrOut, cs, err)
 
 		return 126
 	case isUnchangedError(err):
 		// This means the deployment was a noop, which is noteworthy but not something we should
 		// fail CI on. Print a warning and exit 0. Remove this once we're fully on Machines!
-		printError(io.ErrOut, cs, err)
+		report(io.ErrOut, cs, err)
 		return 0
 	default:
-		printError(io.ErrOut, cs, err)
+		report(io.ErrOut, cs, err)
+
+		if code, ok := flyerr.GetExitCode(err); ok {
+			return code
+		}
 
 		return 1
 	}
@@ -89,6 +103,41 @@ func printError(w io.Writer, cs *iostreams.ColorScheme, err error) {
 	_, _ = b.WriteTo(w)
 }
 
+// wantsJSON reports whether the output of cmd is requested in JSON.
+func wantsJSON(cmd *cobra.Command) bool {
+	if env.IsTruthy(config.JSONOutputEnvKey) {
+		return true
+	}
+
+	if cmd == nil {
+		return false
+	}
+
+	enabled, _ := cmd.Flags().GetBool("json")
+
+	return enabled
+}
+
+// jsonError is how failures are reported in JSON, so that scripts may tell
+// them apart by their code.
+type jsonError struct {
+	Code        flyerr.Code `json:"code"`
+	Message     string      `json:"message"`
+	Description string      `json:"description,omitempty"`
+	Suggestion  string      `json:"suggestion,omitempty"`
+	RequestIDs  []string    `json:"request_ids,omitempty"`
+}
+
+func printJSONError(w io.Writer, _ *iostreams.ColorScheme, err error) {
+	_ = json.NewEncoder(w).Encode(jsonError{
+		Code:        flyerr.GetCode(err),
+		Message:     err.Error(),
+		Description: flyerr.GetErrorDescription(err),
+		Suggestion:  flyerr.GetErrorSuggestion(err),
+		RequestIDs:  flyerr.GetRequestIDs(err),
+	})
+}
+
 // TODO: remove this once generation of the docs has been refactored.
 func NewRootCommand() *cobra.Command {
 	return root.New()
@@ -32,6 +32,7 @@ func New() (cmd *cobra.Command) {
 		newStart(),
 		newStop(),
 		newRestart(),
+		newSOCKS5(),
 	)
 
 	if env.IsTruthy("DEV") {
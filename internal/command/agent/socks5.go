@@ -0,0 +1,116 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/iostreams"
+	"github.com/superfly/flyctl/proxy"
+
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
+)
+
+func newSOCKS5() (cmd *cobra.Command) {
+	const (
+		short = "Serve a SOCKS5 proxy to the private network of an organization"
+		long  = `Serve a SOCKS5 proxy which routes connections over the WireGuard tunnel
+of an organization, so local tools can reach its 6PN addresses and .internal
+names without a 'fly proxy' per port. Names are resolved through the tunnel's
+DNS. Destinations outside of the private network are refused unless
+--allow-public is given, in which case they're dialed from this host.
+
+The proxy requires no authentication and thus only listens on localhost.
+`
+		usage = "socks5"
+	)
+
+	cmd = command.New(usage, short, long, runSOCKS5,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.String{
+			Name:        "listen",
+			Description: "The localhost address to listen on",
+			Default:     "127.0.0.1:1080",
+		},
+		flag.Bool{
+			Name:        "allow-public",
+			Description: "Allow connections to destinations outside of the private network",
+		},
+	)
+
+	return
+}
+
+func runSOCKS5(ctx context.Context) (err error) {
+	listen := flag.GetString(ctx, "listen")
+	if err = checkLoopback(listen); err != nil {
+		return
+	}
+
+	slug := flag.GetOrg(ctx)
+	if slug == "" {
+		org, err := prompt.Org(ctx)
+		if err != nil {
+			return err
+		}
+		slug = org.Slug
+	}
+
+	client, err := establish(ctx)
+	if err != nil {
+		return
+	}
+
+	dialer, err := client.ConnectToTunnel(ctx, slug)
+	if err != nil {
+		return
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed listening on %s: %w", listen, err)
+	}
+
+	srv := &proxy.SOCKS5Server{
+		Listener: listener,
+		Dial:     dialer.DialContext,
+		Resolve: func(ctx context.Context, host string) (string, error) {
+			return client.Resolve(ctx, slug, host)
+		},
+	}
+	if flag.GetBool(ctx, "allow-public") {
+		srv.DialPublic = (&net.Dialer{}).DialContext
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "Serving a SOCKS5 proxy to the private network of %s on %s; press Ctrl-C to stop\n", slug, listener.Addr())
+
+	return srv.Serve(ctx)
+}
+
+// checkLoopback returns an error unless addr is that of a loopback interface.
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+
+	if host == "localhost" {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("the SOCKS5 proxy requires no authentication and may only listen on localhost, not %s", addr)
+	}
+
+	return nil
+}
@@ -48,9 +48,13 @@ The LIST command will list all currently registered applications.
 func BuildContext(ctx context.Context, app *api.AppCompact) (context.Context, error) {
 	client := client.FromContext(ctx).API()
 
-	agentclient, err := agent.Establish(ctx, client)
-	if err != nil {
-		return nil, fmt.Errorf("can't establish agent %w", err)
+	agentclient := agent.ClientFromContext(ctx)
+	if agentclient == nil {
+		var err error
+		if agentclient, err = agent.Establish(ctx, client); err != nil {
+			return nil, fmt.Errorf("can't establish agent %w", err)
+		}
+		ctx = agent.ClientWithContext(ctx, agentclient)
 	}
 
 	dialer, err := agentclient.Dialer(ctx, app.Organization.Slug)
@@ -3,6 +3,7 @@ package apps
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -10,9 +11,13 @@ import (
 	"github.com/superfly/flyctl/iostreams"
 
 	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/helpers"
+	appconfig "github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/networks"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/prompt"
 	"github.com/superfly/flyctl/internal/render"
 )
@@ -20,8 +25,8 @@ import (
 func newCreate() (cmd *cobra.Command) {
 	const (
 		long = `The APPS CREATE command will register a new application
-with the Fly platform. It will not generate a configuration file, but one
-may be fetched with 'fly config save -a <app_name>'`
+with the Fly platform. It will not generate a configuration file unless
+--save-config is passed, but one may be fetched with 'fly config save -a <app_name>'`
 
 		short = "Create a new application"
 		usage = "create [APPNAME]"
@@ -45,13 +50,23 @@ may be fetched with 'fly config save -a <app_name>'`
 		},
 		flag.String{
 			Name:        "network",
-			Description: "Specify custom network id",
+			Description: "Name of the custom network to place the app in (see 'flyctl networks')",
 		},
 		flag.Bool{
 			Name:        "machines",
 			Description: "Use the machines platform",
 		},
+		flag.String{
+			Name:        "save-config",
+			Description: "Write a starter fly.toml for the app, to fly.toml unless a path is given as --save-config=PATH",
+			NoOptDefVal: appconfig.DefaultConfigFileName,
+		},
+		flag.Bool{
+			Name:        "force",
+			Description: "Overwrite the file --save-config writes to, should it exist",
+		},
 		flag.Org(),
+		flag.Region(),
 	)
 
 	return cmd
@@ -86,33 +101,98 @@ func RunCreate(ctx context.Context) (err error) {
 		}
 	}
 
+	configPath := flag.GetString(ctx, "save-config")
+	if configPath != "" && !flag.GetBool(ctx, "force") {
+		if _, err := os.Stat(configPath); err == nil {
+			return flyerr.WithCode(flyerr.CodeValidation,
+				fmt.Errorf("%s already exists; pass --force to overwrite it", configPath))
+		}
+	}
+
 	org, err := prompt.Org(ctx)
 	if err != nil {
 		return
 	}
 
+	var primaryRegion string
+	if configPath != "" {
+		region, err := prompt.Region(ctx, prompt.RegionParams{
+			Message: "Select the primary region of the app:",
+		})
+		if err != nil {
+			return err
+		}
+		primaryRegion = region.Code
+	}
+
 	input := api.CreateAppInput{
 		Name:           name,
 		OrganizationID: org.ID,
 		Machines:       flag.GetBool(ctx, "machines"),
 	}
+	if primaryRegion != "" {
+		input.PreferredRegion = api.StringPointer(primaryRegion)
+	}
 
 	if v := flag.GetString(ctx, "network"); v != "" {
-		input.Network = api.StringPointer(v)
+		network, err := networks.FindByName(ctx, org.Slug, v)
+		if err != nil {
+			return err
+		}
+		input.Network = api.StringPointer(network.Name)
 	}
 
 	app, err := client.FromContext(ctx).
 		API().
 		CreateApp(ctx, input)
+	if err != nil {
+		return err
+	}
 
-	if err == nil {
-		if cfg.JSONOutput {
-			return render.JSON(io.Out, app)
+	if configPath != "" {
+		opts := appconfig.StarterOptions{
+			AppName:         app.Name,
+			PrimaryRegion:   primaryRegion,
+			PlatformVersion: appconfig.NomadPlatform,
+		}
+		if input.Network != nil {
+			opts.Network = *input.Network
 		}
-		fmt.Fprintf(io.Out, "New app created: %s\n", app.Name)
+		if input.Machines {
+			opts.PlatformVersion = appconfig.MachinesPlatform
+		}
+
+		if err := saveStarterConfig(ctx, configPath, opts); err != nil {
+			return fmt.Errorf("app %s was created, but its config wasn't saved: %w", app.Name, err)
+		}
+	}
+
+	if cfg.JSONOutput {
+		return render.JSON(io.Out, app)
+	}
+	fmt.Fprintf(io.Out, "New app created: %s\n", app.Name)
+
+	return nil
+}
+
+// saveStarterConfig writes the starter config of the app opts describe to
+// path.
+func saveStarterConfig(ctx context.Context, path string, opts appconfig.StarterOptions) error {
+	data, err := appconfig.EncodeStarterConfig(ctx, opts)
+	if err != nil {
+		return err
 	}
 
-	return err
+	if err := helpers.MkdirAll(path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Wrote config file %s\n", helpers.PathRelativeToCWD(path))
+
+	return nil
 }
 
 func SelectAppName(ctx context.Context) (name string, err error) {
@@ -0,0 +1,154 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// historyEntry is a config version of a machine along with what it changed
+// and what made it.
+type historyEntry struct {
+	Version        string     `json:"version"`
+	Timestamp      *time.Time `json:"timestamp,omitempty"`
+	Image          string     `json:"image"`
+	ConfigOnly     bool       `json:"config_only"`
+	Trigger        string     `json:"trigger"`
+	ReleaseVersion string     `json:"release_version,omitempty"`
+}
+
+// deployHistory returns the timeline of versions, oldest first. Versions
+// deployments made are attributed to the release stamped on them; the others
+// are manual updates, or pins to the image of a release.
+func deployHistory(versions []*api.MachineVersion) []historyEntry {
+	versions = append([]*api.MachineVersion(nil), versions...)
+
+	// ULIDs sort in the order they were made in
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	var (
+		entries = make([]historyEntry, 0, len(versions))
+		prev    map[string]string
+		image   string
+	)
+
+	for i, version := range versions {
+		var (
+			conf     = version.UserConfig
+			metadata map[string]string
+		)
+		if conf == nil {
+			conf = &api.MachineConfig{}
+		}
+		metadata = conf.Metadata
+
+		entry := historyEntry{
+			Version:    version.Version,
+			Image:      conf.Image,
+			ConfigOnly: i > 0 && conf.Image == image,
+		}
+
+		if id, err := ulid.Parse(version.Version); err == nil {
+			t := ulid.Time(id.Time()).UTC()
+			entry.Timestamp = &t
+		}
+
+		release := metadata[mach.ReleaseVersionMetadataKey]
+		pinned := metadata[mach.PinnedReleaseMetadataKey]
+
+		switch {
+		case release != "" && release != prev[mach.ReleaseVersionMetadataKey]:
+			entry.Trigger = "deploy v" + release
+			entry.ReleaseVersion = release
+		case pinned != "" && pinned != prev[mach.PinnedReleaseMetadataKey]:
+			entry.Trigger = "pinned to v" + pinned
+			entry.ReleaseVersion = pinned
+		case i == 0:
+			entry.Trigger = "created"
+		default:
+			entry.Trigger = "manual update"
+		}
+
+		entries = append(entries, entry)
+		prev, image = metadata, conf.Image
+	}
+
+	return entries
+}
+
+func runDeployHistory(ctx context.Context, machineID string) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		appName = app.NameFromContext(ctx)
+	)
+
+	appCompact, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	flapsClient, err := flaps.New(ctx, appCompact)
+	if err != nil {
+		return fmt.Errorf("could not create flaps client: %w", err)
+	}
+
+	versions, err := flapsClient.GetVersions(ctx, machineID)
+	if err != nil {
+		return err
+	}
+
+	entries := deployHistory(versions)
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, entries)
+	}
+
+	var (
+		colorize = io.ColorScheme()
+		rows     = make([][]string, 0, len(entries))
+	)
+
+	// newest first, the way releases are listed
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+
+		timestamp := "-"
+		if entry.Timestamp != nil {
+			timestamp = entry.Timestamp.Format(time.RFC3339)
+		}
+
+		change := "image"
+		if entry.ConfigOnly {
+			change = colorize.Gray("config only")
+		}
+
+		rows = append(rows, []string{
+			timestamp,
+			entry.Image,
+			change,
+			entry.Trigger,
+		})
+	}
+
+	return render.TableWithColumns(io.Out, fmt.Sprintf("Deploy history of machine %s", machineID), rows,
+		render.TimestampCol("When", flag.GetAbsoluteTimestamps(ctx)),
+		render.Col("Image"),
+		render.Col("Change"),
+		render.Col("Triggered By"),
+	)
+}
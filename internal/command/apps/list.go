@@ -2,6 +2,7 @@ package apps
 
 import (
 	"context"
+	"errors"
 
 	"github.com/spf13/cobra"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/format"
 	"github.com/superfly/flyctl/internal/render"
 )
@@ -21,21 +23,47 @@ func newList() *cobra.Command {
 registered and available to this user. The list will include applications
 from all the organizations the user is a member of. Each application will
 be shown with its name, owner and when it was last deployed.
+
+With --watch, the list refreshes until interrupted, also showing the state of
+each app's latest release and how many of its machines or allocations are
+healthy. Rows that changed since the previous refresh are highlighted.
 `
 		short = "List applications"
 	)
 
-	return command.New("list", short, long, runList,
+	cmd := command.New("list", short, long, runList,
 		command.RequireSession,
 	)
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.Bool{
+			Name:        "watch",
+			Description: "Refresh the list until interrupted",
+		},
+		flag.Int{
+			Name:        "rate",
+			Description: "Refresh Rate for --watch",
+			Default:     5,
+		},
+	)
+
+	return cmd
 }
 
 func runList(ctx context.Context) (err error) {
 	cfg := config.FromContext(ctx)
-	client := client.FromContext(ctx)
+
+	if flag.GetBool(ctx, "watch") {
+		if cfg.JSONOutput {
+			return errors.New("--watch and --json are not supported together")
+		}
+
+		return runWatch(ctx)
+	}
 
 	var apps []api.App
-	if apps, err = client.API().GetApps(ctx, nil); err != nil {
+	if apps, err = listApps(ctx); err != nil {
 		return
 	}
 
@@ -66,3 +94,26 @@ func runList(ctx context.Context) (err error) {
 
 	return
 }
+
+// listApps returns the apps of the user, limited to the organization given
+// via --org, if any.
+func listApps(ctx context.Context) ([]api.App, error) {
+	apps, err := client.FromContext(ctx).API().GetApps(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	org := flag.GetOrg(ctx)
+	if org == "" {
+		return apps, nil
+	}
+
+	filtered := apps[:0]
+	for _, app := range apps {
+		if app.Organization.Slug == org {
+			filtered = append(filtered, app)
+		}
+	}
+
+	return filtered, nil
+}
@@ -41,12 +41,23 @@ including type, when, success/fail and which user triggered the release.
 			Name:        "image",
 			Description: "Display the Docker image reference of the release",
 		},
+		flag.String{
+			Name:        "deploy-history",
+			Description: "Display the timeline of images the machine with the given ID ran instead, along with the releases or updates which changed them",
+		},
+		flag.Timestamps(),
 	)
 
+	cmd.AddCommand(newRollbackInfo())
+
 	return
 }
 
 func runReleases(ctx context.Context) error {
+	if machineID := flag.GetString(ctx, "deploy-history"); machineID != "" {
+		return runDeployHistory(ctx, machineID)
+	}
+
 	appName := app.NameFromContext(ctx)
 
 	releases, err := client.FromContext(ctx).API().GetAppReleases(ctx, appName, 25)
@@ -59,7 +70,10 @@ func runReleases(ctx context.Context) error {
 		return render.JSON(out, releases)
 	}
 
-	var rows [][]string
+	var (
+		rows    [][]string
+		verbose = config.FromContext(ctx).VerboseOutput
+	)
 
 	for _, release := range releases {
 
@@ -77,6 +91,10 @@ func runReleases(ctx context.Context) error {
 			row = append(row, release.ImageRef)
 		}
 
+		if verbose {
+			row = append(row, formatReleaseCommandInstance(release))
+		}
+
 		rows = append(rows, row)
 
 	}
@@ -94,6 +112,10 @@ func runReleases(ctx context.Context) error {
 		headers = append(headers, "Docker Image")
 	}
 
+	if verbose {
+		headers = append(headers, "Release Command")
+	}
+
 	return render.Table(out, "", rows, headers...)
 }
 
@@ -115,3 +137,10 @@ func formatReleaseDescription(r api.Release) string {
 	}
 	return r.Description
 }
+
+func formatReleaseCommandInstance(release api.Release) string {
+	if release.Metadata == nil || release.Metadata.ReleaseCommandInstanceID == "" {
+		return "-"
+	}
+	return release.Metadata.ReleaseCommandInstanceID
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/machine"
@@ -16,7 +18,8 @@ import (
 
 func newRestart() *cobra.Command {
 	const (
-		long  = `The APPS RESTART command will perform a rolling restart against all running VMs`
+		long = `The APPS RESTART command will perform a rolling restart against all running VMs.
+On machines apps, Postgres leaders are restarted last.`
 		short = "Restart an application"
 		usage = "restart [APPNAME]"
 	)
@@ -54,27 +57,64 @@ func runRestart(ctx context.Context) error {
 		return err
 	}
 
+	if app.PlatformVersion == "machines" {
+		input := &api.RestartMachineInput{
+			ForceStop:        flag.GetBool(ctx, "force-stop"),
+			SkipHealthChecks: flag.GetBool(ctx, "skip-health-checks"),
+		}
+
+		return runMachinesRestart(ctx, app, input)
+	}
+
 	if app.IsPostgresApp() {
 		return fmt.Errorf("postgres apps should use `fly pg restart` instead")
 	}
 
-	ctx, err = BuildContext(ctx, app)
+	return runNomadRestart(ctx, app)
+}
+
+// runMachinesRestart restarts the started machines of app one at a time, each
+// leased only while it restarts. For Postgres apps the leader is restarted
+// last.
+func runMachinesRestart(ctx context.Context, app *api.AppCompact, input *api.RestartMachineInput) (err error) {
+	io := iostreams.FromContext(ctx)
+
+	if ctx, err = BuildContext(ctx, app); err != nil {
+		return err
+	}
+
+	machines, err := machine.ListActive(ctx)
 	if err != nil {
 		return err
 	}
 
-	input := &api.RestartMachineInput{
-		ForceStop:        flag.GetBool(ctx, "force-stop"),
-		SkipHealthChecks: flag.GetBool(ctx, "skip-health-checks"),
+	started := lo.Filter(machines, func(m *api.Machine, _ int) bool {
+		return m.State == "started"
+	})
+
+	if len(started) == 0 {
+		fmt.Fprintf(io.Out, "No started machines found for %s\n", app.Name)
+		return nil
 	}
 
-	if app.PlatformVersion == "machines" {
-		if err := machine.RollingRestart(ctx, input); err != nil {
-			return err
+	if app.IsPostgresApp() {
+		started = flypg.RestartOrder(started)
+	}
+
+	for i, m := range started {
+		fmt.Fprintf(io.Out, "[%d/%d] ", i+1, len(started))
+
+		err := machine.WithLease(ctx, m, func(ctx context.Context, m *api.Machine) error {
+			return machine.Restart(ctx, m, input)
+		})
+		if err != nil {
+			return fmt.Errorf("restarted %d of %d machines; failed restarting machine %s: %w", i, len(started), m.ID, err)
 		}
 	}
 
-	return runNomadRestart(ctx, app)
+	fmt.Fprintf(io.Out, "Restarted %d machine(s) of %s\n", len(started), app.Name)
+
+	return nil
 }
 
 func runNomadRestart(ctx context.Context, app *api.AppCompact) error {
@@ -7,9 +7,12 @@ import (
 
 	"github.com/azazeal/pause"
 	"github.com/briandowns/spinner"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/iostreams"
 
 	"github.com/superfly/flyctl/client"
@@ -20,32 +23,57 @@ import (
 func newResume() *cobra.Command {
 	const (
 		long = `The APPS RESUME command will restart a previously suspended application.
-The application will resume with its original region pool and a min count of one
-meaning there will be one running instance once restarted. Use SCALE SET MIN= to raise
-the number of configured instances.
+Machines apps resume by starting exactly the machines APPS SUSPEND stopped.
+Apps on the earlier platform resume with their original region pool and a min
+count of one meaning there will be one running instance once restarted. Use
+SCALE SET MIN= to raise the number of configured instances.
 `
 		short = "Resume an application"
 		usage = "resume [APPNAME]"
 	)
 
 	resume := command.New(usage, short, long, RunResume,
-		command.RequireSession)
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	resume.Args = cobra.MaximumNArgs(1)
 
-	resume.Hidden = true
-	resume.Args = cobra.ExactArgs(1)
+	flag.Add(resume, ResumeFlags)
 
 	return resume
 }
 
+// ResumeFlags are the flags of the resume commands.
+//
+// TODO: make internal once the resume package is removed
+var ResumeFlags = flag.Set{
+	flag.App(),
+	flag.AppConfig(),
+}
+
 // TODO: make internal once the resume package is removed
 func RunResume(ctx context.Context) (err error) {
 	io := iostreams.FromContext(ctx)
 
-	fmt.Fprintf(io.ErrOut, "Warning: this command is deprecated. Only use it if you have a previously suspended app. Use 'fly scale count 0' if you need to stop an app temporarily.\n")
-	appName := flag.FirstArg(ctx)
+	appName, err := suspendedAppName(ctx)
+	if err != nil {
+		return err
+	}
 
 	client := client.FromContext(ctx).API()
 
+	compact, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	if compact.PlatformVersion == "machines" {
+		return runMachinesResume(ctx, compact)
+	}
+
+	fmt.Fprintf(io.ErrOut, "Warning: this command is deprecated for apps on the earlier platform. Only use it if you have a previously suspended app. Use 'fly scale count 0' if you need to stop an app temporarily.\n")
+
 	var app *api.AppCompact
 	if app, err = client.ResumeApp(ctx, appName); err != nil {
 		err = fmt.Errorf("failed resuming %s: %w", appName, err)
@@ -88,3 +116,68 @@ func waitUntilAppIsRunning(ctx context.Context, app *api.AppCompact, err *error)
 		pause.For(ctx, time.Millisecond*100)
 	}
 }
+
+// runMachinesResume starts the machines of app which `apps suspend` stopped,
+// leaving alone the ones which were stopped already. Those failing to start
+// stay recorded as suspended, so that resuming again starts them.
+func runMachinesResume(ctx context.Context, app *api.AppCompact) (err error) {
+	io := iostreams.FromContext(ctx)
+
+	if ctx, err = BuildContext(ctx, app); err != nil {
+		return err
+	}
+
+	machines, err := machine.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	var suspended []*api.Machine
+	suspension, ok := machine.AppSuspension(machines)
+	if ok {
+		suspended = lo.Filter(machines, func(m *api.Machine, _ int) bool {
+			return lo.Contains(suspension.Machines, m.ID)
+		})
+	}
+
+	if len(suspended) == 0 {
+		fmt.Fprintf(io.Out, "%s has no machines suspended by flyctl to resume\n", app.Name)
+
+		if ok {
+			// the machines it stopped are gone
+			return machine.SetAppSuspension(ctx, machines, nil)
+		}
+
+		return nil
+	}
+
+	fmt.Fprintf(io.Out, "Resuming %s, suspended at %s, by starting %d machines\n", app.Name, suspension.At.Format(time.RFC3339), len(suspended))
+
+	results := forEachMachine(suspended, func(m *api.Machine) error {
+		return resumeMachine(ctx, m)
+	})
+
+	suspension.Machines = nil
+	for _, r := range results {
+		if r.err != nil {
+			suspension.Machines = append(suspension.Machines, r.machine.ID)
+		}
+	}
+
+	renderErr := renderSuspendResults(ctx, results, "resuming", "started")
+
+	if err := machine.SetAppSuspension(ctx, machines, suspension); err != nil {
+		return fmt.Errorf("failed recording which machines were started: %w", err)
+	}
+
+	return renderErr
+}
+
+// resumeMachine starts m and waits for it to start.
+func resumeMachine(ctx context.Context, m *api.Machine) error {
+	if _, err := flaps.FromContext(ctx).Start(ctx, m.ID); err != nil {
+		return fmt.Errorf("failed starting; start it with 'fly machine start %s': %w", m.ID, err)
+	}
+
+	return machine.WaitForStartOrStop(ctx, &api.Machine{ID: m.ID}, "start", 5*time.Minute)
+}
@@ -0,0 +1,107 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newRollbackInfo() *cobra.Command {
+	const (
+		short = "Show the machine configurations recorded in a deploy snapshot"
+		long  = short + `
+
+Compares each machine recorded by 'flyctl deploy --revert-on-failure' with its
+current state and prints the command restoring those that have since changed.
+`
+
+		usage = "rollback-info <snapshot-file>"
+	)
+
+	cmd := command.New(usage, short, long, runRollbackInfo,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	return cmd
+}
+
+func runRollbackInfo(ctx context.Context) error {
+	var (
+		io   = iostreams.FromContext(ctx)
+		path = flag.FirstArg(ctx)
+	)
+
+	snapshots, err := mach.ReadSnapshots(path)
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, snapshots)
+	}
+
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, snapshots.App)
+	if err != nil {
+		return fmt.Errorf("could not get app %s: %w", snapshots.App, err)
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Snapshot of %d machines of app %s taken at %s\n\n",
+		len(snapshots.Machines), snapshots.App, snapshots.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+
+	var (
+		rows    [][]string
+		changed []string
+	)
+	for _, s := range snapshots.Machines {
+		current := "-"
+		status := "unknown"
+
+		switch m, err := flapsClient.Get(ctx, s.ID); {
+		case err != nil:
+			status = "not found"
+		case m.Config == nil:
+		default:
+			current = m.Config.Image
+			if current == s.Config.Image {
+				status = "unchanged"
+			} else {
+				status = "changed"
+				changed = append(changed, s.ID)
+			}
+		}
+
+		rows = append(rows, []string{s.ID, s.Region, s.Config.Image, current, status})
+	}
+
+	if err := render.Table(io.Out, "", rows, "ID", "Region", "Snapshot Image", "Current Image", "Status"); err != nil {
+		return err
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(io.Out, "Restore changed machines with:")
+	for _, id := range changed {
+		fmt.Fprintf(io.Out, "  flyctl machine rollback %s --snapshot %s\n", id, path)
+	}
+
+	return nil
+}
@@ -3,26 +3,218 @@ package apps
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
 )
 
+// suspendConcurrency bounds the number of machines suspended or resumed at
+// once.
+const suspendConcurrency = 8
+
+// SuspendFlags are the flags of the suspend commands.
+//
+// TODO: make internal once the suspend package is removed
+var SuspendFlags = flag.Set{
+	flag.App(),
+	flag.AppConfig(),
+	flag.Bool{
+		Name:        "force",
+		Description: "Suspend Postgres apps too, stopping their leaders",
+	},
+}
+
 func newSuspend() *cobra.Command {
 	const (
-		long  = `This command is deprecated. You may still use 'fly resume' to restore suspended apps.`
-		short = "Suspend an application (deprecated)"
-		usage = "suspend"
+		long = `The APPS SUSPEND command stops all the started machines of an application,
+remembering which ones were started so that APPS RESUME starts exactly those
+again. Apps on the earlier platform can no longer be suspended; you may still
+resume those which were.`
+		short = "Suspend an application"
+		usage = "suspend [APPNAME]"
 	)
 
 	suspend := command.New(usage, short, long, RunSuspend,
-		command.RequireSession)
-	suspend.Hidden = true
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+	suspend.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(suspend, SuspendFlags)
+
 	return suspend
 }
 
 // TODO: make internal once the suspend package is removed
 func RunSuspend(ctx context.Context) (err error) {
-	return fmt.Errorf("this command is deprecated. You may still resume suspended apps using 'fly resume'. Use 'fly scale count 0' if you need to stop an app temporarily\n")
+	appName, err := suspendedAppName(ctx)
+	if err != nil {
+		return err
+	}
+
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	if app.PlatformVersion != "machines" {
+		return fmt.Errorf("apps on the earlier platform can no longer be suspended. You may still resume suspended apps using 'fly resume'. Use 'fly scale count 0' if you need to stop an app temporarily")
+	}
+
+	if app.IsPostgresApp() && !flag.GetBool(ctx, "force") {
+		return fmt.Errorf("%s is a Postgres app and suspending it stops its leader; pass --force to suspend it anyway", app.Name)
+	}
+
+	return runMachinesSuspend(ctx, app)
+}
+
+// suspendedAppName returns the app named by the first argument or, failing
+// that, by the app flag or the app's config.
+func suspendedAppName(ctx context.Context) (string, error) {
+	if name := flag.FirstArg(ctx); name != "" {
+		return name, nil
+	}
+
+	if name := app.NameFromContext(ctx); name != "" {
+		return name, nil
+	}
+
+	return "", fmt.Errorf("an app must be specified either as the first argument or with --app")
+}
+
+type suspendResult struct {
+	machine *api.Machine
+	err     error
+}
+
+func runMachinesSuspend(ctx context.Context, app *api.AppCompact) (err error) {
+	io := iostreams.FromContext(ctx)
+
+	if ctx, err = BuildContext(ctx, app); err != nil {
+		return err
+	}
+
+	machines, releaseLeases, err := machine.AcquireAllLeases(ctx)
+	defer releaseLeases(ctx, machines)
+	if err != nil {
+		return err
+	}
+
+	var started []*api.Machine
+	for _, m := range machines {
+		if m.State == "started" {
+			started = append(started, m)
+		}
+	}
+
+	if len(started) == 0 {
+		fmt.Fprintf(io.Out, "%s has no started machines to suspend\n", app.Name)
+
+		return nil
+	}
+
+	fmt.Fprintf(io.Out, "Suspending %s by stopping %d machines\n", app.Name, len(started))
+
+	suspension := &machine.Suspension{At: time.Now().UTC().Truncate(time.Second)}
+	if prev, ok := machine.AppSuspension(machines); ok {
+		// suspending again keeps the machines stopped the first time around
+		suspension.Machines = prev.Machines
+	}
+
+	results := forEachMachine(started, func(m *api.Machine) error {
+		return suspendMachine(ctx, m)
+	})
+
+	for _, r := range results {
+		if r.err == nil && !lo.Contains(suspension.Machines, r.machine.ID) {
+			suspension.Machines = append(suspension.Machines, r.machine.ID)
+		}
+	}
+
+	renderErr := renderSuspendResults(ctx, results, "suspending", "stopped")
+
+	if err := machine.SetAppSuspension(ctx, machines, suspension); err != nil {
+		return fmt.Errorf("failed recording which machines were stopped; resume will not start them: %w", err)
+	}
+
+	return renderErr
+}
+
+// suspendMachine stops m and waits for it to stop.
+func suspendMachine(ctx context.Context, m *api.Machine) error {
+	input := api.StopMachineInput{
+		ID:      m.ID,
+		Filters: &api.Filters{},
+	}
+	if err := flaps.FromContext(ctx).Stop(ctx, input); err != nil {
+		return fmt.Errorf("failed stopping: %w", err)
+	}
+
+	return machine.WaitForStartOrStop(ctx, &api.Machine{ID: m.ID}, "stop", 5*time.Minute)
+}
+
+// forEachMachine runs fn against machines concurrently, returning the outcome
+// for each of them in order.
+func forEachMachine(machines []*api.Machine, fn func(*api.Machine) error) []suspendResult {
+	var (
+		results = make([]suspendResult, len(machines))
+		sem     = make(chan struct{}, suspendConcurrency)
+		wg      sync.WaitGroup
+	)
+
+	for i, m := range machines {
+		wg.Add(1)
+
+		go func(i int, m *api.Machine) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = suspendResult{machine: m, err: fn(m)}
+		}(i, m)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func renderSuspendResults(ctx context.Context, results []suspendResult, action, done string) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		rows   = make([][]string, 0, len(results))
+		failed int
+	)
+
+	for _, r := range results {
+		status := done
+		if r.err != nil {
+			status = r.err.Error()
+			failed++
+		}
+		rows = append(rows, []string{r.machine.ID, r.machine.Name, r.machine.Region, status})
+	}
+
+	if err := render.Table(io.Out, "", rows, "ID", "Name", "Region", "Result"); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed %s %d of %d machines", action, failed, len(results))
+	}
+
+	return nil
 }
@@ -0,0 +1,298 @@
+package apps
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/inancgumus/screen"
+	"github.com/morikuni/aec"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/format"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	// watchConcurrency bounds the number of apps looked up at once
+	watchConcurrency = 16
+	// watchLookupTimeout bounds the time a single app's lookup may take
+	watchLookupTimeout = 10 * time.Second
+	// resizePollInterval is how often the terminal is checked for resizes
+	resizePollInterval = 250 * time.Millisecond
+)
+
+// appSummary is the state of an app as shown by apps list --watch.
+type appSummary struct {
+	Name          string
+	Org           string
+	Status        string
+	ReleaseStatus string
+	Healthy       int
+	Total         int
+	DeployedAt    time.Time
+	// counted reports whether Healthy and Total are known
+	counted bool
+	// stale reports whether the latest lookup of the app failed, in which
+	// case the summary is the one of the refresh before
+	stale bool
+}
+
+// changed reports whether s differs from prev in what's shown for it.
+func (s appSummary) changed(prev appSummary) bool {
+	return s.Status != prev.Status ||
+		s.ReleaseStatus != prev.ReleaseStatus ||
+		s.Healthy != prev.Healthy ||
+		s.Total != prev.Total ||
+		!s.DeployedAt.Equal(prev.DeployedAt)
+}
+
+func (s appSummary) row() []string {
+	name := s.Name
+	if s.stale {
+		name += " (stale)"
+	}
+
+	health := "-"
+	if s.counted {
+		health = fmt.Sprintf("%d/%d", s.Healthy, s.Total)
+	}
+
+	deployed := ""
+	if !s.DeployedAt.IsZero() {
+		deployed = format.RelativeTime(s.DeployedAt)
+	}
+
+	return []string{name, s.Org, s.Status, s.ReleaseStatus, health, deployed}
+}
+
+func runWatch(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	if !io.IsInteractive() {
+		return errors.New("--watch is not supported for non-interactive sessions")
+	}
+
+	rate := flag.GetInt(ctx, "rate")
+	if rate < 1 || rate > 3600 {
+		return errors.New("--rate must be in the [1, 3600] range")
+	}
+
+	// always give the cursor back, including when interrupted
+	fmt.Fprint(io.Out, aec.Hide)
+	defer fmt.Fprint(io.Out, aec.Show)
+
+	var (
+		prev    = map[string]appSummary{}
+		current []appSummary
+		changed map[string]bool
+		listErr error
+	)
+
+	for {
+		switch apps, err := listApps(ctx); {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && current == nil:
+			return err
+		case err != nil:
+			// keep showing what's known, marking all of it stale
+			listErr = err
+			for i := range current {
+				current[i].stale = true
+			}
+			changed = nil
+		default:
+			listErr = nil
+			current = summarizeApps(ctx, apps, prev)
+
+			changed = map[string]bool{}
+			for _, s := range current {
+				if p, ok := prev[s.Name]; !ok || s.changed(p) {
+					changed[s.Name] = len(prev) > 0
+				}
+			}
+
+			prev = make(map[string]appSummary, len(current))
+			for _, s := range current {
+				prev[s.Name] = s
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// redraw as soon as the terminal is resized, until it's time to
+		// refresh
+		deadline := time.Now().Add(time.Duration(rate) * time.Second)
+		for width := 0; time.Now().Before(deadline); {
+			if w := io.TerminalWidth(); w != width {
+				width = w
+				drawWatch(io, current, changed, listErr)
+			}
+
+			pause.For(ctx, resizePollInterval)
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func drawWatch(io *iostreams.IOStreams, summaries []appSummary, changed map[string]bool, listErr error) {
+	colorize := io.ColorScheme()
+
+	rows := make([][]string, 0, len(summaries))
+	for _, s := range summaries {
+		row := s.row()
+		switch {
+		case s.stale:
+			for i := range row {
+				row[i] = colorize.Gray(row[i])
+			}
+		case changed[s.Name]:
+			for i := range row {
+				row[i] = colorize.Yellow(row[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d apps at: %s\n", len(summaries), colorize.Bold(time.Now().UTC().Format("15:04:05")))
+	if listErr != nil {
+		fmt.Fprintf(&buf, "%s\n", colorize.Red(fmt.Sprintf("failed refreshing apps: %v", listErr)))
+	}
+	fmt.Fprintln(&buf)
+	_ = render.Table(&buf, "", rows, "Name", "Owner", "Status", "Release", "Healthy", "Latest Deploy")
+
+	screen.Clear()
+	screen.MoveTopLeft()
+	buf.WriteTo(io.Out)
+}
+
+// summarizeApps looks up the health of apps concurrently. Apps whose lookup
+// fails are summarized as they were in prev, marked stale.
+func summarizeApps(ctx context.Context, apps []api.App, prev map[string]appSummary) []appSummary {
+	summaries := make([]appSummary, len(apps))
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, watchConcurrency)
+	)
+
+	for i := range apps {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			app := apps[i]
+			s := appSummary{
+				Name:   app.Name,
+				Org:    app.Organization.Slug,
+				Status: app.Status,
+			}
+			if app.CurrentRelease != nil {
+				s.ReleaseStatus = app.CurrentRelease.Status
+				if app.Deployed {
+					s.DeployedAt = app.CurrentRelease.CreatedAt
+				}
+			}
+
+			if !app.Deployed || app.Status == "suspended" {
+				summaries[i] = s
+
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, watchLookupTimeout)
+			defer cancel()
+
+			var err error
+			if s.Healthy, s.Total, err = countHealthy(ctx, &app); err != nil {
+				if p, ok := prev[app.Name]; ok {
+					s = p
+				}
+				s.stale = true
+			} else {
+				s.counted = true
+			}
+
+			summaries[i] = s
+		}(i)
+	}
+
+	wg.Wait()
+
+	return summaries
+}
+
+// countHealthy returns the number of healthy machines or allocations of app
+// and their total.
+func countHealthy(ctx context.Context, app *api.App) (healthy, total int, err error) {
+	if app.PlatformVersion == "machines" {
+		var flapsClient *flaps.Client
+		flapsClient, err = flaps.New(ctx, &api.AppCompact{
+			ID:           app.ID,
+			Name:         app.Name,
+			Organization: &api.OrganizationBasic{Slug: app.Organization.Slug},
+		})
+		if err != nil {
+			return
+		}
+
+		var machines []*api.Machine
+		if machines, err = flapsClient.ListActive(ctx); err != nil {
+			return
+		}
+
+		for _, m := range machines {
+			total++
+			if machineHealthy(m) {
+				healthy++
+			}
+		}
+
+		return
+	}
+
+	status, err := client.FromContext(ctx).API().GetAppStatus(ctx, app.Name, false)
+	if err != nil {
+		return
+	}
+
+	for _, alloc := range status.Allocations {
+		total++
+		if alloc.Healthy {
+			healthy++
+		}
+	}
+
+	return
+}
+
+func machineHealthy(m *api.Machine) bool {
+	if m.State != "started" {
+		return false
+	}
+
+	for _, check := range m.Checks {
+		if check.Status != "passing" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -48,12 +48,19 @@ If you do have and account, begin with the AUTH LOGIN subcommand.
 }
 
 func runWebLogin(ctx context.Context, signup bool) error {
+	io := iostreams.FromContext(ctx)
+
+	if !canOpenBrowser() {
+		fmt.Fprintln(io.ErrOut, "no browser can be opened on this host, continuing with a user code instead")
+
+		return runDeviceLogin(ctx, signup)
+	}
+
 	auth, err := api.StartCLISessionWebAuth(state.Hostname(ctx), signup)
 	if err != nil {
 		return err
 	}
 
-	io := iostreams.FromContext(ctx)
 	if err := open.Run(auth.AuthURL); err != nil {
 		fmt.Fprintf(io.ErrOut,
 			"failed opening browser. Copy the url (%s) into a browser and continue\n",
@@ -61,23 +68,19 @@ func runWebLogin(ctx context.Context, signup bool) error {
 		)
 	}
 
-	logger := logger.FromContext(ctx)
-
 	colorize := io.ColorScheme()
 	fmt.Fprintf(io.Out, "Opening %s ...\n\n", colorize.Bold(auth.AuthURL))
 
-	token, err := waitForCLISession(ctx, logger, io.ErrOut, auth.ID)
-	switch {
-	case err == nil:
-		break
-	case errors.Is(err, context.DeadlineExceeded):
-		return errors.New("Login expired, please try again")
-	case token == "":
-		return errors.New("failed to log in, please try again")
-	default:
+	token, err := waitForCLISession(ctx, logger.FromContext(ctx), io.ErrOut, auth.ID, 15*time.Minute, time.Second)
+	if err != nil {
 		return err
 	}
 
+	return finishLogin(ctx, token)
+}
+
+// finishLogin persists token and reports the user it authenticates.
+func finishLogin(ctx context.Context, token string) error {
 	if err := persistAccessToken(ctx, token); err != nil {
 		return err
 	}
@@ -89,39 +92,46 @@ func runWebLogin(ctx context.Context, signup bool) error {
 		return fmt.Errorf("failed retrieving current user: %w", err)
 	}
 
-	fmt.Fprintf(io.Out, "successfully logged in as %s\n", colorize.Bold(user.Email))
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "successfully logged in as %s\n", io.ColorScheme().Bold(user.Email))
 
 	return nil
 }
 
-// TODO: this does NOT break on interrupts
-func waitForCLISession(parent context.Context, logger *logger.Logger, w io.Writer, id string) (token string, err error) {
-	ctx, cancel := context.WithTimeout(parent, 15*time.Minute)
+// waitForCLISession polls the session id every interval until it's
+// authorized, timeout elapses or ctx is canceled, e.g. by an interrupt.
+func waitForCLISession(parent context.Context, logger *logger.Logger, w io.Writer, id string, timeout, interval time.Duration) (token string, err error) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 
 	s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
 	s.Writer = w
 	s.Prefix = "Waiting for session..."
 	s.Start()
+	defer s.Stop()
 
-	for ctx.Err() == nil {
-		if token, err = api.GetAccessTokenForCLISession(ctx, id); err != nil {
-			logger.Debugf("failed retrieving token: %v", err)
+	for {
+		if token, err = api.GetAccessTokenForCLISession(ctx, id); err == nil && token != "" {
+			logger.Debug("retrieved access token.")
 
-			pause.For(ctx, time.Second)
+			s.FinalMSG = "Waiting for session... Done\n"
 
-			continue
+			return token, nil
 		}
 
-		logger.Debug("retrieved access token.")
+		if err != nil {
+			logger.Debugf("failed retrieving token: %v", err)
+		}
 
-		s.FinalMSG = "Waiting for session... Done\n"
-		s.Stop()
+		pause.For(ctx, interval)
 
-		break
+		switch {
+		case parent.Err() != nil:
+			return "", errors.New("login canceled")
+		case ctx.Err() != nil:
+			return "", errors.New("Login expired, please try again")
+		}
 	}
-
-	return
 }
 
 func persistAccessToken(ctx context.Context, token string) (err error) {
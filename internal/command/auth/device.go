@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/logger"
+	"github.com/superfly/flyctl/internal/state"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// runDeviceLogin logs in, or signs up if signup is set, by having the user
+// enter a short code at a verification URL on any device, so it needs neither
+// a browser nor a local listener on this host.
+func runDeviceLogin(ctx context.Context, signup bool) error {
+	auth, err := api.StartCLISessionDeviceAuth(ctx, state.Hostname(ctx), signup)
+	if err != nil {
+		return fmt.Errorf("failed starting login session: %w", err)
+	}
+
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+	)
+
+	action := "log in"
+	if signup {
+		action = "sign up"
+	}
+
+	fmt.Fprintf(io.Out, "Open %s on any device and enter the code %s to %s.\n\n",
+		colorize.Bold(auth.VerificationURL), colorize.Bold(auth.UserCode), action)
+
+	timeout := time.Duration(auth.ExpiresIn) * time.Second
+	if timeout <= 0 {
+		timeout = 15 * time.Minute
+	}
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	token, err := waitForCLISession(ctx, logger.FromContext(ctx), io.ErrOut, auth.ID, timeout, interval)
+	if err != nil {
+		return err
+	}
+
+	return finishLogin(ctx, token)
+}
+
+// canOpenBrowser reports whether a browser can likely be opened on this host,
+// which is not the case in SSH sessions without a display to forward to.
+func canOpenBrowser() bool {
+	if os.Getenv("SSH_CONNECTION") == "" && os.Getenv("SSH_TTY") == "" {
+		return true
+	}
+
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
@@ -15,8 +15,9 @@ import (
 func newLogin() *cobra.Command {
 	const (
 		long = `Logs a user into the Fly platform. Supports browser-based,
-email/password and one-time-password authentication. Defaults to using
-browser-based authentication.
+email/password, one-time-password and headless authentication, the latter
+by entering a user code on another device. Defaults to using browser-based
+authentication, or headless authentication when no browser can be opened.
 `
 		short = "Log in a user"
 	)
@@ -41,6 +42,10 @@ browser-based authentication.
 			Name:        "otp",
 			Description: "One time password",
 		},
+		flag.Bool{
+			Name:        "headless",
+			Description: "Log in with a user code entered on another device, for hosts that can't open a browser",
+		},
 	)
 
 	return cmd
@@ -57,6 +62,8 @@ func runLogin(ctx context.Context) error {
 	switch {
 	case interactive, email != "", password != "", otp != "":
 		return runShellLogin(ctx, email, password, otp)
+	case flag.GetBool(ctx, "headless"):
+		return runDeviceLogin(ctx, false)
 	default:
 		return runWebLogin(ctx, false)
 	}
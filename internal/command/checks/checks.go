@@ -16,7 +16,13 @@ func New() *cobra.Command {
 	listCmd := command.New("list", "List health checks", "", runAppCheckList, command.RequireSession, command.RequireAppName)
 	flag.Add(listCmd, commonFlags,
 		flag.String{Name: "check-name", Description: "Filter checks by name"},
+		flag.String{Name: "machine", Description: "Filter checks by machine ID. Machines apps only."},
+		flag.Bool{Name: "wait", Description: "Wait for all checks to pass, failing once the timeout elapses"},
+		flag.Int{Name: "timeout", Description: "Seconds to wait for checks to pass with --wait", Default: 300},
 	)
 	cmd.AddCommand(listCmd)
+
+	// fly checks history
+	cmd.AddCommand(newHistory(commonFlags))
 	return cmd
 }
@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/flag"
+)
+
+// checkState is the state of a check on a single machine, or allocation in
+// the case of nomad apps.
+type checkState struct {
+	Name      string
+	Status    string
+	Target    string
+	Type      string
+	Output    string
+	UpdatedAt *time.Time
+
+	// Maintenance is set on the checks of machines `machine pause-checks`
+	// excluded, which count as passing whatever their status.
+	Maintenance bool
+}
+
+func (c checkState) key() string {
+	return c.Target + "/" + c.Name
+}
+
+func (c checkState) passing() bool {
+	return c.Maintenance || c.Status == "passing"
+}
+
+// displayStatus returns the status of c, noting any maintenance.
+func (c checkState) displayStatus() string {
+	if c.Maintenance {
+		return "maintenance (" + c.Status + ")"
+	}
+
+	return c.Status
+}
+
+// fetchChecks returns the checks of app the --check-name and --machine flags
+// select, sorted by target and name.
+func fetchChecks(ctx context.Context, app *api.AppCompact) ([]checkState, error) {
+	if app.PlatformVersion == "machines" {
+		return fetchMachineChecks(ctx, app)
+	}
+
+	nomadChecks, err := fetchNomadChecks(ctx, app.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]checkState, 0, len(nomadChecks))
+	for _, check := range nomadChecks {
+		updatedAt := check.UpdatedAt
+		checks = append(checks, checkState{
+			Name:      check.Name,
+			Status:    check.Status,
+			Target:    check.Allocation.IDShort,
+			Output:    check.Output,
+			UpdatedAt: &updatedAt,
+		})
+	}
+	sortChecks(checks)
+
+	return checks, nil
+}
+
+func fetchMachineChecks(ctx context.Context, app *api.AppCompact) ([]checkState, error) {
+	var (
+		nameFilter    = flag.GetString(ctx, "check-name")
+		machineFilter = flag.GetString(ctx, "machine")
+	)
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		checks []checkState
+		now    = time.Now()
+	)
+	for _, machine := range machines {
+		if machineFilter != "" && machineFilter != machine.ID {
+			continue
+		}
+		_, paused := machine.ChecksPaused(now)
+
+		for _, check := range machine.Checks {
+			if nameFilter != "" && nameFilter != check.Name {
+				continue
+			}
+
+			checks = append(checks, checkState{
+				Name:        check.Name,
+				Status:      check.Status,
+				Target:      machine.ID,
+				Type:        machineCheckType(machine, check.Name),
+				Output:      check.Output,
+				UpdatedAt:   check.UpdatedAt,
+				Maintenance: paused,
+			})
+		}
+	}
+	sortChecks(checks)
+
+	return checks, nil
+}
+
+// machineCheckType returns the type of the check named name machine is
+// configured with, if any.
+func machineCheckType(machine *api.Machine, name string) string {
+	if machine.Config == nil {
+		return ""
+	}
+
+	return machine.Config.Checks[name].Type
+}
+
+func fetchNomadChecks(ctx context.Context, appName string) ([]api.CheckState, error) {
+	web := client.FromContext(ctx).API()
+
+	var nameFilter *string
+	if val := flag.GetString(ctx, "check-name"); val != "" {
+		nameFilter = api.StringPointer(val)
+	}
+
+	return web.GetAppHealthChecks(ctx, appName, nameFilter, nil, api.BoolPointer(false))
+}
+
+func sortChecks(checks []checkState) {
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].Target != checks[j].Target {
+			return checks[i].Target < checks[j].Target
+		}
+
+		return checks[i].Name < checks[j].Name
+	})
+}
@@ -0,0 +1,413 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	// outputSnippetLength is how much of the output of checks the history
+	// table shows.
+	outputSnippetLength = 60
+
+	// historyMetadataKey is the app metadata holding the check history of
+	// apps it's enabled for.
+	historyMetadataKey = mach.AppMetadataKeyPrefix + "check_history"
+
+	// maxRecordedTransitions is how many of the latest transitions the
+	// check history keeps, as metadata values are meant to be small.
+	maxRecordedTransitions = 50
+
+	// machineCheckName stands for the machine itself in the transitions
+	// derived from its events, its checks failing along with it.
+	machineCheckName = "(machine)"
+)
+
+// checkTransition is a change of the state of a check on a machine.
+type checkTransition struct {
+	Timestamp time.Time `json:"timestamp"`
+	Machine   string    `json:"machine"`
+	Check     string    `json:"check"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Output    string    `json:"output,omitempty"`
+}
+
+// checkHistory is the check history recorded in app metadata: the last
+// status seen of each check, keyed by machine and name, along with the latest
+// transitions between them.
+type checkHistory struct {
+	Last        map[string]string `json:"last"`
+	Transitions []checkTransition `json:"transitions"`
+}
+
+func newHistory(commonFlags flag.Set) *cobra.Command {
+	const (
+		long = `Shows the state transitions of the health checks of an app. Once enabled
+with checks history enable, the transitions are recorded in the metadata of the
+app as flyctl observes them, each time checks history runs, so running it
+regularly keeps them from being missed. Until then, they're derived from the
+current state of checks and the events of machines, which only go back as far
+as machines keep them.
+`
+		short = "Show the state transitions of health checks"
+	)
+
+	cmd := command.New("history", short, long, runHistory,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd, commonFlags,
+		flag.String{Name: "since", Description: "How far back to show transitions from", Default: "24h"},
+		flag.String{Name: "check-name", Description: "Filter transitions by check name"},
+		flag.String{Name: "machine", Description: "Filter transitions by machine ID"},
+	)
+
+	enable := command.New("enable", "Record the check transitions of an app", "", runHistoryEnable,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+	enable.Args = cobra.NoArgs
+	flag.Add(enable, commonFlags)
+
+	disable := command.New("disable", "Stop recording the check transitions of an app", "", runHistoryDisable,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+	disable.Args = cobra.NoArgs
+	flag.Add(disable, commonFlags)
+
+	cmd.AddCommand(enable, disable)
+
+	return cmd
+}
+
+// historyMachines returns the active machines of the app of ctx, with a
+// flaps client for them in the returned context.
+func historyMachines(ctx context.Context) (context.Context, []*api.Machine, error) {
+	appName := app.NameFromContext(ctx)
+
+	appCompact, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get app: %w", err)
+	}
+
+	if appCompact.PlatformVersion != "machines" {
+		return nil, nil, flyerr.WithCode(flyerr.CodePlatformUnsupported,
+			errors.New("checks history is only available for machines apps"))
+	}
+
+	flapsClient, err := flaps.New(ctx, appCompact)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx = flaps.NewContext(ctx, flapsClient)
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("machines could not be retrieved: %w", err)
+	}
+
+	return ctx, machines, nil
+}
+
+func runHistoryEnable(ctx context.Context) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		appName = app.NameFromContext(ctx)
+	)
+
+	ctx, machines, err := historyMachines(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(machines) == 0 {
+		return fmt.Errorf("%s has no machines to record the check history of", appName)
+	}
+
+	if _, ok := mach.AppMetadata(machines, historyMetadataKey); ok {
+		fmt.Fprintf(io.Out, "The check transitions of %s are already recorded\n", appName)
+		return nil
+	}
+
+	// The checks as they are now are what later transitions are from
+	history := &checkHistory{}
+	history.observe(machines, time.Now())
+
+	if err := saveHistory(ctx, machines, history); err != nil {
+		return fmt.Errorf("failed enabling the check history of %s: %w", appName, err)
+	}
+
+	fmt.Fprintf(io.Out, "Recording the check transitions of %s\n", appName)
+
+	return nil
+}
+
+func runHistoryDisable(ctx context.Context) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		appName = app.NameFromContext(ctx)
+	)
+
+	ctx, machines, err := historyMachines(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := mach.DeleteAppMetadata(ctx, machines, historyMetadataKey); err != nil {
+		return fmt.Errorf("failed disabling the check history of %s: %w", appName, err)
+	}
+
+	fmt.Fprintf(io.Out, "No longer recording the check transitions of %s\n", appName)
+
+	return nil
+}
+
+func runHistory(ctx context.Context) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		appName = app.NameFromContext(ctx)
+	)
+
+	window, err := time.ParseDuration(flag.GetString(ctx, "since"))
+	if err != nil {
+		return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("invalid --since: %w", err))
+	}
+	since := time.Now().Add(-window)
+
+	ctx, machines, err := historyMachines(ctx)
+	if err != nil {
+		return err
+	}
+
+	var transitions []checkTransition
+
+	if value, ok := mach.AppMetadata(machines, historyMetadataKey); ok {
+		history := &checkHistory{}
+		if err := json.Unmarshal([]byte(value), history); err != nil {
+			return fmt.Errorf("failed decoding the check history of %s: %w", appName, err)
+		}
+
+		if history.observe(machines, time.Now()) {
+			if err := saveHistory(ctx, machines, history); err != nil {
+				return fmt.Errorf("failed recording the check history of %s: %w", appName, err)
+			}
+		}
+
+		for _, t := range history.Transitions {
+			if !t.Timestamp.Before(since) {
+				transitions = append(transitions, t)
+			}
+		}
+	} else {
+		fmt.Fprintf(io.ErrOut, "The check history of %s isn't recorded, so transitions are derived from machine events; enable it with `fly checks history enable`\n", appName)
+
+		for _, machine := range machines {
+			transitions = append(transitions, eventTransitions(machine, since)...)
+		}
+		sortTransitions(transitions)
+	}
+
+	transitions = filterTransitions(transitions, flag.GetString(ctx, "check-name"), flag.GetString(ctx, "machine"))
+
+	if config.FromContext(ctx).JSONOutput {
+		if transitions == nil {
+			transitions = []checkTransition{}
+		}
+
+		return render.JSON(io.Out, transitions)
+	}
+
+	rows := make([][]string, 0, len(transitions))
+	for _, t := range transitions {
+		from := t.From
+		if from == "" {
+			from = "unknown"
+		}
+
+		rows = append(rows, []string{
+			t.Timestamp.Format(time.RFC3339),
+			t.Machine,
+			t.Check,
+			from + " → " + t.To,
+			outputSnippet(t.Output),
+		})
+	}
+
+	return render.Table(io.Out, fmt.Sprintf("Check transitions of %s since %s", appName, since.Format(time.RFC3339)), rows,
+		"Timestamp", "Machine", "Check", "Transition", "Output")
+}
+
+func saveHistory(ctx context.Context, machines []*api.Machine, history *checkHistory) error {
+	value, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+
+	return mach.SetAppMetadata(ctx, machines, historyMetadataKey, string(value))
+}
+
+// observe records the transitions of the checks of machines since they were
+// last observed, timed as of when their status last changed, or now should
+// they not tell. Checks seen for the first time have no transition yet. It
+// reports whether anything changed.
+func (h *checkHistory) observe(machines []*api.Machine, now time.Time) (changed bool) {
+	if h.Last == nil {
+		h.Last = map[string]string{}
+	}
+
+	var observed []checkTransition
+	for _, machine := range machines {
+		for _, check := range machine.Checks {
+			key := machine.ID + "/" + check.Name
+
+			prev, seen := h.Last[key]
+			if seen && prev == check.Status {
+				continue
+			}
+			h.Last[key] = check.Status
+			changed = true
+
+			if !seen {
+				continue
+			}
+
+			at := now
+			if check.UpdatedAt != nil {
+				at = check.UpdatedAt.UTC()
+			}
+
+			observed = append(observed, checkTransition{
+				Timestamp: at,
+				Machine:   machine.ID,
+				Check:     check.Name,
+				From:      prev,
+				To:        check.Status,
+				Output:    check.Output,
+			})
+		}
+	}
+
+	sortTransitions(observed)
+	h.Transitions = append(h.Transitions, observed...)
+	if n := len(h.Transitions); n > maxRecordedTransitions {
+		h.Transitions = h.Transitions[n-maxRecordedTransitions:]
+	}
+
+	return changed
+}
+
+// eventTransitions derives the transitions of machine since the given time
+// out of what it tells about itself, oldest first: the machine exiting
+// without being asked to and starting again, which its checks fail and
+// recover along with, and the latest change of the status of each of its
+// checks, which is all machines keep of them.
+func eventTransitions(machine *api.Machine, since time.Time) []checkTransition {
+	events := make([]*api.MachineEvent, len(machine.Events))
+	copy(events, machine.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+
+	var (
+		transitions []checkTransition
+		exited      bool
+	)
+	for _, event := range events {
+		var t checkTransition
+
+		exit := event.Exit()
+		switch {
+		case exit != nil && !exit.RequestedStop:
+			exited = true
+
+			output := exit.Hint()
+			if output == "" {
+				output = fmt.Sprintf("the process exited with code %d", exit.ExitCode)
+			}
+			t = checkTransition{From: "started", To: "exited", Output: output}
+		case exit != nil:
+			exited = false
+			continue
+		case event.Type == "start" && event.Status == "started" && exited:
+			exited = false
+			t = checkTransition{From: "exited", To: "started"}
+		default:
+			continue
+		}
+
+		t.Timestamp = time.UnixMilli(event.Timestamp).UTC()
+		if t.Timestamp.Before(since) {
+			continue
+		}
+		t.Machine, t.Check = machine.ID, machineCheckName
+
+		transitions = append(transitions, t)
+	}
+
+	for _, check := range machine.Checks {
+		if check.UpdatedAt == nil || check.UpdatedAt.Before(since) {
+			continue
+		}
+
+		transitions = append(transitions, checkTransition{
+			Timestamp: check.UpdatedAt.UTC(),
+			Machine:   machine.ID,
+			Check:     check.Name,
+			To:        check.Status,
+			Output:    check.Output,
+		})
+	}
+
+	sortTransitions(transitions)
+
+	return transitions
+}
+
+func sortTransitions(transitions []checkTransition) {
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].Timestamp.Before(transitions[j].Timestamp)
+	})
+}
+
+func filterTransitions(transitions []checkTransition, checkName, machineID string) []checkTransition {
+	var filtered []checkTransition
+	for _, t := range transitions {
+		if (checkName == "" || t.Check == checkName) && (machineID == "" || t.Machine == machineID) {
+			filtered = append(filtered, t)
+		}
+	}
+
+	return filtered
+}
+
+func outputSnippet(output string) string {
+	output = strings.Join(strings.Fields(output), " ")
+	if runes := []rune(output); len(runes) > outputSnippetLength {
+		output = string(runes[:outputSnippetLength-1]) + "…"
+	}
+
+	return output
+}
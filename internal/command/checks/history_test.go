@@ -0,0 +1,89 @@
+package checks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestEventTransitions(t *testing.T) {
+	var (
+		base    = time.Date(2023, 5, 1, 3, 0, 0, 0, time.UTC)
+		at      = func(minutes int) int64 { return base.Add(time.Duration(minutes) * time.Minute).UnixMilli() }
+		updated = base.Add(40 * time.Minute)
+	)
+
+	machine := &api.Machine{
+		ID: "m1",
+		Events: []*api.MachineEvent{
+			// out of order, as machines may list them
+			{Type: "start", Status: "started", Timestamp: at(12)},
+			{Type: "exit", Status: "stopped", Timestamp: at(10), Request: &api.MachineRequest{
+				ExitEvent: &api.MachineExitEvent{ExitCode: 137, OOMKilled: true},
+			}},
+			{Type: "start", Status: "started", Timestamp: at(0)},
+			{Type: "exit", Status: "stopped", Timestamp: at(20), Request: &api.MachineRequest{
+				ExitEvent: &api.MachineExitEvent{RequestedStop: true},
+			}},
+			{Type: "start", Status: "started", Timestamp: at(30)},
+			{Type: "exit", Status: "stopped", Timestamp: at(-10), Request: &api.MachineRequest{
+				ExitEvent: &api.MachineExitEvent{ExitCode: 1},
+			}},
+		},
+		Checks: []*api.MachineCheckStatus{
+			{Name: "http", Status: "critical", Output: "connection refused", UpdatedAt: &updated},
+			{Name: "tcp", Status: "passing"},
+		},
+	}
+
+	assert.Equal(t, []checkTransition{
+		// recovering from an exit from before the window
+		{Timestamp: base, Machine: "m1", Check: machineCheckName, From: "exited", To: "started"},
+		{
+			Timestamp: base.Add(10 * time.Minute), Machine: "m1", Check: machineCheckName,
+			From: "started", To: "exited", Output: "consider increasing memory, the machine was OOM killed",
+		},
+		{Timestamp: base.Add(12 * time.Minute), Machine: "m1", Check: machineCheckName, From: "exited", To: "started"},
+		{Timestamp: updated, Machine: "m1", Check: "http", To: "critical", Output: "connection refused"},
+	}, eventTransitions(machine, base))
+
+	assert.Empty(t, eventTransitions(machine, base.Add(time.Hour)))
+}
+
+func TestCheckHistoryObserve(t *testing.T) {
+	var (
+		now     = time.Date(2023, 5, 1, 3, 0, 0, 0, time.UTC)
+		updated = now.Add(-time.Minute)
+		checks  = func(status string, updatedAt *time.Time) []*api.Machine {
+			return []*api.Machine{{ID: "m1", Checks: []*api.MachineCheckStatus{{Name: "http", Status: status, UpdatedAt: updatedAt}}}}
+		}
+	)
+
+	history := &checkHistory{}
+
+	// first seen, so nothing to transition from
+	assert.True(t, history.observe(checks("passing", nil), now))
+	assert.Empty(t, history.Transitions)
+
+	assert.False(t, history.observe(checks("passing", nil), now))
+
+	assert.True(t, history.observe(checks("critical", &updated), now))
+	assert.True(t, history.observe(checks("passing", nil), now))
+	assert.Equal(t, []checkTransition{
+		{Timestamp: updated, Machine: "m1", Check: "http", From: "passing", To: "critical"},
+		{Timestamp: now, Machine: "m1", Check: "http", From: "critical", To: "passing"},
+	}, history.Transitions)
+
+	for i := 0; i < maxRecordedTransitions; i++ {
+		status := "critical"
+		if i%2 == 1 {
+			status = "passing"
+		}
+		history.observe(checks(status, nil), now)
+	}
+	assert.Len(t, history.Transitions, maxRecordedTransitions)
+	assert.Equal(t, "passing", history.Transitions[maxRecordedTransitions-1].To)
+}
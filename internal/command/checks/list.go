@@ -2,13 +2,12 @@ package checks
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
-	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/config"
@@ -27,6 +26,14 @@ func runAppCheckList(ctx context.Context) error {
 		return fmt.Errorf("failed to get app: %s", err)
 	}
 
+	if app.PlatformVersion != "machines" && flag.GetString(ctx, "machine") != "" {
+		return errors.New("--machine is only available for machines apps")
+	}
+
+	if flag.GetBool(ctx, "wait") {
+		return runWaitForPassing(ctx, app)
+	}
+
 	if app.PlatformVersion == "machines" {
 		return runMachinesAppCheckList(ctx, app)
 	}
@@ -35,35 +42,21 @@ func runAppCheckList(ctx context.Context) error {
 
 func runMachinesAppCheckList(ctx context.Context, app *api.AppCompact) error {
 	out := iostreams.FromContext(ctx).Out
-	nameFilter := flag.GetString(ctx, "check-name")
-
-	flapsClient, err := flaps.New(ctx, app)
-	if err != nil {
-		return err
-	}
 
-	machines, err := flapsClient.ListActive(ctx)
+	checks, err := fetchMachineChecks(ctx, app)
 	if err != nil {
 		return err
 	}
-	sort.Slice(machines, func(i, j int) bool {
-		return machines[i].ID < machines[j].ID
-	})
 
 	fmt.Fprintf(out, "Health Checks for %s\n", app.Name)
-	table := helpers.MakeSimpleTable(out, []string{"Name", "Status", "Machine", "Last Updated", "Output"})
+	table := helpers.MakeSimpleTable(out, []string{"Name", "Status", "Machine", "Type", "Last Updated", "Output"})
 	table.SetRowLine(true)
-	for _, machine := range machines {
-		sort.Slice(machine.Checks, func(i, j int) bool {
-			return machine.Checks[i].Name < machine.Checks[j].Name
-		})
-
-		for _, check := range machine.Checks {
-			if nameFilter != "" && nameFilter != check.Name {
-				continue
-			}
-			table.Append([]string{check.Name, check.Status, machine.ID, format.RelativeTime(*check.UpdatedAt), check.Output})
+	for _, check := range checks {
+		var updatedAt string
+		if check.UpdatedAt != nil {
+			updatedAt = format.RelativeTime(*check.UpdatedAt)
 		}
+		table.Append([]string{check.Name, check.displayStatus(), check.Target, check.Type, updatedAt, check.displayOutput()})
 	}
 	table.Render()
 
@@ -73,14 +66,8 @@ func runMachinesAppCheckList(ctx context.Context, app *api.AppCompact) error {
 func runNomadAppCheckList(ctx context.Context) error {
 	appName := app.NameFromContext(ctx)
 	out := iostreams.FromContext(ctx).Out
-	web := client.FromContext(ctx).API()
-
-	var nameFilter *string
-	if val := flag.GetString(ctx, "check-name"); val != "" {
-		nameFilter = api.StringPointer(val)
-	}
 
-	checks, err := web.GetAppHealthChecks(ctx, appName, nameFilter, nil, api.BoolPointer(false))
+	checks, err := fetchNomadChecks(ctx, appName)
 	if err != nil {
 		return err
 	}
@@ -100,6 +87,36 @@ func runNomadAppCheckList(ctx context.Context) error {
 	return nil
 }
 
+// displayOutput returns the output of c, trimmed to its last lines for exec
+// checks.
+func (c checkState) displayOutput() string {
+	if c.Type == app.CheckTypeExec {
+		return formatExecOutput(c.Output)
+	}
+
+	return c.Output
+}
+
+// execOutputLines is how many of the last lines of the output of exec checks
+// the list shows, as it's whatever the command printed.
+const execOutputLines = 5
+
+// formatExecOutput returns the last lines of the output of an exec check,
+// which tend to carry why it failed.
+func formatExecOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return ""
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) > execOutputLines {
+		lines = append([]string{"…"}, lines[len(lines)-execOutputLines:]...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func formatOutput(output string) string {
 	var newstr string
 	output = strings.ReplaceAll(output, "\n", "")
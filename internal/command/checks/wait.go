@@ -0,0 +1,109 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const waitPollInterval = 2 * time.Second
+
+// runWaitForPassing polls the checks of app until all of them pass, printing
+// each status change as a line of its own so the output suits CI logs. It
+// fails once the --timeout elapses, listing the checks that don't pass.
+func runWaitForPassing(ctx context.Context, app *api.AppCompact) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		timeout = time.Duration(flag.GetInt(ctx, "timeout")) * time.Second
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	fmt.Fprintf(io.Out, "Waiting up to %s for the health checks of %s to pass\n", timeout, app.Name)
+
+	var (
+		last    []checkState
+		seen    = map[string]string{}
+		ticker  = time.NewTicker(waitPollInterval)
+		lastErr error
+	)
+	defer ticker.Stop()
+
+	for {
+		checks, err := fetchChecks(ctx, app)
+		switch {
+		case err == nil:
+			last, lastErr = checks, nil
+		case ctx.Err() == nil:
+			// transient errors are retried until the timeout elapses
+			lastErr = err
+		}
+
+		for _, check := range checks {
+			prev, ok := seen[check.key()]
+			switch {
+			case !ok:
+				fmt.Fprintf(io.Out, "%s on %s is %s\n", check.Name, check.Target, check.displayStatus())
+			case prev != check.displayStatus():
+				fmt.Fprintf(io.Out, "%s on %s changed from %s to %s\n", check.Name, check.Target, prev, check.displayStatus())
+			}
+			seen[check.key()] = check.displayStatus()
+		}
+
+		if err == nil && len(checks) > 0 && allPassing(checks) {
+			fmt.Fprintf(io.Out, "All %d health checks are passing\n", len(checks))
+
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return waitTimeoutError(ctx, timeout, last, lastErr)
+		}
+	}
+}
+
+func allPassing(checks []checkState) bool {
+	for _, check := range checks {
+		if !check.passing() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func waitTimeoutError(ctx context.Context, timeout time.Duration, checks []checkState, lastErr error) error {
+	if lastErr != nil {
+		return fmt.Errorf("timed out after %s waiting for health checks to pass: %w", timeout, lastErr)
+	}
+
+	if len(checks) == 0 {
+		return fmt.Errorf("timed out after %s waiting for health checks to pass: no matching checks were found", timeout)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+
+	var failing int
+	table := helpers.MakeSimpleTable(out, []string{"Name", "Status", "Machine", "Output"})
+	table.SetRowLine(true)
+	for _, check := range checks {
+		if check.passing() {
+			continue
+		}
+		failing++
+		table.Append([]string{check.Name, check.Status, check.Target, check.Output})
+	}
+
+	fmt.Fprintln(out, "Failing health checks")
+	table.Render()
+
+	return fmt.Errorf("timed out after %s waiting for health checks to pass: %d of %d are not passing", timeout, failing, len(checks))
+}
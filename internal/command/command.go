@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/blang/semver"
@@ -21,12 +22,14 @@ import (
 	"github.com/superfly/flyctl/internal/buildinfo"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/env"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/logger"
 	"github.com/superfly/flyctl/internal/update"
 
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/cache"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
 	"github.com/superfly/flyctl/internal/state"
 	"github.com/superfly/flyctl/internal/task"
 )
@@ -41,12 +44,38 @@ type (
 var ErrNotImplementedYet = errors.New("command not implemented yet")
 
 func New(usage, short, long string, fn Runner, p ...Preparer) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   usage,
 		Short: short,
 		Long:  long,
 		RunE:  newRunE(fn, p...),
 	}
+
+	if fn != nil {
+		runners[cmd] = nil
+	}
+
+	return cmd
+}
+
+// runners maps the commands New built with a Runner to their positional
+// argument validators, once DeferArgsValidation takes them over.
+var runners = map[*cobra.Command]cobra.PositionalArgs{}
+
+// DeferArgsValidation walks the tree of cmd and moves the validation of the
+// positional arguments of the commands New built with a Runner to after their
+// preparers ran and the flag defaults of the app applied, as validators may
+// depend on flags. cobra would otherwise validate them first.
+func DeferArgsValidation(cmd *cobra.Command) {
+	for _, c := range cmd.Commands() {
+		DeferArgsValidation(c)
+	}
+
+	if validate, ok := runners[cmd]; !ok || validate != nil || cmd.Args == nil {
+		return
+	}
+
+	runners[cmd], cmd.Args = cmd.Args, cobra.ArbitraryArgs
 }
 
 var commonPreparers = []Preparer{
@@ -91,7 +120,7 @@ func newRunE(fn Runner, preparers ...Preparer) func(*cobra.Command, []string) er
 		return nil
 	}
 
-	return func(cmd *cobra.Command, _ []string) (err error) {
+	return func(cmd *cobra.Command, args []string) (err error) {
 		ctx := cmd.Context()
 		ctx = NewContext(ctx, cmd)
 		ctx = flag.NewContext(ctx, cmd.Flags())
@@ -101,11 +130,19 @@ func newRunE(fn Runner, preparers ...Preparer) func(*cobra.Command, []string) er
 			return
 		}
 
-		// run the preparers specific to the command
-		if ctx, err = prepare(ctx, preparers...); err != nil {
+		// run the preparers specific to the command, then apply the flag
+		// defaults of the app they selected, if any
+		if ctx, err = prepare(ctx, append(preparers, applyFlagDefaults)...); err != nil {
 			return
 		}
 
+		// validate the positional arguments now that the flags are final
+		if validate := runners[cmd]; validate != nil {
+			if err = validate(cmd, args); err != nil {
+				return
+			}
+		}
+
 		// run the command
 		if err = fn(ctx); err == nil {
 			// and finally, run the finalizer
@@ -280,6 +317,51 @@ func loadConfig(ctx context.Context) (context.Context, error) {
 	return config.NewContext(ctx, cfg), nil
 }
 
+// applyFlagDefaults sets the flags the user didn't give to the defaults stored
+// in the profile of the selected app, as the lowest-precedence source.
+func applyFlagDefaults(ctx context.Context) (context.Context, error) {
+	appName := app.NameFromContext(ctx)
+	if appName == "" {
+		return ctx, nil
+	}
+
+	fs := flag.FromContext(ctx)
+	if f := fs.Lookup(flag.IgnoreDefaultsName); f != nil && f.Value.String() == "true" {
+		return ctx, nil
+	}
+
+	path := filepath.Join(state.ConfigDirectory(ctx), config.DefaultsFileName)
+
+	defaults, err := config.AppDefaults(path, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading flag defaults: %w", err)
+	}
+
+	applied, err := flag.ApplyDefaults(fs, defaults)
+	if err != nil {
+		return nil, fmt.Errorf("failed applying the flag defaults of %s: %w", appName, err)
+	}
+
+	if len(applied) == 0 {
+		return ctx, nil
+	}
+
+	// the config reflects the flags as loadConfig found them, so the defaults
+	// have to reach it too
+	config.FromContext(ctx).ApplyFlags(fs)
+
+	list := make([]string, 0, len(applied))
+	for _, name := range applied {
+		list = append(list, fmt.Sprintf("--%s=%s", name, fs.Lookup(name).Value))
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.ErrOut, "Using the flag defaults of %s: %s (bypass with --%s)\n",
+		appName, strings.Join(list, " "), flag.IgnoreDefaultsName)
+
+	return ctx, nil
+}
+
 func initClient(ctx context.Context) (context.Context, error) {
 	logger := logger.FromContext(ctx)
 	cfg := config.FromContext(ctx)
@@ -440,7 +522,7 @@ func killOldAgent(ctx context.Context) (context.Context, error) {
 // RequireSession is a Preparer which makes sure a session exists.
 func RequireSession(ctx context.Context) (context.Context, error) {
 	if !client.FromContext(ctx).Authenticated() {
-		return nil, client.ErrNoAuthToken
+		return nil, flyerr.WithCode(flyerr.CodeUnauthorized, client.ErrNoAuthToken)
 	}
 
 	return ctx, nil
@@ -452,6 +534,16 @@ func RequireSession(ctx context.Context) (context.Context, error) {
 func LoadAppConfigIfPresent(ctx context.Context) (context.Context, error) {
 	logger := logger.FromContext(ctx)
 
+	if source := flag.GetAppConfigFilePath(ctx); app.IsRemoteSource(source) {
+		cfg, err := app.LoadConfigFromSource(ctx, source, iostreams.FromContext(ctx).In, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed loading app config from %s: %w", app.DescribeSource(source), err)
+		}
+		logger.Debugf("app config loaded from %s", cfg.Source())
+
+		return app.WithConfig(ctx, cfg), nil
+	}
+
 	for _, path := range appConfigFilePaths(ctx) {
 		switch cfg, err := app.LoadConfig(ctx, path, ""); {
 		case err == nil:
@@ -497,14 +589,12 @@ func RequireAppName(ctx context.Context) (context.Context, error) {
 		return nil, err
 	}
 
-	name := flag.GetApp(ctx)
+	// if there's no flag present, first consult with the environment
+	name, _ := appNameOverride(ctx)
 	if name == "" {
-		// if there's no flag present, first consult with the environment
-		if name = env.First("FLY_APP"); name == "" {
-			// and then with the config file (if any)
-			if cfg := app.ConfigFromContext(ctx); cfg != nil {
-				name = cfg.AppName
-			}
+		// and then with the config file (if any)
+		if cfg := app.ConfigFromContext(ctx); cfg != nil {
+			name = cfg.AppName
 		}
 	}
 
@@ -515,6 +605,80 @@ func RequireAppName(ctx context.Context) (context.Context, error) {
 	return app.WithName(ctx, name), nil
 }
 
+// appNameOverride returns the app name selected via the app flag or the
+// FLY_APP environment variable, along with where it was found.
+func appNameOverride(ctx context.Context) (name, source string) {
+	if name = flag.GetApp(ctx); name != "" {
+		return name, "--" + flag.AppName
+	}
+
+	if name = env.First("FLY_APP"); name != "" {
+		return name, "FLY_APP"
+	}
+
+	return "", ""
+}
+
+// RequireMatchingAppName is a Preparer which embeds RequireAppName and
+// additionally guards against an app name selected through the command line
+// or the environment silently overriding the one of the app config file.
+//
+// When the two differ, the user is warned and asked to confirm, unless the
+// command's yes flag is set. Config files with strict_app_name set turn the
+// mismatch into an error.
+func RequireMatchingAppName(ctx context.Context) (context.Context, error) {
+	ctx, err := RequireAppName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkAppNameMatchesConfig(ctx); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+func checkAppNameMatchesConfig(ctx context.Context) error {
+	cfg := app.ConfigFromContext(ctx)
+	if cfg == nil || cfg.AppName == "" {
+		return nil
+	}
+
+	name, source := appNameOverride(ctx)
+	if name == "" || name == cfg.AppName {
+		return nil
+	}
+
+	if cfg.StrictAppName {
+		return fmt.Errorf("app %s selected via %s does not match app %s of %s, which sets strict_app_name",
+			name, source, cfg.AppName, cfg.Source())
+	}
+
+	io := iostreams.FromContext(ctx)
+	colorize := io.ColorScheme()
+
+	fmt.Fprintf(io.ErrOut, "%s %s\n", colorize.WarningIcon(),
+		colorize.Yellow(fmt.Sprintf("App %s selected via %s differs from app %s of %s", name, source, cfg.AppName, cfg.Source())))
+
+	if f := flag.FromContext(ctx).Lookup(flag.YesName); f != nil && flag.GetYes(ctx) {
+		return nil
+	}
+
+	switch confirmed, err := prompt.Confirmf(ctx, "Continue with app %s?", name); {
+	case err == nil:
+		if !confirmed {
+			return flyerr.ErrAbort
+		}
+	case prompt.IsNonInteractive(err):
+		return prompt.NonInteractiveError("yes flag must be specified when the selected app doesn't match the app config file and not running interactively")
+	default:
+		return err
+	}
+
+	return nil
+}
+
 // LoadAppNameIfPresent is a Preparer which adds app name if the user has used --app or there appConfig
 // but unlike RequireAppName it does not error if the user has not specified an app name.
 func LoadAppNameIfPresent(ctx context.Context) (context.Context, error) {
@@ -0,0 +1,145 @@
+package command
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/state"
+)
+
+func newAppNameContext(t *testing.T, cfg *app.Config, args ...string) (context.Context, *strings.Builder) {
+	t.Helper()
+
+	cmd := &cobra.Command{}
+	flag.Add(cmd, flag.App(), flag.Yes())
+	require.NoError(t, cmd.Flags().Parse(args))
+
+	var errOut strings.Builder
+	ctx := flag.NewContext(context.Background(), cmd.Flags())
+	ctx = iostreams.NewContext(ctx, &iostreams.IOStreams{
+		Out:    &strings.Builder{},
+		ErrOut: &errOut,
+	})
+
+	if cfg != nil {
+		ctx = app.WithConfig(ctx, cfg)
+	}
+
+	return ctx, &errOut
+}
+
+func TestAppNameOverride(t *testing.T) {
+	t.Setenv("FLY_APP", "env-app")
+
+	ctx, _ := newAppNameContext(t, nil, "--app", "flag-app")
+	name, source := appNameOverride(ctx)
+	assert.Equal(t, "flag-app", name)
+	assert.Equal(t, "--app", source)
+
+	ctx, _ = newAppNameContext(t, nil)
+	name, source = appNameOverride(ctx)
+	assert.Equal(t, "env-app", name)
+	assert.Equal(t, "FLY_APP", source)
+}
+
+func TestCheckAppNameMatchesConfig(t *testing.T) {
+	t.Setenv("FLY_APP", "")
+
+	ctx, _ := newAppNameContext(t, nil, "--app", "prod")
+	assert.NoError(t, checkAppNameMatchesConfig(ctx), "no config")
+
+	ctx, _ = newAppNameContext(t, &app.Config{AppName: "prod"}, "--app", "prod")
+	assert.NoError(t, checkAppNameMatchesConfig(ctx), "matching names")
+
+	ctx, _ = newAppNameContext(t, &app.Config{AppName: "staging"})
+	assert.NoError(t, checkAppNameMatchesConfig(ctx), "config only")
+
+	ctx, errOut := newAppNameContext(t, &app.Config{AppName: "staging"}, "--app", "prod", "--yes")
+	assert.NoError(t, checkAppNameMatchesConfig(ctx), "mismatch confirmed via --yes")
+	assert.Contains(t, errOut.String(), "App prod selected via --app differs from app staging")
+
+	ctx, _ = newAppNameContext(t, &app.Config{AppName: "staging"}, "--app", "prod")
+	err := checkAppNameMatchesConfig(ctx)
+	assert.True(t, prompt.IsNonInteractive(err), "mismatch without confirmation")
+
+	ctx, _ = newAppNameContext(t, &app.Config{AppName: "staging", StrictAppName: true}, "--app", "prod", "--yes")
+	err = checkAppNameMatchesConfig(ctx)
+	require.Error(t, err, "strict mismatch")
+	assert.Contains(t, err.Error(), "strict_app_name")
+}
+
+func TestCheckAppNameMatchesConfigFromEnv(t *testing.T) {
+	t.Setenv("FLY_APP", "prod")
+
+	ctx, _ := newAppNameContext(t, &app.Config{AppName: "prod"})
+	assert.NoError(t, checkAppNameMatchesConfig(ctx), "matching names")
+
+	ctx, errOut := newAppNameContext(t, &app.Config{AppName: "staging"}, "--yes")
+	assert.NoError(t, checkAppNameMatchesConfig(ctx))
+	assert.Contains(t, errOut.String(), "selected via FLY_APP")
+
+	ctx, _ = newAppNameContext(t, &app.Config{AppName: "staging", StrictAppName: true})
+	err := checkAppNameMatchesConfig(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FLY_APP")
+
+	// the flag takes precedence over the environment
+	ctx, _ = newAppNameContext(t, &app.Config{AppName: "staging", StrictAppName: true}, "--app", "staging")
+	assert.NoError(t, checkAppNameMatchesConfig(ctx))
+}
+
+func TestDeferArgsValidation(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	root.Args = cobra.NoArgs
+
+	child := New("child", "", "", func(context.Context) error { return nil })
+	child.Args = cobra.ExactArgs(1)
+	root.AddCommand(child)
+
+	DeferArgsValidation(root)
+	t.Cleanup(func() { delete(runners, child) })
+
+	// cobra accepts anything up front, while the validator waits for newRunE
+	assert.NoError(t, child.ValidateArgs(nil))
+	require.NotNil(t, runners[child])
+	assert.Error(t, runners[child](child, nil))
+	assert.NoError(t, runners[child](child, []string{"arg"}))
+
+	// commands New didn't build keep validating as cobra does
+	assert.Error(t, root.ValidateArgs([]string{"arg"}))
+}
+
+func TestApplyFlagDefaultsReachConfig(t *testing.T) {
+	t.Setenv("FLY_REGION", "")
+
+	dir := t.TempDir()
+	require.NoError(t, config.SetAppDefaults(filepath.Join(dir, config.DefaultsFileName), "app", map[string]string{
+		flag.RegionName: "fra",
+	}))
+
+	cmd := &cobra.Command{}
+	flag.Add(cmd, flag.Region())
+	require.NoError(t, cmd.Flags().Parse(nil))
+
+	ctx := flag.NewContext(context.Background(), cmd.Flags())
+	ctx = state.WithConfigDirectory(ctx, dir)
+	ctx = app.WithName(ctx, "app")
+	ctx = iostreams.NewContext(ctx, &iostreams.IOStreams{Out: &strings.Builder{}, ErrOut: &strings.Builder{}})
+	ctx = config.NewContext(ctx, config.New())
+
+	ctx, err := applyFlagDefaults(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fra", config.FromContext(ctx).Region)
+}
@@ -0,0 +1,139 @@
+// Package dashboard implements the dashboard command chain.
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/skratchdot/open-golang/open"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+)
+
+// BaseURL is the root of the Fly web dashboard.
+const BaseURL = "https://fly.io"
+
+// sections maps the supported dashboard sections to their path relative to
+// the app's overview page.
+var sections = map[string]string{
+	"metrics":    "metrics",
+	"monitoring": "monitoring",
+	"secrets":    "secrets",
+	"machines":   "machines",
+}
+
+// Sections returns the names of the supported dashboard sections, sorted.
+func Sections() []string {
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// URL returns the dashboard URL of the named section of appName. An empty
+// section denotes the app's overview page.
+func URL(appName, section string) (string, error) {
+	u, err := url.Parse(BaseURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.Path = "/apps/" + url.PathEscape(appName)
+
+	if section != "" {
+		path, ok := sections[section]
+		if !ok {
+			return "", fmt.Errorf("unknown dashboard section %q; supported sections are: %s",
+				section, strings.Join(Sections(), ", "))
+		}
+
+		u.Path += "/" + path
+	}
+
+	return u.String(), nil
+}
+
+func New() *cobra.Command {
+	const (
+		long = `Open web browser on Fly Web UI for this application.
+An optional section name opens that part of the dashboard directly.`
+		short = "Open web browser on Fly Web UI for this app"
+		usage = "dashboard [section]"
+	)
+
+	cmd := newCommand(usage, short, long, "")
+	cmd.Aliases = []string{"dash"}
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	for _, section := range Sections() {
+		cmd.AddCommand(newCommand(section,
+			fmt.Sprintf("Open web browser on Fly Web UI for this app's %s", section),
+			fmt.Sprintf("Open web browser on Fly Web UI for this application's %s", section),
+			section,
+		))
+	}
+
+	return cmd
+}
+
+func newCommand(usage, short, long, section string) *cobra.Command {
+	cmd := command.New(usage, short, long, func(ctx context.Context) error {
+		s := section
+		if s == "" {
+			s = flag.FirstArg(ctx)
+		}
+
+		return run(ctx, s)
+	},
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	if section != "" {
+		cmd.Args = cobra.NoArgs
+	}
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Bool{
+			Name:        "print",
+			Description: "Print the dashboard URL instead of opening a browser",
+		},
+	)
+
+	return cmd
+}
+
+func run(ctx context.Context, section string) error {
+	io := iostreams.FromContext(ctx)
+
+	u, err := URL(app.NameFromContext(ctx), section)
+	if err != nil {
+		return err
+	}
+
+	if flag.GetBool(ctx, "print") {
+		fmt.Fprintln(io.Out, u)
+		return nil
+	}
+
+	fmt.Fprintf(io.Out, "Opening %s ...\n", u)
+
+	if err := open.Run(u); err != nil {
+		return fmt.Errorf("failed opening %s: %w", u, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURL(t *testing.T) {
+	cases := map[string]string{
+		"":           "https://fly.io/apps/my-app",
+		"metrics":    "https://fly.io/apps/my-app/metrics",
+		"monitoring": "https://fly.io/apps/my-app/monitoring",
+		"secrets":    "https://fly.io/apps/my-app/secrets",
+		"machines":   "https://fly.io/apps/my-app/machines",
+	}
+
+	for section, exp := range cases {
+		got, err := URL("my-app", section)
+		require.NoError(t, err)
+		assert.Equal(t, exp, got)
+	}
+}
+
+func TestURLUnknownSection(t *testing.T) {
+	_, err := URL("my-app", "billing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "machines, metrics, monitoring, secrets")
+}
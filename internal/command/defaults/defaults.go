@@ -0,0 +1,253 @@
+// Package defaults implements the commands managing the flag defaults of app
+// profiles.
+package defaults
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/internal/state"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// New returns the commands managing flag defaults, meant to be grafted onto
+// the config command.
+func New() []*cobra.Command {
+	return []*cobra.Command{
+		newSet(),
+		newGet(),
+		newUnset(),
+	}
+}
+
+func newSet() *cobra.Command {
+	const (
+		short = "Set flag defaults for an app"
+		long  = short + `. Commands operating on the app use these for flags
+not given on the command line or via the environment; pass --ignore-defaults
+to bypass them. Confirmation flags such as --yes can't have defaults.
+
+For example: fly config set-default --app myapp region=fra vm-size=shared-cpu-2x
+`
+		usage = "set-default NAME=VALUE [NAME=VALUE...]"
+	)
+
+	cmd := command.New(usage, short, long, runSet,
+		command.RequireAppName,
+	)
+	cmd.Args = cobra.MinimumNArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func newGet() *cobra.Command {
+	const (
+		short = "Show the flag defaults of an app"
+		long  = short + "\n"
+		usage = "get-defaults"
+	)
+
+	cmd := command.New(usage, short, long, runGet,
+		command.RequireAppName,
+	)
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func newUnset() *cobra.Command {
+	const (
+		short = "Remove flag defaults of an app"
+		long  = short + "\n"
+		usage = "unset-default NAME [NAME...]"
+	)
+
+	cmd := command.New(usage, short, long, runUnset,
+		command.RequireAppName,
+	)
+	cmd.Args = cobra.MinimumNArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func defaultsPath(ctx context.Context) string {
+	return filepath.Join(state.ConfigDirectory(ctx), config.DefaultsFileName)
+}
+
+func runSet(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		io      = iostreams.FromContext(ctx)
+	)
+
+	vals := map[string]string{}
+	for _, arg := range flag.Args(ctx) {
+		name, value, ok := strings.Cut(arg, "=")
+		name = strings.TrimLeft(name, "-")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid default %q; defaults are given as NAME=VALUE", arg)
+		}
+		if !flag.CanDefault(name) {
+			return fmt.Errorf("--%s can't have a default; only flags tuning where and how commands run can", name)
+		}
+		vals[name] = value
+	}
+
+	if err := config.SetAppDefaults(defaultsPath(ctx), appName, vals); err != nil {
+		return fmt.Errorf("failed storing flag defaults: %w", err)
+	}
+
+	fmt.Fprintf(io.Out, "Stored %d flag defaults for %s\n", len(vals), appName)
+
+	return nil
+}
+
+func runGet(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		out     = iostreams.FromContext(ctx).Out
+	)
+
+	defaults, err := config.AppDefaults(defaultsPath(ctx), appName)
+	if err != nil {
+		return fmt.Errorf("failed loading flag defaults: %w", err)
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		if defaults == nil {
+			defaults = map[string]string{}
+		}
+
+		return render.JSON(out, defaults)
+	}
+
+	if len(defaults) == 0 {
+		fmt.Fprintf(out, "%s has no flag defaults\n", appName)
+
+		return nil
+	}
+
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, []string{name, defaults[name]})
+	}
+
+	return render.Table(out, "", rows, "Flag", "Default")
+}
+
+func runUnset(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		io      = iostreams.FromContext(ctx)
+	)
+
+	names := make([]string, 0, len(flag.Args(ctx)))
+	for _, arg := range flag.Args(ctx) {
+		names = append(names, strings.TrimLeft(arg, "-"))
+	}
+
+	if err := config.UnsetAppDefaults(defaultsPath(ctx), appName, names); err != nil {
+		return fmt.Errorf("failed removing flag defaults: %w", err)
+	}
+
+	fmt.Fprintf(io.Out, "Removed flag defaults %s of %s\n", strings.Join(names, ", "), appName)
+
+	return nil
+}
+
+// WrapHelp returns a help func which, ahead of calling help, notes on the
+// flags of the command the defaults the profile of the selected app sets.
+func WrapHelp(help func(*cobra.Command, []string)) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		annotateHelp(cmd)
+		help(cmd, args)
+	}
+}
+
+func annotateHelp(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	if fs.Lookup(flag.AppName) == nil {
+		return
+	}
+
+	appName := helpAppName(cmd)
+	if appName == "" {
+		return
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+
+	defaults, err := config.AppDefaults(filepath.Join(home, ".fly", config.DefaultsFileName), appName)
+	if err != nil {
+		return
+	}
+
+	flag.AnnotateDefaults(fs, appName, defaults)
+}
+
+// helpAppName returns the name of the app selected via the app flag, the
+// environment or the app config, without reaching out to the API.
+func helpAppName(cmd *cobra.Command) string {
+	fs := cmd.Flags()
+
+	if name, _ := fs.GetString(flag.AppName); name != "" {
+		return name
+	}
+
+	if name := os.Getenv("FLY_APP"); name != "" {
+		return name
+	}
+
+	path := app.DefaultConfigFileName
+	if f := fs.Lookup(flag.AppConfigFilePathName); f != nil && f.Value.String() != "" {
+		path = f.Value.String()
+		if app.IsRemoteSource(path) {
+			return ""
+		}
+		if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+			path = filepath.Join(path, app.DefaultConfigFileName)
+		}
+	}
+
+	cfg, err := app.LoadConfig(context.Background(), path, app.MachinesPlatform)
+	if err != nil {
+		return ""
+	}
+
+	return cfg.AppName
+}
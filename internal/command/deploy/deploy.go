@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/logrusorgru/aurora"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/iostreams"
 
 	"github.com/superfly/flyctl/api"
@@ -22,6 +25,7 @@ import (
 	"github.com/superfly/flyctl/internal/prompt"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/internal/state"
+	"github.com/superfly/flyctl/internal/tracing"
 
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/cmdutil"
@@ -44,6 +48,7 @@ var CommonFlags = flag.Set{
 	flag.BuildArg(),
 	flag.BuildSecret(),
 	flag.BuildTarget(),
+	flag.CacheFrom(),
 	flag.NoCache(),
 	flag.Nixpacks(),
 	flag.BuildOnly(),
@@ -56,6 +61,75 @@ var CommonFlags = flag.Set{
 		Name:        "auto-confirm",
 		Description: "Will automatically confirm changes when running non-interactively.",
 	},
+	flag.Bool{
+		Name:        "skip-secret-validation",
+		Description: "Deploy even though the config refers to secrets which aren't set, as when they're set later on. Machines apps only.",
+	},
+	flag.Bool{
+		Name:        "prune-orphaned-groups",
+		Description: "Destroy the machines of process groups removed from the config, without prompting. Machines apps only.",
+	},
+	flag.Bool{
+		Name:        "keep-orphaned-groups",
+		Description: "Keep the machines of process groups removed from the config running, without prompting. Machines apps only.",
+	},
+	flag.Bool{
+		Name:        "revert-on-failure",
+		Description: "Restore updated machines to their previous configuration if the deployment fails. Machines apps only.",
+	},
+	flag.StringSlice{
+		Name:        "label",
+		Description: "Label in the form of KEY=VALUE to apply to the image and copy into the metadata of machines. Can be specified multiple times.",
+	},
+	flag.Bool{
+		Name:        "auto-labels",
+		Description: fmt.Sprintf("Label the image and machines with %s and %s, as found in the environment of CI providers", gitSHALabel, buildURLLabel),
+	},
+	flag.StringSlice{
+		Name:        "only-regions",
+		Description: "Only update the machines in these regions, marking the release as partial. Machines apps only.",
+	},
+	flag.StringSlice{
+		Name:        "exclude-regions",
+		Description: "Update the machines in all regions but these, marking the release as partial. Machines apps only.",
+	},
+	flag.Bool{
+		Name:        "only-build-changed-groups",
+		Description: "Skip building the images of process groups with [processes.<name>.build] sections whose build context is unchanged since their last deploy",
+	},
+	flag.Bool{
+		Name:        "skip-arch-check",
+		Description: "Deploy without verifying the image has a linux/amd64 variant machines can run",
+	},
+	flag.Bool{
+		Name:        "watch",
+		Description: "Once the deployment succeeds, stream the logs of the updated machines and report changes of their checks for --watch-duration, failing if any went critical. Machines apps only.",
+	},
+	flag.String{
+		Name:        "watch-duration",
+		Default:     "2m",
+		Description: "How long --watch follows the updated machines for",
+	},
+	flag.Bool{
+		Name:        "maintenance-page",
+		Description: "Serve a maintenance page from a temporary machine while the only machine of an app with a volume is replaced. Machines apps only.",
+	},
+	flag.String{
+		Name:        "maintenance-html",
+		Description: "Path to the HTML of the page --maintenance-page serves, instead of a generic one",
+	},
+	flag.String{
+		Name:        "wait-grace-period",
+		Description: "Time to give new machines to start up before failing health checks count against the deployment, e.g. 90s. Overrides deploy.wait_grace_period in fly.toml. Machines apps only.",
+	},
+	flag.Bool{
+		Name:        "verify-signature",
+		Description: "Refuse to deploy the prebuilt image unless it carries a cosign signature by the holder of --cosign-key",
+	},
+	flag.String{
+		Name:        "cosign-key",
+		Description: "Path or URL of the PEM encoded public key --verify-signature verifies cosign signatures with",
+	},
 }
 
 func New() (cmd *cobra.Command) {
@@ -70,7 +144,7 @@ func New() (cmd *cobra.Command) {
 	cmd = command.New("deploy [WORKING_DIRECTORY]", short, long, run,
 		command.RequireSession,
 		command.ChangeWorkingDirectoryToFirstArgIfPresent,
-		command.RequireAppName,
+		command.RequireMatchingAppName,
 	)
 
 	cmd.Args = cobra.MaximumNArgs(1)
@@ -79,25 +153,84 @@ func New() (cmd *cobra.Command) {
 		CommonFlags,
 		flag.App(),
 		flag.AppConfig(),
+		flag.Yes(),
+		flag.String{
+			Name:        "otel-endpoint",
+			Description: fmt.Sprintf("Export OpenTelemetry trace spans of the deployment to this OTLP/HTTP endpoint. Defaults to $%s", tracing.EnvEndpoint),
+		},
 	)
 
 	return
 }
 
-func run(ctx context.Context) error {
-	appConfig, err := determineAppConfig(ctx)
+func run(ctx context.Context) (err error) {
+	endpoint := flag.GetString(ctx, "otel-endpoint")
+	if endpoint == "" {
+		endpoint = env.First(tracing.EnvEndpoint)
+	}
+
+	ctx, shutdown, err := tracing.Setup(ctx, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed setting up tracing: %w", err)
+	}
+	logger := logger.FromContext(ctx)
+	defer func() {
+		// spans are exported even once ctx is canceled
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := shutdown(shutdownCtx); err != nil {
+			logger.Warnf("failed exporting trace spans: %v", err)
+		}
+	}()
+
+	ctx, span := tracing.StartSpan(ctx, "deploy")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	configCtx, configSpan := tracing.StartSpan(ctx, "config")
+	appConfig, err := determineAppConfig(configCtx)
+	tracing.EndSpan(configSpan, err)
 	if err != nil {
 		return err
 	}
 
+	span.SetAttributes(
+		attribute.String("app.name", appConfig.AppName),
+		attribute.Bool("app.machines", appConfig.ForMachines()),
+	)
+
 	return DeployWithConfig(ctx, appConfig)
 }
 
 func DeployWithConfig(ctx context.Context, appConfig *app.Config) (err error) {
 	apiClient := client.FromContext(ctx).API()
 
+	verifiers, err := imageVerifiers(ctx)
+	if err != nil {
+		return err
+	}
+
+	imageRef, err := fetchImageRef(ctx, appConfig)
+	if err != nil {
+		return err
+	}
+	if len(verifiers) > 0 && imageRef == "" {
+		return flyerr.WithCode(flyerr.CodeValidation, errors.New("--verify-signature verifies prebuilt images; pass one with --image"))
+	}
+
 	// Fetch an image ref or build from source to get the final image reference to deploy
-	img, err := determineImage(ctx, appConfig)
+	buildCtx, buildSpan := tracing.StartSpan(ctx, "build")
+	img, err := determineImage(buildCtx, appConfig)
+	if img != nil {
+		buildSpan.SetAttributes(
+			attribute.String("image.id", img.ID),
+			attribute.String("image.tag", img.Tag),
+			attribute.String("build.cache_from", strings.Join(img.CacheFrom, ",")),
+		)
+	}
+	tracing.EndSpan(buildSpan, err)
 	if err != nil {
 		return fmt.Errorf("failed to fetch an image or build from source: %w", err)
 	}
@@ -111,6 +244,18 @@ func DeployWithConfig(ctx context.Context, appConfig *app.Config) (err error) {
 		return nil
 	}
 
+	if !flag.GetBool(ctx, "skip-arch-check") {
+		if err := checkImagePlatform(ctx, img); err != nil {
+			return err
+		}
+	}
+
+	if imageRef != "" {
+		if err := verifyImage(ctx, imageRef, img, verifiers); err != nil {
+			return err
+		}
+	}
+
 	var release *api.Release
 	var releaseCommand *api.ReleaseCommand
 
@@ -132,7 +277,12 @@ func DeployWithConfig(ctx context.Context, appConfig *app.Config) (err error) {
 			}
 		}
 
-		return createMachinesRelease(ctx, appConfig, img, flag.GetString(ctx, "strategy"))
+		updated, err := createMachinesRelease(ctx, appConfig, img, flag.GetString(ctx, "strategy"))
+		if err != nil || !flag.GetBool(ctx, "watch") {
+			return err
+		}
+
+		return followDeployment(ctx, appConfig.AppName, updated)
 	}
 
 	release, releaseCommand, err = createRelease(ctx, appConfig, img)
@@ -154,8 +304,12 @@ func DeployWithConfig(ctx context.Context, appConfig *app.Config) (err error) {
 		tb := render.NewTextBlock(ctx, fmt.Sprintf("Release command detected: %s\n", releaseCommand.Command))
 		tb.Done("This release will not be available until the release command succeeds.")
 
-		if err := watch.ReleaseCommand(ctx, appConfig.AppName, releaseCommand.ID); err != nil {
-			return err
+		rcCtx, rcSpan := tracing.StartSpan(ctx, "release_command", attribute.String("release_command.id", releaseCommand.ID))
+		rcErr := watch.ReleaseCommand(rcCtx, appConfig.AppName, releaseCommand.ID)
+		tracing.EndSpan(rcSpan, rcErr)
+		recordReleaseCommandInstance(ctx, release, releaseCommand.ID)
+		if rcErr != nil {
+			return rcErr
 		}
 
 		release, err = apiClient.GetAppRelease(ctx, appConfig.AppName, release.ID)
@@ -171,7 +325,9 @@ func DeployWithConfig(ctx context.Context, appConfig *app.Config) (err error) {
 		return nil
 	}
 
-	err = watch.Deployment(ctx, appConfig.AppName, release.EvaluationID)
+	rolloutCtx, rolloutSpan := tracing.StartSpan(ctx, "rollout", attribute.String("release.id", release.ID))
+	err = watch.Deployment(rolloutCtx, appConfig.AppName, release.EvaluationID)
+	tracing.EndSpan(rolloutSpan, err)
 
 	return err
 }
@@ -210,20 +366,20 @@ func determineAppConfig(ctx context.Context) (cfg *app.Config, err error) {
 		if !parsedCfg.Valid {
 			fmt.Println()
 			if len(parsedCfg.Errors) > 0 {
-				tb.Printf("\nConfiguration errors in %s:\n\n", cfg.Path)
+				tb.Printf("\nConfiguration errors in %s:\n\n", cfg.Source())
 			}
 			for _, e := range parsedCfg.Errors {
 				tb.Println("   ", aurora.Red("✘").String(), e)
 			}
 			fmt.Println()
-			return nil, errors.New("App configuration is not valid")
+			return nil, flyerr.WithCode(flyerr.CodeValidation, errors.New("App configuration is not valid"))
 		}
 	}
 
 	if env := flag.GetStringSlice(ctx, "env"); len(env) > 0 {
 		var parsedEnv map[string]string
 		if parsedEnv, err = cmdutil.ParseKVStringsToMap(env); err != nil {
-			err = fmt.Errorf("failed parsing environment: %w", err)
+			err = flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("failed parsing environment: %w", err))
 
 			return
 		}
@@ -260,6 +416,16 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 		return
 	}
 
+	labels, err := determineLabels(ctx)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if img != nil {
+			img.Labels = labels
+		}
+	}()
+
 	// we're using a pre-built Docker image
 	if imageRef != "" {
 		opts := imgsrc.RefOptions{
@@ -291,6 +457,7 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 		BuiltInSettings: build.Settings,
 		Builder:         build.Builder,
 		Buildpacks:      build.Buildpacks,
+		Labels:          labels,
 	}
 
 	cliBuildSecrets, err := cmdutil.ParseKVStringsToMap(flag.GetStringSlice(ctx, "build-secret"))
@@ -317,12 +484,14 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 		return
 	}
 
-	if target := appConfig.DockerBuildTarget(); target != "" {
+	if target := flag.GetString(ctx, "build-target"); target != "" {
 		opts.Target = target
-	} else if target := flag.GetString(ctx, "build-target"); target != "" {
+	} else if target := appConfig.DockerBuildTarget(); target != "" {
 		opts.Target = target
 	}
 
+	opts.CacheFrom = flag.GetStringSlice(ctx, "cache-from")
+
 	// finally, build the image
 	heartbeat := resolver.StartHeartbeat(ctx)
 	defer resolver.StopHeartbeat(heartbeat)
@@ -333,6 +502,10 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 	if err == nil {
 		tb.Printf("image: %s\n", img.Tag)
 		tb.Printf("image size: %s\n", humanize.Bytes(uint64(img.Size)))
+
+		for _, stage := range img.Stages {
+			tb.Printf("stage %s: %d of %d steps cached\n", stage.Stage, stage.Cached, stage.Steps)
+		}
 	}
 
 	return
@@ -348,7 +521,7 @@ func resolveDockerfilePath(ctx context.Context, appConfig *app.Config) (path str
 	}()
 
 	if path = appConfig.Dockerfile(); path != "" {
-		path = filepath.Join(filepath.Dir(appConfig.Path), path)
+		path = filepath.Join(configDir(ctx, appConfig), path)
 	} else {
 		path = flag.GetString(ctx, "dockerfile")
 	}
@@ -356,6 +529,35 @@ func resolveDockerfilePath(ctx context.Context, appConfig *app.Config) (path str
 	return
 }
 
+// checkImagePlatform makes sure img has a variant machines can run before any
+// of them are touched. Images outside the Fly registry and failures to read
+// the manifest don't hold the deployment up.
+func checkImagePlatform(ctx context.Context, img *imgsrc.DeploymentImage) error {
+	if !imgsrc.IsFlyRegistryImage(img.Tag) {
+		return nil
+	}
+
+	platforms, err := imgsrc.FetchImagePlatforms(ctx, img.Tag)
+	if err != nil {
+		logger.FromContext(ctx).Warnf("failed verifying the platform of image %s: %v", img.Tag, err)
+
+		return nil
+	}
+
+	return flyerr.WithCode(flyerr.CodePlatformUnsupported, imgsrc.CheckPlatforms(img.Tag, platforms))
+}
+
+// configDir returns the directory paths in appConfig are relative to. Configs
+// read from stdin or a URL have no such directory; their paths are relative to
+// the working directory.
+func configDir(ctx context.Context, appConfig *app.Config) string {
+	if appConfig.Path == "" {
+		return state.WorkingDirectory(ctx)
+	}
+
+	return filepath.Dir(appConfig.Path)
+}
+
 // resolveIgnorefilePath returns the absolute path to the Dockerfile
 // if one was specified in the app config or a command line argument
 func resolveIgnorefilePath(ctx context.Context, appConfig *app.Config) (path string, err error) {
@@ -366,7 +568,7 @@ func resolveIgnorefilePath(ctx context.Context, appConfig *app.Config) (path str
 	}()
 
 	if path = appConfig.Ignorefile(); path != "" {
-		path = filepath.Join(filepath.Dir(appConfig.Path), path)
+		path = filepath.Join(configDir(ctx, appConfig), path)
 	} else {
 		path = flag.GetString(ctx, "ignorefile")
 	}
@@ -382,7 +584,7 @@ func mergeBuildArgs(ctx context.Context, args map[string]string) (map[string]str
 	// set additional Docker build args from the command line, overriding similar ones from the config
 	cliBuildArgs, err := cmdutil.ParseKVStringsToMap(flag.GetStringSlice(ctx, "build-arg"))
 	if err != nil {
-		return nil, fmt.Errorf("invalid build args: %w", err)
+		return nil, flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("invalid build args: %w", err))
 	}
 
 	for k, v := range cliBuildArgs {
@@ -405,6 +607,19 @@ func fetchImageRef(ctx context.Context, cfg *app.Config) (ref string, err error)
 	return ref, nil
 }
 
+// recordReleaseCommandInstance stores the ID of the VM which ran the release
+// command in the release's metadata, so its logs can be found later on.
+func recordReleaseCommandInstance(ctx context.Context, release *api.Release, releaseCommandID string) {
+	rc, err := client.FromContext(ctx).API().GetReleaseCommand(ctx, releaseCommandID)
+	if err != nil || rc.InstanceID == nil {
+		return
+	}
+
+	updateReleaseMetadata(ctx, release, func(m *api.ReleaseMetadata) {
+		m.ReleaseCommandInstanceID = *rc.InstanceID
+	})
+}
+
 func createRelease(ctx context.Context, appConfig *app.Config, img *imgsrc.DeploymentImage) (*api.Release, *api.ReleaseCommand, error) {
 	tb := render.NewTextBlock(ctx, "Creating release")
 
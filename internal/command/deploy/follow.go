@@ -0,0 +1,185 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+	"github.com/superfly/flyctl/logs"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// followChecksInterval is how often --watch looks at the checks of the
+// updated machines.
+const followChecksInterval = 5 * time.Second
+
+// followDeployment streams the logs of the machines a deployment updated and
+// prints the changes of their checks for --watch-duration, or until
+// interrupted. It fails if the checks of any of them went critical meanwhile.
+func followDeployment(ctx context.Context, appName string, machines []*api.Machine) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		colorize  = io.ColorScheme()
+		apiClient = client.FromContext(ctx).API()
+	)
+
+	duration, err := watchDuration(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(machines) == 0 {
+		fmt.Fprintln(io.Out, "No machines were updated; nothing to watch")
+		return nil
+	}
+
+	app, err := apiClient.GetAppCompact(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	ids := make(map[string]bool, len(machines))
+	for _, m := range machines {
+		ids[m.ID] = true
+	}
+
+	fmt.Fprintf(io.Out, "Watching %d machines for %s; press Ctrl-C to stop early\n", len(machines), duration)
+
+	entries := make(chan logs.LogEntry)
+	go func() {
+		defer close(entries)
+
+		opts := &logs.LogOptions{AppName: appName}
+		if err := logs.Poll(ctx, entries, apiClient, opts); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(io.ErrOut, "failed streaming logs: %v\n", err)
+		}
+	}()
+	rendered := make(chan struct{})
+	go func() {
+		defer close(rendered)
+
+		for entry := range entries {
+			if ids[entry.Instance] {
+				render.LogEntry(io.Out, entry)
+			}
+		}
+	}()
+
+	critical := followChecks(ctx, flapsClient, machines)
+
+	// stop streaming logs, and wait for those received to be printed before
+	// the summary
+	cancel()
+	<-rendered
+
+	if len(critical) > 0 {
+		fmt.Fprintln(io.ErrOut, colorize.Red(fmt.Sprintf("Checks went critical while watching on machines %s", strings.Join(critical, ", "))))
+
+		return fmt.Errorf("checks of %d updated machines went critical after the deployment", len(critical))
+	}
+
+	fmt.Fprintln(io.Out, colorize.Green("No checks went critical while watching"))
+
+	return nil
+}
+
+// watchDuration returns how long --watch follows the updated machines for.
+func watchDuration(ctx context.Context) (time.Duration, error) {
+	val := flag.GetString(ctx, "watch-duration")
+
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --watch-duration %q: must be a positive duration such as 2m", val)
+	}
+
+	return d, nil
+}
+
+// followChecks prints each change of the status of the checks of machines
+// until ctx is done, returning the IDs of the machines any check of which was
+// seen critical.
+func followChecks(ctx context.Context, flapsClient *flaps.Client, machines []*api.Machine) []string {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		statuses = map[string]string{}
+		critical = map[string]bool{}
+	)
+
+	ticker := time.NewTicker(followChecksInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, m := range machines {
+			current, err := flapsClient.Get(ctx, m.ID)
+			if err != nil {
+				if ctx.Err() == nil {
+					terminal.Debugf("failed getting machine %s: %v\n", m.ID, err)
+				}
+				continue
+			}
+
+			for _, check := range current.Checks {
+				key := m.ID + "/" + check.Name
+
+				previous, seen := statuses[key]
+				statuses[key] = check.Status
+				if _, paused := current.ChecksPaused(time.Now()); paused {
+					// excluded for maintenance with `machine pause-checks`
+					continue
+				}
+				if check.Status == "critical" {
+					critical[m.ID] = true
+				}
+
+				if seen && previous == check.Status {
+					continue
+				}
+
+				status := check.Status
+				switch status {
+				case "passing":
+					status = colorize.Green(status)
+				case "critical":
+					status = colorize.Red(status)
+				default:
+					status = colorize.Yellow(status)
+				}
+
+				if !seen {
+					fmt.Fprintf(io.Out, "Machine %s check %s is %s\n", m.ID, check.Name, status)
+				} else {
+					fmt.Fprintf(io.Out, "Machine %s check %s went from %s to %s\n", m.ID, check.Name, previous, status)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			ids := make([]string, 0, len(critical))
+			for id := range critical {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+
+			return ids
+		case <-ticker.C:
+		}
+	}
+}
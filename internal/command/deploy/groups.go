@@ -0,0 +1,306 @@
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/shlex"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/build/imgsrc"
+	"github.com/superfly/flyctl/internal/env"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// buildDigestMetadataKey is the machine metadata key deployments record the
+// digest of the build context of the image of a process group under.
+const buildDigestMetadataKey = "fly_build_digest"
+
+// groupImage is the image the machines of a process group with a build of
+// its own are deployed with.
+type groupImage struct {
+	Tag string
+	// ImageDigest is the digest of the image's manifest, if known.
+	ImageDigest string
+	// Digest is that of the build context the image was built from.
+	Digest string
+}
+
+// determineGroupImages builds the images of the process groups of appConfig
+// with builds of their own. With --only-build-changed-groups, groups whose
+// build context is unchanged since their machines were deployed keep their
+// current image.
+func determineGroupImages(ctx context.Context, app *api.AppCompact, appConfig *app.Config, img *imgsrc.DeploymentImage) (map[string]groupImage, error) {
+	if len(appConfig.ProcessBuilds) == 0 {
+		return nil, nil
+	}
+
+	var current map[string]groupImage
+	if flag.GetBool(ctx, "only-build-changed-groups") {
+		flapsClient, err := flaps.New(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+
+		machines, err := flapsClient.ListActive(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		current = currentGroupImages(machines)
+	}
+
+	names := make([]string, 0, len(appConfig.ProcessBuilds))
+	for name := range appConfig.ProcessBuilds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	images := make(map[string]groupImage, len(names))
+	for _, name := range names {
+		contextDir, dockerfile := groupBuildPaths(ctx, appConfig, appConfig.ProcessBuilds[name])
+
+		digest, err := buildContextDigest(contextDir, dockerfile, appConfig.ProcessBuilds[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed computing the build context digest of process group %s: %w", name, err)
+		}
+
+		if cur, ok := current[name]; ok && cur.Digest == digest {
+			fmt.Fprintf(iostreams.FromContext(ctx).Out, "Skipping the build of process group %s; its build context is unchanged\n", name)
+			images[name] = cur
+
+			continue
+		}
+
+		tag, err := buildGroupImage(ctx, appConfig, name, contextDir, dockerfile, img.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("failed building the image of process group %s: %w", name, err)
+		}
+
+		imageDigest, err := imgsrc.FetchImageDigest(ctx, tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed fetching the digest of the image of process group %s: %w", name, err)
+		}
+
+		images[name] = groupImage{Tag: tag, ImageDigest: imageDigest, Digest: digest}
+	}
+
+	return images, nil
+}
+
+// currentGroupImages returns the images the process groups of machines run,
+// for the groups all machines of which run the same image built from the
+// same context.
+func currentGroupImages(machines []*api.Machine) map[string]groupImage {
+	images := map[string]groupImage{}
+	mixed := map[string]bool{}
+
+	for _, m := range machines {
+		group := m.Config.Metadata["process_group"]
+
+		image := groupImage{Tag: m.Config.Image, ImageDigest: m.ImageRef.Digest, Digest: m.Config.Metadata[buildDigestMetadataKey]}
+		if prev, ok := images[group]; (ok && prev != image) || image.Digest == "" {
+			mixed[group] = true
+		}
+		images[group] = image
+	}
+
+	for group := range mixed {
+		delete(images, group)
+	}
+
+	return images
+}
+
+// groupBuildPaths returns the absolute paths of the build context and
+// Dockerfile of build. Both are relative to the directory of the config.
+func groupBuildPaths(ctx context.Context, appConfig *app.Config, build *app.ProcessBuild) (contextDir, dockerfile string) {
+	base := configDir(ctx, appConfig)
+
+	contextDir = base
+	if build.Context != "" {
+		contextDir = filepath.Join(base, build.Context)
+	}
+
+	dockerfile = filepath.Join(contextDir, "Dockerfile")
+	if build.Dockerfile != "" {
+		dockerfile = filepath.Join(base, build.Dockerfile)
+	}
+
+	contextDir, _ = filepath.Abs(contextDir)
+	dockerfile, _ = filepath.Abs(dockerfile)
+
+	return
+}
+
+// buildContextDigest digests the files of contextDir, the Dockerfile and the
+// build's settings, so that changes to any of them call for a new image.
+func buildContextDigest(contextDir, dockerfile string, build *app.ProcessBuild) (string, error) {
+	h := sha256.New()
+
+	err := filepath.WalkDir(contextDir, func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir() && d.Name() == ".git":
+			return filepath.SkipDir
+		case !d.Type().IsRegular():
+			return nil
+		}
+
+		rel, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+
+		return hashFile(h, path)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(h, "dockerfile\x00")
+	if err := hashFile(h, dockerfile); err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(build.Args))
+	for k := range build.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(h, "target\x00%s\x00", build.DockerBuildTarget)
+	for _, k := range keys {
+		fmt.Fprintf(h, "arg\x00%s=%s\x00", k, build.Args[k])
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
+
+// buildGroupImage builds and pushes the image of process group name, returning
+// its tag.
+func buildGroupImage(ctx context.Context, appConfig *app.Config, name, contextDir, dockerfile string, labels map[string]string) (string, error) {
+	var (
+		client = client.FromContext(ctx).API()
+		io     = iostreams.FromContext(ctx)
+		build  = appConfig.ProcessBuilds[name]
+	)
+
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Building image of process group %s", name))
+	daemonType := imgsrc.NewDockerDaemonType(!flag.GetRemoteOnly(ctx), !flag.GetLocalOnly(ctx), env.IsCI(), flag.GetBool(ctx, "nixpacks"))
+	resolver := imgsrc.NewResolver(daemonType, client, appConfig.AppName, io)
+
+	buildArgs, err := mergeBuildArgs(ctx, build.Args)
+	if err != nil {
+		return "", err
+	}
+
+	opts := imgsrc.ImageOptions{
+		AppName:        appConfig.AppName,
+		WorkingDir:     contextDir,
+		DockerfilePath: dockerfile,
+		Publish:        true,
+		NoCache:        flag.GetBool(ctx, "no-cache"),
+		BuildArgs:      buildArgs,
+		Target:         build.DockerBuildTarget,
+		CacheFrom:      flag.GetStringSlice(ctx, "cache-from"),
+		Labels:         labels,
+	}
+	if label := flag.GetString(ctx, "image-label"); label != "" {
+		opts.ImageLabel = fmt.Sprintf("%s-%s", label, name)
+	}
+
+	heartbeat := resolver.StartHeartbeat(ctx)
+	defer resolver.StopHeartbeat(heartbeat)
+
+	img, err := resolver.BuildImage(ctx, io, opts)
+	if err != nil {
+		return "", err
+	}
+
+	tb.Printf("image: %s\n", img.Tag)
+
+	return img.Tag, nil
+}
+
+// applyGroupProcess gives config, a copy of the config of a machine of
+// process group group, the group and command of the group in appConfig, if
+// it has one.
+func applyGroupProcess(config *api.MachineConfig, group string, appConfig *app.Config) error {
+	if appConfig == nil {
+		return nil
+	}
+
+	cmd, ok := appConfig.Processes[group]
+	if !ok {
+		return nil
+	}
+	config.Metadata["process_group"] = group
+
+	if cmd == "" {
+		return nil
+	}
+
+	args, err := shlex.Split(cmd)
+	if err != nil {
+		return fmt.Errorf("failed parsing the command of process group %s: %w", group, err)
+	}
+	config.Init.Cmd = args
+
+	return nil
+}
+
+// applyGroupImage points config, a copy of the config of a machine of process
+// group group, to the group's image.
+func applyGroupImage(config *api.MachineConfig, group string, image groupImage, appConfig *app.Config) {
+	config.Image = image.Tag
+	config.Metadata["process_group"] = group
+	config.Metadata[buildDigestMetadataKey] = image.Digest
+
+	if len(appConfig.Containers) > 0 {
+		config.Containers = appConfig.MachineContainers(image.Tag)
+	}
+}
+
+// groupImageRefs returns the references of images, pinned to their digests
+// where those are known, by process group.
+func groupImageRefs(images map[string]groupImage) map[string]string {
+	if len(images) == 0 {
+		return nil
+	}
+
+	refs := make(map[string]string, len(images))
+	for group, image := range images {
+		refs[group] = image.Tag
+		if image.ImageDigest != "" {
+			refs[group] = fmt.Sprintf("%s@%s", image.Tag, image.ImageDigest)
+		}
+	}
+
+	return refs
+}
@@ -0,0 +1,86 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/superfly/flyctl/internal/cmdutil"
+	"github.com/superfly/flyctl/internal/env"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+const (
+	gitSHALabel   = "fly.git-sha"
+	buildURLLabel = "fly.build-url"
+)
+
+// labelKeyPattern matches the keys of OCI annotations, which are
+// conventionally in reverse domain notation, e.g. org.opencontainers.image.revision.
+var labelKeyPattern = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+
+// validateLabelKey checks key is a valid OCI annotation key that doesn't
+// collide with the metadata flyctl keeps on machines.
+func validateLabelKey(key string) error {
+	switch {
+	case !labelKeyPattern.MatchString(key):
+		return fmt.Errorf("invalid label key %q: keys consist of lowercase letters and digits, separated by single dots, dashes or underscores", key)
+	case key == "process_group" || strings.HasPrefix(key, "fly_"):
+		return fmt.Errorf("invalid label key %q: the key is reserved for machine metadata flyctl manages", key)
+	default:
+		return nil
+	}
+}
+
+// determineLabels returns the labels to apply to the image and machines of
+// the deployment, merging the --label flags over the automatic ones.
+func determineLabels(ctx context.Context) (map[string]string, error) {
+	labels := map[string]string{}
+
+	if flag.GetBool(ctx, "auto-labels") {
+		for key, value := range autoLabels() {
+			labels[key] = value
+		}
+	}
+
+	cliLabels, err := cmdutil.ParseKVStringsToMap(flag.GetStringSlice(ctx, "label"))
+	if err != nil {
+		return nil, flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("failed parsing labels: %w", err))
+	}
+
+	for key, value := range cliLabels {
+		if err := validateLabelKey(key); err != nil {
+			return nil, flyerr.WithCode(flyerr.CodeValidation, err)
+		}
+		labels[key] = value
+	}
+
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	return labels, nil
+}
+
+// autoLabels derives the git SHA and build URL labels from the environment
+// the CI providers we know of set up.
+func autoLabels() map[string]string {
+	labels := map[string]string{}
+
+	if sha := env.First("GITHUB_SHA", "CI_COMMIT_SHA", "CIRCLE_SHA1", "BUILDKITE_COMMIT", "GIT_COMMIT"); sha != "" {
+		labels[gitSHALabel] = sha
+	}
+
+	url := env.First("CI_JOB_URL", "CIRCLE_BUILD_URL", "BUILDKITE_BUILD_URL", "BUILD_URL")
+	if server, repo, run := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"); server != "" && repo != "" && run != "" {
+		url = fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, run)
+	}
+	if url != "" {
+		labels[buildURLLabel] = url
+	}
+
+	return labels
+}
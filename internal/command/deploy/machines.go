@@ -4,21 +4,31 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/build/imgsrc"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/spinner"
+	"github.com/superfly/flyctl/internal/tracing"
+	"github.com/superfly/flyctl/internal/watch"
 	"github.com/superfly/flyctl/iostreams"
+	"github.com/superfly/flyctl/terminal"
 )
 
 // Deploy ta machines app directly from flyctl, applying the desired config to running machines,
 // or launching new ones
-func createMachinesRelease(ctx context.Context, config *app.Config, img *imgsrc.DeploymentImage, strategy string) (err error) {
+func createMachinesRelease(ctx context.Context, config *app.Config, img *imgsrc.DeploymentImage, strategy string) (updated []*api.Machine, err error) {
 	client := client.FromContext(ctx).API()
 
 	app, err := client.GetAppCompact(ctx, config.AppName)
@@ -26,10 +36,37 @@ func createMachinesRelease(ctx context.Context, config *app.Config, img *imgsrc.
 		return
 	}
 
+	scope, err := newRegionScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateOrphanFlags(ctx); err != nil {
+		return nil, err
+	}
+
+	if flag.GetBool(ctx, "watch") {
+		if _, err = watchDuration(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	maintenance, err := newMaintenancePage(ctx, strategy)
+	if err != nil {
+		return nil, err
+	}
+
 	machineConfig := api.MachineConfig{
 		Image: img.Tag,
 	}
 
+	if len(img.Labels) > 0 {
+		machineConfig.Metadata = make(map[string]string, len(img.Labels))
+		for key, value := range img.Labels {
+			machineConfig.Metadata[key] = value
+		}
+	}
+
 	// Convert the new, slimmer http service config to standard services
 	if config.HttpService != nil {
 		concurrency := config.HttpService.Concurrency
@@ -79,29 +116,217 @@ func createMachinesRelease(ctx context.Context, config *app.Config, img *imgsrc.
 		machineConfig.Metrics = config.Metrics
 	}
 
-	if config.Checks != nil {
-		machineConfig.Checks = config.Checks
+	if machineConfig.Checks, err = config.MachineChecks(); err != nil {
+		return nil, err
+	}
+
+	// all of a machine's containers are updated at once, along with the
+	// rest of its config
+	machineConfig.Containers = config.MachineContainers(img.Tag)
+
+	if config.SwapSizeMB != nil {
+		if err := mach.ValidateSwapSize(*config.SwapSizeMB); err != nil {
+			return nil, fmt.Errorf("invalid swap_size_mb in fly.toml: %w", err)
+		}
+		machineConfig.Init.SwapSizeMB = config.SwapSizeMB
 	}
 
 	// Run validations against struct types and their JSON tags
 	err = config.Validate()
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := applyWaitGracePeriod(ctx, config); err != nil {
+		return nil, err
+	}
+
+	if !flag.GetBool(ctx, "skip-secret-validation") {
+		if err := mach.ValidateSecrets(ctx, app.Name, config.ReferencedSecrets()); err != nil {
+			return nil, err
+		}
+	}
+
+	groupImages, err := determineGroupImages(ctx, app, config, img)
+	if err != nil {
+		return nil, err
+	}
+
+	release := createMachinesReleaseRecord(ctx, app, config, img, strategy)
+	if len(groupImages) > 0 {
+		updateReleaseMetadata(ctx, release, func(m *api.ReleaseMetadata) {
+			m.GroupImages = groupImageRefs(groupImages)
+		})
+	}
+	defer func() {
+		status := "complete"
+		if err != nil {
+			status = "failed"
+		}
+		updateRelease(ctx, release, api.UpdateReleaseInput{Status: status})
+	}()
+
+	if err := RunReleaseCommand(ctx, app, config, machineConfig, release); err != nil {
+		return nil, fmt.Errorf("release command failed - aborting deployment. %w", err)
+	}
+
+	if updated, err = deployMachinesApp(ctx, app, strategy, machineConfig, config, groupImages, scope, flag.GetBool(ctx, "revert-on-failure"), release, maintenance); err != nil {
+		return nil, err
+	}
+
+	orphans, err := handleOrphanedGroups(ctx, app, config, scope)
+
+	// machines left on the partial release this one completes count as
+	// being on it
+	var completing int
+	prev := previousPartialRelease(ctx, app, release)
+	if prev != nil {
+		completing = prev.Version
+	}
+
+	regions := scope.laggingRegions(completing)
+	if len(regions) > 0 || len(orphans) > 0 {
+		updateReleaseMetadata(ctx, release, func(m *api.ReleaseMetadata) {
+			m.SkippedRegions = regions
+			m.OrphanedMachines = orphans
+		})
+	}
+
+	if prev != nil {
+		remaining := lo.Reject(prev.Metadata.SkippedRegions, func(region string, _ int) bool {
+			return scope.coversRegion(region)
+		})
+		if len(remaining) < len(prev.Metadata.SkippedRegions) {
+			updateReleaseMetadata(ctx, prev, func(m *api.ReleaseMetadata) {
+				m.SkippedRegions = remaining
+			})
+		}
+	}
+
+	// partial releases are completed by deploying to the skipped regions
+	if release != nil && len(regions) > 0 {
+		list := strings.Join(regions, ",")
+		fmt.Fprintf(iostreams.FromContext(ctx).Out, "Release v%d is partial; complete it with: fly deploy --only-regions %s\n", release.Version, list)
+	}
+
+	return updated, err
+}
+
+// applyWaitGracePeriod overrides the grace period from fly.toml with the one
+// passed on the command line, if any
+func applyWaitGracePeriod(ctx context.Context, config *app.Config) error {
+	val := flag.GetString(ctx, "wait-grace-period")
+	if val == "" {
+		return nil
 	}
 
-	if err := RunReleaseCommand(ctx, app, config, machineConfig); err != nil {
-		return fmt.Errorf("release command failed - aborting deployment. %w", err)
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return fmt.Errorf("invalid wait grace period %q: %w", val, err)
+	}
+
+	if config.Deploy == nil {
+		config.Deploy = &app.Deploy{}
+	}
+	config.Deploy.WaitGracePeriod = &api.Duration{Duration: d}
+
+	return nil
+}
+
+// createMachinesReleaseRecord records the deployment as a release of the app.
+// Since the release only carries metadata the deployment goes on without it
+// should the API refuse to create one.
+func createMachinesReleaseRecord(ctx context.Context, app *api.AppCompact, appConfig *app.Config, img *imgsrc.DeploymentImage, strategy string) *api.Release {
+	input := api.CreateReleaseInput{
+		AppID:           app.ID,
+		Image:           img.Tag,
+		PlatformVersion: "machines",
+		Strategy:        strings.ToUpper(strategy),
+	}
+
+	if len(appConfig.Definition) > 0 {
+		input.Definition = api.DefinitionPtr(appConfig.Definition)
+	}
+
+	release, err := client.FromContext(ctx).API().CreateRelease(ctx, input)
+	if err != nil {
+		terminal.Debugf("failed creating release: %v\n", err)
+		return nil
 	}
 
-	return DeployMachinesApp(ctx, app, strategy, machineConfig, config)
+	return release
 }
 
-func RunReleaseCommand(ctx context.Context, app *api.AppCompact, appConfig *app.Config, machineConfig api.MachineConfig) (err error) {
+// updateRelease applies input to the release, if there is one. Failures are
+// not fatal to the deployment.
+// updateReleaseMetadata applies update to the metadata of release and records
+// it. The API replaces the metadata of releases as a whole, so whatever's
+// been recorded before is carried over.
+func updateReleaseMetadata(ctx context.Context, release *api.Release, update func(*api.ReleaseMetadata)) {
+	if release == nil {
+		return
+	}
+
+	if release.Metadata == nil {
+		release.Metadata = &api.ReleaseMetadata{}
+	}
+	update(release.Metadata)
+
+	updateRelease(ctx, release, api.UpdateReleaseInput{Metadata: release.Metadata})
+}
+
+// previousPartialRelease returns the release before release, should it have
+// left some regions behind.
+func previousPartialRelease(ctx context.Context, app *api.AppCompact, release *api.Release) *api.Release {
+	if release == nil {
+		return nil
+	}
+
+	releases, err := client.FromContext(ctx).API().GetAppReleases(ctx, app.Name, 5)
+	if err != nil {
+		terminal.Debugf("failed retrieving the releases of %s: %v\n", app.Name, err)
+		return nil
+	}
+
+	for i := range releases {
+		if releases[i].Version >= release.Version {
+			continue
+		}
+
+		if releases[i].Metadata == nil || len(releases[i].Metadata.SkippedRegions) == 0 {
+			return nil
+		}
+
+		return &releases[i]
+	}
+
+	return nil
+}
+
+func updateRelease(ctx context.Context, release *api.Release, input api.UpdateReleaseInput) {
+	if release == nil {
+		return
+	}
+
+	input.ReleaseID = release.ID
+	if _, err := client.FromContext(ctx).API().UpdateRelease(ctx, input); err != nil {
+		terminal.Debugf("failed updating release %s: %v\n", release.ID, err)
+	}
+}
+
+// RunReleaseCommand runs the release command of appConfig on a temporary
+// machine. The ID of that machine is recorded against release, if given.
+func RunReleaseCommand(ctx context.Context, app *api.AppCompact, appConfig *app.Config, machineConfig api.MachineConfig, release *api.Release) (err error) {
 	if appConfig.Deploy == nil || appConfig.Deploy.ReleaseCommand == "" {
 		return nil
 	}
 
+	ctx, span := tracing.StartSpan(ctx, "release_command")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	io := iostreams.FromContext(ctx)
 
 	flapsClient, err := flaps.New(ctx, app)
@@ -142,9 +367,17 @@ func RunReleaseCommand(ctx context.Context, app *api.AppCompact, appConfig *app.
 		ID:    machine.ID,
 	}
 
+	span.SetAttributes(attribute.String("machine.id", machine.ID))
+
 	// Make sure we clean up the release command VM
 	defer flapsClient.Destroy(ctx, removeInput)
 
+	// Record the machine right away so it can be tracked down even if we
+	// don't make it to the end
+	updateReleaseMetadata(ctx, release, func(m *api.ReleaseMetadata) {
+		m.ReleaseCommandInstanceID = machine.ID
+	})
+
 	// Ensure the command starts running
 	err = flapsClient.Wait(ctx, machine, "started")
 
@@ -192,14 +425,35 @@ func RunReleaseCommand(ctx context.Context, app *api.AppCompact, appConfig *app.
 	exitCode := lastExitEvent.Request.ExitEvent.ExitCode
 
 	if exitCode != 0 {
-		return fmt.Errorf("release command exited with non-zero status of %d", exitCode)
+		rcErr := &watch.ReleaseCommandError{
+			InstanceID: machine.ID,
+			Reason:     fmt.Sprintf("release command exited with non-zero status of %d", exitCode),
+		}
+
+		if rcErr.Logs, err = watch.RecentLogs(ctx, client.FromContext(ctx).API(), app.Name, machine.ID); err != nil {
+			terminal.Debugf("failed fetching release command logs: %v\n", err)
+		}
+
+		return rcErr
 	}
 
 	return
 }
 
 func DeployMachinesApp(ctx context.Context, app *api.AppCompact, strategy string, machineConfig api.MachineConfig, appConfig *app.Config) (err error) {
+	_, err = deployMachinesApp(ctx, app, strategy, machineConfig, appConfig, nil, nil, false, nil, nil)
+	return
+}
+
+// deployMachinesApp rolls machineConfig out to the machines of app within
+// scope, or all of them if scope is nil. Machines of the process groups in
+// groupImages get the images of their groups instead. It returns the machines
+// it updated, or launched when the app had none. The progress of the rollout
+// is recorded in release, if given. Should maintenance be given, it's served
+// while the only machine of the app is replaced.
+func deployMachinesApp(ctx context.Context, app *api.AppCompact, strategy string, machineConfig api.MachineConfig, appConfig *app.Config, groupImages map[string]groupImage, scope *regionScope, revertOnFailure bool, release *api.Release, maintenance *maintenancePage) (updated []*api.Machine, err error) {
 	io := iostreams.FromContext(ctx)
+	started := time.Now()
 	flapsClient, err := flaps.New(ctx, app)
 	if err != nil {
 		return
@@ -210,16 +464,26 @@ func DeployMachinesApp(ctx context.Context, app *api.AppCompact, strategy string
 		strategy = "rolling"
 	}
 
-	var regionCode string
+	var (
+		regionCode  string
+		gracePeriod time.Duration
+	)
 	if appConfig != nil {
 		regionCode = appConfig.PrimaryRegion
+
+		if appConfig.Deploy != nil && appConfig.Deploy.WaitGracePeriod != nil {
+			gracePeriod = appConfig.Deploy.WaitGracePeriod.Duration
+		}
 	}
 
 	msg := fmt.Sprintf("Deploying with %s strategy", strategy)
 	spin := spinner.Run(io, msg)
 	defer spin.StopWithSuccess()
 
-	machineConfig.Metadata = map[string]string{"process_group": "app"}
+	if machineConfig.Metadata == nil {
+		machineConfig.Metadata = map[string]string{}
+	}
+	machineConfig.Metadata["process_group"] = "app"
 	machineConfig.Init.Cmd = nil
 
 	launchInput := api.LaunchMachineInput{
@@ -234,20 +498,89 @@ func DeployMachinesApp(ctx context.Context, app *api.AppCompact, strategy string
 		return
 	}
 
+	if scope != nil && len(machines) > 0 {
+		scope.warnUnmatched(io.ErrOut, io.ColorScheme(), machines)
+
+		if machines = scope.filter(machines); len(machines) == 0 {
+			scope.printSkipped(io.Out)
+			fmt.Fprintln(io.Out, "No machines to update within the rollout's regions")
+
+			return
+		}
+		scope.printSkipped(io.Out)
+	}
+
+	if err = validateGPURegions(ctx, appConfig, machines, regionCode); err != nil {
+		return
+	}
+
+	if maintenance != nil && !maintenance.appliesTo(machines) {
+		fmt.Fprintln(io.ErrOut, "Not serving a maintenance page as it's only served for apps with a single machine with a volume")
+		maintenance = nil
+	}
+
+	for _, machine := range machines {
+		if machine.HostImpaired() {
+			fmt.Fprintln(io.ErrOut, io.ColorScheme().Yellow(fmt.Sprintf(
+				"The host of machine %s is %s, which may keep it from updating; `fly machine clone %s` moves a copy of it to another host",
+				machine.ID, machine.HostHealth(), machine.ID)))
+		}
+	}
+
 	if len(machines) > 0 {
 
 		for _, machine := range machines {
 			leaseTTL := api.IntPointer(30)
 			lease, err := flapsClient.AcquireLease(ctx, machine.ID, leaseTTL)
 			if err != nil {
-				return err
+				return updated, err
 			}
 			machine.LeaseNonce = lease.Data.Nonce
 
 			defer releaseLease(ctx, machine)
 		}
 
+		var snapshots *mach.SnapshotFile
+		if revertOnFailure {
+			if snapshots, err = mach.NewSnapshotFile(app.Name, machines); err != nil {
+				return updated, err
+			}
+
+			var path string
+			if path, err = snapshots.Write(); err != nil {
+				return updated, err
+			}
+			fmt.Fprintf(io.Out, "Saved the current configuration of %d machines to %s\n", len(machines), path)
+
+			// registered after the lease releases so that it runs while the
+			// leases are still held
+			defer func() {
+				if err == nil && ctx.Err() == nil {
+					os.Remove(path)
+					return
+				}
+				spin.Stop()
+				revertMachines(ctx, flapsClient, snapshots, path, updated)
+			}()
+		}
+
+		progress := newRolloutProgress(ctx, release, machineConfig.Image, machines)
+
 		for _, machine := range machines {
+			// orphaned machines are dealt with once the others are updated
+			if _, orphaned := orphanedGroup(machine, appConfig); orphaned && machineConfig.Image != "" {
+				progress.set(ctx, machine.ID, api.RolloutSkipped)
+
+				continue
+			}
+
+			if version, pinned := mach.PinnedRelease(machine); pinned && machineConfig.Image != "" {
+				fmt.Fprintf(io.ErrOut, "Skipping machine %s as it's pinned to release v%s; clear the pin with `fly machine update %s --clear-pin`\n", machine.ID, version, machine.ID)
+				progress.set(ctx, machine.ID, api.RolloutSkipped)
+
+				continue
+			}
+
 			launchInput.ID = machine.ID
 
 			// We assume a config with no image specificed means the deploy should recreate machines
@@ -262,11 +595,17 @@ func DeployMachinesApp(ctx context.Context, app *api.AppCompact, strategy string
 				launchInput.Config.Env["PRIMARY_REGION"] = machine.Config.Env["PRIMARY_REGION"]
 			}
 
-			launchInput.Config.Checks = machine.Config.Checks
+			// machines keep their checks unless fly.toml defines some
+			if appConfig == nil || len(appConfig.Checks) == 0 {
+				launchInput.Config.Checks = machine.Config.Checks
+			}
 
 			if machine.Config.Guest != nil {
 				launchInput.Config.Guest = machine.Config.Guest
 			}
+			if appConfig != nil {
+				launchInput.Config.Guest = appConfig.GPUGuest(launchInput.Config.Guest)
+			}
 
 			// Until mounts are supported in fly.toml, ensure deployments
 			// maintain any existing volume attachments
@@ -274,35 +613,194 @@ func DeployMachinesApp(ctx context.Context, app *api.AppCompact, strategy string
 				launchInput.Config.Mounts = machine.Config.Mounts
 			}
 
-			updateResult, err := flapsClient.Update(ctx, launchInput, machine.LeaseNonce)
-			if err != nil {
-				if strategy != "immediate" {
-					return err
+			// tracked before updating since a failed update may still have
+			// been partially applied
+			updated = append(updated, machine)
 
-				} else {
-					fmt.Printf("Continuing after error: %s\n", err)
-				}
+			// Secrets scoped to the machine, its autoscale policy, any
+			// pause of its checks and whether it's orphaned outlive
+			// deployments, the latter until fly.toml has its group again
+			machineInput := launchInput
+			if machineInput.Config, err = mach.CloneConfig(*launchInput.Config); err != nil {
+				return updated, err
+			}
+			mach.PreserveScopedSecrets(machineInput.Config, machine.Config)
+			mach.PreserveMetadata(machineInput.Config, machine.Config)
+			if _, orphaned := orphanedGroup(machine, appConfig); !orphaned {
+				delete(machineInput.Config.Metadata, mach.OrphanedGroupMetadataKey)
 			}
+			stampRelease(machineInput.Config, release)
 
-			if strategy != "immediate" {
-				err = flapsClient.Wait(ctx, updateResult, "started")
-				if err != nil {
-					return err
-				}
+			group := machine.Config.Metadata["process_group"]
+			if err := applyGroupProcess(machineInput.Config, group, appConfig); err != nil {
+				return updated, err
 			}
+			if image, ok := groupImages[group]; ok {
+				applyGroupImage(machineInput.Config, group, image, appConfig)
+			}
+
+			progress.set(ctx, machine.ID, api.RolloutUpdating)
+
+			if maintenance != nil {
+				err = maintenance.rollout(ctx, flapsClient, app, machineInput, machine, strategy, gracePeriod)
+			} else {
+				err = rolloutMachine(ctx, flapsClient, machineInput, machine, strategy, gracePeriod)
+			}
+
+			if err != nil {
+				progress.set(ctx, machine.ID, api.RolloutFailed)
+
+				return updated, err
+			}
+
+			progress.set(ctx, machine.ID, api.RolloutDone)
 		}
 
+		maintenance.report(ctx, time.Since(started))
+
 	} else {
+		if appConfig != nil {
+			launchInput.Config.Guest = appConfig.GPUGuest(launchInput.Config.Guest)
+		}
+		stampRelease(launchInput.Config, release)
+
 		fmt.Fprintf(io.Out, "Launching VM with image %s\n", launchInput.Config.Image)
-		_, err = flapsClient.Launch(ctx, launchInput)
+		launched, err := flapsClient.Launch(ctx, launchInput)
 		if err != nil {
-			return err
+			return updated, err
 		}
+		updated = append(updated, launched)
 	}
 
 	return
 }
 
+// stampRelease records the version of release in the metadata of config, so
+// that `fly releases --deploy-history` may attribute the change to it.
+func stampRelease(config *api.MachineConfig, release *api.Release) {
+	if release == nil {
+		return
+	}
+
+	if config.Metadata == nil {
+		config.Metadata = map[string]string{}
+	}
+	config.Metadata[mach.ReleaseVersionMetadataKey] = strconv.Itoa(release.Version)
+}
+
+// validateGPURegions checks that the regions of machines, or the region of
+// the first machine when there are none, offer the GPUs [vm] asks for.
+func validateGPURegions(ctx context.Context, appConfig *app.Config, machines []*api.Machine, region string) error {
+	if appConfig == nil || appConfig.VM == nil || appConfig.VM.GPUKind == "" {
+		return nil
+	}
+
+	var regions []string
+	for _, machine := range machines {
+		if !lo.Contains(regions, machine.Region) {
+			regions = append(regions, machine.Region)
+		}
+	}
+	if len(machines) == 0 && region != "" {
+		regions = append(regions, region)
+	}
+
+	if err := mach.ValidateGPU(ctx, appConfig.GPUGuest(nil), regions...); err != nil {
+		return fmt.Errorf("invalid [vm] gpu_kind in fly.toml: %w", err)
+	}
+
+	return nil
+}
+
+// rolloutMachine updates machine according to launchInput and, unless the
+// strategy is immediate, waits for it to start and pass its health checks.
+func rolloutMachine(ctx context.Context, flapsClient *flaps.Client, launchInput api.LaunchMachineInput, machine *api.Machine, strategy string, gracePeriod time.Duration) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "machine.rollout",
+		attribute.String("machine.id", machine.ID),
+		attribute.String("machine.region", machine.Region),
+		attribute.String("image.ref", launchInput.Config.Image),
+	)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	updateCtx, updateSpan := tracing.StartSpan(ctx, "machine.update")
+	updateResult, err := flapsClient.Update(updateCtx, launchInput, machine.LeaseNonce)
+	tracing.EndSpan(updateSpan, err)
+	if err != nil {
+		if strategy != "immediate" {
+			return err
+		}
+
+		fmt.Printf("Continuing after error: %s\n", err)
+
+		return nil
+	}
+
+	if strategy == "immediate" {
+		return nil
+	}
+
+	waitCtx, waitSpan := tracing.StartSpan(ctx, "machine.wait")
+	err = flapsClient.Wait(waitCtx, updateResult, "started")
+	tracing.EndSpan(waitSpan, err)
+	if err != nil {
+		return err
+	}
+
+	checksCtx, checksSpan := tracing.StartSpan(ctx, "machine.checks")
+	err = watch.MachinesChecksWithGracePeriod(checksCtx, []*api.Machine{updateResult}, gracePeriod)
+	tracing.EndSpan(checksSpan, err)
+	if err != nil {
+		return fmt.Errorf("failed to wait for health checks to pass: %w", err)
+	}
+
+	return nil
+}
+
+// revertMachines restores each of the given machines to its snapshot,
+// reporting the outcome of each restore.
+func revertMachines(ctx context.Context, flapsClient *flaps.Client, snapshots *mach.SnapshotFile, path string, machines []*api.Machine) {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		failed   int
+	)
+
+	// the deployment may have been aborted by the user, in which case ctx is
+	// already done
+	revertCtx, cancel := context.WithTimeout(flaps.NewContext(context.Background(), flapsClient), 5*time.Minute)
+	defer cancel()
+
+	fmt.Fprintf(io.ErrOut, "Deployment failed; reverting %d updated machines\n", len(machines))
+
+	for _, m := range machines {
+		snapshot, ok := snapshots.Find(m.ID)
+		if !ok {
+			continue
+		}
+
+		// the short leases of the deployment may have lapsed by now, so each
+		// machine is leased anew for as long as it takes to restore
+		_ = releaseLease(revertCtx, m)
+
+		err := mach.WithLease(revertCtx, m, func(ctx context.Context, m *api.Machine) error {
+			return mach.Restore(ctx, snapshots.App, snapshot, m.LeaseNonce)
+		})
+		if err != nil {
+			failed++
+			fmt.Fprintf(io.ErrOut, "  %s machine %s: %v\n", colorize.Red("✘"), m.ID, err)
+			continue
+		}
+
+		fmt.Fprintf(io.ErrOut, "  %s machine %s restored\n", colorize.Green("✔"), m.ID)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(io.ErrOut, "%d machines could not be restored. Their previous configuration is in %s; see `flyctl releases rollback-info %s`\n", failed, path, path)
+	}
+}
+
 func releaseLease(ctx context.Context, machine *api.Machine) error {
 	var client = flaps.FromContext(ctx)
 
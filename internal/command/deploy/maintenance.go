@@ -0,0 +1,209 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/tracing"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	// maintenanceGroup is the process group of stopgap machines. Should one
+	// outlive its deployment, the next deployment treats it as orphaned.
+	maintenanceGroup = "maintenance_page"
+
+	maintenanceImage = "busybox:1.36"
+
+	defaultMaintenanceHTML = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Down for maintenance</title></head>
+<body><h1>Down for maintenance</h1><p>We're deploying an update and will be back shortly.</p></body>
+</html>
+`
+)
+
+// maintenancePage is the static page a stopgap machine serves on the ports of
+// the only machine of an app while that machine is replaced, as asked for
+// with --maintenance-page.
+type maintenancePage struct {
+	html string
+
+	// set once the machine is replaced
+	machineID string
+	downFrom  time.Time
+	downUntil time.Time
+}
+
+// newMaintenancePage returns the maintenance page the flags ask for, or nil
+// if they ask for none.
+func newMaintenancePage(ctx context.Context, strategy string) (*maintenancePage, error) {
+	path := flag.GetString(ctx, "maintenance-html")
+
+	if !flag.GetBool(ctx, "maintenance-page") {
+		if path != "" {
+			return nil, flyerr.WithCode(flyerr.CodeValidation, errors.New("--maintenance-html requires --maintenance-page"))
+		}
+
+		return nil, nil
+	}
+
+	// the stopgap serves until the machine passes its health checks, which
+	// the immediate strategy doesn't wait for
+	if strategy == "immediate" {
+		return nil, flyerr.WithCode(flyerr.CodeValidation, errors.New("--maintenance-page can't be used with the immediate strategy"))
+	}
+
+	page := &maintenancePage{html: defaultMaintenanceHTML}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading the maintenance page: %w", err)
+		}
+		page.html = string(data)
+	}
+
+	return page, nil
+}
+
+// appliesTo reports whether machines are those of an app which goes down
+// during deployments: a single machine with a volume, serving on some port.
+func (p *maintenancePage) appliesTo(machines []*api.Machine) bool {
+	if len(machines) != 1 || machines[0].Config == nil {
+		return false
+	}
+
+	config := machines[0].Config
+
+	return len(config.Mounts) > 0 && len(config.Services) > 0
+}
+
+// stopgapConfig returns the config of a machine serving the page on the
+// service ports of machine. It mounts none of the volumes of machine, and
+// runs none of its checks.
+func (p *maintenancePage) stopgapConfig(machine *api.Machine) *api.MachineConfig {
+	guest := *api.MachinePresets["shared-cpu-1x"]
+
+	config := &api.MachineConfig{
+		Image: maintenanceImage,
+		Env: map[string]string{
+			"MAINTENANCE_HTML": p.html,
+		},
+		Metadata: map[string]string{
+			"process_group": maintenanceGroup,
+		},
+		Guest: &guest,
+	}
+
+	seen := map[int]bool{}
+	var ports []int
+
+	for _, service := range machine.Config.Services {
+		service.Autostop = api.BoolPointer(false)
+		service.Autostart = api.BoolPointer(false)
+		config.Services = append(config.Services, service)
+
+		if !seen[service.InternalPort] {
+			seen[service.InternalPort] = true
+			ports = append(ports, service.InternalPort)
+		}
+	}
+	sort.Ints(ports)
+
+	// busybox httpd serves a single port, so there's one per service port;
+	// every path nonexistent serves the page too
+	script := []string{
+		"mkdir -p /www",
+		`printf '%s' "$MAINTENANCE_HTML" > /www/index.html`,
+		"echo 'E404:/www/index.html' > /etc/httpd.conf",
+	}
+	for i, port := range ports {
+		cmd := "httpd -h /www -c /etc/httpd.conf -p " + strconv.Itoa(port)
+		if i == len(ports)-1 {
+			cmd = "exec " + cmd + " -f"
+		}
+		script = append(script, cmd)
+	}
+
+	config.Init.Exec = []string{"/bin/sh", "-c", strings.Join(script, "\n")}
+
+	return config
+}
+
+// rollout replaces machine as rolloutMachine does, with a stopgap machine
+// serving the page in the region of machine until it's healthy again. The
+// stopgap is destroyed however the rollout ends, aborted ones included.
+func (p *maintenancePage) rollout(ctx context.Context, flapsClient *flaps.Client, app *api.AppCompact, launchInput api.LaunchMachineInput, machine *api.Machine, strategy string, gracePeriod time.Duration) (err error) {
+	io := iostreams.FromContext(ctx)
+
+	launchCtx, span := tracing.StartSpan(ctx, "maintenance_page.launch", attribute.String("machine.region", machine.Region))
+	stopgap, err := flapsClient.Launch(launchCtx, api.LaunchMachineInput{
+		AppID:   app.Name,
+		OrgSlug: app.Organization.ID,
+		Region:  machine.Region,
+		Config:  p.stopgapConfig(machine),
+	})
+	if err == nil {
+		defer p.destroyStopgap(ctx, flapsClient, stopgap)
+		err = flapsClient.Wait(launchCtx, stopgap, "started")
+	}
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return fmt.Errorf("failed launching the maintenance page machine: %w", err)
+	}
+
+	fmt.Fprintf(io.Out, "Serving the maintenance page from machine %s in %s\n", stopgap.ID, stopgap.Region)
+
+	p.machineID = machine.ID
+	p.downFrom = time.Now()
+	err = rolloutMachine(ctx, flapsClient, launchInput, machine, strategy, gracePeriod)
+	p.downUntil = time.Now()
+
+	return err
+}
+
+// destroyStopgap destroys the stopgap machine. The deployment may have been
+// aborted by the user, in which case ctx is already done.
+func (p *maintenancePage) destroyStopgap(ctx context.Context, flapsClient *flaps.Client, stopgap *api.Machine) {
+	io := iostreams.FromContext(ctx)
+
+	destroyCtx, cancel := context.WithTimeout(flaps.NewContext(context.Background(), flapsClient), time.Minute)
+	defer cancel()
+
+	if err := flapsClient.Destroy(destroyCtx, api.RemoveMachineInput{ID: stopgap.ID, Kill: true}); err != nil {
+		fmt.Fprintf(io.ErrOut, "Failed destroying maintenance page machine %s: %v\nDestroy it with `fly machine destroy --force %s`\n", stopgap.ID, err, stopgap.ID)
+
+		return
+	}
+
+	fmt.Fprintf(io.Out, "Destroyed maintenance page machine %s\n", stopgap.ID)
+}
+
+// report prints how long the replaced machine was down for, next to how long
+// the deployment took overall.
+func (p *maintenancePage) report(ctx context.Context, deployTime time.Duration) {
+	if p == nil || p.machineID == "" {
+		return
+	}
+
+	w := iostreams.FromContext(ctx).Out
+
+	downtime := p.downUntil.Sub(p.downFrom).Round(time.Second)
+
+	fmt.Fprintf(w, "Machine %s was down for %s (%s to %s) while the maintenance page was served\n",
+		p.machineID, downtime, p.downFrom.Format("15:04:05"), p.downUntil.Format("15:04:05"))
+	fmt.Fprintf(w, "The deployment took %s\n", deployTime.Round(time.Second))
+}
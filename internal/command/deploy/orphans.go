@@ -0,0 +1,157 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	orphanStop    = "stopped"
+	orphanDestroy = "destroyed"
+	orphanKeep    = "kept"
+)
+
+// orphanedGroup returns the process group of m, should appConfig no longer
+// have it. Machines of the default group, which deployments assign, are never
+// orphaned; those an earlier deployment marked are for as long as appConfig
+// lacks the group they were marked for.
+func orphanedGroup(m *api.Machine, appConfig *app.Config) (string, bool) {
+	if appConfig == nil || m.Config == nil {
+		return "", false
+	}
+
+	group, marked := mach.OrphanedGroup(m)
+	if !marked {
+		group = m.Config.Metadata["process_group"]
+	}
+	switch group {
+	case "", "app", "release_command":
+		return "", false
+	}
+
+	_, ok := appConfig.Processes[group]
+
+	return group, !ok
+}
+
+// validateOrphanFlags fails should both of the orphaned groups flags be set.
+func validateOrphanFlags(ctx context.Context) error {
+	if flag.GetBool(ctx, "prune-orphaned-groups") && flag.GetBool(ctx, "keep-orphaned-groups") {
+		return flyerr.WithCode(flyerr.CodeValidation, errors.New("--prune-orphaned-groups and --keep-orphaned-groups are mutually exclusive"))
+	}
+
+	return nil
+}
+
+// handleOrphanedGroups stops, destroys or keeps the machines within scope
+// whose process groups appConfig no longer has, as the orphaned groups flags
+// say or, failing that, as prompted for. Kept and stopped machines are marked
+// so that status keeps warning of them, and aren't asked about again unless
+// pruning.
+func handleOrphanedGroups(ctx context.Context, app *api.AppCompact, appConfig *app.Config, scope *regionScope) ([]api.OrphanedMachine, error) {
+	var (
+		io    = iostreams.FromContext(ctx)
+		prune = flag.GetBool(ctx, "prune-orphaned-groups")
+		keep  = flag.GetBool(ctx, "keep-orphaned-groups")
+	)
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		orphans []api.OrphanedMachine
+		rows    [][]string
+	)
+	for _, m := range scope.within(machines) {
+		if _, marked := mach.OrphanedGroup(m); marked && !prune {
+			continue
+		}
+
+		if group, ok := orphanedGroup(m, appConfig); ok {
+			orphans = append(orphans, api.OrphanedMachine{ID: m.ID, Group: group, Region: m.Region})
+			rows = append(rows, []string{m.ID, group, m.Region, m.State})
+		}
+	}
+
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+
+	fmt.Fprintln(io.ErrOut, io.ColorScheme().Yellow("These machines run process groups the config no longer has:"))
+	if err := render.Table(io.ErrOut, "", rows, "ID", "Group", "Region", "State"); err != nil {
+		return nil, err
+	}
+
+	var action string
+	switch {
+	case prune:
+		action = orphanDestroy
+	case keep:
+		action = orphanKeep
+	default:
+		if action, err = promptOrphanAction(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, orphan := range orphans {
+		switch action {
+		case orphanStop:
+			err = flapsClient.Stop(ctx, api.StopMachineInput{ID: orphan.ID, Filters: &api.Filters{}})
+			if err == nil {
+				err = flapsClient.SetMetadata(ctx, orphan.ID, mach.OrphanedGroupMetadataKey, orphan.Group)
+			}
+		case orphanDestroy:
+			err = flapsClient.Destroy(ctx, api.RemoveMachineInput{AppID: app.Name, ID: orphan.ID, Kill: true})
+		case orphanKeep:
+			err = flapsClient.SetMetadata(ctx, orphan.ID, mach.OrphanedGroupMetadataKey, orphan.Group)
+		}
+		if err != nil {
+			return orphans[:i], fmt.Errorf("failed handling machine %s of removed process group %s: %w", orphan.ID, orphan.Group, err)
+		}
+
+		orphans[i].Action = action
+		fmt.Fprintf(io.Out, "Machine %s of removed process group %s %s\n", orphan.ID, orphan.Group, action)
+	}
+
+	return orphans, nil
+}
+
+// promptOrphanAction asks what to do with orphaned machines, keeping them
+// when it can't ask.
+func promptOrphanAction(ctx context.Context) (string, error) {
+	io := iostreams.FromContext(ctx)
+
+	actions := []string{orphanStop, orphanDestroy, orphanKeep}
+	options := []string{"Stop them", "Destroy them", "Keep them running"}
+
+	var selected int
+	switch err := prompt.Select(ctx, &selected, "What should be done with them?", options[0], options...); {
+	case prompt.IsNonInteractive(err):
+		fmt.Fprintln(io.ErrOut, "Keeping them running; pass --prune-orphaned-groups or --keep-orphaned-groups to decide when not running interactively")
+
+		return orphanKeep, nil
+	case err != nil:
+		return "", err
+	}
+
+	return actions[selected], nil
+}
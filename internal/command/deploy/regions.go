@@ -0,0 +1,163 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// regionScope limits a rollout to the machines of some regions, as given
+// with --only-regions or --exclude-regions.
+type regionScope struct {
+	regions map[string]bool
+	exclude bool
+
+	// skipped collects the machines the rollout left out.
+	skipped []*api.Machine
+}
+
+// newRegionScope returns the scope the region flags call for, or nil if the
+// rollout covers every region.
+func newRegionScope(ctx context.Context) (*regionScope, error) {
+	only := flag.GetStringSlice(ctx, "only-regions")
+	exclude := flag.GetStringSlice(ctx, "exclude-regions")
+
+	switch {
+	case len(only) > 0 && len(exclude) > 0:
+		return nil, flyerr.WithCode(flyerr.CodeValidation, errors.New("--only-regions and --exclude-regions are mutually exclusive"))
+	case len(only) > 0:
+		return &regionScope{regions: regionSet(only)}, nil
+	case len(exclude) > 0:
+		return &regionScope{regions: regionSet(exclude), exclude: true}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func regionSet(regions []string) map[string]bool {
+	set := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		set[strings.TrimSpace(region)] = true
+	}
+
+	return set
+}
+
+// filter returns those of machines the rollout updates, recording the others
+// as skipped.
+func (s *regionScope) filter(machines []*api.Machine) []*api.Machine {
+	if s == nil {
+		return machines
+	}
+
+	var included []*api.Machine
+	for _, m := range machines {
+		if !s.covers(m) {
+			s.skipped = append(s.skipped, m)
+			continue
+		}
+		included = append(included, m)
+	}
+
+	return included
+}
+
+// within returns those of machines the rollout covers, without recording the
+// others as skipped.
+func (s *regionScope) within(machines []*api.Machine) []*api.Machine {
+	if s == nil {
+		return machines
+	}
+
+	var included []*api.Machine
+	for _, m := range machines {
+		if s.covers(m) {
+			included = append(included, m)
+		}
+	}
+
+	return included
+}
+
+func (s *regionScope) covers(m *api.Machine) bool {
+	return s.coversRegion(m.Region)
+}
+
+// coversRegion reports whether the rollout covers region, which it does for
+// every region without a scope.
+func (s *regionScope) coversRegion(region string) bool {
+	return s == nil || s.regions[region] != s.exclude
+}
+
+// printSkipped lists the machines the rollout skipped.
+func (s *regionScope) printSkipped(w io.Writer) {
+	if s == nil || len(s.skipped) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Skipping %d machines outside of the rollout's regions:\n", len(s.skipped))
+	for _, m := range s.skipped {
+		fmt.Fprintf(w, "  %s (%s)\n", m.ID, m.Region)
+	}
+}
+
+// warnUnmatched warns of the regions of the scope none of machines run in.
+func (s *regionScope) warnUnmatched(w io.Writer, colorize *iostreams.ColorScheme, machines []*api.Machine) {
+	if s == nil {
+		return
+	}
+
+	present := map[string]bool{}
+	for _, m := range machines {
+		present[m.Region] = true
+	}
+
+	var unmatched []string
+	for region := range s.regions {
+		if !present[region] {
+			unmatched = append(unmatched, region)
+		}
+	}
+	sort.Strings(unmatched)
+
+	for _, region := range unmatched {
+		fmt.Fprintln(w, colorize.Yellow(fmt.Sprintf("WARNING: no machines run in region %s", region)))
+	}
+}
+
+// laggingRegions returns the regions of the machines the rollout skipped,
+// leaving out those already on release version or a later one, as recorded
+// in their metadata. A version of 0 leaves none out.
+func (s *regionScope) laggingRegions(version int) []string {
+	if s == nil {
+		return nil
+	}
+
+	set := map[string]bool{}
+	for _, m := range s.skipped {
+		if version > 0 && m.Config != nil {
+			if v, err := strconv.Atoi(m.Config.Metadata[mach.ReleaseVersionMetadataKey]); err == nil && v >= version {
+				continue
+			}
+		}
+		set[m.Region] = true
+	}
+
+	regions := make([]string, 0, len(set))
+	for region := range set {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	return regions
+}
@@ -0,0 +1,66 @@
+package deploy
+
+import (
+	"context"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// rolloutProgress records the progress of a rollout in the metadata of its
+// release, so that `fly status --deployment` may follow it from elsewhere. A
+// nil rolloutProgress records nothing.
+type rolloutProgress struct {
+	release *api.Release
+	rollout *api.ReleaseRollout
+}
+
+// newRolloutProgress returns the progress of rolling image out to machines,
+// all of them pending, or nil if there's no release to record it in.
+func newRolloutProgress(ctx context.Context, release *api.Release, image string, machines []*api.Machine) *rolloutProgress {
+	if release == nil {
+		return nil
+	}
+
+	rollout := &api.ReleaseRollout{
+		Image:    image,
+		Machines: make([]api.RolloutMachine, 0, len(machines)),
+	}
+	for _, m := range machines {
+		rollout.Machines = append(rollout.Machines, api.RolloutMachine{
+			ID:     m.ID,
+			Region: m.Region,
+			State:  api.RolloutPending,
+		})
+	}
+
+	p := &rolloutProgress{
+		release: release,
+		rollout: rollout,
+	}
+	p.record(ctx)
+
+	return p
+}
+
+// set records that the machine identified by id is in state.
+func (p *rolloutProgress) set(ctx context.Context, id, state string) {
+	if p == nil {
+		return
+	}
+
+	for i := range p.rollout.Machines {
+		if p.rollout.Machines[i].ID == id {
+			p.rollout.Machines[i].State = state
+		}
+	}
+	p.record(ctx)
+}
+
+func (p *rolloutProgress) record(ctx context.Context) {
+	p.rollout.UpdatedAt = time.Now().UTC()
+
+	updateReleaseMetadata(ctx, p.release, func(m *api.ReleaseMetadata) {
+		m.Rollout = p.rollout
+	})
+}
@@ -0,0 +1,111 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/superfly/flyctl/internal/build/imgsrc"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/logger"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// imageVerification is what deploy --json prints of the prebuilt image it's
+// about to deploy.
+type imageVerification struct {
+	Image         string                `json:"image"`
+	Digest        string                `json:"digest"`
+	Verifications []imgsrc.Verification `json:"verifications"`
+}
+
+// imageVerifiers returns the verifiers the flags ask prebuilt images to pass
+// before they're deployed.
+func imageVerifiers(ctx context.Context) ([]imgsrc.ImageVerifier, error) {
+	var (
+		verify = flag.GetBool(ctx, "verify-signature")
+		key    = flag.GetString(ctx, "cosign-key")
+	)
+
+	switch {
+	case verify && key == "":
+		return nil, flyerr.WithCode(flyerr.CodeValidation, errors.New("--verify-signature requires --cosign-key"))
+	case !verify && key != "":
+		return nil, flyerr.WithCode(flyerr.CodeValidation, errors.New("--cosign-key is only used along with --verify-signature"))
+	case !verify:
+		return nil, nil
+	}
+
+	cosign, err := imgsrc.NewCosignVerifier(ctx, key)
+	if err != nil {
+		return nil, flyerr.WithCode(flyerr.CodeValidation, err)
+	}
+
+	return []imgsrc.ImageVerifier{cosign}, nil
+}
+
+// verifyImage resolves and prints the digest of img, the prebuilt image ref
+// resolved to, and runs the verifiers against it, failing should any of them
+// fail. Images deployed by the ref verified are then pinned to their digest,
+// so that the image deployed is the one verified. Without verifiers, failing
+// to resolve the digest doesn't hold the deployment up.
+func verifyImage(ctx context.Context, ref string, img *imgsrc.DeploymentImage, verifiers []imgsrc.ImageVerifier) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		digest   = img.Digest
+	)
+
+	// images pushed from the local daemon are only known by their new tag
+	if digest == "" && imgsrc.IsFlyRegistryImage(img.Tag) {
+		digest, _ = imgsrc.FetchImageDigest(ctx, img.Tag)
+	}
+
+	image, err := imgsrc.NewRemoteImage(ctx, ref, digest)
+	if err != nil {
+		if len(verifiers) > 0 {
+			return fmt.Errorf("failed verifying image %s: %w", ref, err)
+		}
+		logger.FromContext(ctx).Warnf("failed resolving the digest of image %s: %v", ref, err)
+
+		return nil
+	}
+
+	verifications := imgsrc.VerifyImage(ctx, image, verifiers...)
+
+	if config.FromContext(ctx).JSONOutput {
+		if err := render.JSON(io.Out, imageVerification{Image: ref, Digest: image.Digest, Verifications: verifications}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(io.Out, "image digest: %s\n", image.Digest)
+		for _, v := range verifications {
+			if v.Verified {
+				fmt.Fprintf(io.Out, "%s %s verification passed\n", colorize.SuccessIcon(), v.Verifier)
+			} else {
+				fmt.Fprintf(io.Out, "%s %s verification failed: %s\n", colorize.FailureIcon(), v.Verifier, v.Error)
+			}
+		}
+	}
+
+	var failed []string
+	for _, v := range verifications {
+		if !v.Verified {
+			failed = append(failed, v.Verifier)
+		}
+	}
+	if len(failed) > 0 {
+		return flyerr.WithCode(flyerr.CodeValidation,
+			fmt.Errorf("refusing to deploy image %s as it failed %s verification", ref, strings.Join(failed, ", ")))
+	}
+
+	if len(verifiers) > 0 && img.Tag == ref {
+		img.Tag = image.PinnedRef()
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package image
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -175,14 +176,17 @@ func showMachineImage(ctx context.Context, app *api.AppCompact) error {
 			},
 		}
 
-		return render.VerticalTable(io.Out, "Image Details", obj,
+		if err := render.VerticalTable(io.Out, "Image Details", obj,
 			"Registry",
 			"Repository",
 			"Tag",
 			"Version",
 			"Digest",
-		)
+		); err != nil {
+			return err
+		}
 
+		return renderLabels(ctx, "Image Labels", []*api.Machine{machine})
 	}
 	// get machines
 	machines, err := flaps.List(ctx, "")
@@ -259,7 +263,7 @@ func showMachineImage(ctx context.Context, app *api.AppCompact) error {
 		})
 	}
 
-	return render.Table(
+	if err := render.Table(
 		io.Out,
 		"Image Details",
 		rows,
@@ -269,5 +273,32 @@ func showMachineImage(ctx context.Context, app *api.AppCompact) error {
 		"Tag",
 		"Version",
 		"Digest",
-	)
+	); err != nil {
+		return err
+	}
+
+	return renderLabels(ctx, "Image Labels", machines)
+}
+
+// renderLabels renders the labels of the images of machines, if there are
+// any.
+func renderLabels(ctx context.Context, title string, machines []*api.Machine) error {
+	rows := [][]string{}
+	for _, machine := range machines {
+		keys := make([]string, 0, len(machine.ImageRef.Labels))
+		for key := range machine.ImageRef.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			rows = append(rows, []string{machine.ID, key, machine.ImageRef.Labels[key]})
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return render.Table(iostreams.FromContext(ctx).Out, title, rows, "Machine ID", "Key", "Value")
 }
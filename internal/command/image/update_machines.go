@@ -109,7 +109,7 @@ func updatePostgresOnMachines(ctx context.Context, app *api.AppCompact) (err err
 			continue
 		}
 
-		role := machineRole(machine)
+		role := flypg.MachineRole(machine)
 
 		machineConf, err := mach.CloneConfig(*machine.Config)
 		if err != nil {
@@ -214,22 +214,6 @@ func updatePostgresOnMachines(ctx context.Context, app *api.AppCompact) (err err
 	return nil
 }
 
-func machineRole(machine *api.Machine) (role string) {
-	role = "unknown"
-
-	for _, check := range machine.Checks {
-		if check.Name == "role" {
-			if check.Status == "passing" {
-				role = check.Output
-			} else {
-				role = "error"
-			}
-			break
-		}
-	}
-	return role
-}
-
 func resolveImage(ctx context.Context, machine api.Machine) (string, error) {
 	var (
 		client = client.FromContext(ctx).API()
@@ -2,6 +2,7 @@ package ips
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/spf13/cobra"
 	"github.com/superfly/flyctl/api"
@@ -10,6 +11,7 @@ import (
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/command/orgs"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/iostreams"
 )
 
 func newAllocatev4() *cobra.Command {
@@ -112,5 +114,10 @@ func runAllocateIPAddress(ctx context.Context, addrType string, org *api.Organiz
 
 	ipAddresses := []api.IPAddress{*ipAddress}
 	renderListTable(ctx, ipAddresses)
+
+	if addrType == "private_v6" {
+		fmt.Fprintf(iostreams.FromContext(ctx).Out, "\nThe app is reachable on %s, or %s.flycast through internal DNS, from within the organization's private network\n", ipAddress.Address, appName)
+	}
+
 	return nil
 }
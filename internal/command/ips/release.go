@@ -4,12 +4,19 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/logger"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
 )
 
 func newRelease() *cobra.Command {
@@ -26,6 +33,7 @@ func newRelease() *cobra.Command {
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.Yes(),
 	)
 
 	cmd.Args = cobra.ExactArgs(1)
@@ -42,6 +50,12 @@ func runReleaseIPAddress(ctx context.Context) error {
 		return fmt.Errorf("Invalid IP address: '%s'", address)
 	}
 
+	if isPrivate("", address) {
+		if err := confirmPrivateRelease(ctx, appName, address); err != nil {
+			return err
+		}
+	}
+
 	if err := client.ReleaseIPAddress(ctx, appName, address); err != nil {
 		return err
 	}
@@ -50,3 +64,93 @@ func runReleaseIPAddress(ctx context.Context) error {
 
 	return nil
 }
+
+// confirmPrivateRelease asks for confirmation before releasing a private
+// address the app's configuration still refers to.
+func confirmPrivateRelease(ctx context.Context, appName, address string) error {
+	refs := privateIPReferences(ctx, appName, address)
+	if len(refs) == 0 || flag.GetYes(ctx) {
+		return nil
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.ErrOut, "%s is still referenced by:\n", address)
+	for _, ref := range refs {
+		fmt.Fprintf(io.ErrOut, "  %s\n", ref)
+	}
+
+	switch confirmed, err := prompt.Confirmf(ctx, "Release %s anyway?", address); {
+	case err == nil:
+		if !confirmed {
+			return fmt.Errorf("%s was not released", address)
+		}
+
+		return nil
+	case prompt.IsNonInteractive(err):
+		return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+	default:
+		return err
+	}
+}
+
+// privateIPReferences scans the environment of the app's machines and of its
+// local config for address. The scan is best-effort; failures to retrieve
+// the machines are logged and otherwise ignored.
+func privateIPReferences(ctx context.Context, appName, address string) (refs []string) {
+	if cfg := app.ConfigFromContext(ctx); cfg != nil {
+		refs = append(refs, envReferences(cfg.GetEnvVariables(), address, "fly.toml")...)
+		refs = append(refs, envReferences(cfg.Env, address, "fly.toml")...)
+	}
+
+	logger := logger.FromContext(ctx)
+
+	appCompact, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		logger.Debugf("failed retrieving app %s: %v", appName, err)
+
+		return
+	}
+
+	if appCompact.PlatformVersion != "machines" {
+		return
+	}
+
+	flapsClient, err := flaps.New(ctx, appCompact)
+	if err != nil {
+		logger.Debugf("failed creating flaps client: %v", err)
+
+		return
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		logger.Debugf("failed listing machines: %v", err)
+
+		return
+	}
+
+	for _, m := range machines {
+		refs = append(refs, machineReferences(m, address)...)
+	}
+
+	return
+}
+
+func machineReferences(m *api.Machine, address string) []string {
+	if m.Config == nil {
+		return nil
+	}
+
+	return envReferences(m.Config.Env, address, "machine "+m.ID)
+}
+
+func envReferences(env map[string]string, address, source string) (refs []string) {
+	for name, value := range env {
+		if strings.Contains(value, address) {
+			refs = append(refs, fmt.Sprintf("%s of %s", name, source))
+		}
+	}
+	sort.Strings(refs)
+
+	return
+}
@@ -14,23 +14,30 @@ import (
 func renderListTable(ctx context.Context, ipAddresses []api.IPAddress) {
 	rows := make([][]string, 0, len(ipAddresses))
 
-	var ipType string
 	for _, ipAddr := range ipAddresses {
-		if strings.HasPrefix(ipAddr.Address, "fdaa") {
-			ipType = "private"
-		} else {
-			ipType = "public"
-		}
-
 		if ipAddr.Type == "shared_v4" {
 			rows = append(rows, []string{"v4", ipAddr.Address, "public (shared)", ipAddr.Region, ""})
 		} else {
-			rows = append(rows, []string{ipAddr.Type, ipAddr.Address, ipType, ipAddr.Region, presenters.FormatRelativeTime(ipAddr.CreatedAt)})
+			rows = append(rows, []string{ipAddr.Type, ipAddr.Address, ipScope(ipAddr), ipAddr.Region, presenters.FormatRelativeTime(ipAddr.CreatedAt)})
 		}
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	render.Table(out, "", rows, "Version", "IP", "Type", "Region", "Created At")
+	render.Table(out, "", rows, "Version", "IP", "Scope", "Region", "Created At")
+}
+
+// ipScope returns private for flycast addresses, which are only reachable
+// from within the organization's private network, and public otherwise.
+func ipScope(ipAddr api.IPAddress) string {
+	if isPrivate(ipAddr.Type, ipAddr.Address) {
+		return "private"
+	}
+
+	return "public"
+}
+
+func isPrivate(addrType, address string) bool {
+	return addrType == "private_v6" || strings.HasPrefix(address, "fdaa")
 }
 
 func renderPrivateTable(ctx context.Context, allocations []*api.AllocationStatus, backupRegions []api.Region) {
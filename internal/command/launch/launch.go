@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -75,6 +76,10 @@ func New() (cmd *cobra.Command) {
 			Description: "Use the configuration file if present without prompting",
 			Default:     false,
 		},
+		flag.Bool{
+			Name:        "resume",
+			Description: "Resume placing the volumes and machines of a launch that failed part way through",
+		},
 		flag.Bool{
 			Name:        "dockerignore-from-gitignore",
 			Description: "If a .dockerignore does not exist, create one from .gitignore files",
@@ -95,6 +100,10 @@ func run(ctx context.Context) (err error) {
 		workingDir = absDir
 	}
 
+	if flag.GetBool(ctx, "resume") {
+		return resumeLaunch(ctx, workingDir)
+	}
+
 	appConfig := app.NewConfig()
 
 	var importedConfig bool
@@ -387,29 +396,24 @@ func run(ctx context.Context) (err error) {
 		}
 	}
 
-	// If volumes are requested by the launch scanner, create them
-	if srcInfo != nil && len(srcInfo.Volumes) > 0 {
-		for _, vol := range srcInfo.Volumes {
-
-			appID, err := client.GetAppID(ctx, createdApp.Name)
-			if err != nil {
-				return err
-			}
-
-			volume, err := client.CreateVolume(ctx, api.CreateVolumeInput{
-				AppID:     appID,
-				Name:      vol.Source,
-				Region:    region.Code,
-				SizeGb:    1,
-				Encrypted: true,
-			})
+	// Create the volumes the config mounts, along with machines where
+	// applicable, in every region they're placed in
+	mounts, err := mountsFromConfig(appConfig)
+	if err != nil {
+		return err
+	}
+	if len(mounts) > 0 {
+		var image string
+		if appConfig.Build != nil {
+			image = appConfig.Build.Image
+		}
 
-			if err != nil {
-				return err
-			} else {
-				fmt.Fprintf(io.Out, "Created a %dGB volume %s in the %s region\n", volume.SizeGb, volume.ID, region.Code)
-			}
+		placement := newLaunchState(ctx, createdApp.Name, org.Slug, region.Code, image, mounts)
+		if err := placeAndRender(ctx, placement); err != nil {
+			// Keep the config around so that the launch may be resumed from it
+			_ = appConfig.WriteToDisk(ctx, filepath.Join(workingDir, "fly.toml"))
 
+			return err
 		}
 	}
 
@@ -762,3 +766,50 @@ func LaunchRedis(ctx context.Context, app *api.App, org *api.Organization, regio
 		redis.AttachDatabase(ctx, db, app)
 	}
 }
+
+// resumeLaunch picks up the placement phase of an earlier launch of the app
+// in workingDir from its recorded state.
+func resumeLaunch(ctx context.Context, workingDir string) error {
+	io := iostreams.FromContext(ctx)
+
+	appName := flag.GetString(ctx, "name")
+	if appName == "" {
+		if cfg, err := app.LoadConfig(ctx, filepath.Join(workingDir, "fly.toml"), "nomad"); err == nil {
+			appName = cfg.AppName
+		}
+	}
+	if appName == "" {
+		return errors.New("the app to resume launching must be specified with --name")
+	}
+
+	placement, err := loadLaunchState(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Resuming launch of %s\n", appName)
+
+	if err := placeAndRender(ctx, placement); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.Out, "Your app is ready! Deploy with `flyctl deploy`")
+
+	return nil
+}
+
+// placeAndRender runs placement, summarizes its outcome and forgets about
+// the recorded state once every region has succeeded.
+func placeAndRender(ctx context.Context, placement *launchState) error {
+	placeErr := place(ctx, placement)
+
+	if err := renderPlacement(ctx, placement); err != nil {
+		return err
+	}
+
+	if placeErr != nil {
+		return placeErr
+	}
+
+	return placement.remove()
+}
@@ -0,0 +1,369 @@
+package launch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/internal/state"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// mount is a volume mount declared in fly.toml. Regions lists the regions the
+// volume should be placed in; it defaults to the primary region.
+type mount struct {
+	Source      string   `json:"source"`
+	Destination string   `json:"destination"`
+	Regions     []string `json:"regions,omitempty"`
+}
+
+// mountsFromConfig returns the mounts cfg declares, whether as a single
+// [mounts] table or as an array of [[mounts]] tables.
+func mountsFromConfig(cfg *app.Config) ([]mount, error) {
+	raw, ok := cfg.Definition["mounts"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading mounts: %w", err)
+	}
+
+	var mounts []mount
+	if err := json.Unmarshal(data, &mounts); err == nil {
+		return mounts, nil
+	}
+
+	var single mount
+	if err := json.Unmarshal(data, &single); err != nil {
+		return nil, fmt.Errorf("failed reading mounts: %w", err)
+	}
+
+	return []mount{single}, nil
+}
+
+func (m mount) placedIn(region, primaryRegion string) bool {
+	if len(m.Regions) == 0 {
+		return region == primaryRegion
+	}
+
+	for _, r := range m.Regions {
+		if r == region {
+			return true
+		}
+	}
+
+	return false
+}
+
+// launchState records the progress of the placement phase of a launch so that
+// `fly launch --resume` may pick up where a failed launch left off.
+type launchState struct {
+	AppName       string                  `json:"app_name"`
+	OrgSlug       string                  `json:"org_slug"`
+	PrimaryRegion string                  `json:"primary_region"`
+	Image         string                  `json:"image,omitempty"`
+	Mounts        []mount                 `json:"mounts"`
+	Regions       map[string]*regionState `json:"regions"`
+
+	mu   sync.Mutex
+	path string
+}
+
+type regionState struct {
+	// Volumes maps mount sources to the IDs of the volumes created for them.
+	Volumes   map[string]string `json:"volumes,omitempty"`
+	MachineID string            `json:"machine_id,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+func launchStatePath(ctx context.Context, appName string) string {
+	return filepath.Join(state.ConfigDirectory(ctx), "launch", appName+".json")
+}
+
+func newLaunchState(ctx context.Context, appName, orgSlug, primaryRegion, image string, mounts []mount) *launchState {
+	s := &launchState{
+		AppName:       appName,
+		OrgSlug:       orgSlug,
+		PrimaryRegion: primaryRegion,
+		Image:         image,
+		Mounts:        mounts,
+		Regions:       map[string]*regionState{},
+		path:          launchStatePath(ctx, appName),
+	}
+
+	for _, region := range s.regions() {
+		s.Regions[region] = &regionState{Volumes: map[string]string{}}
+	}
+
+	return s
+}
+
+func loadLaunchState(ctx context.Context, appName string) (*launchState, error) {
+	path := launchStatePath(ctx, appName)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no interrupted launch recorded for app %s", appName)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed reading launch state: %w", err)
+	}
+
+	s := &launchState{path: path}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed parsing launch state %s: %w", path, err)
+	}
+
+	for _, region := range s.regions() {
+		if s.Regions[region] == nil {
+			s.Regions[region] = &regionState{}
+		}
+		if s.Regions[region].Volumes == nil {
+			s.Regions[region].Volumes = map[string]string{}
+		}
+	}
+
+	return s, nil
+}
+
+// regions returns the regions placement happens in: the primary region
+// followed by every other region a mount names.
+func (s *launchState) regions() []string {
+	seen := map[string]bool{s.PrimaryRegion: true}
+	var others []string
+
+	for _, m := range s.Mounts {
+		for _, r := range m.Regions {
+			if !seen[r] {
+				seen[r] = true
+				others = append(others, r)
+			}
+		}
+	}
+	sort.Strings(others)
+
+	return append([]string{s.PrimaryRegion}, others...)
+}
+
+func (s *launchState) update(fn func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+func (s *launchState) remove() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// place creates the volumes, and for machines apps with a known image the
+// machines, of every region concurrently. Progress is recorded as it's made,
+// so calling place again with the same state only creates what's missing.
+func place(ctx context.Context, s *launchState) error {
+	apiClient := client.FromContext(ctx).API()
+
+	app, err := apiClient.GetAppCompact(ctx, s.AppName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", s.AppName, err)
+	}
+
+	volumes, err := apiClient.GetVolumes(ctx, s.AppName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volumes of %s: %w", s.AppName, err)
+	}
+
+	var flapsClient *flaps.Client
+	if app.PlatformVersion == "machines" && s.Image != "" {
+		if flapsClient, err = flaps.New(ctx, app); err != nil {
+			return err
+		}
+	}
+
+	// Adopt volumes that were created without being recorded, e.g. when the
+	// previous launch was interrupted mid-request.
+	s.adoptVolumes(volumes)
+
+	var wg sync.WaitGroup
+	for _, region := range s.regions() {
+		region := region
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := placeRegion(ctx, s, app, flapsClient, region)
+
+			var msg string
+			if err != nil {
+				msg = err.Error()
+			}
+			_ = s.update(func() { s.Regions[region].Error = msg })
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, region := range s.regions() {
+		if s.Regions[region].Error != "" {
+			failed = append(failed, region)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("placement failed in %s. Progress has been saved to %s; rerun with `fly launch --resume --name %s` once the issue is resolved",
+			strings.Join(failed, ", "), s.path, s.AppName)
+	}
+
+	return nil
+}
+
+func (s *launchState) adoptVolumes(volumes []api.Volume) {
+	claimed := map[string]bool{}
+	for _, rs := range s.Regions {
+		for _, id := range rs.Volumes {
+			claimed[id] = true
+		}
+	}
+
+	for _, region := range s.regions() {
+		rs := s.Regions[region]
+
+		for _, m := range s.Mounts {
+			if !m.placedIn(region, s.PrimaryRegion) || rs.Volumes[m.Source] != "" {
+				continue
+			}
+
+			for _, v := range volumes {
+				if v.Name == m.Source && v.Region == region && !claimed[v.ID] {
+					rs.Volumes[m.Source] = v.ID
+					claimed[v.ID] = true
+
+					break
+				}
+			}
+		}
+	}
+}
+
+func placeRegion(ctx context.Context, s *launchState, app *api.AppCompact, flapsClient *flaps.Client, region string) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		rs        = s.Regions[region]
+	)
+
+	var mounts []api.MachineMount
+
+	for _, m := range s.Mounts {
+		if !m.placedIn(region, s.PrimaryRegion) {
+			continue
+		}
+
+		volumeID := rs.Volumes[m.Source]
+		if volumeID == "" {
+			volume, err := apiClient.CreateVolume(ctx, api.CreateVolumeInput{
+				AppID:     app.ID,
+				Name:      m.Source,
+				Region:    region,
+				SizeGb:    1,
+				Encrypted: true,
+			})
+			if err != nil {
+				return fmt.Errorf("failed creating volume %s: %w", m.Source, err)
+			}
+			fmt.Fprintf(io.Out, "Created a %dGB volume %s in the %s region\n", volume.SizeGb, volume.ID, region)
+
+			volumeID = volume.ID
+			if err := s.update(func() { rs.Volumes[m.Source] = volumeID }); err != nil {
+				return fmt.Errorf("failed recording launch state: %w", err)
+			}
+		}
+
+		mounts = append(mounts, api.MachineMount{Volume: volumeID, Path: m.Destination})
+	}
+
+	if flapsClient == nil || rs.MachineID != "" {
+		return nil
+	}
+
+	// Machines support a single mount; deploy takes care of the remaining
+	// configuration.
+	config := &api.MachineConfig{
+		Image:    s.Image,
+		Env:      map[string]string{"PRIMARY_REGION": s.PrimaryRegion},
+		Metadata: map[string]string{"process_group": "app"},
+	}
+	if len(mounts) > 0 {
+		config.Mounts = mounts[:1]
+	}
+
+	machine, err := flapsClient.Launch(ctx, api.LaunchMachineInput{
+		AppID:   app.Name,
+		OrgSlug: app.Organization.ID,
+		Region:  region,
+		Config:  config,
+	})
+	if err != nil {
+		return fmt.Errorf("failed launching machine: %w", err)
+	}
+	fmt.Fprintf(io.Out, "Launched machine %s in the %s region\n", machine.ID, region)
+
+	if err := s.update(func() { rs.MachineID = machine.ID }); err != nil {
+		return fmt.Errorf("failed recording launch state: %w", err)
+	}
+
+	return nil
+}
+
+// renderPlacement prints the outcome of placement in each region.
+func renderPlacement(ctx context.Context, s *launchState) error {
+	io := iostreams.FromContext(ctx)
+
+	rows := make([][]string, 0, len(s.Regions))
+	for _, region := range s.regions() {
+		rs := s.Regions[region]
+
+		var volumes []string
+		for _, m := range s.Mounts {
+			if id := rs.Volumes[m.Source]; id != "" {
+				volumes = append(volumes, fmt.Sprintf("%s (%s)", m.Source, id))
+			}
+		}
+
+		status := "ok"
+		if rs.Error != "" {
+			status = "failed: " + rs.Error
+		}
+
+		rows = append(rows, []string{region, strings.Join(volumes, ", "), rs.MachineID, status})
+	}
+
+	return render.Table(io.Out, "Placement", rows, "Region", "Volumes", "Machine", "Status")
+}
@@ -0,0 +1,129 @@
+package launch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/state"
+)
+
+func TestMountsFromConfig(t *testing.T) {
+	cases := []struct {
+		name       string
+		definition map[string]interface{}
+		want       []mount
+	}{
+		{
+			name:       "no mounts",
+			definition: map[string]interface{}{},
+		},
+		{
+			name: "single table",
+			definition: map[string]interface{}{
+				"mounts": map[string]interface{}{"source": "data", "destination": "/data"},
+			},
+			want: []mount{{Source: "data", Destination: "/data"}},
+		},
+		{
+			name: "array of tables",
+			definition: map[string]interface{}{
+				"mounts": []map[string]interface{}{
+					{"source": "data", "destination": "/data", "regions": []string{"ams", "ord"}},
+					{"source": "logs", "destination": "/logs"},
+				},
+			},
+			want: []mount{
+				{Source: "data", Destination: "/data", Regions: []string{"ams", "ord"}},
+				{Source: "logs", Destination: "/logs"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mounts, err := mountsFromConfig(&app.Config{Definition: tc.definition})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, mounts)
+		})
+	}
+}
+
+func TestMountPlacedIn(t *testing.T) {
+	cases := []struct {
+		name   string
+		mount  mount
+		region string
+		want   bool
+	}{
+		{name: "primary region by default", mount: mount{Source: "data"}, region: "iad", want: true},
+		{name: "only the primary region by default", mount: mount{Source: "data"}, region: "ams"},
+		{name: "listed region", mount: mount{Source: "data", Regions: []string{"ams", "ord"}}, region: "ord", want: true},
+		{name: "primary region left out", mount: mount{Source: "data", Regions: []string{"ams"}}, region: "iad"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.mount.placedIn(tc.region, "iad"))
+		})
+	}
+}
+
+func TestLaunchStateRegions(t *testing.T) {
+	s := &launchState{
+		PrimaryRegion: "iad",
+		Mounts: []mount{
+			{Source: "data", Regions: []string{"ord", "ams"}},
+			{Source: "logs", Regions: []string{"iad", "ams"}},
+			{Source: "cache"},
+		},
+	}
+
+	assert.Equal(t, []string{"iad", "ams", "ord"}, s.regions())
+}
+
+func TestLaunchStateAdoptVolumes(t *testing.T) {
+	s := &launchState{
+		PrimaryRegion: "iad",
+		Mounts:        []mount{{Source: "data", Regions: []string{"iad", "ams"}}},
+		Regions: map[string]*regionState{
+			"iad": {Volumes: map[string]string{"data": "vol_recorded"}},
+			"ams": {Volumes: map[string]string{}},
+		},
+	}
+
+	s.adoptVolumes([]api.Volume{
+		{ID: "vol_other", Name: "other", Region: "ams"},
+		{ID: "vol_recorded", Name: "data", Region: "ams"},
+		{ID: "vol_orphan", Name: "data", Region: "ams"},
+		{ID: "vol_iad", Name: "data", Region: "iad"},
+	})
+
+	// volumes already recorded are neither replaced nor adopted elsewhere
+	assert.Equal(t, "vol_recorded", s.Regions["iad"].Volumes["data"])
+	assert.Equal(t, "vol_orphan", s.Regions["ams"].Volumes["data"])
+}
+
+func TestLaunchStateResume(t *testing.T) {
+	ctx := state.WithConfigDirectory(context.Background(), t.TempDir())
+
+	_, err := loadLaunchState(ctx, "app")
+	assert.Error(t, err, "no launch recorded")
+
+	s := newLaunchState(ctx, "app", "org", "iad", "image", []mount{{Source: "data", Regions: []string{"ams"}}})
+	require.NoError(t, s.update(func() { s.Regions["ams"].Volumes["data"] = "vol_1" }))
+
+	loaded, err := loadLaunchState(ctx, "app")
+	require.NoError(t, err)
+	assert.Equal(t, s.Mounts, loaded.Mounts)
+	assert.Equal(t, "vol_1", loaded.Regions["ams"].Volumes["data"])
+	assert.NotNil(t, loaded.Regions["iad"].Volumes, "regions without volumes are usable")
+
+	require.NoError(t, loaded.remove())
+	_, err = loadLaunchState(ctx, "app")
+	assert.Error(t, err)
+}
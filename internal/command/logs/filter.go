@@ -0,0 +1,97 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/superfly/flyctl/logs"
+)
+
+// entryFilter selects the entries whose messages --search and --regex match,
+// along with up to --context entries of the same instance around them.
+type entryFilter struct {
+	search  string
+	regex   *regexp.Regexp
+	context int
+
+	// before holds the latest unmatched entries of each instance, up to
+	// context of them; after counts the entries of each instance yet to be
+	// shown following a match.
+	before map[string][]logs.LogEntry
+	after  map[string]int
+}
+
+// newEntryFilter returns the filter the given flag values call for, or nil
+// when no filter was asked for.
+func newEntryFilter(search, pattern string, context int) (*entryFilter, error) {
+	if context < 0 {
+		return nil, fmt.Errorf("invalid --context %d: must not be negative", context)
+	}
+
+	if search == "" && pattern == "" {
+		if context > 0 {
+			return nil, fmt.Errorf("--context requires --search or --regex")
+		}
+
+		return nil, nil
+	}
+
+	f := &entryFilter{
+		search:  search,
+		context: context,
+		before:  map[string][]logs.LogEntry{},
+		after:   map[string]int{},
+	}
+
+	if pattern != "" {
+		var err error
+		if f.regex, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid --regex %q: %w", pattern, err)
+		}
+	}
+
+	return f, nil
+}
+
+func (f *entryFilter) matches(entry logs.LogEntry) bool {
+	if f.search != "" && !strings.Contains(entry.Message, f.search) {
+		return false
+	}
+
+	return f.regex == nil || f.regex.MatchString(entry.Message)
+}
+
+// apply returns the entries to show, in order, as of the arrival of entry. A
+// nil filter shows every entry.
+func (f *entryFilter) apply(entry logs.LogEntry) []logs.LogEntry {
+	if f == nil {
+		return []logs.LogEntry{entry}
+	}
+
+	instance := entry.Instance
+
+	if f.matches(entry) {
+		shown := append(f.before[instance], entry)
+		delete(f.before, instance)
+		f.after[instance] = f.context
+
+		return shown
+	}
+
+	if f.after[instance] > 0 {
+		f.after[instance]--
+
+		return []logs.LogEntry{entry}
+	}
+
+	if f.context > 0 {
+		before := append(f.before[instance], entry)
+		if len(before) > f.context {
+			before = before[len(before)-f.context:]
+		}
+		f.before[instance] = before
+	}
+
+	return nil
+}
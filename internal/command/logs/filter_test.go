@@ -0,0 +1,50 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/logs"
+)
+
+func applyAll(f *entryFilter, entries []logs.LogEntry) (shown []string) {
+	for _, entry := range entries {
+		for _, e := range f.apply(entry) {
+			shown = append(shown, e.Instance+":"+e.Message)
+		}
+	}
+
+	return
+}
+
+func TestEntryFilter(t *testing.T) {
+	entries := []logs.LogEntry{
+		{Instance: "a", Message: "a1"},
+		{Instance: "b", Message: "b1"},
+		{Instance: "a", Message: "a2"},
+		{Instance: "a", Message: "request req-42 failed"},
+		{Instance: "b", Message: "b2"},
+		{Instance: "a", Message: "a3"},
+		{Instance: "a", Message: "a4"},
+	}
+
+	f, err := newEntryFilter("req-42", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:request req-42 failed"}, applyAll(f, entries))
+
+	f, err = newEntryFilter("", `req-\d+ failed$`, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:a2", "a:request req-42 failed", "a:a3"}, applyAll(f, entries))
+
+	f, err = newEntryFilter("", "", 0)
+	require.NoError(t, err)
+	assert.Len(t, applyAll(f, entries), len(entries))
+
+	_, err = newEntryFilter("", "(", 0)
+	assert.Error(t, err)
+
+	_, err = newEntryFilter("", "", 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,187 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/superfly/flyctl/logs"
+
+	"github.com/superfly/flyctl/internal/render"
+)
+
+const templateFormatPrefix = "template="
+
+// formatter writes a log entry to w.
+type formatter func(w io.Writer, entry logs.LogEntry) error
+
+// templateEntry is what --format template= templates execute against.
+type templateEntry struct {
+	Timestamp string
+	Level     string
+	Region    string
+	Instance  string
+	Message   string
+	// Provider is the source of the entry, e.g. app or proxy
+	Provider string
+	// Fields holds the structured fields of the entry: those of its metadata
+	// and, for messages which are JSON objects, their top-level keys
+	Fields map[string]interface{}
+}
+
+func newTemplateEntry(entry logs.LogEntry) templateEntry {
+	return templateEntry{
+		Timestamp: entry.Timestamp,
+		Level:     entry.Level,
+		Region:    entry.Region,
+		Instance:  entry.Instance,
+		Message:   entry.Message,
+		Provider:  entry.Meta.Event.Provider,
+		Fields:    structuredFields(entry),
+	}
+}
+
+func structuredFields(entry logs.LogEntry) map[string]interface{} {
+	fields := map[string]interface{}{}
+
+	if method := entry.Meta.HTTP.Request.Method; method != "" {
+		fields["http.method"] = method
+	}
+	if status := entry.Meta.HTTP.Response.StatusCode; status != 0 {
+		fields["http.status"] = status
+	}
+	if url := entry.Meta.URL.Full; url != "" {
+		fields["url"] = url
+	}
+	if code := entry.Meta.Error.Code; code != 0 {
+		fields["error.code"] = code
+	}
+	if msg := entry.Meta.Error.Message; msg != "" {
+		fields["error.message"] = msg
+	}
+
+	if msg := strings.TrimSpace(entry.Message); strings.HasPrefix(msg, "{") {
+		var obj map[string]interface{}
+		if json.Unmarshal([]byte(msg), &obj) == nil {
+			for k, v := range obj {
+				fields[k] = v
+			}
+		}
+	}
+
+	return fields
+}
+
+// newFormatter returns the formatter for format, failing fast on invalid
+// templates. Each entry is written with a single call so that output piped
+// elsewhere isn't held back.
+func newFormatter(format string, jsonOutput bool) (formatter, error) {
+	switch {
+	case jsonOutput || format == "json":
+		return func(w io.Writer, entry logs.LogEntry) error {
+			return render.JSON(w, entry)
+		}, nil
+	case format == "" || format == "text":
+		return func(w io.Writer, entry logs.LogEntry) error {
+			return render.LogEntry(w, entry,
+				render.HideAllocID(),
+				render.RemoveNewlines(),
+				render.HideRegion(),
+			)
+		}, nil
+	case format == "logfmt":
+		return writeLogfmt, nil
+	case strings.HasPrefix(format, templateFormatPrefix):
+		tmpl, err := template.New("format").Parse(strings.TrimPrefix(format, templateFormatPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --format template: %w", err)
+		}
+
+		return func(w io.Writer, entry logs.LogEntry) error {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, newTemplateEntry(entry)); err != nil {
+				return err
+			}
+			if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+
+			_, err := w.Write(buf.Bytes())
+
+			return err
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported --format %q; must be text, json, logfmt or template=<go template>", format)
+	}
+}
+
+func writeLogfmt(w io.Writer, entry logs.LogEntry) error {
+	var buf bytes.Buffer
+
+	pair := func(k string, v interface{}) {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(v))
+	}
+
+	e := newTemplateEntry(entry)
+	pair("timestamp", e.Timestamp)
+	pair("level", e.Level)
+	pair("region", e.Region)
+	pair("instance", e.Instance)
+	if e.Provider != "" {
+		pair("provider", e.Provider)
+	}
+	pair("message", e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		pair(k, e.Fields[k])
+	}
+
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+func logfmtValue(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case int:
+		return strconv.Itoa(v)
+	case float64, bool, nil:
+		return fmt.Sprint(v)
+	default:
+		b, _ := json.Marshal(v)
+		s = string(b)
+	}
+
+	if s == "" {
+		return `""`
+	}
+
+	if strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || unicode.IsSpace(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
@@ -0,0 +1,49 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/logs"
+)
+
+func testEntry() logs.LogEntry {
+	entry := logs.LogEntry{
+		Level:     "info",
+		Instance:  "abc123",
+		Message:   `{"user":"jane","took_ms":12}`,
+		Region:    "fra",
+		Timestamp: "2022-11-02T10:00:00Z",
+	}
+	entry.Meta.Event.Provider = "app"
+
+	return entry
+}
+
+func TestLogfmt(t *testing.T) {
+	format, err := newFormatter("logfmt", false)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, format(&buf, testEntry()))
+
+	assert.Equal(t, `timestamp=2022-11-02T10:00:00Z level=info region=fra instance=abc123 provider=app message="{\"user\":\"jane\",\"took_ms\":12}" took_ms=12 user=jane`+"\n", buf.String())
+}
+
+func TestTemplate(t *testing.T) {
+	format, err := newFormatter(`template={{.Region}} {{.Message}} {{index .Fields "user"}}`, false)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, format(&buf, testEntry()))
+
+	assert.Equal(t, `fra {"user":"jane","took_ms":12} jane`+"\n", buf.String())
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	_, err := newFormatter("template={{.Region", false)
+	assert.ErrorContains(t, err, "invalid --format template")
+}
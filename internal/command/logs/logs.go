@@ -21,7 +21,6 @@ import (
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/logger"
-	"github.com/superfly/flyctl/internal/render"
 )
 
 func New() (cmd *cobra.Command) {
@@ -31,6 +30,23 @@ the Fly platform.
 
 Logs can be filtered to a specific instance using the --instance/-i flag or
 to all instances running in a specific region using the --region/-r flag.
+
+The --format flag selects the shape of each line: text (the default), json,
+logfmt, or template=<go template>, e.g.
+
+  fly logs --format 'template={{.Timestamp}} {{.Region}} {{.Message}}'
+
+Templates may refer to .Timestamp, .Level, .Region, .Instance, .Message and
+.Provider (the source of the entry, such as app or proxy), as well as to
+.Fields, which holds structured fields: http.method, http.status, url,
+error.code and error.message when present, along with the top-level keys of
+messages which are JSON objects. Access these via index, e.g.
+{{index .Fields "http.status"}}.
+
+The --search and --regex flags only show entries whose messages contain the
+given text or match the given regular expression, respectively. --context
+additionally shows that many entries of the same instance before and after
+each match.
 `
 		short = "View app logs"
 	)
@@ -51,14 +67,46 @@ to all instances running in a specific region using the --region/-r flag.
 			Shorthand:   "i",
 			Description: "Filter by instance ID",
 		},
+		flag.String{
+			Name:        "format",
+			Description: "Output format: text, json, logfmt or template=<go template>",
+			Default:     "text",
+		},
+		flag.String{
+			Name:        "search",
+			Description: "Only show entries whose messages contain this text",
+		},
+		flag.String{
+			Name:        "regex",
+			Description: "Only show entries whose messages match this regular expression",
+		},
+		flag.Int{
+			Name:        "context",
+			Description: "Show this many entries of the same instance around each match of --search or --regex",
+		},
 	)
 
+	cmd.AddCommand(newShip())
+
 	return
 }
 
 func run(ctx context.Context) error {
 	client := client.FromContext(ctx).API()
 
+	format, err := newFormatter(flag.GetString(ctx, "format"), config.FromContext(ctx).JSONOutput)
+	if err != nil {
+		return err
+	}
+
+	// neither log backend filters messages, so matching happens here
+	newFilter := func() (*entryFilter, error) {
+		return newEntryFilter(flag.GetString(ctx, "search"), flag.GetString(ctx, "regex"), flag.GetInt(ctx, "context"))
+	}
+	if _, err := newFilter(); err != nil {
+		return err
+	}
+
 	opts := &logs.LogOptions{
 		AppName:    app.NameFromContext(ctx),
 		RegionCode: config.FromContext(ctx).Region,
@@ -73,7 +121,7 @@ func run(ctx context.Context) error {
 	liveEntries := nats(ctx, eg, client, opts, cancelPolling)
 
 	eg.Go(func() error {
-		return printStreams(ctx, pollEntries, liveEntries)
+		return printStreams(ctx, format, newFilter, pollEntries, liveEntries)
 	})
 
 	return eg.Wait()
@@ -128,25 +176,32 @@ func nats(ctx context.Context, eg *errgroup.Group, client *api.Client, opts *log
 	return c
 }
 
-func printStreams(ctx context.Context, streams ...<-chan logs.LogEntry) error {
+// printStreams prints the entries of each of streams newFilter's filters
+// select. Each stream is filtered on its own, since they're printed
+// concurrently.
+func printStreams(ctx context.Context, format formatter, newFilter func() (*entryFilter, error), streams ...<-chan logs.LogEntry) error {
 	var eg *errgroup.Group
 	eg, ctx = errgroup.WithContext(ctx)
 
 	out := iostreams.FromContext(ctx).Out
-	json := config.FromContext(ctx).JSONOutput
 
 	for _, stream := range streams {
 		stream := stream
 
+		filter, err := newFilter()
+		if err != nil {
+			return err
+		}
+
 		eg.Go(func() error {
-			return printStream(ctx, out, stream, json)
+			return printStream(ctx, out, stream, format, filter)
 		})
 	}
 
 	return eg.Wait()
 }
 
-func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry, json bool) error {
+func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry, format formatter, filter *entryFilter) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -156,19 +211,10 @@ func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry,
 				return nil
 			}
 
-			var err error
-			if json {
-				err = render.JSON(w, entry)
-			} else {
-				err = render.LogEntry(w, entry,
-					render.HideAllocID(),
-					render.RemoveNewlines(),
-					render.HideRegion(),
-				)
-			}
-
-			if err != nil {
-				return err
+			for _, entry := range filter.apply(entry) {
+				if err := format(w, entry); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -0,0 +1,666 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/graphql"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	// shipperImage is the image of the standard log shipper, which reads the
+	// logs of an organization from NATS and forwards them to the sinks its
+	// secrets configure.
+	shipperImage = "flyio/log-shipper:latest"
+
+	// shipperMetadataKey marks the machines of log shippers with the name of
+	// the provider they ship to.
+	shipperMetadataKey = "fly_log_shipper"
+
+	// shipperTokenProfile is the profile of the access token the shipper reads
+	// logs with, which is limited to the organization.
+	shipperTokenProfile = "deploy_organization"
+)
+
+// shipProvider is a sink the log shipper forwards logs to, along with the
+// secrets configuring it.
+type shipProvider struct {
+	Name    string
+	Secrets []shipSecret
+}
+
+// shipSecret is a secret configuring a provider, set from the flag of the
+// same name or prompted for.
+type shipSecret struct {
+	Flag        string
+	Name        string
+	Description string
+	Default     string
+	Sensitive   bool
+	Optional    bool
+}
+
+var shipProviders = []shipProvider{
+	{
+		Name: "datadog",
+		Secrets: []shipSecret{
+			{Flag: "datadog-api-key", Name: "DATADOG_API_KEY", Description: "Datadog API key", Sensitive: true},
+			{Flag: "datadog-site", Name: "DATADOG_SITE", Description: "Datadog site", Default: "datadoghq.com"},
+		},
+	},
+	{
+		Name: "s3",
+		Secrets: []shipSecret{
+			{Flag: "s3-bucket", Name: "AWS_BUCKET", Description: "S3 bucket"},
+			{Flag: "s3-region", Name: "AWS_REGION", Description: "Region of the S3 bucket", Default: "us-east-1"},
+			{Flag: "aws-access-key-id", Name: "AWS_ACCESS_KEY_ID", Description: "AWS access key ID", Sensitive: true},
+			{Flag: "aws-secret-access-key", Name: "AWS_SECRET_ACCESS_KEY", Description: "AWS secret access key", Sensitive: true},
+			{Flag: "s3-endpoint", Name: "S3_ENDPOINT", Description: "Endpoint of S3 compatible storage other than AWS", Optional: true},
+		},
+	},
+	{
+		Name: "loki",
+		Secrets: []shipSecret{
+			{Flag: "loki-url", Name: "LOKI_URL", Description: "Loki URL"},
+			{Flag: "loki-username", Name: "LOKI_USERNAME", Description: "Loki username"},
+			{Flag: "loki-password", Name: "LOKI_PASSWORD", Description: "Loki password", Sensitive: true},
+		},
+	},
+}
+
+func findShipProvider(name string) (*shipProvider, error) {
+	names := make([]string, 0, len(shipProviders))
+	for i := range shipProviders {
+		if shipProviders[i].Name == name {
+			return &shipProviders[i], nil
+		}
+		names = append(names, shipProviders[i].Name)
+	}
+
+	return nil, flyerr.WithCode(flyerr.CodeValidation,
+		fmt.Errorf("unknown provider %q; choose one of %s", name, strings.Join(names, ", ")))
+}
+
+func newShip() *cobra.Command {
+	const (
+		long = `Forward the logs of all the apps of an organization to an external sink
+by running the standard log shipper as an app of the organization.
+`
+		short = "Ship the logs of an organization to an external sink"
+	)
+
+	cmd := command.New("ship", short, long, nil)
+
+	cmd.AddCommand(
+		newShipSetup(),
+		newShipStatus(),
+		newShipDestroy(),
+	)
+
+	return cmd
+}
+
+func shipperNameFlag() flag.String {
+	return flag.String{
+		Name:        "name",
+		Description: "Name of the log shipper app, <org>-log-shipper unless set",
+	}
+}
+
+func newShipSetup() *cobra.Command {
+	const (
+		long = `Set up the log shipper of an organization, creating its app and machine.
+Running it again updates the secrets and the machine of the existing shipper,
+dropping the secrets of providers no longer shipped to. Existing apps are only
+updated if all of their machines were launched by setup.
+
+The shipper reads the logs of the organization from NATS with an access token
+limited to the organization, which setup creates the first time it runs.
+`
+		short = "Set up or update the log shipper of an organization"
+	)
+
+	cmd := command.New("setup", short, long, runShipSetup,
+		command.RequireSession,
+	)
+	cmd.Args = cobra.NoArgs
+
+	names := make([]string, 0, len(shipProviders))
+	flags := flag.Set{
+		flag.Org(),
+		flag.Region(),
+		shipperNameFlag(),
+	}
+	for _, p := range shipProviders {
+		names = append(names, p.Name)
+		for _, s := range p.Secrets {
+			flags = append(flags, flag.String{
+				Name:        s.Flag,
+				Description: fmt.Sprintf("%s, for --provider %s", s.Description, p.Name),
+			})
+		}
+	}
+	flags = append(flags, flag.String{
+		Name:        "provider",
+		Description: "Where to ship logs to: " + strings.Join(names, ", "),
+	})
+
+	flag.Add(cmd, flags)
+
+	return cmd
+}
+
+func runShipSetup(ctx context.Context) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+	)
+
+	provider, err := findShipProvider(flag.GetString(ctx, "provider"))
+	if err != nil {
+		return err
+	}
+
+	org, err := prompt.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := shipSecrets(ctx, provider)
+	if err != nil {
+		return err
+	}
+	secrets["ORG"] = org.Slug
+
+	app, machines, err := ensureShipperApp(ctx, org, shipperAppName(ctx, org.Slug))
+	if err != nil {
+		return err
+	}
+
+	existing, err := client.GetAppSecrets(ctx, app.Name)
+	if err != nil {
+		return fmt.Errorf("failed listing the secrets of %s: %w", app.Name, err)
+	}
+
+	if err := unsetStaleShipSecrets(ctx, app, existing, provider); err != nil {
+		return err
+	}
+
+	// the shipper authenticates with NATS the way fly logs does, though with
+	// a token of the organization rather than that of the user
+	if !hasSecret(existing, "ACCESS_TOKEN") {
+		token, err := client.CreateLimitedAccessToken(ctx, org.ID, app.Name, shipperTokenProfile)
+		if err != nil {
+			return fmt.Errorf("failed creating an access token of %s for the log shipper: %w", org.Slug, err)
+		}
+		secrets["ACCESS_TOKEN"] = token.Token
+	}
+
+	fmt.Fprintf(io.Out, "Setting the %s secrets of %s\n", provider.Name, app.Name)
+	if _, err := client.SetSecrets(ctx, app.Name, secrets); err != nil {
+		return fmt.Errorf("failed setting the secrets of %s: %w", app.Name, err)
+	}
+
+	if err := deployShipper(ctx, org, app, machines, provider); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Shipping the logs of %s to %s with app %s\n", org.Slug, provider.Name, app.Name)
+
+	return nil
+}
+
+// shipSecrets collects the secrets of provider from their flags, prompting
+// for those which aren't set.
+func shipSecrets(ctx context.Context, provider *shipProvider) (map[string]string, error) {
+	secrets := map[string]string{}
+
+	for _, s := range provider.Secrets {
+		value := flag.GetString(ctx, s.Flag)
+
+		if value == "" && !s.Optional {
+			msg := s.Description + ":"
+
+			var err error
+			if s.Sensitive {
+				err = prompt.Password(ctx, &value, msg, true)
+			} else {
+				err = prompt.String(ctx, &value, msg, s.Default, true)
+			}
+
+			switch {
+			case prompt.IsNonInteractive(err) && s.Default != "":
+				value = s.Default
+			case prompt.IsNonInteractive(err):
+				return nil, prompt.NonInteractiveError(fmt.Sprintf("--%s must be specified when not running interactively", s.Flag))
+			case err != nil:
+				return nil, err
+			}
+		}
+
+		if value != "" {
+			secrets[s.Name] = value
+		}
+	}
+
+	return secrets, nil
+}
+
+func shipperAppName(ctx context.Context, orgSlug string) string {
+	if name := flag.GetString(ctx, "name"); name != "" {
+		return name
+	}
+
+	return orgSlug + "-log-shipper"
+}
+
+// isShipper reports whether machines are those of a log shipper, which only
+// holds for apps all of whose machines setup launched.
+func isShipper(machines []*api.Machine) bool {
+	if len(machines) == 0 {
+		return false
+	}
+
+	for _, m := range machines {
+		if m.Config == nil || m.Config.Metadata[shipperMetadataKey] == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// shipperMachines returns the machines of the log shipper app, refusing apps
+// which aren't log shippers so that setup and destroy leave those alone.
+func shipperMachines(ctx context.Context, app *api.AppCompact) ([]*api.Machine, error) {
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing the machines of %s: %w", app.Name, err)
+	}
+
+	if !isShipper(machines) {
+		return nil, flyerr.WithCode(flyerr.CodeConflict,
+			fmt.Errorf("app %s isn't a log shipper, as not all of its machines were launched by `fly logs ship setup`; pick another with --name", app.Name))
+	}
+
+	return machines, nil
+}
+
+// ensureShipperApp returns the log shipper app named appName along with its
+// machines, creating it in org if it doesn't exist yet.
+func ensureShipperApp(ctx context.Context, org *api.Organization, appName string) (*api.AppCompact, []*api.Machine, error) {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+	)
+
+	app, err := client.GetAppCompact(ctx, appName)
+	switch {
+	case err == nil:
+		if app.Organization != nil && app.Organization.Slug != org.Slug {
+			return nil, nil, flyerr.WithCode(flyerr.CodeConflict,
+				fmt.Errorf("app %s belongs to organization %s rather than %s; pick another with --name", appName, app.Organization.Slug, org.Slug))
+		}
+
+		machines, err := shipperMachines(ctx, app)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintf(io.Out, "Updating the log shipper app %s\n", appName)
+
+		return app, machines, nil
+	case !api.IsNotFoundError(err) && !graphql.IsNotFoundError(err):
+		return nil, nil, fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	fmt.Fprintf(io.Out, "Creating the log shipper app %s\n", appName)
+
+	created, err := client.CreateApp(ctx, api.CreateAppInput{
+		Name:           appName,
+		OrganizationID: org.ID,
+		Machines:       true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed creating app %s: %w", appName, err)
+	}
+
+	if app, err = client.GetAppCompact(ctx, created.Name); err != nil {
+		return nil, nil, err
+	}
+
+	return app, nil, nil
+}
+
+func hasSecret(secrets []api.Secret, name string) bool {
+	for _, secret := range secrets {
+		if secret.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unsetStaleShipSecrets unsets those of the existing secrets of app which
+// configure providers other than provider, so that it stops shipping logs to
+// those.
+func unsetStaleShipSecrets(ctx context.Context, app *api.AppCompact, existing []api.Secret, provider *shipProvider) error {
+	stale := staleShipSecrets(existing, provider)
+	if len(stale) == 0 {
+		return nil
+	}
+
+	if _, err := client.FromContext(ctx).API().UnsetSecrets(ctx, app.Name, stale); err != nil {
+		return fmt.Errorf("failed unsetting the secrets of other providers: %w", err)
+	}
+
+	return nil
+}
+
+// staleShipSecrets returns the names of those of the existing secrets which
+// configure providers other than provider.
+func staleShipSecrets(existing []api.Secret, provider *shipProvider) (stale []string) {
+	keep := map[string]bool{}
+	for _, s := range provider.Secrets {
+		keep[s.Name] = true
+	}
+
+	known := map[string]bool{}
+	for _, p := range shipProviders {
+		for _, s := range p.Secrets {
+			known[s.Name] = !keep[s.Name]
+		}
+	}
+
+	for _, secret := range existing {
+		if known[secret.Name] {
+			stale = append(stale, secret.Name)
+		}
+	}
+
+	return
+}
+
+// deployShipper launches the machine of the shipper app, or updates the
+// machines it has so that they pick up its secrets.
+func deployShipper(ctx context.Context, org *api.Organization, app *api.AppCompact, machines []*api.Machine, provider *shipProvider) error {
+	io := iostreams.FromContext(ctx)
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+	ctx = flaps.NewContext(ctx, flapsClient)
+
+	if len(machines) == 0 {
+		conf := &api.MachineConfig{
+			Image:    shipperImage,
+			Guest:    api.MachinePresets["shared-cpu-1x"],
+			Metadata: map[string]string{shipperMetadataKey: provider.Name},
+		}
+		conf.Restart.Policy = api.MachineRestartPolicyAlways
+
+		fmt.Fprintf(io.Out, "Launching the log shipper with image %s\n", shipperImage)
+
+		machine, err := flapsClient.Launch(ctx, api.LaunchMachineInput{
+			AppID:   app.ID,
+			OrgSlug: org.ID,
+			Region:  config.FromContext(ctx).Region,
+			Config:  conf,
+		})
+		if err != nil {
+			return fmt.Errorf("failed launching the log shipper: %w", err)
+		}
+
+		fmt.Fprintf(io.Out, "Waiting for machine %s to start...\n", machine.ID)
+
+		return mach.WaitForStartOrStop(ctx, machine, "start", 5*time.Minute)
+	}
+
+	for _, machine := range machines {
+		err := mach.WithLease(ctx, machine, func(ctx context.Context, machine *api.Machine) error {
+			conf, err := mach.CloneConfig(*machine.Config)
+			if err != nil {
+				return err
+			}
+
+			conf.Image = shipperImage
+			if conf.Metadata == nil {
+				conf.Metadata = map[string]string{}
+			}
+			conf.Metadata[shipperMetadataKey] = provider.Name
+
+			return mach.Update(ctx, machine, &api.LaunchMachineInput{
+				AppID:  app.Name,
+				Name:   machine.Name,
+				Region: machine.Region,
+				Config: conf,
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orgShipperApp returns the name of the shipper app --name or --org
+// identify.
+func orgShipperApp(ctx context.Context) (string, error) {
+	if name := flag.GetString(ctx, "name"); name != "" {
+		return name, nil
+	}
+
+	org, err := prompt.Org(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return shipperAppName(ctx, org.Slug), nil
+}
+
+func newShipStatus() *cobra.Command {
+	const (
+		short = "Show the log shipper of an organization"
+		long  = short + "\n"
+	)
+
+	cmd := command.New("status", short, long, runShipStatus,
+		command.RequireSession,
+	)
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Org(),
+		shipperNameFlag(),
+	)
+
+	return cmd
+}
+
+type shipStatus struct {
+	App          string               `json:"app"`
+	Organization string               `json:"organization"`
+	Providers    []string             `json:"providers"`
+	Secrets      []string             `json:"secrets"`
+	Machines     []shipStatusMachines `json:"machines"`
+}
+
+type shipStatusMachines struct {
+	ID     string `json:"id"`
+	State  string `json:"state"`
+	Region string `json:"region"`
+	Image  string `json:"image"`
+}
+
+func runShipStatus(ctx context.Context) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+	)
+
+	appName, err := orgShipperApp(ctx)
+	if err != nil {
+		return err
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	switch {
+	case api.IsNotFoundError(err) || graphql.IsNotFoundError(err):
+		return flyerr.WithCode(flyerr.CodeNotFound,
+			fmt.Errorf("there's no log shipper app %s; set one up with `fly logs ship setup`", appName))
+	case err != nil:
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed listing the machines of %s: %w", app.Name, err)
+	}
+
+	secrets, err := client.GetAppSecrets(ctx, app.Name)
+	if err != nil {
+		return fmt.Errorf("failed listing the secrets of %s: %w", app.Name, err)
+	}
+
+	status := shipStatus{
+		App:      app.Name,
+		Secrets:  make([]string, 0, len(secrets)),
+		Machines: make([]shipStatusMachines, 0, len(machines)),
+	}
+	if app.Organization != nil {
+		status.Organization = app.Organization.Slug
+	}
+	for _, secret := range secrets {
+		status.Secrets = append(status.Secrets, secret.Name)
+	}
+	sort.Strings(status.Secrets)
+
+	providers := map[string]bool{}
+	for _, machine := range machines {
+		status.Machines = append(status.Machines, shipStatusMachines{
+			ID:     machine.ID,
+			State:  machine.State,
+			Region: machine.Region,
+			Image:  machine.ImageRefWithVersion(),
+		})
+		if machine.Config != nil && machine.Config.Metadata[shipperMetadataKey] != "" {
+			providers[machine.Config.Metadata[shipperMetadataKey]] = true
+		}
+	}
+	for provider := range providers {
+		status.Providers = append(status.Providers, provider)
+	}
+	sort.Strings(status.Providers)
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, status)
+	}
+
+	provider := strings.Join(status.Providers, ", ")
+	if provider == "" {
+		provider = "-"
+	}
+
+	obj := [][]string{{status.App, status.Organization, provider, strings.Join(status.Secrets, ", ")}}
+	if err := render.VerticalTable(io.Out, "Log shipper", obj, "App", "Organization", "Provider", "Secrets"); err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(status.Machines))
+	for _, m := range status.Machines {
+		rows = append(rows, []string{m.ID, m.State, m.Region, m.Image})
+	}
+
+	return render.Table(io.Out, "Machines", rows, "ID", "State", "Region", "Image")
+}
+
+func newShipDestroy() *cobra.Command {
+	const (
+		short = "Destroy the log shipper of an organization"
+		long  = short + ", which stops shipping its logs\n"
+	)
+
+	cmd := command.New("destroy", short, long, runShipDestroy,
+		command.RequireSession,
+	)
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.Yes(),
+		shipperNameFlag(),
+	)
+
+	return cmd
+}
+
+func runShipDestroy(ctx context.Context) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+	)
+
+	appName, err := orgShipperApp(ctx)
+	if err != nil {
+		return err
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	// only apps running the log shipper are destroyed
+	if _, err := shipperMachines(ctx, app); err != nil {
+		return err
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Destroy the log shipper app %s, which stops shipping logs?", appName); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	if err := client.DeleteApp(ctx, appName); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Destroyed the log shipper app %s\n", appName)
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestFindShipProvider(t *testing.T) {
+	provider, err := findShipProvider("loki")
+	require.NoError(t, err)
+	assert.Equal(t, "loki", provider.Name)
+
+	_, err = findShipProvider("papertrail")
+	assert.ErrorContains(t, err, "datadog, s3, loki")
+}
+
+func TestIsShipper(t *testing.T) {
+	shipper := func(id string) *api.Machine {
+		return &api.Machine{ID: id, Config: &api.MachineConfig{Metadata: map[string]string{shipperMetadataKey: "datadog"}}}
+	}
+
+	assert.False(t, isShipper(nil), "apps without machines")
+	assert.True(t, isShipper([]*api.Machine{shipper("a"), shipper("b")}))
+	assert.False(t, isShipper([]*api.Machine{shipper("a"), {ID: "b", Config: &api.MachineConfig{}}}), "apps with machines setup didn't launch")
+	assert.False(t, isShipper([]*api.Machine{shipper("a"), {ID: "b"}}), "machines without a config")
+}
+
+func TestStaleShipSecrets(t *testing.T) {
+	provider, err := findShipProvider("datadog")
+	require.NoError(t, err)
+
+	existing := []api.Secret{
+		{Name: "DATADOG_API_KEY"},
+		{Name: "LOKI_URL"},
+		{Name: "AWS_SECRET_ACCESS_KEY"},
+		{Name: "ACCESS_TOKEN"},
+	}
+
+	assert.Equal(t, []string{"LOKI_URL", "AWS_SECRET_ACCESS_KEY"}, staleShipSecrets(existing, provider))
+	assert.True(t, hasSecret(existing, "ACCESS_TOKEN"))
+	assert.False(t, hasSecret(existing, "DATADOG_SITE"))
+}
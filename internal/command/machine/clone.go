@@ -2,7 +2,10 @@ package machine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -42,8 +45,22 @@ func newClone() *cobra.Command {
 		},
 		flag.String{
 			Name:        "name",
-			Description: "Optional name for the new machine",
+			Description: "Optional name for the new machine. With --count, machines are named after it with an index suffix",
 		},
+		flag.Int{
+			Name:        "count",
+			Default:     1,
+			Description: "Number of clones to create",
+		},
+		flag.String{
+			Name:        "regions",
+			Description: "Comma separated list of regions to spread the clones across, round-robin",
+		},
+		flag.String{
+			Name:        "volume",
+			Description: "Create a fresh volume of the given size for each clone in its region, in the form of new:<size in GB>. It's mounted where the source machine mounts its volume",
+		},
+		gpuFlags,
 	)
 
 	return cmd
@@ -91,47 +108,46 @@ func runMachineClone(ctx context.Context) (err error) {
 		fmt.Fprintf(out, "Picked %s for cloning\n", source.ID)
 	}
 
-	region := flag.GetString(ctx, "region")
-	if region == "" {
-		region = source.Region
+	regions, err := cloneRegions(ctx, source)
+	if err != nil {
+		return err
 	}
 
-	fmt.Fprintf(out, "Cloning machine %s into region %s\n", colorize.Bold(source.ID), colorize.Bold(region))
+	// clones get the GPUs of the source unless others are requested, and
+	// may only land in regions offering them either way
+	if source.Config.Guest != nil {
+		applyGPUFlags(ctx, source.Config.Guest)
+	}
+	if gpuRequested(source.Config) {
+		if err := mach.ValidateGPU(ctx, source.Config.Guest, regions...); err != nil {
+			return err
+		}
+	}
 
-	targetConfig := source.Config
+	volumeSize, err := parseCloneVolume(flag.GetString(ctx, "volume"), source)
+	if err != nil {
+		return err
+	}
 
-	// This is a temperary hack to add volume support for PG apps.
-	// Flaps does not currently specify the volume name within the Machine mount spec,
-	// which is required before we can handle this more generally.
-	if app.PostgresAppRole != nil && app.PostgresAppRole.Name == "postgres_cluster" {
-		if len(source.Config.Mounts) > 0 {
-			mnt := source.Config.Mounts[0]
-
-			volInput := api.CreateVolumeInput{
-				AppID:             app.ID,
-				Name:              "pg_data",
-				Region:            region,
-				SizeGb:            mnt.SizeGb,
-				Encrypted:         mnt.Encrypted,
-				RequireUniqueZone: false,
-			}
+	if count := flag.GetInt(ctx, "count"); count != 1 {
+		if count < 1 {
+			return errors.New("--count must be at least 1")
+		}
 
-			vol, err := client.CreateVolume(ctx, volInput)
-			if err != nil {
-				return err
-			}
+		return cloneMany(ctx, app, source, count, regions, volumeSize)
+	}
 
-			targetConfig.Mounts = []api.MachineMount{
-				{
-					Volume:    vol.ID,
-					Path:      mnt.Path,
-					SizeGb:    mnt.SizeGb,
-					Encrypted: mnt.Encrypted,
-				},
-			}
-		}
-	} else {
-		targetConfig.Mounts = nil
+	region := regions[0]
+
+	fmt.Fprintf(out, "Cloning machine %s into region %s\n", colorize.Bold(source.ID), colorize.Bold(region))
+
+	targetConfig, err := mach.CloneConfig(*source.Config)
+	if err != nil {
+		return err
+	}
+
+	if targetConfig.Mounts, err = cloneMounts(ctx, app, source, region, volumeSize); err != nil {
+		return err
 	}
 
 	input := api.LaunchMachineInput{
@@ -145,6 +161,8 @@ func runMachineClone(ctx context.Context) (err error) {
 
 	launchedMachine, err := flapsClient.Launch(ctx, input)
 	if err != nil {
+		deleteCloneVolumes(ctx, targetConfig.Mounts)
+
 		return err
 	}
 
@@ -165,3 +183,125 @@ func runMachineClone(ctx context.Context) (err error) {
 
 	return
 }
+
+// deleteCloneVolumes deletes the volumes cloneMounts created for a clone which
+// failed to launch, so that they don't linger unattached.
+func deleteCloneVolumes(ctx context.Context, mounts []api.MachineMount) {
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+	)
+
+	for _, m := range mounts {
+		if _, err := apiClient.DeleteVolume(ctx, m.Volume); err != nil {
+			fmt.Fprintf(io.ErrOut, "failed deleting volume %s: %v\n", m.Volume, err)
+		}
+	}
+}
+
+// cloneRegions returns the regions clones are placed in, in turn.
+func cloneRegions(ctx context.Context, source *api.Machine) ([]string, error) {
+	region, list := flag.GetString(ctx, "region"), flag.GetString(ctx, "regions")
+
+	switch {
+	case region != "" && list != "":
+		return nil, errors.New("--region and --regions are mutually exclusive")
+	case region != "":
+		return []string{region}, nil
+	case list == "":
+		return []string{source.Region}, nil
+	}
+
+	var regions []string
+	for _, r := range strings.Split(list, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			regions = append(regions, r)
+		}
+	}
+
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("no regions in --regions %q", list)
+	}
+
+	return regions, nil
+}
+
+// parseCloneVolume parses the --volume flag into the size in GB of the
+// volumes to create, or 0 if none are to be created.
+func parseCloneVolume(spec string, source *api.Machine) (int, error) {
+	if spec == "" {
+		return 0, nil
+	}
+
+	kind, size, ok := strings.Cut(spec, ":")
+	if !ok || kind != "new" {
+		return 0, fmt.Errorf("invalid --volume %q: expected new:<size in GB>", spec)
+	}
+
+	sizeGb, err := strconv.Atoi(size)
+	if err != nil || sizeGb < 1 {
+		return 0, fmt.Errorf("invalid --volume %q: the size must be a positive number of GB", spec)
+	}
+
+	if len(source.Config.Mounts) == 0 {
+		return 0, fmt.Errorf("--volume requires machine %s to mount a volume, so clones know where to mount theirs", source.ID)
+	}
+
+	return sizeGb, nil
+}
+
+// cloneMounts returns the mounts of a clone of source in region, creating the
+// volume they need when volumeSize is set.
+func cloneMounts(ctx context.Context, app *api.AppCompact, source *api.Machine, region string, volumeSize int) ([]api.MachineMount, error) {
+	if len(source.Config.Mounts) == 0 {
+		return nil, nil
+	}
+
+	// This is a temperary hack to add volume support for PG apps.
+	// Flaps does not currently specify the volume name within the Machine mount spec,
+	// which is required before we can handle this more generally.
+	isPG := app.PostgresAppRole != nil && app.PostgresAppRole.Name == "postgres_cluster"
+	if !isPG && volumeSize == 0 {
+		return nil, nil
+	}
+
+	var (
+		client = client.FromContext(ctx).API()
+		mnt    = source.Config.Mounts[0]
+		name   = "pg_data"
+	)
+
+	if volumeSize > 0 {
+		mnt.SizeGb = volumeSize
+
+		if !isPG {
+			name = "data"
+			if vol, err := client.GetVolume(ctx, mnt.Volume); err == nil {
+				name = vol.Name
+			}
+		}
+	}
+
+	volInput := api.CreateVolumeInput{
+		AppID:             app.ID,
+		Name:              name,
+		Region:            region,
+		SizeGb:            mnt.SizeGb,
+		Encrypted:         mnt.Encrypted,
+		RequireUniqueZone: false,
+	}
+
+	vol, err := client.CreateVolume(ctx, volInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return []api.MachineMount{
+		{
+			Volume:    vol.ID,
+			Path:      mnt.Path,
+			SizeGb:    mnt.SizeGb,
+			Encrypted: mnt.Encrypted,
+		},
+	}, nil
+}
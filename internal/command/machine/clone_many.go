@@ -0,0 +1,118 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// cloneConcurrency bounds the number of clones created at once.
+const cloneConcurrency = 8
+
+// cloneResult is the outcome of creating a single clone.
+type cloneResult struct {
+	name    string
+	region  string
+	machine *api.Machine
+	err     error
+}
+
+// cloneMany creates count clones of source concurrently, spread across
+// regions round-robin. Failures don't stop the rest of the clones from being
+// created.
+func cloneMany(ctx context.Context, app *api.AppCompact, source *api.Machine, count int, regions []string, volumeSize int) error {
+	var (
+		io          = iostreams.FromContext(ctx)
+		colorize    = io.ColorScheme()
+		flapsClient = flaps.FromContext(ctx)
+		results     = make([]cloneResult, count)
+		sem         = make(chan struct{}, cloneConcurrency)
+		wg          sync.WaitGroup
+	)
+
+	base := flag.GetString(ctx, "name")
+	switch {
+	case base != "":
+	case source.Name != "":
+		base = source.Name + "-clone"
+	default:
+		base = "clone"
+	}
+
+	fmt.Fprintf(io.Out, "Cloning machine %s %d times into %d region(s)\n", colorize.Bold(source.ID), count, len(regions))
+
+	for i := 0; i < count; i++ {
+		results[i] = cloneResult{
+			name:   fmt.Sprintf("%s-%d", base, i+1),
+			region: regions[i%len(regions)],
+		}
+
+		wg.Add(1)
+
+		go func(r *cloneResult) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			config, err := mach.CloneConfig(*source.Config)
+			if err != nil {
+				r.err = err
+
+				return
+			}
+
+			if config.Mounts, r.err = cloneMounts(ctx, app, source, r.region, volumeSize); r.err != nil {
+				return
+			}
+
+			input := api.LaunchMachineInput{
+				AppID:  app.Name,
+				Name:   r.name,
+				Region: r.region,
+				Config: config,
+			}
+
+			if r.machine, r.err = flapsClient.Launch(ctx, input); r.err != nil {
+				deleteCloneVolumes(ctx, config.Mounts)
+
+				return
+			}
+
+			if r.err = mach.WaitForStartOrStop(ctx, r.machine, "start", time.Minute*5); r.err == nil {
+				r.machine.State = "started"
+			}
+		}(&results[i])
+	}
+
+	wg.Wait()
+
+	var failed int
+	rows := make([][]string, 0, count)
+	for _, r := range results {
+		var id, state string
+		if r.machine != nil {
+			id, state = r.machine.ID, r.machine.State
+		}
+		if r.err != nil {
+			failed++
+			state = "failed: " + r.err.Error()
+		}
+		rows = append(rows, []string{id, r.name, r.region, state})
+	}
+	_ = render.Table(io.Out, "", rows, "ID", "Name", "Region", "State")
+
+	if failed > 0 {
+		return fmt.Errorf("failed creating %d of %d clones", failed, count)
+	}
+
+	return nil
+}
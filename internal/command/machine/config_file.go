@@ -0,0 +1,176 @@
+package machine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// readMachineConfigFile returns the raw contents of the file --machine-config
+// points at, reading from stdin when it's set to "-". It returns nil when the
+// flag isn't set.
+func readMachineConfigFile(ctx context.Context) ([]byte, error) {
+	path := flag.GetString(ctx, "machine-config")
+	if path == "" {
+		return nil, nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(iostreams.FromContext(ctx).In)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed reading machine config: %w", err)
+	}
+
+	if !flag.GetBool(ctx, "allow-unknown-fields") {
+		if err := checkUnknownConfigFields(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// loadMachineConfig parses and validates the machine config passed via
+// --machine-config. When base is given the file's top-level fields are merged
+// on top of it; otherwise the file stands on its own.
+func loadMachineConfig(ctx context.Context, base *api.MachineConfig) (*api.MachineConfig, error) {
+	data, err := readMachineConfigFile(ctx)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var merged map[string]json.RawMessage
+	if base != nil {
+		current, err := json.Marshal(base)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(current, &merged); err != nil {
+			return nil, err
+		}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid machine config: %w", err)
+	}
+
+	if merged == nil {
+		merged = fields
+	} else {
+		for k, v := range fields {
+			merged[k] = v
+		}
+	}
+
+	if data, err = json.Marshal(merged); err != nil {
+		return nil, err
+	}
+
+	var config api.MachineConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid machine config: %w", err)
+	}
+
+	if err := validateMachineConfig(&config); err != nil {
+		return nil, fmt.Errorf("invalid machine config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// checkUnknownConfigFields rejects top-level fields the machine config
+// doesn't know about, which usually point at a typo.
+func checkUnknownConfigFields(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&fields); err != nil {
+		return fmt.Errorf("invalid machine config: %w", err)
+	}
+
+	known := machineConfigFields()
+
+	var unknown []string
+	for name := range fields {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	return fmt.Errorf("unknown machine config fields: %s (pass --allow-unknown-fields to ignore them)", strings.Join(unknown, ", "))
+}
+
+func machineConfigFields() map[string]bool {
+	fields := map[string]bool{}
+
+	t := reflect.TypeOf(api.MachineConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+
+	return fields
+}
+
+func validateMachineConfig(config *api.MachineConfig) error {
+	if guest := config.Guest; guest != nil {
+		if guest.CPUs < 0 || guest.MemoryMB < 0 {
+			return fmt.Errorf("guest cpus and memory_mb must not be negative")
+		}
+		if guest.CPUKind != "" && guest.CPUKind != "shared" && guest.CPUKind != "performance" {
+			return fmt.Errorf("unknown guest cpu_kind %q; must be shared or performance", guest.CPUKind)
+		}
+	}
+
+	for _, service := range config.Services {
+		if service.InternalPort < 1 || service.InternalPort > 65535 {
+			return fmt.Errorf("service internal_port %d is out of range", service.InternalPort)
+		}
+	}
+
+	for _, mount := range config.Mounts {
+		if mount.Path == "" {
+			return fmt.Errorf("mount of volume %s is missing a path", mount.Volume)
+		}
+	}
+
+	return nil
+}
+
+// printMachineConfig prints config as the --dry-run output.
+func printMachineConfig(ctx context.Context, config *api.MachineConfig) error {
+	out := iostreams.FromContext(ctx).Out
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(data))
+
+	return err
+}
@@ -0,0 +1,130 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// runMachineDiff compares the config of the machine identified by machineID
+// with that of the one identified by otherID, failing with exit code 1 should
+// they differ so that scripts may assert machines are alike.
+func runMachineDiff(ctx context.Context, machineID, otherID string) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		appName = app.NameFromContext(ctx)
+	)
+
+	otherAppName := flag.GetString(ctx, "diff-app")
+	if otherAppName == "" {
+		otherAppName = appName
+	}
+
+	machine, err := getMachine(ctx, machineID, appName)
+	if err != nil {
+		return err
+	}
+
+	other, err := getMachine(ctx, otherID, otherAppName)
+	if err != nil {
+		return err
+	}
+
+	// scoped secrets are compared by their digests
+	changes, err := mach.DiffConfigs(*mach.MaskScopedSecrets(machine.Config), *mach.MaskScopedSecrets(other.Config))
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		if err := render.JSON(io.Out, changes); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(io.Out, "Comparing machine %s with machine %s\n\n", machineID, otherID)
+		printConfigChanges(io, changes)
+	}
+
+	if len(changes) == 0 {
+		if !config.FromContext(ctx).JSONOutput {
+			fmt.Fprintln(io.Out, "no differences")
+		}
+
+		return nil
+	}
+
+	return &flyerr.ExitCodeError{
+		Err:  fmt.Errorf("the configs of machines %s and %s have %d differences", machineID, otherID, len(changes)),
+		Code: 1,
+	}
+}
+
+func printConfigChanges(io *iostreams.IOStreams, changes []mach.ConfigChange) {
+	colorize := io.ColorScheme()
+
+	var section string
+	for _, change := range changes {
+		if change.Section != section {
+			section = change.Section
+			fmt.Fprintln(io.Out, colorize.Bold(section))
+		}
+
+		path := change.RelativePath()
+		if path != "" {
+			path += ": "
+		}
+
+		switch change.Kind {
+		case mach.ChangeAdded:
+			fmt.Fprintln(io.Out, colorize.Green(fmt.Sprintf("  + %s%s", path, formatConfigValue(change.To))))
+		case mach.ChangeRemoved:
+			fmt.Fprintln(io.Out, colorize.Red(fmt.Sprintf("  - %s%s", path, formatConfigValue(change.From))))
+		default:
+			fmt.Fprintln(io.Out, colorize.Yellow(fmt.Sprintf("  ~ %s%s → %s", path, formatConfigValue(change.From), formatConfigValue(change.To))))
+		}
+	}
+}
+
+func formatConfigValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(data)
+}
+
+// getMachine retrieves the machine identified by machineID from the app named
+// appName or, if it's empty, from whichever app the machine belongs to.
+func getMachine(ctx context.Context, machineID, appName string) (*api.Machine, error) {
+	app, err := appFromMachineOrName(ctx, machineID, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return nil, fmt.Errorf("could not make flaps client: %w", err)
+	}
+
+	machine, err := flapsClient.Get(ctx, machineID)
+	if err != nil {
+		return nil, fmt.Errorf("machine %s could not be retrieved: %w", machineID, err)
+	}
+
+	if machine.Config == nil {
+		return nil, fmt.Errorf("machine %s has no config", machineID)
+	}
+
+	return machine, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/iostreams"
 )
 
@@ -68,7 +69,7 @@ func runMachineKill(ctx context.Context) (err error) {
 	if err != nil {
 		switch {
 		case strings.Contains(err.Error(), "not found") && appName != "":
-			return fmt.Errorf("could not find machine %s in app %s to kill", machineID, appName)
+			return flyerr.WithCode(flyerr.CodeNotFound, fmt.Errorf("could not find machine %s in app %s to kill", machineID, appName))
 		default:
 			return fmt.Errorf("could not kill machine %s: %w", machineID, err)
 		}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
@@ -40,6 +42,10 @@ func newList() *cobra.Command {
 			Shorthand:   "q",
 			Description: "Only list machine ids",
 		},
+		flag.Bool{
+			Name:        "problems-only",
+			Description: "Only list machines on impaired hosts or with failing checks",
+		},
 	)
 
 	return cmd
@@ -76,8 +82,18 @@ func runMachineList(ctx context.Context) (err error) {
 		return nil
 	}
 
+	if flag.GetBool(ctx, "problems-only") {
+		if machines = withProblems(machines); len(machines) == 0 {
+			if cfg.JSONOutput {
+				return render.JSON(io.Out, machines)
+			}
+			fmt.Fprintf(io.Out, "No machines of app %s have problems\n", appName)
+			return nil
+		}
+	}
+
 	if cfg.JSONOutput {
-		return render.JSON(io.Out, machines)
+		return render.JSON(io.Out, mach.MaskMachineSecrets(machines))
 	}
 
 	rows := [][]string{}
@@ -90,26 +106,79 @@ func runMachineList(ctx context.Context) (err error) {
 		}
 		_ = render.Table(io.Out, appName, rows, "ID")
 	} else {
+		hostColumn := anyHostImpaired(machines)
+
 		for _, machine := range machines {
 			var volName string
 			if machine.Config != nil && len(machine.Config.Mounts) > 0 {
 				volName = machine.Config.Mounts[0].Volume
 			}
 
-			rows = append(rows, []string{
+			row := []string{
 				machine.ID,
 				machine.Name,
-				machine.State,
+				displayState(machine),
 				machine.Region,
 				machine.ImageRefWithVersion(),
 				machine.PrivateIP,
 				volName,
 				machine.CreatedAt,
 				machine.UpdatedAt,
-			})
+			}
+			if hostColumn {
+				row = append(row, displayHostStatus(io.ColorScheme(), machine))
+			}
+			rows = append(rows, row)
 		}
 
-		_ = render.Table(io.Out, appName, rows, "ID", "Name", "State", "Region", "Image", "IP Address", "Volume", "Created", "Last Updated")
+		cols := []string{"ID", "Name", "State", "Region", "Image", "IP Address", "Volume", "Created", "Last Updated"}
+		if hostColumn {
+			cols = append(cols, "Host Status")
+		}
+		_ = render.Table(io.Out, appName, rows, cols...)
 	}
 	return nil
 }
+
+// withProblems returns the machines which are on impaired hosts or have
+// failing checks.
+func withProblems(machines []*api.Machine) []*api.Machine {
+	problems := []*api.Machine{}
+	for _, machine := range machines {
+		if machine.HostImpaired() || hasFailingChecks(machine) {
+			problems = append(problems, machine)
+		}
+	}
+
+	return problems
+}
+
+func hasFailingChecks(machine *api.Machine) bool {
+	for _, check := range machine.Checks {
+		if check.Status != "passing" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anyHostImpaired reports whether the host of any of the machines is
+// impaired, which is when their host status is worth showing.
+func anyHostImpaired(machines []*api.Machine) bool {
+	for _, machine := range machines {
+		if machine.HostImpaired() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func displayHostStatus(colorize *iostreams.ColorScheme, machine *api.Machine) string {
+	if machine.HostImpaired() {
+		return colorize.Red(machine.HostHealth())
+	}
+
+	return machine.HostHealth()
+}
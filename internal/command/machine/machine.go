@@ -7,6 +7,7 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/command"
+	mach "github.com/superfly/flyctl/internal/machine"
 )
 
 func New() *cobra.Command {
@@ -35,11 +36,26 @@ func New() *cobra.Command {
 		newUpdate(),
 		newRestart(),
 		newLeases(),
+		newTop(),
+		newRollback(),
+		newPauseChecks(),
+		newResumeChecks(),
+		newSizes(),
+		newMigrateVolume(),
 	)
 
 	return cmd
 }
 
+// displayState returns the state of m, noting any staged update.
+func displayState(m *api.Machine) string {
+	if mach.IsUpdateStaged(m) {
+		return m.State + " (update staged)"
+	}
+
+	return m.State
+}
+
 func appFromMachineOrName(ctx context.Context, machineId string, appName string) (app *api.AppCompact, err error) {
 	client := client.FromContext(ctx).API()
 
@@ -0,0 +1,142 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newPauseChecks() *cobra.Command {
+	const (
+		short = "Pause the health checks of one or more Fly machines for maintenance"
+		long  = short + `. Deployments and 'checks list --wait' skip the checks
+of paused machines, and status shows them as in maintenance instead of failing.
+The pause lasts until 'machine resume-checks', or for --duration if set.
+`
+
+		usage = "pause-checks <id> [<id>...]"
+	)
+
+	cmd := command.New(usage, short, long, runPauseChecks,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.MinimumNArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "duration",
+			Description: "Resume the checks automatically after this long, such as 1h",
+		},
+	)
+
+	return cmd
+}
+
+func newResumeChecks() *cobra.Command {
+	const (
+		short = "Resume the health checks of one or more Fly machines paused for maintenance"
+		long  = short + "\n"
+
+		usage = "resume-checks <id> [<id>...]"
+	)
+
+	cmd := command.New(usage, short, long, runResumeChecks,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.MinimumNArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runPauseChecks(ctx context.Context) error {
+	var (
+		io   = iostreams.FromContext(ctx)
+		args = flag.Args(ctx)
+	)
+
+	value := "true"
+	if val := flag.GetString(ctx, "duration"); val != "" {
+		d, err := time.ParseDuration(val)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid --duration %q: must be a positive duration such as 1h", val)
+		}
+
+		value = time.Now().Add(d).UTC().Format(time.RFC3339)
+	}
+
+	for _, machineID := range args {
+		flapsClient, err := maintenanceClient(ctx, machineID)
+		if err != nil {
+			return err
+		}
+
+		if err := flapsClient.SetMetadata(ctx, machineID, api.MachineChecksPausedMetadataKey, value); err != nil {
+			return fmt.Errorf("could not pause checks of machine %s: %w", machineID, err)
+		}
+
+		if value == "true" {
+			fmt.Fprintf(io.Out, "Paused checks of machine %s until resumed\n", machineID)
+		} else {
+			fmt.Fprintf(io.Out, "Paused checks of machine %s until %s\n", machineID, value)
+		}
+	}
+
+	return nil
+}
+
+func runResumeChecks(ctx context.Context) error {
+	var (
+		io   = iostreams.FromContext(ctx)
+		args = flag.Args(ctx)
+	)
+
+	for _, machineID := range args {
+		flapsClient, err := maintenanceClient(ctx, machineID)
+		if err != nil {
+			return err
+		}
+
+		if err := flapsClient.DeleteMetadata(ctx, machineID, api.MachineChecksPausedMetadataKey); err != nil {
+			return fmt.Errorf("could not resume checks of machine %s: %w", machineID, err)
+		}
+
+		fmt.Fprintf(io.Out, "Resumed checks of machine %s\n", machineID)
+	}
+
+	return nil
+}
+
+func maintenanceClient(ctx context.Context, machineID string) (*flaps.Client, error) {
+	app, err := appFromMachineOrName(ctx, machineID, app.NameFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("could not get app: %w", err)
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return nil, fmt.Errorf("could not make flaps client: %w", err)
+	}
+
+	return flapsClient, nil
+}
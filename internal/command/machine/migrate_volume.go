@@ -0,0 +1,522 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/flyctl"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/apps"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// The steps of a volume migration, in order. Each is recorded once done.
+const (
+	migrationStarted  = "started"
+	migrationCreated  = "volume_created"
+	migrationDetached = "volume_detached"
+	migrationCopied   = "data_copied"
+	migrationSwapped  = "volume_swapped"
+)
+
+var migrationSteps = []string{migrationStarted, migrationCreated, migrationDetached, migrationCopied, migrationSwapped}
+
+const (
+	// migrationCopierGroup is the process group of the machines copying the
+	// data of volumes.
+	migrationCopierGroup = "volume_migration"
+
+	migrationCopierImage = "busybox:1.36"
+)
+
+// volumeMigration is the on-disk record of the progress of migrating the
+// data of a machine's volume to a new one, so that an interrupted migration
+// may be resumed by running the command again.
+type volumeMigration struct {
+	App          string    `json:"app"`
+	MachineID    string    `json:"machine_id"`
+	MountPath    string    `json:"mount_path"`
+	SourceVolume string    `json:"source_volume"`
+	TargetVolume string    `json:"target_volume,omitempty"`
+	SizeGb       int       `json:"size_gb"`
+	Step         string    `json:"step"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// done reports whether the migration got past step. Steps it doesn't know
+// of are never done.
+func (m *volumeMigration) done(step string) bool {
+	for i, s := range migrationSteps {
+		if s != m.Step {
+			continue
+		}
+
+		for _, s := range migrationSteps[:i+1] {
+			if s == step {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// migrationsDir returns the directory the progress of volume migrations is
+// recorded in.
+var migrationsDir = func() string {
+	return filepath.Join(flyctl.ConfigDir(), "volume-migrations")
+}
+
+func migrationPath(machineID string) string {
+	return filepath.Join(migrationsDir(), machineID+".json")
+}
+
+func readMigration(machineID string) (*volumeMigration, error) {
+	data, err := os.ReadFile(migrationPath(machineID))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed reading the progress of the volume migration: %w", err)
+	}
+
+	var m volumeMigration
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid volume migration progress %s: %w", migrationPath(machineID), err)
+	}
+
+	return &m, nil
+}
+
+// record stores that the migration is done with step.
+func (m *volumeMigration) record(step string) error {
+	m.Step = step
+	m.UpdatedAt = time.Now().UTC()
+
+	path := migrationPath(m.MachineID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed recording the progress of the volume migration: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed recording the progress of the volume migration: %w", err)
+	}
+
+	return nil
+}
+
+// reset records that the migration is back to having just created the new
+// volume, once the original volume is attached to the machine again, so that
+// resuming it detaches the original volume and copies the data over anew.
+func (m *volumeMigration) reset() error {
+	return m.record(migrationCreated)
+}
+
+func newMigrateVolume() *cobra.Command {
+	const (
+		short = "Migrate the data of a machine's volume to a new volume of another size"
+		long  = short + `
+
+Creates a volume of the given size in the region of the machine, stops the
+machine and detaches its volume, copies the data over with a temporary machine
+mounting both volumes, attaches the new volume and waits for the machine to
+start and pass its health checks. The original volume is left untouched, and
+reattached should the migration fail; it's only destroyed at the end when
+--destroy-source is given.
+
+The progress of the migration is recorded, so should it be interrupted, run
+the command again to resume it.
+`
+
+		usage = "migrate-volume <machine_id>"
+	)
+
+	cmd := command.New(usage, short, long, runMigrateVolume,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Int{
+			Name:        "to-size",
+			Description: "Size of the new volume in GB",
+		},
+		flag.String{
+			Name:        "volume",
+			Description: "ID of the volume to migrate, when the machine has more than one",
+		},
+		flag.String{
+			Name:        "copy-timeout",
+			Default:     "1h",
+			Description: "How long copying the data of the volume may take",
+		},
+		flag.Bool{
+			Name:        "destroy-source",
+			Description: "Destroy the original volume once the machine runs with the new one",
+		},
+		flag.Yes(),
+	)
+
+	return cmd
+}
+
+func runMigrateVolume(ctx context.Context) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		machineID = flag.FirstArg(ctx)
+		appName   = app.NameFromContext(ctx)
+	)
+
+	copyTimeout, err := time.ParseDuration(flag.GetString(ctx, "copy-timeout"))
+	if err != nil {
+		return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("invalid --copy-timeout: %w", err))
+	}
+
+	app, err := appFromMachineOrName(ctx, machineID, appName)
+	if err != nil {
+		return err
+	}
+
+	// fetched by name for everything the machine's app leaves out
+	if app, err = client.FromContext(ctx).API().GetAppCompact(ctx, app.Name); err != nil {
+		return err
+	}
+
+	if ctx, err = apps.BuildContext(ctx, app); err != nil {
+		return err
+	}
+
+	machine, err := flaps.FromContext(ctx).Get(ctx, machineID)
+	if err != nil {
+		return fmt.Errorf("could not get machine %s: %w", machineID, err)
+	}
+
+	migration, err := readMigration(machineID)
+	if err != nil {
+		return err
+	}
+
+	if migration != nil {
+		if size := flag.GetInt(ctx, "to-size"); size != 0 && size != migration.SizeGb {
+			return fmt.Errorf("a migration of machine %s to a %dGB volume is in progress; resume it, or discard it by removing %s",
+				machineID, migration.SizeGb, migrationPath(machineID))
+		}
+		fmt.Fprintf(io.Out, "Resuming the migration of volume %s of machine %s, done with step %s\n", migration.SourceVolume, machineID, migration.Step)
+	} else {
+		if migration, err = newVolumeMigration(ctx, app, machine); err != nil {
+			return err
+		}
+		if err := migration.record(migrationStarted); err != nil {
+			return err
+		}
+	}
+
+	if err := migrateVolume(ctx, app, machine, migration, copyTimeout); err != nil {
+		return fmt.Errorf("%w\nThe original volume %s is untouched; run the command again to resume the migration", err, migration.SourceVolume)
+	}
+
+	if err := os.Remove(migrationPath(machineID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Machine %s now runs with volume %s of %dGB\n", machineID, migration.TargetVolume, migration.SizeGb)
+
+	return offerSourceVolumeDestroy(ctx, migration)
+}
+
+// newVolumeMigration plans the migration of the volume of machine the flags
+// select.
+func newVolumeMigration(ctx context.Context, app *api.AppCompact, machine *api.Machine) (*volumeMigration, error) {
+	var (
+		size     = flag.GetInt(ctx, "to-size")
+		volumeID = flag.GetString(ctx, "volume")
+	)
+
+	if size < 1 {
+		return nil, flyerr.WithCode(flyerr.CodeValidation, errors.New("--to-size must be given as a number of GB of at least 1"))
+	}
+
+	var mount *api.MachineMount
+	for i, m := range machine.Config.Mounts {
+		if volumeID == "" || m.Volume == volumeID {
+			if mount != nil {
+				return nil, fmt.Errorf("machine %s has more than one volume; select the one to migrate with --volume", machine.ID)
+			}
+			mount = &machine.Config.Mounts[i]
+		}
+	}
+
+	switch {
+	case mount == nil && volumeID != "":
+		return nil, fmt.Errorf("volume %s is not attached to machine %s", volumeID, machine.ID)
+	case mount == nil:
+		return nil, fmt.Errorf("machine %s has no volume to migrate", machine.ID)
+	case mount.SizeGb == size:
+		return nil, fmt.Errorf("volume %s is already %dGB", mount.Volume, size)
+	}
+
+	return &volumeMigration{
+		App:          app.Name,
+		MachineID:    machine.ID,
+		MountPath:    mount.Path,
+		SourceVolume: mount.Volume,
+		SizeGb:       size,
+	}, nil
+}
+
+func migrateVolume(ctx context.Context, app *api.AppCompact, machine *api.Machine, migration *volumeMigration, copyTimeout time.Duration) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+	)
+
+	source, err := apiClient.GetVolume(ctx, migration.SourceVolume)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volume %s: %w", migration.SourceVolume, err)
+	}
+
+	if !migration.done(migrationCreated) {
+		fmt.Fprintf(io.Out, "Creating a %dGB volume in %s\n", migration.SizeGb, machine.Region)
+
+		target, err := apiClient.CreateVolume(ctx, api.CreateVolumeInput{
+			AppID:     app.ID,
+			Name:      source.Name,
+			Region:    machine.Region,
+			SizeGb:    migration.SizeGb,
+			Encrypted: source.Encrypted,
+		})
+		if err != nil {
+			return fmt.Errorf("failed creating the new volume: %w", err)
+		}
+
+		migration.TargetVolume = target.ID
+		if err := migration.record(migrationCreated); err != nil {
+			return err
+		}
+	}
+
+	if !migration.done(migrationDetached) {
+		if err := stopForSwap(ctx, machine); err != nil {
+			return err
+		}
+
+		// volumes are only ever attached to a single machine, so the copy
+		// may only mount the source volume once it's detached
+		if err := remountVolume(ctx, app, machine, migration, nil); err != nil {
+			return err
+		}
+		if err := migration.record(migrationDetached); err != nil {
+			return err
+		}
+	}
+
+	if !migration.done(migrationCopied) {
+		fmt.Fprintf(io.Out, "Copying the data of volume %s to volume %s\n", migration.SourceVolume, migration.TargetVolume)
+
+		if err := copyVolume(ctx, app, machine.Region, migration, copyTimeout); err != nil {
+			return reattachSourceVolume(ctx, app, machine, migration, source, err)
+		}
+		if err := migration.record(migrationCopied); err != nil {
+			return err
+		}
+	}
+
+	if !migration.done(migrationSwapped) {
+		target, err := apiClient.GetVolume(ctx, migration.TargetVolume)
+		if err != nil {
+			return fmt.Errorf("failed retrieving volume %s: %w", migration.TargetVolume, err)
+		}
+
+		if err := remountVolume(ctx, app, machine, migration, target); err != nil {
+			fmt.Fprintf(io.ErrOut, "Machine %s failed to come up with volume %s\n", machine.ID, target.ID)
+
+			return reattachSourceVolume(ctx, app, machine, migration, source, err)
+		}
+		if err := migration.record(migrationSwapped); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyVolume copies the data of the source volume of migration to its target
+// volume with a machine mounting both, which is destroyed once done.
+func copyVolume(ctx context.Context, app *api.AppCompact, region string, migration *volumeMigration, timeout time.Duration) error {
+	var (
+		io          = iostreams.FromContext(ctx)
+		flapsClient = flaps.FromContext(ctx)
+	)
+
+	guest := *api.MachinePresets["shared-cpu-1x"]
+
+	copier, err := flapsClient.Launch(ctx, api.LaunchMachineInput{
+		AppID:   app.Name,
+		OrgSlug: app.Organization.ID,
+		Region:  region,
+		Config: &api.MachineConfig{
+			Image: migrationCopierImage,
+			Init: api.MachineInit{
+				Exec: []string{"/bin/sh", "-c", "cp -a /source/. /target/ && sync"},
+			},
+			Mounts: []api.MachineMount{
+				{Volume: migration.SourceVolume, Path: "/source"},
+				{Volume: migration.TargetVolume, Path: "/target"},
+			},
+			Restart: api.MachineRestart{Policy: api.MachineRestartPolicyNo},
+			Metadata: map[string]string{
+				"process_group": migrationCopierGroup,
+			},
+			Guest: &guest,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed launching the machine copying the volume: %w", err)
+	}
+	defer func() {
+		// the migration may have been aborted by the user, in which case ctx
+		// is already done
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if err := flapsClient.Destroy(ctx, api.RemoveMachineInput{ID: copier.ID, Kill: true}); err != nil {
+			fmt.Fprintf(io.ErrOut, "Failed destroying machine %s which copied the volume: %v\n", copier.ID, err)
+		}
+	}()
+
+	if err := mach.WaitForStartOrStop(ctx, copier, "stop", timeout); err != nil {
+		return fmt.Errorf("copying the volume did not finish: %w", err)
+	}
+
+	copier, err = flapsClient.Get(ctx, copier.ID)
+	if err != nil {
+		return fmt.Errorf("could not get machine %s which copied the volume: %w", copier.ID, err)
+	}
+
+	switch exit := copier.LastExit(); {
+	case exit == nil:
+		return fmt.Errorf("machine %s which copied the volume stopped without exiting", copier.ID)
+	case exit.ExitCode != 0:
+		return fmt.Errorf("copying the volume failed with exit code %d; check the logs of machine %s, and that the data fits the new volume", exit.ExitCode, copier.ID)
+	}
+
+	return nil
+}
+
+// remountVolume updates machine under a lease to mount volume at the mount
+// path of migration in place of whichever volume is mounted there, if any,
+// and waits for it to start and pass its health checks. A nil volume just
+// detaches the mounted one, leaving the machine stopped.
+func remountVolume(ctx context.Context, app *api.AppCompact, machine *api.Machine, migration *volumeMigration, volume *api.Volume) error {
+	return mach.WithLease(ctx, machine, func(ctx context.Context, machine *api.Machine) error {
+		conf, err := mach.CloneConfig(*machine.Config)
+		if err != nil {
+			return err
+		}
+
+		var mounts []api.MachineMount
+		for _, m := range conf.Mounts {
+			if m.Path != migration.MountPath {
+				mounts = append(mounts, m)
+			}
+		}
+		if volume != nil {
+			mounts = append(mounts, api.MachineMount{
+				Volume:    volume.ID,
+				Path:      migration.MountPath,
+				SizeGb:    volume.SizeGb,
+				Encrypted: volume.Encrypted,
+			})
+		}
+		conf.Mounts = mounts
+
+		return mach.Update(ctx, machine, &api.LaunchMachineInput{
+			AppID:      app.Name,
+			Name:       machine.Name,
+			Region:     machine.Region,
+			Config:     conf,
+			SkipLaunch: volume == nil,
+		})
+	})
+}
+
+// reattachSourceVolume mounts the source volume of migration on machine
+// again after the migration failed with cause, so that the migration resumes
+// from the copy the next time around.
+func reattachSourceVolume(ctx context.Context, app *api.AppCompact, machine *api.Machine, migration *volumeMigration, source *api.Volume, cause error) error {
+	io := iostreams.FromContext(ctx)
+
+	fmt.Fprintf(io.ErrOut, "Reattaching the original volume %s to machine %s\n", source.ID, machine.ID)
+
+	if err := remountVolume(ctx, app, machine, migration, source); err != nil {
+		return fmt.Errorf("%w; reattaching volume %s failed too: %v", cause, source.ID, err)
+	}
+
+	if err := migration.reset(); err != nil {
+		return err
+	}
+
+	return cause
+}
+
+// offerSourceVolumeDestroy destroys the volume migrated from when
+// --destroy-source is given, once confirmed.
+func offerSourceVolumeDestroy(ctx context.Context, migration *volumeMigration) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		keep      = fmt.Sprintf("Kept the original volume %s; destroy it with `fly volumes destroy %s`\n", migration.SourceVolume, migration.SourceVolume)
+	)
+
+	if !flag.GetBool(ctx, "destroy-source") {
+		fmt.Fprint(io.Out, keep)
+
+		return nil
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Destroy the original volume %s?", migration.SourceVolume); {
+		case err == nil && !confirmed:
+			fmt.Fprint(io.Out, keep)
+
+			return nil
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		case err != nil:
+			return err
+		}
+	}
+
+	if _, err := apiClient.DeleteVolume(ctx, migration.SourceVolume); err != nil {
+		return fmt.Errorf("failed destroying volume %s: %w", migration.SourceVolume, err)
+	}
+
+	fmt.Fprintf(io.Out, "Destroyed the original volume %s\n", migration.SourceVolume)
+
+	return nil
+}
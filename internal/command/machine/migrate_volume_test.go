@@ -0,0 +1,74 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withMigrationsDir(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	prev := migrationsDir
+	migrationsDir = func() string { return dir }
+	t.Cleanup(func() { migrationsDir = prev })
+}
+
+func TestVolumeMigrationDone(t *testing.T) {
+	m := &volumeMigration{Step: migrationDetached}
+
+	assert.True(t, m.done(migrationStarted))
+	assert.True(t, m.done(migrationCreated))
+	assert.True(t, m.done(migrationDetached))
+	assert.False(t, m.done(migrationCopied))
+	assert.False(t, m.done(migrationSwapped))
+
+	m.Step = migrationSwapped
+	for _, step := range migrationSteps {
+		assert.True(t, m.done(step), step)
+	}
+
+	// an unknown step, as recorded by another version, is done with nothing
+	m.Step = "unknown"
+	assert.False(t, m.done(migrationStarted))
+}
+
+func TestVolumeMigrationRecord(t *testing.T) {
+	withMigrationsDir(t)
+
+	m, err := readMigration("m1")
+	require.NoError(t, err)
+	assert.Nil(t, m, "no migration in progress")
+
+	recorded := &volumeMigration{
+		App:          "app",
+		MachineID:    "m1",
+		MountPath:    "/data",
+		SourceVolume: "vol_source",
+		TargetVolume: "vol_target",
+		SizeGb:       10,
+	}
+	require.NoError(t, recorded.record(migrationCopied))
+
+	m, err = readMigration("m1")
+	require.NoError(t, err)
+	assert.Equal(t, recorded, m)
+}
+
+func TestVolumeMigrationReset(t *testing.T) {
+	withMigrationsDir(t)
+
+	m := &volumeMigration{MachineID: "m1", SourceVolume: "vol_source", TargetVolume: "vol_target"}
+	require.NoError(t, m.record(migrationCopied))
+	require.NoError(t, m.reset())
+
+	// the new volume is kept, while detaching and copying happen again
+	m, err := readMigration("m1")
+	require.NoError(t, err)
+	assert.Equal(t, migrationCreated, m.Step)
+	assert.Equal(t, "vol_target", m.TargetVolume)
+	assert.True(t, m.done(migrationCreated))
+	assert.False(t, m.done(migrationDetached))
+}
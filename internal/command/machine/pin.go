@@ -0,0 +1,64 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/internal/build/imgsrc"
+)
+
+// releaseImage returns the image machine ran as of the given release version
+// of the app, as a reference pinned to its digest so that it can't move.
+func releaseImage(ctx context.Context, appName string, version int, machine *api.Machine) (string, error) {
+	apiClient := client.FromContext(ctx).API()
+
+	// releases are listed newest first, so the latest one tells how many
+	// have to be listed to reach version
+	latest, err := apiClient.GetAppReleases(ctx, appName, 1)
+	if err != nil {
+		return "", fmt.Errorf("failed retrieving the releases of %s: %w", appName, err)
+	}
+	if len(latest) == 0 || version < 1 || version > latest[0].Version {
+		return "", fmt.Errorf("%s has no release v%d", appName, version)
+	}
+
+	releases, err := apiClient.GetAppReleases(ctx, appName, latest[0].Version-version+1)
+	if err != nil {
+		return "", fmt.Errorf("failed retrieving the releases of %s: %w", appName, err)
+	}
+
+	var release *api.Release
+	for i := range releases {
+		if releases[i].Version == version {
+			release = &releases[i]
+		}
+	}
+	if release == nil {
+		return "", fmt.Errorf("%s has no release v%d", appName, version)
+	}
+
+	ref := release.ImageRef
+	if release.Metadata != nil && machine.Config != nil {
+		// process groups with builds of their own ran images of their own
+		if groupRef, ok := release.Metadata.GroupImages[machine.Config.Metadata["process_group"]]; ok {
+			ref = groupRef
+		}
+	}
+	if ref == "" {
+		return "", fmt.Errorf("release v%d of %s records no image", version, appName)
+	}
+
+	if strings.Contains(ref, "@") {
+		return ref, nil
+	}
+
+	digest, err := imgsrc.FetchImageDigest(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed resolving the digest of %s, the image of release v%d: %w", ref, version, err)
+	}
+
+	return ref + "@" + digest, nil
+}
@@ -2,32 +2,56 @@ package machine
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/superfly/flyctl/agent"
+
 	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
-	"github.com/superfly/flyctl/internal/prompt"
-	"github.com/superfly/flyctl/proxy"
+	"github.com/superfly/flyctl/iostreams"
 )
 
 func newProxy() *cobra.Command {
 	const (
-		short = "Establish a proxy to the Machine API through a Wireguard tunnel for local connections"
-		long  = short + "\n"
+		short = "Proxy authenticated requests to the Machines API of an app"
+		long  = short + `
+
+Starts an HTTP server on localhost which forwards requests to the Machines API
+through a WireGuard tunnel, adding your credentials to each request. Requests
+are logged to stderr. Press Ctrl-C to stop the proxy.
+`
 
 		usage = "api-proxy"
 	)
 
-	cmd := command.New(usage, short, long, runMachineProxy, command.RequireSession)
+	cmd := command.New(usage, short, long, runMachineProxy,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
 
 	flag.Add(cmd,
-		flag.Org(),
+		flag.App(),
+		flag.AppConfig(),
+		flag.Int{
+			Name:        "port",
+			Shorthand:   "p",
+			Default:     4280,
+			Description: "Local port to listen on",
+		},
 		flag.Bool{
 			Name:        "quiet",
 			Shorthand:   "q",
-			Description: "Don't print progress indicators for WireGuard",
+			Description: "Don't log proxied requests",
 		},
 	)
 
@@ -35,48 +59,79 @@ func newProxy() *cobra.Command {
 }
 
 func runMachineProxy(ctx context.Context) error {
-	apiClient := client.FromContext(ctx).API()
-	orgSlug := flag.GetOrg(ctx)
-
-	if orgSlug == "" {
-		org, err := prompt.Org(ctx)
-		if err != nil {
-			return err
-		}
-		orgSlug = org.Slug
-	}
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		appName   = app.NameFromContext(ctx)
+	)
 
-	if orgSlug != "" {
-		_, err := apiClient.GetOrganizationBySlug(ctx, orgSlug)
-		if err != nil {
-			return err
-		}
+	app, err := apiClient.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("could not get app %s: %w", appName, err)
 	}
 
-	agentclient, err := agent.Establish(ctx, apiClient)
+	flapsClient, err := flaps.New(ctx, app)
 	if err != nil {
 		return err
 	}
 
-	// do this explicitly so we can get the DNS server address
-	_, err = agentclient.Establish(ctx, orgSlug)
+	// only ever listen on the loopback interface; anyone able to reach the
+	// proxy gets to act with the user's credentials
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(flag.GetInt(ctx, "port")))
+
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed listening on %s: %w", addr, err)
 	}
 
-	dialer, err := agentclient.ConnectToTunnel(ctx, orgSlug)
-	if err != nil {
+	var handler http.Handler = flapsClient.NewReverseProxy()
+	if !flag.GetBool(ctx, "quiet") {
+		handler = logRequests(io, handler)
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(io.Out, "Proxying the Machines API for %s at http://%s/v1/apps/%s/machines\n", app.Name, listener.Addr(), app.Name)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errc:
 		return err
+	case <-ctx.Done():
 	}
 
-	// ports := strings.Split(args[0], ":")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	params := &proxy.ConnectParams{
-		Ports:            []string{"4280"},
-		OrganizationSlug: orgSlug,
-		Dialer:           dialer,
-		RemoteHost:       "_api.internal",
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
 
-	return proxy.Connect(ctx, params)
+	return nil
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func logRequests(io *iostreams.IOStreams, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		fmt.Fprintf(io.ErrOut, "%s %s %d\n", req.Method, req.URL.Path, rec.status)
+	})
 }
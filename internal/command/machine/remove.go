@@ -2,6 +2,7 @@ package machine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,15 +12,22 @@ import (
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/iostreams"
 )
 
 func newRemove() *cobra.Command {
 	const (
 		short = "Remove a Fly machine"
-		long  = short + "\n"
+		long  = short + `
 
-		usage = "remove <id>"
+With --all, removes every machine of the app, or only those which aren't
+started with --stopped-only. Machines with volumes attached are only removed
+with --force, which keeps their volumes, or --destroy-volumes, which deletes
+them too.
+`
+
+		usage = "remove [<id>]"
 	)
 
 	cmd := command.New(usage, short, long, runMachineRemove,
@@ -27,7 +35,7 @@ func newRemove() *cobra.Command {
 		command.LoadAppNameIfPresent,
 	)
 
-	cmd.Aliases = []string{"rm"}
+	cmd.Aliases = []string{"rm", "destroy"}
 
 	flag.Add(
 		cmd,
@@ -38,14 +46,41 @@ func newRemove() *cobra.Command {
 			Shorthand:   "f",
 			Description: "force kill machine if it's running",
 		},
+		flag.Bool{
+			Name:        "all",
+			Description: "Remove all machines of the app",
+		},
+		flag.Bool{
+			Name:        "stopped-only",
+			Description: "With --all, only remove machines which aren't started",
+		},
+		flag.Bool{
+			Name:        "destroy-volumes",
+			Description: "With --all, also delete the volumes attached to removed machines",
+		},
+		flag.Yes(),
 	)
 
-	cmd.Args = cobra.MinimumNArgs(1)
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		switch {
+		case all && len(args) > 0:
+			return errors.New("machine IDs may not be given along with --all")
+		case !all && len(args) != 1:
+			return errors.New("a machine ID is required, unless --all is given")
+		default:
+			return nil
+		}
+	}
 
 	return cmd
 }
 
 func runMachineRemove(ctx context.Context) (err error) {
+	if flag.GetBool(ctx, "all") {
+		return runMachineRemoveAll(ctx)
+	}
+
 	var (
 		appName   = app.NameFromContext(ctx)
 		out       = iostreams.FromContext(ctx).Out
@@ -87,7 +122,7 @@ func runMachineRemove(ctx context.Context) (err error) {
 	if err != nil {
 		switch {
 		case strings.Contains(err.Error(), "not found") && appName != "":
-			return fmt.Errorf("could not find machine %s in app %s to destroy", machineID, appName)
+			return flyerr.WithCode(flyerr.CodeNotFound, fmt.Errorf("could not find machine %s in app %s to destroy", machineID, appName))
 		default:
 			return fmt.Errorf("could not destroy machine %s: %w", machineID, err)
 		}
@@ -0,0 +1,210 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// removeConcurrency bounds the number of machines removed at once.
+const removeConcurrency = 8
+
+// removeResult is the outcome of removing a single machine.
+type removeResult struct {
+	machine *api.Machine
+	err     error
+	deleted []string
+}
+
+func runMachineRemoveAll(ctx context.Context) error {
+	var (
+		io             = iostreams.FromContext(ctx)
+		appName        = app.NameFromContext(ctx)
+		force          = flag.GetBool(ctx, "force")
+		stoppedOnly    = flag.GetBool(ctx, "stopped-only")
+		destroyVolumes = flag.GetBool(ctx, "destroy-volumes")
+	)
+
+	if appName == "" {
+		return errors.New("--all requires an app; specify one via --app or fly.toml")
+	}
+
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("could not get app %s: %w", appName, err)
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return fmt.Errorf("could not make flaps client: %w", err)
+	}
+	ctx = flaps.NewContext(ctx, flapsClient)
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	var targets []*api.Machine
+	for _, m := range machines {
+		if stoppedOnly && m.State == "started" {
+			continue
+		}
+		targets = append(targets, m)
+	}
+
+	if len(targets) == 0 {
+		fmt.Fprintf(io.Out, "No machines of %s to remove\n", appName)
+
+		return nil
+	}
+
+	var started, withVolumes []string
+	for _, m := range targets {
+		if m.State == "started" {
+			started = append(started, m.ID)
+		}
+		if len(machineMounts(m)) > 0 {
+			withVolumes = append(withVolumes, m.ID)
+		}
+	}
+	switch {
+	case len(started) > 0 && !force:
+		return fmt.Errorf("machines %s are started; stop them first, pass --stopped-only to skip them, or --force to kill them", strings.Join(started, ", "))
+	case len(withVolumes) > 0 && !force && !destroyVolumes:
+		return fmt.Errorf("machines %s have volumes attached; pass --force to keep the volumes or --destroy-volumes to delete them too", strings.Join(withVolumes, ", "))
+	}
+
+	rows := make([][]string, 0, len(targets))
+	for _, m := range targets {
+		rows = append(rows, []string{m.ID, m.Name, m.Region, m.State, mountedVolumes(m)})
+	}
+	fmt.Fprintf(io.Out, "The following %d machines of %s will be removed:\n", len(targets), appName)
+	_ = render.Table(io.Out, "", rows, "ID", "Name", "Region", "State", "Volumes")
+
+	if !flag.GetYes(ctx) {
+		msg := fmt.Sprintf("Remove %d machines?", len(targets))
+		if destroyVolumes && len(withVolumes) > 0 {
+			msg = fmt.Sprintf("Remove %d machines and delete their volumes?", len(targets))
+		}
+
+		switch confirmed, err := prompt.Confirm(ctx, msg); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	results := removeMachines(ctx, appName, targets, force, destroyVolumes)
+
+	var failed int
+	rows = rows[:0]
+	for _, r := range results {
+		outcome := "removed"
+		if len(r.deleted) > 0 {
+			outcome += fmt.Sprintf(", deleted %s", strings.Join(r.deleted, ", "))
+		}
+		if r.err != nil {
+			failed++
+			outcome = "failed: " + r.err.Error()
+		}
+		rows = append(rows, []string{r.machine.ID, r.machine.Name, r.machine.Region, outcome})
+	}
+	_ = render.Table(io.Out, "", rows, "ID", "Name", "Region", "Result")
+
+	if failed > 0 {
+		return fmt.Errorf("failed removing %d of %d machines", failed, len(results))
+	}
+
+	return nil
+}
+
+// machineMounts returns the mounts of m, of which there are none while its
+// config is unknown.
+func machineMounts(m *api.Machine) []api.MachineMount {
+	if m.Config == nil {
+		return nil
+	}
+
+	return m.Config.Mounts
+}
+
+func mountedVolumes(m *api.Machine) string {
+	mounts := machineMounts(m)
+
+	volumes := make([]string, 0, len(mounts))
+	for _, mount := range mounts {
+		volumes = append(volumes, mount.Volume)
+	}
+
+	return strings.Join(volumes, ", ")
+}
+
+// removeMachines removes machines concurrently. Failures don't stop the rest
+// of the machines from being removed.
+func removeMachines(ctx context.Context, appName string, machines []*api.Machine, kill, destroyVolumes bool) []removeResult {
+	var (
+		flapsClient = flaps.FromContext(ctx)
+		apiClient   = client.FromContext(ctx).API()
+		results     = make([]removeResult, len(machines))
+		sem         = make(chan struct{}, removeConcurrency)
+		wg          sync.WaitGroup
+	)
+
+	for i, m := range machines {
+		wg.Add(1)
+
+		go func(i int, m *api.Machine) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			r := removeResult{machine: m}
+			defer func() { results[i] = r }()
+
+			input := api.RemoveMachineInput{
+				AppID: appName,
+				ID:    m.ID,
+				Kill:  kill,
+			}
+			if r.err = flapsClient.Destroy(ctx, input); r.err != nil {
+				return
+			}
+
+			if !destroyVolumes {
+				return
+			}
+
+			for _, mount := range machineMounts(m) {
+				if _, err := apiClient.DeleteVolume(ctx, mount.Volume); err != nil {
+					r.err = fmt.Errorf("machine removed, but deleting volume %s failed: %w", mount.Volume, err)
+
+					return
+				}
+				r.deleted = append(r.deleted, mount.Volume)
+			}
+		}(i, m)
+	}
+
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,111 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/apps"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newRollback() *cobra.Command {
+	const (
+		short = "Restore a machine to the configuration recorded in a deploy snapshot"
+		long  = short + `
+
+Snapshot files are written by 'flyctl deploy --revert-on-failure' before any
+machine is updated. Use 'flyctl releases rollback-info' to inspect one.
+`
+
+		usage = "rollback <machine_id>"
+	)
+
+	cmd := command.New(usage, short, long, runMachineRollback,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.String{
+			Name:        "snapshot",
+			Description: "Path to the snapshot file written by deploy",
+		},
+		flag.Yes(),
+	)
+
+	return cmd
+}
+
+func runMachineRollback(ctx context.Context) (err error) {
+	var (
+		io        = iostreams.FromContext(ctx)
+		machineID = flag.FirstArg(ctx)
+		path      = flag.GetString(ctx, "snapshot")
+	)
+
+	if path == "" {
+		return fmt.Errorf("--snapshot is required")
+	}
+
+	snapshots, err := mach.ReadSnapshots(path)
+	if err != nil {
+		return err
+	}
+
+	snapshot, ok := snapshots.Find(machineID)
+	if !ok {
+		return fmt.Errorf("machine %s is not part of snapshot %s", machineID, path)
+	}
+
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, snapshots.App)
+	if err != nil {
+		return fmt.Errorf("could not get app %s: %w", snapshots.App, err)
+	}
+
+	ctx, err = apps.BuildContext(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	machine, err := flaps.FromContext(ctx).Get(ctx, machineID)
+	if err != nil {
+		return fmt.Errorf("could not get machine %s: %w", machineID, err)
+	}
+
+	return mach.WithLease(ctx, machine, func(ctx context.Context, machine *api.Machine) error {
+		if !flag.GetYes(ctx) {
+			confirmed, err := mach.ConfirmConfigChanges(ctx, machine, *snapshot.Config, "")
+			var noChanges *mach.ErrNoConfigChangesFound
+			switch {
+			case errors.As(err, &noChanges):
+				fmt.Fprintf(io.Out, "Machine %s already matches its snapshot\n", machineID)
+				return nil
+			case err != nil:
+				return err
+			}
+			if !confirmed {
+				fmt.Fprintf(io.Out, "No changes to apply\n")
+				return nil
+			}
+		}
+
+		if err := mach.Restore(ctx, snapshots.App, snapshot, machine.LeaseNonce); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(io.Out, "Machine %s restored to its configuration from %s\n", io.ColorScheme().Bold(machineID), snapshots.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+
+		return nil
+	})
+}
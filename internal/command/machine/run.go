@@ -31,6 +31,18 @@ import (
 	"github.com/superfly/flyctl/internal/state"
 )
 
+// gpuFlags request GPUs for machines.
+var gpuFlags = flag.Set{
+	flag.String{
+		Name:        "vm-gpu-kind",
+		Description: "Kind of GPU the machine gets, such as a100-40gb or l40s. Only some regions offer each kind",
+	},
+	flag.Int{
+		Name:        "vm-gpus",
+		Description: "Number of GPUs the machine gets, defaults to 1 along with --vm-gpu-kind",
+	},
+}
+
 var sharedFlags = flag.Set{
 	flag.App(),
 	flag.AppConfig(),
@@ -52,6 +64,7 @@ var sharedFlags = flag.Set{
 		Name:        "memory",
 		Description: "Memory (in megabytes) to attribute to the machine",
 	},
+	gpuFlags,
 	flag.StringSlice{
 		Name:        "env",
 		Shorthand:   "e",
@@ -60,7 +73,15 @@ var sharedFlags = flag.Set{
 	flag.StringSlice{
 		Name:        "volume",
 		Shorthand:   "v",
-		Description: "Volumes to mount in the form of <volume_id_or_name>:/path/inside/machine[:<options>]",
+		Description: "Volumes to mount in the form of <volume_id_or_name>:/path/inside/machine[:<options>]. With machine run, a volume name of new creates a fresh volume",
+	},
+	flag.StringSlice{
+		Name:        "file-secret",
+		Description: "Write the value of an app secret to a file of the machine, in the form of /path/inside/machine=SECRET_NAME. Can be specified multiple times.",
+	},
+	flag.Bool{
+		Name:        "skip-secret-validation",
+		Description: "Don't check that the secrets the machine refers to are set, as when they're set later on",
 	},
 	flag.String{
 		Name:        "entrypoint",
@@ -113,6 +134,14 @@ var sharedFlags = flag.Set{
 		Name:        "kernel-arg",
 		Description: "List of kernel arguments to be provided to the init. Can be specified multiple times.",
 	},
+	flag.Int{
+		Name:        "vm-swap-size",
+		Description: "Swap size (in megabytes) to enable on the machine",
+	},
+	flag.Bool{
+		Name:        "tty",
+		Description: "Allocate a TTY for the machine's init process",
+	},
 	flag.StringSlice{
 		Name:        "metadata",
 		Shorthand:   "m",
@@ -122,6 +151,18 @@ var sharedFlags = flag.Set{
 		Name:        "schedule",
 		Description: `Schedule a machine run at hourly, daily and monthly intervals`,
 	},
+	flag.String{
+		Name:        "machine-config",
+		Description: "Path to a JSON machine config to start from, or - to read it from stdin. Other flags are applied on top of it.",
+	},
+	flag.Bool{
+		Name:        "allow-unknown-fields",
+		Description: "Ignore unknown top-level fields in the --machine-config file",
+	},
+	flag.Bool{
+		Name:        "dry-run",
+		Description: "Print the resulting machine config without applying it, or building or resolving its image",
+	},
 }
 
 func newRun() *cobra.Command {
@@ -154,21 +195,40 @@ func newRun() *cobra.Command {
 			Name:        "org",
 			Description: `The organization that will own the app`,
 		},
+		flag.Bool{
+			Name:        "auto-create",
+			Description: "Create the app without asking in case it doesn't exist",
+		},
+		flag.Bool{
+			Name:        "rm",
+			Description: "Automatically destroy the machine once it exits",
+		},
+		flag.Int{
+			Name:        "wait-timeout",
+			Description: "Seconds to wait for the machine to start and, with --destroy-on-failed-checks, pass its checks",
+			Default:     300,
+		},
+		flag.Bool{
+			Name:        "destroy-on-failed-checks",
+			Description: "Destroy the machine, and any volume created for it with --volume new:, if it fails to pass its checks within --wait-timeout",
+		},
+		flag.Bool{
+			Name:        "keep-on-failure",
+			Description: "Keep the machine around for debugging when it fails its checks, despite --destroy-on-failed-checks",
+		},
 		sharedFlags,
 	)
 
-	cmd.Args = cobra.MinimumNArgs(1)
-
 	return cmd
 }
 
-func runMachineRun(ctx context.Context) error {
+func runMachineRun(ctx context.Context) (err error) {
 	var (
 		appName = app.NameFromContext(ctx)
 		client  = client.FromContext(ctx).API()
 		io      = iostreams.FromContext(ctx)
-		err     error
 		app     *api.AppCompact
+		created bool
 	)
 
 	if appName == "" {
@@ -176,19 +236,36 @@ func runMachineRun(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		created = app != nil
 	} else {
 		app, err = client.GetAppCompact(ctx, appName)
 		if err != nil && strings.Contains(err.Error(), "Could not find App") {
 			app, err = createApp(ctx, fmt.Sprintf("App '%s' does not exist, would you like to create it?", appName), appName, client)
-			if app == nil {
+			if err == nil && app == nil {
 				return nil
 			}
+			created = app != nil
 		}
 		if err != nil {
 			return err
 		}
 	}
 
+	// an app created for this run only is removed again should the machine
+	// fail to launch, so that no empty app is left behind.
+	var launched bool
+	if created {
+		defer func() {
+			if err == nil || launched {
+				return
+			}
+
+			if deleteErr := client.DeleteApp(ctx, app.Name); deleteErr != nil {
+				fmt.Fprintf(io.ErrOut, "failed deleting app %s: %v\n", app.Name, deleteErr)
+			}
+		}()
+	}
+
 	machineConf := &api.MachineConfig{
 		Guest: &api.MachineGuest{
 			CPUKind:    "shared",
@@ -198,6 +275,25 @@ func runMachineRun(ctx context.Context) error {
 		},
 	}
 
+	fileConf, err := loadMachineConfig(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if fileConf != nil {
+		if fileConf.Guest == nil {
+			fileConf.Guest = machineConf.Guest
+		}
+		machineConf = fileConf
+	}
+
+	imageOrPath := flag.FirstArg(ctx)
+	if imageOrPath == "" {
+		imageOrPath = machineConf.Image
+	}
+	if imageOrPath == "" {
+		return fmt.Errorf("an image is required, either as the first argument or in the --machine-config file")
+	}
+
 	input := api.LaunchMachineInput{
 		AppID:  app.Name,
 		Name:   flag.GetString(ctx, "name"),
@@ -219,21 +315,88 @@ func runMachineRun(ctx context.Context) error {
 		return fmt.Errorf("to update an existing machine, use 'flyctl machine update'")
 	}
 
-	machineConf, err = determineMachineConfig(ctx, *machineConf, app, flag.FirstArg(ctx))
+	machineConf, err = determineMachineConfig(ctx, *machineConf, app, imageOrPath)
 	if err != nil {
 		return err
 	}
 
+	if flag.GetBool(ctx, "dry-run") {
+		return printMachineConfig(ctx, machineConf)
+	}
+
 	if flag.GetBool(ctx, "build-only") {
 		return nil
 	}
 
+	if !flag.GetBool(ctx, "skip-secret-validation") {
+		if err := mach.ValidateSecrets(ctx, app.Name, mach.ReferencedSecrets(machineConf)); err != nil {
+			return err
+		}
+	}
+
+	if gpuRequested(machineConf) {
+		// GPUs are only offered in some regions, so the one the machine
+		// would otherwise be placed in has to be known up front
+		if input.Region == "" {
+			region, err := client.GetNearestRegion(ctx)
+			if err != nil {
+				return fmt.Errorf("failed determining the region to place the machine in: %w", err)
+			}
+			input.Region = region.Code
+		}
+
+		if err := mach.ValidateGPU(ctx, machineConf.Guest, input.Region); err != nil {
+			return err
+		}
+	}
+
+	if created {
+		if machineConf.Metadata == nil {
+			machineConf.Metadata = map[string]string{}
+		}
+		machineConf.Metadata[mach.ScratchAppMetadataKey] = "true"
+	}
+
+	if flag.GetBool(ctx, "rm") {
+		machineConf.AutoDestroy = true
+		machineConf.Restart.Policy = api.MachineRestartPolicyNo
+	}
+
+	var createdVolumes []string
+	if hasNewVolumes(machineConf) {
+		// the machine has to land where its volumes are
+		if input.Region == "" {
+			region, err := client.GetNearestRegion(ctx)
+			if err != nil {
+				return fmt.Errorf("failed determining the region to create volumes in: %w", err)
+			}
+			input.Region = region.Code
+		}
+
+		createdVolumes, err = createNewVolumes(ctx, app, machineConf, input.Region)
+		defer func() {
+			if err == nil || launched {
+				return
+			}
+
+			for _, id := range createdVolumes {
+				if _, deleteErr := client.DeleteVolume(ctx, id); deleteErr != nil {
+					fmt.Fprintf(io.ErrOut, "failed deleting volume %s: %v\n", id, deleteErr)
+				}
+			}
+		}()
+		if err != nil {
+			return err
+		}
+	}
+
 	input.Config = machineConf
 
 	machine, err := flapsClient.Launch(ctx, input)
 	if err != nil {
 		return fmt.Errorf("could not launch machine: %w", err)
 	}
+	launched = true
 
 	id, instanceID, state, privateIP := machine.ID, machine.InstanceID, machine.State, machine.PrivateIP
 
@@ -242,8 +405,14 @@ func runMachineRun(ctx context.Context) error {
 	fmt.Fprintf(io.Out, " Instance ID: %s\n", instanceID)
 	fmt.Fprintf(io.Out, " State: %s\n", state)
 
-	// wait for machine to be started
-	if err := mach.WaitForStartOrStop(ctx, machine, "start", time.Minute*5); err != nil {
+	waitTimeout := time.Duration(flag.GetInt(ctx, "wait-timeout")) * time.Second
+
+	if flag.GetBool(ctx, "destroy-on-failed-checks") {
+		if err := waitForChecks(ctx, app, machine, createdVolumes, waitTimeout); err != nil {
+			return err
+		}
+	} else if err := mach.WaitForStartOrStop(ctx, machine, "start", waitTimeout); err != nil {
+		// wait for machine to be started
 		return err
 	}
 
@@ -253,20 +422,27 @@ func runMachineRun(ctx context.Context) error {
 	return nil
 }
 
+// createApp creates a machines app for the machine to run in, after
+// confirming so unless --auto-create is set. It returns a nil app in case the
+// user declines.
 func createApp(ctx context.Context, message, name string, client *api.Client) (*api.AppCompact, error) {
-	confirm, err := prompt.Confirm(ctx, message)
-	if err != nil {
-		return nil, err
-	}
-
-	if !confirm {
-		return nil, nil
+	if !flag.GetBool(ctx, "auto-create") {
+		confirm, err := prompt.Confirm(ctx, message)
+		switch {
+		case prompt.IsNonInteractive(err):
+			return nil, prompt.NonInteractiveError("--auto-create must be specified to create the app when not running interactively")
+		case err != nil:
+			return nil, err
+		case !confirm:
+			return nil, nil
+		}
 	}
 
 	org, err := prompt.Org(ctx)
 	if err != nil {
 		return nil, err
 	}
+	prompt.AnnounceOrg(ctx, org)
 
 	if name == "" {
 		name, err = selectAppName(ctx)
@@ -278,6 +454,7 @@ func createApp(ctx context.Context, message, name string, client *api.Client) (*
 	input := api.CreateAppInput{
 		Name:           name,
 		OrganizationID: org.ID,
+		Machines:       true,
 	}
 
 	app, err := client.CreateApp(ctx, input)
@@ -292,6 +469,8 @@ func createApp(ctx context.Context, message, name string, client *api.Client) (*
 		Deployed: app.Deployed,
 		Hostname: app.Hostname,
 		AppURL:   app.AppURL,
+		// created with Machines set, the app is on the machines platform
+		PlatformVersion: "machines",
 		Organization: &api.OrganizationBasic{
 			ID:   app.Organization.ID,
 			Slug: app.Organization.Slug,
@@ -311,6 +490,31 @@ func parseKVFlag(ctx context.Context, flagName string, initialMap map[string]str
 	return parsed, nil
 }
 
+// determineFileSecrets adds the files given with --file-secret to files,
+// replacing those already written to the same paths.
+func determineFileSecrets(ctx context.Context, files []*api.MachineFile) ([]*api.MachineFile, error) {
+	for _, spec := range flag.GetStringSlice(ctx, "file-secret") {
+		guestPath, secretName, ok := strings.Cut(spec, "=")
+		if !ok || !strings.HasPrefix(guestPath, "/") || secretName == "" {
+			return nil, fmt.Errorf("invalid file secret %q, expected /path/inside/machine=SECRET_NAME", spec)
+		}
+
+		file := &api.MachineFile{GuestPath: guestPath, SecretName: api.StringPointer(secretName)}
+
+		replaced := false
+		for i, f := range files {
+			if f.GuestPath == guestPath {
+				files[i], replaced = file, true
+			}
+		}
+		if !replaced {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
 func determineImage(ctx context.Context, appName string, imageOrPath string) (img *imgsrc.DeploymentImage, err error) {
 	var (
 		client = client.FromContext(ctx).API()
@@ -463,6 +667,26 @@ func selectAppName(ctx context.Context) (name string, err error) {
 	return
 }
 
+// applyGPUFlags sets the GPUs of guest to those --vm-gpu-kind and --vm-gpus
+// request, if any.
+func applyGPUFlags(ctx context.Context, guest *api.MachineGuest) {
+	if kind := flag.GetString(ctx, "vm-gpu-kind"); kind != "" {
+		guest.GPUKind = kind
+		if guest.GPUs == 0 {
+			guest.GPUs = 1
+		}
+	}
+
+	if gpus := flag.GetInt(ctx, "vm-gpus"); gpus != 0 {
+		guest.GPUs = gpus
+	}
+}
+
+// gpuRequested reports whether the machine config asks for GPUs.
+func gpuRequested(conf *api.MachineConfig) bool {
+	return conf.Guest != nil && (conf.Guest.GPUKind != "" || conf.Guest.GPUs != 0)
+}
+
 func determineMachineConfig(ctx context.Context, initialMachineConf api.MachineConfig, app *api.AppCompact, imageOrPath string) (*api.MachineConfig, error) {
 	machineConf, err := mach.CloneConfig(initialMachineConf)
 	if err != nil {
@@ -494,8 +718,25 @@ func determineMachineConfig(ctx context.Context, initialMachineConf api.MachineC
 		machineConf.Guest.MemoryMB = memory
 	}
 
-	if len(flag.GetStringSlice(ctx, "kernel-arg")) != 0 {
-		machineConf.Guest.KernelArgs = flag.GetStringSlice(ctx, "kernel-arg")
+	applyGPUFlags(ctx, machineConf.Guest)
+
+	if kernelArgs := flag.GetStringSlice(ctx, "kernel-arg"); len(kernelArgs) != 0 {
+		if err := mach.ValidateKernelArgs(kernelArgs); err != nil {
+			return machineConf, err
+		}
+		machineConf.Guest.KernelArgs = kernelArgs
+	}
+
+	if flag.IsSpecified(ctx, "vm-swap-size") {
+		swap := flag.GetInt(ctx, "vm-swap-size")
+		if err := mach.ValidateSwapSize(swap); err != nil {
+			return machineConf, err
+		}
+		machineConf.Init.SwapSizeMB = &swap
+	}
+
+	if flag.IsSpecified(ctx, "tty") {
+		machineConf.Init.Tty = flag.GetBool(ctx, "tty")
 	}
 
 	machineConf.Env, err = parseKVFlag(ctx, "env", machineConf.Env)
@@ -517,6 +758,11 @@ func determineMachineConfig(ctx context.Context, initialMachineConf api.MachineC
 		machineConf.Metadata[k] = v
 	}
 
+	machineConf.Files, err = determineFileSecrets(ctx, machineConf.Files)
+	if err != nil {
+		return machineConf, err
+	}
+
 	services, err := determineServices(ctx)
 	if err != nil {
 		return machineConf, err
@@ -533,8 +779,8 @@ func determineMachineConfig(ctx context.Context, initialMachineConf api.MachineC
 		machineConf.Init.Entrypoint = splitted
 	}
 
-	if cmd := flag.Args(ctx)[1:]; len(cmd) > 0 {
-		machineConf.Init.Cmd = cmd
+	if args := flag.Args(ctx); len(args) > 1 {
+		machineConf.Init.Cmd = args[1:]
 	}
 
 	machineConf.Mounts, err = determineMounts(ctx, machineConf.Mounts)
@@ -542,6 +788,13 @@ func determineMachineConfig(ctx context.Context, initialMachineConf api.MachineC
 		return machineConf, err
 	}
 
+	// dry runs print the image as given, rather than building or resolving it
+	if flag.GetBool(ctx, "dry-run") {
+		machineConf.Image = imageOrPath
+
+		return machineConf, nil
+	}
+
 	img, err := determineImage(ctx, app.Name, imageOrPath)
 	if err != nil {
 		return machineConf, err
@@ -0,0 +1,180 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/watch"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// newVolume is the --volume name which calls for creating a fresh volume.
+const newVolume = "new"
+
+// cleanupTimeout bounds the cleanup which follows a machine failing its
+// checks, which runs even once the command has been interrupted.
+const cleanupTimeout = time.Minute
+
+var invalidVolumeNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+func hasNewVolumes(config *api.MachineConfig) bool {
+	for _, mount := range config.Mounts {
+		if mount.Volume == newVolume {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createNewVolumes creates a volume for every mount of config given as
+// new:/path, in region, returning the IDs of the volumes it creates.
+func createNewVolumes(ctx context.Context, app *api.AppCompact, config *api.MachineConfig, region string) (created []string, err error) {
+	apiClient := client.FromContext(ctx).API()
+
+	for i, mount := range config.Mounts {
+		if mount.Volume != newVolume {
+			continue
+		}
+
+		if mount.SizeGb == 0 {
+			mount.SizeGb = 1
+		}
+
+		vol, err := apiClient.CreateVolume(ctx, api.CreateVolumeInput{
+			AppID:     app.ID,
+			Name:      volumeNameFromPath(mount.Path),
+			Region:    region,
+			SizeGb:    mount.SizeGb,
+			Encrypted: mount.Encrypted,
+		})
+		if err != nil {
+			return created, fmt.Errorf("failed creating a volume for %s: %w", mount.Path, err)
+		}
+
+		config.Mounts[i].Volume = vol.ID
+		created = append(created, vol.ID)
+	}
+
+	return created, nil
+}
+
+// volumeNameFromPath names a volume mounted at p after the last element of p.
+func volumeNameFromPath(p string) string {
+	name := invalidVolumeNameChars.ReplaceAllString(strings.ToLower(path.Base(p)), "_")
+	if name = strings.Trim(name, "_"); name == "" || name == "." {
+		return "data"
+	}
+
+	return name
+}
+
+// waitForChecks waits up to timeout for machine to start and pass its checks.
+// Should it not, its last check outputs and log lines are printed and, unless
+// --keep-on-failure is set, it's destroyed along with the volumes in created.
+func waitForChecks(ctx context.Context, app *api.AppCompact, machine *api.Machine, created []string, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := mach.WaitForStartOrStop(waitCtx, machine, "start", timeout)
+	if err == nil {
+		err = watch.MachinesChecks(waitCtx, []*api.Machine{machine})
+	}
+	if err == nil {
+		return nil
+	}
+
+	// the command may have been interrupted by the user, in which case ctx
+	// is already done, while the cleanup mustn't leak machines
+	cleanupCtx := flaps.NewContext(context.Background(), flaps.FromContext(ctx))
+	cleanupCtx = client.NewContext(cleanupCtx, client.FromContext(ctx))
+	cleanupCtx = iostreams.NewContext(cleanupCtx, iostreams.FromContext(ctx))
+	cleanupCtx, cancelCleanup := context.WithTimeout(cleanupCtx, cleanupTimeout)
+	defer cancelCleanup()
+
+	reportFailedChecks(cleanupCtx, app, machine)
+
+	if flag.GetBool(ctx, "keep-on-failure") {
+		fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Keeping machine %s for debugging as --keep-on-failure is set\n", machine.ID)
+
+		return fmt.Errorf("machine %s failed to pass its checks: %w", machine.ID, err)
+	}
+
+	if cleanupErr := destroyFailedMachine(cleanupCtx, app, machine, created); cleanupErr != nil {
+		return fmt.Errorf("machine %s failed to pass its checks (%v) and destroying it failed: %w", machine.ID, err, cleanupErr)
+	}
+
+	return fmt.Errorf("machine %s failed to pass its checks and was destroyed: %w", machine.ID, err)
+}
+
+// reportFailedChecks prints the last outputs of the checks of machine and its
+// last log lines. Failing to retrieve either isn't fatal.
+func reportFailedChecks(ctx context.Context, app *api.AppCompact, machine *api.Machine) {
+	out := iostreams.FromContext(ctx).ErrOut
+
+	if m, err := flaps.FromContext(ctx).Get(ctx, machine.ID); err == nil && len(m.Checks) > 0 {
+		fmt.Fprintln(out, "Last check outputs")
+		table := helpers.MakeSimpleTable(out, []string{"Name", "Status", "Output"})
+		table.SetRowLine(true)
+		for _, check := range m.Checks {
+			table.Append([]string{check.Name, check.Status, check.Output})
+		}
+		table.Render()
+	}
+
+	lines, err := watch.RecentLogs(ctx, client.FromContext(ctx).API(), app.Name, machine.ID)
+	if err != nil || len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "\nLast %d log lines of machine %s:\n", len(lines), machine.ID)
+	for _, line := range lines {
+		fmt.Fprintf(out, "\t%s\n", line)
+	}
+}
+
+// destroyFailedMachine destroys machine, then the volumes in created once
+// it's gone, as volumes can't be deleted while still attached.
+func destroyFailedMachine(ctx context.Context, app *api.AppCompact, machine *api.Machine, created []string) error {
+	var (
+		out         = iostreams.FromContext(ctx).ErrOut
+		flapsClient = flaps.FromContext(ctx)
+	)
+
+	input := api.RemoveMachineInput{
+		AppID: app.Name,
+		ID:    machine.ID,
+		Kill:  true,
+	}
+	if err := flapsClient.Destroy(ctx, input); err != nil {
+		return err
+	}
+
+	if err := flapsClient.Wait(ctx, machine, "destroyed"); err != nil {
+		if len(created) == 0 {
+			return err
+		}
+
+		return fmt.Errorf("machine %s didn't reach the destroyed state, so volumes %s were kept: %w", machine.ID, strings.Join(created, ", "), err)
+	}
+	fmt.Fprintf(out, "Destroyed machine %s\n", machine.ID)
+
+	for _, id := range created {
+		if _, err := client.FromContext(ctx).API().DeleteVolume(ctx, id); err != nil {
+			return fmt.Errorf("failed deleting volume %s: %w", id, err)
+		}
+		fmt.Fprintf(out, "Deleted volume %s\n", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,425 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newSizes() *cobra.Command {
+	const (
+		short = "Commands that size machines"
+		long  = short + "\n"
+		usage = "sizes <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.Args = cobra.NoArgs
+
+	cmd.AddCommand(
+		newSizesRecommend(),
+	)
+
+	return cmd
+}
+
+func newSizesRecommend() *cobra.Command {
+	const (
+		short = "Recommend machine sizes based on observed usage"
+		long  = short + `
+
+Recommends the cheapest size fitting the 95th percentile of the CPU and memory
+usage of each machine over the last --days, with some headroom. With --group,
+machines are sized by process group instead, to fit the busiest of them.
+Machines which haven't reported metrics for long enough aren't given a
+recommendation. With --apply, the machines are resized one after another.
+`
+		usage = "recommend"
+	)
+
+	cmd := command.New(usage, short, long, runSizesRecommend,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.Int{
+			Name:        "days",
+			Default:     7,
+			Description: "Number of days of metrics to base recommendations on",
+		},
+		flag.Bool{
+			Name:        "group",
+			Description: "Recommend a size per process group rather than per machine",
+		},
+		flag.Bool{
+			Name:        "apply",
+			Description: "Resize the machines to the recommended sizes, one after another, after confirmation",
+		},
+	)
+
+	return cmd
+}
+
+const (
+	// sizingHeadroom is the margin recommendations leave over observed usage.
+	sizingHeadroom = 1.25
+
+	// sizingMinHistory is how far back metrics must go for a recommendation
+	// to be made out of them.
+	sizingMinHistory = 20 * time.Hour
+
+	// metrics are reported with a machine's ID as their instance label;
+	// CPU usage is the busy fraction of all of its CPUs
+	sizingCPUQuery     = `quantile_over_time(0.95, (sum by (instance) (rate(fly_instance_cpu{app="%[1]s",mode!="idle"}[5m])) / sum by (instance) (rate(fly_instance_cpu{app="%[1]s"}[5m])))[%[2]dd:5m])`
+	sizingMemoryQuery  = `quantile_over_time(0.95, (fly_instance_memory_mem_total{app="%[1]s"} - fly_instance_memory_mem_available{app="%[1]s"})[%[2]dd:5m])`
+	sizingHistoryQuery = `time() - min_over_time(timestamp(fly_instance_memory_mem_total{app="%[1]s"})[%[2]dd:1h])`
+)
+
+// observedUsage is the 95th percentile usage of a machine or group along
+// with how long metrics go back for. Metrics which weren't reported are nil.
+type observedUsage struct {
+	CPU         *float64
+	MemoryBytes *float64
+	History     time.Duration
+}
+
+// sizing is the recommendation for the machines of a row of the report,
+// which are either a single machine or the machines of a process group.
+type sizing struct {
+	Name          string            `json:"name"`
+	Machines      []*api.Machine    `json:"-"`
+	MachineIDs    []string          `json:"machine_ids"`
+	Current       *api.MachineGuest `json:"current"`
+	CPUPercent    *float64          `json:"p95_cpu_percent"`
+	MemoryMB      *float64          `json:"p95_memory_mb"`
+	Recommended   *api.MachineGuest `json:"recommended,omitempty"`
+	SizeName      string            `json:"recommended_size,omitempty"`
+	SavingMonth   *float32          `json:"saving_month,omitempty"`
+	NotEnoughData bool              `json:"not_enough_data"`
+}
+
+func runSizesRecommend(ctx context.Context) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		appName   = app.NameFromContext(ctx)
+		days      = flag.GetInt(ctx, "days")
+	)
+
+	if days < 1 || days > 30 {
+		return fmt.Errorf("--days must be in the [1, 30] range")
+	}
+
+	app, err := apiClient.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("could not get app %s: %w", appName, err)
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+	ctx = flaps.NewContext(ctx, flapsClient)
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("machines could not be retrieved: %w", err)
+	}
+
+	sizes, err := apiClient.PlatformVMSizes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed retrieving VM sizes: %w", err)
+	}
+
+	usage, err := fetchObservedUsage(ctx, app, days)
+	if err != nil {
+		return err
+	}
+
+	sizings := recommendSizes(mach.MachineSizes(sizes), machines, usage, flag.GetBool(ctx, "group"))
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, sizings)
+	}
+
+	if err := renderSizings(ctx, sizings); err != nil {
+		return err
+	}
+
+	if !flag.GetBool(ctx, "apply") {
+		return nil
+	}
+
+	return applySizings(ctx, app, sizings)
+}
+
+func fetchObservedUsage(ctx context.Context, app *api.AppCompact, days int) (map[string]*observedUsage, error) {
+	apiClient := client.FromContext(ctx).API()
+
+	usage := map[string]*observedUsage{}
+	forInstance := func(s api.MetricSample) *observedUsage {
+		id := s.Labels["instance"]
+		if usage[id] == nil {
+			usage[id] = &observedUsage{}
+		}
+
+		return usage[id]
+	}
+
+	queries := []struct {
+		query string
+		set   func(*observedUsage, float64)
+	}{
+		{fmt.Sprintf(sizingCPUQuery, app.Name, days), func(u *observedUsage, v float64) { u.CPU = &v }},
+		{fmt.Sprintf(sizingMemoryQuery, app.Name, days), func(u *observedUsage, v float64) { u.MemoryBytes = &v }},
+		{fmt.Sprintf(sizingHistoryQuery, app.Name, days), func(u *observedUsage, v float64) { u.History = time.Duration(v) * time.Second }},
+	}
+
+	for _, q := range queries {
+		samples, err := apiClient.QueryMetrics(ctx, app.Organization.Slug, q.query)
+		if err != nil {
+			return nil, fmt.Errorf("failed querying metrics: %w", err)
+		}
+
+		for _, s := range samples {
+			if math.IsNaN(s.Value) {
+				continue
+			}
+			q.set(forInstance(s), s.Value)
+		}
+	}
+
+	return usage, nil
+}
+
+// recommendSizes recommends sizes out of the machine sizes of the catalog for
+// machines, or for their process groups if byGroup is set.
+func recommendSizes(sizes []api.VMSize, machines []*api.Machine, usage map[string]*observedUsage, byGroup bool) []*sizing {
+	var (
+		ret     []*sizing
+		byName  = map[string]*sizing{}
+		grouped = map[string]*observedUsage{}
+	)
+
+	for _, m := range machines {
+		if m.Config == nil || m.Config.Guest == nil {
+			continue
+		}
+
+		name, u := m.ID, usage[m.ID]
+		if byGroup {
+			name = m.Config.Metadata["process_group"]
+			if name == "" {
+				name = "app"
+			}
+		}
+
+		s := byName[name]
+		if s == nil {
+			s = &sizing{Name: name, Current: m.Config.Guest}
+			byName[name] = s
+			ret = append(ret, s)
+		}
+		s.Machines = append(s.Machines, m)
+		s.MachineIDs = append(s.MachineIDs, m.ID)
+
+		// groups are sized after their busiest machines, and only have as
+		// much history as the youngest one
+		g := grouped[name]
+		switch {
+		case g == nil:
+			g = &observedUsage{}
+			if u != nil {
+				*g = *u
+			}
+			grouped[name] = g
+		case u == nil:
+			g.History = 0
+		default:
+			g.CPU = maxMetric(g.CPU, u.CPU)
+			g.MemoryBytes = maxMetric(g.MemoryBytes, u.MemoryBytes)
+			if u.History < g.History {
+				g.History = u.History
+			}
+		}
+	}
+
+	for _, s := range ret {
+		recommendSize(sizes, s, grouped[s.Name])
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	return ret
+}
+
+func recommendSize(sizes []api.VMSize, s *sizing, u *observedUsage) {
+	if u.CPU == nil || u.MemoryBytes == nil || u.History < sizingMinHistory {
+		s.NotEnoughData = true
+		return
+	}
+
+	cpuPercent := *u.CPU * 100
+	memoryMB := *u.MemoryBytes / (1 << 20)
+	s.CPUPercent, s.MemoryMB = &cpuPercent, &memoryMB
+
+	req := mach.FitRequest{
+		CPUs:     float32(*u.CPU * float64(s.Current.CPUs) * sizingHeadroom),
+		MemoryMB: int(math.Ceil(memoryMB * sizingHeadroom)),
+	}
+
+	fit, err := mach.FitVMSize(sizes, req)
+	if err != nil {
+		return
+	}
+
+	guest, ok := mach.GuestForSize(fit.Size, fit.MemoryMB)
+	if !ok {
+		return
+	}
+	s.Recommended, s.SizeName = guest, fit.Size
+
+	if current, ok := mach.GuestPrice(sizes, s.Current); ok {
+		saving := (current - fit.PriceMonth) * float32(len(s.Machines))
+		s.SavingMonth = &saving
+	}
+}
+
+func maxMetric(a, b *float64) *float64 {
+	switch {
+	case a == nil:
+		return b
+	case b == nil || *a >= *b:
+		return a
+	default:
+		return b
+	}
+}
+
+func (s *sizing) unchanged() bool {
+	return s.Recommended == nil ||
+		(s.Recommended.CPUKind == s.Current.CPUKind && s.Recommended.CPUs == s.Current.CPUs && s.Recommended.MemoryMB == s.Current.MemoryMB)
+}
+
+func renderSizings(ctx context.Context, sizings []*sizing) error {
+	io := iostreams.FromContext(ctx)
+
+	rows := make([][]string, 0, len(sizings))
+	for _, s := range sizings {
+		current := fmt.Sprintf("%s/%dMB", mach.SizeName(s.Current), s.Current.MemoryMB)
+
+		if s.NotEnoughData {
+			rows = append(rows, []string{s.Name, current, "-", "-", "not enough data", "-"})
+			continue
+		}
+
+		memory := fmt.Sprintf("%s of %dMB", humanize.IBytes(uint64(*s.MemoryMB*(1<<20))), s.Current.MemoryMB)
+
+		recommended, saving := "no size fits", "-"
+		switch {
+		case s.Recommended == nil:
+		case s.unchanged():
+			recommended = "keep"
+		default:
+			recommended = fmt.Sprintf("%s/%dMB", s.SizeName, s.Recommended.MemoryMB)
+			if s.SavingMonth != nil {
+				saving = fmt.Sprintf("$%.2f", *s.SavingMonth)
+			}
+		}
+
+		rows = append(rows, []string{s.Name, current, fmt.Sprintf("%.1f%%", *s.CPUPercent), memory, recommended, saving})
+	}
+
+	return render.Table(io.Out, "", rows, "Name", "Current", "p95 CPU", "p95 Memory", "Recommended", "Saving/mo")
+}
+
+// applySizings resizes the machines of sizings to their recommended sizes,
+// one machine after another.
+func applySizings(ctx context.Context, app *api.AppCompact, sizings []*sizing) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		targets []*sizing
+		count   int
+	)
+
+	for _, s := range sizings {
+		if !s.NotEnoughData && !s.unchanged() {
+			targets = append(targets, s)
+			count += len(s.Machines)
+		}
+	}
+
+	if count == 0 {
+		fmt.Fprintln(io.Out, "No machines to resize")
+		return nil
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Resize %d machines as recommended?", count); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	for _, s := range targets {
+		for _, m := range s.Machines {
+			err := mach.WithLease(ctx, m, func(ctx context.Context, m *api.Machine) error {
+				conf, err := mach.CloneConfig(*m.Config)
+				if err != nil {
+					return err
+				}
+
+				guest := *s.Recommended
+				if m.Config.Guest != nil {
+					guest.KernelArgs = m.Config.Guest.KernelArgs
+				}
+				conf.Guest = &guest
+
+				return mach.Update(ctx, m, &api.LaunchMachineInput{
+					AppID:  app.Name,
+					Name:   m.Name,
+					Region: m.Region,
+					Config: conf,
+				})
+			})
+			if err != nil {
+				return fmt.Errorf("failed resizing machine %s: %w", m.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -2,23 +2,34 @@ package machine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/watch"
 	"github.com/superfly/flyctl/iostreams"
 )
 
 func newStart() *cobra.Command {
 	const (
 		short = "Start one or more Fly machines"
-		long  = short + "\n"
+		long  = short + `
 
-		usage = "start <id> [<id>...]"
+With --all-staged, starts every machine of the app carrying an update staged
+via ` + "`machine update --skip-launch`" + ` and waits for their health checks.
+`
+
+		usage = "start [<id>...]"
 	)
 
 	cmd := command.New(usage, short, long, runMachineStart,
@@ -26,12 +37,26 @@ func newStart() *cobra.Command {
 		command.LoadAppNameIfPresent,
 	)
 
-	cmd.Args = cobra.MinimumNArgs(1)
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		staged, _ := cmd.Flags().GetBool("all-staged")
+		switch {
+		case staged && len(args) > 0:
+			return errors.New("machine IDs may not be given along with --all-staged")
+		case !staged && len(args) == 0:
+			return errors.New("at least one machine ID is required, unless --all-staged is given")
+		default:
+			return nil
+		}
+	}
 
 	flag.Add(
 		cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.Bool{
+			Name:        "all-staged",
+			Description: "Start all machines with a staged update",
+		},
 	)
 
 	return cmd
@@ -43,6 +68,10 @@ func runMachineStart(ctx context.Context) (err error) {
 		args = flag.Args(ctx)
 	)
 
+	if flag.GetBool(ctx, "all-staged") {
+		return startStaged(ctx)
+	}
+
 	for _, machineID := range args {
 		if err = Start(ctx, machineID); err != nil {
 			return
@@ -71,7 +100,7 @@ func Start(ctx context.Context, machineID string) (err error) {
 	if err != nil {
 		switch {
 		case strings.Contains(err.Error(), "not found") && appName != "":
-			return fmt.Errorf("machine %s was not found in app %s", machineID, appName)
+			return flyerr.WithCode(flyerr.CodeNotFound, fmt.Errorf("machine %s was not found in app %s", machineID, appName))
 		default:
 			return fmt.Errorf("could not start machine %s: %w", machineID, err)
 		}
@@ -82,3 +111,72 @@ func Start(ctx context.Context, machineID string) (err error) {
 	}
 	return
 }
+
+// startStaged starts the machines of the app which carry a staged update and
+// waits for their health checks to pass, only then clearing their markers.
+func startStaged(ctx context.Context) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		appName = app.NameFromContext(ctx)
+	)
+
+	if appName == "" {
+		return errors.New("--all-staged requires an app; specify one via --app or fly.toml")
+	}
+
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("could not get app %s: %w", appName, err)
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return fmt.Errorf("could not make flaps client: %w", err)
+	}
+	ctx = flaps.NewContext(ctx, flapsClient)
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	var staged []*api.Machine
+	for _, m := range machines {
+		if mach.IsUpdateStaged(m) {
+			staged = append(staged, m)
+		}
+	}
+
+	if len(staged) == 0 {
+		fmt.Fprintf(io.Out, "No machines of %s have a staged update\n", appName)
+
+		return nil
+	}
+
+	for _, m := range staged {
+		if err := Start(ctx, m.ID); err != nil {
+			return err
+		}
+
+		if err := mach.WaitForStartOrStop(ctx, m, "start", 5*time.Minute); err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "%s has been started\n", m.ID)
+	}
+
+	if err := watch.MachinesChecks(ctx, staged); err != nil {
+		return fmt.Errorf("failed to wait for health checks to pass: %w", err)
+	}
+
+	// the update is only done with once the machines are healthy, so that
+	// those which aren't may still be found by --all-staged
+	for _, m := range staged {
+		if err := flapsClient.DeleteMetadata(ctx, m.ID, mach.StagedUpdateMetadataKey); err != nil {
+			return fmt.Errorf("could not clear the staged update marker of %s: %w", m.ID, err)
+		}
+	}
+
+	fmt.Fprintf(io.Out, "Started %d machines with staged updates\n", len(staged))
+
+	return nil
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/alecthomas/chroma/quick"
 	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
@@ -31,6 +38,7 @@ func newStatus() *cobra.Command {
 	)
 
 	cmd.Args = cobra.ExactArgs(1)
+	cmd.Aliases = []string{"describe"}
 
 	flag.Add(
 		cmd,
@@ -41,6 +49,15 @@ func newStatus() *cobra.Command {
 			Description: "Display the machine config as JSON",
 			Shorthand:   "d",
 		},
+		flag.String{
+			Name:        "diff",
+			Description: "Compare the config of the machine with that of this other machine, exiting with 1 if they differ",
+		},
+		flag.String{
+			Name:        "diff-app",
+			Description: "The app of the machine --diff compares with, when it's not the same as that of the machine",
+		},
+		flag.Timestamps(),
 	)
 
 	return cmd
@@ -54,6 +71,10 @@ func runMachineStatus(ctx context.Context) (err error) {
 		machineID = flag.FirstArg(ctx)
 	)
 
+	if other := flag.GetString(ctx, "diff"); other != "" {
+		return runMachineDiff(ctx, machineID, other)
+	}
+
 	app, err := appFromMachineOrName(ctx, machineID, appName)
 	if err != nil {
 		return err
@@ -68,29 +89,33 @@ func runMachineStatus(ctx context.Context) (err error) {
 	if err != nil {
 		switch {
 		case strings.Contains(err.Error(), "status"):
-			return fmt.Errorf("retrieve machine failed %s", err)
+			return fmt.Errorf("retrieve machine failed %w", err)
 		case strings.Contains(err.Error(), "not found") && appName != "":
-			return fmt.Errorf("machine %s was not found in app %s", machineID, appName)
+			return flyerr.WithCode(flyerr.CodeNotFound, fmt.Errorf("machine %s was not found in app %s", machineID, appName))
 		default:
-			return fmt.Errorf("machine %s could not be retrieved", machineID)
+			return fmt.Errorf("machine %s could not be retrieved: %w", machineID, err)
 		}
 	}
 
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, machineStatus{Machine: mach.MaskMachineSecrets([]*api.Machine{machine})[0], LastExit: machine.LastExit()})
+	}
+
 	fmt.Fprintf(io.Out, "Machine ID: %s\n", machine.ID)
 	fmt.Fprintf(io.Out, "Instance ID: %s\n", machine.InstanceID)
-	fmt.Fprintf(io.Out, "State: %s\n\n", machine.State)
+	fmt.Fprintf(io.Out, "State: %s\n\n", displayState(machine))
 
 	obj := [][]string{
 		{
 			machine.ID,
 			machine.InstanceID,
-			machine.State,
+			displayState(machine),
 			machine.ImageRefWithVersion(),
 			machine.Name,
 			machine.PrivateIP,
 			machine.Region,
 			machine.Config.Metadata["process_group"],
-			fmt.Sprint(machine.Config.Guest.MemoryMB),
+			strconv.FormatUint(uint64(machine.Config.Guest.MemoryMB)<<20, 10),
 			fmt.Sprint(machine.Config.Guest.CPUs),
 			machine.CreatedAt,
 			machine.UpdatedAt,
@@ -98,17 +123,83 @@ func runMachineStatus(ctx context.Context) (err error) {
 		},
 	}
 
-	var cols []string = []string{"ID", "Instance ID", "State", "Image", "Name", "Private IP", "Region", "Process Group", "Memory", "CPUs", "Created", "Updated", "Command"}
+	absolute := flag.GetAbsoluteTimestamps(ctx)
+
+	cols := []render.Column{
+		render.Col("ID"),
+		render.Col("Instance ID"),
+		render.Col("State"),
+		render.Col("Image"),
+		render.Col("Name"),
+		render.Col("Private IP"),
+		render.Col("Region"),
+		render.Col("Process Group"),
+		render.BytesCol("Memory"),
+		render.NumberCol("CPUs"),
+		render.TimestampCol("Created", absolute),
+		render.TimestampCol("Updated", absolute),
+		render.Col("Command"),
+	}
+
+	if guest := machine.Config.Guest; guest.GPUKind != "" {
+		cols = append(cols, render.Col("GPU Kind"), render.NumberCol("GPUs"))
+		obj[0] = append(obj[0], guest.GPUKind, fmt.Sprint(guest.GPUs))
+	}
+
+	if machine.Config.Init.SwapSizeMB != nil {
+		cols = append(cols, render.BytesCol("Swap"))
+		obj[0] = append(obj[0], strconv.FormatUint(uint64(*machine.Config.Init.SwapSizeMB)<<20, 10))
+	}
+
+	if machine.Config.Init.Tty {
+		cols = append(cols, render.Col("TTY"))
+		obj[0] = append(obj[0], "true")
+	}
+
+	if len(machine.Config.Guest.KernelArgs) > 0 {
+		cols = append(cols, render.Col("Kernel Args"))
+		obj[0] = append(obj[0], strings.Join(machine.Config.Guest.KernelArgs, " "))
+	}
+
+	if machine.HostImpaired() {
+		cols = append(cols, render.Col("Host Status"))
+		obj[0] = append(obj[0], displayHostStatus(io.ColorScheme(), machine))
+		fmt.Fprintf(io.ErrOut, "The host of machine %s is %s; `fly machine clone %s` moves a copy of it to another host\n", machine.ID, machine.HostHealth(), machine.ID)
+	}
 
 	if len(machine.Config.Mounts) > 0 {
-		cols = append(cols, "Volume")
-		obj[0] = append(obj[0], machine.Config.Mounts[0].Volume)
+		cols = append(cols, render.Col("Volume"), render.Col("Encrypted"))
+		obj[0] = append(obj[0], machine.Config.Mounts[0].Volume, fmt.Sprint(machine.Config.Mounts[0].Encrypted))
+
+		// the volume's host is the machine's hardware zone
+		if volume, err := client.FromContext(ctx).API().GetVolume(ctx, machine.Config.Mounts[0].Volume); err == nil {
+			cols = append(cols, render.Col("Zone"))
+			obj[0] = append(obj[0], volume.Host.ID)
+		}
 	}
 
-	if err = render.VerticalTable(io.Out, "VM", obj, cols...); err != nil {
+	if err = render.VerticalTableWithColumns(io.Out, "VM", obj, cols...); err != nil {
 		return
 	}
 
+	if len(machine.Config.Metadata) > 0 {
+		keys := make([]string, 0, len(machine.Config.Metadata))
+		for key := range machine.Config.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		metadata := make([][]string, 0, len(keys))
+		for _, key := range keys {
+			metadata = append(metadata, []string{key, machine.Config.Metadata[key]})
+		}
+		_ = render.Table(io.Out, "Metadata", metadata, "Key", "Value")
+	}
+
+	if exit := machine.LastExit(); exit != nil {
+		renderLastExit(io, exit, absolute)
+	}
+
 	eventLogs := [][]string{}
 
 	for _, event := range machine.Events {
@@ -128,11 +219,17 @@ func runMachineStatus(ctx context.Context) (err error) {
 
 		eventLogs = append(eventLogs, fields)
 	}
-	_ = render.Table(io.Out, "Event Logs", eventLogs, "State", "Event", "Source", "Timestamp", "Info")
+	_ = render.TableWithColumns(io.Out, "Event Logs", eventLogs,
+		render.Col("State"),
+		render.Col("Event"),
+		render.Col("Source"),
+		render.TimestampCol("Timestamp", absolute),
+		render.Col("Info"),
+	)
 
 	if flag.GetBool(ctx, "display-config") {
 		var prettyConfig []byte
-		prettyConfig, err = json.MarshalIndent(machine.Config, "", "  ")
+		prettyConfig, err = json.MarshalIndent(mach.MaskScopedSecrets(machine.Config), "", "  ")
 
 		if err != nil {
 			return err
@@ -145,3 +242,49 @@ func runMachineStatus(ctx context.Context) (err error) {
 
 	return
 }
+
+// machineStatus is what status renders as JSON: the machine along with its
+// decoded last exit.
+type machineStatus struct {
+	*api.Machine
+	LastExit *api.MachineExit `json:"last_exit,omitempty"`
+}
+
+func renderLastExit(io *iostreams.IOStreams, exit *api.MachineExit, absolute bool) {
+	signal := exit.Signal
+	if signal == "" {
+		signal = "none"
+	}
+
+	rows := [][]string{{
+		fmt.Sprint(exit.ExitCode),
+		signal,
+		fmt.Sprint(exit.OOMKilled),
+		fmt.Sprint(exit.RequestedStop),
+		fmt.Sprint(exit.RootfsFailed),
+		fmt.Sprint(exit.MountFailed),
+		exit.At.Format(time.RFC3339),
+	}}
+
+	cols := []render.Column{
+		render.Col("Exit Code"),
+		render.Col("Signal"),
+		render.Col("OOM Killed"),
+		render.Col("Requested Stop"),
+		render.Col("Rootfs Failed"),
+		render.Col("Mount Failed"),
+		render.TimestampCol("Exited", absolute),
+	}
+
+	if exit.Error != "" {
+		cols = append(cols, render.Col("Error"))
+		rows[0] = append(rows[0], exit.Error)
+	}
+
+	_ = render.VerticalTableWithColumns(io.Out, "Last Exit", rows, cols...)
+
+	if hint := exit.Hint(); hint != "" {
+		fmt.Fprintln(io.Out, io.ColorScheme().Yellow("Hint: "+hint))
+		fmt.Fprintln(io.Out)
+	}
+}
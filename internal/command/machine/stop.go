@@ -11,6 +11,7 @@ import (
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/iostreams"
 )
 
@@ -79,7 +80,7 @@ func Stop(ctx context.Context, machineID string) (err error) {
 	if err != nil {
 		switch {
 		case strings.Contains(err.Error(), "not found") && appName != "":
-			return fmt.Errorf("machine %s was not found in app %s", machineID, appName)
+			return flyerr.WithCode(flyerr.CodeNotFound, fmt.Errorf("machine %s was not found in app %s", machineID, appName))
 		default:
 			return fmt.Errorf("could not stop machine %s: %w", machineStopInput.ID, err)
 		}
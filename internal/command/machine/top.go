@@ -0,0 +1,255 @@
+package machine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/dustin/go-humanize"
+	"github.com/inancgumus/screen"
+	"github.com/morikuni/aec"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newTop() *cobra.Command {
+	const (
+		short = "Show a live view of resource usage across an app's machines"
+		long  = short + `
+
+Refreshes every few seconds when attached to a terminal; otherwise prints a
+single snapshot. Machines without recent metrics are shown with dashes.
+`
+
+		usage = "top"
+	)
+
+	cmd := command.New(usage, short, long, runMachineTop,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "sort",
+			Default:     "cpu",
+			Description: "Sort machines by cpu or mem",
+		},
+		flag.Int{
+			Name:        "rate",
+			Default:     5,
+			Description: "Refresh rate in seconds",
+		},
+	)
+
+	return cmd
+}
+
+// machineUsage holds the resource usage of a single machine. Metrics which
+// haven't been reported recently are nil.
+type machineUsage struct {
+	Machine  *api.Machine
+	CPU      *float64
+	MemUsed  *float64
+	MemTotal *float64
+	RX       *float64
+	TX       *float64
+}
+
+const (
+	// metrics are reported with a machine's ID as their instance label
+	topCPUQuery      = `100 * sum by (instance) (rate(fly_instance_cpu{app="%[1]s",mode!="idle"}[1m])) / sum by (instance) (rate(fly_instance_cpu{app="%[1]s"}[1m]))`
+	topMemTotalQuery = `fly_instance_memory_mem_total{app="%s"}`
+	topMemUsedQuery  = `fly_instance_memory_mem_total{app="%[1]s"} - fly_instance_memory_mem_available{app="%[1]s"}`
+	topRXQuery       = `sum by (instance) (rate(fly_instance_net_recv_bytes{app="%s"}[1m]))`
+	topTXQuery       = `sum by (instance) (rate(fly_instance_net_sent_bytes{app="%s"}[1m]))`
+)
+
+func runMachineTop(ctx context.Context) (err error) {
+	var (
+		io      = iostreams.FromContext(ctx)
+		appName = app.NameFromContext(ctx)
+		sortBy  = flag.GetString(ctx, "sort")
+		rate    = flag.GetInt(ctx, "rate")
+	)
+
+	if sortBy != "cpu" && sortBy != "mem" {
+		return fmt.Errorf("invalid sort %q; must be cpu or mem", sortBy)
+	}
+
+	if rate < 1 || rate > 3600 {
+		return errors.New("--rate must be in the [1, 3600] range")
+	}
+
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("could not get app %s: %w", appName, err)
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+	ctx = flaps.NewContext(ctx, flapsClient)
+
+	if !io.IsInteractive() {
+		return renderTop(ctx, io.Out, app, sortBy)
+	}
+
+	// always give the cursor back, including when interrupted
+	fmt.Fprint(io.Out, aec.Hide)
+	defer fmt.Fprint(io.Out, aec.Show)
+
+	var buf bytes.Buffer
+	for {
+		buf.Reset()
+
+		if err := renderTop(ctx, &buf, app, sortBy); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+
+		screen.Clear()
+		screen.MoveTopLeft()
+		fmt.Fprintf(io.Out, "%s at: %s\n\n", io.ColorScheme().Bold(app.Name), time.Now().UTC().Format("15:04:05"))
+		buf.WriteTo(io.Out)
+
+		pause.For(ctx, time.Duration(rate)*time.Second)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func renderTop(ctx context.Context, w io.Writer, app *api.AppCompact, sortBy string) error {
+	usage, err := fetchMachineUsage(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	sortMachineUsage(usage, sortBy)
+
+	rows := make([][]string, 0, len(usage))
+	for _, u := range usage {
+		rows = append(rows, []string{
+			u.Machine.ID,
+			u.Machine.Region,
+			u.Machine.State,
+			formatMetric(u.CPU, func(v float64) string { return fmt.Sprintf("%.1f%%", v) }),
+			formatMemory(u.MemUsed, u.MemTotal),
+			formatMetric(u.RX, formatRate),
+			formatMetric(u.TX, formatRate),
+		})
+	}
+
+	return render.Table(w, "", rows, "ID", "Region", "State", "CPU", "Memory", "Net RX", "Net TX")
+}
+
+func fetchMachineUsage(ctx context.Context, app *api.AppCompact) ([]*machineUsage, error) {
+	var (
+		apiClient   = client.FromContext(ctx).API()
+		flapsClient = flaps.FromContext(ctx)
+	)
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("machines could not be retrieved: %w", err)
+	}
+
+	usage := make([]*machineUsage, 0, len(machines))
+	byID := make(map[string]*machineUsage, len(machines))
+	for _, m := range machines {
+		u := &machineUsage{Machine: m}
+		usage = append(usage, u)
+		byID[m.ID] = u
+	}
+
+	queries := []struct {
+		query string
+		set   func(*machineUsage, *float64)
+	}{
+		{fmt.Sprintf(topCPUQuery, app.Name), func(u *machineUsage, v *float64) { u.CPU = v }},
+		{fmt.Sprintf(topMemTotalQuery, app.Name), func(u *machineUsage, v *float64) { u.MemTotal = v }},
+		{fmt.Sprintf(topMemUsedQuery, app.Name), func(u *machineUsage, v *float64) { u.MemUsed = v }},
+		{fmt.Sprintf(topRXQuery, app.Name), func(u *machineUsage, v *float64) { u.RX = v }},
+		{fmt.Sprintf(topTXQuery, app.Name), func(u *machineUsage, v *float64) { u.TX = v }},
+	}
+
+	for _, q := range queries {
+		samples, err := apiClient.QueryMetrics(ctx, app.Organization.Slug, q.query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range samples {
+			if u, ok := byID[s.Labels["instance"]]; ok {
+				v := s.Value
+				q.set(u, &v)
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// sortMachineUsage sorts usage by the given metric, highest first. Machines
+// without the metric go last.
+func sortMachineUsage(usage []*machineUsage, by string) {
+	key := func(u *machineUsage) *float64 {
+		if by == "mem" {
+			return u.MemUsed
+		}
+		return u.CPU
+	}
+
+	sort.SliceStable(usage, func(i, j int) bool {
+		a, b := key(usage[i]), key(usage[j])
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		default:
+			return *a > *b
+		}
+	})
+}
+
+func formatMetric(v *float64, format func(float64) string) string {
+	if v == nil {
+		return "-"
+	}
+	return format(*v)
+}
+
+func formatMemory(used, total *float64) string {
+	if used == nil || total == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s/%s", humanize.IBytes(uint64(*used)), humanize.IBytes(uint64(*total)))
+}
+
+func formatRate(v float64) string {
+	return humanize.IBytes(uint64(v)) + "/s"
+}
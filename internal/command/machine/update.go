@@ -3,12 +3,14 @@ package machine
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/iostreams"
 
 	"github.com/superfly/flyctl/internal/app"
@@ -35,12 +37,33 @@ func newUpdate() *cobra.Command {
 		cmd,
 		flag.Image(),
 		sharedFlags,
+		volumeSwapFlags,
 		flag.Yes(),
 		flag.Bool{
 			Name:        "skip-health-checks",
 			Description: "Updates machine without waiting for health checks.",
 			Default:     false,
 		},
+		flag.Bool{
+			Name:        "skip-launch",
+			Description: "Update the config of a stopped machine without starting it; start it later with `machine start --all-staged`",
+		},
+		flag.Int{
+			Name:        "from-release",
+			Description: "Run the image of this release version of the app, pinning the machine to it so that deployments skip it until the pin is cleared",
+		},
+		flag.Bool{
+			Name:        "clear-pin",
+			Description: "Clear the release pin of the machine, so that deployments update it again",
+		},
+		flag.Bool{
+			Name:        "force",
+			Description: "Apply the update even if the machine changed since it was read",
+		},
+		flag.Bool{
+			Name:        "merge",
+			Description: "Merge the --machine-config file into the machine's current config instead of replacing it",
+		},
 	)
 
 	cmd.Args = cobra.ExactArgs(1)
@@ -57,6 +80,7 @@ func runUpdate(ctx context.Context) (err error) {
 		machineID        = flag.FirstArg(ctx)
 		autoConfirm      = flag.GetBool(ctx, "yes")
 		skipHealthChecks = flag.GetBool(ctx, "skip-health-checks")
+		skipLaunch       = flag.GetBool(ctx, "skip-launch")
 	)
 
 	app, err := client.GetAppCompact(ctx, appName)
@@ -76,15 +100,37 @@ func runUpdate(ctx context.Context) (err error) {
 		return err
 	}
 
-	// Acquire lease
-	machine, releaseLeaseFunc, err := mach.AcquireLease(ctx, machine)
-	defer releaseLeaseFunc(ctx, machine)
+	if skipLaunch && machine.State != "stopped" {
+		return fmt.Errorf("--skip-launch only applies to stopped machines, but %s is %s", machine.ID, machine.State)
+	}
+
+	// Start from the given machine config, if any
+	baseConf := *machine.Config
+
+	var mergeInto *api.MachineConfig
+	if flag.GetBool(ctx, "merge") {
+		if flag.GetString(ctx, "machine-config") == "" {
+			return fmt.Errorf("--merge requires --machine-config")
+		}
+		mergeInto = machine.Config
+	}
+
+	fileConf, err := loadMachineConfig(ctx, mergeInto)
 	if err != nil {
 		return err
 	}
+	if fileConf != nil {
+		if fileConf.Guest == nil {
+			fileConf.Guest = machine.Config.Guest
+		}
+		baseConf = *fileConf
+	}
 
 	// Resolve image
-	imageOrPath := machine.Config.Image
+	imageOrPath := baseConf.Image
+	if imageOrPath == "" {
+		imageOrPath = machine.Config.Image
+	}
 	image := flag.GetString(ctx, flag.ImageName)
 	dockerfile := flag.GetString(ctx, flag.Dockerfile().Name)
 	if len(image) > 0 {
@@ -93,12 +139,88 @@ func runUpdate(ctx context.Context) (err error) {
 		imageOrPath = "." // cwd
 	}
 
+	pinVersion := flag.GetInt(ctx, "from-release")
+	if pinVersion != 0 {
+		if len(image) > 0 || len(dockerfile) > 0 {
+			return fmt.Errorf("--from-release can't be combined with --image or --dockerfile")
+		}
+		if flag.GetBool(ctx, "clear-pin") {
+			return fmt.Errorf("--from-release and --clear-pin are mutually exclusive")
+		}
+
+		if imageOrPath, err = releaseImage(ctx, appName, pinVersion, machine); err != nil {
+			return err
+		}
+	}
+
 	// Identify configuration changes
-	machineConf, err := determineMachineConfig(ctx, *machine.Config, app, imageOrPath)
+	machineConf, err := determineMachineConfig(ctx, baseConf, app, imageOrPath)
+	if err != nil {
+		return err
+	}
+
+	if hasNewVolumes(machineConf) {
+		return fmt.Errorf("creating volumes with --volume %s: is only supported by machine run", newVolume)
+	}
+
+	if flag.IsSpecified(ctx, "vm-gpu-kind") || flag.IsSpecified(ctx, "vm-gpus") {
+		if err := mach.ValidateGPU(ctx, machineConf.Guest, machine.Region); err != nil {
+			return err
+		}
+	}
+
+	if !flag.GetBool(ctx, "skip-secret-validation") {
+		if err := mach.ValidateSecrets(ctx, app.Name, mach.ReferencedSecrets(machineConf)); err != nil {
+			return err
+		}
+	}
+
+	// Acquire lease
+	read := machine
+	machine, releaseLeaseFunc, err := mach.AcquireLease(ctx, machine)
+	defer releaseLeaseFunc(ctx, machine)
 	if err != nil {
 		return err
 	}
 
+	// The update is based on the machine as read, so it mustn't have
+	// changed before the lease kept anyone else from changing it
+	if !flag.GetBool(ctx, "force") {
+		if err := mach.CheckUnchanged(ctx, read, machine); err != nil {
+			return flyerr.WithCode(flyerr.CodeConflict, err)
+		}
+	}
+
+	// Staged updates are marked as such until the machine is started
+	metadata := make(map[string]string, len(machineConf.Metadata)+1)
+	for k, v := range machineConf.Metadata {
+		metadata[k] = v
+	}
+	if skipLaunch {
+		metadata[mach.StagedUpdateMetadataKey] = "true"
+	} else {
+		delete(metadata, mach.StagedUpdateMetadataKey)
+	}
+	// the update is no longer the one the last deployment made
+	delete(metadata, mach.ReleaseVersionMetadataKey)
+	if pinVersion != 0 {
+		metadata[mach.PinnedReleaseMetadataKey] = strconv.Itoa(pinVersion)
+	} else if flag.GetBool(ctx, "clear-pin") {
+		delete(metadata, mach.PinnedReleaseMetadataKey)
+	}
+	machineConf.Metadata = metadata
+
+	swap := isVolumeSwap(ctx)
+	if swap {
+		if err := applyVolumeSwap(ctx, app, machine, machineConf); err != nil {
+			return err
+		}
+	}
+
+	if flag.GetBool(ctx, "dry-run") {
+		return printMachineConfig(ctx, machineConf)
+	}
+
 	// Prompt user to confirm changes
 	if !autoConfirm {
 		confirmed, err := mach.ConfirmConfigChanges(ctx, machine, *machineConf, "")
@@ -111,6 +233,14 @@ func runUpdate(ctx context.Context) (err error) {
 		}
 	}
 
+	// Volumes may only be swapped while the machine is stopped; the update
+	// starts it again
+	if swap {
+		if err := stopForSwap(ctx, machine); err != nil {
+			return err
+		}
+	}
+
 	// Perform update
 	input := &api.LaunchMachineInput{
 		ID:               machine.ID,
@@ -118,6 +248,7 @@ func runUpdate(ctx context.Context) (err error) {
 		Name:             machine.Name,
 		Region:           machine.Region,
 		Config:           machineConf,
+		SkipLaunch:       skipLaunch,
 		SkipHealthChecks: skipHealthChecks,
 	}
 	if err := mach.Update(ctx, machine, input); err != nil {
@@ -0,0 +1,157 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+var volumeSwapFlags = flag.Set{
+	flag.String{
+		Name:        "detach-volume",
+		Description: "ID of the volume to detach from the machine",
+	},
+	flag.String{
+		Name:        "attach-volume",
+		Description: "Volume to attach to the machine, as VOLUME_ID[:PATH]. PATH defaults to the path of the detached volume",
+	},
+	flag.Bool{
+		Name:        "stop-and-swap",
+		Description: "Stop the machine before swapping its volumes and start it again afterwards",
+	},
+	flag.Bool{
+		Name:        "allow-postgres",
+		Description: "Swap the volumes of machines of Postgres apps, whose cluster state lives on them",
+	},
+}
+
+// isVolumeSwap reports whether any of the volume swap flags are set.
+func isVolumeSwap(ctx context.Context) bool {
+	return flag.GetString(ctx, "detach-volume") != "" || flag.GetString(ctx, "attach-volume") != ""
+}
+
+// applyVolumeSwap detaches and attaches the volumes the flags call for to
+// conf, after verifying the swap is safe, and prints the mounts before and
+// after the swap.
+func applyVolumeSwap(ctx context.Context, app *api.AppCompact, machine *api.Machine, conf *api.MachineConfig) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+
+		detachID = flag.GetString(ctx, "detach-volume")
+		attach   = flag.GetString(ctx, "attach-volume")
+	)
+
+	if app.IsPostgresApp() && !flag.GetBool(ctx, "allow-postgres") {
+		return fmt.Errorf("refusing to swap volumes of postgres app %s since its cluster state lives on them; pass --allow-postgres to proceed anyway", app.Name)
+	}
+
+	if machine.State != "stopped" && !flag.GetBool(ctx, "stop-and-swap") {
+		return fmt.Errorf("machine %s is %s; pass --stop-and-swap to stop it while its volumes are swapped", machine.ID, machine.State)
+	}
+
+	before := append([]api.MachineMount(nil), conf.Mounts...)
+
+	var path string
+	if detachID != "" {
+		var (
+			mounts []api.MachineMount
+			found  bool
+		)
+		for _, m := range conf.Mounts {
+			if m.Volume == detachID {
+				path, found = m.Path, true
+
+				continue
+			}
+			mounts = append(mounts, m)
+		}
+
+		if !found {
+			return fmt.Errorf("volume %s is not attached to machine %s", detachID, machine.ID)
+		}
+		conf.Mounts = mounts
+	}
+
+	if attach != "" {
+		attachID, attachPath, _ := strings.Cut(attach, ":")
+		if attachPath != "" {
+			path = attachPath
+		}
+		if path == "" {
+			return fmt.Errorf("a mount path must be given as --attach-volume %s:PATH", attachID)
+		}
+
+		volume, err := apiClient.GetVolume(ctx, attachID)
+		if err != nil {
+			return fmt.Errorf("failed retrieving volume %s: %w", attachID, err)
+		}
+
+		switch {
+		case volume.Region != machine.Region:
+			return fmt.Errorf("volume %s is in region %s but machine %s is in %s", attachID, volume.Region, machine.ID, machine.Region)
+		case volume.AttachedMachine != nil:
+			return fmt.Errorf("volume %s is already attached to machine %s", attachID, volume.AttachedMachine.ID)
+		case volume.AttachedAllocation != nil:
+			return fmt.Errorf("volume %s is already attached to instance %s", attachID, volume.AttachedAllocation.IDShort)
+		}
+
+		for _, m := range conf.Mounts {
+			if m.Path == path {
+				return fmt.Errorf("machine %s already has volume %s mounted at %s; detach it with --detach-volume", machine.ID, m.Volume, path)
+			}
+		}
+
+		conf.Mounts = append(conf.Mounts, api.MachineMount{
+			Volume:    volume.ID,
+			Path:      path,
+			SizeGb:    volume.SizeGb,
+			Encrypted: volume.Encrypted,
+		})
+	}
+
+	if err := renderMounts(io.Out, "Mounts before", before); err != nil {
+		return err
+	}
+
+	return renderMounts(io.Out, "Mounts after", conf.Mounts)
+}
+
+func renderMounts(w io.Writer, title string, mounts []api.MachineMount) error {
+	rows := make([][]string, 0, len(mounts))
+	for _, m := range mounts {
+		rows = append(rows, []string{m.Volume, m.Path, fmt.Sprintf("%dGB", m.SizeGb), fmt.Sprint(m.Encrypted)})
+	}
+
+	return render.Table(w, title, rows, "Volume", "Path", "Size", "Encrypted")
+}
+
+// stopForSwap stops machine, if it's running, and waits for it to stop.
+func stopForSwap(ctx context.Context, machine *api.Machine) error {
+	if machine.State == "stopped" {
+		return nil
+	}
+
+	var (
+		io          = iostreams.FromContext(ctx)
+		flapsClient = flaps.FromContext(ctx)
+	)
+
+	fmt.Fprintf(io.Out, "Stopping machine %s\n", machine.ID)
+
+	if err := flapsClient.Stop(ctx, api.StopMachineInput{ID: machine.ID, Filters: &api.Filters{}}); err != nil {
+		return fmt.Errorf("could not stop machine %s: %w", machine.ID, err)
+	}
+
+	return mach.WaitForStartOrStop(ctx, machine, "stop", 5*time.Minute)
+}
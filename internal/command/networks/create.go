@@ -0,0 +1,61 @@
+package networks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newCreate() *cobra.Command {
+	const (
+		long = `Create a custom network in an organization. Place apps in it with
+'flyctl apps create --network <name>'.
+`
+		short = "Create a custom network"
+		usage = "create <name>"
+	)
+
+	cmd := command.New(usage, short, long, runCreate,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.Org(),
+	)
+
+	return cmd
+}
+
+func runCreate(ctx context.Context) error {
+	name := flag.FirstArg(ctx)
+
+	org, err := prompt.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	network, err := client.FromContext(ctx).API().CreateNetwork(ctx, org.ID, name)
+	if err != nil {
+		return fmt.Errorf("failed creating network %s: %w", name, err)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, network)
+	}
+
+	fmt.Fprintf(out, "Created network %s in organization %s\n", network.Name, org.Slug)
+
+	return nil
+}
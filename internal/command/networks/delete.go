@@ -0,0 +1,79 @@
+package networks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newDelete() *cobra.Command {
+	const (
+		long = `Delete a custom network of an organization. Networks which still
+have apps placed in them can't be deleted.
+`
+		short = "Delete a custom network"
+		usage = "delete <name>"
+	)
+
+	cmd := command.New(usage, short, long, runDelete,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Aliases = []string{"destroy", "rm"}
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.Yes(),
+	)
+
+	return cmd
+}
+
+func runDelete(ctx context.Context) error {
+	name := flag.FirstArg(ctx)
+
+	org, err := prompt.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	network, err := FindByName(ctx, org.Slug, name)
+	if err != nil {
+		return err
+	}
+
+	if apps := network.AppNames(); len(apps) > 0 {
+		return fmt.Errorf("network %s is still used by %d apps: %s; move or destroy them first",
+			name, len(apps), strings.Join(apps, ", "))
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Delete network %s?", name); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	if err := client.FromContext(ctx).API().DeleteNetwork(ctx, network.ID); err != nil {
+		return fmt.Errorf("failed deleting network %s: %w", name, err)
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "Deleted network %s\n", name)
+
+	return nil
+}
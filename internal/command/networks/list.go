@@ -0,0 +1,68 @@
+package networks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newList() *cobra.Command {
+	const (
+		long = `List the custom networks of an organization along with the apps
+placed in each.
+`
+		short = "List custom networks"
+	)
+
+	cmd := command.New("list", short, long, runList,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Aliases = []string{"ls"}
+
+	flag.Add(cmd,
+		flag.Org(),
+	)
+
+	return cmd
+}
+
+func runList(ctx context.Context) error {
+	org, err := prompt.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	networks, err := client.FromContext(ctx).API().GetNetworks(ctx, org.Slug)
+	if err != nil {
+		return fmt.Errorf("failed retrieving networks of organization %s: %w", org.Slug, err)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, networks)
+	}
+
+	rows := make([][]string, 0, len(networks))
+	for _, network := range networks {
+		apps := strings.Join(network.AppNames(), ", ")
+		if apps == "" {
+			apps = "-"
+		}
+
+		rows = append(rows, []string{network.Name, apps})
+	}
+
+	return render.Table(out, "", rows, "Name", "Apps")
+}
@@ -0,0 +1,51 @@
+// Package networks implements the networks command chain.
+package networks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/internal/command"
+)
+
+// New initializes and returns a new networks Command.
+func New() *cobra.Command {
+	const (
+		long = `Commands for managing the custom 6PN networks of an organization.
+Apps placed in a custom network can only reach other apps in the same network.
+`
+		short = "Manage custom 6PN networks"
+	)
+
+	cmd := command.New("networks", short, long, nil)
+
+	cmd.Aliases = []string{"network"}
+
+	cmd.AddCommand(
+		newList(),
+		newCreate(),
+		newDelete(),
+	)
+
+	return cmd
+}
+
+// FindByName returns the network of the organization with the given name.
+func FindByName(ctx context.Context, orgSlug, name string) (*api.Network, error) {
+	networks, err := client.FromContext(ctx).API().GetNetworks(ctx, orgSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving networks of organization %s: %w", orgSlug, err)
+	}
+
+	for i := range networks {
+		if networks[i].Name == name {
+			return &networks[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("organization %s has no network named %s; create it with 'flyctl networks create %s'", orgSlug, name, name)
+}
@@ -0,0 +1,106 @@
+package orgs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/internal/state"
+)
+
+func newSwitch() *cobra.Command {
+	const (
+		long = `Sets the organization commands use when none is given via --org
+or FLY_ORG, instead of prompting for one. It's stored in the config file.
+`
+		short = "Set the default organization"
+		usage = "switch [slug]"
+	)
+
+	cmd := command.New(usage, short, long, runSwitch,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(cmd,
+		flag.Bool{
+			Name:        "unset",
+			Description: "Clear the default organization, prompting for one again",
+		},
+	)
+
+	return cmd
+}
+
+func runSwitch(ctx context.Context) error {
+	var (
+		io   = iostreams.FromContext(ctx)
+		path = state.ConfigFile(ctx)
+	)
+
+	if flag.GetBool(ctx, "unset") {
+		if err := config.SetDefaultOrg(path, ""); err != nil {
+			return fmt.Errorf("failed persisting %s in %s: %w", config.DefaultOrgFileKey, path, err)
+		}
+
+		fmt.Fprintln(io.Out, "Cleared the default organization")
+
+		return nil
+	}
+
+	org, err := OrgFromFirstArgOrSelect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := config.SetDefaultOrg(path, org.Slug); err != nil {
+		return fmt.Errorf("failed persisting %s in %s: %w", config.DefaultOrgFileKey, path, err)
+	}
+
+	fmt.Fprintf(io.Out, "Switched to organization %s (%s)\n", org.Name, org.Slug)
+
+	return nil
+}
+
+func newCurrent() *cobra.Command {
+	const (
+		long = `Shows the organization commands use when none is given, and
+whether it was set via --org, FLY_ORG or fly orgs switch.
+`
+		short = "Show the organization commands default to"
+	)
+
+	return command.New("current", short, long, runCurrent)
+}
+
+func runCurrent(ctx context.Context) error {
+	var (
+		io  = iostreams.FromContext(ctx)
+		cfg = config.FromContext(ctx)
+	)
+
+	if cfg.JSONOutput {
+		return render.JSON(io.Out, map[string]string{
+			"slug":   cfg.Organization,
+			"source": cfg.OrganizationSource,
+		})
+	}
+
+	if cfg.Organization == "" {
+		fmt.Fprintln(io.Out, "No default organization; commands prompt for one. Set it with `fly orgs switch`")
+
+		return nil
+	}
+
+	fmt.Fprintf(io.Out, "%s (set by %s)\n", cfg.Organization, cfg.OrganizationSource)
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package platform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mach "github.com/superfly/flyctl/internal/machine"
+)
+
+// parseFit parses the value of --fit, such as cpu=2,mem=4096.
+func parseFit(val string) (req mach.FitRequest, err error) {
+	for _, pair := range strings.Split(val, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return req, fmt.Errorf("invalid --fit %q: expected key=value pairs such as cpu=2,mem=4096", val)
+		}
+
+		switch key {
+		case "cpu", "cpus":
+			cpus, err := strconv.ParseFloat(value, 32)
+			if err != nil || cpus <= 0 {
+				return req, fmt.Errorf("invalid --fit cpu %q: must be a positive number", value)
+			}
+			req.CPUs = float32(cpus)
+		case "mem", "memory":
+			mb, err := strconv.Atoi(value)
+			if err != nil || mb <= 0 {
+				return req, fmt.Errorf("invalid --fit mem %q: must be a positive number of MB", value)
+			}
+			req.MemoryMB = mb
+		default:
+			return req, fmt.Errorf("invalid --fit key %q: must be cpu or mem", key)
+		}
+	}
+
+	if req.CPUs == 0 && req.MemoryMB == 0 {
+		return req, fmt.Errorf("invalid --fit %q: at least one of cpu and mem is required", val)
+	}
+
+	return req, nil
+}
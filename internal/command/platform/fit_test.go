@@ -0,0 +1,25 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mach "github.com/superfly/flyctl/internal/machine"
+)
+
+func TestParseFit(t *testing.T) {
+	req, err := parseFit("cpu=2,mem=4096")
+	require.NoError(t, err)
+	assert.Equal(t, mach.FitRequest{CPUs: 2, MemoryMB: 4096}, req)
+
+	req, err = parseFit("memory=512")
+	require.NoError(t, err)
+	assert.Equal(t, mach.FitRequest{MemoryMB: 512}, req)
+
+	for _, val := range []string{"", "cpu", "cpu=0", "mem=lots", "disk=10"} {
+		_, err := parseFit(val)
+		assert.Error(t, err, val)
+	}
+}
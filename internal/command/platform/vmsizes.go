@@ -10,13 +10,17 @@ import (
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
 
 func newVMSizes() (cmd *cobra.Command) {
 	const (
-		long = `View a list of VM sizes which can be used with the FLYCTL SCALE VM command
+		long = `View a list of VM sizes which can be used with the FLYCTL SCALE VM command.
+With --fit, print the cheapest size satisfying the given cpu and memory
+instead, such as --fit cpu=2,mem=4096.
 `
 		short = "List VM Sizes"
 	)
@@ -27,20 +31,51 @@ func newVMSizes() (cmd *cobra.Command) {
 
 	cmd.Args = cobra.NoArgs
 
+	flag.Add(cmd,
+		flag.String{
+			Name:        "fit",
+			Description: "Recommend the cheapest size with at least this cpu and memory (MB), such as cpu=2,mem=4096",
+		},
+	)
+
 	return
 }
 
+// vmSize is a VM size as --json renders it.
+type vmSize struct {
+	api.VMSize
+	PriceHour float32
+}
+
 func runVMSizes(ctx context.Context) error {
 	client := client.FromContext(ctx).API()
 
+	var fit *mach.FitRequest
+	if val := flag.GetString(ctx, "fit"); val != "" {
+		req, err := parseFit(val)
+		if err != nil {
+			return err
+		}
+		fit = &req
+	}
+
 	sizes, err := client.PlatformVMSizes(ctx)
 	if err != nil {
 		return fmt.Errorf("failed retrieving sizes: %w", err)
 	}
 
+	if fit != nil {
+		return runVMSizesFit(ctx, sizes, *fit)
+	}
+
 	out := iostreams.FromContext(ctx).Out
 	if config.FromContext(ctx).JSONOutput {
-		return render.JSON(out, sizes)
+		doc := make([]vmSize, 0, len(sizes))
+		for _, size := range sizes {
+			doc = append(doc, vmSize{VMSize: size, PriceHour: size.PriceSecond * 3600})
+		}
+
+		return render.JSON(out, doc)
 	}
 
 	var rows [][]string
@@ -49,10 +84,37 @@ func runVMSizes(ctx context.Context) error {
 			size.Name,
 			cores(size),
 			memory(size),
+			memoryIncrements(size),
+			fmt.Sprintf("$%.4f", size.PriceSecond*3600),
+			fmt.Sprintf("$%.2f", size.PriceMonth),
 		})
 	}
 
-	return render.Table(out, "", rows, "Name", "CPU Cores", "Memory")
+	return render.Table(out, "", rows, "Name", "CPU Cores", "Memory", "Memory Increments", "Price/Hour", "Price/Month")
+}
+
+func runVMSizesFit(ctx context.Context, sizes []api.VMSize, req mach.FitRequest) error {
+	out := iostreams.FromContext(ctx).Out
+
+	fit, err := mach.FitVMSize(sizes, req)
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, fit)
+	}
+
+	fmt.Fprintf(out, "The cheapest VM size with %s is %s with %d MB of memory, at $%.4f/hour ($%.2f/month)\n",
+		req, fit.Size, fit.MemoryMB, fit.PriceHour, fit.PriceMonth)
+
+	if fit.Custom {
+		fmt.Fprintf(out, "Scale to it with `flyctl scale vm %s --memory %d`\n", fit.Size, fit.MemoryMB)
+	} else {
+		fmt.Fprintf(out, "Scale to it with `flyctl scale vm %s`\n", fit.Size)
+	}
+
+	return nil
 }
 
 func cores(size api.VMSize) string {
@@ -68,3 +130,26 @@ func memory(size api.VMSize) string {
 	}
 	return fmt.Sprintf("%d GB", int(size.MemoryGB))
 }
+
+// memoryIncrements sums up the amounts of memory size can be scaled to.
+func memoryIncrements(size api.VMSize) string {
+	if len(size.MemoryIncrementsMB) == 0 {
+		return "-"
+	}
+
+	min, max := size.MemoryIncrementsMB[0], size.MemoryIncrementsMB[0]
+	for _, mb := range size.MemoryIncrementsMB {
+		if mb < min {
+			min = mb
+		}
+		if mb > max {
+			max = mb
+		}
+	}
+
+	if min == max {
+		return fmt.Sprintf("%d MB", min)
+	}
+
+	return fmt.Sprintf("%d-%d MB", min, max)
+}
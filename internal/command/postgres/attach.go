@@ -14,8 +14,8 @@ import (
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
 	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/prompt"
 	"github.com/superfly/flyctl/iostreams"
@@ -28,12 +28,20 @@ type AttachParams struct {
 	DbUser       string
 	VariableName string
 	Force        bool
+	// ForceRotatePassword regenerates the credentials of an existing
+	// attachment
+	ForceRotatePassword bool
+	// SkipReachabilityCheck skips verifying the consuming app may reach the
+	// cluster before attaching
+	SkipReachabilityCheck bool
 }
 
 func newAttach() *cobra.Command {
 	const (
 		short = "Attach a postgres cluster to an app"
-		long  = short + "\n"
+		long  = short + `. Attaching again converges to the attached state, repairing
+what an earlier, partially failed attempt left behind.
+`
 		usage = "attach [POSTGRES APP]"
 	)
 
@@ -59,6 +67,14 @@ func newAttach() *cobra.Command {
 			Default:     "DATABASE_URL",
 			Description: "The environment variable name that will be added to the consuming app. ",
 		},
+		flag.Bool{
+			Name:        "force-rotate-password",
+			Description: "Regenerate the database user's password, even if the app is already attached",
+		},
+		flag.Bool{
+			Name:        "skip-reachability-check",
+			Description: "Skip verifying the consuming app can reach the cluster over its private network",
+		},
 		flag.Yes(),
 	)
 
@@ -78,7 +94,7 @@ func runAttach(ctx context.Context) error {
 	}
 
 	if !pgApp.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a postgres app", pgAppName)
+		return notPostgresAppError(pgAppName)
 	}
 
 	app, err := client.GetAppCompact(ctx, appName)
@@ -87,7 +103,7 @@ func runAttach(ctx context.Context) error {
 	}
 
 	// Build context around the postgres app
-	ctx, err = apps.BuildContext(ctx, pgApp)
+	ctx, err = buildContext(ctx, pgApp)
 	if err != nil {
 		return err
 	}
@@ -99,15 +115,18 @@ func runAttach(ctx context.Context) error {
 		DbUser:       flag.GetString(ctx, "database-user"),
 		VariableName: flag.GetString(ctx, "variable-name"),
 		Force:        flag.GetBool(ctx, "yes"),
+
+		ForceRotatePassword:   flag.GetBool(ctx, "force-rotate-password"),
+		SkipReachabilityCheck: flag.GetBool(ctx, "skip-reachability-check"),
 	}
 
 	switch pgApp.PlatformVersion {
 	case "machines":
-		return machineAttachCluster(ctx, params)
+		return machineAttachCluster(ctx, pgApp, app, params)
 	case "nomad":
-		return nomadAttachCluster(ctx, pgApp, params)
+		return nomadAttachCluster(ctx, pgApp, app, params)
 	default:
-		return fmt.Errorf("platform is not supported")
+		return flyerr.WithCode(flyerr.CodePlatformUnsupported, errors.New("platform is not supported"))
 	}
 }
 
@@ -126,44 +145,40 @@ func AttachCluster(ctx context.Context, params AttachParams) error {
 	}
 
 	if !pgApp.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a postgres app", pgAppName)
+		return notPostgresAppError(pgAppName)
 	}
 
-	ctx, err = apps.BuildContext(ctx, pgApp)
+	ctx, err = buildContext(ctx, pgApp)
 	if err != nil {
 		return err
 	}
 
 	// Verify that the target app exists.
-	_, err = client.GetAppCompact(ctx, appName)
+	app, err := client.GetAppCompact(ctx, appName)
 	if err != nil {
 		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
 	}
 
 	switch pgApp.PlatformVersion {
 	case "machines":
-		return machineAttachCluster(ctx, params)
+		return machineAttachCluster(ctx, pgApp, app, params)
 	case "nomad":
-		return nomadAttachCluster(ctx, pgApp, params)
+		return nomadAttachCluster(ctx, pgApp, app, params)
 	default:
-		return fmt.Errorf("platform is not supported")
+		return flyerr.WithCode(flyerr.CodePlatformUnsupported, errors.New("platform is not supported"))
 	}
 }
 
-func nomadAttachCluster(ctx context.Context, pgApp *api.AppCompact, params AttachParams) error {
+func nomadAttachCluster(ctx context.Context, pgApp, app *api.AppCompact, params AttachParams) error {
 	var (
 		MinPostgresHaVersion = "0.0.19"
-		client               = client.FromContext(ctx).API()
 	)
 
 	if err := hasRequiredVersionOnNomad(pgApp, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
 		return err
 	}
 
-	agentclient, err := agent.Establish(ctx, client)
-	if err != nil {
-		return errors.Wrap(err, "can't establish agent")
-	}
+	agentclient := agent.ClientFromContext(ctx)
 
 	pgInstances, err := agentclient.Instances(ctx, pgApp.Organization.Slug, pgApp.Name)
 	if err != nil {
@@ -179,10 +194,14 @@ func nomadAttachCluster(ctx context.Context, pgApp *api.AppCompact, params Attac
 		return err
 	}
 
-	return runAttachCluster(ctx, leaderIP, params)
+	if err := verifyReachability(ctx, app, pgApp, leaderIP, params); err != nil {
+		return err
+	}
+
+	return runAttachCluster(ctx, pgApp, leaderIP, params)
 }
 
-func machineAttachCluster(ctx context.Context, params AttachParams) error {
+func machineAttachCluster(ctx context.Context, pgApp, app *api.AppCompact, params AttachParams) error {
 	// Minimum image version requirements
 	var (
 		MinPostgresHaVersion = "0.0.19"
@@ -206,13 +225,27 @@ func machineAttachCluster(ctx context.Context, params AttachParams) error {
 		return err
 	}
 
-	return runAttachCluster(ctx, leader.PrivateIP, params)
+	if err := verifyReachability(ctx, app, pgApp, leader.PrivateIP, params); err != nil {
+		return err
+	}
+
+	return runAttachCluster(ctx, pgApp, leader.PrivateIP, params)
+}
+
+// verifyReachability checks app is able to reach the leader of pgApp, unless
+// told not to. The dialer of ctx is that of the organization of pgApp, which
+// is also that of app once their organizations are known to match.
+func verifyReachability(ctx context.Context, app, pgApp *api.AppCompact, leaderIP string, params AttachParams) error {
+	if params.SkipReachabilityCheck {
+		return nil
+	}
+
+	return checkReachability(ctx, agent.DialerFromContext(ctx), app, pgApp, leaderIP)
 }
 
-func runAttachCluster(ctx context.Context, leaderIP string, params AttachParams) error {
+func runAttachCluster(ctx context.Context, pgApp *api.AppCompact, leaderIP string, params AttachParams) error {
 	var (
 		client = client.FromContext(ctx).API()
-		dialer = agent.DialerFromContext(ctx)
 		io     = iostreams.FromContext(ctx)
 
 		appName   = params.AppName
@@ -248,25 +281,40 @@ func runAttachCluster(ctx context.Context, leaderIP string, params AttachParams)
 		VariableName:         api.StringPointer(varName),
 	}
 
-	pgclient := flypg.NewFromInstance(leaderIP, dialer)
+	pgclient := pgClient(ctx, leaderIP)
 
 	secrets, err := client.GetAppSecrets(ctx, input.AppID)
 	if err != nil {
 		return err
 	}
+	var hasSecret bool
 	for _, secret := range secrets {
-		if secret.Name == *input.VariableName {
-			return fmt.Errorf("consumer app %q already contains a secret named %s", input.AppID, *input.VariableName)
+		hasSecret = hasSecret || secret.Name == *input.VariableName
+	}
+
+	// An attachment record is the first thing created, so one matching these
+	// parameters means an earlier attempt got at least that far.
+	attachments, err := client.ListPostgresClusterAttachments(ctx, input.AppID, input.PostgresClusterAppID)
+	if err != nil {
+		return err
+	}
+	var attached bool
+	for _, a := range attachments {
+		if a.DatabaseName == *input.DatabaseName && a.DatabaseUser == *input.DatabaseUser && a.EnvironmentVariableName == *input.VariableName {
+			attached = true
 		}
 	}
 
+	if hasSecret && !attached {
+		return fmt.Errorf("consumer app %q already contains a secret named %s", input.AppID, *input.VariableName)
+	}
+
 	// Check to see if database exists
 	dbExists, err := pgclient.DatabaseExists(ctx, *input.DatabaseName)
 	if err != nil {
 		return err
 	}
-	if dbExists && !force {
-		confirm := false
+	if dbExists && !attached && !force {
 		msg := fmt.Sprintf("Database %q already exists. Continue with the attachment process?", *input.DatabaseName)
 		confirm, err := prompt.Confirm(ctx, msg)
 		if err != nil {
@@ -282,14 +330,36 @@ func runAttachCluster(ctx context.Context, leaderIP string, params AttachParams)
 	if err != nil {
 		return err
 	}
+
+	if attached && hasSecret && dbExists && usrExists && !params.ForceRotatePassword {
+		fmt.Fprintf(io.Out, "Postgres cluster %s is already attached to %s\n", input.PostgresClusterAppID, input.AppID)
+
+		return nil
+	}
+
+	// The password of an existing user can't be recovered, only changed
 	if usrExists {
-		return fmt.Errorf("database user %q already exists. Please specify a new database user via --database-user", *input.DatabaseUser)
+		switch {
+		case !attached:
+			return fmt.Errorf("database user %q already exists. Please specify a new database user via --database-user", *input.DatabaseUser)
+		case !hasSecret && !params.ForceRotatePassword && !force:
+			msg := fmt.Sprintf("Database user %q already exists from an earlier attempt to attach, but %s lacks its password. Change it?",
+				*input.DatabaseUser, input.AppID)
+			confirm, err := prompt.Confirm(ctx, msg)
+			if err != nil {
+				return err
+			}
+			if !confirm {
+				return nil
+			}
+		}
 	}
 
 	// Create attachment
-	_, err = client.AttachPostgresCluster(ctx, input)
-	if err != nil {
-		return err
+	if !attached {
+		if _, err = client.AttachPostgresCluster(ctx, input); err != nil {
+			return err
+		}
 	}
 
 	// Create database if it doesn't already exist
@@ -303,15 +373,23 @@ func runAttachCluster(ctx context.Context, leaderIP string, params AttachParams)
 		}
 	}
 
-	// Create user
 	pwd, err := helpers.RandString(15)
 	if err != nil {
 		return err
 	}
 
-	err = pgclient.CreateUser(ctx, *input.DatabaseUser, pwd, true)
-	if err != nil {
-		return fmt.Errorf("failed executing create-user: %w", err)
+	if usrExists {
+		// Changed in place, so the user keeps its privileges and whatever it owns
+		cmd := flypg.NewCommandWithDialer(ctx, pgApp, agent.DialerFromContext(ctx))
+		if err := cmd.UpdateUserPassword(ctx, leaderIP, *input.DatabaseUser, pwd); err != nil {
+			return fmt.Errorf("failed changing the password of %s: %w", *input.DatabaseUser, err)
+		}
+	} else {
+		// Create user; superusers have all the privileges the app expects
+		err = pgclient.CreateUser(ctx, *input.DatabaseUser, pwd, true)
+		if err != nil {
+			return fmt.Errorf("failed executing create-user: %w", err)
+		}
 	}
 
 	connectionString := fmt.Sprintf(
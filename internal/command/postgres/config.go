@@ -17,6 +17,44 @@ var pgSettings = map[string]string{
 	"shared-preload-libraries":   "shared_preload_libraries",
 }
 
+// exportedSettings are the settings exports read. The settings view of flypg
+// only returns the settings it's asked for by name, so these are those
+// commonly tuned along with the version of the server.
+var exportedSettings = []string{
+	"autovacuum",
+	"autovacuum_max_workers",
+	"autovacuum_naptime",
+	"checkpoint_completion_target",
+	"checkpoint_timeout",
+	"default_statistics_target",
+	"effective_cache_size",
+	"effective_io_concurrency",
+	"idle_in_transaction_session_timeout",
+	"log_connections",
+	"log_disconnections",
+	"log_lock_waits",
+	"log_min_duration_statement",
+	"log_statement",
+	"maintenance_work_mem",
+	"max_connections",
+	"max_parallel_maintenance_workers",
+	"max_parallel_workers",
+	"max_parallel_workers_per_gather",
+	"max_wal_size",
+	"max_worker_processes",
+	"min_wal_size",
+	"random_page_cost",
+	"server_version",
+	"shared_buffers",
+	"shared_preload_libraries",
+	"statement_timeout",
+	"temp_buffers",
+	"timezone",
+	"wal_buffers",
+	"wal_level",
+	"work_mem",
+}
+
 func newConfig() (cmd *cobra.Command) {
 	const (
 		short = "View and manage Postgres configuration."
@@ -28,6 +66,9 @@ func newConfig() (cmd *cobra.Command) {
 	cmd.AddCommand(
 		newConfigView(),
 		newConfigUpdate(),
+		newConfigBackupSettings(),
+		newConfigExport(),
+		newConfigImport(),
 	)
 
 	return
@@ -0,0 +1,254 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flypg"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// backupSettings lists the settings relevant to WAL archiving. Only one of
+// wal_keep_size (13+) and wal_keep_segments (12 and older) is reported by any
+// given server.
+var backupSettings = []string{
+	"archive_mode",
+	"archive_command",
+	"archive_timeout",
+	"wal_keep_size",
+	"wal_keep_segments",
+}
+
+func newConfigBackupSettings() (cmd *cobra.Command) {
+	const (
+		long = `Show the WAL archiving settings of the Postgres leader along with the
+outcome of its most recent archival attempts. Credentials in archive_command
+are redacted.`
+		short = "Show WAL archiving settings and status"
+		usage = "backup-settings"
+	)
+
+	cmd = command.New(usage, short, long, runConfigBackupSettings,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return
+}
+
+func runConfigBackupSettings(ctx context.Context) error {
+	var (
+		client  = client.FromContext(ctx).API()
+		appName = app.NameFromContext(ctx)
+	)
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	if !app.IsPostgresApp() {
+		return notPostgresAppError(appName)
+	}
+
+	ctx, err = buildContext(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	switch app.PlatformVersion {
+	case "machines":
+		return runMachineConfigBackupSettings(ctx)
+	case "nomad":
+		return runNomadConfigBackupSettings(ctx, app)
+	default:
+		return fmt.Errorf("unknown platform version")
+	}
+}
+
+func runMachineConfigBackupSettings(ctx context.Context) (err error) {
+	var (
+		MinPostgresHaVersion = "0.0.19"
+	)
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("machines could not be retrieved %w", err)
+	}
+
+	if err := hasRequiredVersionOnMachines(machines, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
+		return err
+	}
+
+	leader, err := pickLeader(ctx, machines)
+	if err != nil {
+		return err
+	}
+
+	return renderBackupSettings(ctx, leader.PrivateIP)
+}
+
+func runNomadConfigBackupSettings(ctx context.Context, app *api.AppCompact) (err error) {
+	var (
+		MinPostgresHaVersion = "0.0.19"
+	)
+
+	if err := hasRequiredVersionOnNomad(app, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
+		return err
+	}
+
+	agentclient := agent.ClientFromContext(ctx)
+
+	pgInstances, err := agentclient.Instances(ctx, app.Organization.Slug, app.Name)
+	if err != nil {
+		return fmt.Errorf("failed to lookup 6pn ip for %s app: %v", app.Name, err)
+	}
+	if len(pgInstances.Addresses) == 0 {
+		return fmt.Errorf("no 6pn ips found for %s app", app.Name)
+	}
+
+	leaderIP, err := leaderIpFromNomadInstances(ctx, pgInstances.Addresses)
+	if err != nil {
+		return err
+	}
+
+	return renderBackupSettings(ctx, leaderIP)
+}
+
+type backupSettingsReport struct {
+	Settings map[string]string    `json:"settings"`
+	Archiver *flypg.ArchiverStats `json:"archiver,omitempty"`
+	Failing  bool                 `json:"failing"`
+}
+
+func renderBackupSettings(ctx context.Context, leaderIP string) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		cfg      = config.FromContext(ctx)
+	)
+
+	pgclient := pgClient(ctx, leaderIP)
+
+	res, err := pgclient.ViewSettings(ctx, backupSettings)
+	if err != nil {
+		return fmt.Errorf("error fetching settings: %w", err)
+	}
+
+	report := backupSettingsReport{
+		Settings: map[string]string{},
+	}
+
+	for _, setting := range res.Settings {
+		value := setting.Setting
+		if setting.Name == "archive_command" {
+			value = redactArchiveCommand(value)
+		}
+		if setting.Unit != "" {
+			value += setting.Unit
+		}
+
+		report.Settings[setting.Name] = value
+	}
+
+	// Older images don't expose the archiver statistics; report the settings
+	// regardless.
+	switch stats, err := pgclient.ArchiverStats(ctx); {
+	case err == nil:
+		report.Archiver = stats
+		report.Failing = stats.IsFailing()
+	case flypg.ErrorStatus(err) == http.StatusNotFound:
+	default:
+		return fmt.Errorf("error fetching archiver statistics: %w", err)
+	}
+
+	if cfg.JSONOutput {
+		return render.JSON(io.Out, report)
+	}
+
+	rows := make([][]string, 0, len(backupSettings))
+	for _, name := range backupSettings {
+		if value, ok := report.Settings[name]; ok {
+			rows = append(rows, []string{name, value})
+		}
+	}
+
+	if err := render.Table(io.Out, "Settings", rows, "Name", "Value"); err != nil {
+		return err
+	}
+
+	if report.Archiver == nil {
+		fmt.Fprintln(io.Out, "Archiver statistics are not available on this image version.")
+
+		return nil
+	}
+
+	stats := report.Archiver
+	rows = [][]string{
+		{"Archived", fmt.Sprint(stats.ArchivedCount)},
+		{"Last Archived WAL", stats.LastArchivedWAL},
+		{"Last Archived", formatArchiverTime(stats.LastArchivedTime)},
+		{"Failed", fmt.Sprint(stats.FailedCount)},
+		{"Last Failed WAL", stats.LastFailedWAL},
+		{"Last Failed", formatArchiverTime(stats.LastFailedTime)},
+		{"Stats Reset", formatArchiverTime(stats.StatsReset)},
+	}
+
+	if err := render.Table(io.Out, "Archiver", rows, "", ""); err != nil {
+		return err
+	}
+
+	if report.Failing {
+		fmt.Fprintln(io.ErrOut, colorize.Red(fmt.Sprintf("WAL archiving is failing: the last attempt to archive %s failed %s.", stats.LastFailedWAL, formatArchiverTime(stats.LastFailedTime))))
+	}
+
+	return nil
+}
+
+func formatArchiverTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+
+	return humanize.Time(*t)
+}
+
+var archiveCommandSecrets = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	// credentials embedded in URLs, e.g. s3://key:secret@bucket
+	{regexp.MustCompile(`(\w+://)[^/\s:@]+:[^/\s@]+@`), "${1}<redacted>@"},
+	// environment assignments, e.g. AWS_SECRET_ACCESS_KEY=secret
+	{regexp.MustCompile(`(?i)(\b\w*(?:secret|password|passwd|token|key)\w*=)('[^']*'|"[^"]*"|\S+)`), "${1}<redacted>"},
+	// command-line options, e.g. --password secret
+	{regexp.MustCompile(`(?i)(--?\w*(?:secret|password|passwd|token|key)\w*[=\s]+)('[^']*'|"[^"]*"|\S+)`), "${1}<redacted>"},
+}
+
+// redactArchiveCommand masks credentials that may appear in archive_command.
+func redactArchiveCommand(cmd string) string {
+	for _, s := range archiveCommandSecrets {
+		cmd = s.pattern.ReplaceAllString(cmd, s.replace)
+	}
+
+	return cmd
+}
@@ -0,0 +1,433 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flypg"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// settingsFile is the document `pg config export` writes and `pg config
+// import` reads.
+type settingsFile struct {
+	App           string            `json:"app"`
+	ServerVersion string            `json:"server_version,omitempty"`
+	ExportedAt    time.Time         `json:"exported_at"`
+	Settings      map[string]string `json:"settings"`
+}
+
+// unportableSettings are identity, path and replication settings of the node
+// itself, which mustn't be carried over to another cluster.
+var unportableSettings = map[string]bool{
+	"archive_command":           true,
+	"cluster_name":              true,
+	"config_file":               true,
+	"data_directory":            true,
+	"hba_file":                  true,
+	"ident_file":                true,
+	"listen_addresses":          true,
+	"port":                      true,
+	"primary_conninfo":          true,
+	"primary_slot_name":         true,
+	"restore_command":           true,
+	"ssl_cert_file":             true,
+	"ssl_key_file":              true,
+	"synchronous_standby_names": true,
+	"unix_socket_directories":   true,
+}
+
+// isPortable reports whether setting was tuned on the cluster rather than
+// being a default, read-only, per-session or per-node setting.
+func isPortable(setting flypg.PGSetting) bool {
+	switch {
+	case setting.IsDefault():
+		return false
+	case setting.Context == "internal":
+		return false
+	case setting.Source == "client", setting.Source == "session", setting.Source == "override", setting.Source == "environment variable":
+		return false
+	default:
+		return !unportableSettings[setting.Name]
+	}
+}
+
+// newSettingsFile returns the export of the portable settings of appName
+// among settings.
+func newSettingsFile(appName string, settings []flypg.PGSetting) settingsFile {
+	export := settingsFile{
+		App:        appName,
+		ExportedAt: time.Now().UTC(),
+		Settings:   map[string]string{},
+	}
+	for _, setting := range settings {
+		if setting.Name == "server_version" {
+			export.ServerVersion = setting.Setting
+		}
+		if isPortable(setting) {
+			export.Settings[setting.Name] = setting.Setting
+		}
+	}
+
+	return export
+}
+
+func newConfigExport() (cmd *cobra.Command) {
+	const (
+		long = `Export the settings tuned on a Postgres cluster, those differing from
+their defaults, to a file which fly postgres config import applies to another
+cluster. Settings particular to the nodes of the cluster aren't exported.`
+		short = "Export the tuned settings of a Postgres cluster"
+		usage = "export"
+	)
+
+	cmd = command.New(usage, short, long, runConfigExport,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "output",
+			Shorthand:   "o",
+			Description: "Path of the file to write the settings to, instead of stdout",
+		},
+	)
+
+	return
+}
+
+func runConfigExport(ctx context.Context) error {
+	app, ctx, err := postgresApp(ctx)
+	if err != nil {
+		return err
+	}
+
+	leaderIP, err := leaderAddress(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	res, err := pgClient(ctx, leaderIP).ViewSettings(ctx, exportedSettings)
+	if err != nil {
+		return fmt.Errorf("failed querying the settings of %s: %w", app.Name, err)
+	}
+
+	export := newSettingsFile(app.Name, res.Settings)
+
+	path := flag.GetString(ctx, "output")
+	if path == "" {
+		return render.JSON(iostreams.FromContext(ctx).Out, export)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed creating %s: %w", path, err)
+	}
+
+	err = render.JSON(f, export)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed writing %s: %w", path, err)
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Wrote %d settings of %s to %s\n", len(export.Settings), app.Name, path)
+
+	return nil
+}
+
+func newConfigImport() (cmd *cobra.Command) {
+	const (
+		long = `Apply the settings fly postgres config export wrote to a Postgres
+cluster. What would change is shown before anything is applied, and the cluster
+is restarted for the settings requiring it.
+
+Settings the PostgreSQL version of the cluster doesn't know or accept abort the
+import, unless --skip-invalid is given.`
+		short = "Apply exported settings to a Postgres cluster"
+		usage = "import <path>"
+	)
+
+	cmd = command.New(usage, short, long, runConfigImport,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Detach(),
+		flag.Bool{
+			Name:        "skip-invalid",
+			Description: "Skip the settings the cluster rejects instead of aborting",
+		},
+		flag.Bool{
+			Name:        "auto-restart",
+			Description: "Restart the cluster without asking when any of the changes require it, then report the effective values",
+		},
+		flag.Yes(),
+	)
+
+	return
+}
+
+func runConfigImport(ctx context.Context) error {
+	path := flag.FirstArg(ctx)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed reading %s: %w", path, err)
+	}
+
+	var imported settingsFile
+	if err := json.Unmarshal(data, &imported); err != nil {
+		return fmt.Errorf("failed parsing %s: %w", path, err)
+	}
+
+	if len(imported.Settings) == 0 {
+		return fmt.Errorf("%s holds no settings", path)
+	}
+
+	app, ctx, err := postgresApp(ctx)
+	if err != nil {
+		return err
+	}
+
+	return applyConfig(ctx, app, func(ctx context.Context, app *api.AppCompact, leaderIP string) ([]string, error) {
+		return importSettings(ctx, app, leaderIP, &imported)
+	})
+}
+
+// settingChange is a setting import changes, from the value it has now.
+type settingChange struct {
+	setting flypg.PGSetting
+	value   string
+}
+
+// importSettings applies the settings of imported which differ on the
+// cluster through its leader at leaderIP, returning the names of those which
+// only take effect after a restart.
+func importSettings(ctx context.Context, app *api.AppCompact, leaderIP string, imported *settingsFile) ([]string, error) {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		pgclient = pgClient(ctx, leaderIP)
+	)
+
+	// the file may hold settings beyond those export writes, so those it
+	// holds are the ones queried
+	names := make([]string, 0, len(imported.Settings)+1)
+	for name := range imported.Settings {
+		names = append(names, name)
+	}
+	names = append(names, "server_version")
+
+	res, err := pgclient.ViewSettings(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying the settings of %s: %w", app.Name, err)
+	}
+
+	current := make(map[string]flypg.PGSetting, len(res.Settings))
+	for _, setting := range res.Settings {
+		current[setting.Name] = setting
+	}
+
+	if version := current["server_version"].Setting; imported.ServerVersion != "" && version != imported.ServerVersion {
+		fmt.Fprintf(io.ErrOut, "The settings were exported from PostgreSQL %s; %s runs %s\n", imported.ServerVersion, app.Name, version)
+	}
+
+	changes, invalid := planImport(current, imported)
+
+	if len(invalid) > 0 {
+		if !flag.GetBool(ctx, "skip-invalid") {
+			return nil, fmt.Errorf("%s rejects these settings; pass --skip-invalid to import the others:\n  %s", app.Name, strings.Join(invalid, "\n  "))
+		}
+
+		fmt.Fprintln(io.ErrOut, colorize.Yellow(fmt.Sprintf("Skipping settings %s rejects:\n  %s", app.Name, strings.Join(invalid, "\n  "))))
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(io.Out, "The settings of %s already match\n", app.Name)
+
+		return nil, nil
+	}
+
+	rows := make([][]string, 0, len(changes))
+	for _, change := range changes {
+		rows = append(rows, []string{
+			strings.Replace(change.setting.Name, "_", "-", -1),
+			change.setting.Setting,
+			change.value,
+			change.setting.Unit,
+			fmt.Sprint(change.setting.Context == "postmaster"),
+		})
+	}
+	_ = render.Table(io.Out, "", rows, "Name", "Value", "Target value", "Unit", "Restart Required")
+
+	if !flag.GetBool(ctx, "yes") {
+		const msg = "Are you sure you want to apply these changes?"
+
+		switch confirmed, err := prompt.Confirmf(ctx, msg); {
+		case err == nil:
+			if !confirmed {
+				return nil, nil
+			}
+		case prompt.IsNonInteractive(err):
+			return nil, prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return nil, err
+		}
+	}
+
+	values := make(map[string]string, len(changes))
+	keys := make([]string, 0, len(changes))
+	for _, change := range changes {
+		values[change.setting.Name] = change.value
+		keys = append(keys, change.setting.Name)
+	}
+
+	fmt.Fprintln(io.Out, "Performing update...")
+
+	if err := pgclient.UpdateSettings(ctx, values); err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(io.Out, "Update complete!")
+
+	settings, err := pgclient.ViewSettings(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying the updated settings: %w", err)
+	}
+
+	var pending []string
+	for _, setting := range settings.Settings {
+		if setting.PendingRestart || setting.Context == "postmaster" {
+			pending = append(pending, setting.Name)
+		}
+	}
+	sort.Strings(pending)
+
+	return pending, nil
+}
+
+// planImport returns the changes importing imported makes to the settings
+// current, along with the reasons those it can't make are rejected for.
+func planImport(current map[string]flypg.PGSetting, imported *settingsFile) (changes []settingChange, invalid []string) {
+	names := make([]string, 0, len(imported.Settings))
+	for name := range imported.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := imported.Settings[name]
+
+		setting, ok := current[name]
+		switch {
+		case !ok:
+			invalid = append(invalid, fmt.Sprintf("%s: unknown to PostgreSQL %s", name, current["server_version"].Setting))
+
+			continue
+		case setting.Context == "internal" || unportableSettings[name]:
+			invalid = append(invalid, fmt.Sprintf("%s: can't be set", name))
+
+			continue
+		}
+
+		if err := validateConfigValue(setting, name, value); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", name, err))
+
+			continue
+		}
+
+		if setting.Setting != value {
+			changes = append(changes, settingChange{setting: setting, value: value})
+		}
+	}
+
+	return changes, invalid
+}
+
+// postgresApp returns the postgres app the command targets along with a
+// context able to reach its members.
+func postgresApp(ctx context.Context) (*api.AppCompact, context.Context, error) {
+	appName := app.NameFromContext(ctx)
+
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	if !app.IsPostgresApp() {
+		return nil, nil, notPostgresAppError(appName)
+	}
+
+	ctx, err = buildContext(ctx, app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return app, ctx, nil
+}
+
+// leaderAddress returns the private address of the leader of the cluster of
+// app.
+func leaderAddress(ctx context.Context, app *api.AppCompact) (string, error) {
+	const minPostgresHaVersion = "0.0.19"
+
+	switch app.PlatformVersion {
+	case "machines":
+		machines, err := mach.ListActive(ctx)
+		if err != nil {
+			return "", fmt.Errorf("machines could not be retrieved %w", err)
+		}
+
+		if err := hasRequiredVersionOnMachines(machines, minPostgresHaVersion, minPostgresHaVersion); err != nil {
+			return "", err
+		}
+
+		leader, err := pickLeader(ctx, machines)
+		if err != nil {
+			return "", err
+		}
+
+		return leader.PrivateIP, nil
+	case "nomad":
+		if err := hasRequiredVersionOnNomad(app, minPostgresHaVersion, minPostgresHaVersion); err != nil {
+			return "", err
+		}
+
+		pgInstances, err := agent.ClientFromContext(ctx).Instances(ctx, app.Organization.Slug, app.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to lookup 6pn ip for %s app: %v", app.Name, err)
+		}
+
+		if len(pgInstances.Addresses) == 0 {
+			return "", fmt.Errorf("no 6pn ips found for %s app", app.Name)
+		}
+
+		return leaderIpFromNomadInstances(ctx, pgInstances.Addresses)
+	default:
+		return "", fmt.Errorf("unknown platform version")
+	}
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/flypg"
+)
+
+func TestIsPortable(t *testing.T) {
+	cases := []struct {
+		name    string
+		setting flypg.PGSetting
+		want    bool
+	}{
+		{
+			name:    "tuned",
+			setting: flypg.PGSetting{Name: "work_mem", Setting: "8192", BootVal: "4096", Source: "configuration file", Context: "user"},
+			want:    true,
+		},
+		{
+			name:    "default",
+			setting: flypg.PGSetting{Name: "work_mem", Setting: "4096", BootVal: "4096", Source: "configuration file", Context: "user"},
+		},
+		{
+			name:    "read-only",
+			setting: flypg.PGSetting{Name: "server_version", Setting: "15.2", Source: "default", Context: "internal"},
+		},
+		{
+			name:    "per session",
+			setting: flypg.PGSetting{Name: "application_name", Setting: "psql", BootVal: "", Source: "client", Context: "user"},
+		},
+		{
+			name:    "per node",
+			setting: flypg.PGSetting{Name: "primary_conninfo", Setting: "host=other", Source: "configuration file", Context: "sighup"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, isPortable(tc.setting))
+		})
+	}
+}
+
+func TestNewSettingsFile(t *testing.T) {
+	export := newSettingsFile("db", []flypg.PGSetting{
+		{Name: "server_version", Setting: "15.2", Source: "default", Context: "internal"},
+		{Name: "work_mem", Setting: "8192", BootVal: "4096", Source: "configuration file", Context: "user"},
+		{Name: "max_connections", Setting: "100", BootVal: "100", Source: "configuration file", Context: "postmaster"},
+	})
+
+	assert.Equal(t, "db", export.App)
+	assert.Equal(t, "15.2", export.ServerVersion)
+	assert.Equal(t, map[string]string{"work_mem": "8192"}, export.Settings)
+}
+
+func TestPlanImport(t *testing.T) {
+	current := map[string]flypg.PGSetting{
+		"server_version":  {Name: "server_version", Setting: "15.2", Context: "internal"},
+		"work_mem":        {Name: "work_mem", Setting: "4096", VarType: "integer", MinVal: "64", MaxVal: "2147483647", Context: "user"},
+		"max_connections": {Name: "max_connections", Setting: "100", VarType: "integer", MinVal: "1", MaxVal: "262143", Context: "postmaster"},
+		"wal_level":       {Name: "wal_level", Setting: "replica", VarType: "enum", EnumVals: []string{"minimal", "replica", "logical"}, Context: "postmaster"},
+		"cluster_name":    {Name: "cluster_name", Setting: "db", VarType: "string", Context: "postmaster"},
+	}
+
+	changes, invalid := planImport(current, &settingsFile{Settings: map[string]string{
+		"work_mem":        "8192",
+		"max_connections": "100",
+		"wal_level":       "verbose",
+		"cluster_name":    "other",
+		"server_version":  "16.0",
+		"removed_setting": "on",
+	}})
+
+	if assert.Len(t, changes, 1, "only settings which differ change") {
+		assert.Equal(t, "work_mem", changes[0].setting.Name)
+		assert.Equal(t, "8192", changes[0].value)
+	}
+
+	if assert.Len(t, invalid, 4) {
+		assert.Equal(t, "cluster_name: can't be set", invalid[0])
+		assert.Equal(t, "removed_setting: unknown to PostgreSQL 15.2", invalid[1])
+		assert.Equal(t, "server_version: can't be set", invalid[2])
+		assert.Contains(t, invalid[3], "wal_level: invalid value")
+	}
+}
@@ -3,10 +3,10 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/pkg/errors"
 	"github.com/r3labs/diff"
 	"github.com/spf13/cobra"
 	"github.com/superfly/flyctl/agent"
@@ -15,7 +15,6 @@ import (
 	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
 	"github.com/superfly/flyctl/internal/flag"
 	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/prompt"
@@ -67,6 +66,10 @@ func newConfigUpdate() (cmd *cobra.Command) {
 			Name:        "force",
 			Description: "Skips pg-setting value verification.",
 		},
+		flag.Bool{
+			Name:        "auto-restart",
+			Description: "Restart the cluster without asking when any of the changes require it, then report the effective values",
+		},
 		flag.Yes(),
 	)
 
@@ -85,31 +88,35 @@ func runConfigUpdate(ctx context.Context) error {
 	}
 
 	if !app.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a postgres app", appName)
+		return notPostgresAppError(appName)
 	}
 
-	ctx, err = apps.BuildContext(ctx, app)
+	ctx, err = buildContext(ctx, app)
 	if err != nil {
 		return err
 	}
 
+	return applyConfig(ctx, app, updateStolonConfig)
+}
+
+// settingsApplier applies settings through the leader at leaderIP, returning
+// the names of the changed settings which only take effect after a restart.
+type settingsApplier func(ctx context.Context, app *api.AppCompact, leaderIP string) ([]string, error)
+
+// applyConfig applies settings to the cluster of app through apply, then
+// restarts it for those requiring it.
+func applyConfig(ctx context.Context, app *api.AppCompact, apply settingsApplier) error {
 	switch app.PlatformVersion {
 	case "machines":
-		return runMachineConfigUpdate(ctx, app)
+		return runMachineConfigUpdate(ctx, app, apply)
 	case "nomad":
-		return runNomadConfigUpdate(ctx, app)
+		return runNomadConfigUpdate(ctx, app, apply)
 	default:
 		return fmt.Errorf("unknown platform version")
 	}
 }
 
-func runMachineConfigUpdate(ctx context.Context, app *api.AppCompact) error {
-	var (
-		io          = iostreams.FromContext(ctx)
-		colorize    = io.ColorScheme()
-		autoConfirm = flag.GetBool(ctx, "yes")
-	)
-
+func runMachineConfigUpdate(ctx context.Context, app *api.AppCompact, apply settingsApplier) error {
 	var MinPostgresVersion = "v0.0.33"
 
 	machines, releaseLeaseFunc, err := mach.AcquireAllLeases(ctx)
@@ -127,57 +134,44 @@ func runMachineConfigUpdate(ctx context.Context, app *api.AppCompact) error {
 		return err
 	}
 
-	requiresRestart, err := updateStolonConfig(ctx, app, leader.PrivateIP)
+	pending, err := apply(ctx, app, leader.PrivateIP)
 	if err != nil {
 		return err
 	}
 
-	if requiresRestart {
-		if !autoConfirm {
-			fmt.Fprintln(io.Out, colorize.Yellow("Please note that some of your changes will require a cluster restart before they will be applied."))
+	restart := func(ctx context.Context) error {
+		// Ensure leases are released before we issue restart.
+		releaseLeaseFunc(ctx, machines)
 
-			switch confirmed, err := prompt.Confirm(ctx, "Restart cluster now?"); {
-			case err == nil:
-				if !confirmed {
-					return nil
-				}
-			case prompt.IsNonInteractive(err):
-				return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
-			default:
-				return err
-			}
+		return machinesRestart(ctx, &api.RestartMachineInput{}, restartOptions{timeout: defaultNodeHealthTimeout, failFast: true})
+	}
+
+	// the restart may well have failed the leader over
+	leaderIP := func(ctx context.Context) (string, error) {
+		machines, err := mach.ListActive(ctx)
+		if err != nil {
+			return "", err
 		}
 
-		// Ensure leases are released before we issue restart.
-		releaseLeaseFunc(ctx, machines)
-		if err := machinesRestart(ctx, &api.RestartMachineInput{}); err != nil {
-			return err
+		leader, err := pickLeader(ctx, machines)
+		if err != nil {
+			return "", err
 		}
+
+		return leader.PrivateIP, nil
 	}
 
-	return nil
+	return restartForSettings(ctx, app, pending, restart, leaderIP)
 }
 
-func runNomadConfigUpdate(ctx context.Context, app *api.AppCompact) error {
-	var (
-		io       = iostreams.FromContext(ctx)
-		colorize = io.ColorScheme()
-
-		autoConfirm = flag.GetBool(ctx, "yes")
-	)
-
+func runNomadConfigUpdate(ctx context.Context, app *api.AppCompact, apply settingsApplier) error {
 	var MinPostgresVersion = "v0.0.32"
 
 	if err := hasRequiredVersionOnNomad(app, MinPostgresVersion, MinPostgresVersion); err != nil {
 		return err
 	}
 
-	client := client.FromContext(ctx).API()
-
-	agentclient, err := agent.Establish(ctx, client)
-	if err != nil {
-		return errors.Wrap(err, "can't establish agent")
-	}
+	agentclient := agent.ClientFromContext(ctx)
 
 	pgInstances, err := agentclient.Instances(ctx, app.Organization.Slug, app.Name)
 	if err != nil {
@@ -192,39 +186,105 @@ func runNomadConfigUpdate(ctx context.Context, app *api.AppCompact) error {
 		return err
 	}
 
-	requiresRestart, err := updateStolonConfig(ctx, app, leaderIP)
+	pending, err := apply(ctx, app, leaderIP)
 	if err != nil {
 		return err
 	}
 
-	if requiresRestart {
-		if !autoConfirm {
-			fmt.Fprintln(io.Out, colorize.Yellow("Please note that some of your changes will require a cluster restart before they will be applied."))
+	restart := func(ctx context.Context) error {
+		return nomadRestart(ctx, app, restartOptions{timeout: defaultNodeHealthTimeout})
+	}
 
-			switch confirmed, err := prompt.Confirm(ctx, "Restart cluster now?"); {
-			case err == nil:
-				if !confirmed {
-					return nil
-				}
-			case prompt.IsNonInteractive(err):
-				return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
-			default:
-				return err
-			}
+	newLeaderIP := func(ctx context.Context) (string, error) {
+		pgInstances, err := agentclient.Instances(ctx, app.Organization.Slug, app.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to lookup 6pn ip for %s app: %v", app.Name, err)
 		}
 
-		if err := nomadRestart(ctx, app); err != nil {
+		return leaderIpFromNomadInstances(ctx, pgInstances.Addresses)
+	}
+
+	return restartForSettings(ctx, app, pending, restart, newLeaderIP)
+}
+
+// restartForSettings restarts the cluster for the settings in pending to take
+// effect, then reports their effective values. Unless --auto-restart is given
+// the user is asked first; declining leaves the settings listed as pending.
+// Clusters the restart refuses, such as those without a replica to fail over
+// to, are left for `fly postgres restart --force` to restart.
+func restartForSettings(ctx context.Context, app *api.AppCompact, pending []string, restart func(context.Context) error, leaderIP func(context.Context) (string, error)) error {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+
+		autoConfirm = flag.GetBool(ctx, "yes") || flag.GetBool(ctx, "auto-restart")
+	)
+
+	names := make([]string, 0, len(pending))
+	for _, name := range pending {
+		names = append(names, strings.Replace(name, "_", "-", -1))
+	}
+	fmt.Fprintln(io.Out, colorize.Yellow(fmt.Sprintf("These settings are pending a cluster restart before they will be applied: %s", strings.Join(names, ", "))))
+
+	if !autoConfirm {
+		switch confirmed, err := prompt.Confirm(ctx, "Restart cluster now?"); {
+		case err == nil:
+			if !confirmed {
+				fmt.Fprintf(io.Out, "Run `fly postgres restart --app %s` to apply them; `fly postgres config show` lists them as pending restart until then.\n", app.Name)
+
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes or auto-restart flag must be specified when not running interactively")
+		default:
 			return err
 		}
 	}
 
+	if err := restart(ctx); err != nil {
+		return fmt.Errorf("failed restarting the cluster for the settings to take effect; `fly postgres restart --app %s` retries, with --force to restart without a leader or replica to fail over to: %w", app.Name, err)
+	}
+
+	ip, err := leaderIP(ctx)
+	if err != nil {
+		return fmt.Errorf("failed finding the leader to report the effective settings: %w", err)
+	}
+
+	settings, err := pgClient(ctx, ip).ViewSettings(ctx, pending)
+	if err != nil {
+		return fmt.Errorf("failed querying the effective settings: %w", err)
+	}
+
+	var stillPending bool
+	rows := make([][]string, 0, len(settings.Settings))
+	for _, setting := range settings.Settings {
+		stillPending = stillPending || setting.PendingRestart
+		rows = append(rows, []string{
+			strings.Replace(setting.Name, "_", "-", -1),
+			setting.Setting,
+			setting.Unit,
+			fmt.Sprint(setting.PendingRestart),
+		})
+	}
+	_ = render.Table(io.Out, "Effective settings", rows, "Name", "Value", "Unit", "Pending Restart")
+
+	if stillPending {
+		return fmt.Errorf("some settings are still pending a restart after restarting the cluster")
+	}
+
 	return nil
 }
 
-func updateStolonConfig(ctx context.Context, app *api.AppCompact, leaderIP string) (bool, error) {
+// updateStolonConfig applies the requested changes through the leader at
+// leaderIP, returning the names of the changed settings which only take
+// effect after a restart.
+func updateStolonConfig(ctx context.Context, app *api.AppCompact, leaderIP string) ([]string, error) {
 	var (
-		io     = iostreams.FromContext(ctx)
-		dialer = agent.DialerFromContext(ctx)
+		io = iostreams.FromContext(ctx)
 
 		force       = flag.GetBool(ctx, "force")
 		autoConfirm = flag.GetBool(ctx, "yes")
@@ -241,32 +301,28 @@ func updateStolonConfig(ctx context.Context, app *api.AppCompact, leaderIP strin
 		}
 	}
 
-	restartRequired := false
 	if !force {
 		// Query PG settings
-		pgclient := flypg.NewFromInstance(leaderIP, dialer)
+		pgclient := pgClient(ctx, leaderIP)
 		settings, err := pgclient.ViewSettings(ctx, keys)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		if len(changes) == 0 {
-			return false, fmt.Errorf("no changes were specified")
+			return nil, fmt.Errorf("no changes were specified")
 		}
 
 		changelog, err := resolveChangeLog(ctx, changes, settings)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		if len(changelog) == 0 {
-			return false, fmt.Errorf("no changes to apply")
+			return nil, fmt.Errorf("no changes to apply")
 		}
 
 		rows := make([][]string, 0, len(changelog))
 		for _, change := range changelog {
 			requiresRestart := isRestartRequired(settings, change.Path[len(change.Path)-1])
-			if requiresRestart {
-				restartRequired = true
-			}
 
 			name := strings.Replace(change.Path[len(change.Path)-1], "_", "-", -1)
 			rows = append(rows, []string{
@@ -284,26 +340,40 @@ func updateStolonConfig(ctx context.Context, app *api.AppCompact, leaderIP strin
 			switch confirmed, err := prompt.Confirmf(ctx, msg); {
 			case err == nil:
 				if !confirmed {
-					return false, nil
+					return nil, nil
 				}
 			case prompt.IsNonInteractive(err):
-				return false, prompt.NonInteractiveError("auto-confirm flag must be specified when not running interactively")
+				return nil, prompt.NonInteractiveError("auto-confirm flag must be specified when not running interactively")
 			default:
-				return false, err
+				return nil, err
 			}
 		}
 	}
 
-	cmd := flypg.NewFromInstance(leaderIP, dialer)
+	cmd := pgClient(ctx, leaderIP)
 
 	fmt.Fprintln(io.Out, "Performing update...")
 
 	if err := cmd.UpdateSettings(ctx, changes); err != nil {
-		return false, err
+		return nil, err
 	}
 	fmt.Fprintln(io.Out, "Update complete!")
 
-	return restartRequired, nil
+	// query the settings anew, as --force skips doing so beforehand
+	settings, err := cmd.ViewSettings(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying the updated settings: %w", err)
+	}
+
+	var pending []string
+	for _, setting := range settings.Settings {
+		if _, ok := changes[setting.Name]; ok && (setting.PendingRestart || setting.Context == "postmaster") {
+			pending = append(pending, setting.Name)
+		}
+	}
+	sort.Strings(pending)
+
+	return pending, nil
 }
 
 func resolveChangeLog(ctx context.Context, changes map[string]string, settings *flypg.PGSettings) (diff.Changelog, error) {
@@ -3,10 +3,13 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/api"
@@ -14,7 +17,6 @@ import (
 	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
 	"github.com/superfly/flyctl/internal/flag"
 	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/render"
@@ -33,9 +35,21 @@ func newConfigView() (cmd *cobra.Command) {
 		command.RequireAppName,
 	)
 
+	cmd.Aliases = []string{"show"}
+
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.String{
+			Name:        "output",
+			Shorthand:   "o",
+			Default:     "table",
+			Description: "Output format: table, conf (the commonly tuned settings as postgresql.conf) or json (those settings with their metadata)",
+		},
+		flag.String{
+			Name:        "output-file",
+			Description: "Write the output to the given file instead of stdout",
+		},
 	)
 
 	return
@@ -53,10 +67,10 @@ func runConfigView(ctx context.Context) error {
 	}
 
 	if !app.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a postgres app", appName)
+		return notPostgresAppError(appName)
 	}
 
-	ctx, err = apps.BuildContext(ctx, app)
+	ctx, err = buildContext(ctx, app)
 	if err != nil {
 		return err
 	}
@@ -76,11 +90,6 @@ func runMachineConfigView(ctx context.Context, app *api.AppCompact) (err error)
 		MinPostgresHaVersion = "0.0.19"
 	)
 
-	ctx, err = apps.BuildContext(ctx, app)
-	if err != nil {
-		return err
-	}
-
 	machines, err := mach.ListActive(ctx)
 	if err != nil {
 		return fmt.Errorf("machines could not be retrieved %w", err)
@@ -101,13 +110,9 @@ func runMachineConfigView(ctx context.Context, app *api.AppCompact) (err error)
 func runNomadConfigView(ctx context.Context, app *api.AppCompact) (err error) {
 	var (
 		MinPostgresHaVersion = "0.0.19"
-		client               = client.FromContext(ctx).API()
 	)
 
-	agentclient, err := agent.Establish(ctx, client)
-	if err != nil {
-		return errors.Wrap(err, "can't establish agent")
-	}
+	agentclient := agent.ClientFromContext(ctx)
 
 	if err := hasRequiredVersionOnNomad(app, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
 		return err
@@ -134,10 +139,18 @@ func viewSettings(ctx context.Context, app *api.AppCompact, leaderIP string) err
 	var (
 		io       = iostreams.FromContext(ctx)
 		colorize = io.ColorScheme()
-		dialer   = agent.DialerFromContext(ctx)
+		output   = flag.GetString(ctx, "output")
 	)
 
-	pgclient := flypg.NewFromInstance(leaderIP, dialer)
+	pgclient := pgClient(ctx, leaderIP)
+
+	switch output {
+	case "table":
+	case "conf", "json":
+		return exportSettings(ctx, app, pgclient, output)
+	default:
+		return fmt.Errorf("unsupported output format %q; must be one of table, conf or json", output)
+	}
 
 	var settings []string
 	for _, k := range pgSettings {
@@ -192,13 +205,110 @@ func viewSettings(ctx context.Context, app *api.AppCompact, leaderIP string) err
 			restart,
 		})
 	}
-	_ = render.Table(io.Out, "", rows, "Name", "Value", "Unit", "Description", "Pending Restart")
+	out, closeOut, err := settingsOutput(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	_ = render.Table(out, "", rows, "Name", "Value", "Unit", "Description", "Pending Restart")
 
 	if pendingRestart {
-		fmt.Fprintln(io.Out, colorize.Yellow("Some changes are awaiting a restart!"))
-		fmt.Fprintln(io.Out, colorize.Yellow(fmt.Sprintf("To apply changes, run: `fly postgres restart --app %s`", app.Name)))
+		fmt.Fprintln(io.ErrOut, colorize.Yellow("Some changes are awaiting a restart!"))
+		fmt.Fprintln(io.ErrOut, colorize.Yellow(fmt.Sprintf("To apply changes, run: `fly postgres restart --app %s`", app.Name)))
 	}
 
 	return nil
 
 }
+
+// settingsOutput returns the writer settings should be rendered to, honoring
+// the output-file flag.
+func settingsOutput(ctx context.Context) (w io.Writer, closeFn func() error, err error) {
+	path := flag.GetString(ctx, "output-file")
+	if path == "" {
+		return iostreams.FromContext(ctx).Out, func() error { return nil }, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed creating %s: %w", path, err)
+	}
+
+	return f, f.Close, nil
+}
+
+// exportSettings writes the exported settings of the leader either as a
+// postgresql.conf document or as JSON. Values are written verbatim; this is an
+// authenticated operation against the user's own cluster.
+func exportSettings(ctx context.Context, app *api.AppCompact, pgclient *flypg.Client, format string) error {
+	res, err := pgclient.ViewSettings(ctx, exportedSettings)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(res.Settings, func(i, j int) bool {
+		return res.Settings[i].Name < res.Settings[j].Name
+	})
+
+	out, closeOut, err := settingsOutput(ctx)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		err = render.JSON(out, res.Settings)
+	} else {
+		err = renderConf(out, app.Name, res.Settings)
+	}
+
+	if cerr := closeOut(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		if path := flag.GetString(ctx, "output-file"); path != "" {
+			fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Wrote %d settings to %s\n", len(res.Settings), path)
+		}
+	}
+
+	return err
+}
+
+// renderConf writes settings as a postgresql.conf document. Settings which
+// differ from their defaults are active; defaults are commented out.
+func renderConf(w io.Writer, appName string, settings []flypg.PGSetting) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Effective PostgreSQL configuration of %s\n", appName)
+	fmt.Fprintf(&b, "# Exported by flyctl on %s\n\n", time.Now().UTC().Format(time.RFC3339))
+
+	for _, s := range settings {
+		line := fmt.Sprintf("%s = %s", s.Name, confValue(s))
+		if s.IsDefault() {
+			line = "#" + line
+		}
+
+		source := s.Source
+		if source == "" {
+			source = "default"
+		}
+
+		fmt.Fprintf(&b, "%-60s # source: %s\n", line, source)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// confValue formats a setting's value the way postgresql.conf expects it.
+// Numeric values are in the setting's base unit, so they're written bare.
+func confValue(s flypg.PGSetting) string {
+	switch s.VarType {
+	case "string", "enum":
+		return "'" + strings.ReplaceAll(s.Setting, "'", "''") + "'"
+	default:
+		return s.Setting
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
 	"github.com/superfly/flyctl/internal/command/ssh"
 	"github.com/superfly/flyctl/internal/flag"
 )
@@ -69,10 +68,10 @@ func runConnect(ctx context.Context) error {
 	}
 
 	if !app.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a postgres app", appName)
+		return notPostgresAppError(appName)
 	}
 
-	ctx, err = apps.BuildContext(ctx, app)
+	ctx, err = buildContext(ctx, app)
 	if err != nil {
 		return err
 	}
@@ -126,8 +125,6 @@ func runMachineConnect(ctx context.Context, app *api.AppCompact) error {
 
 func runNomadConnect(ctx context.Context, app *api.AppCompact) error {
 	var (
-		client = client.FromContext(ctx).API()
-
 		MinPostgresStandaloneVersion = "0.0.4"
 		MinPostgresHaVersion         = "0.0.9"
 
@@ -140,10 +137,7 @@ func runNomadConnect(ctx context.Context, app *api.AppCompact) error {
 		return err
 	}
 
-	agentclient, err := agent.Establish(ctx, client)
-	if err != nil {
-		return fmt.Errorf("failed to establish agent: %w", err)
-	}
+	agentclient := agent.ClientFromContext(ctx)
 
 	pgInstances, err := agentclient.Instances(ctx, app.Organization.Slug, app.Name)
 	if err != nil {
@@ -100,6 +100,7 @@ func run(ctx context.Context) (err error) {
 	if err != nil {
 		return
 	}
+	prompt.AnnounceOrg(ctx, org)
 
 	var region *api.Region
 
@@ -8,10 +8,8 @@ import (
 	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
-	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	mach "github.com/superfly/flyctl/internal/machine"
@@ -68,10 +66,10 @@ func runListDbs(ctx context.Context) error {
 	}
 
 	if !app.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a postgres app", appName)
+		return notPostgresAppError(appName)
 	}
 
-	ctx, err = apps.BuildContext(ctx, app)
+	ctx, err = buildContext(ctx, app)
 	if err != nil {
 		return err
 	}
@@ -116,17 +114,13 @@ func runNomadListDbs(ctx context.Context, app *api.AppCompact) error {
 	// Minimum image version requirements
 	var (
 		MinPostgresHaVersion = "0.0.19"
-		client               = client.FromContext(ctx).API()
 	)
 
 	if err := hasRequiredVersionOnNomad(app, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
 		return err
 	}
 
-	agentclient, err := agent.Establish(ctx, client)
-	if err != nil {
-		return fmt.Errorf("can't establish agent %w", err)
-	}
+	agentclient := agent.ClientFromContext(ctx)
 
 	pgInstances, err := agentclient.Instances(ctx, app.Organization.Slug, app.Name)
 	if err != nil {
@@ -147,12 +141,11 @@ func runNomadListDbs(ctx context.Context, app *api.AppCompact) error {
 
 func listDBs(ctx context.Context, leaderIP string) error {
 	var (
-		dialer = agent.DialerFromContext(ctx)
-		io     = iostreams.FromContext(ctx)
-		cfg    = config.FromContext(ctx)
+		io  = iostreams.FromContext(ctx)
+		cfg = config.FromContext(ctx)
 	)
 
-	pgclient := flypg.NewFromInstance(leaderIP, dialer)
+	pgclient := pgClient(ctx, leaderIP)
 	databases, err := pgclient.ListDatabases(ctx)
 	if err != nil {
 		return err
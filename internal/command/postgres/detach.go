@@ -8,10 +8,8 @@ import (
 	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
-	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
 	"github.com/superfly/flyctl/internal/flag"
 	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/prompt"
@@ -58,7 +56,7 @@ func runDetach(ctx context.Context) error {
 		return fmt.Errorf("get app: %w", err)
 	}
 
-	ctx, err = apps.BuildContext(ctx, pgApp)
+	ctx, err = buildContext(ctx, pgApp)
 	if err != nil {
 		return err
 	}
@@ -103,13 +101,9 @@ func runMachineDetach(ctx context.Context, app *api.AppCompact, pgApp *api.AppCo
 func runNomadDetach(ctx context.Context, app *api.AppCompact, pgApp *api.AppCompact) error {
 	var (
 		MinPostgresHaVersion = "0.0.19"
-		client               = client.FromContext(ctx).API()
 	)
 
-	agentclient, err := agent.Establish(ctx, client)
-	if err != nil {
-		return fmt.Errorf("can't establish agent %w", err)
-	}
+	agentclient := agent.ClientFromContext(ctx)
 
 	if err := hasRequiredVersionOnNomad(pgApp, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
 		return err
@@ -136,7 +130,6 @@ func runNomadDetach(ctx context.Context, app *api.AppCompact, pgApp *api.AppComp
 func detachAppFromPostgres(ctx context.Context, leaderIP string, app *api.AppCompact, pgApp *api.AppCompact) error {
 	var (
 		client = client.FromContext(ctx).API()
-		dialer = agent.DialerFromContext(ctx)
 		io     = iostreams.FromContext(ctx)
 	)
 
@@ -146,7 +139,9 @@ func detachAppFromPostgres(ctx context.Context, leaderIP string, app *api.AppCom
 	}
 
 	if len(attachments) == 0 {
-		return fmt.Errorf("no attachments found")
+		fmt.Fprintf(io.Out, "%s has no attachments to %s; nothing to detach\n", app.Name, pgApp.Name)
+
+		return nil
 	}
 
 	selected := 0
@@ -166,7 +161,7 @@ func detachAppFromPostgres(ctx context.Context, leaderIP string, app *api.AppCom
 
 	targetAttachment := attachments[selected]
 
-	pgclient := flypg.NewFromInstance(leaderIP, dialer)
+	pgclient := pgClient(ctx, leaderIP)
 
 	// Remove user if exists
 	exists, err := pgclient.UserExists(ctx, targetAttachment.DatabaseUser)
@@ -180,12 +175,20 @@ func detachAppFromPostgres(ctx context.Context, leaderIP string, app *api.AppCom
 		}
 	}
 
-	// Remove secret from consumer app.
-	_, err = client.UnsetSecrets(ctx, app.Name, []string{targetAttachment.EnvironmentVariableName})
+	// Remove secret from consumer app, unless an earlier attempt already did.
+	secrets, err := client.GetAppSecrets(ctx, app.Name)
 	if err != nil {
-		// This will error if secret doesn't exist, so just send to stdout.
-		fmt.Fprintln(io.Out, err.Error())
-	} else {
+		return err
+	}
+	var hasSecret bool
+	for _, secret := range secrets {
+		hasSecret = hasSecret || secret.Name == targetAttachment.EnvironmentVariableName
+	}
+
+	if hasSecret {
+		if _, err = client.UnsetSecrets(ctx, app.Name, []string{targetAttachment.EnvironmentVariableName}); err != nil {
+			return fmt.Errorf("failed removing secret %s: %w", targetAttachment.EnvironmentVariableName, err)
+		}
 		fmt.Fprintf(io.Out, "Secret %q was scheduled to be removed from app %s\n",
 			targetAttachment.EnvironmentVariableName,
 			app.Name,
@@ -2,28 +2,33 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/avast/retry-go/v4"
 	"github.com/spf13/cobra"
-	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
 	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/watch"
 	"github.com/superfly/flyctl/iostreams"
 )
 
+const failoverPollInterval = 2 * time.Second
+
 func newFailover() *cobra.Command {
 	const (
 		short = "Failover to a new primary"
-		long  = short + "\n"
+		long  = short + `. A healthy replica in the region of the current leader is
+promoted, and the command waits for a different member to report as leader.
+Only machines based clusters with more than one member can fail over.
+`
 		usage = "failover"
 	)
 
@@ -36,6 +41,11 @@ func newFailover() *cobra.Command {
 		cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.Int{
+			Name:        "timeout",
+			Description: "Seconds to wait for a new leader to be elected",
+			Default:     120,
+		},
 	)
 
 	return cmd
@@ -45,8 +55,10 @@ func runFailover(ctx context.Context) (err error) {
 	var (
 		MinPostgresHaVersion = "0.0.20"
 		io                   = iostreams.FromContext(ctx)
+		colorize             = io.ColorScheme()
 		client               = client.FromContext(ctx).API()
 		appName              = app.NameFromContext(ctx)
+		timeout              = time.Duration(flag.GetInt(ctx, "timeout")) * time.Second
 	)
 
 	app, err := client.GetAppCompact(ctx, appName)
@@ -55,20 +67,19 @@ func runFailover(ctx context.Context) (err error) {
 	}
 
 	if !app.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a Postgres app", app.Name)
+		return notPostgresAppError(app.Name)
 	}
 
 	if app.PlatformVersion != "machines" {
-		return fmt.Errorf("failover is only supported for machines apps")
+		return flyerr.WithCode(flyerr.CodePlatformUnsupported, errors.New("failover is only supported for machines apps"))
 	}
 
-	ctx, err = apps.BuildContext(ctx, app)
+	ctx, err = buildContext(ctx, app)
 	if err != nil {
 		return err
 	}
 
-	machines, releaseFunc, err := mach.AcquireAllLeases(ctx)
-	defer releaseFunc(ctx, machines)
+	machines, err := mach.ListActive(ctx)
 	if err != nil {
 		return fmt.Errorf("machines could not be retrieved %w", err)
 	}
@@ -79,7 +90,7 @@ func runFailover(ctx context.Context) (err error) {
 
 	// You can not failerover for single node postgres
 	if len(machines) <= 1 {
-		return fmt.Errorf("failover is not available for standalone postgres")
+		return flyerr.WithCode(flyerr.CodeValidation, errors.New("failover is not available for standalone postgres"))
 	}
 
 	leader, err := pickLeader(ctx, machines)
@@ -87,29 +98,27 @@ func runFailover(ctx context.Context) (err error) {
 		return err
 	}
 
-	flapsClient := flaps.FromContext(ctx)
-	dialer := agent.DialerFromContext(ctx)
-
-	pgclient := flypg.NewFromInstance(leader.PrivateIP, dialer)
-	fmt.Fprintf(io.Out, "Performing a failover\n")
-	if err := pgclient.Failover(ctx); err != nil {
-		return fmt.Errorf("failed to trigger failover %w", err)
+	candidates := failoverCandidates(leader, machines)
+	if len(candidates) == 0 {
+		return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("there is no healthy replica in %s for leader %s to fail over to", leader.Region, leader.ID))
 	}
 
-	// Wait until the leader lost its role
-	if err := retry.Do(
-		func() error {
-			var err error
-			leader, err = flapsClient.Get(ctx, leader.ID)
-			if err != nil {
-				return err
-			} else if machineRole(leader) == "leader" {
-				return fmt.Errorf("%s hasn't lost its leader role", leader.ID)
-			}
-			return nil
-		},
-		retry.Context(ctx), retry.Attempts(30), retry.Delay(time.Second), retry.DelayType(retry.FixedDelay),
-	); err != nil {
+	fmt.Fprintf(io.Out, "Leader: %s; %d healthy replicas in %s may be promoted\n", colorize.Bold(leader.ID), len(candidates), leader.Region)
+
+	var newLeader *api.Machine
+	err = mach.WithLease(ctx, leader, func(ctx context.Context, leader *api.Machine) (err error) {
+		pgclient := pgClient(ctx, leader.PrivateIP)
+
+		fmt.Fprintf(io.Out, "Performing a failover\n")
+		if err := pgclient.Failover(ctx); err != nil {
+			return fmt.Errorf("failed to trigger failover %w", err)
+		}
+
+		newLeader, err = waitForNewLeader(ctx, leader.ID, timeout)
+
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
@@ -118,6 +127,59 @@ func runFailover(ctx context.Context) (err error) {
 		return fmt.Errorf("failed to wait for health checks to pass: %w", err)
 	}
 
-	fmt.Fprintf(io.Out, "Failover complete\n")
+	fmt.Fprintf(io.Out, "Failover complete: the leader moved from %s to %s\n", colorize.Bold(leader.ID), colorize.Bold(newLeader.ID))
 	return
 }
+
+// failoverCandidates returns the started replicas in the region of leader all
+// of whose checks pass, which are those that may be promoted.
+func failoverCandidates(leader *api.Machine, machines []*api.Machine) (candidates []*api.Machine) {
+	for _, m := range machines {
+		if m.ID == leader.ID || m.Region != leader.Region || m.State != "started" {
+			continue
+		}
+
+		if flypg.MachineRole(m) != "replica" {
+			continue
+		}
+
+		healthy := true
+		for _, check := range m.Checks {
+			if check.Status != "passing" {
+				healthy = false
+			}
+		}
+
+		if healthy {
+			candidates = append(candidates, m)
+		}
+	}
+
+	return
+}
+
+// waitForNewLeader waits up to timeout for a member other than the one with
+// the ID oldLeader to report as leader.
+func waitForNewLeader(ctx context.Context, oldLeader string, timeout time.Duration) (*api.Machine, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	flapsClient := flaps.FromContext(ctx)
+
+	for {
+		machines, err := flapsClient.ListActive(ctx)
+		if err == nil {
+			for _, m := range machines {
+				if m.ID != oldLeader && flypg.MachineRole(m) == "leader" {
+					return m, nil
+				}
+			}
+		}
+
+		select {
+		case <-time.After(failoverPollInterval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no member other than %s became leader within %s", oldLeader, timeout)
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/apps"
+	"github.com/superfly/flyctl/internal/flyerr"
 )
 
 func New() *cobra.Command {
@@ -32,6 +34,7 @@ func New() *cobra.Command {
 		newDetach(),
 		newList(),
 		newRestart(),
+		newSandbox(),
 		newUsers(),
 		newFailover(),
 	)
@@ -124,27 +127,9 @@ func hasRequiredVersionOnMachines(machines []*api.Machine, cluster, standalone s
 	return nil
 }
 
-func machinesNodeRoles(ctx context.Context, machines []*api.Machine) (leader *api.Machine, replicas []*api.Machine) {
-	for _, machine := range machines {
-		role := machineRole(machine)
-
-		switch role {
-		case "leader":
-			leader = machine
-		case "replica":
-			replicas = append(replicas, machine)
-		default:
-			replicas = append(replicas, machine)
-		}
-	}
-	return leader, replicas
-}
-
 func nomadNodeRoles(ctx context.Context, allocs []*api.AllocationStatus) (leader *api.AllocationStatus, replicas []*api.AllocationStatus, err error) {
-	dialer := agent.DialerFromContext(ctx)
-
 	for _, alloc := range allocs {
-		pgclient := flypg.NewFromInstance(alloc.PrivateIP, dialer)
+		pgclient := pgClient(ctx, alloc.PrivateIP)
 		if err != nil {
 			return nil, nil, fmt.Errorf("can't connect to %s: %w", alloc.ID, err)
 		}
@@ -164,26 +149,9 @@ func nomadNodeRoles(ctx context.Context, allocs []*api.AllocationStatus) (leader
 	return leader, replicas, nil
 }
 
-func machineRole(machine *api.Machine) (role string) {
-	role = "unknown"
-
-	for _, check := range machine.Checks {
-		if check.Name == "role" {
-			if check.Status == "passing" {
-				role = check.Output
-			} else {
-				role = "error"
-			}
-			break
-		}
-	}
-	return role
-}
-
 func leaderIpFromNomadInstances(ctx context.Context, addrs []string) (string, error) {
-	dialer := agent.DialerFromContext(ctx)
 	for _, addr := range addrs {
-		pgclient := flypg.NewFromInstance(addr, dialer)
+		pgclient := pgClient(ctx, addr)
 		role, err := pgclient.NodeRole(ctx)
 		if err != nil {
 			return "", fmt.Errorf("can't get role for %s: %w", addr, err)
@@ -198,9 +166,37 @@ func leaderIpFromNomadInstances(ctx context.Context, addrs []string) (string, er
 
 func pickLeader(ctx context.Context, machines []*api.Machine) (*api.Machine, error) {
 	for _, machine := range machines {
-		if machineRole(machine) == "leader" {
+		if flypg.MachineRole(machine) == "leader" {
 			return machine, nil
 		}
 	}
 	return nil, fmt.Errorf("no active leader found")
 }
+
+// notPostgresAppError is returned by commands targeting apps which aren't
+// postgres clusters.
+func notPostgresAppError(appName string) error {
+	return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("app %s is not a postgres app", appName))
+}
+
+// buildContext builds the context of apps.BuildContext around the cluster
+// app, adding a flypg.Pool sharing its dialer so that all of the flypg clients
+// of a command reuse their connections.
+func buildContext(ctx context.Context, app *api.AppCompact) (context.Context, error) {
+	ctx, err := apps.BuildContext(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	return flypg.PoolWithContext(ctx, flypg.NewPool(agent.DialerFromContext(ctx))), nil
+}
+
+// pgClient returns a flypg client of the member at address, off the pool of
+// ctx when it carries one.
+func pgClient(ctx context.Context, address string) *flypg.Client {
+	if pool := flypg.PoolFromContext(ctx); pool != nil {
+		return pool.Instance(address)
+	}
+
+	return flypg.NewFromInstance(address, agent.DialerFromContext(ctx))
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+)
+
+const (
+	// postgresPort is the port the proxy of the cluster accepts connections
+	// on.
+	postgresPort = "5432"
+
+	reachabilityTimeout = 2 * time.Second
+)
+
+type contextDialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// checkReachability verifies the consumer app may connect to the postgres
+// port of the cluster's leader, before attaching creates anything. dialer
+// must dial from within the organization of the consumer app.
+func checkReachability(ctx context.Context, dialer contextDialer, consumer, cluster *api.AppCompact, leaderIP string) error {
+	if consumer.Organization.Slug != cluster.Organization.Slug {
+		return fmt.Errorf("%s belongs to organization %s while %s belongs to %s; apps can only reach postgres clusters of their own organization. Create a cluster in %s and attach that instead",
+			consumer.Name, consumer.Organization.Slug, cluster.Name, cluster.Organization.Slug, consumer.Organization.Slug)
+	}
+
+	if network(consumer) != network(cluster) {
+		return fmt.Errorf("%s is on the %s network while %s is on the %s network, so %s won't be able to reach the cluster. Create a cluster on the %s network and attach that instead",
+			consumer.Name, network(consumer), cluster.Name, network(cluster), consumer.Name, network(consumer))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reachabilityTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(leaderIP, postgresPort)
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed connecting to %s of %s from the %s network: %w. Check that the cluster is healthy, or pass --skip-reachability-check if this machine can't reach it",
+			addr, cluster.Name, network(consumer), err)
+	}
+
+	return conn.Close()
+}
+
+func network(app *api.AppCompact) string {
+	if app.Network == "" {
+		return "default"
+	}
+
+	return app.Network
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/superfly/flyctl/api"
+)
+
+type fakeDialer struct {
+	addrs []string
+	err   error
+}
+
+func (d *fakeDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.addrs = append(d.addrs, addr)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	client, server := net.Pipe()
+	_ = server.Close()
+
+	return client, nil
+}
+
+func testApp(name, org, network string) *api.AppCompact {
+	return &api.AppCompact{
+		Name:         name,
+		Organization: &api.OrganizationBasic{Slug: org},
+		Network:      network,
+	}
+}
+
+func TestCheckReachability(t *testing.T) {
+	ctx := context.Background()
+	cluster := testApp("db", "acme", "")
+
+	dialer := &fakeDialer{}
+	require.NoError(t, checkReachability(ctx, dialer, testApp("web", "acme", "default"), cluster, "fdaa::3"))
+	assert.Equal(t, []string{"[fdaa::3]:5432"}, dialer.addrs)
+
+	dialer = &fakeDialer{}
+	err := checkReachability(ctx, dialer, testApp("web", "acme", "isolated"), cluster, "fdaa::3")
+	assert.ErrorContains(t, err, "web is on the isolated network while db is on the default network")
+	assert.Empty(t, dialer.addrs)
+
+	dialer = &fakeDialer{}
+	err = checkReachability(ctx, dialer, testApp("web", "other", ""), cluster, "fdaa::3")
+	assert.ErrorContains(t, err, "web belongs to organization other while db belongs to acme")
+	assert.Empty(t, dialer.addrs)
+
+	dialer = &fakeDialer{err: errors.New("i/o timeout")}
+	err = checkReachability(ctx, dialer, testApp("web", "acme", ""), cluster, "fdaa::3")
+	assert.ErrorContains(t, err, "failed connecting to [fdaa::3]:5432 of db")
+	assert.ErrorContains(t, err, "--skip-reachability-check")
+}
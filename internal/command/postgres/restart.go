@@ -3,24 +3,38 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
+	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
 	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
 
+const (
+	// defaultNodeHealthTimeout bounds the wait for each restarted member to
+	// become healthy again, unless --wait-timeout says otherwise.
+	defaultNodeHealthTimeout = 5 * time.Minute
+	nodeHealthInterval       = 2 * time.Second
+)
+
 func newRestart() *cobra.Command {
 	const (
 		short = "Restarts each member of the Postgres cluster one by one."
-		long  = short + " Downtime should be minimal." + "\n"
+		long  = short + " Downtime should be minimal, as each member is only restarted once the previous one\n" +
+			"is healthy again, or fails to become so within --wait-timeout. Replicas which fail to restart keep the\n" +
+			"leader from failing over, and with --fail-fast also keep the rest from restarting. A summary of the\n" +
+			"outcome for each member is shown at the end.\n"
 		usage = "restart"
 	)
 
@@ -34,7 +48,7 @@ func newRestart() *cobra.Command {
 		flag.AppConfig(),
 		flag.Bool{
 			Name:        "force",
-			Description: "Force a restart even we don't have an active leader",
+			Description: "Force a restart even if there's no active leader or no replica to fail the leader over to",
 			Default:     false,
 		},
 		flag.Bool{
@@ -42,11 +56,46 @@ func newRestart() *cobra.Command {
 			Description: "Runs rolling restart process without waiting for health checks. ( Machines only )",
 			Default:     false,
 		},
+		flag.String{
+			Name:        "region",
+			Shorthand:   "r",
+			Description: "Only restart the members in this region",
+		},
+		flag.StringSlice{
+			Name:        "machine",
+			Description: "Only restart this member, by machine or allocation ID. Can be specified multiple times.",
+		},
+		flag.Int{
+			Name:        "wait-timeout",
+			Description: "Seconds to wait for each member to become healthy again before restarting the next",
+			Default:     int(defaultNodeHealthTimeout.Seconds()),
+		},
+		flag.Bool{
+			Name:        "fail-fast",
+			Description: "Stop at the first member which fails to restart, rather than moving on to the next replica",
+		},
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "Show the order members would be restarted in, without leasing or restarting any, failing should the restart be bound to fail",
+		},
 	)
 
 	return cmd
 }
 
+// restartOptions are the options of cluster restarts.
+type restartOptions struct {
+	// force restarts clusters without a leader, or without a replica to fail
+	// over to, in place, as well as leaders matched by filter.
+	force bool
+	// timeout bounds the wait for each member to become healthy again.
+	timeout time.Duration
+	// filter, if set, limits the restart to the members it matches.
+	filter *memberFilter
+	// failFast stops the restart at the first member which fails to.
+	failFast bool
+}
+
 func runRestart(ctx context.Context) error {
 	var (
 		appName = app.NameFromContext(ctx)
@@ -59,40 +108,59 @@ func runRestart(ctx context.Context) error {
 	}
 
 	if !app.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a postgres app", appName)
+		return notPostgresAppError(appName)
 	}
 
-	ctx, err = apps.BuildContext(ctx, app)
+	ctx, err = buildContext(ctx, app)
 	if err != nil {
 		return err
 	}
 
+	opts := restartOptions{
+		force:    flag.GetBool(ctx, "force"),
+		timeout:  time.Duration(flag.GetInt(ctx, "wait-timeout")) * time.Second,
+		filter:   newMemberFilter(ctx),
+		failFast: flag.GetBool(ctx, "fail-fast"),
+	}
+
+	if flag.GetBool(ctx, "dry-run") {
+		return runRestartDryRun(ctx, app, opts)
+	}
+
 	switch app.PlatformVersion {
 	case "machines":
 		input := api.RestartMachineInput{
 			SkipHealthChecks: flag.GetBool(ctx, "skip-health-checks"),
 		}
-		return machinesRestart(ctx, &input)
+		return machinesRestart(ctx, &input, opts)
 	case "nomad":
-		return nomadRestart(ctx, app)
+		return nomadRestart(ctx, app, opts)
 	default:
 		return fmt.Errorf("unknown platform version")
 	}
 }
 
-func machinesRestart(ctx context.Context, input *api.RestartMachineInput) (err error) {
+// machinesRestart restarts the replicas of the cluster one by one, each once
+// the previous one is healthy again, then fails the leader over to one of
+// them and restarts it last. With force, clusters without a leader or without
+// a replica to fail over to are restarted in place. Replicas which fail to
+// restart are moved past unless failing fast, but keep the leader from failing
+// over. Given a filter, only the members it matches are restarted, and the
+// leader only with force; skipping it is shown in the summary rendered at the
+// end, along with the outcome for each member.
+func machinesRestart(ctx context.Context, input *api.RestartMachineInput, opts restartOptions) (err error) {
 	var (
 		MinPostgresHaVersion = "0.0.20"
 
-		dialer   = agent.DialerFromContext(ctx)
 		io       = iostreams.FromContext(ctx)
 		colorize = io.ColorScheme()
-
-		force = flag.GetBool(ctx, "force")
+		force    = opts.force
+		timeout  = opts.timeout
 	)
 
-	machines, releaseLeaseFunc, err := mach.AcquireAllLeases(ctx)
-	defer releaseLeaseFunc(ctx, machines)
+	// each member is leased only while it's restarted, so that the leases
+	// of later members can't run out while earlier ones restart
+	machines, err := mach.ListActive(ctx)
 	if err != nil {
 		return err
 	}
@@ -101,66 +169,168 @@ func machinesRestart(ctx context.Context, input *api.RestartMachineInput) (err e
 		return err
 	}
 
-	leader, replicas := machinesNodeRoles(ctx, machines)
-
-	if leader == nil {
-		if !force {
-			return fmt.Errorf("no active leader found")
-		}
-		fmt.Fprintln(io.Out, colorize.Yellow("No leader found, but continuing with restart"))
-	}
+	leader, replicas := flypg.MachineNodeRoles(machines)
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].ID < replicas[j].ID
+	})
 
 	fmt.Fprintln(io.Out, "Identifying cluster role(s)")
 	for _, machine := range machines {
-		fmt.Fprintf(io.Out, "  Machine %s: %s\n", colorize.Bold(machine.ID), machineRole(machine))
+		fmt.Fprintf(io.Out, "  Machine %s: %s\n", colorize.Bold(machine.ID), flypg.MachineRole(machine))
 	}
 
-	// Restarting replicas
-	for _, replica := range replicas {
-		if err = mach.Restart(ctx, replica, input); err != nil {
-			return err
+	// Don't attempt to failover unless we have in-region replicas
+	inRegionReplicas := 0
+	if leader != nil {
+		for _, replica := range replicas {
+			if replica.Region == leader.Region {
+				inRegionReplicas++
+			}
 		}
 	}
 
-	// Don't attempt to failover unless we have in-region replicas
-	inRegionReplicas := 0
-	for _, replica := range replicas {
-		if replica.Region == leader.Region {
-			inRegionReplicas++
+	replicas, restartLeader, err := selectMachineMembers(ctx, leader, replicas, opts)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case leader == nil && !force:
+		return fmt.Errorf("no active leader found; pass --force to restart every member in place")
+	case leader == nil:
+		fmt.Fprintln(io.Out, colorize.Yellow("No leader found, but continuing with restart"))
+	default:
+		fmt.Fprintf(io.Out, "Leader: %s\n", colorize.Bold(leader.ID))
+
+		if restartLeader && inRegionReplicas == 0 && !force {
+			return fmt.Errorf("there is no replica in %s for leader %s to fail over to; pass --force to restart it in place", leader.Region, leader.ID)
 		}
 	}
 
-	if inRegionReplicas > 0 {
-		pgclient := flypg.NewFromInstance(leader.PrivateIP, dialer)
-		fmt.Fprintf(io.Out, "Attempting to failover %s\n", colorize.Bold(leader.ID))
+	leaderSkipped := !restartLeader && leader != nil && opts.filter != nil && opts.filter.matches(leader.ID, leader.Region)
 
-		if err := pgclient.Failover(ctx); err != nil {
-			msg := fmt.Sprintf("failed to perform failover: %s", err.Error())
-			if !force {
-				return fmt.Errorf(msg)
+	summary := newRestartSummary(replicas)
+	if restartLeader {
+		summary.add(leader, "leader")
+	} else if leaderSkipped {
+		summary.add(leader, "leader")
+		summary.skip(len(replicas), "pass --force to restart it too")
+	}
+
+	// Restarting replicas
+	for i, replica := range replicas {
+		err := mach.WithLease(ctx, replica, func(ctx context.Context, replica *api.Machine) error {
+			if err := restartMember(ctx, replica, input, timeout); err != nil {
+				return err
 			}
 
-			fmt.Fprintln(io.Out, colorize.Red(msg))
+			if input.SkipHealthChecks {
+				return nil
+			}
+
+			return waitForNodeRole(ctx, replica.ID, replica.PrivateIP, "replica", timeout)
+		})
+
+		summary.record(i, err)
+		if err != nil && opts.failFast {
+			break
 		}
 	}
 
-	if err = mach.Restart(ctx, leader, input); err != nil {
+	if restartLeader && summary.failed() > 0 {
+		summary.skip(len(replicas), "not failed over, as replicas failed to restart")
+	} else if restartLeader {
+		err := mach.WithLease(ctx, leader, func(ctx context.Context, leader *api.Machine) error {
+			if inRegionReplicas > 0 {
+				pgclient := pgClient(ctx, leader.PrivateIP)
+				fmt.Fprintf(io.Out, "Attempting to failover %s\n", colorize.Bold(leader.ID))
+
+				if err := pgclient.Failover(ctx); err != nil {
+					msg := fmt.Sprintf("failed to perform failover: %s", err.Error())
+					if !force {
+						return fmt.Errorf(msg)
+					}
+
+					fmt.Fprintln(io.Out, colorize.Red(msg))
+				}
+			}
+
+			return restartMember(ctx, leader, input, timeout)
+		})
+		summary.record(len(replicas), err)
+	}
+
+	if err := summary.render(ctx); err != nil {
 		return err
 	}
 
+	if failed := summary.failed(); failed > 0 {
+		return fmt.Errorf("failed restarting %d of %d members of the Postgres cluster", failed, len(summary.results))
+	}
+
+	if leaderSkipped {
+		fmt.Fprintf(io.Out, "Postgres cluster has been restarted, except for leader %s\n", leader.ID)
+
+		return
+	}
+
 	fmt.Fprintf(io.Out, "Postgres cluster has been successfully restarted!\n")
 
 	return
 }
 
-func nomadRestart(ctx context.Context, app *api.AppCompact) error {
+// selectMachineMembers narrows replicas down to those the filter of opts
+// matches, if any, and reports whether leader is restarted too, which takes
+// force when filtering.
+func selectMachineMembers(ctx context.Context, leader *api.Machine, replicas []*api.Machine, opts restartOptions) ([]*api.Machine, bool, error) {
+	var (
+		colorize = iostreams.FromContext(ctx).ColorScheme()
+		errOut   = iostreams.FromContext(ctx).ErrOut
+		filter   = opts.filter
+	)
+
+	restartLeader := leader != nil
+	if filter == nil {
+		return replicas, restartLeader, nil
+	}
+
+	var targeted []*api.Machine
+	for _, replica := range replicas {
+		if filter.matches(replica.ID, replica.Region) {
+			targeted = append(targeted, replica)
+		}
+	}
+	restartLeader = leader != nil && filter.matches(leader.ID, leader.Region)
+
+	if err := filter.validate(len(targeted) > 0 || restartLeader); err != nil {
+		return nil, false, err
+	}
+
+	if restartLeader && !opts.force {
+		fmt.Fprintln(errOut, colorize.Yellow(fmt.Sprintf("Skipping leader %s, which would fail over and restart; pass --force to restart it too", leader.ID)))
+		restartLeader = false
+
+		if len(targeted) == 0 {
+			return nil, false, flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("only leader %s matches the filters; pass --force to restart it", leader.ID))
+		}
+	}
+
+	return targeted, restartLeader, nil
+}
+
+// nomadRestart restarts the cluster the way machinesRestart does, rendering
+// the same summary. Single node clusters, which have no replica to fail over
+// to, require force.
+func nomadRestart(ctx context.Context, app *api.AppCompact, opts restartOptions) error {
 	var (
 		MinPostgresHaVersion = "0.0.20"
 
 		client   = client.FromContext(ctx).API()
-		dialer   = agent.DialerFromContext(ctx)
 		io       = iostreams.FromContext(ctx)
 		colorize = io.ColorScheme()
+		force    = opts.force
+		timeout  = opts.timeout
+		filter   = opts.filter
 	)
 
 	if err := hasRequiredVersionOnNomad(app, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
@@ -180,38 +350,353 @@ func nomadRestart(ctx context.Context, app *api.AppCompact) error {
 	if leader == nil {
 		return fmt.Errorf("no leader found")
 	}
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].ID < replicas[j].ID
+	})
+
+	fmt.Fprintf(io.Out, "Leader: %s\n", colorize.Bold(leader.ID))
+
+	if len(replicas) == 0 && !force {
+		return fmt.Errorf("there is no replica for leader %s to fail over to; pass --force to restart it in place", leader.ID)
+	}
 
-	if len(replicas) > 0 {
-		fmt.Fprintln(io.Out, "Attempting to restart replica(s)")
+	// every replica can take over as leader, whether the filter matches it
+	// or not
+	standbys := replicas
 
+	restartLeader, leaderSkipped := true, false
+	if filter != nil {
+		var targeted []*api.AllocationStatus
 		for _, replica := range replicas {
-			fmt.Fprintf(io.Out, " Restarting %s\n", replica.ID)
+			if filter.matchesAlloc(replica) {
+				targeted = append(targeted, replica)
+			}
+		}
+		replicas = targeted
+		restartLeader = filter.matchesAlloc(leader)
 
-			if err := client.RestartAllocation(ctx, app.Name, replica.ID); err != nil {
-				return fmt.Errorf("failed to restart vm %s: %w", replica.ID, err)
+		if err := filter.validate(len(replicas) > 0 || restartLeader); err != nil {
+			return err
+		}
+
+		if restartLeader && !force {
+			fmt.Fprintln(io.ErrOut, colorize.Yellow(fmt.Sprintf("Skipping leader %s, which would fail over and restart; pass --force to restart it too", leader.ID)))
+			restartLeader, leaderSkipped = false, true
+
+			if len(replicas) == 0 {
+				return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("only leader %s matches the filters; pass --force to restart it", leader.ID))
 			}
-			// TODO - wait for health checks to pass
 		}
 	}
 
-	// Don't perform failover if the cluster is only running a single node.
-	if len(allocs) > 1 {
-		pgclient := flypg.NewFromInstance(leader.PrivateIP, dialer)
+	summary := &restartSummary{}
+	for _, replica := range replicas {
+		summary.addAlloc(replica, "replica")
+	}
+	if restartLeader || leaderSkipped {
+		summary.addAlloc(leader, "leader")
+	}
+	if leaderSkipped {
+		summary.skip(len(replicas), "pass --force to restart it too")
+	}
 
-		fmt.Fprintf(io.Out, "Performing a failover\n")
-		if err := pgclient.Failover(ctx); err != nil {
-			if err := pgclient.Failover(ctx); err != nil {
-				fmt.Fprintln(io.Out, colorize.Yellow(fmt.Sprintf("WARN: failed to perform failover: %s", err.Error())))
-			}
+	for i, replica := range replicas {
+		fmt.Fprintf(io.Out, "Restarting replica %s\n", replica.ID)
+
+		err := client.RestartAllocation(ctx, app.Name, replica.ID)
+		if err == nil {
+			err = waitForNodeRole(ctx, replica.ID, replica.PrivateIP, "replica", timeout)
+		}
+
+		summary.record(i, err)
+		if err != nil && opts.failFast {
+			break
 		}
 	}
 
-	if err := client.RestartAllocation(ctx, app.Name, leader.ID); err != nil {
-		return fmt.Errorf("failed to restart vm %s: %w", leader.ID, err)
+	if restartLeader && summary.failed() > 0 {
+		summary.skip(len(replicas), "not failed over, as replicas failed to restart")
+	} else if restartLeader {
+		summary.record(len(replicas), restartNomadLeader(ctx, app, leader, standbys, opts))
+	}
+
+	if err := summary.render(ctx); err != nil {
+		return err
+	}
+
+	if failed := summary.failed(); failed > 0 {
+		return fmt.Errorf("failed restarting %d of %d members of the Postgres cluster", failed, len(summary.results))
+	}
+
+	if leaderSkipped {
+		fmt.Fprintf(io.Out, "Postgres cluster has been restarted, except for leader %s\n", leader.ID)
+
+		return nil
 	}
 
 	fmt.Fprintf(io.Out, "Postgres cluster has been successfully restarted!\n")
 
 	return nil
+}
 
+// restartNomadLeader fails leader over to one of the standbys, if any, waiting
+// for one of them to take over, then restarts it and waits for it to rejoin as
+// a replica. With force, a failed failover restarts the leader in place.
+func restartNomadLeader(ctx context.Context, app *api.AppCompact, leader *api.AllocationStatus, standbys []*api.AllocationStatus, opts restartOptions) error {
+	var (
+		client   = client.FromContext(ctx).API()
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		role     = "leader"
+	)
+
+	if len(standbys) > 0 {
+		fmt.Fprintf(io.Out, "Attempting to failover %s\n", colorize.Bold(leader.ID))
+
+		err := pgClient(ctx, leader.PrivateIP).Failover(ctx)
+		if err == nil {
+			err = waitForNomadLeader(ctx, standbys, opts.timeout)
+		}
+
+		switch {
+		case err == nil:
+			role = "replica"
+		case !opts.force:
+			return fmt.Errorf("failed to perform failover: %w", err)
+		default:
+			fmt.Fprintln(io.Out, colorize.Red(fmt.Sprintf("failed to perform failover: %s", err.Error())))
+		}
+	}
+
+	fmt.Fprintf(io.Out, "Restarting %s\n", leader.ID)
+	if err := client.RestartAllocation(ctx, app.Name, leader.ID); err != nil {
+		return err
+	}
+
+	return waitForNodeRole(ctx, leader.ID, leader.PrivateIP, role, opts.timeout)
+}
+
+// waitForNomadLeader waits up to timeout for one of standbys to report being
+// the leader of the cluster.
+func waitForNomadLeader(ctx context.Context, standbys []*api.AllocationStatus, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		for _, standby := range standbys {
+			if role, err := pgClient(ctx, standby.PrivateIP).NodeRole(ctx); err == nil && role == "leader" {
+				fmt.Fprintf(iostreams.FromContext(ctx).Out, "%s is the new leader\n", standby.ID)
+
+				return nil
+			}
+		}
+
+		select {
+		case <-time.After(nodeHealthInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("no replica took over as leader within %s", timeout)
+		}
+	}
+}
+
+// memberFilter limits a restart to the members in a region, if given, and to
+// those of some IDs, if any.
+type memberFilter struct {
+	region  string
+	ids     map[string]bool
+	matched map[string]bool
+}
+
+// newMemberFilter returns the filter the --region and --machine flags call
+// for, or nil if they're not set.
+func newMemberFilter(ctx context.Context) *memberFilter {
+	var (
+		region = flag.GetString(ctx, "region")
+		ids    = flag.GetStringSlice(ctx, "machine")
+	)
+
+	if region == "" && len(ids) == 0 {
+		return nil
+	}
+
+	filter := &memberFilter{
+		region:  region,
+		ids:     make(map[string]bool, len(ids)),
+		matched: map[string]bool{},
+	}
+	for _, id := range ids {
+		filter.ids[id] = true
+	}
+
+	return filter
+}
+
+func (f *memberFilter) matches(id, region string) bool {
+	if f.region != "" && region != f.region {
+		return false
+	}
+
+	if len(f.ids) > 0 && !f.ids[id] {
+		return false
+	}
+	f.matched[id] = true
+
+	return true
+}
+
+func (f *memberFilter) matchesAlloc(alloc *api.AllocationStatus) bool {
+	return f.matches(alloc.ID, alloc.Region) || f.matches(alloc.IDShort, alloc.Region)
+}
+
+// validate fails unless the filter matched any member, and every ID given
+// matched one.
+func (f *memberFilter) validate(matchedAny bool) error {
+	var unknown []string
+	for id := range f.ids {
+		if !f.matched[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Strings(unknown)
+
+	switch {
+	case len(unknown) > 0 && f.region != "":
+		return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("%s are not members of the cluster in %s", strings.Join(unknown, ", "), f.region))
+	case len(unknown) > 0:
+		return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("%s are not members of the cluster", strings.Join(unknown, ", ")))
+	case !matchedAny:
+		return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("no members of the cluster match --region %s", f.region))
+	default:
+		return nil
+	}
+}
+
+// restartMember restarts m, waiting up to timeout for it to start and, unless
+// input skips them, pass its health checks.
+func restartMember(ctx context.Context, m *api.Machine, input *api.RestartMachineInput, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := mach.Restart(ctx, m, input); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s didn't become healthy within %s: %w", m.ID, timeout, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// restartResult is the outcome of restarting a member of a cluster.
+type restartResult struct {
+	ID        string `json:"id"`
+	Region    string `json:"region"`
+	PrivateIP string `json:"private_ip"`
+	Role      string `json:"role"`
+	// Status is one of restarted, failed, skipped or pending, for members
+	// the restart stopped before.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type restartSummary struct {
+	results []restartResult
+}
+
+func newRestartSummary(replicas []*api.Machine) *restartSummary {
+	summary := &restartSummary{}
+	for _, replica := range replicas {
+		summary.add(replica, "replica")
+	}
+
+	return summary
+}
+
+func (s *restartSummary) add(m *api.Machine, role string) {
+	s.results = append(s.results, restartResult{
+		ID:        m.ID,
+		Region:    m.Region,
+		PrivateIP: m.PrivateIP,
+		Role:      role,
+		Status:    "pending",
+	})
+}
+
+func (s *restartSummary) addAlloc(alloc *api.AllocationStatus, role string) {
+	s.results = append(s.results, restartResult{
+		ID:        alloc.ID,
+		Region:    alloc.Region,
+		PrivateIP: alloc.PrivateIP,
+		Role:      role,
+		Status:    "pending",
+	})
+}
+
+// record records the outcome of restarting the i-th member.
+func (s *restartSummary) record(i int, err error) {
+	r := &s.results[i]
+	if err == nil {
+		r.Status = "restarted"
+		return
+	}
+
+	r.Status = "failed"
+	r.Error = fmt.Sprintf("machine %s (%s): %v", r.ID, r.PrivateIP, err)
+}
+
+func (s *restartSummary) skip(i int, reason string) {
+	s.results[i].Status = "skipped"
+	s.results[i].Error = reason
+}
+
+func (s *restartSummary) failed() (n int) {
+	for _, r := range s.results {
+		if r.Status == "failed" {
+			n++
+		}
+	}
+
+	return
+}
+
+func (s *restartSummary) render(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, s.results)
+	}
+
+	rows := make([][]string, 0, len(s.results))
+	for _, r := range s.results {
+		rows = append(rows, []string{r.ID, r.Region, r.PrivateIP, r.Role, r.Status, r.Error})
+	}
+
+	return render.Table(io.Out, "Restart summary", rows, "Machine", "Region", "Private IP", "Role", "Status", "Error")
+}
+
+// waitForNodeRole waits up to timeout for the flypg API of the member at ip to
+// report it serves as role, which it only does once postgres is up again.
+func waitForNodeRole(ctx context.Context, id, ip, role string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pgclient := pgClient(ctx, ip)
+
+	for {
+		current, err := pgclient.NodeRole(ctx)
+		if err == nil && current == role {
+			return nil
+		}
+
+		select {
+		case <-time.After(nodeHealthInterval):
+		case <-ctx.Done():
+			if err == nil {
+				err = fmt.Errorf("it reports being %s", current)
+			}
+
+			return fmt.Errorf("%s didn't become a healthy %s within %s: %w", id, role, timeout, err)
+		}
+	}
 }
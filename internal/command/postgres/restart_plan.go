@@ -0,0 +1,338 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flypg"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// leaderProbeTimeout bounds checking the leader of the cluster answers, as
+// failing over requires it to.
+const leaderProbeTimeout = 5 * time.Second
+
+// restartPlan is what restarting a cluster would do, as shown by
+// `pg restart --dry-run`.
+type restartPlan struct {
+	App      string `json:"app"`
+	Platform string `json:"platform"`
+	Leader   string `json:"leader,omitempty"`
+	// LeaseTTL is the number of seconds each member would be leased for
+	// while it restarts. Only machines are leased.
+	LeaseTTL    int           `json:"lease_ttl_seconds,omitempty"`
+	WaitTimeout int           `json:"wait_timeout_seconds"`
+	Steps       []restartStep `json:"steps"`
+	// Problems are the preconditions which would make the restart fail.
+	Problems []string `json:"problems,omitempty"`
+
+	ordered int
+}
+
+// restartStep is what restarting a cluster would do to one of its members.
+type restartStep struct {
+	Order  int    `json:"order,omitempty"`
+	Action string `json:"action"`
+	ID     string `json:"id"`
+	Region string `json:"region"`
+	Role   string `json:"role"`
+	State  string `json:"state"`
+	Image  string `json:"image"`
+	Note   string `json:"note,omitempty"`
+}
+
+func (p *restartPlan) add(action string, step restartStep) {
+	step.Action = action
+	if action != "skip" {
+		p.ordered++
+		step.Order = p.ordered
+	}
+	p.Steps = append(p.Steps, step)
+}
+
+func (p *restartPlan) problem(format string, a ...interface{}) {
+	p.Problems = append(p.Problems, fmt.Sprintf(format, a...))
+}
+
+// runRestartDryRun shows what restarting the cluster would do, without
+// leasing or restarting any of its members, and fails should any
+// precondition keep the restart from succeeding.
+func runRestartDryRun(ctx context.Context, app *api.AppCompact, opts restartOptions) error {
+	plan := &restartPlan{
+		App:         app.Name,
+		Platform:    app.PlatformVersion,
+		WaitTimeout: int(opts.timeout.Seconds()),
+		Steps:       []restartStep{},
+	}
+
+	var err error
+	switch app.PlatformVersion {
+	case "machines":
+		err = planMachinesRestart(ctx, plan, opts)
+	case "nomad":
+		err = planNomadRestart(ctx, app, plan, opts)
+	default:
+		return fmt.Errorf("unknown platform version")
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := renderRestartPlan(ctx, plan); err != nil {
+		return err
+	}
+
+	if len(plan.Problems) > 0 {
+		return flyerr.WithCode(flyerr.CodeValidation,
+			fmt.Errorf("restarting the cluster would fail: %s", strings.Join(plan.Problems, "; ")))
+	}
+
+	return nil
+}
+
+// planMachinesRestart plans the restart machinesRestart would carry out.
+func planMachinesRestart(ctx context.Context, plan *restartPlan, opts restartOptions) error {
+	const minPostgresHaVersion = "0.0.20"
+
+	plan.LeaseTTL = mach.LeaseTTL
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := hasRequiredVersionOnMachines(machines, minPostgresHaVersion, minPostgresHaVersion); err != nil {
+		plan.problem("%v", err)
+	}
+
+	leader, replicas := flypg.MachineNodeRoles(machines)
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].ID < replicas[j].ID
+	})
+
+	selected, restartLeader, err := selectMachineMembers(ctx, leader, replicas, opts)
+	if err != nil {
+		return err
+	}
+
+	step := func(m *api.Machine) restartStep {
+		s := restartStep{
+			ID:     m.ID,
+			Region: m.Region,
+			Role:   flypg.MachineRole(m),
+			State:  m.State,
+			Image:  m.ImageRefWithVersion(),
+		}
+		if m.State == "stopped" {
+			s.Note = "stopped, so restarting starts it"
+		}
+
+		return s
+	}
+
+	chosen := make(map[string]bool, len(selected))
+	for _, replica := range selected {
+		chosen[replica.ID] = true
+		plan.add("restart", step(replica))
+	}
+	for _, replica := range replicas {
+		if !chosen[replica.ID] {
+			s := step(replica)
+			s.Note = "not matched by the filters"
+			plan.add("skip", s)
+		}
+	}
+
+	if leader == nil {
+		if !opts.force {
+			plan.problem("no active leader found; pass --force to restart every member in place")
+		}
+
+		return nil
+	}
+	plan.Leader = leader.ID
+
+	if !restartLeader {
+		s := step(leader)
+		s.Note = "not matched by the filters, or matched without --force"
+		plan.add("skip", s)
+
+		return nil
+	}
+
+	inRegionReplicas := 0
+	for _, replica := range replicas {
+		if replica.Region == leader.Region {
+			inRegionReplicas++
+		}
+	}
+
+	switch {
+	case inRegionReplicas > 0:
+		if err := probeLeader(ctx, leader.PrivateIP); err != nil {
+			plan.problem("leader %s can't be reached to fail over: %v", leader.ID, err)
+		}
+
+		s := step(leader)
+		s.Note = fmt.Sprintf("fails over to one of %d replicas in %s", inRegionReplicas, leader.Region)
+		plan.add("failover", s)
+	case !opts.force:
+		plan.problem("there is no replica in %s for leader %s to fail over to; pass --force to restart it in place", leader.Region, leader.ID)
+	}
+
+	s := step(leader)
+	if inRegionReplicas == 0 {
+		s.Note = "restarted in place, without failing over"
+	}
+	plan.add("restart", s)
+
+	return nil
+}
+
+// planNomadRestart plans the restart nomadRestart would carry out.
+func planNomadRestart(ctx context.Context, app *api.AppCompact, plan *restartPlan, opts restartOptions) error {
+	const minPostgresHaVersion = "0.0.20"
+
+	if err := hasRequiredVersionOnNomad(app, minPostgresHaVersion, minPostgresHaVersion); err != nil {
+		plan.problem("%v", err)
+	}
+
+	allocs, err := client.FromContext(ctx).API().GetAllocations(ctx, app.Name, false)
+	if err != nil {
+		return fmt.Errorf("can't fetch allocations: %w", err)
+	}
+
+	image := app.ImageDetails.Repository + ":" + app.ImageDetails.Version
+
+	// roles are looked up from each member, failing should any not answer
+	leader, replicas, err := nomadNodeRoles(ctx, allocs)
+	if err != nil {
+		plan.problem("%v", err)
+
+		for _, alloc := range allocs {
+			plan.add("skip", restartStep{ID: alloc.IDShort, Region: alloc.Region, Role: "unknown", State: alloc.Status, Image: image})
+		}
+
+		return nil
+	}
+	if leader == nil {
+		plan.problem("no leader found")
+
+		return nil
+	}
+	plan.Leader = leader.IDShort
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].ID < replicas[j].ID
+	})
+
+	step := func(alloc *api.AllocationStatus, role string) restartStep {
+		return restartStep{ID: alloc.IDShort, Region: alloc.Region, Role: role, State: alloc.Status, Image: image}
+	}
+
+	if len(replicas) == 0 && !opts.force {
+		plan.problem("there is no replica for leader %s to fail over to; pass --force to restart it in place", leader.ID)
+	}
+
+	var (
+		restartLeader = true
+		skipped       []*api.AllocationStatus
+		targeted      = replicas
+	)
+	if filter := opts.filter; filter != nil {
+		targeted = nil
+		for _, replica := range replicas {
+			if filter.matchesAlloc(replica) {
+				targeted = append(targeted, replica)
+			} else {
+				skipped = append(skipped, replica)
+			}
+		}
+		restartLeader = filter.matchesAlloc(leader) && opts.force
+
+		if err := filter.validate(len(targeted) > 0 || filter.matchesAlloc(leader)); err != nil {
+			return err
+		}
+	}
+
+	for _, replica := range targeted {
+		plan.add("restart", step(replica, "replica"))
+	}
+	for _, replica := range skipped {
+		s := step(replica, "replica")
+		s.Note = "not matched by the filters"
+		plan.add("skip", s)
+	}
+
+	if !restartLeader {
+		s := step(leader, "leader")
+		s.Note = "not matched by the filters, or matched without --force"
+		plan.add("skip", s)
+
+		return nil
+	}
+
+	if len(replicas) > 0 {
+		if err := probeLeader(ctx, leader.PrivateIP); err != nil {
+			plan.problem("leader %s can't be reached to fail over: %v", leader.IDShort, err)
+		}
+		plan.add("failover", step(leader, "leader"))
+	}
+	plan.add("restart", step(leader, "leader"))
+
+	return nil
+}
+
+// probeLeader checks the flypg API of the leader at ip answers.
+func probeLeader(ctx context.Context, ip string) error {
+	ctx, cancel := context.WithTimeout(ctx, leaderProbeTimeout)
+	defer cancel()
+
+	_, err := pgClient(ctx, ip).NodeRole(ctx)
+
+	return err
+}
+
+func renderRestartPlan(ctx context.Context, plan *restartPlan) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+	)
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, plan)
+	}
+
+	rows := make([][]string, 0, len(plan.Steps))
+	for _, s := range plan.Steps {
+		order := "-"
+		if s.Order > 0 {
+			order = fmt.Sprint(s.Order)
+		}
+		rows = append(rows, []string{order, s.Action, s.ID, s.Region, s.Role, s.State, s.Image, s.Note})
+	}
+
+	if err := render.Table(io.Out, fmt.Sprintf("Restart plan for %s (dry run)", plan.App), rows,
+		"Order", "Action", "Member", "Region", "Role", "State", "Image", "Note"); err != nil {
+		return err
+	}
+
+	if plan.LeaseTTL > 0 {
+		fmt.Fprintf(io.Out, "Each member would be leased for %ds, refreshed while it restarts\n", plan.LeaseTTL)
+	}
+	fmt.Fprintf(io.Out, "Each member would be given %ds to become healthy again\n", plan.WaitTimeout)
+
+	for _, problem := range plan.Problems {
+		fmt.Fprintln(io.ErrOut, colorize.Red("✘ "+problem))
+	}
+
+	return nil
+}
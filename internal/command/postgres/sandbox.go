@@ -0,0 +1,514 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/flypg"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	// sandboxOfMetadataKey names the cluster the sandbox machine was forked
+	// from.
+	sandboxOfMetadataKey = "fly_sandbox_of"
+	// sandboxExpiresAtMetadataKey holds the time, in RFC 3339, after which
+	// sandbox prune destroys the sandbox.
+	sandboxExpiresAtMetadataKey = "fly_sandbox_expires_at"
+)
+
+func newSandbox() *cobra.Command {
+	const (
+		short = "Manage disposable clones of a Postgres cluster"
+		long  = short + `. Sandboxes run on a fork of the volume of the
+cluster's leader, so heavy queries can be tried out against its data without
+touching the cluster itself.` + "\n"
+	)
+
+	cmd := command.New("sandbox", short, long, nil)
+
+	cmd.AddCommand(
+		newSandboxCreate(),
+		newSandboxList(),
+		newSandboxDestroy(),
+		newSandboxPrune(),
+	)
+
+	return cmd
+}
+
+func newSandboxCreate() *cobra.Command {
+	const (
+		short = "Create a sandbox from the leader of the cluster"
+		long  = short + `. The sandbox is a single Postgres machine, in the
+leader's region, on a fork of the leader's volume. It's destroyed by
+sandbox prune once its --ttl runs out.` + "\n"
+		usage = "create"
+	)
+
+	cmd := command.New(usage, short, long, runSandboxCreate,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "vm-size",
+			Description: "The VM size of the sandbox; defaults to that of the leader",
+		},
+		flag.String{
+			Name:        "ttl",
+			Description: "How long the sandbox is kept for, such as 30m or 8h",
+			Default:     "4h",
+		},
+	)
+
+	return cmd
+}
+
+func runSandboxCreate(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		client  = client.FromContext(ctx).API()
+		io      = iostreams.FromContext(ctx)
+	)
+
+	ttl, err := time.ParseDuration(flag.GetString(ctx, "ttl"))
+	if err != nil || ttl <= 0 {
+		return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("--ttl must be a positive duration, such as 30m or 8h"))
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	if !app.IsPostgresApp() {
+		return notPostgresAppError(appName)
+	}
+
+	if app.PlatformVersion != "machines" {
+		return flyerr.WithCode(flyerr.CodePlatformUnsupported, errors.New("sandboxes are only supported for Postgres clusters on machines"))
+	}
+
+	if ctx, err = buildContext(ctx, app); err != nil {
+		return err
+	}
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	leader, _ := flypg.MachineNodeRoles(machines)
+	switch {
+	case leader == nil:
+		return fmt.Errorf("no active leader found to fork the sandbox from")
+	case leader.Config == nil || len(leader.Config.Mounts) == 0:
+		return fmt.Errorf("leader %s has no volume to fork the sandbox from", leader.ID)
+	}
+
+	vmSize, err := sandboxVMSize(ctx, leader)
+	if err != nil {
+		return err
+	}
+
+	suffix, err := helpers.RandString(6)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mount     = leader.Config.Mounts[0]
+		name      = sandboxPrefix(app.Name) + strings.ToLower(suffix)
+		expiresAt = time.Now().Add(ttl).UTC()
+	)
+
+	fmt.Fprintf(io.Out, "Forking volume %s of leader %s into sandbox %s\n", mount.Volume, leader.ID, name)
+
+	input := &flypg.CreateClusterInput{
+		AppName:            name,
+		ImageRef:           leader.FullImageRef(),
+		InitialClusterSize: 1,
+		Organization: &api.Organization{
+			ID:   app.Organization.ID,
+			Slug: app.Organization.Slug,
+			Name: app.Organization.Slug,
+		},
+		Region:     leader.Region,
+		VolumeSize: api.IntPointer(mount.SizeGb),
+		VMSize:     vmSize,
+		ForkFrom:   api.StringPointer(mount.Volume),
+		Metadata: map[string]string{
+			sandboxOfMetadataKey:        app.Name,
+			sandboxExpiresAtMetadataKey: expiresAt.Format(time.RFC3339),
+		},
+	}
+
+	if err := flypg.NewLauncher(client).LaunchMachinesPostgres(ctx, input, false); err != nil {
+		return fmt.Errorf("failed creating sandbox %s; destroy what's left of it with fly apps destroy %s: %w", name, name, err)
+	}
+
+	fmt.Fprintf(io.Out, "Sandbox %s expires at %s; fly pg sandbox prune destroys it from then on\n", name, expiresAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// sandboxVMSize returns the size given with --vm-size or, failing that, the
+// size of leader.
+func sandboxVMSize(ctx context.Context, leader *api.Machine) (*api.VMSize, error) {
+	if name := flag.GetString(ctx, "vm-size"); name != "" {
+		return resolveVMSize(ctx, "machines", name)
+	}
+
+	guest := leader.Config.Guest
+	if guest == nil {
+		return resolveVMSize(ctx, "machines", MachineVMSizes()[0].Name)
+	}
+
+	return &api.VMSize{
+		CPUClass: guest.CPUKind,
+		CPUCores: float32(guest.CPUs),
+		MemoryMB: guest.MemoryMB,
+	}, nil
+}
+
+func newSandboxList() *cobra.Command {
+	const (
+		short = "List the sandboxes of the cluster"
+		long  = short + "\n"
+		usage = "list"
+	)
+
+	cmd := command.New(usage, short, long, runSandboxList,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+	cmd.Aliases = []string{"ls"}
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runSandboxList(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	sandboxes, err := listSandboxes(ctx, app.NameFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if len(sandboxes) == 0 {
+		fmt.Fprintln(io.Out, "No sandboxes found")
+
+		return nil
+	}
+
+	rows := make([][]string, 0, len(sandboxes))
+	for _, sb := range sandboxes {
+		expires := sb.expiresAt.Format(time.RFC3339)
+		if sb.expired() {
+			expires += " (expired)"
+		}
+
+		rows = append(rows, []string{sb.app.Name, sb.machine.ID, sb.machine.Region, sb.machine.State, expires})
+	}
+
+	return render.Table(io.Out, "", rows, "Name", "Machine", "Region", "State", "Expires")
+}
+
+func newSandboxDestroy() *cobra.Command {
+	const (
+		short = "Destroy a sandbox of the cluster, along with its volume"
+		long  = short + "\n"
+		usage = "destroy <sandbox>"
+	)
+
+	cmd := command.New(usage, short, long, runSandboxDestroy,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+	)
+
+	return cmd
+}
+
+func runSandboxDestroy(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		name    = flag.FirstArg(ctx)
+	)
+
+	sandboxes, err := listSandboxes(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	for _, sb := range sandboxes {
+		if sb.app.Name != name {
+			continue
+		}
+
+		switch confirmed, err := confirmSandboxDestroy(ctx, fmt.Sprintf("Destroy sandbox %s along with its volume?", name)); {
+		case err != nil:
+			return err
+		case !confirmed:
+			return nil
+		}
+
+		return destroySandbox(ctx, sb)
+	}
+
+	return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf("%s is not a sandbox of %s; see fly pg sandbox list", name, appName))
+}
+
+func newSandboxPrune() *cobra.Command {
+	const (
+		short = "Destroy the sandboxes of the cluster which have expired"
+		long  = short + "\n"
+		usage = "prune"
+	)
+
+	cmd := command.New(usage, short, long, runSandboxPrune,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+	)
+
+	return cmd
+}
+
+func runSandboxPrune(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	sandboxes, err := listSandboxes(ctx, app.NameFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	var expired []*sandbox
+	for _, sb := range sandboxes {
+		if sb.expired() {
+			expired = append(expired, sb)
+		}
+	}
+
+	if len(expired) == 0 {
+		fmt.Fprintln(io.Out, "No expired sandboxes to prune")
+
+		return nil
+	}
+
+	names := make([]string, 0, len(expired))
+	for _, sb := range expired {
+		names = append(names, sb.app.Name)
+	}
+
+	switch confirmed, err := confirmSandboxDestroy(ctx, fmt.Sprintf("Destroy %d expired sandboxes (%s) along with their volumes?", len(expired), strings.Join(names, ", "))); {
+	case err != nil:
+		return err
+	case !confirmed:
+		return nil
+	}
+
+	var pruned, failed int
+	for _, sb := range expired {
+		if err := destroySandbox(ctx, sb); err != nil {
+			fmt.Fprintf(io.ErrOut, "failed destroying sandbox %s: %v\n", sb.app.Name, err)
+			failed++
+
+			continue
+		}
+		pruned++
+	}
+
+	fmt.Fprintf(io.Out, "Pruned %d expired sandboxes\n", pruned)
+
+	if failed > 0 {
+		return fmt.Errorf("failed destroying %d expired sandboxes", failed)
+	}
+
+	return nil
+}
+
+// confirmSandboxDestroy asks to confirm destroying sandboxes with msg, unless
+// --yes is given.
+func confirmSandboxDestroy(ctx context.Context, msg string) (bool, error) {
+	if flag.GetYes(ctx) {
+		return true, nil
+	}
+
+	switch confirmed, err := prompt.Confirm(ctx, msg); {
+	case err == nil:
+		return confirmed, nil
+	case prompt.IsNonInteractive(err):
+		return false, prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+	default:
+		return false, err
+	}
+}
+
+// sandbox is a sandbox app and the machine carrying its expiry.
+type sandbox struct {
+	app       *api.AppCompact
+	machine   *api.Machine
+	expiresAt time.Time
+}
+
+func (sb *sandbox) expired() bool {
+	return !time.Now().Before(sb.expiresAt)
+}
+
+func sandboxPrefix(appName string) string {
+	return appName + "-sandbox-"
+}
+
+// listSandboxes returns the sandboxes forked from the cluster appName, as told
+// apart by the name of their apps and the metadata of their machines. Apps
+// whose machines carry no expiry of a sandbox of appName aren't sandboxes as
+// far as flyctl can tell, and apps whose machines can't be listed are skipped
+// and reported.
+func listSandboxes(ctx context.Context, appName string) ([]*sandbox, error) {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+	)
+
+	clusters, err := client.GetApps(ctx, api.StringPointer("postgres_cluster"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list postgres clusters: %w", err)
+	}
+
+	var sandboxes []*sandbox
+	for _, cluster := range clusters {
+		if !strings.HasPrefix(cluster.Name, sandboxPrefix(appName)) {
+			continue
+		}
+
+		sb, err := findSandbox(ctx, cluster.Name, appName)
+		switch {
+		case err != nil:
+			fmt.Fprintf(io.ErrOut, "Skipping %s: %v\n", cluster.Name, err)
+		case sb != nil:
+			sandboxes = append(sandboxes, sb)
+		}
+	}
+
+	return sandboxes, nil
+}
+
+// findSandbox returns the sandbox of appName the app named name is, or nil if
+// none of its machines carries the expiry of one.
+func findSandbox(ctx context.Context, name, appName string) (*sandbox, error) {
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := flapsClient.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed listing machines of %s: %w", app.Name, err)
+	}
+
+	for _, m := range machines {
+		if m.Config == nil || m.Config.Metadata[sandboxOfMetadataKey] != appName {
+			continue
+		}
+
+		expiresAt, err := time.Parse(time.RFC3339, m.Config.Metadata[sandboxExpiresAtMetadataKey])
+		if err != nil {
+			return nil, fmt.Errorf("sandbox %s has an invalid expiry: %w", app.Name, err)
+		}
+
+		return &sandbox{app: app, machine: m, expiresAt: expiresAt}, nil
+	}
+
+	return nil, nil
+}
+
+// destroySandbox destroys the machines of sb and their volumes, and then the
+// app of sb itself.
+func destroySandbox(ctx context.Context, sb *sandbox) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+	)
+
+	flapsClient, err := flaps.New(ctx, sb.app)
+	if err != nil {
+		return err
+	}
+
+	machines, err := flapsClient.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed listing machines of %s: %w", sb.app.Name, err)
+	}
+
+	for _, m := range machines {
+		input := api.RemoveMachineInput{
+			AppID: sb.app.Name,
+			ID:    m.ID,
+			Kill:  true,
+		}
+		if err := flapsClient.Destroy(ctx, input); err != nil {
+			return fmt.Errorf("failed destroying machine %s: %w", m.ID, err)
+		}
+		fmt.Fprintf(io.Out, "Destroyed machine %s\n", m.ID)
+
+		if m.Config == nil {
+			continue
+		}
+
+		for _, mount := range m.Config.Mounts {
+			if _, err := client.DeleteVolume(ctx, mount.Volume); err != nil {
+				return fmt.Errorf("failed deleting volume %s: %w", mount.Volume, err)
+			}
+			fmt.Fprintf(io.Out, "Deleted volume %s\n", mount.Volume)
+		}
+	}
+
+	if err := client.DeleteApp(ctx, sb.app.Name); err != nil {
+		return fmt.Errorf("failed deleting app %s: %w", sb.app.Name, err)
+	}
+	fmt.Fprintf(io.Out, "Destroyed sandbox %s\n", sb.app.Name)
+
+	return nil
+}
@@ -8,10 +8,8 @@ import (
 	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
-	"github.com/superfly/flyctl/flypg"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
-	"github.com/superfly/flyctl/internal/command/apps"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	mach "github.com/superfly/flyctl/internal/machine"
@@ -31,6 +29,7 @@ func newUsers() *cobra.Command {
 
 	cmd.AddCommand(
 		newListUsers(),
+		newUpdateUser(),
 	)
 
 	return cmd
@@ -70,10 +69,10 @@ func runListUsers(ctx context.Context) error {
 	}
 
 	if !app.IsPostgresApp() {
-		return fmt.Errorf("app %s is not a postgres app", appName)
+		return notPostgresAppError(appName)
 	}
 
-	ctx, err = apps.BuildContext(ctx, app)
+	ctx, err = buildContext(ctx, app)
 	if err != nil {
 		return err
 	}
@@ -114,17 +113,13 @@ func runMachineListUsers(ctx context.Context, app *api.AppCompact) (err error) {
 func runNomadListUsers(ctx context.Context, app *api.AppCompact) (err error) {
 	var (
 		MinPostgresHaVersion = "0.0.19"
-		client               = client.FromContext(ctx).API()
 	)
 
 	if err := hasRequiredVersionOnNomad(app, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
 		return err
 	}
 
-	agentclient, err := agent.Establish(ctx, client)
-	if err != nil {
-		return fmt.Errorf("failed to establish agent: %w", err)
-	}
+	agentclient := agent.ClientFromContext(ctx)
 
 	pgInstances, err := agentclient.Instances(ctx, app.Organization.Slug, app.Name)
 	if err != nil {
@@ -144,12 +139,11 @@ func runNomadListUsers(ctx context.Context, app *api.AppCompact) (err error) {
 
 func renderUsers(ctx context.Context, leaderIP string) error {
 	var (
-		io     = iostreams.FromContext(ctx)
-		cfg    = config.FromContext(ctx)
-		dialer = agent.DialerFromContext(ctx)
+		io  = iostreams.FromContext(ctx)
+		cfg = config.FromContext(ctx)
 	)
 
-	pgclient := flypg.NewFromInstance(leaderIP, dialer)
+	pgclient := pgClient(ctx, leaderIP)
 
 	users, err := pgclient.ListUsers(ctx)
 	if err != nil {
@@ -0,0 +1,349 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flypg"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// clusterUsers are the users the cluster itself runs as, whose passwords are
+// kept in the secrets of the postgres app rather than of consumer apps.
+var clusterUsers = map[string]bool{
+	"flypgadmin": true,
+	"postgres":   true,
+	"repluser":   true,
+}
+
+func newUpdateUser() *cobra.Command {
+	const (
+		short = "Update a user"
+		long  = short + `. --password-rotate generates a new password for the user
+and updates the secrets of every app it's attached to with it.
+`
+
+		usage = "update <username>"
+	)
+
+	cmd := command.New(usage, short, long, runUpdateUser,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Bool{
+			Name:        "password-rotate",
+			Description: "Generate a new password and update the attached apps to use it",
+		},
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "List the apps --password-rotate would update without changing anything",
+		},
+		flag.Yes(),
+	)
+
+	return cmd
+}
+
+func runUpdateUser(ctx context.Context) error {
+	var (
+		client  = client.FromContext(ctx).API()
+		appName = app.NameFromContext(ctx)
+	)
+
+	if !flag.GetBool(ctx, "password-rotate") {
+		return errors.New("nothing to update; pass --password-rotate to rotate the password of the user")
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	if !app.IsPostgresApp() {
+		return notPostgresAppError(appName)
+	}
+
+	ctx, err = buildContext(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	switch app.PlatformVersion {
+	case "machines":
+		return runMachineUpdateUser(ctx, app)
+	case "nomad":
+		return runNomadUpdateUser(ctx, app)
+	default:
+		return fmt.Errorf("unknown platform version")
+	}
+}
+
+func runMachineUpdateUser(ctx context.Context, app *api.AppCompact) error {
+	var (
+		MinPostgresHaVersion = "0.0.19"
+	)
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("machines could not be retrieved %w", err)
+	}
+
+	if err := hasRequiredVersionOnMachines(machines, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
+		return err
+	}
+
+	leader, err := pickLeader(ctx, machines)
+	if err != nil {
+		return err
+	}
+
+	return rotatePassword(ctx, app, leader.PrivateIP, flag.FirstArg(ctx))
+}
+
+func runNomadUpdateUser(ctx context.Context, app *api.AppCompact) error {
+	var (
+		MinPostgresHaVersion = "0.0.19"
+	)
+
+	if err := hasRequiredVersionOnNomad(app, MinPostgresHaVersion, MinPostgresHaVersion); err != nil {
+		return err
+	}
+
+	agentclient := agent.ClientFromContext(ctx)
+
+	pgInstances, err := agentclient.Instances(ctx, app.Organization.Slug, app.Name)
+	if err != nil {
+		return fmt.Errorf("failed to lookup 6pn ip for %s app: %v", app.Name, err)
+	}
+	if len(pgInstances.Addresses) == 0 {
+		return fmt.Errorf("no 6pn ips found for %s app", app.Name)
+	}
+
+	leaderIP, err := leaderIpFromNomadInstances(ctx, pgInstances.Addresses)
+	if err != nil {
+		return err
+	}
+
+	return rotatePassword(ctx, app, leaderIP, flag.FirstArg(ctx))
+}
+
+// userAttachment is an attachment of a postgres cluster to a consumer app
+// through the user a password rotation is for.
+type userAttachment struct {
+	App        string
+	Attachment *api.PostgresClusterAttachment
+	// AppAttachments are all the attachments of the cluster to App.
+	AppAttachments []*api.PostgresClusterAttachment
+}
+
+// userAttachments returns the attachments of pgApp through username, found by
+// looking for them on every other app of its organization.
+func userAttachments(ctx context.Context, pgApp *api.AppCompact, username string) ([]userAttachment, error) {
+	client := client.FromContext(ctx).API()
+
+	apps, err := client.GetApps(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing apps: %w", err)
+	}
+
+	var names []string
+	for _, app := range apps {
+		if app.Name != pgApp.Name && app.Organization.Slug == pgApp.Organization.Slug {
+			names = append(names, app.Name)
+		}
+	}
+
+	attachments, err := client.ListPostgresClusterAttachmentsOfApps(ctx, names, pgApp.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing attachments of %s: %w", pgApp.Name, err)
+	}
+
+	var found []userAttachment
+	for _, name := range names {
+		for _, attachment := range attachments[name] {
+			if attachment.DatabaseUser == username {
+				found = append(found, userAttachment{App: name, Attachment: attachment, AppAttachments: attachments[name]})
+			}
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].App < found[j].App
+	})
+
+	return found, nil
+}
+
+// rotatePassword sets a new password for username on the leader of app and
+// updates the secrets of the apps attached through it. The secrets are
+// prepared before the password changes so the consumer apps are left using
+// the old one for as short as possible.
+func rotatePassword(ctx context.Context, app *api.AppCompact, leaderIP, username string) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		client   = client.FromContext(ctx).API()
+		pgclient = pgClient(ctx, leaderIP)
+	)
+
+	if clusterUsers[username] {
+		return fmt.Errorf("%s is used by the cluster itself; its password can't be rotated this way", username)
+	}
+
+	exists, err := pgclient.UserExists(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("user %s does not exist on %s", username, app.Name)
+	}
+
+	attachments, err := userAttachments(ctx, app, username)
+	if err != nil {
+		return err
+	}
+
+	if len(attachments) == 0 {
+		fmt.Fprintf(io.Out, "No apps are attached to %s as %s; only the password of the user will change\n", app.Name, username)
+	} else {
+		fmt.Fprintf(io.Out, "These apps are attached to %s as %s and will have their secrets updated:\n", app.Name, username)
+		for _, a := range attachments {
+			fmt.Fprintf(io.Out, "  %s (%s of database %s)\n", a.App, a.Attachment.EnvironmentVariableName, a.Attachment.DatabaseName)
+		}
+	}
+
+	if flag.GetBool(ctx, "dry-run") {
+		return nil
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Rotate the password of %s?", username); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	pwd, err := helpers.RandString(15)
+	if err != nil {
+		return err
+	}
+
+	// Each app gets all of its secrets in a single update, so it's
+	// deployed with the new password once.
+	var apps []string
+	payloads := map[string]map[string]string{}
+	for _, a := range attachments {
+		if payloads[a.App] == nil {
+			apps = append(apps, a.App)
+
+			payload, err := pgSecrets(ctx, a, username, pwd)
+			if err != nil {
+				return err
+			}
+			payloads[a.App] = payload
+		}
+
+		payloads[a.App][a.Attachment.EnvironmentVariableName] = fmt.Sprintf(
+			"postgres://%s:%s@top2.nearest.of.%s.internal:5432/%s?sslmode=disable",
+			username, pwd, app.Name, a.Attachment.DatabaseName,
+		)
+	}
+
+	cmd := flypg.NewCommandWithDialer(ctx, app, agent.DialerFromContext(ctx))
+	if err := cmd.UpdateUserPassword(ctx, leaderIP, username, pwd); err != nil {
+		return fmt.Errorf("failed updating the password of %s: %w", username, err)
+	}
+	fmt.Fprintf(io.Out, "Updated the password of %s\n", username)
+
+	var failed []string
+	for _, name := range apps {
+		if _, err := client.SetSecrets(ctx, name, payloads[name]); err != nil {
+			fmt.Fprintf(io.ErrOut, "Failed updating the secrets of %s: %v\n", name, err)
+			failed = append(failed, name)
+			continue
+		}
+
+		fmt.Fprintf(io.Out, "Updated the secrets of %s\n", name)
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(io.ErrOut, colorize.Yellow(fmt.Sprintf("These apps still use the old password of %s; rotating it again updates them: %s", username, strings.Join(failed, ", "))))
+
+	return fmt.Errorf("failed updating the secrets of %d of %d attached apps", len(failed), len(apps))
+}
+
+// pgSecrets returns the secrets of the app of a besides its attachments which
+// hold the password of username, as set to pwd.
+func pgSecrets(ctx context.Context, a userAttachment, username, pwd string) (map[string]string, error) {
+	secrets, err := client.FromContext(ctx).API().GetAppSecrets(ctx, a.App)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving secrets of %s: %w", a.App, err)
+	}
+
+	names := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		names = append(names, secret.Name)
+	}
+
+	payload := map[string]string{}
+	for _, name := range passwordSecrets(names, a.AppAttachments, username) {
+		payload[name] = pwd
+	}
+
+	return payload, nil
+}
+
+// passwordSecrets returns those of secrets, the names of the secrets of an
+// app, which hold the password of username. Secret values can't be read, so
+// PGPASSWORD and PG_PASSWORD are taken to hold the password of the user the
+// DATABASE_URL of the app is attached through, and are left be unless that's
+// username.
+func passwordSecrets(secrets []string, attachments []*api.PostgresClusterAttachment, username string) (names []string) {
+	var matches bool
+	for _, attachment := range attachments {
+		if attachment.EnvironmentVariableName == "DATABASE_URL" {
+			matches = attachment.DatabaseUser == username
+		}
+	}
+
+	if !matches {
+		return nil
+	}
+
+	for _, secret := range secrets {
+		if secret == "PGPASSWORD" || secret == "PG_PASSWORD" {
+			names = append(names, secret)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/superfly/flyctl/api"
+)
+
+func TestPasswordSecrets(t *testing.T) {
+	secrets := []string{"DATABASE_URL", "PGPASSWORD", "PG_PASSWORD", "OTHER_DATABASE_URL", "API_KEY"}
+
+	cases := []struct {
+		name        string
+		attachments []*api.PostgresClusterAttachment
+		want        []string
+	}{
+		{
+			name: "attached as the user",
+			attachments: []*api.PostgresClusterAttachment{
+				{EnvironmentVariableName: "DATABASE_URL", DatabaseUser: "app"},
+			},
+			want: []string{"PGPASSWORD", "PG_PASSWORD"},
+		},
+		{
+			name: "attached as another user",
+			attachments: []*api.PostgresClusterAttachment{
+				{EnvironmentVariableName: "DATABASE_URL", DatabaseUser: "other"},
+				{EnvironmentVariableName: "OTHER_DATABASE_URL", DatabaseUser: "app"},
+			},
+		},
+		{
+			name: "attached as the user through another variable only",
+			attachments: []*api.PostgresClusterAttachment{
+				{EnvironmentVariableName: "OTHER_DATABASE_URL", DatabaseUser: "app"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, passwordSecrets(secrets, tc.attachments, "app"))
+		})
+	}
+
+	assert.Empty(t, passwordSecrets([]string{"DATABASE_URL"}, []*api.PostgresClusterAttachment{
+		{EnvironmentVariableName: "DATABASE_URL", DatabaseUser: "app"},
+	}, "app"), "apps without password secrets")
+}
@@ -1,11 +1,15 @@
 package redis
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
-	"time"
+	"strings"
 
+	"github.com/google/shlex"
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/agent"
@@ -15,23 +19,38 @@ import (
 
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
 )
 
+const localProxyPort = "16379"
+
 func newConnect() (cmd *cobra.Command) {
 	const (
-		long = `Connect to a Redis database using redis-cli`
-
-		short = long
-		usage = "connect"
+		short = `Connect to a Redis database using redis-cli`
+		long  = short + `
+
+The database is proxied through the agent's tunnel to a local port. Without
+a local redis-cli, or with --remote, commands are run through flyctl's own
+client instead. With --json, the proxy's details are printed and it's kept
+open until interrupted.
+`
+		usage = "connect [name]"
 	)
 
 	cmd = command.New(usage, short, long, runConnect, command.RequireSession)
 
+	cmd.Args = cobra.MaximumNArgs(1)
+
 	flag.Add(cmd,
 		flag.Org(),
 		flag.Region(),
+		flag.Bool{
+			Name:        "remote",
+			Description: "Run commands through flyctl's built-in client rather than a local redis-cli",
+		},
 	)
 
 	return cmd
@@ -43,32 +62,14 @@ func runConnect(ctx context.Context) (err error) {
 		io     = iostreams.FromContext(ctx)
 	)
 
-	if err != nil {
-		return err
-	}
-
-	var index int
-	var options []string
-
-	result, err := gql.ListAddOns(ctx, client.GenqClient, "redis")
-	if err != nil {
-		return
-	}
-
-	databases := result.AddOns.Nodes
-
-	for _, database := range databases {
-		options = append(options, fmt.Sprintf("%s (%s) %s", database.Name, database.PrimaryRegion, database.Organization.Slug))
-	}
-
-	err = prompt.Select(ctx, &index, "Select a database to connect to", "", options...)
+	name, err := selectDatabase(ctx)
 	if err != nil {
 		return
 	}
 
-	response, err := gql.GetAddOn(ctx, client.GenqClient, databases[index].Name)
+	response, err := gql.GetAddOn(ctx, client.GenqClient, name)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed retrieving Redis database %s: %w", name, err)
 	}
 
 	database := response.AddOn
@@ -83,32 +84,129 @@ func runConnect(ctx context.Context) (err error) {
 		return err
 	}
 
-	localProxyPort := "16379"
+	jsonOutput := config.FromContext(ctx).JSONOutput
 
-	params := &proxy.ConnectParams{
+	redisCliPath, lookErr := exec.LookPath("redis-cli")
+	if !jsonOutput && (flag.GetBool(ctx, "remote") || lookErr != nil) {
+		if lookErr != nil && !flag.GetBool(ctx, "remote") {
+			fmt.Fprintln(io.ErrOut, "Could not find redis-cli in your $PATH; running commands through flyctl instead")
+		}
+
+		return runShell(ctx, dialer, &database)
+	}
+
+	server, err := proxy.NewServer(ctx, &proxy.ConnectParams{
 		Ports:            []string{localProxyPort, "6379"},
 		OrganizationSlug: database.Organization.Slug,
 		Dialer:           dialer,
 		RemoteHost:       database.PrivateIp,
+		DisableSpinner:   jsonOutput,
+	})
+	if err != nil {
+		return err
 	}
 
-	go proxy.Connect(ctx, params)
+	go server.ProxyServer(ctx)
+
+	if jsonOutput {
+		if err = render.JSON(io.Out, map[string]string{
+			"name":          database.Name,
+			"local_address": server.LocalAddr,
+			"remote_host":   database.PrivateIp,
+		}); err != nil {
+			return
+		}
 
-	redisCliPath, err := exec.LookPath("redis-cli")
+		<-ctx.Done()
+
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, redisCliPath, "-p", localProxyPort)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("REDISCLI_AUTH=%s", database.Password))
+	cmd.Stdout = io.Out
+	cmd.Stderr = io.ErrOut
+	cmd.Stdin = io.In
+
+	return cmd.Run()
+}
+
+// selectDatabase returns the name of the database given as an argument or,
+// lacking one, the one the user picks among those of --org.
+func selectDatabase(ctx context.Context) (string, error) {
+	if name := flag.FirstArg(ctx); name != "" {
+		return name, nil
+	}
+
+	databases, err := listDatabases(ctx, flag.GetOrg(ctx))
 	if err != nil {
-		fmt.Fprintf(io.Out, "Could not find redis-cli in your $PATH. Install it or point your redis-cli at: %s", "someurl")
-	} else {
-		// TODO: let proxy.Connect inform us about readiness
-		time.Sleep(3 * time.Second)
-		cmd := exec.CommandContext(ctx, redisCliPath, "-p", localProxyPort)
-		cmd.Env = append(cmd.Env, fmt.Sprintf("REDISCLI_AUTH=%s", database.Password))
-		cmd.Stdout = io.Out
-		cmd.Stderr = io.ErrOut
-		cmd.Stdin = io.In
-
-		cmd.Start()
-		cmd.Wait()
+		return "", err
+	}
+
+	if len(databases) == 1 {
+		return databases[0].Name, nil
 	}
 
-	return
+	var options []string
+	for _, database := range databases {
+		options = append(options, fmt.Sprintf("%s (%s) %s", database.Name, database.PrimaryRegion, database.Organization.Slug))
+	}
+
+	var index int
+	switch err := prompt.Select(ctx, &index, "Select a database to connect to", "", options...); {
+	case prompt.IsNonInteractive(err):
+		return "", prompt.NonInteractiveError("a database name must be specified when not running interactively")
+	case err != nil:
+		return "", err
+	}
+
+	return databases[index].Name, nil
+}
+
+// runShell reads commands from stdin and runs them against database through
+// the tunnel, printing replies the way redis-cli does.
+func runShell(ctx context.Context, dialer agent.Dialer, database *gql.GetAddOnAddOn) error {
+	io := iostreams.FromContext(ctx)
+
+	conn, err := dialRedis(ctx, dialer, database.PrivateIp, database.Password)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	interactive := io.IsInteractive()
+	scanner := bufio.NewScanner(io.In)
+
+	for {
+		if interactive {
+			fmt.Fprintf(io.Out, "%s> ", database.Name)
+		}
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+
+		args, err := shlex.Split(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(io.ErrOut, "(error) %v\n", err)
+
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if cmd := strings.ToLower(args[0]); cmd == "quit" || cmd == "exit" {
+			return nil
+		}
+
+		reply, err := conn.do(args...)
+		var e errReply
+		switch {
+		case errors.As(err, &e):
+			reply = e
+		case err != nil:
+			return err
+		}
+
+		formatReply(io.Out, reply, "")
+	}
 }
@@ -2,16 +2,14 @@ package redis
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 
-	"github.com/superfly/flyctl/gql"
 	"github.com/superfly/flyctl/iostreams"
 
-	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/render"
 )
@@ -33,52 +31,47 @@ func newList() (cmd *cobra.Command) {
 }
 
 func runList(ctx context.Context) (err error) {
-	var (
-		out    = iostreams.FromContext(ctx).Out
-		client = client.FromContext(ctx).API().GenqClient
-	)
-
-	_ = `# @genqlient
-		query ListAddOns($addOnType: AddOnType) {
-			addOns(type: $addOnType) {
-				nodes {
-					id
-					name
-					addOnPlan {
-						displayName
-					}
-					privateIp
-					primaryRegion
-					readRegions
-					options
-					organization {
-						id
-						slug
-					}
-				}
-			}
-		}
-	`
-	response, err := gql.ListAddOns(ctx, client, "redis")
+	out := iostreams.FromContext(ctx).Out
 
-	var rows [][]string
+	databases, err := listDatabases(ctx, flag.GetOrg(ctx))
+	if err != nil {
+		return
+	}
 
-	for _, addon := range response.AddOns.Nodes {
-		options, _ := addon.Options.(map[string]interface{})
-		fmt.Println(options)
-		var eviction = "Disabled"
+	if config.FromContext(ctx).JSONOutput {
+		type entry struct {
+			Name          string   `json:"name"`
+			Org           string   `json:"org"`
+			Plan          string   `json:"plan"`
+			Eviction      bool     `json:"eviction"`
+			PrimaryRegion string   `json:"primary_region"`
+			ReadRegions   []string `json:"read_regions"`
+		}
 
-		if options["eviction"] != nil && options["eviction"].(bool) {
-			eviction = "Enabled"
+		entries := make([]entry, 0, len(databases))
+		for _, db := range databases {
+			entries = append(entries, entry{
+				Name:          db.Name,
+				Org:           db.Organization.Slug,
+				Plan:          db.AddOnPlan.DisplayName,
+				Eviction:      evictionStatus(db.Options) == "Enabled",
+				PrimaryRegion: db.PrimaryRegion,
+				ReadRegions:   db.ReadRegions,
+			})
 		}
 
+		return render.JSON(out, entries)
+	}
+
+	var rows [][]string
+	for _, db := range databases {
 		rows = append(rows, []string{
-			addon.Name,
-			addon.Organization.Slug,
-			addon.AddOnPlan.DisplayName,
-			eviction,
-			addon.PrimaryRegion,
-			strings.Join(addon.ReadRegions, ","),
+			db.Name,
+			db.Organization.Slug,
+			db.AddOnPlan.DisplayName,
+			evictionStatus(db.Options),
+			db.PrimaryRegion,
+			strings.Join(db.ReadRegions, ","),
 		})
 	}
 
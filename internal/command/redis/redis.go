@@ -1,8 +1,13 @@
 package redis
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/spf13/cobra"
 
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/gql"
 	"github.com/superfly/flyctl/internal/command"
 )
 
@@ -29,3 +34,40 @@ func New() (cmd *cobra.Command) {
 
 	return cmd
 }
+
+type database = gql.ListAddOnsAddOnsAddOnConnectionNodesAddOn
+
+// listDatabases returns the Redis databases of org, or of all organizations
+// in case org is empty. It fails in case there are none.
+func listDatabases(ctx context.Context, org string) ([]database, error) {
+	response, err := gql.ListAddOns(ctx, client.FromContext(ctx).API().GenqClient, "redis")
+	if err != nil {
+		return nil, fmt.Errorf("failed listing Redis databases: %w", err)
+	}
+
+	var databases []database
+	for _, db := range response.AddOns.Nodes {
+		if org == "" || db.Organization.Slug == org {
+			databases = append(databases, db)
+		}
+	}
+
+	if len(databases) == 0 {
+		if org != "" {
+			return nil, fmt.Errorf("organization %s has no Redis databases; create one with `fly redis create`", org)
+		}
+
+		return nil, fmt.Errorf("no Redis databases found; create one with `fly redis create`")
+	}
+
+	return databases, nil
+}
+
+// evictionStatus describes whether the add-on options enable eviction.
+func evictionStatus(options interface{}) string {
+	if o, _ := options.(map[string]interface{}); o["eviction"] == true {
+		return "Enabled"
+	}
+
+	return "Disabled"
+}
@@ -0,0 +1,163 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/agent"
+)
+
+// respConn is a minimal client of the Redis protocol, enough to run commands
+// against databases through the agent's tunnel without a local redis-cli.
+type respConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// errReply is an error the server replied with.
+type errReply string
+
+func (e errReply) Error() string {
+	return string(e)
+}
+
+// dialRedis connects to the database at host through dialer and
+// authenticates with password.
+func dialRedis(ctx context.Context, dialer agent.Dialer, host, password string) (*respConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, "6379"))
+	if err != nil {
+		return nil, fmt.Errorf("failed connecting to %s: %w", host, err)
+	}
+
+	c := &respConn{conn: conn, r: bufio.NewReader(conn)}
+	if password != "" {
+		if _, err := c.do("AUTH", password); err != nil {
+			c.Close()
+
+			return nil, fmt.Errorf("failed authenticating: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *respConn) Close() error {
+	return c.conn.Close()
+}
+
+// do runs the command args and returns its reply: a string, an int64, nil or
+// a []interface{} of those.
+func (c *respConn) do(args ...string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.r)
+}
+
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+
+	switch kind, rest := line[0], line[1:]; kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, errReply(rest)
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				var e errReply
+				if !errors.As(err, &e) {
+					return nil, err
+				}
+				items[i] = e
+			}
+		}
+
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
+
+// parseInfo parses the reply of the INFO command into its fields.
+func parseInfo(info string) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			fields[k] = v
+		}
+	}
+
+	return fields
+}
+
+// formatReply renders reply the way redis-cli does.
+func formatReply(w io.Writer, reply interface{}, indent string) {
+	switch v := reply.(type) {
+	case nil:
+		fmt.Fprintf(w, "%s(nil)\n", indent)
+	case int64:
+		fmt.Fprintf(w, "%s(integer) %d\n", indent, v)
+	case string:
+		fmt.Fprintf(w, "%s%q\n", indent, v)
+	case errReply:
+		fmt.Fprintf(w, "%s(error) %s\n", indent, v)
+	case []interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(w, "%s(empty array)\n", indent)
+		}
+		for i, item := range v {
+			fmt.Fprintf(w, "%s%d) ", indent, i+1)
+			formatReply(w, item, "")
+		}
+	}
+}
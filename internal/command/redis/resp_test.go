@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadReply(t *testing.T) {
+	cases := []struct {
+		name  string
+		reply string
+		want  interface{}
+	}{
+		{name: "simple string", reply: "+PONG\r\n", want: "PONG"},
+		{name: "integer", reply: ":42\r\n", want: int64(42)},
+		{name: "bulk string", reply: "$12\r\nhello\r\nworld\r\n", want: "hello\r\nworld"},
+		{name: "empty bulk string", reply: "$0\r\n\r\n", want: ""},
+		{name: "nil bulk string", reply: "$-1\r\n", want: nil},
+		{name: "nil array", reply: "*-1\r\n", want: nil},
+		{name: "empty array", reply: "*0\r\n", want: []interface{}{}},
+		{
+			name:  "nested array",
+			reply: "*3\r\n$3\r\nfoo\r\n:1\r\n*2\r\n+a\r\n$-1\r\n",
+			want:  []interface{}{"foo", int64(1), []interface{}{"a", nil}},
+		},
+		{
+			name:  "errors within arrays",
+			reply: "*2\r\n-ERR within\r\n:2\r\n",
+			want:  []interface{}{errReply("ERR within"), int64(2)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reply, err := readReply(bufio.NewReader(strings.NewReader(tc.reply)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, reply)
+		})
+	}
+}
+
+func TestReadReplyErrors(t *testing.T) {
+	_, err := readReply(bufio.NewReader(strings.NewReader("-WRONGPASS invalid password\r\n")))
+	assert.Equal(t, errReply("WRONGPASS invalid password"), err)
+
+	for _, reply := range []string{"", "\r\n", "?what\r\n", ":nan\r\n", "$5\r\nab", "*2\r\n:1\r\n"} {
+		_, err := readReply(bufio.NewReader(strings.NewReader(reply)))
+		assert.Error(t, err, "%q", reply)
+	}
+}
+
+func TestRespConnDo(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		const request = "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$7\r\ntwo val\r\n"
+
+		buf := make([]byte, len(request))
+		_, _ = server.Read(buf)
+		received <- string(buf)
+
+		_, _ = server.Write([]byte("+OK\r\n"))
+	}()
+
+	c := &respConn{conn: client, r: bufio.NewReader(client)}
+	reply, err := c.do("SET", "key", "two val")
+	require.NoError(t, err)
+	assert.Equal(t, "OK", reply)
+	assert.Equal(t, "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$7\r\ntwo val\r\n", <-received)
+}
+
+func TestParseInfo(t *testing.T) {
+	info := "# Server\r\nredis_version:7.0.5\r\nuptime_in_seconds:120\r\n\r\n# Keyspace\r\ndb0:keys=3,expires=0\r\n"
+
+	assert.Equal(t, map[string]string{
+		"redis_version":     "7.0.5",
+		"uptime_in_seconds": "120",
+		"db0":               "keys=3,expires=0",
+	}, parseInfo(info))
+}
+
+func TestFormatReply(t *testing.T) {
+	var b bytes.Buffer
+	formatReply(&b, []interface{}{"foo", int64(1), nil, errReply("ERR bad")}, "")
+	formatReply(&b, []interface{}{}, "")
+
+	assert.Equal(t, "1) \"foo\"\n2) (integer) 1\n3) (nil)\n4) (error) ERR bad\n(empty array)\n", b.String())
+}
@@ -2,12 +2,16 @@ package redis
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/gql"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
@@ -16,7 +20,9 @@ import (
 func newStatus() *cobra.Command {
 	const (
 		short = "Show status of a Redis database"
-		long  = short + "\n"
+		long  = short + `, including its connection count and memory usage as
+reported by the database itself.
+`
 
 		usage = "status <name>"
 	)
@@ -32,32 +38,69 @@ func newStatus() *cobra.Command {
 	return cmd
 }
 
+// databaseStats is the usage of a database as reported by INFO.
+type databaseStats struct {
+	Connections int    `json:"connections"`
+	MemoryUsed  string `json:"memory_used"`
+	MemoryLimit string `json:"memory_limit,omitempty"`
+}
+
 func runStatus(ctx context.Context) (err error) {
 	var (
 		io     = iostreams.FromContext(ctx)
 		name   = flag.FirstArg(ctx)
-		client = client.FromContext(ctx).API().GenqClient
+		client = client.FromContext(ctx).API()
 	)
 
-	response, err := gql.GetAddOn(ctx, client, name)
+	response, err := gql.GetAddOn(ctx, client.GenqClient, name)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed retrieving Redis database %s: %w", name, err)
 	}
 
 	addOn := response.AddOn
 
+	stats, statsErr := fetchStats(ctx, &addOn)
+	if statsErr != nil {
+		fmt.Fprintf(io.ErrOut, "failed retrieving usage of %s: %v\n", name, statsErr)
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, struct {
+			ID            string         `json:"id"`
+			Name          string         `json:"name"`
+			Org           string         `json:"org"`
+			Plan          string         `json:"plan"`
+			PrimaryRegion string         `json:"primary_region"`
+			ReadRegions   []string       `json:"read_regions"`
+			Eviction      bool           `json:"eviction"`
+			PrivateURL    string         `json:"private_url"`
+			Stats         *databaseStats `json:"stats"`
+		}{
+			ID:            addOn.Id,
+			Name:          addOn.Name,
+			Org:           addOn.Organization.Slug,
+			Plan:          addOn.AddOnPlan.DisplayName,
+			PrimaryRegion: addOn.PrimaryRegion,
+			ReadRegions:   addOn.ReadRegions,
+			Eviction:      evictionStatus(addOn.Options) == "Enabled",
+			PrivateURL:    addOn.PublicUrl,
+			Stats:         stats,
+		})
+	}
+
 	var readRegions string = "None"
 
 	if len(addOn.ReadRegions) > 0 {
 		readRegions = strings.Join(addOn.ReadRegions, ",")
 	}
 
-	options, _ := addOn.Options.(map[string]interface{})
-
-	evictionStatus := "Disabled"
-
-	if options["eviction"] != nil && options["eviction"].(bool) {
-		evictionStatus = "Enabled"
+	connections, memory := "unavailable", "unavailable"
+	if stats != nil {
+		connections = strconv.Itoa(stats.Connections)
+		memory = stats.MemoryUsed
+		if stats.MemoryLimit != "" {
+			memory += " of " + stats.MemoryLimit
+		}
 	}
 
 	obj := [][]string{
@@ -67,12 +110,14 @@ func runStatus(ctx context.Context) (err error) {
 			addOn.AddOnPlan.DisplayName,
 			addOn.PrimaryRegion,
 			readRegions,
-			evictionStatus,
+			evictionStatus(addOn.Options),
 			addOn.PublicUrl,
+			connections,
+			memory,
 		},
 	}
 
-	var cols []string = []string{"ID", "Name", "Plan", "Primary Region", "Read Regions", "Eviction", "Private URL"}
+	var cols []string = []string{"ID", "Name", "Plan", "Primary Region", "Read Regions", "Eviction", "Private URL", "Connections", "Memory"}
 
 	if err = render.VerticalTable(io.Out, "Redis", obj, cols...); err != nil {
 		return
@@ -80,3 +125,44 @@ func runStatus(ctx context.Context) (err error) {
 
 	return
 }
+
+// fetchStats asks the database about its usage through the agent's tunnel.
+func fetchStats(ctx context.Context, addOn *gql.GetAddOnAddOn) (*databaseStats, error) {
+	agentclient, err := agent.Establish(ctx, client.FromContext(ctx).API())
+	if err != nil {
+		return nil, err
+	}
+
+	dialer, err := agentclient.Dialer(ctx, addOn.Organization.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialRedis(ctx, dialer, addOn.PrivateIp, addOn.Password)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	reply, err := conn.do("INFO")
+	if err != nil {
+		return nil, err
+	}
+
+	info, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected INFO reply %v", reply)
+	}
+	fields := parseInfo(info)
+
+	stats := &databaseStats{
+		MemoryUsed:  fields["used_memory_human"],
+		MemoryLimit: fields["maxmemory_human"],
+	}
+	if stats.MemoryLimit == "0B" {
+		stats.MemoryLimit = ""
+	}
+	stats.Connections, _ = strconv.Atoi(fields["connected_clients"])
+
+	return stats, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/command/apps"
+	"github.com/superfly/flyctl/internal/flag"
 )
 
 // TODO: deprecate & remove
@@ -20,9 +21,13 @@ the number of configured instances.
 	)
 
 	resume := command.New(usage, short, long, apps.RunResume,
-		command.RequireSession)
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	flag.Add(resume, apps.ResumeFlags)
 
-	resume.Args = cobra.ExactArgs(1)
+	resume.Args = cobra.MaximumNArgs(1)
 
 	return resume
 }
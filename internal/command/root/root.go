@@ -14,6 +14,8 @@ import (
 	"github.com/superfly/flyctl/internal/command/checks"
 	"github.com/superfly/flyctl/internal/command/create"
 	"github.com/superfly/flyctl/internal/command/curl"
+	"github.com/superfly/flyctl/internal/command/dashboard"
+	"github.com/superfly/flyctl/internal/command/defaults"
 	"github.com/superfly/flyctl/internal/command/deploy"
 	"github.com/superfly/flyctl/internal/command/destroy"
 	"github.com/superfly/flyctl/internal/command/dig"
@@ -29,6 +31,7 @@ import (
 	"github.com/superfly/flyctl/internal/command/machine"
 	"github.com/superfly/flyctl/internal/command/monitor"
 	"github.com/superfly/flyctl/internal/command/move"
+	"github.com/superfly/flyctl/internal/command/networks"
 	"github.com/superfly/flyctl/internal/command/open"
 	"github.com/superfly/flyctl/internal/command/orgs"
 	"github.com/superfly/flyctl/internal/command/ping"
@@ -46,6 +49,7 @@ import (
 	"github.com/superfly/flyctl/internal/command/version"
 	"github.com/superfly/flyctl/internal/command/vm"
 	"github.com/superfly/flyctl/internal/command/volumes"
+	"github.com/superfly/flyctl/internal/flag"
 )
 
 // New initializes and returns a reference to a new root command.
@@ -159,6 +163,8 @@ func New() *cobra.Command {
 		checks.New(),
 		launch.New(),
 		info.New(),
+		dashboard.New(),
+		networks.New(),
 	}
 
 	// if os.Getenv("DEV") != "" {
@@ -194,10 +200,23 @@ func New() *cobra.Command {
 	// and finally, add the new commands
 	root.AddCommand(newCommands...)
 
+	// the config command is yet to be migrated; graft the new subcommands
+	// managing flag defaults onto it
+	if configCmd, _, err := root.Find([]string{"config"}); err == nil && configCmd != root {
+		configCmd.AddCommand(defaults.New()...)
+	}
+
+	root.PersistentFlags().Bool(flag.IgnoreDefaultsName, false, "Ignore the flag defaults of the app's profile")
+	root.PersistentFlags().String(flag.TunnelModeName, "auto", "How to reach private networks: agent, userspace (in-process, without the agent), or auto to fall back to userspace when the agent can't start")
+	root.SetHelpFunc(defaults.WrapHelp(root.HelpFunc()))
+
 	root.SetHelpCommand(help.New(root))
 
 	root.RunE = help.NewRootHelp().RunE
 
+	// validate positional arguments once the flag defaults of the app apply
+	command.DeferArgsValidation(root)
+
 	return root
 }
 
@@ -2,8 +2,10 @@ package secrets
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
@@ -44,6 +46,16 @@ func runList(ctx context.Context) (err error) {
 		return err
 	}
 
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	scoped, err := listScopedSecrets(ctx, app)
+	if err != nil {
+		return fmt.Errorf("failed listing scoped secrets: %w", err)
+	}
+
 	var rows [][]string
 
 	for _, secret := range secrets {
@@ -60,8 +72,33 @@ func runList(ctx context.Context) (err error) {
 		"Created At",
 	}
 	if cfg.JSONOutput {
-		return render.JSON(out, secrets)
-	} else {
-		return render.Table(out, "", rows, headers...)
+		if len(scoped) == 0 {
+			return render.JSON(out, secrets)
+		}
+
+		return render.JSON(out, struct {
+			Secrets []api.Secret
+			Scoped  []scopedSecret
+		}{secrets, scoped})
+	}
+
+	if err := render.Table(out, "", rows, headers...); err != nil {
+		return err
+	}
+
+	if len(scoped) == 0 {
+		return nil
+	}
+
+	rows = rows[:0]
+	for _, secret := range scoped {
+		rows = append(rows, []string{
+			secret.Name,
+			secret.Digest,
+			secret.Group,
+			secret.Machine,
+		})
 	}
+
+	return render.Table(out, "Scoped Secrets", rows, "Name", "Digest", "Group", "Machine")
 }
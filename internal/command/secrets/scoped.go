@@ -0,0 +1,195 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/command/apps"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+var scopeFlags = flag.Set{
+	flag.String{
+		Name:        "group",
+		Description: "Scope the secrets to the machines of this process group instead of the app. Machines apps only.",
+	},
+	flag.String{
+		Name:        "machine",
+		Description: "Scope the secrets to this machine instead of the app. Machines apps only.",
+	},
+}
+
+// isScoped reports whether the scope flags are set.
+func isScoped(ctx context.Context) bool {
+	return flag.GetString(ctx, "group") != "" || flag.GetString(ctx, "machine") != ""
+}
+
+// scopedMachines returns the machines of app the scope flags target.
+func scopedMachines(ctx context.Context, app *api.AppCompact) ([]*api.Machine, error) {
+	if app.PlatformVersion != "machines" {
+		return nil, errors.New("--group and --machine are only available for machines apps")
+	}
+
+	var (
+		group     = flag.GetString(ctx, "group")
+		machineID = flag.GetString(ctx, "machine")
+	)
+
+	if group != "" && machineID != "" {
+		return nil, errors.New("--group and --machine may not be used together")
+	}
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []*api.Machine
+	for _, m := range machines {
+		switch {
+		case machineID != "" && m.ID == machineID:
+			targets = append(targets, m)
+		case group != "" && m.Config != nil && m.Config.Metadata["process_group"] == group:
+			targets = append(targets, m)
+		}
+	}
+
+	if len(targets) == 0 {
+		if machineID != "" {
+			return nil, fmt.Errorf("machine %s was not found in app %s", machineID, app.Name)
+		}
+
+		return nil, fmt.Errorf("no machines of app %s belong to process group %s", app.Name, group)
+	}
+
+	return targets, nil
+}
+
+// updateScopedSecrets applies fn to the config of every machine the scope
+// flags target, updating each machine under a lease. fn reports whether it
+// changed anything.
+func updateScopedSecrets(ctx context.Context, app *api.AppCompact, fn func(*api.MachineConfig) bool) error {
+	ctx, err := apps.BuildContext(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	machines, err := scopedMachines(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	machines, releaseLeases, err := mach.AcquireLeases(ctx, machines)
+	defer releaseLeases(ctx, machines)
+	if err != nil {
+		return err
+	}
+
+	io := iostreams.FromContext(ctx)
+
+	for _, m := range machines {
+		conf, err := mach.CloneConfig(*m.Config)
+		if err != nil {
+			return err
+		}
+
+		if !fn(conf) {
+			fmt.Fprintf(io.Out, "No changes to apply to machine %s\n", m.ID)
+
+			continue
+		}
+
+		input := &api.LaunchMachineInput{
+			ID:     m.ID,
+			AppID:  app.Name,
+			Name:   m.Name,
+			Region: m.Region,
+			Config: conf,
+		}
+		if err := mach.Update(ctx, m, input); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setScopedSecrets(ctx context.Context, app *api.AppCompact, secrets map[string]string) error {
+	io := iostreams.FromContext(ctx)
+
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(io.Out, "Setting scoped secrets %s\n", strings.Join(names, ", "))
+
+	return updateScopedSecrets(ctx, app, func(conf *api.MachineConfig) bool {
+		mach.SetScopedSecrets(conf, secrets)
+
+		return true
+	})
+}
+
+func unsetScopedSecrets(ctx context.Context, app *api.AppCompact, names []string) error {
+	io := iostreams.FromContext(ctx)
+
+	fmt.Fprintf(io.Out, "Unsetting scoped secrets %s\n", strings.Join(names, ", "))
+
+	return updateScopedSecrets(ctx, app, func(conf *api.MachineConfig) bool {
+		return len(mach.UnsetScopedSecrets(conf, names)) > 0
+	})
+}
+
+// scopedSecret is a secret set on a single machine.
+type scopedSecret struct {
+	Name    string
+	Digest  string
+	Group   string
+	Machine string
+}
+
+func listScopedSecrets(ctx context.Context, app *api.AppCompact) ([]scopedSecret, error) {
+	if app.PlatformVersion != "machines" {
+		return nil, nil
+	}
+
+	ctx, err := apps.BuildContext(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []scopedSecret
+	for _, m := range machines {
+		for _, name := range mach.ScopedSecretNames(m.Config) {
+			secrets = append(secrets, scopedSecret{
+				Name:    name,
+				Digest:  mach.SecretDigest(m.Config.Env[name]),
+				Group:   m.Config.Metadata["process_group"],
+				Machine: m.ID,
+			})
+		}
+	}
+
+	sort.Slice(secrets, func(i, j int) bool {
+		if secrets[i].Name != secrets[j].Name {
+			return secrets[i].Name < secrets[j].Name
+		}
+
+		return secrets[i].Machine < secrets[j].Machine
+	})
+
+	return secrets, nil
+}
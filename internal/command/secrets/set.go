@@ -25,6 +25,7 @@ func newSet() (cmd *cobra.Command) {
 
 	flag.Add(cmd,
 		sharedFlags,
+		scopeFlags,
 	)
 
 	cmd.Args = cobra.MinimumNArgs(1)
@@ -62,6 +63,10 @@ func runSet(ctx context.Context) (err error) {
 		return errors.New("requires at least one SECRET=VALUE pair")
 	}
 
+	if isScoped(ctx) {
+		return setScopedSecrets(ctx, app, secrets)
+	}
+
 	release, err := client.SetSecrets(ctx, appName, secrets)
 	if err != nil {
 		return err
@@ -2,18 +2,28 @@ package secrets
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/app"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
 )
 
 func newUnset() (cmd *cobra.Command) {
 	const (
-		long  = `Unset one or more encrypted secrets for an application`
-		short = long
+		long = `Unset one or more encrypted secrets for an application. Names holding
+glob characters, like those given with --all-matching, unset every secret they
+match, all at once and after confirmation.`
+		short = "Unset one or more encrypted secrets for an application"
 		usage = "unset [flags] NAME NAME ..."
 	)
 
@@ -21,25 +31,175 @@ func newUnset() (cmd *cobra.Command) {
 
 	flag.Add(cmd,
 		sharedFlags,
+		scopeFlags,
+		flag.Yes(),
+		flag.StringSlice{
+			Name:        "all-matching",
+			Description: "Unset the secrets whose names match this glob pattern, such as 'FEATURE_*'. Can be specified multiple times.",
+		},
 	)
 
-	cmd.Args = cobra.MinimumNArgs(1)
-
 	return cmd
 }
 
 func runUnset(ctx context.Context) (err error) {
 	client := client.FromContext(ctx).API()
 	appName := app.NameFromContext(ctx)
+
+	literals, patterns := splitPatterns(flag.Args(ctx))
+	patterns = append(patterns, flag.GetStringSlice(ctx, "all-matching")...)
+
+	if len(literals) == 0 && len(patterns) == 0 {
+		return errors.New("at least one secret name or --all-matching pattern is required")
+	}
+
 	app, err := client.GetAppCompact(ctx, appName)
 	if err != nil {
 		return err
 	}
 
-	release, err := client.UnsetSecrets(ctx, appName, flag.Args(ctx))
+	names := literals
+	if len(patterns) > 0 {
+		existing, err := existingSecretNames(ctx, app)
+		if err != nil {
+			return err
+		}
+
+		matched, err := matchSecretNames(patterns, existing)
+		if err != nil {
+			return err
+		}
+
+		switch confirmed, err := confirmUnset(ctx, matched); {
+		case err != nil:
+			return err
+		case !confirmed:
+			return nil
+		}
+
+		names = mergeNames(literals, matched)
+	}
+
+	if isScoped(ctx) {
+		return unsetScopedSecrets(ctx, app, names)
+	}
+
+	release, err := client.UnsetSecrets(ctx, appName, names)
 	if err != nil {
 		return err
 	}
 
 	return deployForSecrets(ctx, app, release)
 }
+
+// splitPatterns splits names into those to be matched exactly and the glob
+// patterns among them.
+func splitPatterns(names []string) (literals, patterns []string) {
+	for _, name := range names {
+		if strings.ContainsAny(name, "*?[") {
+			patterns = append(patterns, name)
+		} else {
+			literals = append(literals, name)
+		}
+	}
+
+	return
+}
+
+// matchSecretNames returns the names out of existing which any of patterns
+// match, sorted. Every pattern must match at least one name.
+func matchSecretNames(patterns, existing []string) ([]string, error) {
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		var found bool
+		for _, name := range existing {
+			ok, err := path.Match(pattern, name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+
+			if ok {
+				seen[name], found = true, true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no secrets match %q", pattern)
+		}
+	}
+
+	matched := make([]string, 0, len(seen))
+	for name := range seen {
+		matched = append(matched, name)
+	}
+	sort.Strings(matched)
+
+	return matched, nil
+}
+
+// mergeNames returns literals followed by those of matched not among them.
+func mergeNames(literals, matched []string) []string {
+	names := append([]string(nil), literals...)
+	for _, name := range matched {
+		found := false
+		for _, l := range literals {
+			found = found || l == name
+		}
+
+		if !found {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// existingSecretNames returns the names of the secrets of app within the
+// scope the flags call for.
+func existingSecretNames(ctx context.Context, app *api.AppCompact) ([]string, error) {
+	if isScoped(ctx) {
+		scoped, err := listScopedSecrets(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(scoped))
+		for _, s := range scoped {
+			names = append(names, s.Name)
+		}
+
+		return names, nil
+	}
+
+	secrets, err := client.FromContext(ctx).API().GetAppSecrets(ctx, app.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		names = append(names, s.Name)
+	}
+
+	return names, nil
+}
+
+func confirmUnset(ctx context.Context, matched []string) (bool, error) {
+	io := iostreams.FromContext(ctx)
+
+	fmt.Fprintf(io.Out, "The following %d secrets match:\n", len(matched))
+	for _, name := range matched {
+		fmt.Fprintf(io.Out, "  %s\n", name)
+	}
+
+	if flag.GetYes(ctx) {
+		return true, nil
+	}
+
+	confirmed, err := prompt.Confirmf(ctx, "Unset %d secrets?", len(matched))
+	if prompt.IsNonInteractive(err) {
+		return false, prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+	}
+
+	return confirmed, err
+}
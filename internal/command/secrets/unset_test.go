@@ -0,0 +1,28 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchSecretNames(t *testing.T) {
+	existing := []string{"FEATURE_B", "FEATURE_A", "DATABASE_URL", "FEATURES"}
+
+	literals, patterns := splitPatterns([]string{"DATABASE_URL", "FEATURE_*"})
+	assert.Equal(t, []string{"DATABASE_URL"}, literals)
+	assert.Equal(t, []string{"FEATURE_*"}, patterns)
+
+	matched, err := matchSecretNames(patterns, existing)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FEATURE_A", "FEATURE_B"}, matched)
+
+	assert.Equal(t, []string{"DATABASE_URL", "FEATURE_A", "FEATURE_B"}, mergeNames(literals, matched))
+
+	_, err = matchSecretNames([]string{"FEATURE_*", "STRIPE_*"}, existing)
+	assert.EqualError(t, err, `no secrets match "STRIPE_*"`)
+
+	_, err = matchSecretNames([]string{"["}, existing)
+	assert.Error(t, err)
+}
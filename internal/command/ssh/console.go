@@ -99,6 +99,24 @@ func newConsole() *cobra.Command {
 	cmd.Args = cobra.MaximumNArgs(1)
 
 	stdArgsSSH(cmd)
+	flag.Add(cmd,
+		flag.Bool{
+			Name:        "pty",
+			Description: "Allocate a pseudo-terminal even when running a command with non-interactive stdin",
+		},
+		flag.String{
+			Name:        "escape-char",
+			Shorthand:   "e",
+			Default:     "~",
+			Description: "Escape character for terminating the session with <char>. at the start of a line, or 'none' to disable",
+		},
+		flag.String{
+			Name:        "user",
+			Shorthand:   "u",
+			Default:     "root",
+			Description: "Unix user to connect as; a single-use certificate is issued for it",
+		},
+	)
 
 	return cmd
 }
@@ -176,20 +194,26 @@ func runConsole(ctx context.Context) error {
 
 	// BUG(tqbf): many of these are no longer really params
 	params := &SSHParams{
-		Ctx:    ctx,
-		Org:    app.Organization,
-		Dialer: dialer,
-		App:    appName,
-		Cmd:    flag.GetString(ctx, "command"),
-		Stdin:  os.Stdin,
-		Stdout: ioutils.NewWriteCloserWrapper(colorable.NewColorableStdout(), func() error { return nil }),
-		Stderr: ioutils.NewWriteCloserWrapper(colorable.NewColorableStderr(), func() error { return nil }),
+		Ctx:      ctx,
+		Org:      app.Organization,
+		Dialer:   dialer,
+		App:      appName,
+		Cmd:      flag.GetString(ctx, "command"),
+		Username: flag.GetString(ctx, "user"),
+		Stdin:    os.Stdin,
+		Stdout:   ioutils.NewWriteCloserWrapper(colorable.NewColorableStdout(), func() error { return nil }),
+		Stderr:   ioutils.NewWriteCloserWrapper(colorable.NewColorableStderr(), func() error { return nil }),
 	}
 
 	if quiet(ctx) {
 		params.DisableSpinner = true
 	}
 
+	escape, err := escapeChar(ctx)
+	if err != nil {
+		return err
+	}
+
 	sshc, err := sshConnect(params, addr)
 	if err != nil {
 		captureError(err, app)
@@ -197,10 +221,12 @@ func runConsole(ctx context.Context) error {
 	}
 
 	term := &ssh.Terminal{
-		Stdin:  params.Stdin,
-		Stdout: params.Stdout,
-		Stderr: params.Stderr,
-		Mode:   "xterm",
+		Stdin:      params.Stdin,
+		Stdout:     params.Stdout,
+		Stderr:     params.Stderr,
+		Mode:       "xterm",
+		DisablePTY: params.Cmd != "" && !flag.GetBool(ctx, "pty") && !ssh.IsTerminal(params.Stdin),
+		EscapeChar: escape,
 	}
 
 	if err := sshc.Shell(params.Ctx, term, params.Cmd); err != nil {
@@ -211,10 +237,21 @@ func runConsole(ctx context.Context) error {
 	return err
 }
 
+func escapeChar(ctx context.Context) (byte, error) {
+	switch c := flag.GetString(ctx, "escape-char"); {
+	case c == "none":
+		return 0, nil
+	case len(c) == 1:
+		return c[0], nil
+	default:
+		return 0, fmt.Errorf("invalid escape character %q: must be a single character or 'none'", c)
+	}
+}
+
 func sshConnect(p *SSHParams, addr string) (*ssh.Client, error) {
 	terminal.Debugf("Fetching certificate for %s\n", addr)
 
-	cert, err := singleUseSSHCertificate(p.Ctx, p.Org)
+	cert, err := singleUseSSHCertificate(p.Ctx, p.Org, p.principals())
 	if err != nil {
 		return nil, fmt.Errorf("create ssh certificate: %w (if you haven't created a key for your org yet, try `flyctl ssh establish`)", err)
 	}
@@ -230,7 +267,7 @@ func sshConnect(p *SSHParams, addr string) (*ssh.Client, error) {
 
 	sshClient := &ssh.Client{
 		Addr: net.JoinHostPort(addr, "22"),
-		User: "root",
+		User: p.sshUser(),
 
 		Dial: p.Dialer.DialContext,
 
@@ -246,7 +283,7 @@ func sshConnect(p *SSHParams, addr string) (*ssh.Client, error) {
 	}
 
 	if err := sshClient.Connect(p.Ctx); err != nil {
-		return nil, errors.Wrap(err, "error connecting to SSH server")
+		return nil, p.connectError(err)
 	}
 
 	terminal.Debugf("Connection completed.\n", addr)
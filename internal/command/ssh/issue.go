@@ -12,6 +12,7 @@ import (
 	"net/mail"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/ejcx/sshcert"
@@ -30,8 +31,9 @@ import (
 func newIssue() *cobra.Command {
 	const (
 		long = `Issue a new SSH credential. With -agent, populate credential
-into SSH agent. With -hour, set the number of hours (1-72) for credential
-validity.`
+into SSH agent. With -valid-for, set how long (1h-72h) the credential is
+valid. With -user, issue the credential for the given unix users instead
+of root; it is not checked that the users exist on the app's machines.`
 		short = `Issue a new SSH credential`
 		usage = "issue [org] [email] [path]"
 	)
@@ -42,15 +44,25 @@ validity.`
 
 	flag.Add(cmd,
 		flag.Org(),
+		flag.StringSlice{
+			Name:        "user",
+			Shorthand:   "u",
+			Description: "Unix user the SSH cert is valid for, can be repeated (defaults to root)",
+		},
 		flag.String{
 			Name:        "username",
-			Shorthand:   "u",
 			Description: "Unix username for SSH cert",
+			Hidden:      true,
+		},
+		flag.String{
+			Name:        "valid-for",
+			Description: "How long the SSH cert is valid, e.g. 2h (1h-72h)",
 		},
 		flag.Int{
 			Name:        "hours",
 			Default:     24,
 			Description: "Expiration, in hours (<72)",
+			Hidden:      true,
 		},
 
 		flag.Bool{
@@ -103,18 +115,17 @@ func runSSHIssue(ctx context.Context) (err error) {
 		}
 	}
 
-	var username *string
-
-	if vals := flag.GetString(ctx, "username"); vals != "" {
-		username = &vals
+	principals := flag.GetStringSlice(ctx, "user")
+	if username := flag.GetString(ctx, "username"); username != "" {
+		principals = append(principals, username)
 	}
 
-	hours := flag.GetInt(ctx, "hours")
-	if hours < 1 || hours > 72 {
-		return fmt.Errorf("Invalid expiration time (1-72 hours)\n")
+	hours, err := validHours(ctx)
+	if err != nil {
+		return err
 	}
 
-	icert, err := client.IssueSSHCertificate(ctx, org, email.Address, username, &hours)
+	icert, err := client.IssueSSHCertificate(ctx, org, email.Address, principals, &hours)
 	if err != nil {
 		return err
 	}
@@ -197,6 +208,27 @@ func runSSHIssue(ctx context.Context) (err error) {
 	return nil
 }
 
+// validHours returns the number of hours the cert should be valid for, from
+// --valid-for or else --hours. Partial hours are rounded up.
+func validHours(ctx context.Context) (int, error) {
+	hours := flag.GetInt(ctx, "hours")
+
+	if v := flag.GetString(ctx, "valid-for"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --valid-for %q: %w", v, err)
+		}
+
+		hours = int((d + time.Hour - 1) / time.Hour)
+	}
+
+	if hours < 1 || hours > 72 {
+		return 0, fmt.Errorf("Invalid expiration time (1-72 hours)\n")
+	}
+
+	return hours, nil
+}
+
 func argOrPromptImpl(ctx context.Context, nth int, prompt string, first bool) (string, error) {
 	if len(flag.Args(ctx)) >= (nth + 1) {
 		return flag.Args(ctx)[nth], nil
@@ -14,6 +14,7 @@ import (
 	"math/rand"
 	"net"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -57,6 +58,7 @@ type SSHParams struct {
 	App            string
 	Dialer         agent.Dialer
 	Cmd            string
+	Username       string
 	Stdin          io.Reader
 	Stdout         io.WriteCloser
 	Stderr         io.WriteCloser
@@ -96,7 +98,7 @@ func RunSSHCommand(ctx context.Context, app *api.AppCompact, dialer agent.Dialer
 func SSHConnect(p *SSHParams, addr string) error {
 	terminal.Debugf("Fetching certificate for %s\n", addr)
 
-	cert, err := singleUseSSHCertificate(p.Ctx, p.Org)
+	cert, err := singleUseSSHCertificate(p.Ctx, p.Org, p.principals())
 	if err != nil {
 		return fmt.Errorf("create ssh certificate: %w (if you haven't created a key for your org yet, try `flyctl ssh establish`)", err)
 	}
@@ -112,7 +114,7 @@ func SSHConnect(p *SSHParams, addr string) error {
 
 	sshClient := &ssh.Client{
 		Addr: net.JoinHostPort(addr, "22"),
-		User: "root",
+		User: p.sshUser(),
 
 		Dial: p.Dialer.DialContext,
 
@@ -128,7 +130,7 @@ func SSHConnect(p *SSHParams, addr string) error {
 	}
 
 	if err := sshClient.Connect(context.Background()); err != nil {
-		return errors.Wrap(err, "error connecting to SSH server")
+		return p.connectError(err)
 	}
 	defer sshClient.Close()
 
@@ -219,7 +221,38 @@ func marshalED25519PrivateKey(key ed25519.PrivateKey, comment string) []byte {
 	})
 }
 
-func singleUseSSHCertificate(ctx context.Context, org api.OrganizationImpl) (*api.IssuedCertificate, error) {
+// sshUser returns the unix user to log in as, root unless another one was
+// asked for.
+func (p *SSHParams) sshUser() string {
+	if p.Username == "" {
+		return "root"
+	}
+
+	return p.Username
+}
+
+// principals returns the principals to issue the single-use certificate for,
+// leaving root to the API default.
+func (p *SSHParams) principals() []string {
+	if user := p.sshUser(); user != "root" {
+		return []string{user}
+	}
+
+	return nil
+}
+
+// connectError wraps an error connecting to the SSH server, pointing out that
+// authentication failures for a user other than root usually mean the image
+// doesn't have that user.
+func (p *SSHParams) connectError(err error) error {
+	if user := p.sshUser(); user != "root" && strings.Contains(err.Error(), "unable to authenticate") {
+		return errors.Wrapf(err, "error connecting to SSH server as %s (check that the user exists on the machine)", user)
+	}
+
+	return errors.Wrap(err, "error connecting to SSH server")
+}
+
+func singleUseSSHCertificate(ctx context.Context, org api.OrganizationImpl, principals []string) (*api.IssuedCertificate, error) {
 	client := client.FromContext(ctx).API()
 
 	user, err := client.GetCurrentUser(ctx)
@@ -228,7 +261,7 @@ func singleUseSSHCertificate(ctx context.Context, org api.OrganizationImpl) (*ap
 	}
 
 	hours := 1
-	return client.IssueSSHCertificate(ctx, org, user.Email, nil, &hours)
+	return client.IssueSSHCertificate(ctx, org, user.Email, principals, &hours)
 }
 
 func parsePrivateKey(key64 string) (ed25519.PrivateKey, error) {
@@ -0,0 +1,177 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/inancgumus/screen"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// staleRollout is how long a rollout may go without progress before it's
+// deemed to have crashed.
+const staleRollout = time.Hour
+
+// activeRollout returns the latest release of the app along with its rollout,
+// or nil if no rollout is in progress.
+func activeRollout(ctx context.Context, appName string) (*api.Release, error) {
+	releases, err := client.FromContext(ctx).API().GetAppReleases(ctx, appName, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving releases of %s: %w", appName, err)
+	}
+
+	if len(releases) == 0 {
+		return nil, nil
+	}
+
+	release := &releases[0]
+	switch {
+	case release.Metadata == nil || release.Metadata.Rollout == nil:
+		return nil, nil
+	case release.Status == "complete" || release.Status == "failed":
+		return nil, nil
+	case !release.Metadata.Rollout.Active():
+		return nil, nil
+	}
+
+	return release, nil
+}
+
+// runDeployment renders the progress of the rollout of the app's latest
+// release, refreshing it until it's over when the session is interactive.
+func runDeployment(ctx context.Context, app *api.AppCompact) error {
+	var (
+		streams  = iostreams.FromContext(ctx)
+		colorize = streams.ColorScheme()
+		interval = time.Duration(flag.GetInt(ctx, "rate")) * time.Second
+		buf      bytes.Buffer
+		seen     bool
+	)
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	for {
+		release, err := activeRollout(ctx, app.Name)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if release == nil {
+			if seen {
+				fmt.Fprintln(streams.Out, "The rollout is over; see `fly releases` for its outcome")
+			} else {
+				fmt.Fprintf(streams.Out, "No rollout is in progress for %s\n", app.Name)
+			}
+
+			return nil
+		}
+
+		if config.FromContext(ctx).JSONOutput {
+			return render.JSON(streams.Out, release)
+		}
+
+		buf.Reset()
+		if err := renderRollout(ctx, &buf, flapsClient, release); err != nil {
+			return err
+		}
+
+		if !streams.IsInteractive() {
+			_, err := io.Copy(streams.Out, &buf)
+
+			return err
+		}
+
+		screen.Clear()
+		screen.MoveTopLeft()
+
+		fmt.Fprintf(streams.Out, "%s %s %s\n\n", colorize.Bold(app.Name), "at:", colorize.Bold(time.Now().UTC().Format("15:04:05")))
+		io.Copy(streams.Out, &buf)
+
+		seen = true
+		pause.For(ctx, interval)
+	}
+}
+
+func renderRollout(ctx context.Context, w io.Writer, flapsClient *flaps.Client, release *api.Release) error {
+	var (
+		colorize = iostreams.FromContext(ctx).ColorScheme()
+		rollout  = release.Metadata.Rollout
+		stale    = time.Since(rollout.UpdatedAt) > staleRollout
+	)
+
+	startedBy := release.User.Email
+	if startedBy == "" {
+		startedBy = "unknown"
+	}
+
+	fmt.Fprintf(w, "Release v%d of %s, started by %s at %s\n", release.Version, rollout.Image, startedBy, release.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "Last progress at %s\n\n", rollout.UpdatedAt.Format(time.RFC3339))
+
+	// a rollout which hasn't made progress in a while probably died along
+	// with the process driving it, in which case what the machines run now
+	// is shown next to its last recorded state
+	current := map[string]string{}
+	if stale {
+		fmt.Fprintln(w, colorize.Yellow(fmt.Sprintf("The rollout hasn't made progress in over %s; the deploy probably crashed. Compare the images the machines run now to reconcile them.\n", staleRollout)))
+
+		ids := make([]string, 0, len(rollout.Machines))
+		for _, m := range rollout.Machines {
+			ids = append(ids, m.ID)
+		}
+
+		machines, err := flapsClient.GetMany(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed retrieving machines: %w", err)
+		}
+
+		for _, m := range machines {
+			current[m.ID] = m.ImageRefWithVersion()
+		}
+	}
+
+	rows := make([][]string, 0, len(rollout.Machines))
+	for _, m := range rollout.Machines {
+		row := []string{m.ID, m.Region, rolloutState(colorize, m.State)}
+		if stale {
+			row = append(row, current[m.ID])
+		}
+		rows = append(rows, row)
+	}
+
+	cols := []string{"ID", "Region", "State"}
+	if stale {
+		cols = append(cols, "Current Image")
+	}
+
+	return render.Table(w, "", rows, cols...)
+}
+
+func rolloutState(colorize *iostreams.ColorScheme, state string) string {
+	switch state {
+	case api.RolloutDone:
+		return colorize.Green(state)
+	case api.RolloutFailed:
+		return colorize.Red(state)
+	case api.RolloutUpdating:
+		return colorize.Yellow(state)
+	default:
+		return state
+	}
+}
@@ -0,0 +1,389 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command/machine"
+	"github.com/superfly/flyctl/internal/flyerr"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+	"github.com/superfly/flyctl/logs"
+)
+
+// interactiveRefresh is how often the interactive status lists the machines
+// of the app again.
+const interactiveRefresh = 5 * time.Second
+
+const interactiveKeys = "↑/↓ select  r restart  s stop  u start  l tail logs  q quit"
+
+// runInteractive shows the machines of the app in a terminal UI from which
+// they can be restarted, stopped, started and have their logs tailed. Those
+// actions run the code of the respective machine commands.
+func runInteractive(ctx context.Context) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		client  = client.FromContext(ctx).API()
+		appName = app.NameFromContext(ctx)
+	)
+
+	if !io.IsInteractive() {
+		return flyerr.WithCode(flyerr.CodeValidation, errors.New("--interactive requires a terminal; run `fly status` for plain output instead"))
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed to get app: %w", err)
+	}
+
+	if app.PlatformVersion != "machines" {
+		return flyerr.WithCode(flyerr.CodePlatformUnsupported, errors.New("--interactive is only supported for machines apps"))
+	}
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+	ctx = flaps.NewContext(ctx, flapsClient)
+
+	ui := newStatusUI(ctx, app)
+
+	return ui.run()
+}
+
+type statusUI struct {
+	ctx context.Context
+	app *api.AppCompact
+
+	tui     *tview.Application
+	table   *tview.Table
+	details *tview.TextView
+	output  *tview.TextView
+	footer  *tview.TextView
+
+	mu         sync.Mutex
+	machines   []*api.Machine
+	busy       bool
+	cancelTail context.CancelFunc
+}
+
+func newStatusUI(ctx context.Context, app *api.AppCompact) *statusUI {
+	ui := &statusUI{
+		ctx:     ctx,
+		app:     app,
+		tui:     tview.NewApplication(),
+		table:   tview.NewTable(),
+		details: tview.NewTextView(),
+		output:  tview.NewTextView(),
+		footer:  tview.NewTextView(),
+	}
+
+	ui.table.SetSelectable(true, false).SetFixed(1, 0)
+	ui.table.SetBorder(true).SetTitle(fmt.Sprintf(" Machines of %s ", app.Name))
+	ui.table.SetSelectionChangedFunc(func(int, int) {
+		ui.renderDetails()
+	})
+
+	ui.details.SetBorder(true).SetTitle(" Details ")
+
+	ui.output.SetScrollable(true).SetMaxLines(500)
+	ui.output.SetBorder(true).SetTitle(" Output ")
+	ui.output.SetChangedFunc(func() {
+		ui.tui.Draw()
+	})
+
+	ui.footer.SetText(interactiveKeys)
+
+	top := tview.NewFlex().
+		AddItem(ui.table, 0, 3, true).
+		AddItem(ui.details, 0, 2, false)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(top, 0, 3, true).
+		AddItem(ui.output, 0, 2, false).
+		AddItem(ui.footer, 1, 0, false)
+
+	ui.tui.SetRoot(layout, true).SetInputCapture(ui.handleKey)
+
+	return ui
+}
+
+func (ui *statusUI) run() error {
+	ctx, cancel := context.WithCancel(ui.ctx)
+	defer cancel()
+
+	go ui.refreshEvery(ctx)
+
+	err := ui.tui.Run()
+	ui.stopTail()
+
+	return err
+}
+
+func (ui *statusUI) refreshEvery(ctx context.Context) {
+	ticker := time.NewTicker(interactiveRefresh)
+	defer ticker.Stop()
+
+	for {
+		ui.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh lists the machines again, keeping the selected one selected.
+func (ui *statusUI) refresh(ctx context.Context) {
+	machines, err := flaps.FromContext(ctx).ListActive(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(ui.output, "failed listing machines: %v\n", err)
+		}
+
+		return
+	}
+
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].ID < machines[j].ID
+	})
+
+	ui.tui.QueueUpdateDraw(func() {
+		selected := ui.selectedID()
+
+		ui.mu.Lock()
+		ui.machines = machines
+		ui.mu.Unlock()
+
+		ui.renderTable(selected)
+		ui.renderDetails()
+	})
+}
+
+func (ui *statusUI) renderTable(selected string) {
+	ui.table.Clear()
+
+	for col, title := range []string{"ID", "Name", "State", "Region", "Health checks", "Image"} {
+		ui.table.SetCell(0, col, tview.NewTableCell(title).SetSelectable(false).SetAttributes(tcell.AttrBold))
+	}
+
+	row := 1
+	for i, m := range ui.machines {
+		cells := []string{m.ID, m.Name, m.State, m.Region, render.MachineHealthChecksSummary(m), m.ImageRefWithVersion()}
+		for col, text := range cells {
+			ui.table.SetCell(i+1, col, tview.NewTableCell(text))
+		}
+
+		if m.ID == selected {
+			row = i + 1
+		}
+	}
+
+	if len(ui.machines) > 0 {
+		ui.table.Select(row, 0)
+	}
+}
+
+func (ui *statusUI) renderDetails() {
+	m := ui.selected()
+	if m == nil {
+		ui.details.SetText("No machine selected")
+		return
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "ID:      %s\n", m.ID)
+	fmt.Fprintf(&b, "State:   %s\n", m.State)
+	fmt.Fprintf(&b, "Region:  %s\n", m.Region)
+	fmt.Fprintf(&b, "Image:   %s\n", m.ImageRefWithVersion())
+	if m.Config != nil {
+		fmt.Fprintf(&b, "Process: %s\n", m.Config.Metadata["process_group"])
+	}
+
+	fmt.Fprintf(&b, "\nChecks\n")
+	if len(m.Checks) == 0 {
+		fmt.Fprintf(&b, "  none\n")
+	}
+	for _, check := range m.Checks {
+		fmt.Fprintf(&b, "  %s: %s %s\n", check.Name, check.Status, strings.TrimSpace(check.Output))
+	}
+
+	fmt.Fprintf(&b, "\nEvents\n")
+	for i, event := range m.Events {
+		if i == 10 {
+			break
+		}
+		at := time.UnixMilli(event.Timestamp).Format(time.RFC3339)
+		fmt.Fprintf(&b, "  %s %s %s (%s)\n", at, event.Type, event.Status, event.Source)
+	}
+
+	ui.details.SetText(b.String()).ScrollToBeginning()
+}
+
+func (ui *statusUI) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Rune() {
+	case 'q':
+		ui.tui.Stop()
+	case 'r':
+		ui.act("Restarting", func(ctx context.Context, m *api.Machine) error {
+			return mach.WithLease(ctx, m, func(ctx context.Context, m *api.Machine) error {
+				return mach.Restart(ctx, m, &api.RestartMachineInput{})
+			})
+		})
+	case 's':
+		ui.act("Stopping", func(ctx context.Context, m *api.Machine) error {
+			return machine.Stop(ctx, m.ID)
+		})
+	case 'u':
+		ui.act("Starting", func(ctx context.Context, m *api.Machine) error {
+			return machine.Start(ctx, m.ID)
+		})
+	case 'l':
+		ui.tail()
+	default:
+		return event
+	}
+
+	return nil
+}
+
+// act runs fn against the selected machine in the background, unless another
+// action is still running, and writes what it prints to the output pane.
+func (ui *statusUI) act(verb string, fn func(context.Context, *api.Machine) error) {
+	m := ui.selected()
+	if m == nil {
+		return
+	}
+
+	ui.mu.Lock()
+	if ui.busy {
+		ui.mu.Unlock()
+		fmt.Fprintln(ui.output, "Another action is still running")
+		return
+	}
+	ui.busy = true
+	ui.mu.Unlock()
+
+	ui.stopTail()
+	ui.output.Clear()
+	ui.output.SetTitle(" Output ")
+
+	go func() {
+		defer func() {
+			ui.mu.Lock()
+			ui.busy = false
+			ui.mu.Unlock()
+		}()
+
+		ctx := iostreams.NewContext(ui.ctx, paneStreams(ui.output))
+
+		fmt.Fprintf(ui.output, "%s machine %s\n", verb, m.ID)
+		if err := fn(ctx, m); err != nil {
+			fmt.Fprintf(ui.output, "Error: %v\n", err)
+		} else {
+			fmt.Fprintf(ui.output, "Done\n")
+		}
+
+		ui.refresh(ui.ctx)
+	}()
+}
+
+// tail tails the logs of the selected machine into the output pane, until
+// another action replaces them.
+func (ui *statusUI) tail() {
+	m := ui.selected()
+	if m == nil {
+		return
+	}
+
+	ui.stopTail()
+
+	ctx, cancel := context.WithCancel(ui.ctx)
+	ui.mu.Lock()
+	ui.cancelTail = cancel
+	ui.mu.Unlock()
+
+	ui.output.Clear().ScrollToEnd()
+	ui.output.SetTitle(fmt.Sprintf(" Logs of %s ", m.ID))
+
+	entries := make(chan logs.LogEntry)
+	opts := &logs.LogOptions{
+		AppName: ui.app.Name,
+		VMID:    m.ID,
+	}
+
+	go func() {
+		defer close(entries)
+
+		if err := logs.Poll(ctx, entries, client.FromContext(ctx).API(), opts); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(ui.output, "Error: %v\n", err)
+		}
+	}()
+
+	go func() {
+		for entry := range entries {
+			fmt.Fprintf(ui.output, "%s %s\n", entry.Timestamp, entry.Message)
+		}
+	}()
+}
+
+func (ui *statusUI) stopTail() {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	if ui.cancelTail != nil {
+		ui.cancelTail()
+		ui.cancelTail = nil
+	}
+}
+
+func (ui *statusUI) selectedID() string {
+	if m := ui.selected(); m != nil {
+		return m.ID
+	}
+
+	return ""
+}
+
+func (ui *statusUI) selected() *api.Machine {
+	row, _ := ui.table.GetSelection()
+
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	if row < 1 || row > len(ui.machines) {
+		return nil
+	}
+
+	return ui.machines[row-1]
+}
+
+// paneStreams returns streams which write to w, without colors, spinners or
+// prompts, which would garble the UI.
+func paneStreams(w io.Writer) *iostreams.IOStreams {
+	streams := &iostreams.IOStreams{
+		In:     io.NopCloser(strings.NewReader("")),
+		Out:    w,
+		ErrOut: w,
+	}
+	streams.SetNeverPrompt(true)
+
+	return streams
+}
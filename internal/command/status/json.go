@@ -0,0 +1,166 @@
+package status
+
+import (
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	mach "github.com/superfly/flyctl/internal/machine"
+)
+
+// machinesStatus is the JSON document status renders for machines apps. Its
+// shape is meant to be scripted against, so fields are only ever added.
+type machinesStatus struct {
+	App         statusApp                       `json:"app"`
+	Autostop    bool                            `json:"autostop"`
+	SuspendedAt *time.Time                      `json:"suspended_at,omitempty"`
+	Autoscale   map[string]mach.AutoscalePolicy `json:"autoscale,omitempty"`
+	Machines    []statusMachine                 `json:"machines"`
+	Notices     []machineNotice                 `json:"notices"`
+}
+
+type statusApp struct {
+	Name         string `json:"name"`
+	Organization string `json:"organization"`
+	Hostname     string `json:"hostname"`
+	Platform     string `json:"platform"`
+	Status       string `json:"status"`
+	Deployed     bool   `json:"deployed"`
+	Network      string `json:"network,omitempty"`
+}
+
+type statusMachine struct {
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	State          string        `json:"state"`
+	Region         string        `json:"region"`
+	ProcessGroup   string        `json:"process_group,omitempty"`
+	ImageRef       string        `json:"image_ref"`
+	Version        string        `json:"image_version,omitempty"`
+	PrivateIP      string        `json:"private_ip"`
+	CreatedAt      string        `json:"created_at"`
+	UpdatedAt      string        `json:"updated_at"`
+	Health         statusHealth  `json:"health"`
+	Checks         []statusCheck `json:"checks"`
+	LastStopReason string        `json:"last_stop_reason,omitempty"`
+	PinnedRelease  string        `json:"pinned_release,omitempty"`
+	OrphanedGroup  string        `json:"orphaned_group,omitempty"`
+	Maintenance    bool          `json:"maintenance"`
+	MaintenanceEnd *time.Time    `json:"maintenance_until,omitempty"`
+	HostStatus     string        `json:"host_status"`
+}
+
+// statusHealth sums up the results of the checks of a machine. Total counts
+// the checks the machine is configured with, some of which may not have
+// reported yet.
+type statusHealth struct {
+	Total    int `json:"total"`
+	Passing  int `json:"passing"`
+	Warning  int `json:"warning"`
+	Critical int `json:"critical"`
+}
+
+type statusCheck struct {
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	Output    string     `json:"output"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func newMachinesStatus(app *api.AppCompact, machines []*api.Machine, notices []machineNotice) machinesStatus {
+	doc := machinesStatus{
+		App: statusApp{
+			Name:     app.Name,
+			Hostname: app.Hostname,
+			Platform: app.PlatformVersion,
+			Status:   app.Status,
+			Deployed: app.Deployed,
+			Network:  app.Network,
+		},
+		Autostop: autostopEnabled(machines),
+		Machines: make([]statusMachine, 0, len(machines)),
+		Notices:  notices,
+	}
+
+	if app.Organization != nil {
+		doc.App.Organization = app.Organization.Slug
+	}
+
+	if at, ok := mach.SuspendedAt(machines); ok {
+		doc.SuspendedAt = &at
+	}
+
+	if policies := mach.AutoscaleByRegion(machines); len(policies) > 0 {
+		doc.Autoscale = policies
+	}
+
+	for _, m := range machines {
+		doc.Machines = append(doc.Machines, newStatusMachine(m))
+	}
+
+	return doc
+}
+
+func newStatusMachine(m *api.Machine) statusMachine {
+	sm := statusMachine{
+		ID:             m.ID,
+		Name:           m.Name,
+		State:          m.State,
+		Region:         m.Region,
+		ImageRef:       m.FullImageRef(),
+		Version:        m.ImageVersion(),
+		PrivateIP:      m.PrivateIP,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+		Health:         machineHealth(m),
+		Checks:         make([]statusCheck, 0, len(m.Checks)),
+		LastStopReason: lastStopReason(m),
+		HostStatus:     m.HostHealth(),
+	}
+
+	sm.PinnedRelease, _ = mach.PinnedRelease(m)
+	sm.OrphanedGroup, _ = mach.OrphanedGroup(m)
+	if until, paused := m.ChecksPaused(time.Now()); paused {
+		sm.Maintenance = true
+		if !until.IsZero() {
+			sm.MaintenanceEnd = &until
+		}
+	}
+
+	if m.Config != nil {
+		sm.ProcessGroup = m.Config.Metadata["process_group"]
+	}
+
+	for _, check := range m.Checks {
+		sm.Checks = append(sm.Checks, statusCheck{
+			Name:      check.Name,
+			Status:    check.Status,
+			Output:    check.Output,
+			UpdatedAt: check.UpdatedAt,
+		})
+	}
+
+	return sm
+}
+
+func machineHealth(m *api.Machine) (health statusHealth) {
+	if m.Config != nil {
+		health.Total = len(m.Config.Checks)
+	}
+
+	for _, check := range m.Checks {
+		switch check.Status {
+		case "passing":
+			health.Passing++
+		case "warning", "warn":
+			health.Warning++
+		case "critical":
+			health.Critical++
+		}
+	}
+
+	if len(m.Checks) > health.Total {
+		health.Total = len(m.Checks)
+	}
+
+	return
+}
@@ -5,44 +5,99 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
 
 func renderMachineStatus(ctx context.Context, app *api.AppCompact) error {
-	var (
-		io       = iostreams.FromContext(ctx)
-		colorize = io.ColorScheme()
-		client   = client.FromContext(ctx).API()
-	)
 	flapsClient, err := flaps.New(ctx, app)
 	if err != nil {
 		return err
 	}
 
-	machines, err := flapsClient.ListActive(ctx)
+	machines, err := listMachines(ctx, flapsClient)
 	if err != nil {
 		return err
 	}
 
+	return renderMachines(ctx, app, machines, nil)
+}
+
+// listMachines lists the active machines of the app, sorted by region and,
+// within regions, by when they were created.
+func listMachines(ctx context.Context, flapsClient *flaps.Client) ([]*api.Machine, error) {
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	sort.Slice(machines, func(i, j int) bool {
-		return machines[i].ID < machines[j].ID
+		a, b := machines[i], machines[j]
+		switch {
+		case a.Region != b.Region:
+			return a.Region < b.Region
+		case a.CreatedAt != b.CreatedAt:
+			return a.CreatedAt < b.CreatedAt
+		default:
+			return a.ID < b.ID
+		}
 	})
 
+	return machines, nil
+}
+
+// renderMachines renders the status of the machines of app, highlighting the
+// states of the machines in changed.
+func renderMachines(ctx context.Context, app *api.AppCompact, machines []*api.Machine, changed map[string]bool) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		client   = client.FromContext(ctx).API()
+	)
+
+	notices := collectNotices(machines)
+
+	if config.FromContext(ctx).JSONOutput {
+		if err := render.JSON(io.Out, newMachinesStatus(app, machines, notices)); err != nil {
+			return err
+		}
+
+		return noticesError(ctx, notices)
+	}
+
 	if app.IsPostgresApp() {
-		return renderPGStatus(ctx, app, machines)
+		if err := renderPGStatus(ctx, app, machines, changed); err != nil {
+			return err
+		}
+
+		return renderNotices(ctx, io.Out, notices)
 	}
 
-	// Tracks latest eligible version
-	var latest *api.ImageVersion
+	// Tracks latest eligible version of each process group, since groups
+	// with builds of their own run images of their own
+	latest := map[string]*api.ImageVersion{}
 
-	var updatable []*api.Machine
+	var updatable, pinned, orphaned []*api.Machine
 
 	for _, machine := range machines {
+		if _, ok := mach.OrphanedGroup(machine); ok {
+			orphaned = append(orphaned, machine)
+		}
+
+		// pinned machines run older images on purpose
+		if _, ok := mach.PinnedRelease(machine); ok {
+			pinned = append(pinned, machine)
+			continue
+		}
+
 		image := fmt.Sprintf("%s:%s", machine.ImageRef.Repository, machine.ImageRef.Tag)
 
 		latestImage, err := client.GetLatestImageDetails(ctx, image)
@@ -54,12 +109,13 @@ func renderMachineStatus(ctx context.Context, app *api.AppCompact) error {
 			return fmt.Errorf("unable to fetch latest image details for %s: %w", image, err)
 		}
 
-		if latest == nil {
-			latest = latestImage
+		group := machine.Config.Metadata["process_group"]
+		if latest[group] == nil {
+			latest[group] = latestImage
 		}
 
 		// Exclude machines that are already running the latest version
-		if machine.ImageRef.Digest == latest.Digest {
+		if machine.ImageRef.Digest == latest[group].Digest {
 			continue
 		}
 		updatable = append(updatable, machine)
@@ -69,7 +125,8 @@ func renderMachineStatus(ctx context.Context, app *api.AppCompact) error {
 		msgs := []string{"Updates available:\n\n"}
 
 		for _, machine := range updatable {
-			latestStr := fmt.Sprintf("%s:%s (%s)", latest.Repository, latest.Tag, latest.Version)
+			l := latest[machine.Config.Metadata["process_group"]]
+			latestStr := fmt.Sprintf("%s:%s (%s)", l.Repository, l.Tag, l.Version)
 			msg := fmt.Sprintf("Machine %q %s -> %s\n", machine.ID, machine.ImageRefWithVersion(), latestStr)
 			msgs = append(msgs, msg)
 		}
@@ -78,27 +135,134 @@ func renderMachineStatus(ctx context.Context, app *api.AppCompact) error {
 		fmt.Fprintln(io.ErrOut, colorize.Yellow("Run `flyctl image update` to migrate to the latest image version."))
 	}
 
+	if len(pinned) > 0 {
+		msgs := []string{"Pinned to releases:\n\n"}
+
+		for _, machine := range pinned {
+			version, _ := mach.PinnedRelease(machine)
+			msgs = append(msgs, fmt.Sprintf("Machine %q %s (release v%s)\n", machine.ID, machine.ImageRefWithVersion(), version))
+		}
+
+		fmt.Fprintln(io.Out, colorize.Gray(strings.Join(msgs, "")))
+		fmt.Fprintln(io.ErrOut, colorize.Gray("Deployments skip pinned machines; run `fly machine update <id> --clear-pin` to unpin one."))
+	}
+
+	if len(orphaned) > 0 {
+		msgs := []string{"Machines of process groups removed from fly.toml:\n\n"}
+
+		for _, machine := range orphaned {
+			group, _ := mach.OrphanedGroup(machine)
+			msgs = append(msgs, fmt.Sprintf("Machine %q %s (group %s)\n", machine.ID, machine.State, group))
+		}
+
+		fmt.Fprintln(io.Out, colorize.Yellow(strings.Join(msgs, "")))
+		fmt.Fprintln(io.ErrOut, colorize.Yellow("Deployments no longer update these; run `fly machine destroy <id>` once they're no longer needed."))
+	}
+
 	obj := [][]string{{app.Name, app.Organization.Slug, app.Hostname, app.PlatformVersion}}
-	if err := render.VerticalTable(io.Out, "App", obj, "Name", "Owner", "Hostname", "Platform"); err != nil {
+	cols := []string{"Name", "Owner", "Hostname", "Platform"}
+	if app.Network != "" {
+		cols = append(cols, "Network")
+		obj[0] = append(obj[0], app.Network)
+	}
+	if policies := mach.AutoscaleByRegion(machines); len(policies) > 0 {
+		cols = append(cols, "Autoscale")
+		obj[0] = append(obj[0], formatAutoscale(policies))
+	}
+
+	if err := render.VerticalTable(io.Out, "App", obj, cols...); err != nil {
 		return err
 	}
+	renderReleaseNotices(ctx, io.Out, app.Name)
+
+	if suspendedAt, suspended := mach.SuspendedAt(machines); suspended {
+		fmt.Fprintln(io.Out, colorize.Yellow(fmt.Sprintf("Suspended by flyctl at %s; run `fly apps resume %s` to start its machines again", suspendedAt.Format(time.RFC3339), app.Name)))
+		fmt.Fprintln(io.Out)
+	}
+
+	if autostopEnabled(machines) {
+		fmt.Fprintln(io.Out, colorize.Gray("The services of this app stop idle machines and start them on demand, so stopped machines may be intentional."))
+		fmt.Fprintln(io.Out)
+	}
+
+	verbose := config.FromContext(ctx).VerboseOutput
+
+	// host statuses are only shown when some host is impaired
+	var hostColumn bool
+	for _, machine := range machines {
+		hostColumn = hostColumn || machine.HostImpaired()
+	}
 
 	rows := [][]string{}
 	for _, machine := range machines {
-		rows = append(rows, []string{
+		row := []string{
 			machine.ID,
-			machine.State,
+			machineState(colorize, machine, changed),
 			machine.Region,
-			render.MachineHealthChecksSummary(machine),
+			machineChecks(colorize, machine),
+			machineSize(machine),
 			machine.ImageRefWithVersion(),
 			machine.CreatedAt,
 			machine.UpdatedAt,
-		})
+		}
+		if hostColumn {
+			row = append(row, hostStatus(colorize, machine))
+		}
+		if verbose {
+			row = append(row, lastStopReason(machine))
+		}
+		rows = append(rows, row)
+		rows = append(rows, containerRows(machine, len(row))...)
+	}
+	absolute := flag.GetAbsoluteTimestamps(ctx)
+
+	columns := []render.Column{
+		render.Col("ID"),
+		render.Col("State"),
+		render.Col("Region"),
+		render.Col("Health checks"),
+		render.Col("Size"),
+		render.Col("Image"),
+		render.TimestampCol("Created", absolute),
+		render.TimestampCol("Updated", absolute),
 	}
-	return render.Table(io.Out, "", rows, "ID", "State", "Region", "Health checks", "Image", "Created", "Updated")
+	if hostColumn {
+		columns = append(columns, render.Col("Host Status"))
+	}
+	if verbose {
+		columns = append(columns, render.Col("Last Stop Reason"))
+	}
+
+	if err := render.TableWithColumns(io.Out, "", rows, columns...); err != nil {
+		return err
+	}
+
+	if hostColumn {
+		fmt.Fprintln(io.ErrOut, colorize.Yellow("Machines on impaired hosts may misbehave; run `fly machine clone <id>` to move a copy of one to another host."))
+	}
+
+	return renderNotices(ctx, io.Out, notices)
 }
 
-func renderPGStatus(ctx context.Context, app *api.AppCompact, machines []*api.Machine) (err error) {
+// containerRows returns the rows of the containers of machine, for machines
+// running more than one, nested under the row of the machine.
+func containerRows(machine *api.Machine, width int) [][]string {
+	if machine.Config == nil {
+		return nil
+	}
+
+	rows := make([][]string, 0, len(machine.Config.Containers))
+	for _, c := range machine.Config.Containers {
+		row := make([]string, width)
+		row[0] = "  └ " + c.Name
+		row[5] = c.Image
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func renderPGStatus(ctx context.Context, app *api.AppCompact, machines []*api.Machine, changed map[string]bool) (err error) {
 	var (
 		io       = iostreams.FromContext(ctx)
 		colorize = io.ColorScheme()
@@ -150,6 +314,17 @@ func renderPGStatus(ctx context.Context, app *api.AppCompact, machines []*api.Ma
 		fmt.Fprintln(io.ErrOut, colorize.Yellow("Run `flyctl image update` to migrate to the latest image version."))
 	}
 
+	// zones only add to the status, so failing to look them up leaves them out
+	zones, err := mach.Zones(ctx, app.Name, machines)
+	if err != nil {
+		fmt.Fprintf(io.ErrOut, "failed retrieving the zones of the machines: %v\n", err)
+		zones = map[string]string{}
+	}
+
+	for _, warning := range sharedZoneWarnings(machines, zones) {
+		fmt.Fprintln(io.ErrOut, colorize.Yellow(warning))
+	}
+
 	rows := [][]string{}
 
 	for _, machine := range machines {
@@ -166,14 +341,147 @@ func renderPGStatus(ctx context.Context, app *api.AppCompact, machines []*api.Ma
 
 		rows = append(rows, []string{
 			machine.ID,
-			machine.State,
+			machineState(colorize, machine, changed),
 			role,
 			machine.Region,
-			render.MachineHealthChecksSummary(machine),
+			machineZone(zones, machine),
+			machineChecks(colorize, machine),
+			machineSize(machine),
 			machine.ImageRefWithVersion(),
 			machine.CreatedAt,
 			machine.UpdatedAt,
 		})
 	}
-	return render.Table(io.Out, "", rows, "ID", "State", "Role", "Region", "Health checks", "Image", "Created", "Updated")
+	absolute := flag.GetAbsoluteTimestamps(ctx)
+
+	return render.TableWithColumns(io.Out, "", rows,
+		render.Col("ID"),
+		render.Col("State"),
+		render.Col("Role"),
+		render.Col("Region"),
+		render.Col("Zone"),
+		render.Col("Health checks"),
+		render.Col("Size"),
+		render.Col("Image"),
+		render.TimestampCol("Created", absolute),
+		render.TimestampCol("Updated", absolute),
+	)
+}
+
+// machineState returns the state of machine, highlighted if it's in changed.
+func machineState(colorize *iostreams.ColorScheme, machine *api.Machine, changed map[string]bool) string {
+	if changed[machine.ID] {
+		return colorize.Yellow(machine.State)
+	}
+
+	return machine.State
+}
+
+// machineChecks sums up the checks of machine as passing out of total, in red
+// should any of them not pass, or as - should it have none.
+func machineChecks(colorize *iostreams.ColorScheme, machine *api.Machine) string {
+	health := machineHealth(machine)
+	if health.Total == 0 {
+		return "-"
+	}
+
+	if _, paused := machine.ChecksPaused(time.Now()); paused {
+		return colorize.Yellow("maintenance")
+	}
+
+	summary := fmt.Sprintf("%d/%d passing", health.Passing, health.Total)
+	if health.Passing < health.Total {
+		return colorize.Red(summary)
+	}
+
+	return summary
+}
+
+func hostStatus(colorize *iostreams.ColorScheme, machine *api.Machine) string {
+	if machine.HostImpaired() {
+		return colorize.Red(machine.HostHealth())
+	}
+
+	return machine.HostHealth()
+}
+
+// machineSize names the size of machine the way VM sizes are named, along
+// with its memory and GPUs, such as shared-cpu-1x 256MB or performance-8x
+// 32768MB 1x a100-40gb.
+func machineSize(machine *api.Machine) string {
+	if machine.Config == nil || machine.Config.Guest == nil {
+		return "-"
+	}
+
+	guest := machine.Config.Guest
+
+	kind := "shared-cpu"
+	if guest.CPUKind == "performance" {
+		kind = "performance"
+	}
+
+	size := fmt.Sprintf("%s-%dx %dMB", kind, guest.CPUs, guest.MemoryMB)
+	if guest.GPUKind != "" {
+		size += fmt.Sprintf(" %dx %s", guest.GPUs, guest.GPUKind)
+	}
+
+	return size
+}
+
+// machineZone returns the hardware zone of machine or "-" if it's unknown.
+func machineZone(zones map[string]string, machine *api.Machine) string {
+	if zone := zones[machine.ID]; zone != "" {
+		return zone
+	}
+
+	return "-"
+}
+
+// sharedZoneWarnings warns of each hardware zone more than one of the
+// members of a cluster run in, since a single host failure takes them all
+// down.
+func sharedZoneWarnings(machines []*api.Machine, zones map[string]string) (warnings []string) {
+	members := map[string][]string{}
+	for _, machine := range machines {
+		if zone := zones[machine.ID]; zone != "" {
+			members[zone] = append(members[zone], machine.ID)
+		}
+	}
+
+	names := make([]string, 0, len(members))
+	for zone := range members {
+		names = append(names, zone)
+	}
+	sort.Strings(names)
+
+	for _, zone := range names {
+		if ids := members[zone]; len(ids) > 1 {
+			warnings = append(warnings, fmt.Sprintf("WARNING: members %s share hardware zone %s; a single host failure affects all of them", strings.Join(ids, ", "), zone))
+		}
+	}
+
+	return
+}
+
+// formatAutoscale sums up the autoscale policies of the regions of an app,
+// which are usually all the same.
+func formatAutoscale(policies map[string]mach.AutoscalePolicy) string {
+	regions := make([]string, 0, len(policies))
+	for region := range policies {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	parts := make([]string, 0, len(regions))
+	uniform := true
+	for _, region := range regions {
+		parts = append(parts, fmt.Sprintf("%s=%s", region, policies[region]))
+		uniform = uniform && policies[region] == policies[regions[0]]
+	}
+
+	if uniform {
+		return fmt.Sprintf("%s machines in %s", policies[regions[0]], strings.Join(regions, ", "))
+	}
+
+	return strings.Join(parts, " ")
 }
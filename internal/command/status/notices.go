@@ -0,0 +1,94 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/render"
+)
+
+// machineNotice is a pending host event affecting a machine.
+type machineNotice struct {
+	MachineID string     `json:"machine_id"`
+	Region    string     `json:"region"`
+	Type      string     `json:"type"`
+	Reason    string     `json:"reason,omitempty"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+}
+
+func collectNotices(machines []*api.Machine) []machineNotice {
+	notices := []machineNotice{}
+
+	for _, machine := range machines {
+		for _, n := range machine.Notices {
+			notices = append(notices, machineNotice{
+				MachineID: machine.ID,
+				Region:    machine.Region,
+				Type:      n.Type,
+				Reason:    n.Reason,
+				StartsAt:  n.StartsAt,
+				EndsAt:    n.EndsAt,
+			})
+		}
+	}
+
+	return notices
+}
+
+// renderNotices renders the notices, if there are any, and returns the error
+// the notices-exit-code flag calls for.
+func renderNotices(ctx context.Context, w io.Writer, notices []machineNotice) error {
+	if len(notices) == 0 {
+		return nil
+	}
+
+	rows := make([][]string, 0, len(notices))
+	for _, n := range notices {
+		rows = append(rows, []string{
+			n.MachineID,
+			n.Region,
+			n.Type,
+			formatNoticeWindow(n.StartsAt, n.EndsAt),
+			n.Reason,
+		})
+	}
+
+	if err := render.Table(w, "Notices", rows, "Machine", "Region", "Type", "Window", "Reason"); err != nil {
+		return err
+	}
+
+	return noticesError(ctx, notices)
+}
+
+func noticesError(ctx context.Context, notices []machineNotice) error {
+	code := flag.GetInt(ctx, "notices-exit-code")
+	if code == 0 || len(notices) == 0 {
+		return nil
+	}
+
+	return &flyerr.ExitCodeError{
+		Err:  fmt.Errorf("%d pending host notices affect this app's machines", len(notices)),
+		Code: code,
+	}
+}
+
+func formatNoticeWindow(start, end *time.Time) string {
+	const layout = "2006-01-02 15:04 MST"
+
+	switch {
+	case start == nil && end == nil:
+		return "unscheduled"
+	case end == nil:
+		return "from " + start.Local().Format(layout)
+	case start == nil:
+		return "until " + end.Local().Format(layout)
+	default:
+		return start.Local().Format(layout) + " - " + end.Local().Format(layout)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/inancgumus/screen"
 	"github.com/spf13/cobra"
 
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/iostreams"
 
 	"github.com/superfly/flyctl/api"
@@ -52,7 +54,7 @@ currently allocated.
 		},
 		flag.Bool{
 			Name:        "deployment",
-			Description: "Always show deployment status",
+			Description: "Always show deployment status. For machines apps, follow the rollout in progress instead, whichever process drives it",
 		},
 		flag.Bool{
 			Name:        "watch",
@@ -63,6 +65,15 @@ currently allocated.
 			Description: "Refresh Rate for --watch",
 			Default:     5,
 		},
+		flag.Timestamps(),
+		flag.Bool{
+			Name:        "interactive",
+			Description: "Browse the machines of the app in a terminal UI, from which they can be restarted, stopped, started and have their logs tailed. Machines apps only.",
+		},
+		flag.Int{
+			Name:        "notices-exit-code",
+			Description: "Exit with this code when machines have pending host maintenance notices. Machines apps only.",
+		},
 	)
 
 	cmd.AddCommand(
@@ -75,7 +86,29 @@ currently allocated.
 func run(ctx context.Context) error {
 	watch := flag.GetBool(ctx, "watch")
 	if watch && config.FromContext(ctx).JSONOutput {
-		return errors.New("--watch and --json are not supported together")
+		return flyerr.WithCode(flyerr.CodeValidation, errors.New("--watch and --json are not supported together"))
+	}
+	if watch && flag.GetInt(ctx, "notices-exit-code") != 0 {
+		return flyerr.WithCode(flyerr.CodeValidation, errors.New("--watch and --notices-exit-code are not supported together"))
+	}
+
+	if flag.GetBool(ctx, "deployment") {
+		app, err := client.FromContext(ctx).API().GetAppCompact(ctx, app.NameFromContext(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to get app: %w", err)
+		}
+
+		if app.PlatformVersion == "machines" {
+			return runDeployment(ctx, app)
+		}
+	}
+
+	if flag.GetBool(ctx, "interactive") {
+		if watch || config.FromContext(ctx).JSONOutput {
+			return flyerr.WithCode(flyerr.CodeValidation, errors.New("--interactive is not supported together with --watch or --json"))
+		}
+
+		return runInteractive(ctx)
 	}
 
 	if !watch {
@@ -99,7 +132,7 @@ func once(ctx context.Context, out io.Writer) (err error) {
 
 	app, err := client.GetAppCompact(ctx, appName)
 	if err != nil {
-		return fmt.Errorf("failed to get app: %s", err)
+		return fmt.Errorf("failed to get app: %w", err)
 	}
 
 	platformVersion := app.PlatformVersion
@@ -139,9 +172,16 @@ func once(ctx context.Context, out io.Writer) (err error) {
 		},
 	}
 
-	if err = render.VerticalTable(out, "App", obj, "Name", "Owner", "Version", "Status", "Hostname", "Platform"); err != nil {
+	cols := []string{"Name", "Owner", "Version", "Status", "Hostname", "Platform"}
+	if app.Network != "" {
+		cols = append(cols, "Network")
+		obj[0] = append(obj[0], app.Network)
+	}
+
+	if err = render.VerticalTable(out, "App", obj, cols...); err != nil {
 		return
 	}
+	renderReleaseNotices(ctx, out, appName)
 	if !status.Deployed && platformVersion == "" {
 		_, err = fmt.Fprintln(out, "App has not been deployed yet.")
 
@@ -162,6 +202,34 @@ func once(ctx context.Context, out io.Writer) (err error) {
 	return
 }
 
+// renderReleaseNotices points at the logs of the release command of the app's
+// latest release, should that release have failed, and at the regions it left
+// on their previous release, should it have been partial.
+func renderReleaseNotices(ctx context.Context, out io.Writer, appName string) {
+	releases, err := client.FromContext(ctx).API().GetAppReleases(ctx, appName, 1)
+	if err != nil || len(releases) == 0 {
+		return
+	}
+
+	release := releases[0]
+	if release.Metadata == nil {
+		return
+	}
+
+	colorize := iostreams.FromContext(ctx).ColorScheme()
+
+	if id := release.Metadata.ReleaseCommandInstanceID; strings.EqualFold(release.Status, "failed") && id != "" {
+		fmt.Fprintln(out, colorize.Yellow(fmt.Sprintf("Release v%d failed. Its release command ran on %s; view its logs with: fly logs -i %s", release.Version, id, id)))
+		fmt.Fprintln(out)
+	}
+
+	if regions := release.Metadata.SkippedRegions; len(regions) > 0 {
+		list := strings.Join(regions, ",")
+		fmt.Fprintln(out, colorize.Yellow(fmt.Sprintf("Release v%d was partial; machines in %s weren't updated. Complete it with: fly deploy --only-regions %s", release.Version, list, list)))
+		fmt.Fprintln(out)
+	}
+}
+
 func renderDeploymentStatus(w io.Writer, ds *api.DeploymentStatus) error {
 	obj := [][]string{
 		{
@@ -194,13 +262,22 @@ func runWatch(ctx context.Context) (err error) {
 
 	sleep := flag.GetInt(ctx, "rate")
 	if sleep < 1 || sleep > 3600 {
-		err = errors.New("--rate must be in the [1, 3600] range")
+		err = flyerr.WithCode(flyerr.CodeValidation, errors.New("--rate must be in the [1, 3600] range"))
 
 		return
 	}
 
 	appName := app.NameFromContext(ctx)
 
+	app, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed to get app: %w", err)
+	}
+
+	if app.PlatformVersion == "machines" {
+		return watchMachines(ctx, app, time.Duration(sleep)*time.Second)
+	}
+
 	var buf bytes.Buffer
 
 	for err == nil {
@@ -225,3 +302,65 @@ func runWatch(ctx context.Context) (err error) {
 
 	return
 }
+
+// watchMachines redraws the status of the machines of app every interval until
+// ctx is done, highlighting the machines whose state changed since the last
+// redraw. The app is fetched once, as only its machines change meanwhile.
+func watchMachines(ctx context.Context, app *api.AppCompact, interval time.Duration) error {
+	var (
+		streams  = iostreams.FromContext(ctx)
+		colorize = streams.ColorScheme()
+		buf      bytes.Buffer
+		previous map[string]string
+	)
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	// the status renders into buf so that the screen is only cleared once
+	// there's a new status to replace it with
+	buffered := *streams
+	buffered.Out, buffered.ErrOut = &buf, &buf
+	bufferedCtx := iostreams.NewContext(ctx, &buffered)
+
+	for {
+		machines, err := listMachines(ctx, flapsClient)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		states := make(map[string]string, len(machines))
+		changed := map[string]bool{}
+		for _, m := range machines {
+			states[m.ID] = m.State
+
+			// machines which appeared since count as changed too
+			if state, ok := previous[m.ID]; previous != nil && (!ok || state != m.State) {
+				changed[m.ID] = true
+			}
+		}
+		previous = states
+
+		buf.Reset()
+		if err := renderMachines(bufferedCtx, app, machines, changed); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		screen.Clear()
+		screen.MoveTopLeft()
+
+		fmt.Fprintf(streams.Out, "%s %s %s\n\n", colorize.Bold(app.Name), "at:", colorize.Bold(time.Now().UTC().Format("15:04:05")))
+		io.Copy(streams.Out, &buf)
+
+		pause.For(ctx, interval)
+	}
+}
@@ -0,0 +1,68 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// autostopEnabled reports whether any of the services of any of machines
+// stops or starts machines on demand.
+func autostopEnabled(machines []*api.Machine) bool {
+	for _, m := range machines {
+		if m.Config == nil {
+			continue
+		}
+
+		for _, service := range m.Config.Services {
+			if (service.Autostop != nil && *service.Autostop) || (service.Autostart != nil && *service.Autostart) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// lastStopReason classifies the most recent event which stopped m, or returns
+// an empty string when there's none.
+func lastStopReason(m *api.Machine) string {
+	var last *api.MachineEvent
+	for _, event := range m.Events {
+		if event.Type != "stop" && event.Type != "exit" {
+			continue
+		}
+		if last == nil || event.Timestamp > last.Timestamp {
+			last = event
+		}
+	}
+
+	if last == nil {
+		return ""
+	}
+
+	if strings.Contains(last.Source, "proxy") {
+		return "proxy (autostop)"
+	}
+
+	var exit *api.MachineExitEvent
+	if last.Request != nil {
+		exit = last.Request.ExitEvent
+	}
+
+	switch {
+	case exit == nil && last.Source == "user":
+		return "user"
+	case exit == nil:
+		return last.Source
+	case exit.OOMKilled:
+		return "crash (out of memory)"
+	case exit.RequestedStop:
+		return "requested"
+	case exit.ExitCode != 0:
+		return fmt.Sprintf("crash (exit code %d)", exit.ExitCode)
+	default:
+		return "exited"
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/command/apps"
+	"github.com/superfly/flyctl/internal/flag"
 )
 
 // TODO: deprecate & remove
@@ -20,7 +21,11 @@ for details on restarting it.
 	)
 
 	suspend := command.New(usage, short, long, apps.RunSuspend,
-		command.RequireSession)
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	flag.Add(suspend, apps.SuspendFlags)
 
 	return suspend
 }
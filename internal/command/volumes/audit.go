@@ -0,0 +1,154 @@
+package volumes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+)
+
+func newAudit() *cobra.Command {
+	const (
+		long = `List the volumes of the app, or of all apps of the organization given
+via --org, which aren't encrypted at rest.`
+
+		short = "List unencrypted volumes"
+	)
+
+	cmd := command.New("audit", short, long, runAudit,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Org(),
+	)
+
+	return cmd
+}
+
+// auditedVolume is an unencrypted volume, along with the app it belongs to.
+type auditedVolume struct {
+	App    string     `json:"app"`
+	Volume api.Volume `json:"volume"`
+}
+
+func runAudit(ctx context.Context) error {
+	var (
+		cfg    = config.FromContext(ctx)
+		out    = iostreams.FromContext(ctx).Out
+		client = client.FromContext(ctx).API()
+		org    = flag.GetOrg(ctx)
+	)
+
+	appNames, err := auditedApps(ctx, org)
+	if err != nil {
+		return err
+	}
+
+	var (
+		checked     int
+		unencrypted []auditedVolume
+	)
+	for _, appName := range appNames {
+		volumes, err := client.GetVolumes(ctx, appName)
+		if err != nil {
+			return fmt.Errorf("failed retrieving volumes of %s: %w", appName, err)
+		}
+
+		checked += len(volumes)
+		for _, volume := range volumes {
+			if !volume.Encrypted {
+				unencrypted = append(unencrypted, auditedVolume{App: appName, Volume: volume})
+			}
+		}
+	}
+
+	if cfg.JSONOutput {
+		return render.JSON(out, struct {
+			Checked     int             `json:"checked"`
+			Unencrypted []auditedVolume `json:"unencrypted"`
+		}{checked, unencrypted})
+	}
+
+	if len(unencrypted) == 0 {
+		fmt.Fprintf(out, "All %d volumes are encrypted\n", checked)
+
+		return nil
+	}
+
+	rows := make([][]string, 0, len(unencrypted))
+	for _, v := range unencrypted {
+		var attachedVMID string
+		if v.Volume.AttachedMachine != nil {
+			attachedVMID = v.Volume.AttachedMachine.ID
+		} else if v.Volume.AttachedAllocation != nil {
+			attachedVMID = v.Volume.AttachedAllocation.IDShort
+		}
+
+		rows = append(rows, []string{
+			v.App,
+			v.Volume.ID,
+			v.Volume.Name,
+			strconv.FormatUint(uint64(v.Volume.SizeGb)<<30, 10),
+			v.Volume.Region,
+			attachedVMID,
+		})
+	}
+
+	fmt.Fprintf(out, "%d of %d volumes are not encrypted:\n", len(unencrypted), checked)
+
+	return render.TableWithColumns(out, "", rows,
+		render.Col("App"),
+		render.Col("ID"),
+		render.Col("Name"),
+		render.BytesCol("Size"),
+		render.Col("Region"),
+		render.Col("Attached VM"),
+	)
+}
+
+// auditedApps returns the names of the apps of org, or the app of the
+// context when no org is given.
+func auditedApps(ctx context.Context, org string) ([]string, error) {
+	if org == "" {
+		appName := app.NameFromContext(ctx)
+		if appName == "" {
+			return nil, errors.New("specify the app to audit via --app or fly.toml, or an organization via --org")
+		}
+
+		return []string{appName}, nil
+	}
+
+	apps, err := client.FromContext(ctx).API().GetApps(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving apps: %w", err)
+	}
+
+	var names []string
+	for _, a := range apps {
+		if a.Organization.Slug == org {
+			names = append(names, a.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
@@ -0,0 +1,321 @@
+package volumes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/flaps"
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/internal/app"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/render"
+)
+
+func newCloneToRegion() *cobra.Command {
+	const (
+		long = `Clone a volume into another region, by restoring a snapshot of it there.
+A fresh snapshot of the volume is taken unless --snapshot names an existing one.
+Whatever the volume stored after the snapshot was taken isn't carried over.`
+
+		short = "Clone a volume into another region"
+
+		usage = "clone-to-region <id>"
+	)
+
+	cmd := command.New(usage, short, long, runCloneToRegion,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Region(),
+		flag.String{
+			Name:        "snapshot",
+			Description: "ID of the snapshot of the volume to restore, instead of taking a new one",
+		},
+		flag.String{
+			Name:        "attach-to",
+			Description: "Machine in the destination region the new volume is meant for, as MACHINE_ID[:PATH]. It's validated up front and the machine update attaching the volume is printed. PATH defaults to where the volume is mounted now",
+		},
+		flag.Bool{
+			Name:        "require-unique-zone",
+			Description: "Require the new volume to be placed in a separate hardware zone from existing volumes",
+			Default:     true,
+		},
+	)
+
+	return cmd
+}
+
+const (
+	// snapshotTimeout bounds the wait for a new snapshot to complete.
+	snapshotTimeout = 10 * time.Minute
+
+	// restoreTimeout bounds the wait for a restored volume to become
+	// available.
+	restoreTimeout = 10 * time.Minute
+)
+
+func runCloneToRegion(ctx context.Context) error {
+	var (
+		cfg       = config.FromContext(ctx)
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+
+		volID   = flag.FirstArg(ctx)
+		appName = app.NameFromContext(ctx)
+		region  = cfg.Region
+	)
+
+	if region == "" {
+		return fmt.Errorf("the destination region must be given with --region")
+	}
+
+	source, err := apiClient.GetVolume(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volume %s: %w", volID, err)
+	}
+
+	if source.Region == region {
+		return fmt.Errorf("volume %s is already in region %s; use `fly volumes create --snapshot-id` to restore it there", volID, region)
+	}
+
+	appCompact, err := apiClient.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	var target *attachTarget
+	if val := flag.GetString(ctx, "attach-to"); val != "" {
+		if target, err = resolveAttachTarget(ctx, appCompact, source, val, region); err != nil {
+			return err
+		}
+	}
+
+	snapshot, err := cloneSnapshot(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	input := api.CreateVolumeInput{
+		AppID:             appCompact.ID,
+		Name:              source.Name,
+		Region:            region,
+		SizeGb:            source.SizeGb,
+		Encrypted:         source.Encrypted,
+		RequireUniqueZone: flag.GetBool(ctx, "require-unique-zone"),
+		SnapshotID:        api.StringPointer(snapshot.ID),
+	}
+
+	fmt.Fprintf(io.ErrOut, "Restoring snapshot %s of volume %s into region %s\n", snapshot.ID, volID, region)
+
+	volume, err := apiClient.CreateVolume(ctx, input)
+	if err != nil {
+		if flyerr.GetCode(err) == flyerr.CodeValidation {
+			return flyerr.WithCode(flyerr.CodeValidation, fmt.Errorf(
+				"restoring volume %s from region %s into region %s was rejected; cross-region restore may not be supported for the plan of organization %s: %w",
+				volID, source.Region, region, appCompact.Organization.Slug, err))
+		}
+
+		return fmt.Errorf("failed restoring snapshot %s into region %s: %w", snapshot.ID, region, err)
+	}
+
+	if volume, err = waitForVolume(ctx, volume.ID); err != nil {
+		return err
+	}
+
+	if cfg.JSONOutput {
+		return render.JSON(io.Out, volume)
+	}
+
+	if err := printVolume(io.Out, volume); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.ErrOut, "\nVolume %s holds the data of volume %s as of %s; whatever was written after isn't included.\n",
+		volume.ID, volID, snapshot.CreatedAt.Format(time.RFC822))
+
+	if target != nil {
+		update := fmt.Sprintf("fly machine update %s --app %s --attach-volume %s:%s --stop-and-swap", target.Machine.ID, appName, volume.ID, target.Path)
+		if target.Replaces != "" {
+			update += " --detach-volume " + target.Replaces
+		}
+
+		fmt.Fprintf(io.ErrOut, "Attach it to machine %s with:\n  %s\n", target.Machine.ID, update)
+	}
+
+	return nil
+}
+
+// attachTarget is the machine --attach-to names, along with the path to mount
+// the new volume at and the volume mounted there now, if any.
+type attachTarget struct {
+	Machine  *api.Machine
+	Path     string
+	Replaces string
+}
+
+// resolveAttachTarget validates the MACHINE_ID[:PATH] value of --attach-to
+// before any snapshot is taken or restored.
+func resolveAttachTarget(ctx context.Context, app *api.AppCompact, source *api.Volume, val, region string) (*attachTarget, error) {
+	id, path, _ := strings.Cut(val, ":")
+
+	flapsClient, err := flaps.New(ctx, app)
+	if err != nil {
+		return nil, fmt.Errorf("could not create flaps client: %w", err)
+	}
+
+	machine, err := flapsClient.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving machine %s: %w", id, err)
+	}
+
+	if machine.Region != region {
+		return nil, fmt.Errorf("machine %s is in region %s, not in the destination region %s", id, machine.Region, region)
+	}
+
+	if path == "" && source.AttachedMachine != nil {
+		if attached, err := flapsClient.Get(ctx, source.AttachedMachine.ID); err == nil && attached.Config != nil {
+			for _, m := range attached.Config.Mounts {
+				if m.Volume == source.ID {
+					path = m.Path
+				}
+			}
+		}
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("volume %s isn't mounted anywhere to take the path from; give it as --attach-to %s:PATH", source.ID, id)
+	}
+
+	target := &attachTarget{
+		Machine: machine,
+		Path:    path,
+	}
+
+	if machine.Config != nil {
+		for _, m := range machine.Config.Mounts {
+			if m.Path == path {
+				target.Replaces = m.Volume
+			}
+		}
+	}
+
+	return target, nil
+}
+
+// cloneSnapshot returns the snapshot of source --snapshot names or, lacking
+// it, takes a new one and waits for it to complete.
+func cloneSnapshot(ctx context.Context, source *api.Volume) (*api.Snapshot, error) {
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		id        = flag.GetString(ctx, "snapshot")
+	)
+
+	existing, err := apiClient.GetVolumeSnapshots(ctx, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving snapshots of volume %s: %w", source.ID, err)
+	}
+
+	if id != "" {
+		for i := range existing {
+			if existing[i].ID != id {
+				continue
+			}
+			if !snapshotComplete(existing[i]) {
+				return nil, fmt.Errorf("snapshot %s of volume %s is %s; only complete snapshots can be restored", id, source.ID, existing[i].Status)
+			}
+
+			return &existing[i], nil
+		}
+
+		return nil, fmt.Errorf("volume %s has no snapshot %s", source.ID, id)
+	}
+
+	seen := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		seen[s.ID] = true
+	}
+
+	fmt.Fprintf(io.ErrOut, "Taking a snapshot of volume %s\n", source.ID)
+
+	if err := apiClient.CreateVolumeSnapshot(ctx, source.ID); err != nil {
+		return nil, fmt.Errorf("failed snapshotting volume %s: %w", source.ID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+
+	for {
+		snapshots, err := apiClient.GetVolumeSnapshots(ctx, source.ID)
+		if err != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("failed retrieving snapshots of volume %s: %w", source.ID, err)
+		}
+
+		if snapshot := newCompleteSnapshot(snapshots, seen); snapshot != nil {
+			return snapshot, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("timed out waiting for the snapshot of volume %s: %w", source.ID, ctx.Err())
+		}
+
+		pause.For(ctx, 2*time.Second)
+	}
+}
+
+// snapshotComplete reports whether s has been taken in full, which is when
+// it can be restored.
+func snapshotComplete(s api.Snapshot) bool {
+	return s.Status == "complete"
+}
+
+// newCompleteSnapshot returns the first of snapshots that isn't in seen and
+// is complete, if any.
+func newCompleteSnapshot(snapshots []api.Snapshot, seen map[string]bool) *api.Snapshot {
+	for i := range snapshots {
+		if !seen[snapshots[i].ID] && snapshotComplete(snapshots[i]) {
+			return &snapshots[i]
+		}
+	}
+
+	return nil
+}
+
+// waitForVolume waits for the volume volID to be restored and returns it.
+func waitForVolume(ctx context.Context, volID string) (*api.Volume, error) {
+	apiClient := client.FromContext(ctx).API()
+
+	ctx, cancel := context.WithTimeout(ctx, restoreTimeout)
+	defer cancel()
+
+	for {
+		volume, err := apiClient.GetVolume(ctx, volID)
+		switch {
+		case err == nil && volume.State == "created":
+			return volume, nil
+		case err != nil && ctx.Err() == nil:
+			return nil, fmt.Errorf("failed retrieving volume %s: %w", volID, err)
+		case ctx.Err() != nil:
+			return nil, fmt.Errorf("timed out waiting for volume %s to be restored: %w", volID, ctx.Err())
+		}
+
+		pause.For(ctx, 2*time.Second)
+	}
+}
@@ -0,0 +1,46 @@
+package volumes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestNewCompleteSnapshot(t *testing.T) {
+	seen := map[string]bool{"vs_old": true}
+
+	cases := []struct {
+		name      string
+		snapshots []api.Snapshot
+		want      string
+	}{
+		{
+			name:      "nothing new",
+			snapshots: []api.Snapshot{{ID: "vs_old", Status: "complete"}},
+		},
+		{
+			name:      "new snapshot still being taken",
+			snapshots: []api.Snapshot{{ID: "vs_old", Status: "complete"}, {ID: "vs_new", Status: "running"}},
+		},
+		{
+			name:      "new snapshot complete",
+			snapshots: []api.Snapshot{{ID: "vs_old", Status: "complete"}, {ID: "vs_new", Status: "complete"}},
+			want:      "vs_new",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			snapshot := newCompleteSnapshot(tc.snapshots, seen)
+			if tc.want == "" {
+				assert.Nil(t, snapshot)
+				return
+			}
+			if assert.NotNil(t, snapshot) {
+				assert.Equal(t, tc.want, snapshot.ID)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package volumes
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"github.com/spf13/cobra"
 
@@ -48,7 +49,7 @@ sets the size as the number of gigabytes the volume will consume.`
 		},
 		flag.Bool{
 			Name:        "no-encryption",
-			Description: "Do not encrypt the volume contents",
+			Description: "Do not encrypt the volume contents. Volumes are encrypted at rest unless this is given",
 			Default:     false,
 		},
 		flag.Bool{
@@ -74,11 +75,18 @@ func runCreate(ctx context.Context) error {
 		appName    = app.NameFromContext(ctx)
 	)
 
-	appID, err := client.GetAppID(ctx, appName)
+	appCompact, err := client.GetAppCompact(ctx, appName)
 	if err != nil {
 		return err
 	}
 
+	// volumes are billed to the organization of their app
+	org, err := client.GetOrganizationBySlug(ctx, appCompact.Organization.Slug)
+	if err != nil {
+		return err
+	}
+	prompt.AnnounceOrg(ctx, org)
+
 	var region *api.Region
 
 	if region, err = prompt.Region(ctx, prompt.RegionParams{
@@ -93,7 +101,7 @@ func runCreate(ctx context.Context) error {
 	}
 
 	input := api.CreateVolumeInput{
-		AppID:             appID,
+		AppID:             appCompact.ID,
 		Name:              volumeName,
 		Region:            region.Code,
 		SizeGb:            flag.GetInt(ctx, "size"),
@@ -102,8 +110,19 @@ func runCreate(ctx context.Context) error {
 		SnapshotID:        snapshotID,
 	}
 
+	if !input.Encrypted {
+		io := iostreams.FromContext(ctx)
+		fmt.Fprintln(io.ErrOut, io.ColorScheme().Yellow("WARNING: the volume won't be encrypted at rest, and will show up in `fly volumes audit`"))
+	}
+
 	volume, err := client.CreateVolume(ctx, input)
 	if err != nil {
+		if input.RequireUniqueZone && isZoneCapacityError(err) {
+			if n := countVolumes(ctx, appName, volumeName, region.Code); n > 0 {
+				return fmt.Errorf("failed creating volume: no hardware zone in region %s is free of the app's %d other volumes named %s; create it in another region or pass --require-unique-zone=false: %w", region.Code, n, volumeName, err)
+			}
+		}
+
 		return fmt.Errorf("failed creating volume: %w", err)
 	}
 
@@ -115,3 +134,28 @@ func runCreate(ctx context.Context) error {
 
 	return printVolume(out, volume)
 }
+
+// zoneCapacityError matches the errors the API fails volumes with when no
+// hardware zone of the region can hold them, as opposed to any other error.
+var zoneCapacityError = regexp.MustCompile(`(?i)\b(unique|available|free)\b.*\bzones?\b|\bzones?\b.*\b(unavailable|capacity|full)\b`)
+
+func isZoneCapacityError(err error) bool {
+	return zoneCapacityError.MatchString(err.Error())
+}
+
+// countVolumes returns the number of volumes of appName named name in region,
+// or 0 in case they can't be listed.
+func countVolumes(ctx context.Context, appName, name, region string) (n int) {
+	volumes, err := client.FromContext(ctx).API().GetVolumes(ctx, appName)
+	if err != nil {
+		return 0
+	}
+
+	for _, v := range volumes {
+		if v.Name == name && v.Region == region {
+			n++
+		}
+	}
+
+	return
+}
@@ -3,6 +3,7 @@ package volumes
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -14,10 +15,17 @@ import (
 	"github.com/superfly/flyctl/internal/prompt"
 )
 
+// volumes without a snapshot taken within this window require typing their ID
+// to confirm deletion.
+const recentSnapshotWindow = 24 * time.Hour
+
 func newDelete() *cobra.Command {
 	const (
 		long = `Delete a volume Requires the volume's ID
-number to operate. This can be found through the volumes list command`
+number to operate. This can be found through the volumes list command.
+
+Volumes which are attached or have no snapshot from the last 24 hours
+require typing the volume's ID to confirm.`
 
 		short = "Delete a volume from the app"
 	)
@@ -26,6 +34,7 @@ number to operate. This can be found through the volumes list command`
 		command.RequireSession,
 	)
 	cmd.Args = cobra.ExactArgs(1)
+	cmd.Aliases = []string{"destroy"}
 
 	flag.Add(cmd,
 		flag.Yes(),
@@ -42,11 +51,39 @@ func runDelete(ctx context.Context) error {
 		volID    = flag.FirstArg(ctx)
 	)
 
-	if !flag.GetYes(ctx) {
+	details, err := fetchVolumeDetails(ctx, volID)
+	if err != nil {
+		return err
+	}
+
+	attached := details.AttachedTo()
+	risky := attached != "" || !hasRecentSnapshot(details)
+
+	switch {
+	case flag.GetYes(ctx):
+		if risky {
+			fmt.Fprintf(io.ErrOut, "%s Deleting volume %s %s\n", colorize.WarningIcon(), volID, describeRisk(details))
+		}
+	case risky:
+		fmt.Fprintln(io.ErrOut, colorize.Red("Deleting a volume is not reversible."))
+		fmt.Fprintf(io.ErrOut, "Volume %s %s.\n", volID, describeRisk(details))
+
+		var typed string
+		switch err := prompt.String(ctx, &typed, "Type the volume ID to confirm:", "", true); {
+		case err == nil:
+			if typed != volID {
+				return fmt.Errorf("%q does not match volume ID %s; not deleting", typed, volID)
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	default:
 		const msg = "Deleting a volume is not reversible."
 		fmt.Fprintln(io.ErrOut, colorize.Red(msg))
 
-		switch confirmed, err := prompt.Confirm(ctx, "Are you sure you want to delete this volume?"); {
+		switch confirmed, err := prompt.Confirmf(ctx, "Are you sure you want to delete this volume? (last snapshot: %s)", details.SnapshotAge()); {
 		case err == nil:
 			if !confirmed {
 				return nil
@@ -67,3 +104,17 @@ func runDelete(ctx context.Context) error {
 
 	return nil
 }
+
+func hasRecentSnapshot(d *volumeDetails) bool {
+	s := d.LatestSnapshot()
+
+	return s != nil && time.Since(s.CreatedAt) < recentSnapshotWindow
+}
+
+func describeRisk(d *volumeDetails) string {
+	if attached := d.AttachedTo(); attached != "" {
+		return fmt.Sprintf("is attached to %s (last snapshot: %s)", attached, d.SnapshotAge())
+	}
+
+	return fmt.Sprintf("has no snapshot from the last 24 hours (last snapshot: %s)", d.SnapshotAge())
+}
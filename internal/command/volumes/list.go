@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/iostreams"
@@ -15,6 +14,7 @@ import (
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/format"
 	"github.com/superfly/flyctl/internal/render"
 )
 
@@ -33,6 +33,7 @@ func newList() *cobra.Command {
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.Timestamps(),
 	)
 
 	return cmd
@@ -77,14 +78,26 @@ func runList(ctx context.Context) error {
 			volume.ID,
 			volume.State,
 			volume.Name,
-			strconv.Itoa(volume.SizeGb) + "GB",
+			strconv.FormatUint(uint64(volume.SizeGb)<<30, 10),
 			volume.Region,
 			volume.Host.ID,
 			fmt.Sprint(volume.Encrypted),
 			attachedVMID,
-			humanize.Time(volume.CreatedAt),
+			format.Time(volume.CreatedAt),
 		})
 	}
 
-	return render.Table(out, "", rows, "ID", "State", "Name", "Size", "Region", "Zone", "Encrypted", "Attached VM", "Created At")
+	absolute := flag.GetAbsoluteTimestamps(ctx)
+
+	return render.TableWithColumns(out, "", rows,
+		render.Col("ID"),
+		render.Col("State"),
+		render.Col("Name"),
+		render.BytesCol("Size"),
+		render.Col("Region"),
+		render.Col("Zone"),
+		render.Col("Encrypted"),
+		render.Col("Attached VM"),
+		render.TimestampCol("Created At", absolute),
+	)
 }
@@ -2,14 +2,13 @@ package volumes
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/iostreams"
 
-	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/volumes/snapshots"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/render"
@@ -33,20 +32,23 @@ number to operate. This can be found through the volumes list command`
 
 func runShow(ctx context.Context) error {
 	cfg := config.FromContext(ctx)
-	client := client.FromContext(ctx).API()
 
 	volumeID := flag.FirstArg(ctx)
 
-	volume, err := client.GetVolume(ctx, volumeID)
+	details, err := fetchVolumeDetails(ctx, volumeID)
 	if err != nil {
-		return fmt.Errorf("failed retrieving volume: %w", err)
+		return err
+	}
+
+	if err := snapshots.LoadRetention(ctx, details.Volume); err != nil {
+		return err
 	}
 
 	out := iostreams.FromContext(ctx).Out
 
 	if cfg.JSONOutput {
-		return render.JSON(out, volume)
+		return render.JSON(out, details.Volume)
 	}
 
-	return printVolume(out, volume)
+	return printVolumeDetails(out, details)
 }
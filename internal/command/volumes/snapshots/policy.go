@@ -0,0 +1,125 @@
+package snapshots
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// DefaultRetentionDays is how long snapshots are kept for unless their
+// volume's policy says otherwise.
+const DefaultRetentionDays = 5
+
+// RetentionDays returns the number of days the snapshots of vol are kept for
+// and whether that's the default.
+func RetentionDays(vol *api.Volume) (days int, isDefault bool) {
+	if vol.SnapshotRetention == nil {
+		return DefaultRetentionDays, true
+	}
+
+	return *vol.SnapshotRetention, false
+}
+
+// LoadRetention looks up the snapshot retention of vol, where the API
+// reports it; elsewhere the default applies.
+func LoadRetention(ctx context.Context, vol *api.Volume) error {
+	client := client.FromContext(ctx).API()
+
+	switch supported, err := client.HasField(ctx, "Volume", "snapshotRetention"); {
+	case err != nil:
+		return fmt.Errorf("failed checking whether the API reports snapshot retention: %w", err)
+	case !supported:
+		return nil
+	}
+
+	retention, err := client.GetVolumeSnapshotRetention(ctx, vol.ID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving the snapshot retention of volume %s: %w", vol.ID, err)
+	}
+	vol.SnapshotRetention = retention
+
+	return nil
+}
+
+func newPolicy() *cobra.Command {
+	const (
+		long  = "Commands for managing the snapshot retention policy of a volume"
+		short = "Manage snapshot retention"
+		usage = "policy"
+	)
+
+	cmd := command.New(usage, short, long, nil,
+		command.RequireSession,
+	)
+
+	cmd.AddCommand(
+		newPolicyShow(),
+	)
+
+	return cmd
+}
+
+func newPolicyShow() *cobra.Command {
+	const (
+		long  = "Show how long the snapshots of the specified volume are kept for"
+		short = "Show snapshot retention"
+		usage = "show <volume-id>"
+	)
+
+	cmd := command.New(usage, short, long, runPolicyShow,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	return cmd
+}
+
+func runPolicyShow(ctx context.Context) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+		volID  = flag.FirstArg(ctx)
+	)
+
+	vol, err := client.GetVolume(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volume: %w", err)
+	}
+
+	if err := LoadRetention(ctx, vol); err != nil {
+		return err
+	}
+
+	return renderPolicy(ctx, io, vol)
+}
+
+func renderPolicy(ctx context.Context, io *iostreams.IOStreams, vol *api.Volume) error {
+	days, isDefault := RetentionDays(vol)
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, map[string]interface{}{
+			"volume_id":      vol.ID,
+			"retention_days": days,
+			"default":        isDefault,
+		})
+	}
+
+	retention := fmt.Sprintf("%d days", days)
+	if isDefault {
+		retention += " (default)"
+	}
+
+	rows := [][]string{{vol.ID, retention}}
+
+	return render.VerticalTable(io.Out, "Snapshot policy", rows, "Volume", "Retention")
+}
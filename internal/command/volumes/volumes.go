@@ -2,13 +2,17 @@ package volumes
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
 
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/command/volumes/snapshots"
@@ -33,6 +37,8 @@ func New() *cobra.Command {
 		newDelete(),
 		newExtend(),
 		newShow(),
+		newAudit(),
+		newCloneToRegion(),
 		snapshots.New(),
 	)
 
@@ -55,3 +61,82 @@ func printVolume(w io.Writer, vol *api.Volume) error {
 
 	return err
 }
+
+// volumeDetails wraps a volume along with its snapshots, sorted newest first.
+type volumeDetails struct {
+	Volume    *api.Volume
+	Snapshots []api.Snapshot
+}
+
+// fetchVolumeDetails fetches the volume identified by volID and its snapshots.
+func fetchVolumeDetails(ctx context.Context, volID string) (*volumeDetails, error) {
+	client := client.FromContext(ctx).API()
+
+	volume, err := client.GetVolume(ctx, volID)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving volume: %w", err)
+	}
+
+	snapshots, err := client.GetVolumeSnapshots(ctx, volID)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving snapshots: %w", err)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return &volumeDetails{
+		Volume:    volume,
+		Snapshots: snapshots,
+	}, nil
+}
+
+// AttachedTo returns the ID of the machine or allocation the volume is
+// attached to or an empty string.
+func (d *volumeDetails) AttachedTo() string {
+	switch {
+	case d.Volume.AttachedMachine != nil:
+		return d.Volume.AttachedMachine.ID
+	case d.Volume.AttachedAllocation != nil:
+		return d.Volume.AttachedAllocation.IDShort
+	default:
+		return ""
+	}
+}
+
+// LatestSnapshot returns the newest snapshot of the volume or nil.
+func (d *volumeDetails) LatestSnapshot() *api.Snapshot {
+	if len(d.Snapshots) == 0 {
+		return nil
+	}
+
+	return &d.Snapshots[0]
+}
+
+// SnapshotAge describes how long ago the newest snapshot was taken.
+func (d *volumeDetails) SnapshotAge() string {
+	if s := d.LatestSnapshot(); s != nil {
+		return humanize.Time(s.CreatedAt)
+	}
+
+	return "never"
+}
+
+func printVolumeDetails(w io.Writer, d *volumeDetails) error {
+	if err := printVolume(w, d.Volume); err != nil {
+		return err
+	}
+
+	attached := d.AttachedTo()
+	if attached == "" {
+		attached = "-"
+	}
+
+	days, _ := snapshots.RetentionDays(d.Volume)
+	retention := fmt.Sprintf("%d days", days)
+
+	_, err := fmt.Fprintf(w, "%10s: %s\n%10s: %s\n%10s: %s\n", "Attached", attached, "Snapshot", d.SnapshotAge(), "Retention", retention)
+
+	return err
+}
@@ -19,15 +19,17 @@ const (
 	AccessTokenEnvKey     = envKeyPrefix + "ACCESS_TOKEN"
 	AccessTokenFileKey    = "access_token"
 	WireGuardStateFileKey = "wire_guard_state"
+	DefaultOrgFileKey     = "default_org"
 	APITokenEnvKey        = envKeyPrefix + "API_TOKEN"
 	orgEnvKey             = envKeyPrefix + "ORG"
 	registryHostEnvKey    = envKeyPrefix + "REGISTRY_HOST"
 	organizationEnvKey    = envKeyPrefix + "ORGANIZATION"
 	regionEnvKey          = envKeyPrefix + "REGION"
 	verboseOutputEnvKey   = envKeyPrefix + "VERBOSE"
-	jsonOutputEnvKey      = envKeyPrefix + "JSON"
+	JSONOutputEnvKey      = envKeyPrefix + "JSON"
 	logGQLEnvKey          = envKeyPrefix + "LOG_GQL_ERRORS"
 	localOnlyEnvKey       = envKeyPrefix + "LOCAL_ONLY"
+	tunnelModeEnvKey      = envKeyPrefix + "TUNNEL_MODE"
 
 	defaultAPIBaseURL   = "https://api.fly.io"
 	defaultRegistryHost = "registry.fly.io"
@@ -57,6 +59,9 @@ type Config struct {
 	// Organization denotes the organizational slug the user has selected.
 	Organization string
 
+	// OrganizationSource denotes where Organization was set from, if it was.
+	OrganizationSource string
+
 	// Region denotes the region slug the user has selected.
 	Region string
 
@@ -65,8 +70,20 @@ type Config struct {
 
 	// AccessToken denotes the user's access token.
 	AccessToken string
+
+	// TunnelMode denotes how the user wants to reach private networks: through
+	// the agent, through in-process tunnels, or through the former, falling
+	// back to the latter.
+	TunnelMode string
 }
 
+// The sources of Organization, in increasing order of precedence.
+const (
+	OrgSourceFile = "config file"
+	OrgSourceEnv  = "environment"
+	OrgSourceFlag = "flag"
+)
+
 // New returns a new instance of Config populated with default values.
 func New() *Config {
 	return &Config{
@@ -90,15 +107,18 @@ func (cfg *Config) ApplyEnv() {
 	cfg.AccessToken = strings.TrimSpace(cfg.AccessToken)
 
 	cfg.VerboseOutput = env.IsTruthy(verboseOutputEnvKey) || cfg.VerboseOutput
-	cfg.JSONOutput = env.IsTruthy(jsonOutputEnvKey) || cfg.JSONOutput
+	cfg.JSONOutput = env.IsTruthy(JSONOutputEnvKey) || cfg.JSONOutput
 	cfg.LogGQLErrors = env.IsTruthy(logGQLEnvKey) || cfg.LogGQLErrors
 	cfg.LocalOnly = env.IsTruthy(localOnlyEnvKey) || cfg.LocalOnly
 
-	cfg.Organization = env.FirstOrDefault(cfg.Organization,
-		orgEnvKey, organizationEnvKey)
+	if env.IsSet(orgEnvKey, organizationEnvKey) {
+		cfg.Organization = env.First(orgEnvKey, organizationEnvKey)
+		cfg.OrganizationSource = OrgSourceEnv
+	}
 	cfg.Region = env.FirstOrDefault(cfg.Region, regionEnvKey)
 	cfg.RegistryHost = env.FirstOrDefault(cfg.RegistryHost, registryHostEnvKey)
 	cfg.APIBaseURL = env.FirstOrDefault(cfg.APIBaseURL, apiBaseURLEnvKey)
+	cfg.TunnelMode = env.FirstOrDefault(cfg.TunnelMode, tunnelModeEnvKey)
 }
 
 // ApplyFile sets the properties of cfg which may be set via configuration file
@@ -109,10 +129,16 @@ func (cfg *Config) ApplyFile(path string) (err error) {
 
 	var w struct {
 		AccessToken string `yaml:"access_token"`
+		DefaultOrg  string `yaml:"default_org"`
 	}
 
 	if err = unmarshal(path, &w); err == nil {
 		cfg.AccessToken = w.AccessToken
+
+		if w.DefaultOrg != "" {
+			cfg.Organization = w.DefaultOrg
+			cfg.OrganizationSource = OrgSourceFile
+		}
 	}
 
 	return
@@ -128,6 +154,7 @@ func (cfg *Config) ApplyFlags(fs *pflag.FlagSet) {
 		flag.AccessTokenName: &cfg.AccessToken,
 		flag.OrgName:         &cfg.Organization,
 		flag.RegionName:      &cfg.Region,
+		flag.TunnelModeName:  &cfg.TunnelMode,
 	})
 
 	applyBoolFlags(fs, map[string]*bool{
@@ -135,6 +162,10 @@ func (cfg *Config) ApplyFlags(fs *pflag.FlagSet) {
 		flag.JSONOutputName: &cfg.JSONOutput,
 		flag.LocalOnlyName:  &cfg.LocalOnly,
 	})
+
+	if fs.Changed(flag.OrgName) {
+		cfg.OrganizationSource = OrgSourceFlag
+	}
 }
 
 func applyStringFlags(fs *pflag.FlagSet, flags map[string]*string) {
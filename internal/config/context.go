@@ -14,3 +14,12 @@ func NewContext(ctx context.Context, cfg *Config) context.Context {
 func FromContext(ctx context.Context) *Config {
 	return ctx.Value(contextKey{}).(*Config)
 }
+
+// MaybeFromContext returns the Config ctx carries, if any.
+func MaybeFromContext(ctx context.Context) (cfg *Config) {
+	if v := ctx.Value(contextKey{}); v != nil {
+		cfg = v.(*Config)
+	}
+
+	return
+}
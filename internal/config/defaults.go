@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+)
+
+// DefaultsFileName denotes the name of the file holding the flag defaults of
+// app profiles.
+const DefaultsFileName = "defaults.yml"
+
+// defaultsFile is the layout of the defaults file.
+type defaultsFile struct {
+	Apps map[string]map[string]string `yaml:"apps"`
+}
+
+func readDefaults(path string) (f defaultsFile, err error) {
+	if err = unmarshal(path, &f); os.IsNotExist(err) {
+		err = nil
+	}
+
+	return
+}
+
+// AppDefaults returns the flag defaults of appName, keyed by flag name, as
+// stored in the defaults file found at path.
+func AppDefaults(path, appName string) (map[string]string, error) {
+	f, err := readDefaults(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Apps[appName], nil
+}
+
+// SetAppDefaults stores vals as flag defaults of appName in the defaults file
+// found at path, keeping the rest.
+func SetAppDefaults(path, appName string, vals map[string]string) error {
+	f, err := readDefaults(path)
+	if err != nil {
+		return err
+	}
+
+	if f.Apps == nil {
+		f.Apps = map[string]map[string]string{}
+	}
+	if f.Apps[appName] == nil {
+		f.Apps[appName] = map[string]string{}
+	}
+	for name, value := range vals {
+		f.Apps[appName][name] = value
+	}
+
+	return marshal(path, f)
+}
+
+// UnsetAppDefaults removes the flag defaults of appName named in names from
+// the defaults file found at path.
+func UnsetAppDefaults(path, appName string, names []string) error {
+	f, err := readDefaults(path)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		delete(f.Apps[appName], name)
+	}
+	if len(f.Apps[appName]) == 0 {
+		delete(f.Apps, appName)
+	}
+
+	return marshal(path, f)
+}
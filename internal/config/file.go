@@ -19,6 +19,14 @@ func SetAccessToken(path, token string) error {
 	})
 }
 
+// SetDefaultOrg sets the slug of the organization commands default to at the
+// configuration file found at path. An empty slug clears it.
+func SetDefaultOrg(path, slug string) error {
+	return set(path, map[string]interface{}{
+		DefaultOrgFileKey: slug,
+	})
+}
+
 // Clear clears the access token and wireguard-related keys of the configuration
 // file found at path.
 func Clear(path string) (err error) {
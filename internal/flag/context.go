@@ -54,6 +54,12 @@ func GetInt(ctx context.Context, name string) int {
 	}
 }
 
+// IsSpecified returns whether the named flag was explicitly set on the
+// command line.
+func IsSpecified(ctx context.Context, name string) bool {
+	return FromContext(ctx).Changed(name)
+}
+
 // GetString returns the value of the named string flag ctx carries. It panics
 // in case ctx carries no flags or in case the named flag isn't a string one.
 func GetStringSlice(ctx context.Context, name string) []string {
@@ -0,0 +1,94 @@
+package flag
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// IgnoreDefaultsName denotes the name of the flag bypassing the flag defaults
+// of the app profile.
+const IgnoreDefaultsName = "ignore-defaults"
+
+// defaultsEnvKeys maps flags to the environment variables which also set
+// them; these take precedence over profile defaults.
+var defaultsEnvKeys = map[string][]string{
+	RegionName: {"FLY_REGION"},
+	OrgName:    {"FLY_ORG", "FLY_ORGANIZATION"},
+}
+
+// defaultable are the flags profile defaults may apply to: those tuning where
+// and how commands run. Anything else, such as flags confirming destructive
+// operations or selecting what the profile applies to, can't have a default.
+var defaultable = map[string]bool{
+	RegionName:     true,
+	OrgName:        true,
+	"vm-size":      true,
+	"vm-gpu-kind":  true,
+	"vm-gpus":      true,
+	"cpus":         true,
+	"memory":       true,
+	"strategy":     true,
+	"wait-timeout": true,
+	DetachName:     true,
+	remoteOnlyName: true,
+	LocalOnlyName:  true,
+	dockerfileName: true,
+	ignorefileName: true,
+	TunnelModeName: true,
+	timestampsName: true,
+	"build-target": true,
+	"image-label":  true,
+	"nixpacks":     true,
+}
+
+// CanDefault reports whether name is a flag profile defaults may apply to.
+func CanDefault(name string) bool {
+	return defaultable[name]
+}
+
+// ApplyDefaults sets the flags of fs named in defaults to their default
+// values, unless they were given on the command line or via the environment.
+// It returns the names of the flags it set, sorted.
+func ApplyDefaults(fs *pflag.FlagSet, defaults map[string]string) (applied []string, err error) {
+	for name, value := range defaults {
+		f := fs.Lookup(name)
+		if f == nil || f.Changed || !CanDefault(name) || setInEnv(name) {
+			continue
+		}
+
+		if err = fs.Set(name, value); err != nil {
+			return nil, err
+		}
+		applied = append(applied, name)
+	}
+
+	sort.Strings(applied)
+
+	return
+}
+
+// AnnotateDefaults appends to the usage of the flags of fs named in defaults
+// a note of the default from the profile of appName, for help output.
+func AnnotateDefaults(fs *pflag.FlagSet, appName string, defaults map[string]string) {
+	for name, value := range defaults {
+		f := fs.Lookup(name)
+		if f == nil || !CanDefault(name) || strings.Contains(f.Usage, "(profile default") {
+			continue
+		}
+
+		f.Usage += " (profile default for " + appName + ": " + value + ")"
+	}
+}
+
+func setInEnv(name string) bool {
+	for _, key := range defaultsEnvKeys[name] {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package flag
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String(RegionName, "", "")
+	fs.String(OrgName, "", "")
+	fs.String("vm-size", "", "")
+	fs.Bool(YesName, false, "")
+	fs.Bool("force", false, "")
+	require.NoError(t, fs.Parse([]string{"--vm-size", "performance-1x"}))
+
+	t.Setenv("FLY_ORG", "personal")
+
+	applied, err := ApplyDefaults(fs, map[string]string{
+		RegionName: "fra",
+		OrgName:    "other",
+		"vm-size":  "shared-cpu-2x",
+		YesName:    "true",
+		"force":    "true",
+		"missing":  "value",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{RegionName}, applied)
+	assert.Equal(t, "fra", fs.Lookup(RegionName).Value.String())
+	assert.Equal(t, "", fs.Lookup(OrgName).Value.String())
+	assert.Equal(t, "performance-1x", fs.Lookup("vm-size").Value.String())
+	assert.Equal(t, "false", fs.Lookup(YesName).Value.String())
+	assert.Equal(t, "false", fs.Lookup("force").Value.String())
+}
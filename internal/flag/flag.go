@@ -49,6 +49,9 @@ const (
 
 	// DetachName denotes the name of the detach flag.
 	DetachName = "detach"
+
+	// TunnelModeName denotes the name of the tunnel mode flag.
+	TunnelModeName = "tunnel-mode"
 )
 
 // Flag wraps the set of flags.
@@ -102,6 +105,8 @@ type String struct {
 	ConfName    string
 	EnvName     string
 	Hidden      bool
+	// NoOptDefVal is the value of the flag when it's passed without one.
+	NoOptDefVal string
 }
 
 func (s String) addTo(cmd *cobra.Command) {
@@ -115,6 +120,7 @@ func (s String) addTo(cmd *cobra.Command) {
 
 	f := flags.Lookup(s.Name)
 	f.Hidden = s.Hidden
+	f.NoOptDefVal = s.NoOptDefVal
 }
 
 // Int wraps the set of int flags.
@@ -204,7 +210,7 @@ func AppConfig() String {
 	return String{
 		Name:        AppConfigFilePathName,
 		Shorthand:   "c",
-		Description: "Path to application configuration file",
+		Description: "Path to application configuration file, an https:// URL to fetch it from, or - to read it from stdin",
 	}
 }
 
@@ -356,6 +362,13 @@ func BuildTarget() String {
 	}
 }
 
+func CacheFrom() StringSlice {
+	return StringSlice{
+		Name:        "cache-from",
+		Description: "Image reference to use as a build cache source, e.g. a previous deployment's image. Can be specified multiple times.",
+	}
+}
+
 func Nixpacks() Bool {
 	return Bool{
 		Name:        "nixpacks",
@@ -370,3 +383,20 @@ func Strategy() String {
 		Description: "The strategy for replacing running instances. Options are canary, rolling, bluegreen, or immediate. Default is canary, or rolling when max-per-region is set.",
 	}
 }
+
+const timestampsName = "timestamps"
+
+// Timestamps returns a string flag selecting how timestamps are displayed
+func Timestamps() String {
+	return String{
+		Name:        timestampsName,
+		Default:     "relative",
+		Description: "How to display timestamps: relative (e.g. 3h ago) or absolute",
+	}
+}
+
+// GetAbsoluteTimestamps reports whether the timestamps flag asks for absolute
+// timestamps
+func GetAbsoluteTimestamps(ctx context.Context) bool {
+	return GetString(ctx, timestampsName) == "absolute"
+}
@@ -0,0 +1,154 @@
+package flyerr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/graphql"
+)
+
+// Code classifies errors for scripts consuming the JSON output of failures.
+type Code string
+
+const (
+	CodeNotFound            Code = "not_found"
+	CodeUnauthorized        Code = "unauthorized"
+	CodeValidation          Code = "validation"
+	CodeConflict            Code = "conflict" // e.g. a lease is held on the machine
+	CodePlatformUnsupported Code = "platform_unsupported"
+	CodeAPIUnavailable      Code = "api_unavailable"
+	CodeTimeout             Code = "timeout"
+	CodeUnknown             Code = "unknown"
+)
+
+// CodedError is an error explicitly classified with a Code.
+type CodedError struct {
+	Err  error
+	Code Code
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// WithCode classifies err with code.
+func WithCode(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &CodedError{Err: err, Code: code}
+}
+
+// statusError is implemented by errors carrying the HTTP status of the
+// response of a failed request, like those of the Machines API.
+type statusError interface {
+	error
+	StatusCode() int
+}
+
+// requestIDError is implemented by errors carrying the ID the API assigned to
+// a failed request.
+type requestIDError interface {
+	error
+	RequestID() string
+}
+
+// GetCode returns the Code of err. Errors explicitly classified with WithCode
+// take precedence over those inferred from API errors.
+func GetCode(err error) Code {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return CodeTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return CodeTimeout
+		}
+
+		return CodeAPIUnavailable
+	}
+
+	if errors.Is(err, api.ErrNotFound) {
+		return CodeNotFound
+	}
+
+	var apiErr *api.ApiError
+	if errors.As(err, &apiErr) {
+		return codeFromStatus(apiErr.Status)
+	}
+
+	var statusErr statusError
+	if errors.As(err, &statusErr) {
+		return codeFromStatus(statusErr.StatusCode())
+	}
+
+	var gqlErr *graphql.GraphQLError
+	if errors.As(err, &gqlErr) {
+		if graphql.IsClientError(gqlErr) {
+			return CodeAPIUnavailable
+		}
+
+		return codeFromGraphQL(gqlErr.Extensions.Code)
+	}
+
+	return CodeUnknown
+}
+
+func codeFromStatus(status int) Code {
+	switch {
+	case status == 404:
+		return CodeNotFound
+	case status == 401 || status == 403:
+		return CodeUnauthorized
+	case status == 409 || status == 412:
+		return CodeConflict
+	case status == 400 || status == 422:
+		return CodeValidation
+	case status == 408 || status == 504:
+		return CodeTimeout
+	case status >= 500:
+		return CodeAPIUnavailable
+	default:
+		return CodeUnknown
+	}
+}
+
+func codeFromGraphQL(code string) Code {
+	switch code {
+	case "NOT_FOUND":
+		return CodeNotFound
+	case "UNAUTHORIZED", "UNAUTHENTICATED", "FORBIDDEN":
+		return CodeUnauthorized
+	case "BAD_USER_INPUT", "VALIDATION_ERROR", "UNPROCESSABLE":
+		return CodeValidation
+	case "CONFLICT":
+		return CodeConflict
+	default:
+		return CodeUnknown
+	}
+}
+
+// GetRequestIDs returns the request IDs of the API errors in the chain of err.
+func GetRequestIDs(err error) (ids []string) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if reqErr, ok := err.(requestIDError); ok && reqErr.RequestID() != "" {
+			ids = append(ids, reqErr.RequestID())
+		}
+	}
+
+	return
+}
@@ -0,0 +1,47 @@
+package flyerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/superfly/flyctl/api"
+)
+
+type testAPIError struct {
+	status int
+	id     string
+}
+
+func (e *testAPIError) Error() string     { return "request failed" }
+func (e *testAPIError) StatusCode() int   { return e.status }
+func (e *testAPIError) RequestID() string { return e.id }
+
+func TestGetCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		code Code
+	}{
+		{errors.New("boom"), CodeUnknown},
+		{fmt.Errorf("wrapped: %w", &testAPIError{status: 404}), CodeNotFound},
+		{&testAPIError{status: 409}, CodeConflict},
+		{&testAPIError{status: 503}, CodeAPIUnavailable},
+		{&api.ApiError{Status: 401}, CodeUnauthorized},
+		{api.ErrNotFound, CodeNotFound},
+		{fmt.Errorf("waiting: %w", context.DeadlineExceeded), CodeTimeout},
+		{WithCode(CodeValidation, &testAPIError{status: 500}), CodeValidation},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.code, GetCode(c.err), c.err.Error())
+	}
+}
+
+func TestGetRequestIDs(t *testing.T) {
+	err := fmt.Errorf("failed updating machine: %w", &testAPIError{status: 409, id: "01GZ"})
+
+	assert.Equal(t, []string{"01GZ"}, GetRequestIDs(err))
+	assert.Empty(t, GetRequestIDs(errors.New("boom")))
+}
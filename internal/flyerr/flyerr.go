@@ -39,6 +39,29 @@ func GetErrorSuggestion(err error) string {
 	return ""
 }
 
+// ExitCodeError is an error for when the CLI should exit with a specific code
+type ExitCodeError struct {
+	Err  error
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// GetExitCode returns the exit code err requests, if any
+func GetExitCode(err error) (int, bool) {
+	var ferr *ExitCodeError
+	if errors.As(err, &ferr) {
+		return ferr.Code, true
+	}
+	return 0, false
+}
+
 func PrintCLIOutput(err error) {
 	if err == nil {
 		return
@@ -4,6 +4,7 @@ package format
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,8 +13,12 @@ import (
 )
 
 func RelativeTime(t time.Time) string {
-	if t.Before(time.Now()) {
-		dur := time.Since(t)
+	return relativeTime(t, time.Now())
+}
+
+func relativeTime(t, now time.Time) string {
+	if t.Before(now) {
+		dur := now.Sub(t)
 		if dur.Seconds() < 1 {
 			return "just now"
 		}
@@ -27,8 +32,12 @@ func RelativeTime(t time.Time) string {
 		if dur.Hours() < 24 {
 			return fmt.Sprintf("%dh%dm ago", int64(dur.Hours()), int64(math.Mod(dur.Minutes(), 60)))
 		}
+
+		if dur.Hours() < 24*30 {
+			return fmt.Sprintf("%dd%dh ago", int64(dur.Hours()/24), int64(math.Mod(dur.Hours(), 24)))
+		}
 	} else {
-		dur := time.Until(t)
+		dur := t.Sub(now)
 		if dur.Seconds() < 60 {
 			return fmt.Sprintf("%ds", int64(dur.Seconds()))
 		}
@@ -49,6 +58,27 @@ func Time(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
 
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+
+// Bytes formats n in binary (1024 based) units, e.g. 10737418240 as "10 GB".
+// The output is the same regardless of locale.
+func Bytes(n uint64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value, unit := float64(n), 0
+	for value >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+
+	s := strconv.FormatFloat(value, 'f', 1, 64)
+	s = strings.TrimSuffix(s, ".0")
+
+	return s + " " + byteUnits[unit]
+}
+
 func HealthChecksSummary(allocs ...*api.AllocationStatus) string {
 	var total, pass, crit, warn int
 
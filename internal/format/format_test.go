@@ -0,0 +1,47 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytes(t *testing.T) {
+	cases := map[uint64]string{
+		0:             "0 B",
+		1023:          "1023 B",
+		1024:          "1 KB",
+		1536:          "1.5 KB",
+		256 << 20:     "256 MB",
+		10737418240:   "10 GB",
+		3 << 40:       "3 TB",
+		1<<30 + 1<<29: "1.5 GB",
+	}
+
+	for n, exp := range cases {
+		assert.Equal(t, exp, Bytes(n), "Bytes(%d)", n)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2022, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		t   time.Time
+		exp string
+	}{
+		{now.Add(-100 * time.Millisecond), "just now"},
+		{now.Add(-42 * time.Second), "42s ago"},
+		{now.Add(-5*time.Minute - 3*time.Second), "5m3s ago"},
+		{now.Add(-3*time.Hour - 10*time.Minute), "3h10m ago"},
+		{now.Add(-50 * time.Hour), "2d2h ago"},
+		{now.Add(-60 * 24 * time.Hour), "2022-08-15T12:00:00Z"},
+		{now.Add(90 * time.Second), "1m30s"},
+		{now.Add(48 * time.Hour), "2022-10-16T12:00:00Z"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.exp, relativeTime(c.t, now))
+	}
+}
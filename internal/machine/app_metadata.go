@@ -0,0 +1,133 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flaps"
+)
+
+// AppMetadataKeyPrefix prefixes the keys of app metadata, which is about an
+// app rather than any one of its machines. The platform keeps metadata on
+// machines only, so app metadata is set on every machine of the app, outlives
+// deployments and is read from whichever machine carries it.
+const AppMetadataKeyPrefix = "fly_app_"
+
+// AppSuspendedMetadataKey is the app metadata recording when `apps suspend`
+// suspended an app and which machines it stopped, so that `apps resume`
+// starts exactly those again.
+const AppSuspendedMetadataKey = AppMetadataKeyPrefix + "suspended"
+
+// Suspension is what the AppSuspendedMetadataKey app metadata records.
+type Suspension struct {
+	At       time.Time `json:"at"`
+	Machines []string  `json:"machines"`
+}
+
+// AppSuspension returns the suspension the machines of an app carry, if any.
+func AppSuspension(machines []*api.Machine) (*Suspension, bool) {
+	value, ok := AppMetadata(machines, AppSuspendedMetadataKey)
+	if !ok {
+		return nil, false
+	}
+
+	var s Suspension
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return nil, false
+	}
+
+	return &s, true
+}
+
+// SuspendedAt returns the time the app the machines belong to was suspended
+// at by `apps suspend`, if it was.
+func SuspendedAt(machines []*api.Machine) (time.Time, bool) {
+	s, ok := AppSuspension(machines)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return s.At, true
+}
+
+// SetAppSuspension records s as the suspension of the app the machines
+// belong to, or clears it when s has no machines left to resume.
+func SetAppSuspension(ctx context.Context, machines []*api.Machine, s *Suspension) error {
+	if s == nil || len(s.Machines) == 0 {
+		return DeleteAppMetadata(ctx, machines, AppSuspendedMetadataKey)
+	}
+
+	value, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return SetAppMetadata(ctx, machines, AppSuspendedMetadataKey, string(value))
+}
+
+// IsAppMetadataKey reports whether key is that of app metadata.
+func IsAppMetadataKey(key string) bool {
+	return strings.HasPrefix(key, AppMetadataKeyPrefix)
+}
+
+// AppMetadata returns the value of the app metadata key carried by the
+// machines of an app, if any of them carries it.
+func AppMetadata(machines []*api.Machine, key string) (value string, ok bool) {
+	for _, m := range machines {
+		if m.Config == nil {
+			continue
+		}
+
+		if value, ok = m.Config.Metadata[key]; ok {
+			return
+		}
+	}
+
+	return "", false
+}
+
+// SetAppMetadata sets the app metadata key to value on each of the machines
+// of an app.
+func SetAppMetadata(ctx context.Context, machines []*api.Machine, key, value string) error {
+	flapsClient := flaps.FromContext(ctx)
+
+	for _, m := range machines {
+		if err := flapsClient.SetMetadata(ctx, m.ID, key, value); err != nil {
+			return err
+		}
+
+		if m.Config != nil {
+			if m.Config.Metadata == nil {
+				m.Config.Metadata = map[string]string{}
+			}
+			m.Config.Metadata[key] = value
+		}
+	}
+
+	return nil
+}
+
+// DeleteAppMetadata removes the app metadata key from each of the machines of
+// an app carrying it.
+func DeleteAppMetadata(ctx context.Context, machines []*api.Machine, key string) error {
+	flapsClient := flaps.FromContext(ctx)
+
+	for _, m := range machines {
+		if m.Config == nil {
+			continue
+		}
+		if _, ok := m.Config.Metadata[key]; !ok {
+			continue
+		}
+
+		if err := flapsClient.DeleteMetadata(ctx, m.ID, key); err != nil {
+			return err
+		}
+		delete(m.Config.Metadata, key)
+	}
+
+	return nil
+}
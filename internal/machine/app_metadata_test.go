@@ -0,0 +1,39 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestAppMetadata(t *testing.T) {
+	machines := []*api.Machine{
+		{ID: "a"},
+		{ID: "b", Config: &api.MachineConfig{}},
+		{ID: "c", Config: &api.MachineConfig{Metadata: map[string]string{AppSuspendedMetadataKey: "{}"}}},
+	}
+
+	value, ok := AppMetadata(machines, AppSuspendedMetadataKey)
+	assert.True(t, ok)
+	assert.Equal(t, "{}", value)
+
+	_, ok = AppMetadata(machines[:2], AppSuspendedMetadataKey)
+	assert.False(t, ok)
+}
+
+func TestPreserveMetadata(t *testing.T) {
+	src := &api.MachineConfig{Metadata: map[string]string{
+		AppSuspendedMetadataKey:            "{}",
+		api.MachineChecksPausedMetadataKey: "2026-01-01T00:00:00Z",
+		"process_group":                    "web",
+	}}
+	dst := &api.MachineConfig{}
+
+	PreserveMetadata(dst, src)
+	assert.Equal(t, map[string]string{
+		AppSuspendedMetadataKey:            "{}",
+		api.MachineChecksPausedMetadataKey: "2026-01-01T00:00:00Z",
+	}, dst.Metadata)
+}
@@ -5,14 +5,139 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/samber/lo"
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/internal/prompt"
 	"github.com/superfly/flyctl/iostreams"
 )
 
+// ScratchAppMetadataKey marks the machines of apps flyctl created on the fly
+// for a one-off `machine run`, so that such apps can be found later on.
+const ScratchAppMetadataKey = "fly_scratch_app"
+
+// StagedUpdateMetadataKey marks stopped machines whose config was updated
+// without starting them, so that `machine start --all-staged` may find them.
+const StagedUpdateMetadataKey = "fly_update_staged"
+
+// IsUpdateStaged reports whether m carries a staged update.
+func IsUpdateStaged(m *api.Machine) bool {
+	return m.Config != nil && m.Config.Metadata[StagedUpdateMetadataKey] == "true"
+}
+
+// OrphanedGroupMetadataKey marks the machines deployments kept although fly.toml
+// no longer has their process group, which it holds the name of.
+const OrphanedGroupMetadataKey = "fly_orphaned_group"
+
+// OrphanedGroup returns the process group m was kept running for after it was
+// removed from fly.toml, if any.
+func OrphanedGroup(m *api.Machine) (group string, ok bool) {
+	if m.Config == nil {
+		return "", false
+	}
+
+	group, ok = m.Config.Metadata[OrphanedGroupMetadataKey]
+
+	return
+}
+
+// PinnedReleaseMetadataKey marks machines `machine update --from-release`
+// pinned to the image of a release with the version of that release, so that
+// deployments leave them be until the pin is cleared.
+const PinnedReleaseMetadataKey = "fly_pinned_release"
+
+// ReleaseVersionMetadataKey holds the version of the release the deployment
+// which last updated the machine created, so that the config versions of the
+// machine may be traced back to releases. Manual updates clear it.
+const ReleaseVersionMetadataKey = "fly_release_version"
+
+// PinnedRelease returns the version of the release m is pinned to, if any.
+func PinnedRelease(m *api.Machine) (version string, ok bool) {
+	if m.Config == nil {
+		return "", false
+	}
+
+	version = m.Config.Metadata[PinnedReleaseMetadataKey]
+
+	return version, version != ""
+}
+
+// AutoscaleMinMetadataKey and AutoscaleMaxMetadataKey hold the number of
+// machines `autoscale set` keeps a region of a machines app between, on each
+// machine of that region.
+const (
+	AutoscaleMinMetadataKey = "fly_autoscale_min"
+	AutoscaleMaxMetadataKey = "fly_autoscale_max"
+)
+
+// AutoscalePolicy bounds the number of machines of a region.
+type AutoscalePolicy struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+func (p AutoscalePolicy) String() string {
+	return fmt.Sprintf("%d-%d", p.Min, p.Max)
+}
+
+// Autoscale returns the autoscale policy m carries, if any.
+func Autoscale(m *api.Machine) (policy AutoscalePolicy, ok bool) {
+	if m.Config == nil {
+		return policy, false
+	}
+
+	var err error
+	if policy.Min, err = strconv.Atoi(m.Config.Metadata[AutoscaleMinMetadataKey]); err != nil {
+		return policy, false
+	}
+	if policy.Max, err = strconv.Atoi(m.Config.Metadata[AutoscaleMaxMetadataKey]); err != nil {
+		return policy, false
+	}
+
+	return policy, true
+}
+
+// AutoscaleByRegion returns the autoscale policies of the regions of machines
+// which have one.
+func AutoscaleByRegion(machines []*api.Machine) map[string]AutoscalePolicy {
+	policies := map[string]AutoscalePolicy{}
+	for _, m := range machines {
+		if policy, ok := Autoscale(m); ok {
+			policies[m.Region] = policy
+		}
+	}
+
+	return policies
+}
+
+// persistentMetadataKeys are the keys of the metadata flyctl keeps on machines
+// across deployments.
+var persistentMetadataKeys = []string{
+	AutoscaleMinMetadataKey,
+	AutoscaleMaxMetadataKey,
+	api.MachineChecksPausedMetadataKey,
+	OrphanedGroupMetadataKey,
+}
+
+// PreserveMetadata carries the metadata of src which outlives deployments,
+// app metadata included, over to dst, so that replacing a machine's config
+// doesn't drop it.
+func PreserveMetadata(dst, src *api.MachineConfig) {
+	for key, value := range src.Metadata {
+		if !IsAppMetadataKey(key) && !lo.Contains(persistentMetadataKeys, key) {
+			continue
+		}
+
+		if dst.Metadata == nil {
+			dst.Metadata = map[string]string{}
+		}
+		dst.Metadata[key] = value
+	}
+}
+
 type ErrNoConfigChangesFound struct{}
 
 func (e *ErrNoConfigChangesFound) Error() string {
@@ -53,6 +178,33 @@ func ConfirmConfigChanges(ctx context.Context, machine *api.Machine, targetConfi
 	return true, nil
 }
 
+// RootfsSizeMB is the size of a machine's root filesystem, which swap is
+// carved out of.
+const RootfsSizeMB = 8 * 1024
+
+// ValidateSwapSize rejects swap sizes the machine couldn't fit on its disk.
+func ValidateSwapSize(mb int) error {
+	switch {
+	case mb < 0:
+		return fmt.Errorf("swap size must not be negative, got %d", mb)
+	case mb > RootfsSizeMB:
+		return fmt.Errorf("swap size of %dMB exceeds the machine's %dMB disk", mb, RootfsSizeMB)
+	}
+	return nil
+}
+
+// ValidateKernelArgs rejects kernel arguments which aren't in either the
+// key or key=value form.
+func ValidateKernelArgs(args []string) error {
+	for _, arg := range args {
+		key, _, _ := strings.Cut(arg, "=")
+		if key == "" || strings.ContainsAny(arg, " \t\n") {
+			return fmt.Errorf("invalid kernel argument %q; must be in the form key or key=value", arg)
+		}
+	}
+	return nil
+}
+
 func CloneConfig(orig api.MachineConfig) (*api.MachineConfig, error) {
 	config := &api.MachineConfig{}
 
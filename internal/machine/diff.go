@@ -0,0 +1,184 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// DiffSections are the parts of machine configs DiffConfigs compares, in the
+// order it reports them in.
+var DiffSections = []string{"image", "env", "services", "checks", "guest", "mounts", "metadata"}
+
+// volatileMetadataKeys hold what differs between machines deployed and run
+// alike, such as when they were last updated.
+var volatileMetadataKeys = []string{
+	ReleaseVersionMetadataKey,
+	StagedUpdateMetadataKey,
+}
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// ConfigChange is a difference between two machine configs at Path, such as
+// env.MODE or services[0].internal_port.
+type ConfigChange struct {
+	Section string      `json:"section"`
+	Path    string      `json:"path"`
+	Kind    string      `json:"kind"`
+	From    interface{} `json:"from,omitempty"`
+	To      interface{} `json:"to,omitempty"`
+}
+
+// DiffConfigs returns how config b differs from config a within
+// DiffSections, leaving out the ids of volumes and other fields which are
+// bound to differ between machines.
+func DiffConfigs(a, b api.MachineConfig) ([]ConfigChange, error) {
+	na, err := normalizeConfig(a)
+	if err != nil {
+		return nil, err
+	}
+	nb, err := normalizeConfig(b)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []ConfigChange{}
+	for _, section := range DiffSections {
+		var sectionChanges []ConfigChange
+		diffValues(section, na[section], nb[section], &sectionChanges)
+
+		for _, change := range sectionChanges {
+			change.Section = section
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+func normalizeConfig(config api.MachineConfig) (map[string]interface{}, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding machine config: %w", err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed decoding machine config: %w", err)
+	}
+
+	if metadata, ok := m["metadata"].(map[string]interface{}); ok {
+		for _, key := range volatileMetadataKeys {
+			delete(metadata, key)
+		}
+	}
+
+	if mounts, ok := m["mounts"].([]interface{}); ok {
+		for _, mount := range mounts {
+			if mount, ok := mount.(map[string]interface{}); ok {
+				delete(mount, "volume")
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func diffValues(path string, a, b interface{}, changes *[]ConfigChange) {
+	// absent and empty objects and lists are alike
+	if a == nil {
+		a = emptyLike(b)
+	}
+	if b == nil {
+		b = emptyLike(a)
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(av)+len(bv))
+			for k := range av {
+				keys = append(keys, k)
+			}
+			for k := range bv {
+				if _, ok := av[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				diffChild(path+"."+k, av, bv, k, changes)
+			}
+
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			for i := 0; i < len(av) || i < len(bv); i++ {
+				child := fmt.Sprintf("%s[%d]", path, i)
+
+				switch {
+				case i >= len(av):
+					*changes = append(*changes, ConfigChange{Path: child, Kind: ChangeAdded, To: bv[i]})
+				case i >= len(bv):
+					*changes = append(*changes, ConfigChange{Path: child, Kind: ChangeRemoved, From: av[i]})
+				default:
+					diffValues(child, av[i], bv[i], changes)
+				}
+			}
+
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, ConfigChange{Path: path, Kind: ChangeChanged, From: a, To: b})
+	}
+}
+
+func diffChild(path string, a, b map[string]interface{}, key string, changes *[]ConfigChange) {
+	av, aok := a[key]
+	bv, bok := b[key]
+
+	switch {
+	case !aok && !isEmpty(bv):
+		*changes = append(*changes, ConfigChange{Path: path, Kind: ChangeAdded, To: bv})
+	case !bok && !isEmpty(av):
+		*changes = append(*changes, ConfigChange{Path: path, Kind: ChangeRemoved, From: av})
+	case aok && bok:
+		diffValues(path, av, bv, changes)
+	}
+}
+
+func emptyLike(v interface{}) interface{} {
+	switch v.(type) {
+	case map[string]interface{}:
+		return map[string]interface{}{}
+	case []interface{}:
+		return []interface{}{}
+	}
+
+	return nil
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	return reflect.DeepEqual(v, emptyLike(v))
+}
+
+// RelativePath returns the path of the change within its section.
+func (c ConfigChange) RelativePath() string {
+	return strings.TrimPrefix(strings.TrimPrefix(c.Path, c.Section), ".")
+}
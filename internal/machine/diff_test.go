@@ -0,0 +1,59 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	a := api.MachineConfig{
+		Image: "app:v1",
+		Env:   map[string]string{"MODE": "a", "OLD": "x"},
+		Mounts: []api.MachineMount{
+			{Path: "/data", Volume: "vol_1"},
+		},
+		Metadata: map[string]string{
+			"process_group":           "app",
+			ReleaseVersionMetadataKey: "3",
+		},
+		Services: []api.MachineService{
+			{Protocol: "tcp", InternalPort: 8080},
+		},
+	}
+
+	b := a
+	b.Metadata = map[string]string{
+		"process_group":           "app",
+		ReleaseVersionMetadataKey: "4",
+	}
+	b.Mounts = []api.MachineMount{
+		{Path: "/data", Volume: "vol_2"},
+	}
+
+	// releases and volumes differ between machines deployed alike
+	changes, err := DiffConfigs(a, b)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	b.Image = "app:v2"
+	b.Env = map[string]string{"MODE": "b", "NEW": "y"}
+	b.Services = []api.MachineService{
+		{Protocol: "tcp", InternalPort: 9090},
+	}
+
+	changes, err = DiffConfigs(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigChange{
+		{Section: "image", Path: "image", Kind: ChangeChanged, From: "app:v1", To: "app:v2"},
+		{Section: "env", Path: "env.MODE", Kind: ChangeChanged, From: "a", To: "b"},
+		{Section: "env", Path: "env.NEW", Kind: ChangeAdded, To: "y"},
+		{Section: "env", Path: "env.OLD", Kind: ChangeRemoved, From: "x"},
+		{Section: "services", Path: "services[0].internal_port", Kind: ChangeChanged, From: float64(8080), To: float64(9090)},
+	}, changes)
+	assert.Equal(t, "MODE", changes[1].RelativePath())
+	assert.Equal(t, "[0].internal_port", changes[4].RelativePath())
+}
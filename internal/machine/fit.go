@@ -0,0 +1,172 @@
+package machine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// extraMemoryPriceMonthPerGB is the published price of each GB of memory a VM
+// is given beyond that of its size, which the API doesn't list.
+const (
+	extraMemoryPriceMonthPerGB  = 5.0
+	extraMemoryPriceSecondPerGB = extraMemoryPriceMonthPerGB / (30 * 24 * 3600)
+)
+
+// FitRequest is the guest a VM size is looked for.
+type FitRequest struct {
+	CPUs     float32
+	MemoryMB int
+}
+
+// VMFit is a size along with the memory to give it so it satisfies a
+// FitRequest, priced along with the memory it's given beyond that of the
+// size when Custom.
+type VMFit struct {
+	Size       string
+	CPUCores   float32
+	MemoryMB   int
+	Custom     bool
+	PriceMonth float32
+	PriceHour  float32
+}
+
+// FitVMSize returns the cheapest of sizes which, given as much memory as one
+// of its memory increments allows, satisfies req.
+func FitVMSize(sizes []api.VMSize, req FitRequest) (*VMFit, error) {
+	var fits []VMFit
+	for _, size := range sizes {
+		if size.CPUCores < req.CPUs {
+			continue
+		}
+
+		fit := VMFit{
+			Size:     size.Name,
+			CPUCores: size.CPUCores,
+			MemoryMB: size.MemoryMB,
+		}
+
+		if size.MemoryMB < req.MemoryMB {
+			increment, ok := memoryIncrement(size, req.MemoryMB)
+			if !ok {
+				continue
+			}
+			fit.MemoryMB, fit.Custom = increment, true
+		}
+
+		extraGB := float32(fit.MemoryMB-size.MemoryMB) / 1024
+		fit.PriceMonth = size.PriceMonth + extraGB*extraMemoryPriceMonthPerGB
+		fit.PriceHour = (size.PriceSecond + extraGB*extraMemoryPriceSecondPerGB) * 3600
+
+		fits = append(fits, fit)
+	}
+
+	if len(fits) == 0 {
+		return nil, fmt.Errorf("no VM size fits %s", req)
+	}
+
+	sort.SliceStable(fits, func(i, j int) bool {
+		switch a, b := fits[i], fits[j]; {
+		case a.PriceMonth != b.PriceMonth:
+			return a.PriceMonth < b.PriceMonth
+		case a.CPUCores != b.CPUCores:
+			return a.CPUCores < b.CPUCores
+		default:
+			return a.MemoryMB < b.MemoryMB
+		}
+	})
+
+	return &fits[0], nil
+}
+
+// memoryIncrement returns the smallest memory increment of size holding at
+// least mb.
+func memoryIncrement(size api.VMSize, mb int) (int, bool) {
+	best := 0
+	for _, increment := range size.MemoryIncrementsMB {
+		if increment >= mb && (best == 0 || increment < best) {
+			best = increment
+		}
+	}
+
+	return best, best != 0
+}
+
+func (r FitRequest) String() string {
+	var parts []string
+	if r.CPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%g CPUs", r.CPUs))
+	}
+	if r.MemoryMB > 0 {
+		parts = append(parts, fmt.Sprintf("%d MB of memory", r.MemoryMB))
+	}
+
+	return strings.Join(parts, " and ")
+}
+
+// SizeName returns the name of the VM size of guest, such as shared-cpu-1x.
+func SizeName(guest *api.MachineGuest) string {
+	if guest.CPUKind == "performance" {
+		return fmt.Sprintf("performance-%dx", guest.CPUs)
+	}
+
+	return fmt.Sprintf("%s-cpu-%dx", guest.CPUKind, guest.CPUs)
+}
+
+// GuestForSize returns the guest of the VM size named name given memoryMB of
+// memory. It returns false for the sizes machines can't be of.
+func GuestForSize(name string, memoryMB int) (*api.MachineGuest, bool) {
+	var (
+		kind = "shared"
+		cpus int
+	)
+
+	switch {
+	case strings.HasPrefix(name, "shared-cpu-"):
+		if _, err := fmt.Sscanf(name, "shared-cpu-%dx", &cpus); err != nil {
+			return nil, false
+		}
+	case strings.HasPrefix(name, "performance-"):
+		kind = "performance"
+		if _, err := fmt.Sscanf(name, "performance-%dx", &cpus); err != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	return &api.MachineGuest{CPUKind: kind, CPUs: cpus, MemoryMB: memoryMB}, true
+}
+
+// MachineSizes returns those of sizes machines can be of.
+func MachineSizes(sizes []api.VMSize) (ret []api.VMSize) {
+	for _, size := range sizes {
+		if _, ok := GuestForSize(size.Name, size.MemoryMB); ok {
+			ret = append(ret, size)
+		}
+	}
+
+	return
+}
+
+// GuestPrice returns the monthly price of guest, priced as its size along
+// with the memory it has beyond that of the size.
+func GuestPrice(sizes []api.VMSize, guest *api.MachineGuest) (float32, bool) {
+	name := SizeName(guest)
+	for _, size := range sizes {
+		if size.Name != name {
+			continue
+		}
+
+		extraGB := float32(guest.MemoryMB-size.MemoryMB) / 1024
+		if extraGB < 0 {
+			extraGB = 0
+		}
+
+		return size.PriceMonth + extraGB*extraMemoryPriceMonthPerGB, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,89 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// testCatalog is a fixed subset of the VM sizes the API lists.
+var testCatalog = []api.VMSize{
+	{Name: "shared-cpu-1x", CPUCores: 1, MemoryMB: 256, PriceMonth: 1.94, PriceSecond: 0.00000075, MemoryIncrementsMB: []int{256, 512, 1024, 2048}},
+	{Name: "dedicated-cpu-1x", CPUCores: 1, MemoryMB: 2048, PriceMonth: 31, PriceSecond: 0.0000120, MemoryIncrementsMB: []int{2048, 4096, 8192}},
+	{Name: "dedicated-cpu-2x", CPUCores: 2, MemoryMB: 4096, PriceMonth: 62, PriceSecond: 0.0000240, MemoryIncrementsMB: []int{4096, 8192, 16384}},
+	{Name: "dedicated-cpu-4x", CPUCores: 4, MemoryMB: 8192, PriceMonth: 124, PriceSecond: 0.0000480, MemoryIncrementsMB: []int{8192, 16384, 32768}},
+}
+
+func TestFitVMSize(t *testing.T) {
+	cases := []struct {
+		name   string
+		req    FitRequest
+		size   string
+		memory int
+		custom bool
+	}{
+		{name: "preset", req: FitRequest{CPUs: 2, MemoryMB: 4096}, size: "dedicated-cpu-2x", memory: 4096},
+		{name: "smallest", req: FitRequest{CPUs: 1}, size: "shared-cpu-1x", memory: 256},
+		{name: "extra memory is cheaper than a bigger size", req: FitRequest{CPUs: 1, MemoryMB: 1024}, size: "shared-cpu-1x", memory: 1024, custom: true},
+		{name: "beyond the increments of smaller sizes", req: FitRequest{MemoryMB: 8192}, size: "dedicated-cpu-1x", memory: 8192, custom: true},
+		{name: "more cpus than requested", req: FitRequest{CPUs: 3}, size: "dedicated-cpu-4x", memory: 8192},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fit, err := FitVMSize(testCatalog, tc.req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.size, fit.Size)
+			assert.Equal(t, tc.memory, fit.MemoryMB)
+			assert.Equal(t, tc.custom, fit.Custom)
+		})
+	}
+
+	_, err := FitVMSize(testCatalog, FitRequest{CPUs: 8})
+	assert.Error(t, err)
+}
+
+func TestFitVMSizePricesExtraMemory(t *testing.T) {
+	fit, err := FitVMSize(testCatalog, FitRequest{CPUs: 1, MemoryMB: 2048})
+	require.NoError(t, err)
+
+	// 1792 MB beyond the 256 MB of the size
+	assert.Equal(t, "shared-cpu-1x", fit.Size)
+	assert.True(t, fit.Custom)
+	assert.InDelta(t, 1.94+1.75*extraMemoryPriceMonthPerGB, fit.PriceMonth, 0.001)
+	assert.InDelta(t, (0.00000075+1.75*extraMemoryPriceSecondPerGB)*3600, fit.PriceHour, 0.00001)
+
+	// a bigger size with the memory is cheaper than the extra memory
+	catalog := append([]api.VMSize{
+		{Name: "shared-cpu-2x", CPUCores: 2, MemoryMB: 2048, PriceMonth: 8, PriceSecond: 0.0000031, MemoryIncrementsMB: []int{2048}},
+	}, testCatalog...)
+
+	fit, err = FitVMSize(catalog, FitRequest{CPUs: 1, MemoryMB: 2048})
+	require.NoError(t, err)
+
+	assert.Equal(t, "shared-cpu-2x", fit.Size)
+	assert.False(t, fit.Custom)
+	assert.InDelta(t, 8, fit.PriceMonth, 0.001)
+}
+
+func TestGuestForSize(t *testing.T) {
+	guest, ok := GuestForSize("shared-cpu-2x", 1024)
+	require.True(t, ok)
+	assert.Equal(t, &api.MachineGuest{CPUKind: "shared", CPUs: 2, MemoryMB: 1024}, guest)
+	assert.Equal(t, "shared-cpu-2x", SizeName(guest))
+
+	guest, ok = GuestForSize("performance-4x", 8192)
+	require.True(t, ok)
+	assert.Equal(t, "performance-4x", SizeName(guest))
+
+	_, ok = GuestForSize("dedicated-cpu-1x", 2048)
+	assert.False(t, ok)
+
+	price, ok := GuestPrice(testCatalog, &api.MachineGuest{CPUKind: "shared", CPUs: 1, MemoryMB: 1280})
+	require.True(t, ok)
+	assert.InDelta(t, 1.94+extraMemoryPriceMonthPerGB, price, 0.001)
+}
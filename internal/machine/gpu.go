@@ -0,0 +1,95 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// GPURegions returns the codes of the regions offering GPUs of kind, sorted.
+func GPURegions(regions []api.Region, kind string) []string {
+	var codes []string
+	for _, region := range regions {
+		for _, k := range region.GPUKinds {
+			if k == kind {
+				codes = append(codes, region.Code)
+
+				break
+			}
+		}
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+// CheckGPU rejects GPUs of kind in any of the given regions not offering them,
+// naming those which do.
+func CheckGPU(available []api.Region, kind string, regions ...string) error {
+	offering := GPURegions(available, kind)
+	if len(offering) == 0 {
+		kinds := map[string]bool{}
+		for _, region := range available {
+			for _, k := range region.GPUKinds {
+				kinds[k] = true
+			}
+		}
+
+		known := make([]string, 0, len(kinds))
+		for k := range kinds {
+			known = append(known, k)
+		}
+		sort.Strings(known)
+
+		return fmt.Errorf("no region offers GPUs of kind %q; available kinds: %s", kind, strings.Join(known, ", "))
+	}
+
+	for _, region := range regions {
+		if !lo.Contains(offering, region) {
+			return fmt.Errorf("region %s has no %s GPUs; regions offering them: %s", region, kind, strings.Join(offering, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ValidateGPU checks the GPUs guest asks for against what the given regions
+// offer, as listed by the platform where it lists them. Guests without GPUs
+// are always valid.
+func ValidateGPU(ctx context.Context, guest *api.MachineGuest, regions ...string) error {
+	switch {
+	case guest == nil || (guest.GPUKind == "" && guest.GPUs == 0):
+		return nil
+	case guest.GPUKind == "":
+		return fmt.Errorf("a GPU kind is required along with the number of GPUs")
+	case guest.GPUs < 1:
+		return fmt.Errorf("the number of GPUs must be at least 1, got %d", guest.GPUs)
+	}
+
+	// Not every API lists the GPUs of regions yet. Where it doesn't, or
+	// can't be asked, the regions are left for the Machines API to reject.
+	client := client.FromContext(ctx).API()
+
+	switch supported, err := client.HasField(ctx, "Region", "gpuKinds"); {
+	case err != nil:
+		terminal.Debugf("skipping GPU region checks; failed checking whether the API lists GPUs: %v\n", err)
+		return nil
+	case !supported:
+		return nil
+	}
+
+	available, err := client.PlatformGPURegions(ctx)
+	if err != nil {
+		terminal.Debugf("skipping GPU region checks; failed retrieving the regions offering GPUs: %v\n", err)
+		return nil
+	}
+
+	return CheckGPU(available, guest.GPUKind, regions...)
+}
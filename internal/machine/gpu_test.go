@@ -0,0 +1,27 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestCheckGPU(t *testing.T) {
+	regions := []api.Region{
+		{Code: "ord", GPUKinds: []string{"a100-40gb", "l40s"}},
+		{Code: "iad", GPUKinds: []string{"a100-40gb"}},
+		{Code: "ams"},
+	}
+
+	assert.Equal(t, []string{"iad", "ord"}, GPURegions(regions, "a100-40gb"))
+
+	assert.NoError(t, CheckGPU(regions, "a100-40gb", "ord", "iad"))
+	assert.NoError(t, CheckGPU(regions, "l40s"))
+
+	assert.EqualError(t, CheckGPU(regions, "a100-40gb", "ams"),
+		"region ams has no a100-40gb GPUs; regions offering them: iad, ord")
+	assert.EqualError(t, CheckGPU(regions, "h100"),
+		`no region offers GPUs of kind "h100"; available kinds: a100-40gb, l40s`)
+}
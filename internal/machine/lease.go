@@ -4,12 +4,30 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
 
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/flaps"
 	"github.com/superfly/flyctl/iostreams"
 )
 
+const (
+	// LeaseTTL is the number of seconds leases are acquired and refreshed for.
+	LeaseTTL = 120
+	// leaseRefreshInterval is how often held leases are refreshed, well
+	// within their TTL.
+	leaseRefreshInterval = LeaseTTL * time.Second / 3
+	// leaseReleaseTimeout bounds releasing a lease, which is done even once
+	// the command has been interrupted so that no lease is left dangling.
+	leaseReleaseTimeout = 10 * time.Second
+	// leaseConcurrency bounds the number of leases acquired or released at
+	// once.
+	leaseConcurrency = 8
+)
+
 type releaseLeasesFunc func(ctx context.Context, machines []*api.Machine)
 type releaseLeaseFunc func(ctx context.Context, machine *api.Machine)
 
@@ -25,33 +43,105 @@ func AcquireAllLeases(ctx context.Context) ([]*api.Machine, releaseLeasesFunc, e
 	return AcquireLeases(ctx, machines)
 }
 
-// AcquireLeases works to acquire/attach a lease for each machine specified.
+// AcquireLeases works to acquire/attach a lease for each machine specified,
+// several at a time. Should any lease fail to be acquired, those acquired by
+// then are released and no machines are returned.
 func AcquireLeases(ctx context.Context, machines []*api.Machine) ([]*api.Machine, releaseLeasesFunc, error) {
 	var (
 		flapsClient = flaps.FromContext(ctx)
 		io          = iostreams.FromContext(ctx)
 	)
 
-	releaseFunc := func(ctx context.Context, machines []*api.Machine) {
-		for _, m := range machines {
+	releaseFunc := func(_ context.Context, machines []*api.Machine) {
+		ctx, cancel := context.WithTimeout(context.Background(), leaseReleaseTimeout)
+		defer cancel()
+
+		releaseAll(machines, func(m *api.Machine) {
 			if err := flapsClient.ReleaseLease(ctx, m.ID, m.LeaseNonce); err != nil {
 				if !strings.Contains(err.Error(), "lease not found") {
-					fmt.Fprintf(io.Out, "failed to release lease for machine %s: %s", m.ID, err.Error())
+					fmt.Fprintf(io.Out, "failed to release lease for machine %s: %s\n", m.ID, err.Error())
 				}
 			}
-		}
+		})
 	}
 
-	leaseHoldingMachines := []*api.Machine{}
-	for _, machine := range machines {
+	acquire := func(ctx context.Context, machine *api.Machine) (*api.Machine, error) {
 		m, _, err := AcquireLease(ctx, machine)
-		if err != nil {
-			return leaseHoldingMachines, releaseFunc, err
+		return m, err
+	}
+
+	leased, err := acquireAll(ctx, machines, acquire, func(m *api.Machine) {
+		releaseFunc(ctx, []*api.Machine{m})
+	})
+
+	return leased, releaseFunc, err
+}
+
+// acquireAll acquires a lease on each of machines by acquire, leaseConcurrency
+// at a time, returning the leased machines in the order of machines. acquire
+// returns a machine whenever it holds a lease on it, even along with an error.
+// Should any acquire fail, the leases held by then are released by release.
+func acquireAll(ctx context.Context, machines []*api.Machine, acquire func(context.Context, *api.Machine) (*api.Machine, error), release func(*api.Machine)) ([]*api.Machine, error) {
+	var (
+		leased = make([]*api.Machine, len(machines))
+		sem    = make(chan struct{}, leaseConcurrency)
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i, machine := range machines {
+		i, machine := i, machine
+
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+			defer func() { <-sem }()
+
+			m, err := acquire(gctx, machine)
+			leased[i] = m
+
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		var held []*api.Machine
+		for _, m := range leased {
+			if m != nil {
+				held = append(held, m)
+			}
 		}
-		leaseHoldingMachines = append(leaseHoldingMachines, m)
+		releaseAll(held, release)
+
+		return nil, err
 	}
 
-	return leaseHoldingMachines, releaseFunc, nil
+	return leased, nil
+}
+
+// releaseAll runs release against machines, leaseConcurrency at a time.
+func releaseAll(machines []*api.Machine, release func(*api.Machine)) {
+	var (
+		sem = make(chan struct{}, leaseConcurrency)
+		wg  sync.WaitGroup
+	)
+
+	for _, m := range machines {
+		wg.Add(1)
+
+		go func(m *api.Machine) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			release(m)
+		}(m)
+	}
+
+	wg.Wait()
 }
 
 // AcquireLease works to acquire/attach a lease for the specified machine.
@@ -62,7 +152,11 @@ func AcquireLease(ctx context.Context, machine *api.Machine) (*api.Machine, rele
 		io          = iostreams.FromContext(ctx)
 	)
 
-	releaseFunc := func(ctx context.Context, machine *api.Machine) {
+	releaseFunc := func(_ context.Context, machine *api.Machine) {
+		// released as the command exits, possibly after being interrupted
+		ctx, cancel := context.WithTimeout(context.Background(), leaseReleaseTimeout)
+		defer cancel()
+
 		if machine != nil {
 			if err := flapsClient.ReleaseLease(ctx, machine.ID, machine.LeaseNonce); err != nil {
 				fmt.Fprintf(io.Out, "failed to release lease for machine %s: %s\n", machine.ID, err.Error())
@@ -70,7 +164,7 @@ func AcquireLease(ctx context.Context, machine *api.Machine) (*api.Machine, rele
 		}
 	}
 
-	lease, err := flapsClient.AcquireLease(ctx, machine.ID, api.IntPointer(120))
+	lease, err := flapsClient.AcquireLease(ctx, machine.ID, api.IntPointer(LeaseTTL))
 	if err != nil {
 		return nil, releaseFunc, fmt.Errorf("failed to obtain lease: %w", err)
 	}
@@ -80,12 +174,66 @@ func AcquireLease(ctx context.Context, machine *api.Machine) (*api.Machine, rele
 	machine.LeaseNonce = lease.Data.Nonce
 
 	// Re-query machine post-lease acquisition to ensure we are working against the latest configuration.
-	machine, err = flapsClient.Get(ctx, machine.ID)
+	latest, err := flapsClient.Get(ctx, machine.ID)
 	if err != nil {
 		return machine, releaseFunc, err
 	}
 
-	machine.LeaseNonce = lease.Data.Nonce
+	latest.LeaseNonce = lease.Data.Nonce
+
+	return latest, releaseFunc, nil
+}
 
-	return machine, releaseFunc, nil
+// WithLease runs fn against machine, as re-fetched once leased, while holding
+// a lease on it. The lease is refreshed for as long as fn runs, however long
+// that is, and released once fn returns, whether or not ctx is done by then.
+func WithLease(ctx context.Context, machine *api.Machine, fn func(context.Context, *api.Machine) error) error {
+	machine, release, err := AcquireLease(ctx, machine)
+	defer release(ctx, machine)
+	if err != nil {
+		return err
+	}
+
+	stop := keepLease(ctx, machine)
+	defer stop()
+
+	return fn(ctx, machine)
+}
+
+// keepLease refreshes the lease held on machine until the returned func is
+// called.
+func keepLease(ctx context.Context, machine *api.Machine) (stop func()) {
+	var (
+		flapsClient = flaps.FromContext(ctx)
+		io          = iostreams.FromContext(ctx)
+		wg          sync.WaitGroup
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(leaseRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if _, err := flapsClient.RefreshLease(ctx, machine.ID, api.IntPointer(LeaseTTL), machine.LeaseNonce); err != nil && ctx.Err() == nil {
+				fmt.Fprintf(io.ErrOut, "failed to refresh lease for machine %s: %s\n", machine.ID, err.Error())
+			}
+		}
+	}()
+
+	// waits for any refresh in flight so that it can't outlive a release
+	return func() {
+		cancel()
+		wg.Wait()
+	}
 }
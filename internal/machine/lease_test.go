@@ -0,0 +1,103 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func testMachines(n int) []*api.Machine {
+	machines := make([]*api.Machine, n)
+	for i := range machines {
+		machines[i] = &api.Machine{ID: fmt.Sprintf("m%02d", i)}
+	}
+
+	return machines
+}
+
+func TestAcquireAllKeepsOrderAndBoundsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	acquire := func(_ context.Context, m *api.Machine) (*api.Machine, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		return &api.Machine{ID: m.ID, LeaseNonce: "nonce-" + m.ID}, nil
+	}
+
+	machines := testMachines(12)
+	leased, err := acquireAll(context.Background(), machines, acquire, func(*api.Machine) {
+		t.Fatal("released a lease although every acquire succeeded")
+	})
+	require.NoError(t, err)
+
+	require.Len(t, leased, len(machines))
+	for i, m := range leased {
+		assert.Equal(t, machines[i].ID, m.ID)
+		assert.Equal(t, "nonce-"+machines[i].ID, m.LeaseNonce)
+	}
+	assert.LessOrEqual(t, int(maxInFlight), leaseConcurrency)
+	assert.Greater(t, int(maxInFlight), 1)
+}
+
+func TestAcquireAllReleasesHeldLeasesOnFailure(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		acquired []string
+		released []string
+	)
+
+	acquire := func(ctx context.Context, m *api.Machine) (*api.Machine, error) {
+		switch m.ID {
+		case "m03":
+			return nil, errors.New("failed to obtain lease")
+		case "m05":
+			// leased, but failed to be re-fetched
+			mu.Lock()
+			acquired = append(acquired, m.ID)
+			mu.Unlock()
+
+			return m, errors.New("failed to get VM")
+		}
+
+		mu.Lock()
+		acquired = append(acquired, m.ID)
+		mu.Unlock()
+
+		return m, nil
+	}
+
+	release := func(m *api.Machine) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		released = append(released, m.ID)
+	}
+
+	leased, err := acquireAll(context.Background(), testMachines(12), acquire, release)
+	require.Error(t, err)
+	assert.Nil(t, leased)
+
+	sort.Strings(acquired)
+	sort.Strings(released)
+	assert.Equal(t, acquired, released)
+	assert.NotContains(t, released, "m03")
+}
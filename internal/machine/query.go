@@ -5,6 +5,7 @@ import (
 
 	"github.com/samber/lo"
 	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/flaps"
 )
 
@@ -25,3 +26,31 @@ func ListActive(ctx context.Context) ([]*api.Machine, error) {
 	return machines, nil
 
 }
+
+// Zones returns the hardware zones of the machines of appName, keyed by
+// machine ID. The zone of a machine is that of the volume it mounts, so
+// machines without volumes are left out.
+func Zones(ctx context.Context, appName string, machines []*api.Machine) (map[string]string, error) {
+	volumes, err := client.FromContext(ctx).API().GetVolumes(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	volumeZones := make(map[string]string, len(volumes))
+	for _, v := range volumes {
+		volumeZones[v.ID] = v.Host.ID
+	}
+
+	zones := map[string]string{}
+	for _, m := range machines {
+		if m.Config == nil || len(m.Config.Mounts) == 0 {
+			continue
+		}
+
+		if zone := volumeZones[m.Config.Mounts[0].Volume]; zone != "" {
+			zones[m.ID] = zone
+		}
+	}
+
+	return zones, nil
+}
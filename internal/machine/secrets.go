@@ -0,0 +1,247 @@
+package machine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/client"
+)
+
+// ScopedSecretsMetadataKey names the metadata entry listing the environment
+// variables flyctl manages as secrets scoped to a machine, rather than to the
+// whole app.
+const ScopedSecretsMetadataKey = "fly_scoped_secrets"
+
+// ScopedSecretNames returns the names of the scoped secrets set on conf.
+func ScopedSecretNames(conf *api.MachineConfig) []string {
+	if conf == nil || conf.Metadata[ScopedSecretsMetadataKey] == "" {
+		return nil
+	}
+
+	return strings.Split(conf.Metadata[ScopedSecretsMetadataKey], ",")
+}
+
+func setScopedSecretNames(conf *api.MachineConfig, names []string) {
+	if len(names) == 0 {
+		delete(conf.Metadata, ScopedSecretsMetadataKey)
+
+		return
+	}
+
+	if conf.Metadata == nil {
+		conf.Metadata = map[string]string{}
+	}
+
+	sort.Strings(names)
+	conf.Metadata[ScopedSecretsMetadataKey] = strings.Join(names, ",")
+}
+
+// SetScopedSecrets injects secrets into the environment of conf and marks
+// them as scoped secrets.
+func SetScopedSecrets(conf *api.MachineConfig, secrets map[string]string) {
+	if conf.Env == nil {
+		conf.Env = map[string]string{}
+	}
+
+	names := map[string]bool{}
+	for _, name := range ScopedSecretNames(conf) {
+		names[name] = true
+	}
+
+	for name, value := range secrets {
+		conf.Env[name] = value
+		names[name] = true
+	}
+
+	setScopedSecretNames(conf, keys(names))
+}
+
+// UnsetScopedSecrets removes the named scoped secrets from conf and returns
+// the names of those that were set.
+func UnsetScopedSecrets(conf *api.MachineConfig, names []string) (removed []string) {
+	unset := map[string]bool{}
+	for _, name := range names {
+		unset[name] = true
+	}
+
+	var kept []string
+	for _, name := range ScopedSecretNames(conf) {
+		if unset[name] {
+			delete(conf.Env, name)
+			removed = append(removed, name)
+
+			continue
+		}
+		kept = append(kept, name)
+	}
+
+	setScopedSecretNames(conf, kept)
+
+	return
+}
+
+// PreserveScopedSecrets carries the scoped secrets of src over to dst, so
+// that replacing a machine's config doesn't drop them.
+func PreserveScopedSecrets(dst, src *api.MachineConfig) {
+	names := ScopedSecretNames(src)
+	if len(names) == 0 {
+		return
+	}
+
+	secrets := make(map[string]string, len(names))
+	for _, name := range names {
+		if value, ok := src.Env[name]; ok {
+			secrets[name] = value
+		}
+	}
+
+	SetScopedSecrets(dst, secrets)
+}
+
+// MaskScopedSecrets returns conf for display, with the values of its scoped
+// secrets replaced by their digests. conf itself is left untouched.
+func MaskScopedSecrets(conf *api.MachineConfig) *api.MachineConfig {
+	names := ScopedSecretNames(conf)
+	if len(names) == 0 {
+		return conf
+	}
+
+	masked := *conf
+	masked.Env = make(map[string]string, len(conf.Env))
+	for name, value := range conf.Env {
+		masked.Env[name] = value
+	}
+
+	for _, name := range names {
+		if value, ok := masked.Env[name]; ok {
+			masked.Env[name] = fmt.Sprintf("<secret %s>", SecretDigest(value))
+		}
+	}
+
+	return &masked
+}
+
+// MaskMachineSecrets returns machines for display, as copies whose configs
+// have their scoped secrets masked by MaskScopedSecrets.
+func MaskMachineSecrets(machines []*api.Machine) []*api.Machine {
+	masked := make([]*api.Machine, 0, len(machines))
+	for _, m := range machines {
+		if m == nil || m.Config == nil {
+			masked = append(masked, m)
+
+			continue
+		}
+
+		c := *m
+		c.Config = MaskScopedSecrets(m.Config)
+		masked = append(masked, &c)
+	}
+
+	return masked
+}
+
+// SecretDigest returns a digest of value suitable for display in place of
+// the value itself.
+func SecretDigest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+var interpolation = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// InterpolatedSecrets returns the names of the variables interpolated as
+// ${NAME} into the values of env or into texts, such as the shell references
+// of commands, which neither env nor the platform define, and which thus have
+// to be set as secrets.
+func InterpolatedSecrets(env map[string]string, texts ...string) []string {
+	for _, value := range env {
+		texts = append(texts, value)
+	}
+
+	names := map[string]bool{}
+	for _, text := range texts {
+		for _, match := range interpolation.FindAllStringSubmatch(text, -1) {
+			name := match[1]
+			if _, ok := env[name]; ok || isPlatformEnv(name) {
+				continue
+			}
+			names[name] = true
+		}
+	}
+
+	return keys(names)
+}
+
+// isPlatformEnv reports whether name is that of a variable the platform sets
+// in the environment of every machine.
+func isPlatformEnv(name string) bool {
+	return strings.HasPrefix(name, "FLY_") || name == "PRIMARY_REGION"
+}
+
+// ReferencedSecrets returns the names of the app secrets conf refers to,
+// either as the contents of files or through interpolation.
+func ReferencedSecrets(conf *api.MachineConfig) []string {
+	names := map[string]bool{}
+	for _, file := range conf.Files {
+		if file.SecretName != nil {
+			names[*file.SecretName] = true
+		}
+	}
+
+	texts := append(append(append([]string{}, conf.Init.Exec...), conf.Init.Entrypoint...), conf.Init.Cmd...)
+	for _, name := range InterpolatedSecrets(conf.Env, texts...) {
+		names[name] = true
+	}
+
+	return keys(names)
+}
+
+// ValidateSecrets returns an error listing those of referenced which aren't
+// set as secrets of the named app, so that rollouts fail before replacing
+// machines rather than once their replacements boot.
+func ValidateSecrets(ctx context.Context, appName string, referenced []string) error {
+	if len(referenced) == 0 {
+		return nil
+	}
+
+	secrets, err := client.FromContext(ctx).API().GetAppSecrets(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving the secrets of %s: %w", appName, err)
+	}
+
+	set := make(map[string]bool, len(secrets))
+	for _, secret := range secrets {
+		set[secret.Name] = true
+	}
+
+	var missing []string
+	for _, name := range referenced {
+		if !set[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+
+	return fmt.Errorf("the config refers to secrets which aren't set on %s: %s; set them with 'fly secrets set' or pass --skip-secret-validation", appName, strings.Join(missing, ", "))
+}
+
+func keys(m map[string]bool) []string {
+	s := make([]string, 0, len(m))
+	for k := range m {
+		s = append(s, k)
+	}
+
+	return s
+}
@@ -0,0 +1,47 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestMaskScopedSecrets(t *testing.T) {
+	conf := &api.MachineConfig{Env: map[string]string{"MODE": "production"}}
+	SetScopedSecrets(conf, map[string]string{"API_KEY": "hunter2"})
+
+	masked := MaskScopedSecrets(conf)
+	assert.Equal(t, "production", masked.Env["MODE"])
+	assert.Equal(t, "<secret "+SecretDigest("hunter2")+">", masked.Env["API_KEY"])
+	assert.NotContains(t, masked.Env["API_KEY"], "hunter2")
+
+	// the config the machine runs with keeps the value
+	assert.Equal(t, "hunter2", conf.Env["API_KEY"])
+
+	plain := &api.MachineConfig{Env: map[string]string{"MODE": "production"}}
+	assert.Same(t, plain, MaskScopedSecrets(plain), "configs without scoped secrets")
+}
+
+func TestMaskMachineSecrets(t *testing.T) {
+	conf := &api.MachineConfig{}
+	SetScopedSecrets(conf, map[string]string{"API_KEY": "hunter2"})
+	machines := []*api.Machine{{ID: "a", Config: conf}, {ID: "b"}}
+
+	masked := MaskMachineSecrets(machines)
+	assert.Len(t, masked, 2)
+	assert.NotEqual(t, "hunter2", masked[0].Config.Env["API_KEY"])
+	assert.Nil(t, masked[1].Config)
+	assert.Equal(t, "hunter2", machines[0].Config.Env["API_KEY"])
+}
+
+func TestUnsetScopedSecrets(t *testing.T) {
+	conf := &api.MachineConfig{Env: map[string]string{"MODE": "production"}}
+	SetScopedSecrets(conf, map[string]string{"API_KEY": "hunter2", "TOKEN": "t"})
+	assert.Equal(t, []string{"API_KEY", "TOKEN"}, ScopedSecretNames(conf))
+
+	assert.Equal(t, []string{"TOKEN"}, UnsetScopedSecrets(conf, []string{"TOKEN", "MODE"}))
+	assert.Equal(t, map[string]string{"MODE": "production", "API_KEY": "hunter2"}, conf.Env)
+	assert.Equal(t, []string{"API_KEY"}, ScopedSecretNames(conf))
+}
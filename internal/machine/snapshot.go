@@ -0,0 +1,124 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flaps"
+)
+
+// Snapshot is the configuration of a machine as it was before an update.
+type Snapshot struct {
+	ID     string             `json:"id"`
+	Name   string             `json:"name"`
+	Region string             `json:"region"`
+	Config *api.MachineConfig `json:"config"`
+}
+
+// SnapshotFile is the on-disk record of the machines a deployment is about to
+// update, kept around so they may be restored should flyctl not get to.
+type SnapshotFile struct {
+	App       string     `json:"app"`
+	CreatedAt time.Time  `json:"created_at"`
+	Machines  []Snapshot `json:"machines"`
+}
+
+// Find returns the snapshot of the given machine, if any.
+func (f *SnapshotFile) Find(machineID string) (Snapshot, bool) {
+	for _, s := range f.Machines {
+		if s.ID == machineID {
+			return s, true
+		}
+	}
+	return Snapshot{}, false
+}
+
+// NewSnapshotFile captures the current configuration of the given machines.
+func NewSnapshotFile(appName string, machines []*api.Machine) (*SnapshotFile, error) {
+	file := &SnapshotFile{
+		App:       appName,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	for _, m := range machines {
+		config, err := CloneConfig(*m.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		file.Machines = append(file.Machines, Snapshot{
+			ID:     m.ID,
+			Name:   m.Name,
+			Region: m.Region,
+			Config: config,
+		})
+	}
+
+	return file, nil
+}
+
+// Write stores the snapshots in a new temporary file and returns its path.
+func (f *SnapshotFile) Write() (string, error) {
+	out, err := os.CreateTemp("", fmt.Sprintf("flyctl-%s-snapshot-*.json", f.App))
+	if err != nil {
+		return "", fmt.Errorf("failed creating snapshot file: %w", err)
+	}
+	defer out.Close()
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return "", fmt.Errorf("failed writing snapshot file: %w", err)
+	}
+
+	return out.Name(), nil
+}
+
+// ReadSnapshots reads the snapshot file at path.
+func ReadSnapshots(path string) (*SnapshotFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading snapshot file: %w", err)
+	}
+
+	var file SnapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid snapshot file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// Restore updates the machine to the configuration captured by the snapshot
+// and waits for it to start. The caller must hold the machine's lease.
+func Restore(ctx context.Context, appName string, s Snapshot, nonce string) error {
+	flapsClient := flaps.FromContext(ctx)
+
+	input := api.LaunchMachineInput{
+		ID:     s.ID,
+		AppID:  appName,
+		Name:   s.Name,
+		Region: s.Region,
+		Config: s.Config,
+	}
+
+	m, err := flapsClient.Update(ctx, input, nonce)
+	if err != nil {
+		return fmt.Errorf("failed restoring machine %s: %w", s.ID, err)
+	}
+
+	state := "started"
+	if s.Config.Schedule != "" {
+		state = "stopped"
+	}
+
+	if err := flapsClient.Wait(ctx, m, state); err != nil {
+		return fmt.Errorf("machine %s did not reach the %s state: %w", s.ID, state, err)
+	}
+
+	return nil
+}
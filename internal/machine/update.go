@@ -11,6 +11,47 @@ import (
 	"github.com/superfly/flyctl/iostreams"
 )
 
+// ConcurrentUpdateError is returned when a machine changed between being
+// read to base an update on and being leased to apply it, so applying the
+// update would silently undo the other change.
+type ConcurrentUpdateError struct {
+	MachineID string
+	// Diff is how the live config of the machine differs from the config
+	// the update was based on.
+	Diff string
+}
+
+func (e *ConcurrentUpdateError) Error() string {
+	return fmt.Sprintf("machine %s was updated by someone else since it was read", e.MachineID)
+}
+
+func (e *ConcurrentUpdateError) Description() string {
+	if e.Diff == "" {
+		return "Its config is unchanged, but it was restarted or replaced meanwhile."
+	}
+
+	return "Its live config differs from the one this update was based on:\n\n" + e.Diff
+}
+
+func (e *ConcurrentUpdateError) Suggestion() string {
+	return "Run the update again to base it on the live config, or pass --force to apply it anyway."
+}
+
+// CheckUnchanged returns a *ConcurrentUpdateError if live, the machine as
+// re-fetched once leased, is no longer the version of it read was.
+func CheckUnchanged(ctx context.Context, read, live *api.Machine) error {
+	if read.InstanceID == live.InstanceID {
+		return nil
+	}
+
+	diff := ""
+	if read.Config != nil && live.Config != nil {
+		diff = configCompare(ctx, *read.Config, *live.Config)
+	}
+
+	return &ConcurrentUpdateError{MachineID: live.ID, Diff: diff}
+}
+
 func Update(ctx context.Context, m *api.Machine, input *api.LaunchMachineInput) error {
 	var (
 		flapsClient = flaps.FromContext(ctx)
@@ -25,6 +66,12 @@ func Update(ctx context.Context, m *api.Machine, input *api.LaunchMachineInput)
 		return fmt.Errorf("could not stop machine %s: %w", input.ID, err)
 	}
 
+	if input.SkipLaunch {
+		fmt.Fprintf(io.Out, "Machine %s updated; it stays stopped until started\n", colorize.Bold(m.ID))
+
+		return nil
+	}
+
 	waitForAction := "start"
 	if m.Config.Schedule != "" {
 		waitForAction = "stop"
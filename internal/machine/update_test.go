@@ -0,0 +1,50 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func TestCheckUnchanged(t *testing.T) {
+	io, _, _, _ := iostreams.Test()
+	ctx := iostreams.NewContext(context.Background(), io)
+
+	read := &api.Machine{
+		ID:         "m01",
+		InstanceID: "01GSV1",
+		Config:     &api.MachineConfig{Image: "app:v1", Env: map[string]string{"MODE": "a"}},
+	}
+
+	// leased without anyone else updating it meanwhile
+	same := *read
+	same.LeaseNonce = "nonce"
+	assert.NoError(t, CheckUnchanged(ctx, read, &same))
+
+	// someone else's update bumped its version before the lease
+	bumped := *read
+	bumped.InstanceID = "01GSV2"
+	bumped.Config = &api.MachineConfig{Image: "app:v1", Env: map[string]string{"MODE": "b"}}
+
+	err := CheckUnchanged(ctx, read, &bumped)
+
+	var concurrent *ConcurrentUpdateError
+	require.True(t, errors.As(err, &concurrent))
+	assert.Equal(t, "m01", concurrent.MachineID)
+	assert.Contains(t, concurrent.Diff, `"MODE": "a"`)
+	assert.Contains(t, concurrent.Diff, `"MODE": "b"`)
+
+	// restarted onto a new version with the same config
+	restarted := *read
+	restarted.InstanceID = "01GSV3"
+
+	err = CheckUnchanged(ctx, read, &restarted)
+	require.True(t, errors.As(err, &concurrent))
+	assert.Empty(t, concurrent.Diff)
+}
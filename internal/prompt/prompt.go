@@ -16,6 +16,7 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/client"
 	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/logger"
 	"github.com/superfly/flyctl/internal/sort"
 )
 
@@ -194,8 +195,16 @@ func Org(ctx context.Context) (*api.Organization, error) {
 	}
 	sort.OrganizationsByTypeAndName(orgs)
 
-	io := iostreams.FromContext(ctx)
-	slug := config.FromContext(ctx).Organization
+	var (
+		io     = iostreams.FromContext(ctx)
+		cfg    = config.FromContext(ctx)
+		logger = logger.FromContext(ctx)
+		slug   = cfg.Organization
+	)
+
+	if slug != "" {
+		logger.Debugf("using organization %s set by %s", slug, cfg.OrganizationSource)
+	}
 
 	switch {
 	case slug == "" && len(orgs) == 1 && orgs[0].Type == "PERSONAL":
@@ -210,8 +219,14 @@ func Org(ctx context.Context) (*api.Organization, error) {
 			}
 		}
 
+		if cfg.OrganizationSource == config.OrgSourceFile {
+			return nil, fmt.Errorf("organization %s, the default set by `fly orgs switch`, not found; switch to another one", slug)
+		}
+
 		return nil, fmt.Errorf("organization %s not found", slug)
 	default:
+		logger.Debug("no organization given; prompting for one")
+
 		switch org, err := SelectOrg(ctx, orgs); {
 		case err == nil:
 			return org, nil
@@ -223,6 +238,14 @@ func Org(ctx context.Context) (*api.Organization, error) {
 	}
 }
 
+// AnnounceOrg prints the organization a command is about to create billable
+// resources in, whether or not it was prompted for, so that creating them in
+// the wrong one is caught.
+func AnnounceOrg(ctx context.Context, org *api.Organization) {
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.ErrOut, "Using organization %s (%s)\n", io.ColorScheme().Bold(org.Name), org.Slug)
+}
+
 func SelectOrg(ctx context.Context, orgs []api.Organization) (org *api.Organization, err error) {
 	var options []string
 	for _, org := range orgs {
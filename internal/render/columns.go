@@ -0,0 +1,126 @@
+package render
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/superfly/flyctl/internal/format"
+)
+
+// Alignment is the alignment of the cells of a column.
+type Alignment int
+
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+)
+
+// Column describes a column of a table. Rows always carry raw values; Format,
+// if set, converts them for display only, so that machine readable output
+// derived from the same rows is unaffected.
+type Column struct {
+	Name   string
+	Align  Alignment
+	Format func(string) string
+}
+
+// Col returns a left aligned column displaying values as-is.
+func Col(name string) Column {
+	return Column{Name: name}
+}
+
+// NumberCol returns a right aligned column.
+func NumberCol(name string) Column {
+	return Column{Name: name, Align: AlignRight}
+}
+
+// BytesCol returns a right aligned column displaying byte counts in
+// humanized units, e.g. 10737418240 as 10 GB.
+func BytesCol(name string) Column {
+	return Column{Name: name, Align: AlignRight, Format: formatBytes}
+}
+
+// TimestampCol returns a column displaying RFC 3339 timestamps relative to
+// now, e.g. 3h ago, or as-is in case absolute is set.
+func TimestampCol(name string, absolute bool) Column {
+	col := Column{Name: name}
+	if !absolute {
+		col.Format = formatRelativeTimestamp
+	}
+
+	return col
+}
+
+func formatBytes(v string) string {
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return v
+	}
+
+	return format.Bytes(n)
+}
+
+func formatRelativeTimestamp(v string) string {
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return v
+	}
+
+	return format.RelativeTime(t)
+}
+
+func (c Column) format(v string) string {
+	if c.Format == nil {
+		return v
+	}
+
+	return c.Format(v)
+}
+
+// TableWithColumns behaves like Table, formatting and aligning the cells of
+// the rows as cols describe.
+func TableWithColumns(w io.Writer, title string, rows [][]string, cols ...Column) error {
+	names := make([]string, len(cols))
+	alignments := make([]int, len(cols))
+	for i, col := range cols {
+		names[i] = col.Name
+
+		alignments[i] = tablewriter.ALIGN_LEFT
+		if col.Align == AlignRight {
+			alignments[i] = tablewriter.ALIGN_RIGHT
+		}
+	}
+
+	return renderTable(w, title, formatRows(rows, cols), names, alignments)
+}
+
+// VerticalTableWithColumns behaves like VerticalTable, formatting the values
+// of the objects as cols describe.
+func VerticalTableWithColumns(w io.Writer, title string, objects [][]string, cols ...Column) error {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.Name
+	}
+
+	return VerticalTable(w, title, formatRows(objects, cols), names...)
+}
+
+func formatRows(rows [][]string, cols []Column) [][]string {
+	formatted := make([][]string, len(rows))
+
+	for i, row := range rows {
+		formatted[i] = make([]string, len(row))
+
+		for j, v := range row {
+			if j < len(cols) {
+				v = cols[j].format(v)
+			}
+			formatted[i][j] = v
+		}
+	}
+
+	return formatted
+}
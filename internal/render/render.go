@@ -27,6 +27,10 @@ func TitledJSON(w io.Writer, title string, v interface{}) error {
 // Table renders the table defined by the given properties into w. Both title &
 // cols are optional.
 func Table(w io.Writer, title string, rows [][]string, cols ...string) error {
+	return renderTable(w, title, rows, cols, nil)
+}
+
+func renderTable(w io.Writer, title string, rows [][]string, cols []string, alignments []int) error {
 	if title != "" {
 		fmt.Fprintln(w, aurora.Bold(title))
 	}
@@ -47,6 +51,10 @@ func Table(w io.Writer, title string, rows [][]string, cols ...string) error {
 	table.SetNoWhiteSpace(true)
 	table.SetTablePadding("\t")
 
+	if len(alignments) > 0 {
+		table.SetColumnAlignment(alignments)
+	}
+
 	table.AppendBulk(rows)
 
 	table.Render()
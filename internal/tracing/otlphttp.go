@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpClient implements otlptrace.Client, posting protobuf encoded spans to
+// the /v1/traces path of an OTLP/HTTP collector.
+type httpClient struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPClient(endpoint string) *httpClient {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "https://" + url
+	}
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+
+	return &httpClient{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *httpClient) Start(context.Context) error { return nil }
+
+func (c *httpClient) Stop(context.Context) error {
+	c.client.CloseIdleConnections()
+
+	return nil
+}
+
+func (c *httpClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	body, err := proto.Marshal(&coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: spans,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("failed exporting traces to %s: %s", c.url, res.Status)
+	}
+
+	return nil
+}
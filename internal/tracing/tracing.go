@@ -0,0 +1,101 @@
+// Package tracing implements optional OpenTelemetry tracing for long running
+// commands such as deploy.
+//
+// Tracing is disabled unless an OTLP endpoint is configured. While disabled,
+// every span started through this package is a no-op.
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/superfly/flyctl/internal/buildinfo"
+)
+
+// EnvEndpoint names the environment variable which, when set, enables tracing
+// and configures the OTLP/HTTP endpoint spans are exported to.
+const EnvEndpoint = "FLY_OTEL_EXPORTER_OTLP_ENDPOINT"
+
+const instrumentationName = "github.com/superfly/flyctl"
+
+type contextKey struct{}
+
+// NewContext derives a Context that carries t from ctx.
+func NewContext(ctx context.Context, t trace.Tracer) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the Tracer ctx carries. It returns a no-op Tracer in case
+// ctx carries none.
+func FromContext(ctx context.Context) trace.Tracer {
+	if t, ok := ctx.Value(contextKey{}).(trace.Tracer); ok {
+		return t
+	}
+
+	return trace.NewNoopTracerProvider().Tracer(instrumentationName)
+}
+
+// StartSpan starts a span named name as a child of the span ctx carries, if
+// any, using the Tracer ctx carries.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return FromContext(ctx).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err, if any, on span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// Shutdown flushes and stops a tracer set up by Setup.
+type Shutdown func(context.Context) error
+
+func noopShutdown(context.Context) error { return nil }
+
+// Setup configures a Tracer exporting spans to the OTLP/HTTP collector at
+// endpoint and returns a Context carrying it. In case endpoint is empty,
+// Setup returns ctx unchanged along with a no-op Shutdown.
+func Setup(ctx context.Context, endpoint string) (context.Context, Shutdown, error) {
+	if endpoint == "" {
+		return ctx, noopShutdown, nil
+	}
+
+	exporter, err := otlptrace.New(ctx, newHTTPClient(endpoint))
+	if err != nil {
+		return ctx, noopShutdown, err
+	}
+
+	return WithExporter(ctx, exporter)
+}
+
+// WithExporter configures a Tracer exporting spans to exporter and returns a
+// Context carrying it.
+func WithExporter(ctx context.Context, exporter sdktrace.SpanExporter) (context.Context, Shutdown, error) {
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String("flyctl"),
+		semconv.ServiceVersionKey.String(buildinfo.Version().String()),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(time.Second)),
+		sdktrace.WithResource(res),
+	)
+
+	shutdown := func(ctx context.Context) error {
+		return provider.Shutdown(ctx)
+	}
+
+	return NewContext(ctx, provider.Tracer(instrumentationName)), shutdown, nil
+}
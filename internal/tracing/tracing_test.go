@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// retainingExporter keeps exported spans around after shutdown so that they
+// may be inspected.
+type retainingExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (retainingExporter) Shutdown(context.Context) error { return nil }
+
+func TestFromContextDefaultsToNoop(t *testing.T) {
+	_, span := StartSpan(context.Background(), "noop")
+	defer span.End()
+
+	assert.False(t, span.IsRecording())
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	ctx := context.Background()
+
+	got, shutdown, err := Setup(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, ctx, got)
+	assert.NoError(t, shutdown(ctx))
+}
+
+func TestSpanHierarchy(t *testing.T) {
+	exporter := retainingExporter{tracetest.NewInMemoryExporter()}
+
+	ctx, shutdown, err := WithExporter(context.Background(), exporter)
+	require.NoError(t, err)
+
+	ctx, root := StartSpan(ctx, "deploy")
+
+	_, cfg := StartSpan(ctx, "config")
+	EndSpan(cfg, nil)
+
+	machineCtx, machine := StartSpan(ctx, "machine.rollout", attribute.String("machine.id", "abc123"))
+	_, update := StartSpan(machineCtx, "machine.update")
+	EndSpan(update, errors.New("boom"))
+	EndSpan(machine, nil)
+
+	EndSpan(root, nil)
+	require.NoError(t, shutdown(context.Background()))
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, s := range exporter.GetSpans() {
+		spans[s.Name] = s
+	}
+	require.Len(t, spans, 4)
+
+	rootID := spans["deploy"].SpanContext.SpanID()
+	assert.False(t, spans["deploy"].Parent.IsValid())
+	assert.Equal(t, rootID, spans["config"].Parent.SpanID())
+	assert.Equal(t, rootID, spans["machine.rollout"].Parent.SpanID())
+	assert.Equal(t, spans["machine.rollout"].SpanContext.SpanID(), spans["machine.update"].Parent.SpanID())
+
+	assert.Contains(t, spans["machine.rollout"].Attributes, attribute.String("machine.id", "abc123"))
+	assert.Equal(t, codes.Error, spans["machine.update"].Status.Code)
+	assert.Equal(t, codes.Unset, spans["config"].Status.Code)
+}
+
+func TestSetupExportsOverHTTP(t *testing.T) {
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var req coltracepb.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(body, &req))
+		require.Len(t, req.ResourceSpans, 1)
+		assert.Equal(t, "deploy", req.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans[0].Name)
+	}))
+	defer srv.Close()
+
+	ctx, shutdown, err := Setup(context.Background(), srv.URL)
+	require.NoError(t, err)
+
+	_, span := StartSpan(ctx, "deploy")
+	span.End()
+
+	require.NoError(t, shutdown(context.Background()))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+}
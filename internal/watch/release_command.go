@@ -0,0 +1,65 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// ReleaseCommandLogLines is the number of log lines a ReleaseCommandError
+// carries.
+const ReleaseCommandLogLines = 20
+
+// ReleaseCommandError describes a failed release command along with what's
+// needed to dig into it.
+type ReleaseCommandError struct {
+	// InstanceID is the ID of the machine or VM which ran the command.
+	InstanceID string
+	Reason     string
+	// Logs holds the last lines the command logged, oldest first.
+	Logs []string
+}
+
+func (e *ReleaseCommandError) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Reason)
+
+	if len(e.Logs) > 0 {
+		fmt.Fprintf(&b, "\n\nLast %d lines of the release command's output:\n", len(e.Logs))
+		for _, line := range e.Logs {
+			fmt.Fprintf(&b, "\t%s\n", line)
+		}
+	}
+
+	if e.InstanceID != "" {
+		fmt.Fprintf(&b, "\nView the full logs with: fly logs -i %s", e.InstanceID)
+	}
+
+	return b.String()
+}
+
+// RecentLogs returns up to the last ReleaseCommandLogLines messages logged by
+// the given instance.
+func RecentLogs(ctx context.Context, client *api.Client, appName, instanceID string) ([]string, error) {
+	entries, _, err := client.GetAppLogs(ctx, appName, "", "", instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, entry.Message)
+	}
+
+	return lastLines(lines, ReleaseCommandLogLines), nil
+}
+
+func lastLines(lines []string, n int) []string {
+	if len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -160,6 +160,11 @@ func ReleaseCommand(ctx context.Context, appName string, id string) error {
 
 	rcUpdates := make(chan api.ReleaseCommand)
 
+	var (
+		logsMu     sync.Mutex
+		recentLogs []string
+	)
+
 	startLogs := func(ctx context.Context, vmid string) {
 		g.Go(func() error {
 			childCtx, cancel := context.WithCancel(ctx)
@@ -181,6 +186,10 @@ func ReleaseCommand(ctx context.Context, appName string, id string) error {
 
 				fmt.Fprintln(io.Out, "\t", entry.Message)
 
+				logsMu.Lock()
+				recentLogs = lastLines(append(recentLogs, entry.Message), ReleaseCommandLogLines)
+				logsMu.Unlock()
+
 				// watch for the shutdown message
 				if entry.Message == "Starting clean up." {
 					cancel()
@@ -257,7 +266,21 @@ func ReleaseCommand(ctx context.Context, appName string, id string) error {
 				if rc.Succeeded && interactive {
 					s.StopWithMessage("Running release task... Done.")
 				} else if rc.Failed {
-					return fmt.Errorf("release command failed, deployment aborted")
+					rcErr := &ReleaseCommandError{
+						Reason: "release command failed, deployment aborted",
+					}
+					if rc.InstanceID != nil {
+						rcErr.InstanceID = *rc.InstanceID
+					}
+					if rc.ExitCode != nil {
+						rcErr.Reason = fmt.Sprintf("release command exited with non-zero status of %d, deployment aborted", *rc.ExitCode)
+					}
+
+					logsMu.Lock()
+					rcErr.Logs = append([]string(nil), recentLogs...)
+					logsMu.Unlock()
+
+					return rcErr
 				}
 			}
 		}
@@ -296,6 +319,13 @@ func renderLogs(ctx context.Context, alloc *api.AllocationStatus) {
 }
 
 func MachinesChecks(ctx context.Context, machines []*api.Machine) error {
+	return MachinesChecksWithGracePeriod(ctx, machines, 0)
+}
+
+// MachinesChecksWithGracePeriod waits for the checks of the given machines to
+// pass. Checks which aren't passing within the grace period are reported as
+// starting; the usual timeout only starts counting once the grace period ends.
+func MachinesChecksWithGracePeriod(ctx context.Context, machines []*api.Machine, gracePeriod time.Duration) error {
 	io := iostreams.FromContext(ctx)
 	colorize := io.ColorScheme()
 
@@ -307,9 +337,10 @@ func MachinesChecks(ctx context.Context, machines []*api.Machine) error {
 	}
 
 	machineIDs := lo.Map(machines, func(m *api.Machine, _ int) string { return m.ID })
-	ctx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, gracePeriod+300*time.Second)
 	defer cancel()
 	iteration := 0
+	graceEnd := time.Now().Add(gracePeriod)
 
 	fn := func() error {
 		checked, err := retryGetMachines(ctx, machineIDs...)
@@ -324,13 +355,37 @@ func MachinesChecks(ctx context.Context, machines []*api.Machine) error {
 			fmt.Fprint(io.ErrOut, str.String())
 		}
 
+		graceLeft := time.Until(graceEnd).Round(time.Second)
+
 		checksPassed := 0
 		for _, machine := range checked {
 			if machine.Config.Checks == nil {
 				continue
 			}
+			if _, paused := machine.ChecksPaused(time.Now()); paused {
+				// paused for maintenance, so not waited for
+				checksPassed += len(machine.Config.Checks)
+				fmt.Fprintf(io.ErrOut, "  Skipping checks of %s (%s, maintenance)\n",
+					colorize.Bold(machine.ID),
+					colorize.Green(machine.State),
+				)
+				continue
+			}
 			pass, _, _ := countChecks(machine.Checks)
 			checksPassed += pass
+
+			if graceLeft > 0 && pass < len(machine.Config.Checks) {
+				// Waiting for xxxxxxxx to become healthy (started, 1/3, 2 starting, grace period 45s left)
+				fmt.Fprintf(io.ErrOut, "  Waiting for %s to become healthy (%s, %s, %s, grace period %s left)\n",
+					colorize.Bold(machine.ID),
+					colorize.Green(machine.State),
+					colorize.Green(fmt.Sprintf("%d/%d", pass, len(machine.Checks))),
+					colorize.Yellow(fmt.Sprintf("%d starting", len(machine.Config.Checks)-pass)),
+					graceLeft,
+				)
+				continue
+			}
+
 			// Waiting for xxxxxxxx to become healthy (started, 3/3)
 			fmt.Fprintf(io.ErrOut, "  Waiting for %s to become healthy (%s, %s)\n",
 				colorize.Bold(machine.ID),
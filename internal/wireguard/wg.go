@@ -8,6 +8,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/pkg/errors"
@@ -39,7 +40,22 @@ func generatePeerName(ctx context.Context, apiClient *api.Client) (string, error
 }
 
 func StateForOrg(apiClient *api.Client, org *api.Organization, regionCode string, name string, recycle bool) (*wg.WireGuardState, error) {
-	state, err := getWireGuardStateForOrg(org.Slug)
+	return stateFor(apiClient, org, org.Slug, "interactive-agent", regionCode, name, recycle)
+}
+
+// userspaceStateSuffix sets the keys of the states of the peers of in-process
+// tunnels apart from those of the agent's.
+const userspaceStateSuffix = "/userspace"
+
+// UserspaceStateForOrg is StateForOrg for the in-process tunnels commands
+// dial through without an agent. Their peer is kept apart from the agent's,
+// since both may be connected at once.
+func UserspaceStateForOrg(apiClient *api.Client, org *api.Organization, recycle bool) (*wg.WireGuardState, error) {
+	return stateFor(apiClient, org, org.Slug+userspaceStateSuffix, "interactive-userspace", "", "", recycle)
+}
+
+func stateFor(apiClient *api.Client, org *api.Organization, key, prefix, regionCode, name string, recycle bool) (*wg.WireGuardState, error) {
+	state, err := getWireGuardStateForOrg(key)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +72,7 @@ func StateForOrg(apiClient *api.Client, org *api.Organization, regionCode string
 			return nil, err
 		}
 
-		name = fmt.Sprintf("interactive-agent-%s", n)
+		name = fmt.Sprintf("%s-%s", prefix, n)
 	}
 
 	stateb, err := Create(apiClient, org, regionCode, name)
@@ -64,7 +80,7 @@ func StateForOrg(apiClient *api.Client, org *api.Organization, regionCode string
 		return nil, err
 	}
 
-	if err := setWireGuardStateForOrg(org.Slug, stateb); err != nil {
+	if err := setWireGuardStateForOrg(key, stateb); err != nil {
 		return nil, err
 	}
 
@@ -72,6 +88,15 @@ func StateForOrg(apiClient *api.Client, org *api.Organization, regionCode string
 }
 
 func Create(apiClient *api.Client, org *api.Organization, regionCode, name string) (*wg.WireGuardState, error) {
+	return create(apiClient, org, regionCode, name, 0)
+}
+
+// CreateEphemeral creates a peer which the API removes once ttl has elapsed.
+func CreateEphemeral(apiClient *api.Client, org *api.Organization, regionCode, name string, ttl time.Duration) (*wg.WireGuardState, error) {
+	return create(apiClient, org, regionCode, name, ttl)
+}
+
+func create(apiClient *api.Client, org *api.Organization, regionCode, name string, ttl time.Duration) (*wg.WireGuardState, error) {
 	ctx := context.TODO()
 	var (
 		err error
@@ -107,7 +132,12 @@ func Create(apiClient *api.Client, org *api.Organization, regionCode, name strin
 
 	pubkey, privatekey := C25519pair()
 
-	data, err := apiClient.CreateWireGuardPeer(ctx, org, regionCode, name, pubkey)
+	var data *api.CreatedWireGuardPeer
+	if ttl > 0 {
+		data, err = apiClient.CreateEphemeralWireGuardPeer(ctx, org, regionCode, name, pubkey, ttl)
+	} else {
+		data, err = apiClient.CreateWireGuardPeer(ctx, org, regionCode, name, pubkey)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -206,3 +236,14 @@ func PruneInvalidPeers(ctx context.Context, apiClient *api.Client) error {
 
 	return setWireGuardState(state)
 }
+
+// AgentPeerName returns the name of the peer the local agent uses for the
+// given organization, if any.
+func AgentPeerName(orgSlug string) (string, error) {
+	state, err := getWireGuardStateForOrg(orgSlug)
+	if err != nil || state == nil {
+		return "", err
+	}
+
+	return state.Name, nil
+}
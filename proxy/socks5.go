@@ -0,0 +1,282 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/superfly/flyctl/terminal"
+)
+
+const socks5Version = 0x05
+
+// SOCKS5 authentication methods, commands, address types and replies, as
+// defined by RFC 1928.
+const (
+	socks5NoAuth       = 0x00
+	socks5NoAcceptable = 0xff
+
+	socks5Connect = 0x01
+
+	socks5IPv4   = 0x01
+	socks5Domain = 0x03
+	socks5IPv6   = 0x04
+
+	socks5Succeeded          = 0x00
+	socks5GeneralFailure     = 0x01
+	socks5NotAllowed         = 0x02
+	socks5HostUnreachable    = 0x04
+	socks5ConnectionRefused  = 0x05
+	socks5CommandUnsupported = 0x07
+	socks5AddressUnsupported = 0x08
+)
+
+// privateNetwork is the range of 6PN addresses.
+var privateNetwork = &net.IPNet{IP: net.ParseIP("fdaa::"), Mask: net.CIDRMask(16, 128)}
+
+var errNotAllowed = errors.New("destination is outside the private network")
+
+// SOCKS5Server serves unauthenticated SOCKS5 CONNECT requests, routing those
+// for 6PN addresses and .internal names through an organization's tunnel.
+type SOCKS5Server struct {
+	Listener net.Listener
+	// Dial dials 6PN addresses through the tunnel.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	// Resolve resolves .internal names through the tunnel's DNS.
+	Resolve func(ctx context.Context, host string) (string, error)
+	// DialPublic, if set, dials destinations outside of 6PN. Requests for
+	// those are refused otherwise.
+	DialPublic func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// Serve serves connections until ctx is done, when the listener and all of
+// the connections still open are closed.
+func (srv *SOCKS5Server) Serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		srv.Listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := srv.Listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			srv.serveConn(ctx, conn)
+		}()
+	}
+}
+
+func (srv *SOCKS5Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	if err := socks5Handshake(conn); err != nil {
+		terminal.Debugf("socks5: handshake with %s failed: %v\n", conn.RemoteAddr(), err)
+		return
+	}
+
+	host, port, err := socks5ReadRequest(conn)
+	if err != nil {
+		terminal.Debugf("socks5: bad request from %s: %v\n", conn.RemoteAddr(), err)
+		return
+	}
+
+	target, err := srv.connect(ctx, host, port)
+	if err != nil {
+		terminal.Debugf("socks5: %s failed connecting to %s: %v\n", conn.RemoteAddr(), net.JoinHostPort(host, port), err)
+		socks5Reply(conn, socks5ReplyFor(err))
+
+		return
+	}
+	defer target.Close()
+
+	if err := socks5Reply(conn, socks5Succeeded); err != nil {
+		return
+	}
+
+	terminal.Debugf("socks5: %s connected to %s\n", conn.RemoteAddr(), net.JoinHostPort(host, port))
+
+	var copies sync.WaitGroup
+	copies.Add(2)
+
+	copyFunc := func(dst, src net.Conn) {
+		defer copies.Done()
+		io.Copy(dst, src)
+
+		// close the write half if it exports a CloseWrite() method
+		if conn, ok := dst.(ClosableWrite); ok {
+			conn.CloseWrite()
+		}
+	}
+
+	go copyFunc(target, conn)
+	go copyFunc(conn, target)
+
+	copies.Wait()
+
+	terminal.Debugf("socks5: %s disconnected from %s\n", conn.RemoteAddr(), net.JoinHostPort(host, port))
+}
+
+// connect dials host, resolving .internal names through the tunnel.
+func (srv *SOCKS5Server) connect(ctx context.Context, host, port string) (net.Conn, error) {
+	if ip := net.ParseIP(host); ip == nil && strings.HasSuffix(strings.TrimSuffix(host, "."), ".internal") {
+		addr, err := srv.Resolve(ctx, strings.TrimSuffix(host, "."))
+		if err != nil {
+			return nil, &resolveError{host: host, err: err}
+		}
+		host = addr
+	}
+
+	if ip := net.ParseIP(host); ip != nil && privateNetwork.Contains(ip) {
+		return srv.Dial(ctx, "tcp", net.JoinHostPort(host, port))
+	}
+
+	if srv.DialPublic == nil {
+		return nil, errNotAllowed
+	}
+
+	return srv.DialPublic(ctx, "tcp", net.JoinHostPort(host, port))
+}
+
+type resolveError struct {
+	host string
+	err  error
+}
+
+func (e *resolveError) Error() string {
+	return fmt.Sprintf("failed resolving %s: %v", e.host, e.err)
+}
+
+func (e *resolveError) Unwrap() error {
+	return e.err
+}
+
+func socks5ReplyFor(err error) byte {
+	var re *resolveError
+
+	switch {
+	case errors.Is(err, errNotAllowed):
+		return socks5NotAllowed
+	case errors.As(err, &re):
+		return socks5HostUnreachable
+	default:
+		return socks5ConnectionRefused
+	}
+}
+
+// socks5Handshake negotiates the absence of authentication; the listener
+// being bound to localhost leaves that to the local user.
+func socks5Handshake(conn net.Conn) error {
+	var header [2]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return err
+	}
+	if header[0] != socks5Version {
+		return fmt.Errorf("unsupported version %d", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return err
+	}
+
+	for _, method := range methods {
+		if method == socks5NoAuth {
+			_, err := conn.Write([]byte{socks5Version, socks5NoAuth})
+			return err
+		}
+	}
+
+	conn.Write([]byte{socks5Version, socks5NoAcceptable})
+
+	return errors.New("client requires authentication")
+}
+
+// socks5ReadRequest reads a CONNECT request, replying to any other kind with
+// an error, and returns the destination it names.
+func socks5ReadRequest(conn net.Conn) (host, port string, err error) {
+	var header [4]byte
+	if _, err = io.ReadFull(conn, header[:]); err != nil {
+		return
+	}
+	if header[0] != socks5Version {
+		err = fmt.Errorf("unsupported version %d", header[0])
+		return
+	}
+	if header[1] != socks5Connect {
+		socks5Reply(conn, socks5CommandUnsupported)
+		err = fmt.Errorf("unsupported command %d", header[1])
+		return
+	}
+
+	switch header[3] {
+	case socks5IPv4, socks5IPv6:
+		ip := make(net.IP, net.IPv4len)
+		if header[3] == socks5IPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err = io.ReadFull(conn, ip); err != nil {
+			return
+		}
+		host = ip.String()
+	case socks5Domain:
+		var n [1]byte
+		if _, err = io.ReadFull(conn, n[:]); err != nil {
+			return
+		}
+		name := make([]byte, n[0])
+		if _, err = io.ReadFull(conn, name); err != nil {
+			return
+		}
+		host = string(name)
+	default:
+		socks5Reply(conn, socks5AddressUnsupported)
+		err = fmt.Errorf("unsupported address type %d", header[3])
+		return
+	}
+
+	var p [2]byte
+	if _, err = io.ReadFull(conn, p[:]); err != nil {
+		return
+	}
+	port = strconv.Itoa(int(binary.BigEndian.Uint16(p[:])))
+
+	return
+}
+
+// socks5Reply replies with code. The bound address is left unspecified as
+// connections through the tunnel have none of use to clients.
+func socks5Reply(conn net.Conn, code byte) error {
+	_, err := conn.Write([]byte{socks5Version, code, 0x00, socks5IPv4, 0, 0, 0, 0, 0, 0})
+
+	return err
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startSOCKS5(t *testing.T, srv *SOCKS5Server) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv.Listener = listener
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	return listener.Addr().String()
+}
+
+func socks5Request(t *testing.T, addr string, req []byte) (net.Conn, byte) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = conn.Write([]byte{socks5Version, 1, socks5NoAuth})
+	require.NoError(t, err)
+
+	var method [2]byte
+	_, err = io.ReadFull(conn, method[:])
+	require.NoError(t, err)
+	require.Equal(t, []byte{socks5Version, socks5NoAuth}, method[:])
+
+	_, err = conn.Write(req)
+	require.NoError(t, err)
+
+	var reply [10]byte
+	_, err = io.ReadFull(conn, reply[:])
+	require.NoError(t, err)
+
+	return conn, reply[1]
+}
+
+func TestSOCKS5ResolvesInternalNames(t *testing.T) {
+	var dialed string
+
+	addr := startSOCKS5(t, &SOCKS5Server{
+		Resolve: func(_ context.Context, host string) (string, error) {
+			assert.Equal(t, "my-app.internal", host)
+			return "fdaa:0:1::2", nil
+		},
+		Dial: func(_ context.Context, _, addr string) (net.Conn, error) {
+			dialed = addr
+
+			client, server := net.Pipe()
+			go func() {
+				server.Write([]byte("pong"))
+				server.Close()
+			}()
+
+			return client, nil
+		},
+	})
+
+	name := "my-app.internal"
+	req := append([]byte{socks5Version, socks5Connect, 0, socks5Domain, byte(len(name))}, name...)
+	conn, code := socks5Request(t, addr, append(req, 0x1f, 0x90))
+	require.Equal(t, byte(socks5Succeeded), code)
+
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+
+	assert.Equal(t, "pong", string(data))
+	assert.Equal(t, "[fdaa:0:1::2]:8080", dialed)
+}
+
+func TestSOCKS5RefusesPublicDestinations(t *testing.T) {
+	addr := startSOCKS5(t, &SOCKS5Server{
+		Dial: func(context.Context, string, string) (net.Conn, error) {
+			t.Fatal("dialed a public destination through the tunnel")
+			return nil, nil
+		},
+	})
+
+	_, code := socks5Request(t, addr, []byte{socks5Version, socks5Connect, 0, socks5IPv4, 1, 1, 1, 1, 0, 80})
+
+	assert.Equal(t, byte(socks5NotAllowed), code)
+}
@@ -3,7 +3,11 @@ package ssh
 import (
 	"context"
 	"io"
+	"os"
+	"os/signal"
 	"runtime"
+	"sync"
+	"sync/atomic"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/term"
@@ -38,6 +42,14 @@ type Terminal struct {
 	Stderr io.WriteCloser
 
 	Mode string
+
+	// DisablePTY streams stdin, stdout and stderr as-is instead of
+	// allocating a remote pseudo-terminal.
+	DisablePTY bool
+
+	// EscapeChar enables ssh-style escape sequences (e.g. "~.") on an
+	// interactive terminal. A zero value disables them.
+	EscapeChar byte
 }
 
 func getFd(reader io.Reader) (fd int, ok bool) {
@@ -50,9 +62,25 @@ func getFd(reader io.Reader) (fd int, ok bool) {
 	return fd, term.IsTerminal(fd)
 }
 
+// IsTerminal reports whether reader is attached to a terminal.
+func IsTerminal(reader io.Reader) bool {
+	_, ok := getFd(reader)
+	return ok
+}
+
 func (t *Terminal) attach(ctx context.Context, sess *ssh.Session, cmd string) error {
+	if t.DisablePTY {
+		return t.stream(sess, cmd)
+	}
+
+	// the parent context is canceled on ^C; since those are forwarded to the
+	// remote end we must keep watching the window size until we detach.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	width, height := DefaultWidth, DefaultHeight
-	if fd, ok := getFd(t.Stdin); ok {
+	fd, interactive := getFd(t.Stdin)
+	if interactive {
 		state, err := term.MakeRaw(fd)
 		if err != nil {
 			return err
@@ -92,18 +120,164 @@ func (t *Terminal) attach(ctx context.Context, sess *ssh.Session, cmd string) er
 		return err
 	}
 
-	go io.Copy(stdin, t.Stdin)
-	go io.Copy(t.Stdout, stdout)
-	go io.Copy(t.Stderr, stderr)
+	// set by the goroutine copying stdin
+	var escaped atomic.Bool
+	input := t.Stdin
+	if interactive && t.EscapeChar != 0 {
+		input = newEscapeReader(t.Stdin, t.EscapeChar, func() {
+			escaped.Store(true)
+			sess.Close()
+		})
+	}
+
+	go forwardSignals(ctx, stdin)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go io.Copy(stdin, input)
+	go func() {
+		defer wg.Done()
+		io.Copy(t.Stdout, stdout)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(t.Stderr, stderr)
+	}()
 
 	if cmd == "" {
-		err = sess.Shell()
+		if err = sess.Shell(); err == nil {
+			err = sess.Wait()
+		}
 	} else {
 		err = sess.Run(cmd)
 	}
+
+	if escaped.Load() {
+		return nil
+	}
 	if err != nil && err != io.EOF {
 		return err
 	}
 
+	// drain whatever output is still in flight before handing the terminal back
+	wg.Wait()
+
 	return nil
 }
+
+// stream runs cmd without a pty. Input and output are copied as they arrive
+// rather than line by line, so interactive programs keep working over pipes.
+func (t *Terminal) stream(sess *ssh.Session, cmd string) error {
+	sess.Stdin = t.Stdin
+	sess.Stdout = t.Stdout
+	sess.Stderr = t.Stderr
+
+	var err error
+	if cmd == "" {
+		if err = sess.Shell(); err == nil {
+			err = sess.Wait()
+		}
+	} else {
+		err = sess.Run(cmd)
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// forwardSignals relays locally delivered interrupt signals to the remote pty
+// as their control characters instead of letting them stop the local client.
+func forwardSignals(ctx context.Context, w io.Writer) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, forwardedSignals...)
+	defer signal.Stop(sigc)
+
+	for {
+		select {
+		case sig := <-sigc:
+			if b, ok := controlChars[sig]; ok {
+				w.Write([]byte{b})
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// escapeReader watches input for an escape character typed at the beginning
+// of a line. The escape character followed by '.' terminates the session and
+// typing it twice sends it once; anything else passes through unchanged.
+type escapeReader struct {
+	r        io.Reader
+	char     byte
+	onEscape func()
+
+	lineStart bool
+	pending   bool
+	buf       []byte
+	done      bool
+}
+
+func newEscapeReader(r io.Reader, char byte, onEscape func()) *escapeReader {
+	return &escapeReader{
+		r:         r,
+		char:      char,
+		onEscape:  onEscape,
+		lineStart: true,
+	}
+}
+
+func (e *escapeReader) Read(p []byte) (int, error) {
+	for len(e.buf) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+
+		in := make([]byte, len(p))
+		n, err := e.r.Read(in)
+		e.filter(in[:n])
+
+		if err != nil && len(e.buf) == 0 {
+			return 0, err
+		}
+	}
+
+	n := copy(p, e.buf)
+	e.buf = e.buf[n:]
+
+	return n, nil
+}
+
+func (e *escapeReader) filter(in []byte) {
+	for _, b := range in {
+		if e.done {
+			return
+		}
+
+		switch {
+		case e.pending:
+			e.pending = false
+
+			switch b {
+			case '.':
+				e.done = true
+				e.onEscape()
+				return
+			case e.char:
+				e.buf = append(e.buf, b)
+			default:
+				e.buf = append(e.buf, e.char, b)
+			}
+		case e.lineStart && b == e.char:
+			e.pending = true
+			continue
+		default:
+			e.buf = append(e.buf, b)
+		}
+
+		e.lineStart = b == '\r' || b == '\n'
+	}
+}
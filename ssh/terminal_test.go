@@ -0,0 +1,39 @@
+package ssh
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeReader(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		output  string
+		escaped bool
+	}{
+		{"passthrough", "ls -la\r", "ls -la\r", false},
+		{"mid-line escape char", "echo a~.b\r", "echo a~.b\r", false},
+		{"terminate", "ls\r~.rm -rf /\r", "ls\r", true},
+		{"terminate at start", "~.", "", true},
+		{"doubled escape char", "~~.\r", "~.\r", false},
+		{"other escape sequence", "~x\r", "~x\r", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var escaped bool
+			r := newEscapeReader(strings.NewReader(tc.input), '~', func() { escaped = true })
+
+			out, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.output, string(out))
+			assert.Equal(t, tc.escaped, escaped)
+		})
+	}
+}
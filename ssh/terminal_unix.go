@@ -13,9 +13,17 @@ import (
 	"golang.org/x/term"
 )
 
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTSTP}
+
+var controlChars = map[os.Signal]byte{
+	os.Interrupt:    0x03, // ^C
+	syscall.SIGTSTP: 0x1a, // ^Z
+}
+
 func watchWindowSize(ctx context.Context, fd int, sess *ssh.Session) error {
 	sigc := make(chan os.Signal, 1)
 	signal.Notify(sigc, syscall.SIGWINCH)
+	defer signal.Stop(sigc)
 
 	for {
 		select {
@@ -5,10 +5,17 @@ package ssh
 
 import (
 	"context"
+	"os"
 
 	"golang.org/x/crypto/ssh"
 )
 
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+var controlChars = map[os.Signal]byte{
+	os.Interrupt: 0x03, // ^C
+}
+
 func watchWindowSize(ctx context.Context, fd int, sess *ssh.Session) error {
 	// TODO: SIGWINCH for windows?
 	return nil
@@ -10,6 +10,7 @@ import (
 	"net/netip"
 
 	"github.com/miekg/dns"
+	"github.com/superfly/flyctl/terminal"
 	"golang.zx2c4.com/wireguard/conn"
 	"golang.zx2c4.com/wireguard/device"
 	"golang.zx2c4.com/wireguard/tun"
@@ -34,7 +35,7 @@ func Connect(ctx context.Context, state *WireGuardState) (*Tunnel, error) {
 
 func doConnect(ctx context.Context, state *WireGuardState, wswg bool) (*Tunnel, error) {
 	cfg := state.TunnelConfig()
-	fmt.Println("wg connect", cfg.DNS, cfg.Endpoint, cfg.LocalNetwork.IP, cfg.RemoteNetwork.IP)
+	terminal.Debugf("wg connect %v %v %v %v\n", cfg.DNS, cfg.Endpoint, cfg.LocalNetwork.IP, cfg.RemoteNetwork.IP)
 	addr, ok := netip.AddrFromSlice(cfg.LocalNetwork.IP)
 
 	if !ok {
@@ -105,7 +106,7 @@ func doConnect(ctx context.Context, state *WireGuardState, wswg bool) (*Tunnel,
 		resolv: &net.Resolver{
 			PreferGo: true,
 			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-				fmt.Println("resolver.Dial", network, address)
+				terminal.Debugf("resolver.Dial %s %s\n", network, address)
 				return gNet.DialContext(ctx, "tcp", net.JoinHostPort(dnsIP.String(), "53"))
 			},
 		},